@@ -1,6 +1,10 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"expvar"
 	"flag"
 	"fmt"
 	"log/slog"
@@ -11,70 +15,79 @@ import (
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/tektoncd/release-mcp/internal/tools"
 	"go.etcd.io/etcd/version"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	filteredinformerfactory "knative.dev/pkg/client/injection/kube/informers/factory/filtered"
+	"knative.dev/pkg/controller"
 	"knative.dev/pkg/injection"
 	"knative.dev/pkg/signals"
 )
 
-// ManagedByLabelKey is the label key used to mark what is managing this resource
-const ManagedByLabelKey = "app.kubernetes.io/managed-by"
+// logLevel is the level of the server log, set by -log-level
+var logLevel = new(slog.LevelVar)
 
 func main() {
-	// Configure logging
-	logHandler := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-		Level: slog.LevelInfo,
+	// Configure logging. The log goes to stderr, stdout is the stdio transport,
+	// and the records of every tool call are also kept in its run log.
+	logHandler := slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+		Level: logLevel,
 	})
-	logger := slog.New(logHandler)
+	logger := slog.New(tools.LogHandler(logHandler))
 	slog.SetDefault(logger)
 
+	// The status command renders the state of a release served by a running server
+	if len(os.Args) > 1 && os.Args[1] == "status" {
+		if err := runStatus(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// The run-tool command runs a single tool as a Tekton Task step
+	if len(os.Args) > 1 && os.Args[1] == "run-tool" {
+		if err := runTool(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Parse command line flags
 	var transport string
 	var httpAddr string
+	var tlsCert, tlsKey, tlsClientCA string
+	var setup serverFlags
 	flag.StringVar(&transport, "transport", "http", "Transport type (stdio or http)")
 	flag.StringVar(&httpAddr, "address", ":3000", "Address to bind the HTTP server to")
+	flag.StringVar(&tlsCert, "tls-cert", "", "PEM certificate to serve the HTTP transport over TLS with, requires -tls-key")
+	flag.StringVar(&tlsKey, "tls-key", "", "PEM private key of -tls-cert")
+	flag.StringVar(&tlsClientCA, "tls-client-ca", "", "PEM CA bundle client certificates must be signed by, requiring clients to present one")
+	setup.register(flag.CommandLine)
 	flag.Parse()
 
 	if httpAddr == "" && transport == "http" {
 		slog.Error("-address is required when transport is set to 'http'")
 		os.Exit(1)
 	}
-
-	// Create MCP server
-	impl := &mcp.Implementation{
-		Name:    "Tekton Release MCP Server",
-		Version: version.Version,
-		Title:   "Tekton Release Management Server",
-	}
-	opts := &mcp.ServerOptions{
-		Instructions: `This server provides tools for managing Tekton releases:
-- Creating release branches
-- Configuring hack repository
-- Managing ReleasePlanAdmission and ReleasePlan files`,
+	tlsConf, err := serverTLSConfig(tlsCert, tlsKey, tlsClientCA)
+	if err != nil {
+		slog.Error("Invalid TLS configuration", "error", err)
+		os.Exit(1)
 	}
-	s := mcp.NewServer(impl, opts)
 
 	// Create context with cancellation
 	ctx := signals.NewContext()
 
-	// Load kubernetes configuration
-	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
-	configOverrides := &clientcmd.ConfigOverrides{}
-	kubeConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, configOverrides)
-	cfg, err := kubeConfig.ClientConfig()
+	ctx, s, err := newServer(ctx, setup)
 	if err != nil {
-		slog.Error("Failed to get Kubernetes config", "error", err)
+		slog.Error("Failed to set up the server", "error", err)
 		os.Exit(1)
 	}
 
-	// Configure and start informers
-	ctx = filteredinformerfactory.WithSelectors(ctx, ManagedByLabelKey)
-	ctx, startInformers := injection.EnableInjectionOrDie(ctx, cfg)
-	startInformers()
-
-	// Add tools to the server
-	if err = tools.Add(ctx, s); err != nil {
-		slog.Error("Failed to add tools", "error", err)
+	// Run the scheduled tasks in this long-running server only, not in run-tool
+	if err := tools.StartScheduler(ctx); err != nil {
+		slog.Error("Failed to start the scheduler", "error", err)
 		os.Exit(1)
 	}
 
@@ -86,13 +99,18 @@ func main() {
 	case "http":
 		// Configure HTTP server with timeouts and handlers
 		streamableHandler := mcp.NewStreamableHTTPHandler(func(r *http.Request) *mcp.Server { return s }, nil)
-		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mux := http.NewServeMux()
+		mux.Handle("/admin/maintenance", tools.MaintenanceHandler())
+		mux.Handle("/admin/v1/", tools.AdminAPIHandler(ctx, s))
+		mux.Handle("/debug/vars", tools.AdminOnly(expvar.Handler()))
+		mux.Handle("/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			streamableHandler.ServeHTTP(w, r.WithContext(ctx))
-		})
+		}))
 
 		server := &http.Server{
 			Addr:              httpAddr,
-			Handler:           handler,
+			Handler:           mux,
+			TLSConfig:         tlsConf,
 			ReadHeaderTimeout: 3 * time.Second,
 			ReadTimeout:       30 * time.Second,
 			WriteTimeout:      30 * time.Second,
@@ -100,8 +118,13 @@ func main() {
 		}
 
 		go func() {
-			slog.Info("Server listening", "address", httpAddr)
-			if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			slog.Info("Server listening", "address", httpAddr, "tls", tlsConf != nil)
+			serve := server.ListenAndServe
+			if tlsConf != nil {
+				// The certificate is loaded in the TLS configuration
+				serve = func() error { return server.ListenAndServeTLS("", "") }
+			}
+			if err := serve(); err != nil && err != http.ErrServerClosed {
 				errC <- fmt.Errorf("server error: %w", err)
 			}
 		}()
@@ -129,4 +152,177 @@ func main() {
 	}
 
 	slog.Info("Server shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	tools.StopTracing(shutdownCtx)
+	tools.StopSandbox()
+}
+
+// serverTLSConfig returns the TLS configuration of the HTTP transport, nil
+// when no certificate is set. With a client CA, clients must present a
+// certificate signed by it.
+func serverTLSConfig(certFile, keyFile, clientCAFile string) (*tls.Config, error) {
+	if certFile == "" && keyFile == "" {
+		if clientCAFile != "" {
+			return nil, fmt.Errorf("-tls-client-ca requires -tls-cert and -tls-key")
+		}
+		return nil, nil
+	}
+	if certFile == "" || keyFile == "" {
+		return nil, fmt.Errorf("-tls-cert and -tls-key must be set together")
+	}
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the TLS certificate: %w", err)
+	}
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if clientCAFile != "" {
+		pem, err := os.ReadFile(clientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", clientCAFile, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("%s holds no PEM certificate", clientCAFile)
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return config, nil
+}
+
+// serverFlags holds the flags shared by the server and the run-tool command
+type serverFlags struct {
+	reposConfig    string
+	reposConfigMap string
+	managedByLabel string
+	templatesDir   string
+	konfluxRepo    string
+	konfluxFork    string
+	otlpEndpoint   string
+	mode           string
+	sandboxDir     string
+	sandboxFaults  float64
+	logLevel       string
+}
+
+func (f *serverFlags) register(flags *flag.FlagSet) {
+	flags.StringVar(&f.reposConfig, "repos-config", "", "YAML or JSON file listing the repositories release branches are cut in")
+	flags.StringVar(&f.reposConfigMap, "repos-configmap", "", "ConfigMap (namespace/name) listing the repositories release branches are cut in, when running in-cluster")
+	flags.StringVar(&f.templatesDir, "templates-dir", "", "Directory of template files (e.g., rpa.yaml.tmpl) replacing the built-in ones of the same name")
+	flags.StringVar(&f.konfluxRepo, "konflux-repo", "", "GitLab repository (namespace/name) release plan merge requests target, defaults to $RELEASE_MCP_KONFLUX_REPOSITORY or releng/konflux-release-data")
+	flags.StringVar(&f.konfluxFork, "konflux-fork", "", "GitLab fork (namespace/name) of the konflux-release-data repository branches are pushed to, defaults to $RELEASE_MCP_KONFLUX_FORK")
+	flags.StringVar(&f.managedByLabel, "managed-by-label", tools.DefaultManagedByLabel, "key=value label stamped onto the resources the server creates on-cluster and selected by its informers")
+	flags.StringVar(&f.mode, "mode", "live", "live operates on the real forges and cluster, sandbox on in-process fakes of them")
+	flags.StringVar(&f.sandboxDir, "sandbox-dir", "", "Directory the sandbox keeps its repositories and state in, kept on exit; a temporary directory by default")
+	flags.Float64Var(&f.sandboxFaults, "sandbox-fault-rate", 0, "Fraction (0 to 1) of the fake forge and cluster API requests failing with a 503, to exercise retries")
+	flags.StringVar(&f.logLevel, "log-level", "info", "Level of the server log (debug, info, warn or error); run logs keep every level")
+	flags.StringVar(&f.otlpEndpoint, "otlp-endpoint", "", "OTLP/HTTP endpoint (e.g., http://collector:4318/v1/traces) tool calls are traced to, defaults to $OTEL_EXPORTER_OTLP_TRACES_ENDPOINT or $OTEL_EXPORTER_OTLP_ENDPOINT")
+}
+
+// informerSyncTimeout is how long the server waits for its informers to sync
+// before serving without them
+const informerSyncTimeout = 30 * time.Second
+
+// startInformers injects the Kubernetes clients into ctx and starts the
+// informers of the resources this server manages. An unreachable cluster is
+// logged rather than stopping the server: the informers keep syncing in the
+// background, and the cluster tools fail until the cluster is reachable.
+func startInformers(ctx context.Context, cfg *rest.Config) context.Context {
+	ctx = filteredinformerfactory.WithSelectors(ctx, tools.ManagedBySelector())
+	ctx = injection.WithConfig(ctx, cfg)
+	ctx, informers := injection.Default.SetupInformers(ctx, cfg)
+
+	synced := make(chan error, 1)
+	go func() {
+		synced <- controller.StartInformers(ctx.Done(), informers...)
+	}()
+	select {
+	case err := <-synced:
+		if err != nil {
+			slog.Warn("Failed to start informers", "error", err)
+		}
+	case <-time.After(informerSyncTimeout):
+		slog.Warn("Informers have not synced, serving without waiting for them", "timeout", informerSyncTimeout)
+	}
+	return ctx
+}
+
+// newServer creates the MCP server with all tools registered, starting the
+// informers it depends on. The returned context carries the injected clients.
+func newServer(ctx context.Context, setup serverFlags) (context.Context, *mcp.Server, error) {
+	impl := &mcp.Implementation{
+		Name:    "Tekton Release MCP Server",
+		Version: version.Version,
+		Title:   "Tekton Release Management Server",
+	}
+	// The instructions are generated from the registered tools on initialization
+	s := mcp.NewServer(impl, nil)
+
+	if err := logLevel.UnmarshalText([]byte(setup.logLevel)); err != nil {
+		return ctx, nil, fmt.Errorf("invalid -log-level %q, expected debug, info, warn or error", setup.logLevel)
+	}
+
+	// Load kubernetes configuration, or serve the cluster and forges from fakes in sandbox mode
+	var cfg *rest.Config
+	var err error
+	switch setup.mode {
+	case "live":
+		loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+		configOverrides := &clientcmd.ConfigOverrides{}
+		kubeConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, configOverrides)
+		// Tools working on the forges only do not need a cluster, those that
+		// do report that it is not configured
+		if cfg, err = kubeConfig.ClientConfig(); err != nil {
+			slog.Warn("No Kubernetes config, running without the cluster tools", "error", err)
+			cfg = nil
+		}
+	case "sandbox":
+		cfg, err = tools.StartSandbox(tools.SandboxOptions{Dir: setup.sandboxDir, FaultRate: setup.sandboxFaults})
+		if err != nil {
+			return ctx, nil, fmt.Errorf("failed to start the sandbox: %w", err)
+		}
+	default:
+		return ctx, nil, fmt.Errorf("invalid -mode %q, expected live or sandbox", setup.mode)
+	}
+
+	// Configure and start informers, which only watch the resources this server manages
+	if err := tools.SetManagedByLabel(setup.managedByLabel); err != nil {
+		return ctx, nil, fmt.Errorf("invalid -managed-by-label: %w", err)
+	}
+	if cfg != nil {
+		ctx = startInformers(ctx, cfg)
+	}
+
+	// Load the repository list, replacing the built-in one
+	if err := tools.LoadRepositories(ctx, setup.reposConfig, setup.reposConfigMap); err != nil {
+		return ctx, nil, fmt.Errorf("failed to load repositories: %w", err)
+	}
+
+	// Replace the built-in template files with those of the templates directory
+	if err := tools.SetTemplatesDir(setup.templatesDir); err != nil {
+		return ctx, nil, fmt.Errorf("invalid -templates-dir: %w", err)
+	}
+
+	// Select the konflux-release-data repository and the fork branches are pushed to
+	if err := tools.SetKonfluxRemotes(setup.konfluxRepo, setup.konfluxFork); err != nil {
+		return ctx, nil, fmt.Errorf("invalid -konflux-repo or -konflux-fork: %w", err)
+	}
+
+	// Stamp generated manifests with the server version
+	tools.SetGeneratorVersion(version.Version)
+
+	// Trace tool calls and their steps when a collector is configured
+	if err := tools.StartTracing(setup.otlpEndpoint); err != nil {
+		return ctx, nil, fmt.Errorf("invalid -otlp-endpoint: %w", err)
+	}
+
+	// Add tools to the server
+	if err := tools.Add(ctx, s); err != nil {
+		return ctx, nil, fmt.Errorf("failed to add tools: %w", err)
+	}
+	return ctx, s, nil
 }
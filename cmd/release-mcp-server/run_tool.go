@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/tektoncd/release-mcp/internal/tools"
+	"go.etcd.io/etcd/version"
+	"knative.dev/pkg/signals"
+)
+
+// Names of the files written to the results directory, matching the results
+// a Tekton Task running the command declares
+const (
+	resultStatus     = "status"
+	resultSummary    = "summary"
+	resultStructured = "structured-output"
+)
+
+// maxResultSize keeps the results of a step under the 4096 byte budget Tekton
+// gives all results of a step combined
+const maxResultSize = 1800
+
+// runTool implements the run-tool command, which calls a single tool of an
+// in-process server and writes its outcome as Tekton results. The command
+// exits non-zero when the tool fails, failing the step.
+func runTool(args []string) error {
+	flags := flag.NewFlagSet("run-tool", flag.ExitOnError)
+	var toolName, params, paramsFile, resultsDir string
+	var confirm bool
+	var setup serverFlags
+	flags.StringVar(&toolName, "tool", "", "Name of the tool to run (e.g., create-release-branches)")
+	flags.StringVar(&params, "params", "", "Tool arguments as a JSON object")
+	flags.StringVar(&paramsFile, "params-file", "", "File holding the tool arguments as a JSON object")
+	flags.StringVar(&resultsDir, "results-dir", os.Getenv("RESULTS_DIR"), "Directory to write the status, summary and structured-output results to (e.g., /tekton/results)")
+	flags.BoolVar(&confirm, "confirm", false, "Confirm the plan of a tool call that pushes to many repositories or changes prod, as the Task was reviewed ahead of time")
+	setup.register(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if toolName == "" {
+		return fmt.Errorf("--tool is required")
+	}
+
+	arguments, err := toolArguments(params, paramsFile)
+	if err != nil {
+		return err
+	}
+	// A step must not exit before the tool is done, so jobs are never used
+	delete(arguments, "async")
+
+	ctx, server, err := newServer(signals.NewContext(), setup)
+	if err != nil {
+		return err
+	}
+	// Export the spans of the tool call before the step exits
+	defer tools.StopTracing(context.Background())
+	defer tools.StopSandbox()
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport)
+	if err != nil {
+		return fmt.Errorf("failed to start the server: %w", err)
+	}
+	defer serverSession.Close()
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "release-mcp-run-tool", Version: version.Version}, &mcp.ClientOptions{
+		ProgressNotificationHandler: func(_ context.Context, _ *mcp.ClientSession, p *mcp.ProgressNotificationParams) {
+			slog.Info("Progress", "tool", toolName, "message", p.Message)
+		},
+	})
+	session, err := client.Connect(ctx, clientTransport)
+	if err != nil {
+		return fmt.Errorf("failed to connect to the server: %w", err)
+	}
+	defer session.Close()
+
+	call := &mcp.CallToolParams{Name: toolName, Arguments: arguments, Meta: mcp.Meta{}}
+	call.SetProgressToken(toolName)
+	result, err := session.CallTool(ctx, call)
+	if hash, ok := planHash(result); err == nil && ok && confirm {
+		slog.Info("Confirming plan", "tool", toolName, "hash", hash)
+		arguments["confirm"] = hash
+		result, err = session.CallTool(ctx, call)
+	}
+	if err != nil {
+		if werr := writeResults(resultsDir, "failed", err.Error(), nil); werr != nil {
+			slog.Error("Failed to write results", "error", werr)
+		}
+		return fmt.Errorf("%s failed: %w", toolName, err)
+	}
+
+	var summary strings.Builder
+	for _, content := range result.Content {
+		if text, ok := content.(*mcp.TextContent); ok {
+			summary.WriteString(text.Text)
+		}
+	}
+	fmt.Println(summary.String())
+
+	status := "succeeded"
+	if result.IsError {
+		status = "failed"
+	}
+	if err := writeResults(resultsDir, status, summary.String(), result.StructuredContent); err != nil {
+		return err
+	}
+	if result.IsError {
+		return fmt.Errorf("%s failed", toolName)
+	}
+	return nil
+}
+
+// planHash returns the plan hash of a tool call held back for confirmation
+func planHash(result *mcp.CallToolResult) (string, bool) {
+	if result == nil {
+		return "", false
+	}
+	hash, ok := result.Meta[tools.PlanHashMeta].(string)
+	return hash, ok
+}
+
+// toolArguments decodes the tool arguments from the --params flag or the
+// file named by --params-file
+func toolArguments(params, paramsFile string) (map[string]any, error) {
+	if params != "" && paramsFile != "" {
+		return nil, fmt.Errorf("--params and --params-file are mutually exclusive")
+	}
+	if paramsFile != "" {
+		data, err := os.ReadFile(paramsFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", paramsFile, err)
+		}
+		params = string(data)
+	}
+
+	arguments := map[string]any{}
+	if strings.TrimSpace(params) == "" {
+		return arguments, nil
+	}
+	if err := json.Unmarshal([]byte(params), &arguments); err != nil {
+		return nil, fmt.Errorf("tool arguments must be a JSON object: %w", err)
+	}
+	return arguments, nil
+}
+
+// writeResults writes the outcome of the tool to the results directory. The
+// summary is truncated to fit the result budget, and the structured output is
+// left out when it does not fit, since a truncated document is not valid JSON.
+func writeResults(dir, status, summary string, structured any) error {
+	if dir == "" {
+		return nil
+	}
+
+	results := map[string]string{
+		resultStatus:     status,
+		resultSummary:    truncateResult(summary),
+		resultStructured: "",
+	}
+	if structured != nil {
+		data, err := json.Marshal(structured)
+		if err != nil {
+			return fmt.Errorf("failed to encode the structured output: %w", err)
+		}
+		if len(data) > maxResultSize {
+			slog.Warn("Structured output does not fit in a result, leaving it empty", "size", len(data))
+			data = nil
+		}
+		results[resultStructured] = string(data)
+	}
+
+	for name, value := range results {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(value), 0o644); err != nil {
+			return fmt.Errorf("failed to write the %s result: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func truncateResult(value string) string {
+	if len(value) <= maxResultSize {
+		return value
+	}
+	const marker = "\n... (truncated, see the step log)"
+	return strings.ToValidUTF8(value[:maxResultSize-len(marker)], "") + marker
+}
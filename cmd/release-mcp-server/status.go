@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/tektoncd/release-mcp/internal/tools"
+	"go.etcd.io/etcd/version"
+)
+
+// ANSI escape sequences used by the status view
+const (
+	ansiReset  = "\033[0m"
+	ansiBold   = "\033[1m"
+	ansiGreen  = "\033[32m"
+	ansiRed    = "\033[31m"
+	ansiYellow = "\033[33m"
+	ansiClear  = "\033[H\033[2J"
+)
+
+// runStatus implements the status command, which renders the release state of
+// a version served by a running server
+func runStatus(args []string) error {
+	flags := flag.NewFlagSet("status", flag.ExitOnError)
+	var releaseVersion, serverURL string
+	var watch time.Duration
+	flags.StringVar(&releaseVersion, "version", "", "Minor version to show the status of (e.g., 1.19)")
+	flags.StringVar(&serverURL, "server", "http://localhost:3000", "URL of the running server's HTTP transport")
+	flags.DurationVar(&watch, "watch", 0, "Refresh interval; the status is printed once when zero")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if releaseVersion == "" {
+		return fmt.Errorf("--version is required")
+	}
+
+	ctx := context.Background()
+	client := mcp.NewClient(&mcp.Implementation{Name: "release-mcp-status", Version: version.Version}, nil)
+	session, err := client.Connect(ctx, mcp.NewStreamableClientTransport(serverURL, nil))
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", serverURL, err)
+	}
+	defer session.Close()
+
+	for {
+		status, err := fetchReleaseStatus(ctx, session, releaseVersion)
+		if err != nil {
+			return err
+		}
+		if watch > 0 {
+			fmt.Print(ansiClear)
+		}
+		renderReleaseStatus(os.Stdout, status)
+		if watch <= 0 {
+			return nil
+		}
+		time.Sleep(watch)
+	}
+}
+
+func fetchReleaseStatus(ctx context.Context, session *mcp.ClientSession, releaseVersion string) (tools.ReleaseStatus, error) {
+	var status tools.ReleaseStatus
+	uri := fmt.Sprintf("release://%s/status", releaseVersion)
+	result, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: uri})
+	if err != nil {
+		return status, fmt.Errorf("failed to read %s: %w", uri, err)
+	}
+	if len(result.Contents) == 0 {
+		return status, fmt.Errorf("%s returned no content", uri)
+	}
+	if err := json.Unmarshal([]byte(result.Contents[0].Text), &status); err != nil {
+		return status, fmt.Errorf("failed to decode release status: %w", err)
+	}
+	return status, nil
+}
+
+func renderReleaseStatus(w io.Writer, status tools.ReleaseStatus) {
+	fmt.Fprintf(w, "%sRelease %s%s  phase: %s\n", ansiBold, status.Version, ansiReset, status.Phase)
+	if dates := status.Dates; dates != nil {
+		for _, entry := range []struct{ label, date string }{
+			{"merge window", dates.MergeWindowStart},
+			{"code freeze", dates.CodeFreeze},
+			{"branch", dates.BranchDate},
+			{"GA", dates.GA},
+		} {
+			if entry.date != "" {
+				fmt.Fprintf(w, "  %-13s %s\n", entry.label, entry.date)
+			}
+		}
+	}
+
+	fmt.Fprintf(w, "\n%sSteps%s\n", ansiBold, ansiReset)
+	for _, step := range status.Steps {
+		marker, color := "○", ansiYellow
+		switch step.State {
+		case "done":
+			marker, color = "✓", ansiGreen
+		case "failed":
+			marker, color = "✗", ansiRed
+		}
+		fmt.Fprintf(w, "  %s%s%s %-26s %s", color, marker, ansiReset, step.Tool, step.State)
+		if !step.Time.IsZero() {
+			fmt.Fprintf(w, " (%s)", step.Time.Local().Format("2006-01-02 15:04"))
+		}
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintf(w, "\n%sRecent events%s\n", ansiBold, ansiReset)
+	if len(status.RecentEvents) == 0 {
+		fmt.Fprintln(w, "  none")
+	}
+	for i := len(status.RecentEvents) - 1; i >= 0; i-- {
+		event := status.RecentEvents[i]
+		color := ansiGreen
+		if !event.Success {
+			color = ansiRed
+		}
+		summary := strings.TrimSpace(strings.SplitN(event.Summary, "\n", 2)[0])
+		fmt.Fprintf(w, "  %s %s%-26s%s %s\n", event.Time.Local().Format("01-02 15:04"), color, event.Tool, ansiReset, summary)
+	}
+}
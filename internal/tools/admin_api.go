@@ -0,0 +1,271 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	mcp "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// adminClientHeader names the automation calling the admin API, each
+	// getting its own MCP session, so confirmed plans and remembered arguments
+	// are not shared between them
+	adminClientHeader = "X-Release-MCP-Client"
+
+	// maxAdminRequest bounds the size of tool arguments sent to the admin API
+	maxAdminRequest = 1 << 20
+
+	// maxAdminSessions bounds the sessions of the admin API, the client header
+	// being chosen by the callers
+	maxAdminSessions = 32
+
+	// adminSessionIdleTimeout is how long the session of a caller is kept
+	// after its last request, with its confirmed plans and remembered arguments
+	adminSessionIdleTimeout = time.Hour
+)
+
+// AdminToolResult represents the outcome of a tool called through the admin API
+type AdminToolResult struct {
+	Tool              string `json:"tool"`
+	IsError           bool   `json:"is_error"`
+	Text              string `json:"text"`
+	StructuredContent any    `json:"structured_content,omitempty"`
+	PlanHash          string `json:"plan_hash,omitempty" jsonschema:"Set when the call is held back for confirmation, repeat it with confirm set to the hash"`
+	JobID             string `json:"job_id,omitempty" jsonschema:"Set when the tool was called with async, poll it with get-job-status"`
+}
+
+// adminAPI serves tool invocations and release state queries to automation
+// that does not speak MCP. Requests are forwarded to the MCP server over
+// in-memory sessions, so they run the same handlers and middlewares as MCP
+// clients: audit log, release windows, confirmations, maintenance and locks.
+type adminAPI struct {
+	ctx      context.Context
+	server   *mcp.Server
+	mu       sync.Mutex
+	sessions map[string]*adminSession
+}
+
+// adminSession represents the MCP session of a caller of the admin API
+type adminSession struct {
+	session  *mcp.ClientSession
+	lastUsed time.Time
+	calls    int      // Requests in progress
+	jobs     []string // Background jobs started by the caller, which the session must outlive
+}
+
+// busy reports whether the session has requests or background jobs in progress
+func (s *adminSession) busy() bool {
+	if s.calls > 0 {
+		return true
+	}
+	for _, id := range s.jobs {
+		if j, err := backgroundJobs.get(id); err == nil && j.snapshot().State == jobRunning {
+			return true
+		}
+	}
+	return false
+}
+
+// AdminAPIHandler serves the admin REST API of the server under /admin/v1/.
+// Like the other admin endpoints, requests must carry the admin token as a
+// bearer token, and the API is disabled when no admin token is configured.
+//
+//	GET  /admin/v1/tools                       lists the tools and their schemas
+//	POST /admin/v1/tools/{name}                calls a tool with a JSON object of arguments
+//	GET  /admin/v1/releases/{version}/status   returns the release status of a version
+//	GET  /admin/v1/releases/{version}/actions  returns the release actions changelog of a version
+//	GET  /admin/v1/supported-versions          returns the supported versions matrix
+func AdminAPIHandler(ctx context.Context, s *mcp.Server) http.Handler {
+	api := &adminAPI{ctx: ctx, server: s, sessions: map[string]*adminSession{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /admin/v1/tools", api.listTools)
+	mux.HandleFunc("POST /admin/v1/tools/{name}", api.callTool)
+	mux.HandleFunc("GET /admin/v1/releases/{version}/status", api.resource("release://%s/status"))
+	mux.HandleFunc("GET /admin/v1/releases/{version}/actions", api.resource("release://%s/actions"))
+	mux.HandleFunc("GET /admin/v1/supported-versions", api.resource(supportedVersionsURI))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !adminAuthorized(w, r) {
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// session returns the MCP session of the automation making the request,
+// connecting it on its first request, and marks it in use until done is
+// called. Sessions idle for adminSessionIdleTimeout are closed, and the least
+// recently used idle session makes room for a new caller once
+// maxAdminSessions are open.
+func (api *adminAPI) session(r *http.Request) (session *adminSession, done func(), err error) {
+	name := r.Header.Get(adminClientHeader)
+	if name == "" {
+		name = "default"
+	}
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.expireSessions(time.Now())
+
+	session, ok := api.sessions[name]
+	if !ok {
+		if len(api.sessions) >= maxAdminSessions && !api.evictSession() {
+			return nil, nil, fmt.Errorf("too many admin API clients, at most %d sessions are kept", maxAdminSessions)
+		}
+		serverTransport, clientTransport := mcp.NewInMemoryTransports()
+		if _, err := api.server.Connect(api.ctx, serverTransport); err != nil {
+			return nil, nil, fmt.Errorf("failed to connect to the server: %w", err)
+		}
+		client := mcp.NewClient(&mcp.Implementation{Name: "release-mcp-admin-" + name, Version: currentGeneratorVersion()}, nil)
+		clientSession, err := client.Connect(api.ctx, clientTransport)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to connect to the server: %w", err)
+		}
+		session = &adminSession{session: clientSession}
+		api.sessions[name] = session
+	}
+	session.calls++
+	session.lastUsed = time.Now()
+	return session, func() {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		session.calls--
+		session.lastUsed = time.Now()
+	}, nil
+}
+
+// expireSessions closes the sessions idle since before the timeout. Callers hold mu.
+func (api *adminAPI) expireSessions(now time.Time) {
+	for name, session := range api.sessions {
+		if now.Sub(session.lastUsed) > adminSessionIdleTimeout && !session.busy() {
+			_ = session.session.Close()
+			delete(api.sessions, name)
+		}
+	}
+}
+
+// evictSession closes the least recently used idle session and reports
+// whether one was closed. Callers hold mu.
+func (api *adminAPI) evictSession() bool {
+	oldest := ""
+	for name, session := range api.sessions {
+		if !session.busy() && (oldest == "" || session.lastUsed.Before(api.sessions[oldest].lastUsed)) {
+			oldest = name
+		}
+	}
+	if oldest == "" {
+		return false
+	}
+	_ = api.sessions[oldest].session.Close()
+	delete(api.sessions, oldest)
+	return true
+}
+
+func (api *adminAPI) listTools(w http.ResponseWriter, r *http.Request) {
+	session, done, err := api.session(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	defer done()
+	var tools []*mcp.Tool
+	for tool, err := range session.session.Tools(r.Context(), nil) {
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to list tools: %v", err), http.StatusInternalServerError)
+			return
+		}
+		tools = append(tools, tool)
+	}
+	writeAdminJSON(w, http.StatusOK, tools)
+}
+
+func (api *adminAPI) callTool(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	arguments := map[string]any{}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAdminRequest)).Decode(&arguments); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, fmt.Sprintf("tool arguments must be a JSON object: %v", err), http.StatusBadRequest)
+		return
+	}
+	// Long-running tools always run as background jobs, as the HTTP server
+	// times out responses long before they finish
+	if isAsync(name) {
+		arguments[asyncArg] = true
+	}
+	session, done, err := api.session(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	defer done()
+
+	result, err := session.session.CallTool(r.Context(), &mcp.CallToolParams{Name: name, Arguments: arguments})
+	if err != nil {
+		// Unknown tools and invalid arguments are protocol errors
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	response := AdminToolResult{Tool: name, IsError: result.IsError, StructuredContent: result.StructuredContent}
+	var text strings.Builder
+	for _, content := range result.Content {
+		if t, ok := content.(*mcp.TextContent); ok {
+			text.WriteString(t.Text)
+		}
+	}
+	response.Text = text.String()
+	response.PlanHash, _ = result.Meta[PlanHashMeta].(string)
+	// The job status is decoded as a JSON object on the client side
+	if job, ok := result.Meta["job"].(map[string]any); ok {
+		response.JobID, _ = job["id"].(string)
+	}
+	if response.JobID != "" {
+		api.mu.Lock()
+		session.jobs = append(slices.DeleteFunc(session.jobs, func(id string) bool {
+			j, err := backgroundJobs.get(id)
+			return err != nil || j.snapshot().State != jobRunning
+		}), response.JobID)
+		api.mu.Unlock()
+	}
+	writeAdminJSON(w, http.StatusOK, response)
+}
+
+// resource serves the JSON content of an MCP resource, formatting its URI
+// with the version of the request path when it has one
+func (api *adminAPI) resource(uri string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		uri := uri
+		if v := r.PathValue("version"); v != "" {
+			uri = fmt.Sprintf(uri, v)
+		}
+		session, done, err := api.session(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		defer done()
+		result, err := session.session.ReadResource(r.Context(), &mcp.ReadResourceParams{URI: uri})
+		if err != nil {
+			// The server is in process, reads only fail for unknown versions
+			http.Error(w, fmt.Sprintf("failed to read %s: %v", uri, err), http.StatusNotFound)
+			return
+		}
+		if len(result.Contents) == 0 {
+			http.Error(w, fmt.Sprintf("%s returned no content", uri), http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(result.Contents[0].Text))
+	}
+}
+
+func writeAdminJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
@@ -0,0 +1,238 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
+	mcp "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// artifactsKey is the key of the artifacts in the structured content and metadata of tool results
+const artifactsKey = "artifacts"
+
+// PushedBranch represents a branch pushed by a tool call
+type PushedBranch struct {
+	Repository string `json:"repository" jsonschema:"Repository path on its forge, or the remote URL when it has none"`
+	Branch     string `json:"branch"`
+}
+
+// StepStatus represents the status of a step of a tool call
+type StepStatus struct {
+	Name   string `json:"name"`
+	Status string `json:"status" jsonschema:"done, or failed for the step a failed call stopped at"`
+}
+
+// ToolArtifacts represents what a tool call created, for agents chaining follow-up actions
+type ToolArtifacts struct {
+	Files          []string       `json:"files,omitempty" jsonschema:"Paths of the files written"`
+	Branches       []PushedBranch `json:"branches,omitempty" jsonschema:"Branches pushed"`
+	ChangeRequests []string       `json:"change_requests,omitempty" jsonschema:"URLs of the pull and merge requests opened"`
+	Steps          []StepStatus   `json:"steps,omitempty" jsonschema:"Steps the call went through, in order"`
+}
+
+func (a ToolArtifacts) empty() bool {
+	return len(a.Files) == 0 && len(a.Branches) == 0 && len(a.ChangeRequests) == 0 && len(a.Steps) == 0
+}
+
+// runArtifacts collects the artifacts of a tool call across goroutines
+type runArtifacts struct {
+	mu        sync.Mutex
+	artifacts ToolArtifacts
+}
+
+type runArtifactsKey struct{}
+
+// withRunArtifacts returns a context collecting the artifacts of a tool call
+func withRunArtifacts(ctx context.Context) (context.Context, *runArtifacts) {
+	artifacts := &runArtifacts{}
+	return context.WithValue(ctx, runArtifactsKey{}, artifacts), artifacts
+}
+
+// runArtifactsFrom returns the artifacts of the tool call of the context. The
+// methods of the returned artifacts are no-ops when there is none.
+func runArtifactsFrom(ctx context.Context) *runArtifacts {
+	artifacts, _ := ctx.Value(runArtifactsKey{}).(*runArtifacts)
+	return artifacts
+}
+
+func (a *runArtifacts) recordFile(path string) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, file := range a.artifacts.Files {
+		if file == path {
+			return
+		}
+	}
+	a.artifacts.Files = append(a.artifacts.Files, path)
+}
+
+func (a *runArtifacts) recordBranch(branch PushedBranch) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.artifacts.Branches = append(a.artifacts.Branches, branch)
+}
+
+// resolvePush returns the branches of a git push command, resolving the
+// remote to the repository it points to. Other commands push nothing.
+func resolvePush(ctx context.Context, cmd *exec.Cmd) []PushedBranch {
+	if len(cmd.Args) < 2 || filepath.Base(cmd.Args[0]) != "git" || cmd.Args[1] != "push" {
+		return nil
+	}
+	remote, branches := pushedBranches(cmd.Args[2:])
+	if remote == "" {
+		return nil
+	}
+	repository := remote
+	if !strings.Contains(remote, ":") {
+		urlCmd := exec.CommandContext(ctx, "git", "remote", "get-url", remote)
+		urlCmd.Dir = cmd.Dir
+		if output, err := commandOutput(ctx, urlCmd); err == nil {
+			repository = strings.TrimSpace(string(output))
+		}
+	}
+	if path := forgeRepoPath(repository); path != "" {
+		repository = path
+	}
+
+	var pushed []PushedBranch
+	for _, branch := range branches {
+		pushed = append(pushed, PushedBranch{Repository: repository, Branch: branch})
+	}
+	return pushed
+}
+
+func (a *runArtifacts) recordChangeRequest(url string) {
+	if a == nil || url == "" {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.artifacts.ChangeRequests = append(a.artifacts.ChangeRequests, url)
+}
+
+// recordStep records the start of a step, completing the previous one
+func (a *runArtifacts) recordStep(name string) {
+	if a == nil || name == "" {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.artifacts.Steps = append(a.artifacts.Steps, StepStatus{Name: name, Status: "done"})
+}
+
+// snapshot returns the artifacts, marking the last step failed when the call failed
+func (a *runArtifacts) snapshot(failed bool) ToolArtifacts {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	artifacts := a.artifacts
+	artifacts.Steps = append([]StepStatus(nil), a.artifacts.Steps...)
+	if failed && len(artifacts.Steps) > 0 {
+		artifacts.Steps[len(artifacts.Steps)-1].Status = "failed"
+	}
+	return artifacts
+}
+
+// pushedBranches returns the remote and the destination branches of git push arguments
+func pushedBranches(args []string) (string, []string) {
+	remote := ""
+	var branches []string
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			continue
+		}
+		if remote == "" {
+			remote = arg
+			continue
+		}
+		src, dst, ok := strings.Cut(strings.TrimPrefix(arg, "+"), ":")
+		if !ok {
+			dst = src
+		}
+		if dst = strings.TrimPrefix(dst, "refs/heads/"); dst != "" && dst != "HEAD" {
+			branches = append(branches, dst)
+		}
+	}
+	return remote, branches
+}
+
+func (a ToolArtifacts) String() string {
+	var parts []string
+	for _, branch := range a.Branches {
+		parts = append(parts, fmt.Sprintf("pushed %s to %s", branch.Branch, branch.Repository))
+	}
+	for _, url := range a.ChangeRequests {
+		parts = append(parts, "opened "+url)
+	}
+	if len(a.Files) > 0 {
+		parts = append(parts, fmt.Sprintf("wrote %d files", len(a.Files)))
+	}
+	return "Artifacts: " + strings.Join(parts, ", ")
+}
+
+// withArtifactsSchema adds the artifacts property to a tool output schema
+func withArtifactsSchema(schema *jsonschema.Schema) *jsonschema.Schema {
+	artifacts, err := jsonschema.For[ToolArtifacts]()
+	if err != nil {
+		panic(fmt.Sprintf("failed to infer artifacts schema: %v", err))
+	}
+	if schema.Properties == nil {
+		schema.Properties = map[string]*jsonschema.Schema{}
+	}
+	schema.Properties[artifactsKey] = artifacts
+	return schema
+}
+
+// artifactsMiddleware collects the files, branches, pull and merge requests
+// and steps of every tool call and reports them under the artifacts key of the
+// structured content and of the result metadata
+func artifactsMiddleware(next mcp.MethodHandler[*mcp.ServerSession]) mcp.MethodHandler[*mcp.ServerSession] {
+	return func(ctx context.Context, session *mcp.ServerSession, method string, params mcp.Params) (mcp.Result, error) {
+		if _, ok := params.(*mcp.CallToolParamsFor[json.RawMessage]); method != "tools/call" || !ok {
+			return next(ctx, session, method, params)
+		}
+
+		ctx, collected := withRunArtifacts(ctx)
+		result, err := next(ctx, session, method, params)
+		toolResult, ok := result.(*mcp.CallToolResult)
+		if err != nil || !ok {
+			return result, err
+		}
+
+		artifacts := collected.snapshot(toolResult.IsError)
+		if artifacts.empty() {
+			return toolResult, nil
+		}
+		if toolResult.Meta == nil {
+			toolResult.Meta = mcp.Meta{}
+		}
+		toolResult.Meta[artifactsKey] = artifacts
+
+		// Structured content is an object of the output schema, the artifacts are added to it
+		content := map[string]any{}
+		if toolResult.StructuredContent != nil {
+			encoded, err := json.Marshal(toolResult.StructuredContent)
+			if err != nil || json.Unmarshal(encoded, &content) != nil {
+				return toolResult, nil
+			}
+		}
+		content[artifactsKey] = artifacts
+		toolResult.StructuredContent = content
+
+		if len(artifacts.Branches) > 0 || len(artifacts.ChangeRequests) > 0 || len(artifacts.Files) > 0 {
+			toolResult.Content = append(toolResult.Content, &mcp.TextContent{Text: artifacts.String()})
+		}
+		return toolResult, nil
+	}
+}
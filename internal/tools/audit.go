@@ -0,0 +1,212 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	mcp "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// stateDirEnv is the environment variable overriding where the server keeps its state
+	stateDirEnv = "RELEASE_MCP_STATE_DIR"
+
+	auditLogFile       = "audit.jsonl"
+	releaseActionsFile = "RELEASE_ACTIONS.md"
+
+	// unversionedActions groups actions of tools that do not target a version
+	unversionedActions = "unversioned"
+)
+
+// AuditEvent represents a structured record of an action performed by the server
+type AuditEvent struct {
+	Time      time.Time      `json:"time"`
+	Version   string         `json:"version"`
+	Tool      string         `json:"tool"`
+	Arguments map[string]any `json:"arguments,omitempty"`
+	Success   bool           `json:"success"`
+	Summary   string         `json:"summary"`
+}
+
+var auditMu sync.Mutex
+
+// stateDir returns the directory where the server persists its state
+func stateDir() string {
+	if dir := os.Getenv(stateDirEnv); dir != "" {
+		return dir
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".release-mcp")
+	}
+	return filepath.Join(os.TempDir(), "release-mcp")
+}
+
+// auditVersion extracts the version an action targets from its arguments
+func auditVersion(args map[string]any) string {
+	for _, key := range []string{"minor_version", "version"} {
+		if v, ok := args[key].(string); ok && isSafeVersionDir(v) {
+			if patch, ok := args["patch_version"].(string); ok && patch != "" {
+				return v + "." + patch
+			}
+			return v
+		}
+	}
+	return unversionedActions
+}
+
+// isSafeVersionDir reports whether a version can be used as a state directory name
+func isSafeVersionDir(version string) bool {
+	return version != "" && version != "." && version != ".." && !strings.ContainsAny(version, `/\`)
+}
+
+// recordAuditEvent appends the event to the version's audit log and to its
+// human-readable RELEASE_ACTIONS.md changelog
+func recordAuditEvent(event AuditEvent) error {
+	auditMu.Lock()
+	defer auditMu.Unlock()
+
+	dir := filepath.Join(stateDir(), event.Version)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create audit directory: %w", err)
+	}
+
+	line, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit event: %w", err)
+	}
+	if err := appendToFile(filepath.Join(dir, auditLogFile), string(line)+"\n"); err != nil {
+		return err
+	}
+
+	actionsPath := filepath.Join(dir, releaseActionsFile)
+	var entry string
+	if _, err := os.Stat(actionsPath); os.IsNotExist(err) {
+		entry = fmt.Sprintf("# Release actions for %s\n\n", event.Version)
+	}
+	entry += formatAuditEvent(event)
+	return appendToFile(actionsPath, entry)
+}
+
+func appendToFile(path, content string) error {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(content); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// formatAuditEvent renders an audit event as a Markdown changelog entry
+func formatAuditEvent(event AuditEvent) string {
+	status := "✅"
+	if !event.Success {
+		status = "❌"
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "- %s %s `%s`", event.Time.UTC().Format("2006-01-02 15:04:05 UTC"), status, event.Tool)
+
+	var keys []string
+	for key := range event.Arguments {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var args []string
+	for _, key := range keys {
+		args = append(args, fmt.Sprintf("%s=%v", key, event.Arguments[key]))
+	}
+	if len(args) > 0 {
+		fmt.Fprintf(&sb, " (%s)", strings.Join(args, ", "))
+	}
+
+	summary := strings.TrimSpace(strings.SplitN(event.Summary, "\n", 2)[0])
+	if summary != "" {
+		fmt.Fprintf(&sb, ": %s", summary)
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// readReleaseActions returns the RELEASE_ACTIONS.md changelog of a version
+func readReleaseActions(version string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(stateDir(), version, releaseActionsFile))
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+// auditMiddleware records an audit event for every tool call handled by the server
+func auditMiddleware(next mcp.MethodHandler[*mcp.ServerSession]) mcp.MethodHandler[*mcp.ServerSession] {
+	return func(ctx context.Context, session *mcp.ServerSession, method string, params mcp.Params) (mcp.Result, error) {
+		callParams, ok := params.(*mcp.CallToolParamsFor[json.RawMessage])
+		if method != "tools/call" || !ok {
+			return next(ctx, session, method, params)
+		}
+
+		var args map[string]any
+		_ = json.Unmarshal(callParams.Arguments, &args)
+
+		// Events published by the tool are attributed to this call
+		ctx = withEventSource(ctx, callParams.Name, auditVersion(args))
+		result, err := next(ctx, session, method, params)
+
+		event := AuditEvent{
+			Time:      time.Now(),
+			Version:   auditVersion(args),
+			Tool:      callParams.Name,
+			Arguments: args,
+			Success:   err == nil,
+		}
+		if err != nil {
+			event.Summary = err.Error()
+		} else if toolResult, ok := result.(*mcp.CallToolResult); ok {
+			event.Success = !toolResult.IsError
+			for _, content := range toolResult.Content {
+				if text, ok := content.(*mcp.TextContent); ok {
+					event.Summary = text.Text
+					break
+				}
+			}
+		}
+
+		if auditErr := publish(ctx, Event{Type: EventToolCompleted, Data: event}); auditErr != nil {
+			slog.ErrorContext(ctx, "Failed to record audit event", "error", auditErr)
+		}
+		return result, err
+	}
+}
+
+// addReleaseActionsResource exposes the per-version changelog as an MCP resource
+func addReleaseActionsResource(s *mcp.Server) {
+	s.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "release-actions",
+		Description: "Human-readable changelog of every action the server performed for a version",
+		MIMEType:    "text/markdown",
+		URITemplate: "release://{version}/actions",
+	}, func(ctx context.Context, session *mcp.ServerSession, params *mcp.ReadResourceParams) (*mcp.ReadResourceResult, error) {
+		version := strings.TrimSuffix(strings.TrimPrefix(params.URI, "release://"), "/actions")
+		if !isSafeVersionDir(version) {
+			return nil, mcp.ResourceNotFoundError(params.URI)
+		}
+		content, err := readReleaseActions(version)
+		if err != nil {
+			return nil, mcp.ResourceNotFoundError(params.URI)
+		}
+		return &mcp.ReadResourceResult{
+			Contents: []*mcp.ResourceContents{{URI: params.URI, MIMEType: "text/markdown", Text: content}},
+		}, nil
+	})
+}
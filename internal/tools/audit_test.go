@@ -0,0 +1,27 @@
+package tools
+
+import "testing"
+
+func TestAuditVersion(t *testing.T) {
+	tests := []struct {
+		name string
+		args map[string]any
+		want string
+	}{
+		{name: "no version", args: map[string]any{"repository": "operator"}, want: unversionedActions},
+		{name: "minor version", args: map[string]any{"minor_version": "1.21"}, want: "1.21"},
+		{name: "version", args: map[string]any{"version": "1.21"}, want: "1.21"},
+		{name: "minor version first", args: map[string]any{"minor_version": "1.21", "version": "1.20"}, want: "1.21"},
+		{name: "patch version", args: map[string]any{"minor_version": "1.21", "patch_version": "3"}, want: "1.21.3"},
+		{name: "path is not a version", args: map[string]any{"minor_version": "../1.21"}, want: unversionedActions},
+		{name: "unsafe minor version falls back to version", args: map[string]any{"minor_version": "..", "version": "1.20"}, want: "1.20"},
+		{name: "not a string", args: map[string]any{"version": 1.21}, want: unversionedActions},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := auditVersion(tt.args); got != tt.want {
+				t.Errorf("auditVersion(%v) = %q, want %q", tt.args, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,285 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// autoReleaseLabel makes a ReleasePlan release every snapshot passing its tests
+const autoReleaseLabel = "release.appstudio.openshift.io/auto-release"
+
+// templateRPLabels are the ReleasePlan labels set by the rp template itself
+var templateRPLabels = map[string]bool{
+	autoReleaseLabel: true,
+	"release.appstudio.openshift.io/standing-attribution": true,
+	"release.appstudio.openshift.io/releasePlanAdmission": true,
+}
+
+var releasePlanGVR = schema.GroupVersionResource{
+	Group:    "appstudio.redhat.com",
+	Version:  "v1alpha1",
+	Resource: "releaseplans",
+}
+
+// validateRPLabels checks extra ReleasePlan labels are valid Kubernetes
+// labels that do not override those of the template
+func validateRPLabels(labels map[string]string) error {
+	for key, value := range labels {
+		if templateRPLabels[key] {
+			return fmt.Errorf("label %s is set by the rp template, use auto_release for %s", key, autoReleaseLabel)
+		}
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return fmt.Errorf("invalid label key %q: %s", key, strings.Join(errs, ", "))
+		}
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			return fmt.Errorf("invalid value %q of label %s: %s", value, key, strings.Join(errs, ", "))
+		}
+	}
+	return nil
+}
+
+// AutoReleaseConfig represents the configuration for toggling the auto-release label of ReleasePlans
+type AutoReleaseConfig struct {
+	MinorVersion string
+	Enabled      bool
+	Components   []string // Only the ReleasePlans of these applications, all when empty
+	Environments []string // Only the ReleasePlans of these environments, all when empty
+	Cluster      bool     // Patch the ReleasePlans on the cluster instead of opening a merge request
+	RepoPath     string
+	DryRun       bool // Only report the ReleasePlans that would change
+}
+
+// AutoReleaseChange represents the auto-release label of a ReleasePlan
+type AutoReleaseChange struct {
+	Name     string `json:"name"`
+	Path     string `json:"path,omitempty" jsonschema:"File of the ReleasePlan in konflux-release-data, unset for cluster patches"`
+	Previous string `json:"previous" jsonschema:"Value of the label before the change, empty when unset"`
+	Changed  bool   `json:"changed"`
+}
+
+// AutoReleaseResult represents the structured result of toggle-auto-release
+type AutoReleaseResult struct {
+	MinorVersion    string              `json:"minor_version"`
+	Enabled         bool                `json:"enabled"`
+	Mode            string              `json:"mode" jsonschema:"merge-request or cluster"`
+	DryRun          bool                `json:"dry_run"`
+	ReleasePlans    []AutoReleaseChange `json:"release_plans"`
+	Changed         int                 `json:"changed"`
+	BranchName      string              `json:"branch_name,omitempty"`
+	MergeRequestURL string              `json:"merge_request_url,omitempty"`
+}
+
+// toggleAutoRelease sets the auto-release label of the ReleasePlans of a
+// version, in konflux-release-data through a merge request, or directly on
+// the cluster
+func toggleAutoRelease(ctx context.Context, config AutoReleaseConfig) (AutoReleaseResult, error) {
+	result := AutoReleaseResult{MinorVersion: config.MinorVersion, Enabled: config.Enabled, Mode: "merge-request", DryRun: config.DryRun}
+	if config.Cluster {
+		result.Mode = "cluster"
+	}
+	components, environments := make(map[string]bool), make(map[string]bool)
+	for _, component := range config.Components {
+		components[component] = true
+	}
+	for _, env := range config.Environments {
+		environments[env] = true
+	}
+	planName := regexp.MustCompile(`^` + regexp.QuoteMeta(product().Prefix) + `-(.+)-` + regexp.QuoteMeta(config.MinorVersion) + `-([a-z]+)-release-as-op$`)
+	selected := func(name string) bool {
+		match := planName.FindStringSubmatch(name)
+		return match != nil && (len(components) == 0 || components[match[1]]) && (len(environments) == 0 || environments[match[2]])
+	}
+	value := strconv.FormatBool(config.Enabled)
+
+	var err error
+	if config.Cluster {
+		err = toggleClusterAutoRelease(ctx, &result, selected, value)
+	} else {
+		err = toggleRepoAutoRelease(ctx, config, &result, selected, value)
+	}
+	if err != nil {
+		return result, err
+	}
+	if len(result.ReleasePlans) == 0 {
+		return result, fmt.Errorf("no ReleasePlan of %s matches the components and environments", config.MinorVersion)
+	}
+	return result, nil
+}
+
+// toggleClusterAutoRelease patches the label of the ReleasePlans in the tenant namespace
+func toggleClusterAutoRelease(ctx context.Context, result *AutoReleaseResult, selected func(string) bool, value string) error {
+	dc, err := dynamicClient(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+	plans, err := dc.Resource(releasePlanGVR).Namespace(tenantNamespace()).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list ReleasePlans: %w", err)
+	}
+	sort.Slice(plans.Items, func(i, j int) bool { return plans.Items[i].GetName() < plans.Items[j].GetName() })
+
+	patch, err := json.Marshal(map[string]any{"metadata": map[string]any{"labels": map[string]string{autoReleaseLabel: value}}})
+	if err != nil {
+		return err
+	}
+	for _, plan := range plans.Items {
+		if !selected(plan.GetName()) {
+			continue
+		}
+		change := AutoReleaseChange{Name: plan.GetName(), Previous: plan.GetLabels()[autoReleaseLabel]}
+		change.Changed = change.Previous != value
+		if change.Changed && !result.DryRun {
+			if _, err := dc.Resource(releasePlanGVR).Namespace(tenantNamespace()).Patch(ctx, change.Name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+				return fmt.Errorf("failed to patch ReleasePlan %s: %w", change.Name, err)
+			}
+		}
+		if change.Changed {
+			result.Changed++
+		}
+		result.ReleasePlans = append(result.ReleasePlans, change)
+	}
+	return nil
+}
+
+// toggleRepoAutoRelease edits the label of the ReleasePlan files of
+// konflux-release-data and opens a merge request with the change
+func toggleRepoAutoRelease(ctx context.Context, config AutoReleaseConfig, result *AutoReleaseResult, selected func(string) bool, value string) error {
+	rpaConfig := RPAConfig{MinorVersion: config.MinorVersion, RepoPath: config.RepoPath}
+	if err := cloneKonfluxRepo(ctx, rpaConfig); err != nil {
+		return fmt.Errorf("failed to clone konflux-release-data repository: %w", err)
+	}
+	entries, err := os.ReadDir(filepath.Join(config.RepoPath, tenantReleasePlanDir()))
+	if err != nil {
+		return fmt.Errorf("failed to read directory %s: %w", tenantReleasePlanDir(), err)
+	}
+
+	updated := make(map[string][]byte)
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".yaml")
+		if entry.IsDir() || !selected(name) {
+			continue
+		}
+		path := filepath.Join(tenantReleasePlanDir(), entry.Name())
+		content, err := os.ReadFile(filepath.Join(config.RepoPath, path))
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", entry.Name(), err)
+		}
+		patched, previous, ok := setAutoReleaseLabel(content, value)
+		if !ok {
+			return fmt.Errorf("%s has no metadata labels", path)
+		}
+		change := AutoReleaseChange{Name: name, Path: path, Previous: previous, Changed: previous != value}
+		if change.Changed {
+			updated[path] = patched
+			result.Changed++
+		}
+		result.ReleasePlans = append(result.ReleasePlans, change)
+	}
+	if config.DryRun || len(updated) == 0 {
+		return nil
+	}
+
+	state := "enabled"
+	if !config.Enabled {
+		state = "disabled"
+	}
+	branchName, err := createPushBranch(ctx, config.RepoPath, fmt.Sprintf("auto-release-v%s-%s", config.MinorVersion, state))
+	if err != nil {
+		return err
+	}
+	rpaConfig.Branch = branchName
+	result.BranchName = branchName
+	for path, content := range updated {
+		if err := writeFile(ctx, filepath.Join(config.RepoPath, path), content, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+	if err := runBuildManifests(ctx, rpaConfig); err != nil {
+		return fmt.Errorf("failed to run build-manifests.sh: %w", err)
+	}
+
+	rpaConfig.Title = fmt.Sprintf("Set auto-release to %s on the ReleasePlans of v%s", value, config.MinorVersion)
+	effect := "only released on demand"
+	if config.Enabled {
+		effect = "released automatically"
+	}
+	var description strings.Builder
+	fmt.Fprintf(&description, "Sets the `%s` label of the ReleasePlans of %s to `%s`, so snapshots passing their tests are %s.\n\n", autoReleaseLabel, config.MinorVersion, value, effect)
+	for _, change := range result.ReleasePlans {
+		if change.Changed {
+			fmt.Fprintf(&description, "- %s\n", change.Name)
+		}
+	}
+	rpaConfig.Description = description.String()
+	mrURL, err := createAndPushMR(ctx, rpaConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create and push merge request: %w", err)
+	}
+	result.MergeRequestURL = mrURL
+	return nil
+}
+
+// setAutoReleaseLabel sets the auto-release label of a ReleasePlan manifest,
+// adding it to its labels when missing. It returns the updated content, the
+// previous value of the label and whether the manifest has labels.
+func setAutoReleaseLabel(content []byte, value string) ([]byte, string, bool) {
+	lines := strings.Split(string(content), "\n")
+	labels := -1
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if labels < 0 && trimmed == "labels:" && strings.HasPrefix(line, "  ") && !strings.HasPrefix(line, "   ") {
+			labels = i
+			continue
+		}
+		if previous, ok := strings.CutPrefix(trimmed, autoReleaseLabel+":"); ok && labels >= 0 {
+			previous = strings.Trim(strings.TrimSpace(previous), `"'`)
+			lines[i] = fmt.Sprintf(`%s%s: "%s"`, leadingSpaces(line), autoReleaseLabel, value)
+			return []byte(strings.Join(lines, "\n")), previous, true
+		}
+	}
+	if labels < 0 {
+		return content, "", false
+	}
+	label := fmt.Sprintf(`    %s: "%s"`, autoReleaseLabel, value)
+	lines = append(lines[:labels+1], append([]string{label}, lines[labels+1:]...)...)
+	return []byte(strings.Join(lines, "\n")), "", true
+}
+
+func formatAutoRelease(result AutoReleaseResult) string {
+	var sb strings.Builder
+	verb := "Set"
+	if result.DryRun {
+		verb = "Dry run: would set"
+	}
+	fmt.Fprintf(&sb, "%s auto-release to %t on the ReleasePlans of %s (%s):\n", verb, result.Enabled, result.MinorVersion, result.Mode)
+	for _, change := range result.ReleasePlans {
+		if change.Changed {
+			previous := change.Previous
+			if previous == "" {
+				previous = "unset"
+			}
+			fmt.Fprintf(&sb, "✓ %s: %s → %t\n", change.Name, previous, result.Enabled)
+		} else {
+			fmt.Fprintf(&sb, "- %s: already %s\n", change.Name, change.Previous)
+		}
+	}
+	if result.MergeRequestURL != "" {
+		fmt.Fprintf(&sb, "\nBranch: %s\nMerge request: %s\n", result.BranchName, result.MergeRequestURL)
+	}
+	if result.Mode == "cluster" && result.Changed > 0 && !result.DryRun {
+		sb.WriteString("\nThe cluster change is reverted by the next sync of konflux-release-data unless the files are changed too\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,223 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// releaseBlockerLabel is the label marking issues that block a release
+const releaseBlockerLabel = "release-blocker"
+
+// defaultJiraURL is the JIRA instance searched when JIRA_URL is not set
+const defaultJiraURL = "https://issues.redhat.com"
+
+// BlockingIssuesConfig represents the configuration for searching release blockers
+type BlockingIssuesConfig struct {
+	Version        string
+	GitHubOrgs     []string
+	JiraProjects   []string
+	JiraFixVersion string // Defaults to Version
+}
+
+// BlockingIssue represents an open issue blocking the release
+type BlockingIssue struct {
+	Source string `json:"source"`
+	Key    string `json:"key"`
+	Title  string `json:"title"`
+	URL    string `json:"url"`
+	Status string `json:"status"`
+}
+
+func findBlockingIssues(ctx context.Context, config BlockingIssuesConfig) ([]BlockingIssue, error) {
+	var issues []BlockingIssue
+
+	if len(config.GitHubOrgs) > 0 {
+		gh, err := newGitHubClient()
+		if err != nil {
+			return nil, err
+		}
+		for _, org := range config.GitHubOrgs {
+			found, err := searchGitHubBlockers(ctx, gh, org, config.Version)
+			if err != nil {
+				return nil, fmt.Errorf("failed to search GitHub org %s: %w", org, err)
+			}
+			issues = append(issues, found...)
+		}
+	}
+
+	// Without a token, the JIRA projects searched by default are skipped
+	// rather than failing the GitHub results
+	if len(config.JiraProjects) > 0 && os.Getenv("JIRA_TOKEN") == "" {
+		slog.WarnContext(ctx, "JIRA_TOKEN is not set, skipping the JIRA search for release blockers", "projects", config.JiraProjects)
+	} else if len(config.JiraProjects) > 0 {
+		fixVersion := config.JiraFixVersion
+		if fixVersion == "" {
+			fixVersion = config.Version
+		}
+		found, err := searchJiraBlockers(ctx, config.JiraProjects, fixVersion)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search JIRA: %w", err)
+		}
+		issues = append(issues, found...)
+	}
+
+	return issues, nil
+}
+
+// searchGitHubBlockers returns open release-blocker issues of org targeting the version,
+// either through their milestone or a version label
+func searchGitHubBlockers(ctx context.Context, gh *githubClient, org, version string) ([]BlockingIssue, error) {
+	query := fmt.Sprintf("org:%s is:issue is:open label:%s", org, releaseBlockerLabel)
+
+	var resp struct {
+		Items []struct {
+			Number    int    `json:"number"`
+			Title     string `json:"title"`
+			HTMLURL   string `json:"html_url"`
+			State     string `json:"state"`
+			Milestone *struct {
+				Title string `json:"title"`
+			} `json:"milestone"`
+			Labels []struct {
+				Name string `json:"name"`
+			} `json:"labels"`
+		} `json:"items"`
+	}
+	if err := gh.do(ctx, http.MethodGet, "/search/issues?per_page=100&q="+url.QueryEscape(query), nil, &resp); err != nil {
+		return nil, err
+	}
+
+	versionToken := versionTokenPattern(version)
+	var issues []BlockingIssue
+	for _, item := range resp.Items {
+		targets := item.Milestone != nil && versionToken.MatchString(item.Milestone.Title)
+		for _, label := range item.Labels {
+			if versionToken.MatchString(label.Name) {
+				targets = true
+			}
+		}
+		if !targets {
+			continue
+		}
+
+		// html_url is https://github.com/<org>/<repo>/issues/<n>
+		repo := strings.TrimPrefix(item.HTMLURL, "https://github.com/")
+		repo = strings.Split(repo, "/issues/")[0]
+		issues = append(issues, BlockingIssue{
+			Source: "github",
+			Key:    fmt.Sprintf("%s#%d", repo, item.Number),
+			Title:  item.Title,
+			URL:    item.HTMLURL,
+			Status: item.State,
+		})
+	}
+	return issues, nil
+}
+
+// versionTokenPattern matches the version as a whole token of a milestone or
+// label, optionally prefixed by v: 1.2 matches "v1.2", "1.2.x" and
+// "release 1.2", but not "1.21", "1.20" or "11.2"
+func versionTokenPattern(version string) *regexp.Regexp {
+	return regexp.MustCompile(`(?:^|[^0-9.])v?` + regexp.QuoteMeta(version) + `(?:$|\.?[^0-9.]|\.$)`)
+}
+
+// jqlString quotes a value as a JQL string literal
+func jqlString(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+// searchJiraBlockers returns unresolved release-blocker issues of the projects fixed in fixVersion
+func searchJiraBlockers(ctx context.Context, projects []string, fixVersion string) ([]BlockingIssue, error) {
+	token := os.Getenv("JIRA_TOKEN")
+	if token == "" {
+		return nil, fmt.Errorf("JIRA_TOKEN environment variable must be set")
+	}
+	baseURL := os.Getenv("JIRA_URL")
+	if baseURL == "" {
+		baseURL = defaultJiraURL
+	}
+
+	client, err := httpClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+
+	quoted := make([]string, 0, len(projects))
+	for _, project := range projects {
+		quoted = append(quoted, jqlString(project))
+	}
+	jql := fmt.Sprintf(`project in (%s) AND labels = %s AND fixVersion = %s AND statusCategory != Done`,
+		strings.Join(quoted, ", "), jqlString(releaseBlockerLabel), jqlString(fixVersion))
+	searchURL := fmt.Sprintf("%s/rest/api/2/search?fields=summary,status&maxResults=200&jql=%s",
+		strings.TrimSuffix(baseURL, "/"), url.QueryEscape(jql))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, searchURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("JIRA search failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JIRA response: %w", err)
+	}
+	runUsageFrom(ctx).recordAPICall(len(body))
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("JIRA search returned %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Issues []struct {
+			Key    string `json:"key"`
+			Fields struct {
+				Summary string `json:"summary"`
+				Status  struct {
+					Name string `json:"name"`
+				} `json:"status"`
+			} `json:"fields"`
+		} `json:"issues"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode JIRA response: %w", err)
+	}
+
+	var issues []BlockingIssue
+	for _, issue := range result.Issues {
+		issues = append(issues, BlockingIssue{
+			Source: "jira",
+			Key:    issue.Key,
+			Title:  issue.Fields.Summary,
+			URL:    fmt.Sprintf("%s/browse/%s", strings.TrimSuffix(baseURL, "/"), issue.Key),
+			Status: issue.Fields.Status.Name,
+		})
+	}
+	return issues, nil
+}
+
+func formatBlockingIssues(version string, issues []BlockingIssue) string {
+	if len(issues) == 0 {
+		return fmt.Sprintf("No release-blocking issues found for %s", version)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d release-blocking issue(s) found for %s:\n", len(issues), version)
+	for _, issue := range issues {
+		fmt.Fprintf(&sb, "- [%s] %s: %s (%s) %s\n", issue.Source, issue.Key, issue.Title, issue.Status, issue.URL)
+	}
+	return sb.String()
+}
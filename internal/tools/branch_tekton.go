@@ -0,0 +1,291 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const (
+	// tektonDir is the directory of a repository holding its Konflux PipelineRuns
+	tektonDir = ".tekton"
+
+	// defaultSourceVersion is the version the Konflux names of the source branches carry
+	defaultSourceVersion = "next"
+)
+
+// BranchTektonConfig represents the configuration for verifying the .tekton
+// PipelineRuns of the release branches
+type BranchTektonConfig struct {
+	MinorVersion  string
+	SourceVersion string   // Version of the Konflux names on the source branch, next when empty
+	Repositories  []string // Names of the release repositories to check, all of them when empty
+	WorkDir       string
+	DryRun        bool // Only report the changes, without pushing or opening pull requests
+}
+
+// BranchTektonRepo represents the .tekton PipelineRuns of the release branch of a repository
+type BranchTektonRepo struct {
+	Repository string   `json:"repository"`
+	Status     string   `json:"status" jsonschema:"up to date, updated when a pull request was opened, outdated in dry runs, missing branch, missing .tekton, or failed"`
+	Files      []string `json:"files,omitempty" jsonschema:"PipelineRun files that needed changes"`
+	Unfiltered []string `json:"unfiltered,omitempty" jsonschema:"PipelineRun files without a target branch filter, which run for every branch"`
+	PRURL      string   `json:"pr_url,omitempty"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// BranchTektonResult represents the structured result of verify-branch-build-yaml
+type BranchTektonResult struct {
+	Branch       string             `json:"branch"`
+	DryRun       bool               `json:"dry_run"`
+	Repositories []BranchTektonRepo `json:"repositories"`
+}
+
+// tektonBranchFilter matches the target branch of the PipelinesAsCode CEL
+// expressions and on-target-branch annotations
+var tektonBranchFilter = regexp.MustCompile(`target_branch\s*==\s*["']([^"']+)["']|on-target-branch:\s*["']?\[([^\]]*)\]`)
+
+// verifyBranchTekton checks that the .tekton PipelineRuns of the release
+// branch of every repository run for the release branch and build into the
+// applications of the version, and opens a pull request fixing the ones that
+// still carry the filters and names of the source branch
+func verifyBranchTekton(ctx context.Context, config BranchTektonConfig) (BranchTektonResult, error) {
+	result := BranchTektonResult{Branch: fmt.Sprintf("release-v%s.x", config.MinorVersion), DryRun: config.DryRun}
+	if config.MinorVersion == "" {
+		return result, fmt.Errorf("minor version is required")
+	}
+	if config.SourceVersion == "" {
+		config.SourceVersion = defaultSourceVersion
+	}
+
+	var repos []Repository
+	for _, repo := range releaseRepositories() {
+		if !repo.Skip && (len(config.Repositories) == 0 || slices.Contains(config.Repositories, repo.Name)) {
+			repos = append(repos, repo)
+		}
+	}
+	if len(repos) == 0 {
+		return result, fmt.Errorf("no release repository matches %s", strings.Join(config.Repositories, ", "))
+	}
+
+	var provider VCSProvider
+	if !config.DryRun {
+		var err error
+		if provider, err = vcsProvider(githubHost); err != nil {
+			return result, err
+		}
+	}
+	for i, repo := range repos {
+		reportProgress(ctx, float64(i), float64(len(repos)), "Checking .tekton of "+repo.Name)
+		outcome := verifyRepoTekton(ctx, config, provider, repo, result.Branch)
+		result.Repositories = append(result.Repositories, outcome)
+	}
+	return result, nil
+}
+
+// verifyRepoTekton checks and updates the .tekton PipelineRuns of the release branch of a repository
+func verifyRepoTekton(ctx context.Context, config BranchTektonConfig, provider VCSProvider, repo Repository, branch string) BranchTektonRepo {
+	outcome := BranchTektonRepo{Repository: repo.Name}
+	fail := func(err error) BranchTektonRepo {
+		outcome.Status, outcome.Error = "failed", err.Error()
+		return outcome
+	}
+
+	lsRemoteCmd, err := gitCommand(ctx, githubHost, "ls-remote", "--exit-code", "--heads", repo.RepoURL, branch)
+	if err != nil {
+		return fail(err)
+	}
+	if err := runCommand(ctx, lsRemoteCmd); err != nil {
+		// ls-remote exits with 2 when no branch matches
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 2 {
+			outcome.Status = "missing branch"
+			return outcome
+		}
+		return fail(fmt.Errorf("failed to list the branches of %s: %w", repo.Name, err))
+	}
+
+	repoDir := filepath.Join(config.WorkDir, repo.Name)
+	cloneCmd, err := gitCommand(ctx, githubHost, "clone", "--depth", "1", "-b", branch, repo.RepoURL, repoDir)
+	if err != nil {
+		return fail(err)
+	}
+	var cloneStderr bytes.Buffer
+	cloneCmd.Stderr = &cloneStderr
+	if err := runCommand(ctx, cloneCmd); err != nil {
+		return fail(fmt.Errorf("failed to clone %s: %v: %s", branch, err, strings.TrimSpace(cloneStderr.String())))
+	}
+	runUsageFrom(ctx).recordClone(repoDir)
+
+	entries, err := os.ReadDir(filepath.Join(repoDir, tektonDir))
+	if os.IsNotExist(err) {
+		outcome.Status = "missing .tekton"
+		return outcome
+	}
+	if err != nil {
+		return fail(fmt.Errorf("failed to read %s: %w", tektonDir, err))
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || (filepath.Ext(name) != ".yaml" && filepath.Ext(name) != ".yml") {
+			continue
+		}
+		file := filepath.Join(repoDir, tektonDir, name)
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return fail(fmt.Errorf("failed to read %s: %w", name, err))
+		}
+		if !bytes.Contains(content, []byte("kind: PipelineRun")) {
+			continue
+		}
+		if !tektonBranchFilter.Match(content) {
+			outcome.Unfiltered = append(outcome.Unfiltered, filepath.Join(tektonDir, name))
+		}
+		updated := retargetTektonPipelineRun(string(content), repo.SourceBranch, branch, config.SourceVersion, config.MinorVersion)
+		if updated == string(content) {
+			continue
+		}
+		if err := writeFile(ctx, file, []byte(updated), 0644); err != nil {
+			return fail(fmt.Errorf("failed to write %s: %w", name, err))
+		}
+		outcome.Files = append(outcome.Files, filepath.Join(tektonDir, name))
+	}
+	sort.Strings(outcome.Files)
+	sort.Strings(outcome.Unfiltered)
+
+	switch {
+	case len(outcome.Files) == 0:
+		outcome.Status = "up to date"
+		return outcome
+	case config.DryRun:
+		outcome.Status = "outdated"
+		return outcome
+	}
+
+	prBranch := "update-tekton-" + branch
+	commitMsg := fmt.Sprintf("Update .tekton PipelineRuns for %s", branch)
+	for _, args := range [][]string{{"checkout", "-B", prBranch}, {"add", tektonDir}, {"commit", "-m", commitMsg}} {
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Dir = repoDir
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		if err := runCommand(ctx, cmd); err != nil {
+			return fail(fmt.Errorf("git %s failed: %v: %s", args[0], err, strings.TrimSpace(stderr.String())))
+		}
+	}
+	pushCmd, err := gitCommand(ctx, githubHost, "push", "-f", "origin", prBranch)
+	if err != nil {
+		return fail(err)
+	}
+	pushCmd.Dir = repoDir
+	var pushStderr bytes.Buffer
+	pushCmd.Stderr = &pushStderr
+	if err := runCommand(ctx, pushCmd); err != nil {
+		return fail(fmt.Errorf("failed to push %s: %v: %s", prBranch, err, strings.TrimSpace(pushStderr.String())))
+	}
+
+	// The push updated the pull request of a previous run that is still open
+	open, err := provider.ListChangeRequests(ctx, forgeRepoPath(repo.RepoURL))
+	if err != nil {
+		return fail(fmt.Errorf("failed to list pull requests: %w", err))
+	}
+	for _, request := range open {
+		if request.SourceBranch == prBranch && request.TargetBranch == branch {
+			outcome.Status, outcome.PRURL = "updated", request.URL
+			return outcome
+		}
+	}
+
+	body, err := renderNotification("branch-tekton-pr", map[string]any{
+		"Branch":       branch,
+		"SourceBranch": repo.SourceBranch,
+		"MinorVersion": config.MinorVersion,
+		"Files":        outcome.Files,
+	})
+	if err != nil {
+		return fail(err)
+	}
+	pr, err := provider.OpenChangeRequest(ctx, forgeRepoPath(repo.RepoURL), ChangeRequest{
+		Title:        fmt.Sprintf("[%s] %s", branch, commitMsg),
+		Body:         body,
+		SourceBranch: prBranch,
+		TargetBranch: branch,
+	})
+	if err != nil {
+		return fail(fmt.Errorf("failed to open pull request: %w", err))
+	}
+	outcome.Status, outcome.PRURL = "updated", pr.URL
+	return outcome
+}
+
+// Lines of a PipelineRun carrying Konflux names
+var (
+	tektonKonfluxLabel     = regexp.MustCompile(`^\s*appstudio\.openshift\.io/(application|component):`)
+	tektonMetadataName     = regexp.MustCompile(`^  name:`)
+	tektonOutputImageParam = regexp.MustCompile(`^\s*(- )?name:\s*["']?output-image["']?\s*$`)
+	tektonParamValue       = regexp.MustCompile(`^\s*value:`)
+)
+
+// retargetTektonPipelineRun points the branch filters of a PipelineRun from
+// the source branch to the release branch, and replaces the source version in
+// the Konflux names it carries: the application and component labels, the
+// PipelineRun name and the output image (e.g., tektoncd-core-next-controller
+// becomes tektoncd-core-1.21-controller). The rest of the file, such as
+// references to other next resources, is kept as is.
+func retargetTektonPipelineRun(content, sourceBranch, branch, sourceVersion, minorVersion string) string {
+	// Only whole branch names, a filter on next-feature is left alone
+	source := regexp.MustCompile(`(^|[\s"',\[])` + regexp.QuoteMeta(sourceBranch) + `([\s"',\]]|$)`)
+	content = tektonBranchFilter.ReplaceAllStringFunc(content, func(match string) string {
+		return source.ReplaceAllString(match, "${1}"+branch+"${2}")
+	})
+
+	version := regexp.MustCompile(`([a-z0-9])-` + regexp.QuoteMeta(sourceVersion) + `\b`)
+	lines := strings.Split(content, "\n")
+	inMetadata, outputImage := false, false
+	for i, line := range lines {
+		if line != "" && !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "#") {
+			inMetadata = line == "metadata:"
+		}
+		konfluxName := tektonKonfluxLabel.MatchString(line) ||
+			(inMetadata && tektonMetadataName.MatchString(line)) ||
+			(outputImage && tektonParamValue.MatchString(line))
+		outputImage = tektonOutputImageParam.MatchString(line)
+		if konfluxName {
+			lines[i] = version.ReplaceAllString(line, "${1}-"+minorVersion)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func formatBranchTekton(result BranchTektonResult) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, ".tekton PipelineRuns of %s", result.Branch)
+	if result.DryRun {
+		sb.WriteString(" (dry run)")
+	}
+	sb.WriteString(":\n")
+	for _, repo := range result.Repositories {
+		fmt.Fprintf(&sb, "- %s: %s", repo.Repository, repo.Status)
+		if repo.PRURL != "" {
+			fmt.Fprintf(&sb, ", %s", repo.PRURL)
+		}
+		if repo.Error != "" {
+			fmt.Fprintf(&sb, ": %s", repo.Error)
+		}
+		sb.WriteString("\n")
+		for _, file := range repo.Files {
+			fmt.Fprintf(&sb, "  - %s\n", file)
+		}
+		for _, file := range repo.Unfiltered {
+			fmt.Fprintf(&sb, "  - %s has no target branch filter\n", file)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,170 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var (
+	// componentGVR identifies the Konflux Component resource
+	componentGVR = schema.GroupVersionResource{
+		Group:    "appstudio.redhat.com",
+		Version:  "v1alpha1",
+		Resource: "components",
+	}
+	// pipelineRunGVR identifies the Tekton PipelineRuns Konflux builds run as
+	pipelineRunGVR = schema.GroupVersionResource{
+		Group:    "tekton.dev",
+		Version:  "v1",
+		Resource: "pipelineruns",
+	}
+)
+
+const (
+	// componentLabel is the PipelineRun label naming the component it builds
+	componentLabel = "appstudio.openshift.io/component"
+	// pipelineTypeLabel is the PipelineRun label telling builds from tests and releases
+	pipelineTypeLabel = "pipelines.appstudio.openshift.io/type"
+)
+
+// ComponentBuild represents the latest build of a Konflux component
+type ComponentBuild struct {
+	Application string    `json:"application"`
+	Component   string    `json:"component"`
+	State       string    `json:"state" jsonschema:"succeeded, failed, running or none when the component was never built"`
+	PipelineRun string    `json:"pipeline_run,omitempty"`
+	StartedAt   time.Time `json:"started_at,omitempty"`
+	Revision    string    `json:"revision,omitempty" jsonschema:"Commit the build is for"`
+	Message     string    `json:"message,omitempty"`
+}
+
+// BuildStatusResult represents the structured result of check-build-status
+type BuildStatusResult struct {
+	MinorVersion string           `json:"minor_version"`
+	Namespace    string           `json:"namespace"`
+	Ready        bool             `json:"ready" jsonschema:"Whether the latest build of every component succeeded"`
+	Builds       []ComponentBuild `json:"builds"`
+}
+
+// releaseApplications returns the Konflux applications of a minor version,
+// named like the generated ReleasePlans. FBC index applications are not built
+// from components of their own and are left out.
+func releaseApplications(minorVersion string) []string {
+	var applications []string
+	for _, name := range sortedComponentNames(productComponents()) {
+		if name != "fbc" {
+			applications = append(applications, productName(name, minorVersion))
+		}
+	}
+	return applications
+}
+
+// checkBuildStatus reports the state of the latest build PipelineRun of every
+// component of the applications
+func checkBuildStatus(ctx context.Context, minorVersion, namespace string, applications []string) (BuildStatusResult, error) {
+	if namespace == "" {
+		namespace = tenantNamespace()
+	}
+	result := BuildStatusResult{MinorVersion: minorVersion, Namespace: namespace, Ready: true}
+
+	dc, err := dynamicClient(ctx)
+	if err != nil {
+		return result, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+
+	components, err := dc.Resource(componentGVR).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return result, fmt.Errorf("failed to list components: %w", err)
+	}
+
+	for _, application := range applications {
+		runs, err := dc.Resource(pipelineRunGVR).Namespace(namespace).List(ctx, metav1.ListOptions{
+			LabelSelector: fmt.Sprintf("%s=%s,%s=build", snapshotApplicationLabel, application, pipelineTypeLabel),
+		})
+		if err != nil {
+			return result, fmt.Errorf("failed to list PipelineRuns of %s: %w", application, err)
+		}
+		latest := make(map[string]*unstructured.Unstructured)
+		for i := range runs.Items {
+			run := &runs.Items[i]
+			component := run.GetLabels()[componentLabel]
+			if current, ok := latest[component]; !ok || run.GetCreationTimestamp().After(current.GetCreationTimestamp().Time) {
+				latest[component] = run
+			}
+		}
+
+		for i := range components.Items {
+			component := &components.Items[i]
+			if app, _, _ := unstructured.NestedString(component.Object, "spec", "application"); app != application {
+				continue
+			}
+			build := ComponentBuild{Application: application, Component: component.GetName(), State: "none"}
+			if run, ok := latest[component.GetName()]; ok {
+				build.PipelineRun = run.GetName()
+				build.StartedAt = run.GetCreationTimestamp().UTC()
+				build.Revision = run.GetAnnotations()["build.appstudio.redhat.com/commit_sha"]
+				build.State, build.Message = pipelineRunState(run)
+			}
+			if build.State != "succeeded" {
+				result.Ready = false
+			}
+			result.Builds = append(result.Builds, build)
+		}
+	}
+	return result, nil
+}
+
+// pipelineRunState maps the Succeeded condition of a PipelineRun to
+// succeeded, failed or running, with the condition message
+func pipelineRunState(run *unstructured.Unstructured) (string, string) {
+	conditions, _, _ := unstructured.NestedSlice(run.Object, "status", "conditions")
+	for _, item := range conditions {
+		values, ok := item.(map[string]any)
+		if !ok || values["type"] != "Succeeded" {
+			continue
+		}
+		message, _ := values["message"].(string)
+		switch values["status"] {
+		case string(metav1.ConditionTrue):
+			return "succeeded", ""
+		case string(metav1.ConditionFalse):
+			return "failed", message
+		}
+	}
+	return "running", ""
+}
+
+func formatBuildStatus(result BuildStatusResult) string {
+	var sb strings.Builder
+	if len(result.Builds) == 0 {
+		fmt.Fprintf(&sb, "No components of %s found in %s\n", result.MinorVersion, result.Namespace)
+		return sb.String()
+	}
+	counts := map[string]int{}
+	for _, build := range result.Builds {
+		counts[build.State]++
+		marker := map[string]string{"succeeded": "✓", "failed": "✗", "running": "…"}[build.State]
+		if marker == "" {
+			marker = "-"
+		}
+		fmt.Fprintf(&sb, "%s %s/%s: %s", marker, build.Application, build.Component, build.State)
+		if build.PipelineRun != "" {
+			fmt.Fprintf(&sb, " (%s)", build.PipelineRun)
+		}
+		if build.Message != "" {
+			fmt.Fprintf(&sb, ": %s", build.Message)
+		}
+		sb.WriteString("\n")
+	}
+	fmt.Fprintf(&sb, "\n%d succeeded, %d failed, %d running, %d never built\n", counts["succeeded"], counts["failed"], counts["running"], counts["none"])
+	if result.Ready {
+		sb.WriteString("All component builds are green\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,203 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
+	mcp "github.com/modelcontextprotocol/go-sdk/mcp"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// calendarFileEnv is the environment variable pointing to the release calendar
+	calendarFileEnv = "RELEASE_MCP_CALENDAR_FILE"
+
+	// windowOverrideArg is the argument allowing destructive tools to run outside their window
+	windowOverrideArg = "override_window"
+)
+
+// ReleaseDates represents the calendar of a single minor version.
+// Dates use the YYYY-MM-DD format.
+type ReleaseDates struct {
+	MergeWindowStart string `yaml:"merge_window_start,omitempty" json:"merge_window_start,omitempty"`
+	CodeFreeze       string `yaml:"code_freeze,omitempty" json:"code_freeze,omitempty"`
+	BranchDate       string `yaml:"branch_date,omitempty" json:"branch_date,omitempty"`
+	GA               string `yaml:"ga,omitempty" json:"ga,omitempty"`
+	// Destructive tools may only run within the release window, which
+	// defaults to starting at code freeze and being open ended
+	ReleaseWindowStart string `yaml:"release_window_start,omitempty" json:"release_window_start,omitempty"`
+	ReleaseWindowEnd   string `yaml:"release_window_end,omitempty" json:"release_window_end,omitempty"`
+}
+
+// ReleaseCalendar represents the release calendar in YAML
+type ReleaseCalendar struct {
+	Releases map[string]ReleaseDates `yaml:"releases"`
+}
+
+// releasePlanVersion matches the minor version in the name of a generated
+// ReleasePlan (e.g., openshift-pipelines-core-1.21-prod-release-as-op)
+var releasePlanVersion = regexp.MustCompile(`-(\d+\.\d+)-`)
+
+// windowGuardedTools holds the names of the tools checked against the release window
+var (
+	windowGuardedTools   = map[string]bool{}
+	windowGuardedToolsMu sync.Mutex
+)
+
+func loadReleaseCalendar() (*ReleaseCalendar, error) {
+	path := os.Getenv(calendarFileEnv)
+	if path == "" {
+		return nil, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read release calendar %s: %w", path, err)
+	}
+
+	var calendar ReleaseCalendar
+	if err := yaml.Unmarshal(content, &calendar); err != nil {
+		return nil, fmt.Errorf("failed to parse release calendar %s: %w", path, err)
+	}
+	return &calendar, nil
+}
+
+// releaseWindow returns the window destructive tools may run in for the version
+func releaseWindow(dates ReleaseDates) (start, end time.Time, err error) {
+	startDate := dates.ReleaseWindowStart
+	if startDate == "" {
+		startDate = dates.CodeFreeze
+	}
+	if startDate != "" {
+		if start, err = time.Parse("2006-01-02", startDate); err != nil {
+			return start, end, fmt.Errorf("invalid release window start %q: %w", startDate, err)
+		}
+	}
+	if dates.ReleaseWindowEnd != "" {
+		if end, err = time.Parse("2006-01-02", dates.ReleaseWindowEnd); err != nil {
+			return start, end, fmt.Errorf("invalid release window end %q: %w", dates.ReleaseWindowEnd, err)
+		}
+		// The end date is inclusive
+		end = end.AddDate(0, 0, 1)
+	}
+	return start, end, nil
+}
+
+// checkReleaseWindow returns an error when now is outside the release window
+// of the version. Versions missing from the calendar are not restricted.
+func checkReleaseWindow(calendar *ReleaseCalendar, version string, now time.Time) error {
+	if calendar == nil {
+		return nil
+	}
+	dates, ok := calendar.Releases[version]
+	if !ok {
+		return nil
+	}
+
+	start, end, err := releaseWindow(dates)
+	if err != nil {
+		return err
+	}
+	if !start.IsZero() && now.Before(start) {
+		return fmt.Errorf("release window for %s opens on %s", version, start.Format("2006-01-02"))
+	}
+	if !end.IsZero() && !now.Before(end) {
+		return fmt.Errorf("release window for %s closed on %s", version, end.AddDate(0, 0, -1).Format("2006-01-02"))
+	}
+	return nil
+}
+
+// guardReleaseWindow marks a destructive tool to be checked against the release
+// window and adds the override argument to its input schema. It must be called
+// before the tool is added to the server. Guarded tools are also mutating.
+func guardReleaseWindow(tool *mcp.Tool) *mcp.Tool {
+	markMutating(tool.Name)
+	windowGuardedToolsMu.Lock()
+	windowGuardedTools[tool.Name] = true
+	windowGuardedToolsMu.Unlock()
+
+	if tool.InputSchema.Properties == nil {
+		tool.InputSchema.Properties = map[string]*jsonschema.Schema{}
+	}
+	tool.InputSchema.Properties[windowOverrideArg] = &jsonschema.Schema{
+		Type:        "boolean",
+		Description: "Run even if the current date is outside the version's release window",
+	}
+	return tool
+}
+
+// releaseWindowMiddleware rejects guarded tool calls made outside the release
+// window of the version they target, unless the override argument is set
+func releaseWindowMiddleware(next mcp.MethodHandler[*mcp.ServerSession]) mcp.MethodHandler[*mcp.ServerSession] {
+	return func(ctx context.Context, session *mcp.ServerSession, method string, params mcp.Params) (mcp.Result, error) {
+		callParams, ok := params.(*mcp.CallToolParamsFor[json.RawMessage])
+		if method != "tools/call" || !ok {
+			return next(ctx, session, method, params)
+		}
+
+		windowGuardedToolsMu.Lock()
+		guarded := windowGuardedTools[callParams.Name]
+		windowGuardedToolsMu.Unlock()
+		if !guarded {
+			return next(ctx, session, method, params)
+		}
+
+		var args map[string]any
+		_ = json.Unmarshal(callParams.Arguments, &args)
+		if override, _ := args[windowOverrideArg].(bool); override {
+			return next(ctx, session, method, params)
+		}
+
+		version, _ := args["minor_version"].(string)
+		if version == "" {
+			version, _ = args["version"].(string)
+		}
+		// Releases target the version of the ReleasePlan they go through
+		if releasePlan, _ := args["release_plan"].(string); version == "" {
+			if match := releasePlanVersion.FindStringSubmatch(releasePlan); match != nil {
+				version = match[1]
+			}
+		}
+		// The calendar is keyed by minor version, patch releases fall in its window
+		if match := looseVersion.FindStringSubmatch(version); match != nil {
+			version = match[1]
+		}
+
+		calendar, err := loadReleaseCalendar()
+		if err == nil {
+			err = checkReleaseWindow(calendar, version, time.Now())
+		}
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Refusing to run %s: %v. Set %s to true to run anyway", callParams.Name, err, windowOverrideArg)}},
+				IsError: true,
+			}, nil
+		}
+		return next(ctx, session, method, params)
+	}
+}
+
+func formatReleaseDates(version string, dates ReleaseDates) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Release calendar for %s:\n", version)
+	for _, entry := range []struct{ label, date string }{
+		{"Merge window opens", dates.MergeWindowStart},
+		{"Code freeze", dates.CodeFreeze},
+		{"Branch date", dates.BranchDate},
+		{"GA", dates.GA},
+		{"Release window start", dates.ReleaseWindowStart},
+		{"Release window end", dates.ReleaseWindowEnd},
+	} {
+		if entry.date != "" {
+			fmt.Fprintf(&sb, "- %s: %s\n", entry.label, entry.date)
+		}
+	}
+	return sb.String()
+}
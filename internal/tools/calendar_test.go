@@ -0,0 +1,132 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	mcp "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestReleaseWindow(t *testing.T) {
+	tests := []struct {
+		name      string
+		dates     ReleaseDates
+		wantStart time.Time
+		wantEnd   time.Time
+		wantErr   bool
+	}{
+		{name: "open"},
+		{name: "starts at code freeze", dates: ReleaseDates{CodeFreeze: "2026-05-04"}, wantStart: time.Date(2026, 5, 4, 0, 0, 0, 0, time.UTC)},
+		{
+			name:      "explicit start",
+			dates:     ReleaseDates{CodeFreeze: "2026-05-04", ReleaseWindowStart: "2026-05-11"},
+			wantStart: time.Date(2026, 5, 11, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "end is inclusive",
+			dates:     ReleaseDates{CodeFreeze: "2026-05-04", ReleaseWindowEnd: "2026-06-01"},
+			wantStart: time.Date(2026, 5, 4, 0, 0, 0, 0, time.UTC),
+			wantEnd:   time.Date(2026, 6, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{name: "invalid start", dates: ReleaseDates{CodeFreeze: "May 4"}, wantErr: true},
+		{name: "invalid end", dates: ReleaseDates{ReleaseWindowEnd: "June"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, err := releaseWindow(tt.dates)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("releaseWindow() = %s, %s, want an error", start, end)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("releaseWindow() = %v", err)
+			}
+			if !start.Equal(tt.wantStart) || !end.Equal(tt.wantEnd) {
+				t.Errorf("releaseWindow() = %s, %s, want %s, %s", start, end, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestCheckReleaseWindow(t *testing.T) {
+	calendar := &ReleaseCalendar{Releases: map[string]ReleaseDates{
+		"1.21": {CodeFreeze: "2026-05-04", ReleaseWindowEnd: "2026-06-01"},
+		"1.22": {CodeFreeze: "not a date"},
+	}}
+	tests := []struct {
+		name     string
+		calendar *ReleaseCalendar
+		version  string
+		now      time.Time
+		wantErr  bool
+	}{
+		{name: "no calendar", version: "1.21", now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "version missing from the calendar", calendar: calendar, version: "1.20", now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "before code freeze", calendar: calendar, version: "1.21", now: time.Date(2026, 5, 3, 23, 0, 0, 0, time.UTC), wantErr: true},
+		{name: "at code freeze", calendar: calendar, version: "1.21", now: time.Date(2026, 5, 4, 0, 0, 0, 0, time.UTC)},
+		{name: "on the last day", calendar: calendar, version: "1.21", now: time.Date(2026, 6, 1, 23, 0, 0, 0, time.UTC)},
+		{name: "after the window", calendar: calendar, version: "1.21", now: time.Date(2026, 6, 2, 0, 0, 0, 0, time.UTC), wantErr: true},
+		{name: "invalid dates", calendar: calendar, version: "1.22", now: time.Date(2026, 5, 4, 0, 0, 0, 0, time.UTC), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkReleaseWindow(tt.calendar, tt.version, tt.now)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkReleaseWindow(%q, %s) = %v, want error %t", tt.version, tt.now, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReleaseWindowMiddleware(t *testing.T) {
+	calendarFile := filepath.Join(t.TempDir(), "calendar.yaml")
+	content := "releases:\n  \"1.19\":\n    code_freeze: \"2025-01-06\"\n    release_window_end: \"2025-03-31\"\n"
+	if err := os.WriteFile(calendarFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(calendarFileEnv, calendarFile)
+
+	const tool = "prepare-patch-release"
+	windowGuardedToolsMu.Lock()
+	windowGuardedTools[tool] = true
+	windowGuardedToolsMu.Unlock()
+	t.Cleanup(func() {
+		windowGuardedToolsMu.Lock()
+		delete(windowGuardedTools, tool)
+		windowGuardedToolsMu.Unlock()
+	})
+
+	tests := []struct {
+		name        string
+		args        string
+		wantBlocked bool
+	}{
+		{name: "patch version of a closed window", args: `{"version": "1.19.2"}`, wantBlocked: true},
+		{name: "minor version of a closed window", args: `{"minor_version": "1.19"}`, wantBlocked: true},
+		{name: "override", args: `{"version": "1.19.2", "override_window": true}`},
+		{name: "version missing from the calendar", args: `{"version": "1.20.1"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			next := func(context.Context, *mcp.ServerSession, string, mcp.Params) (mcp.Result, error) {
+				called = true
+				return &mcp.CallToolResult{}, nil
+			}
+			params := &mcp.CallToolParamsFor[json.RawMessage]{Name: tool, Arguments: json.RawMessage(tt.args)}
+			res, err := releaseWindowMiddleware(next)(context.Background(), nil, "tools/call", params)
+			if err != nil {
+				t.Fatalf("releaseWindowMiddleware() = %v", err)
+			}
+			if blocked := res.(*mcp.CallToolResult).IsError; blocked != tt.wantBlocked || called == blocked {
+				t.Errorf("releaseWindowMiddleware(%s) blocked = %t, called = %t, want blocked = %t", tt.args, blocked, called, tt.wantBlocked)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// pinnedRevision matches a full commit SHA
+var pinnedRevision = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// CatalogPinConfig represents the configuration for auditing the catalog revision of RPAs
+type CatalogPinConfig struct {
+	RepoPath     string
+	Dir          string // Relative to the konflux-release-data root
+	MinorVersion string // Only audit RPAs of this version when set
+}
+
+// RPACatalogRevision represents the release-service-catalog revision an RPA resolves its pipeline at
+type RPACatalogRevision struct {
+	File     string `json:"file"`
+	Revision string `json:"revision"`
+	Pinned   bool   `json:"pinned" jsonschema:"Whether the revision is a commit SHA"`
+}
+
+// CatalogPinResult represents the structured result of pin-and-verify-release-service-catalog-revision
+type CatalogPinResult struct {
+	Pinned    bool                 `json:"pinned" jsonschema:"Whether every audited RPA resolves its pipeline at a commit SHA"`
+	Branch    string               `json:"branch"`
+	BranchSHA string               `json:"branch_sha" jsonschema:"Commit the catalog branch points to, the SHA to pin unpinned RPAs to"`
+	Revisions []RPACatalogRevision `json:"revisions,omitempty"`
+	Unpinned  int                  `json:"unpinned"`
+}
+
+// auditCatalogPins reads the RPAs of the konflux-release-data repository and
+// reports those resolving their release-service-catalog pipeline at a moving
+// branch instead of a commit
+func auditCatalogPins(ctx context.Context, config CatalogPinConfig) (CatalogPinResult, error) {
+	result := CatalogPinResult{Pinned: true, Branch: releaseServiceCatalogRevision}
+
+	sha, err := resolveCatalogRevision(ctx, releaseServiceCatalogRevision)
+	if err != nil {
+		return result, err
+	}
+	result.BranchSHA = sha
+
+	if err := cloneKonfluxRepo(ctx, RPAConfig{RepoPath: config.RepoPath}); err != nil {
+		return result, fmt.Errorf("failed to clone konflux-release-data repository: %w", err)
+	}
+
+	dir := filepath.Join(config.RepoPath, config.Dir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return result, fmt.Errorf("failed to read directory %s: %w", dir, err)
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".yaml") {
+			continue
+		}
+		if config.MinorVersion != "" && !strings.Contains(name, "-"+config.MinorVersion+"-") {
+			continue
+		}
+		revision, ok, err := rpaCatalogRevision(filepath.Join(dir, name))
+		if err != nil {
+			return result, err
+		}
+		if !ok {
+			continue
+		}
+		rev := RPACatalogRevision{File: name, Revision: revision, Pinned: pinnedRevision.MatchString(revision)}
+		if !rev.Pinned {
+			result.Pinned = false
+			result.Unpinned++
+		}
+		result.Revisions = append(result.Revisions, rev)
+	}
+	sort.Slice(result.Revisions, func(i, j int) bool { return result.Revisions[i].File < result.Revisions[j].File })
+	return result, nil
+}
+
+// rpaCatalogRevision returns the revision of the pipelineRef of an RPA, and
+// whether the pipeline is resolved from the release-service-catalog
+func rpaCatalogRevision(filePath string) (string, bool, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read %s: %w", filePath, err)
+	}
+	var rpa struct {
+		Kind string `yaml:"kind"`
+		Spec struct {
+			Pipeline struct {
+				PipelineRef struct {
+					Params []struct {
+						Name  string `yaml:"name"`
+						Value string `yaml:"value"`
+					} `yaml:"params"`
+				} `yaml:"pipelineRef"`
+			} `yaml:"pipeline"`
+		} `yaml:"spec"`
+	}
+	if err := yaml.Unmarshal(content, &rpa); err != nil {
+		return "", false, fmt.Errorf("failed to parse %s: %w", filePath, err)
+	}
+	if rpa.Kind != "ReleasePlanAdmission" {
+		return "", false, nil
+	}
+
+	var repoURL, revision string
+	for _, param := range rpa.Spec.Pipeline.PipelineRef.Params {
+		switch param.Name {
+		case "url":
+			repoURL = param.Value
+		case "revision":
+			revision = param.Value
+		}
+	}
+	if !strings.Contains(repoURL, releaseServiceCatalogRepo) {
+		return "", false, nil
+	}
+	return revision, true, nil
+}
+
+func formatCatalogPins(result CatalogPinResult) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "release-service-catalog %s is at %s\n\n", result.Branch, result.BranchSHA)
+	if len(result.Revisions) == 0 {
+		sb.WriteString("No RPA resolves its pipeline from the release-service-catalog\n")
+		return sb.String()
+	}
+	for _, rev := range result.Revisions {
+		status := "pinned"
+		if !rev.Pinned {
+			status = "UNPINNED"
+		}
+		fmt.Fprintf(&sb, "- %s: %s (%s)\n", rev.File, rev.Revision, status)
+	}
+	if result.Pinned {
+		fmt.Fprintf(&sb, "\nAll %d RPAs are pinned to a commit\n", len(result.Revisions))
+	} else {
+		fmt.Fprintf(&sb, "\n%d of %d RPAs follow a moving revision, pin them to %s or recreate them with pin_catalog_revision\n", result.Unpinned, len(result.Revisions), result.BranchSHA)
+	}
+	return sb.String()
+}
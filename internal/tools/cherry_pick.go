@@ -0,0 +1,280 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CherryPickConfig represents the configuration for cherry-picking a fix across release branches
+type CherryPickConfig struct {
+	Repository  string   // Name or owner/name of a release repository
+	Commit      string   // Commit of the fix on the default branch
+	Branches    []string // Target branches, the release branches of the supported versions when empty
+	CVE         string   // CVE fixed by the commit, quoted in the pull requests
+	WorkDir     string
+	KonfluxPath string // konflux-release-data clone listing the supported versions
+	DryRun      bool   // Only cherry-pick locally, without pushing or opening pull requests
+
+	OverrideWindow bool // Push to branches whose version is outside its release window
+}
+
+// CherryPickOutcome represents the result of cherry-picking a fix onto a release branch
+type CherryPickOutcome struct {
+	TargetBranch string   `json:"target_branch"`
+	Status       string   `json:"status" jsonschema:"picked, conflict, already applied, missing when the branch does not exist, outside window when the release window of its version is closed, or failed"`
+	Branch       string   `json:"branch,omitempty" jsonschema:"Branch the cherry-pick was pushed to"`
+	PRURL        string   `json:"pr_url,omitempty"`
+	Conflicts    []string `json:"conflicts,omitempty" jsonschema:"Files the fix conflicts in"`
+	Error        string   `json:"error,omitempty"`
+}
+
+// CherryPickResult represents the structured result of bulk-cherry-pick-fix
+type CherryPickResult struct {
+	Repository string              `json:"repository"`
+	Commit     string              `json:"commit"`
+	Subject    string              `json:"subject"`
+	DryRun     bool                `json:"dry_run"`
+	Branches   []CherryPickOutcome `json:"branches"`
+	Conflicts  int                 `json:"conflicts" jsonschema:"Branches the fix needs a manual backport to"`
+}
+
+// cherryPickFix cherry-picks a commit onto every target release branch of a
+// repository, each on its own branch with its own pull request, so a branch
+// the fix conflicts with does not hold back the others
+func cherryPickFix(ctx context.Context, config CherryPickConfig) (CherryPickResult, error) {
+	result := CherryPickResult{Commit: config.Commit, DryRun: config.DryRun}
+	var repo *Repository
+	for _, candidate := range releaseRepositories() {
+		if candidate.Name == config.Repository || forgeRepoPath(candidate.RepoURL) == config.Repository {
+			repo = &candidate
+			break
+		}
+	}
+	if repo == nil {
+		return result, fmt.Errorf("unknown repository %q, expected one of the release repositories", config.Repository)
+	}
+	result.Repository = forgeRepoPath(repo.RepoURL)
+
+	branches := config.Branches
+	if len(branches) == 0 {
+		supported, err := listSupportedVersions(ctx, config.KonfluxPath, false, time.Now())
+		if err != nil {
+			return result, fmt.Errorf("failed to list supported versions: %w", err)
+		}
+		for _, version := range supported.Versions {
+			branches = append(branches, fmt.Sprintf("release-v%s.x", version.Version))
+		}
+		if len(branches) == 0 {
+			return result, fmt.Errorf("no supported version found")
+		}
+	}
+
+	// The fix is picked with the history of every release branch, but only
+	// the contents it touches are needed
+	cloneCmd, err := gitCommand(ctx, githubHost, "clone", partialCloneFilter, repo.RepoURL, config.WorkDir)
+	if err != nil {
+		return result, err
+	}
+	var cloneStderr bytes.Buffer
+	cloneCmd.Stderr = &cloneStderr
+	if err := runCommand(ctx, cloneCmd); err != nil {
+		return result, fmt.Errorf("failed to clone %s: %v\nError details: %s", result.Repository, err, cloneStderr.String())
+	}
+	runUsageFrom(ctx).recordClone(config.WorkDir)
+
+	output, err := gitIn(ctx, config.WorkDir, "log", "-1", "--format=%H%n%s", config.Commit)
+	if err != nil {
+		return result, fmt.Errorf("commit %s not found in %s: %w", config.Commit, result.Repository, err)
+	}
+	result.Commit, result.Subject, _ = strings.Cut(strings.TrimSpace(output), "\n")
+
+	var provider VCSProvider
+	var calendar *ReleaseCalendar
+	if !config.DryRun {
+		if provider, err = vcsProvider(githubHost); err != nil {
+			return result, err
+		}
+		if calendar, err = loadReleaseCalendar(); err != nil {
+			return result, err
+		}
+	}
+	for i, target := range branches {
+		reportProgress(ctx, float64(i), float64(len(branches)), "Cherry-picking onto "+target)
+		// The call spans versions, so the window is checked per target branch
+		version := strings.TrimSuffix(strings.TrimPrefix(target, "release-v"), ".x")
+		if err := checkReleaseWindow(calendar, version, time.Now()); err != nil && !config.OverrideWindow {
+			result.Branches = append(result.Branches, CherryPickOutcome{TargetBranch: target, Status: "outside window", Error: err.Error()})
+			continue
+		}
+		outcome := cherryPickOnto(ctx, config, provider, result, target)
+		if outcome.Status == "conflict" {
+			result.Conflicts++
+		}
+		result.Branches = append(result.Branches, outcome)
+	}
+	reportProgress(ctx, float64(len(branches)), float64(len(branches)), fmt.Sprintf("Cherry-picked onto %d branches, %d conflicts", len(branches), result.Conflicts))
+	return result, nil
+}
+
+// cherryPickOnto cherry-picks the fix onto a target branch of the clone,
+// then pushes it and opens a pull request unless the call is a dry run
+func cherryPickOnto(ctx context.Context, config CherryPickConfig, provider VCSProvider, result CherryPickResult, target string) CherryPickOutcome {
+	outcome := CherryPickOutcome{TargetBranch: target}
+	dir := config.WorkDir
+	if _, err := gitIn(ctx, dir, "rev-parse", "--verify", "--quiet", "origin/"+target); err != nil {
+		outcome.Status = "missing"
+		return outcome
+	}
+	if _, err := gitIn(ctx, dir, "merge-base", "--is-ancestor", result.Commit, "origin/"+target); err == nil {
+		outcome.Status = "already applied"
+		return outcome
+	}
+
+	outcome.Branch = fmt.Sprintf("cherry-pick-%s-%s", result.Commit[:min(len(result.Commit), 8)], target)
+	if _, err := gitFetchingIn(ctx, githubHost, dir, "checkout", "-B", outcome.Branch, "origin/"+target); err != nil {
+		outcome.Status, outcome.Error = "failed", err.Error()
+		return outcome
+	}
+	// Merge commits need a mainline parent to pick the changes of
+	pickArgs := []string{"cherry-pick", "-x"}
+	if parents, err := gitIn(ctx, dir, "rev-list", "--parents", "-n", "1", result.Commit); err == nil && len(strings.Fields(parents)) > 2 {
+		pickArgs = append(pickArgs, "-m", "1")
+	}
+	if _, err := gitFetchingIn(ctx, githubHost, dir, append(pickArgs, result.Commit)...); err != nil {
+		conflicts, _ := gitIn(ctx, dir, "diff", "--name-only", "--diff-filter=U")
+		status, _ := gitIn(ctx, dir, "status", "--porcelain")
+		_, _ = gitIn(ctx, dir, "cherry-pick", "--abort")
+		switch {
+		case strings.TrimSpace(conflicts) != "":
+			outcome.Status = "conflict"
+			outcome.Conflicts = strings.Fields(conflicts)
+			sort.Strings(outcome.Conflicts)
+		case strings.TrimSpace(status) == "":
+			// The fix was backported under another commit
+			outcome.Status = "already applied"
+		default:
+			outcome.Status, outcome.Error = "failed", err.Error()
+		}
+		outcome.Branch = ""
+		return outcome
+	}
+	outcome.Status = "picked"
+	if config.DryRun {
+		return outcome
+	}
+
+	// The branch name is derived from the fix and the target, so an existing
+	// branch is only replaced when it holds an earlier pick of the same fix,
+	// and only if nobody pushed to it since the clone
+	lease := "--force-with-lease=" + outcome.Branch + ":"
+	if remoteSHA, err := gitIn(ctx, dir, "rev-parse", "--verify", "--quiet", "refs/remotes/origin/"+outcome.Branch); err == nil {
+		message, _ := gitIn(ctx, dir, "log", "-1", "--format=%B", "refs/remotes/origin/"+outcome.Branch)
+		if !strings.Contains(message, "(cherry picked from commit "+result.Commit+")") {
+			outcome.Status, outcome.Error = "failed", fmt.Sprintf("branch %s already exists and does not hold a cherry-pick of %s", outcome.Branch, result.Commit)
+			return outcome
+		}
+		lease += strings.TrimSpace(remoteSHA)
+	}
+	pushCmd, err := gitCommand(ctx, githubHost, "push", lease, "origin", outcome.Branch)
+	if err != nil {
+		outcome.Status, outcome.Error = "failed", err.Error()
+		return outcome
+	}
+	pushCmd.Dir = dir
+	var pushStderr bytes.Buffer
+	pushCmd.Stderr = &pushStderr
+	if err := runCommand(ctx, pushCmd); err != nil {
+		outcome.Status, outcome.Error = "failed", fmt.Sprintf("failed to push %s: %v: %s", outcome.Branch, err, strings.TrimSpace(pushStderr.String()))
+		return outcome
+	}
+
+	body, err := renderNotification("cherry-pick-pr", map[string]any{
+		"Commit": result.Commit,
+		"Target": target,
+		"CVE":    config.CVE,
+	})
+	if err != nil {
+		outcome.Status, outcome.Error = "failed", err.Error()
+		return outcome
+	}
+	pr, err := provider.OpenChangeRequest(ctx, result.Repository, ChangeRequest{
+		Title:        fmt.Sprintf("[%s] %s", target, result.Subject),
+		Body:         body,
+		SourceBranch: outcome.Branch,
+		TargetBranch: target,
+	})
+	if err != nil {
+		outcome.Status, outcome.Error = "failed", fmt.Sprintf("failed to open pull request: %v", err)
+		return outcome
+	}
+	outcome.PRURL = pr.URL
+	return outcome
+}
+
+// gitIn runs a local git command in a clone, returning its output
+func gitIn(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := commandOutput(ctx, cmd)
+	if err != nil {
+		return string(output), fmt.Errorf("git %s failed: %v: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return string(output), nil
+}
+
+// gitFetchingIn runs a git command in a partial clone of a repository on
+// host, authenticating the fetches of the contents it reads, and returns its
+// output
+func gitFetchingIn(ctx context.Context, host, dir string, args ...string) (string, error) {
+	cmd, err := gitCommand(ctx, host, args...)
+	if err != nil {
+		return "", err
+	}
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := commandOutput(ctx, cmd)
+	if err != nil {
+		return string(output), fmt.Errorf("git %s failed: %v: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return string(output), nil
+}
+
+func formatCherryPick(result CherryPickResult) string {
+	var sb strings.Builder
+	if result.DryRun {
+		sb.WriteString("Dry run: ")
+	}
+	fmt.Fprintf(&sb, "Cherry-pick of %s (%s) in %s:\n", result.Commit[:min(len(result.Commit), 12)], result.Subject, result.Repository)
+	for _, outcome := range result.Branches {
+		marker := "✓"
+		switch outcome.Status {
+		case "conflict", "failed":
+			marker = "✗"
+		case "missing", "already applied", "outside window":
+			marker = "-"
+		}
+		fmt.Fprintf(&sb, "%s %s: %s", marker, outcome.TargetBranch, outcome.Status)
+		if outcome.PRURL != "" {
+			fmt.Fprintf(&sb, " %s", outcome.PRURL)
+		}
+		if len(outcome.Conflicts) > 0 {
+			fmt.Fprintf(&sb, " in %s", strings.Join(outcome.Conflicts, ", "))
+		}
+		if outcome.Error != "" {
+			fmt.Fprintf(&sb, ": %s", outcome.Error)
+		}
+		sb.WriteString("\n")
+	}
+	if result.Conflicts > 0 {
+		fmt.Fprintf(&sb, "\n%d branches need a manual backport\n", result.Conflicts)
+	}
+	return sb.String()
+}
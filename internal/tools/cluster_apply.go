@@ -0,0 +1,160 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+// applyFieldManager owns the fields the server sets with server-side apply
+const applyFieldManager = "release-mcp-server"
+
+// releaseResources maps the kinds of the generated manifests to their resources
+var releaseResources = map[string]schema.GroupVersionResource{
+	"ReleasePlanAdmission": {Group: "appstudio.redhat.com", Version: "v1alpha1", Resource: "releaseplanadmissions"},
+	"ReleasePlan":          {Group: "appstudio.redhat.com", Version: "v1alpha1", Resource: "releaseplans"},
+}
+
+// AppliedObject represents a generated manifest applied to the cluster
+type AppliedObject struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Action    string `json:"action" jsonschema:"created, updated or failed"`
+	Error     string `json:"error,omitempty"`
+}
+
+// ApplyResult represents the structured result of apply-release-plans
+type ApplyResult struct {
+	DryRun  bool            `json:"dry_run" jsonschema:"Whether the objects were only validated by the API server"`
+	Objects []AppliedObject `json:"objects"`
+	Failed  int             `json:"failed"`
+}
+
+// applyReleasePlans renders the ReleasePlanAdmissions and ReleasePlans of a
+// release and creates or updates them on the cluster with server-side apply.
+// With dryRun, the API server validates the objects without persisting them.
+func applyReleasePlans(ctx context.Context, config RPAConfig, dryRun bool) (ApplyResult, error) {
+	result := ApplyResult{DryRun: dryRun}
+
+	// Render into a fresh directory so only the manifests of this release are applied
+	workDir, err := os.MkdirTemp("", "release-plans-apply-*")
+	if err != nil {
+		return result, fmt.Errorf("failed to create working directory: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+	config.RepoPath = workDir
+
+	if err := createRPAs(ctx, config); err != nil {
+		return result, fmt.Errorf("failed to render ReleasePlanAdmissions: %w", err)
+	}
+	if err := createRPs(ctx, config); err != nil {
+		return result, fmt.Errorf("failed to render ReleasePlans: %w", err)
+	}
+
+	objects, err := readManifests(workDir)
+	if err != nil {
+		return result, err
+	}
+
+	dc, err := dynamicClient(ctx)
+	if err != nil {
+		return result, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+
+	options := metav1.ApplyOptions{FieldManager: applyFieldManager, Force: true}
+	if dryRun {
+		options.DryRun = []string{metav1.DryRunAll}
+	}
+	for _, obj := range objects {
+		applied := AppliedObject{Kind: obj.GetKind(), Namespace: obj.GetNamespace(), Name: obj.GetName(), Action: "updated"}
+		client := dc.Resource(releaseResources[obj.GetKind()]).Namespace(obj.GetNamespace())
+
+		_, err := client.Get(ctx, obj.GetName(), metav1.GetOptions{})
+		switch {
+		case apierrors.IsNotFound(err):
+			applied.Action = "created"
+		case err != nil:
+			applied.Action = "failed"
+			applied.Error = err.Error()
+		}
+		if applied.Action != "failed" {
+			stampManagedBy(obj)
+			if _, err := client.Apply(ctx, obj.GetName(), obj, options); err != nil {
+				applied.Action = "failed"
+				applied.Error = err.Error()
+			}
+		}
+		if applied.Action == "failed" {
+			result.Failed++
+		}
+		result.Objects = append(result.Objects, applied)
+	}
+	return result, nil
+}
+
+// readManifests decodes the ReleasePlanAdmissions and ReleasePlans rendered
+// under dir, ReleasePlanAdmissions first as ReleasePlans refer to them.
+// ReleasePlans carry no namespace, which kustomize sets from their tenant.
+func readManifests(dir string) ([]*unstructured.Unstructured, error) {
+	var objects []*unstructured.Unstructured
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || !strings.HasSuffix(path, ".yaml") {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		data, err := yaml.YAMLToJSON(content)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(data); err != nil {
+			return fmt.Errorf("failed to decode %s: %w", path, err)
+		}
+		if _, ok := releaseResources[obj.GetKind()]; !ok {
+			return fmt.Errorf("unexpected kind %q in %s", obj.GetKind(), path)
+		}
+		if obj.GetNamespace() == "" {
+			obj.SetNamespace(tenantNamespace())
+		}
+		objects = append(objects, obj)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(objects, func(i, j int) bool {
+		return objects[i].GetKind() == "ReleasePlanAdmission" && objects[j].GetKind() != "ReleasePlanAdmission"
+	})
+	return objects, nil
+}
+
+func formatApplyResult(result ApplyResult) string {
+	var sb strings.Builder
+	if result.DryRun {
+		sb.WriteString("Dry run, nothing was persisted\n\n")
+	}
+	for _, obj := range result.Objects {
+		if obj.Action == "failed" {
+			fmt.Fprintf(&sb, "✗ %s %s/%s: %s\n", obj.Kind, obj.Namespace, obj.Name, obj.Error)
+			continue
+		}
+		fmt.Fprintf(&sb, "✓ %s %s/%s %s\n", obj.Kind, obj.Namespace, obj.Name, obj.Action)
+	}
+	fmt.Fprintf(&sb, "\n%d of %d objects applied\n", len(result.Objects)-result.Failed, len(result.Objects))
+	return sb.String()
+}
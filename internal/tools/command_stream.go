@@ -0,0 +1,143 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
+	mcp "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// verboseArg is the argument streaming the output of subprocesses to the client
+const verboseArg = "verbose"
+
+// commandStream sends the output lines of the subprocesses of a tool call to
+// the client as log notifications
+type commandStream struct {
+	mu      sync.Mutex
+	ctx     context.Context
+	session *mcp.ServerSession
+	tool    string
+}
+
+type commandStreamKey struct{}
+
+// commandStreamFrom returns the stream of the tool call of the context, or
+// nil when the call is not verbose
+func commandStreamFrom(ctx context.Context) *commandStream {
+	stream, _ := ctx.Value(commandStreamKey{}).(*commandStream)
+	return stream
+}
+
+// streamCommands adds the verbose argument to the input schema of a tool
+// running git or scripts
+func streamCommands(tool *mcp.Tool) *mcp.Tool {
+	if tool.InputSchema.Properties == nil {
+		tool.InputSchema.Properties = map[string]*jsonschema.Schema{}
+	}
+	tool.InputSchema.Properties[verboseArg] = &jsonschema.Schema{
+		Type:        "boolean",
+		Description: "Stream the output of git and scripts as log notifications while the tool runs",
+	}
+	return tool
+}
+
+// verboseMiddleware streams the subprocess output of tool calls setting the verbose argument
+func verboseMiddleware(next mcp.MethodHandler[*mcp.ServerSession]) mcp.MethodHandler[*mcp.ServerSession] {
+	return func(ctx context.Context, session *mcp.ServerSession, method string, params mcp.Params) (mcp.Result, error) {
+		callParams, ok := params.(*mcp.CallToolParamsFor[json.RawMessage])
+		if method != "tools/call" || !ok || session == nil {
+			return next(ctx, session, method, params)
+		}
+
+		var args map[string]any
+		_ = json.Unmarshal(callParams.Arguments, &args)
+		if verbose, _ := args[verboseArg].(bool); verbose {
+			stream := &commandStream{ctx: ctx, session: session, tool: callParams.Name}
+			ctx = context.WithValue(ctx, commandStreamKey{}, stream)
+		}
+		return next(ctx, session, method, params)
+	}
+}
+
+// send notifies the client of a line of output. Delivery failures are
+// ignored, the output is only informative.
+func (s *commandStream) send(level mcp.LoggingLevel, line string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_ = s.session.Log(s.ctx, &mcp.LoggingMessageParams{Level: level, Logger: s.tool, Data: line})
+}
+
+// attach tees the output of a command to the stream, see attachLines
+func (s *commandStream) attach(cmd *exec.Cmd, captureStdout bool) func() {
+	return attachLines(s, cmd, captureStdout)
+}
+
+// lineSink receives the output of subprocesses line by line
+type lineSink interface {
+	send(level mcp.LoggingLevel, line string)
+}
+
+// attachLines tees the standard output, unless the caller reads it, and the
+// standard error of a command to sink. The returned function flushes
+// incomplete lines once the command exits.
+func attachLines(sink lineSink, cmd *exec.Cmd, captureStdout bool) func() {
+	sink.send("info", "$ "+strings.Join(cmd.Args, " "))
+
+	var writers []*lineWriter
+	tee := func(w io.Writer, level mcp.LoggingLevel) io.Writer {
+		lw := &lineWriter{sink: sink, level: level}
+		writers = append(writers, lw)
+		if w == nil {
+			return lw
+		}
+		return io.MultiWriter(w, lw)
+	}
+	if !captureStdout {
+		cmd.Stdout = tee(cmd.Stdout, "info")
+	}
+	cmd.Stderr = tee(cmd.Stderr, "notice")
+
+	return func() {
+		for _, lw := range writers {
+			lw.flush()
+		}
+	}
+}
+
+// lineWriter sends what is written to it line by line
+type lineWriter struct {
+	mu    sync.Mutex
+	sink  lineSink
+	level mcp.LoggingLevel
+	buf   bytes.Buffer
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf.Write(p)
+	for {
+		i := bytes.IndexByte(w.buf.Bytes(), '\n')
+		if i < 0 {
+			break
+		}
+		line := strings.TrimRight(string(w.buf.Next(i+1)), "\r\n")
+		w.sink.send(w.level, line)
+	}
+	return len(p), nil
+}
+
+func (w *lineWriter) flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.buf.Len() > 0 {
+		w.sink.send(w.level, w.buf.String())
+		w.buf.Reset()
+	}
+}
@@ -0,0 +1,188 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ComponentMappingConfig represents the configuration for reconciling componentMapping
+type ComponentMappingConfig struct {
+	Branch   string // Hack branch to compare with, defaults to main
+	RepoPath string
+	Generate bool // Also render the mapping generated from the repo files
+}
+
+// UnknownRepository represents a hack repo file whose repository is missing from componentMapping
+type UnknownRepository struct {
+	File string `json:"file"`
+	Name string `json:"name"`
+}
+
+// ComponentMappingResult represents the structured result of reconcile-componentMapping-with-hack-repo
+type ComponentMappingResult struct {
+	Branch     string              `json:"branch"`
+	Mapped     []string            `json:"mapped" jsonschema:"Repositories present both in componentMapping and the hack repo"`
+	Unknown    []UnknownRepository `json:"unknown,omitempty" jsonschema:"Repo files missing from componentMapping, configured with default branch entries"`
+	Unmapped   []string            `json:"unmapped,omitempty" jsonschema:"componentMapping entries without a repo file"`
+	Dynamic    bool                `json:"dynamic" jsonschema:"Whether repositories missing from componentMapping are mapped from the repo files"`
+	Consistent bool                `json:"consistent"`
+	Generated  string              `json:"generated,omitempty" jsonschema:"componentMapping generated from the repo files, as Go source"`
+}
+
+// componentForRepo returns the component of a hack repository. Repositories
+// missing from componentMapping are their own component when the mapping is
+// generated dynamically from the repo files.
+func componentForRepo(repoName string) (string, bool) {
+	if componentName, ok := componentMapping[repoName]; ok {
+		return componentName, true
+	}
+	if currentConfig().DynamicComponentMapping && repoName != "" {
+		return repoName, true
+	}
+	return "", false
+}
+
+// hackRepoNames returns the repository name of every repo file of the hack
+// repository, by file name
+func hackRepoNames(repoPath string) (map[string]string, error) {
+	reposDir := filepath.Join(repoPath, "config", "konflux", "repos")
+	entries, err := os.ReadDir(reposDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read repos directory: %w", err)
+	}
+
+	names := make(map[string]string)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".yaml") {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(reposDir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file %s: %w", entry.Name(), err)
+		}
+		var repo RepoConfig
+		if err := yaml.Unmarshal(content, &repo); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", entry.Name(), err)
+		}
+		names[entry.Name()] = repo.Name
+	}
+	return names, nil
+}
+
+// compareComponentMapping cross-checks componentMapping against the repo
+// files of the hack repository
+func compareComponentMapping(result *ComponentMappingResult, names map[string]string) {
+	present := make(map[string]bool)
+	files := make([]string, 0, len(names))
+	for file := range names {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		name := names[file]
+		if _, ok := componentMapping[name]; ok {
+			if !present[name] {
+				result.Mapped = append(result.Mapped, name)
+			}
+			present[name] = true
+			continue
+		}
+		result.Unknown = append(result.Unknown, UnknownRepository{File: file, Name: name})
+	}
+	for name := range componentMapping {
+		if !present[name] {
+			result.Unmapped = append(result.Unmapped, name)
+		}
+	}
+	sort.Strings(result.Mapped)
+	sort.Strings(result.Unmapped)
+	result.Consistent = len(result.Unknown) == 0 && len(result.Unmapped) == 0
+}
+
+// generateComponentMapping renders componentMapping for the repo files as Go
+// source, keeping the components of the repositories already mapped
+func generateComponentMapping(names map[string]string) string {
+	mapping := make(map[string]string)
+	width := 0
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		mapping[name] = name
+		if componentName, ok := componentMapping[name]; ok {
+			mapping[name] = componentName
+		}
+		width = max(width, len(name)+4) // Quotes, colon and space
+	}
+	repos := make([]string, 0, len(mapping))
+	for name := range mapping {
+		repos = append(repos, name)
+	}
+	sort.Strings(repos)
+
+	var sb strings.Builder
+	sb.WriteString("var componentMapping = map[string]string{\n")
+	for _, name := range repos {
+		fmt.Fprintf(&sb, "\t%-*s%q,\n", width, fmt.Sprintf("%q:", name), mapping[name])
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+func reconcileComponentMapping(ctx context.Context, config ComponentMappingConfig) (ComponentMappingResult, error) {
+	if config.Branch == "" {
+		config.Branch = "main"
+	}
+	result := ComponentMappingResult{Branch: config.Branch, Dynamic: currentConfig().DynamicComponentMapping}
+
+	if err := cloneHackRepo(ctx, HackConfig{BaseBranch: config.Branch, RepoPath: config.RepoPath}); err != nil {
+		return result, fmt.Errorf("failed to clone hack repository: %w", err)
+	}
+	names, err := hackRepoNames(config.RepoPath)
+	if err != nil {
+		return result, err
+	}
+
+	compareComponentMapping(&result, names)
+	if config.Generate {
+		result.Generated = generateComponentMapping(names)
+	}
+	return result, nil
+}
+
+func formatComponentMapping(result ComponentMappingResult) string {
+	var sb strings.Builder
+	if result.Consistent {
+		fmt.Fprintf(&sb, "✓ componentMapping matches the %d repo files of hack branch %s\n", len(result.Mapped), result.Branch)
+	} else {
+		fmt.Fprintf(&sb, "✗ componentMapping diverges from hack branch %s (%d repositories mapped)\n", result.Branch, len(result.Mapped))
+	}
+
+	if len(result.Unknown) > 0 {
+		if result.Dynamic {
+			sb.WriteString("\nRepositories missing from componentMapping, mapped dynamically to themselves:\n")
+		} else {
+			sb.WriteString("\nRepositories missing from componentMapping, configured with default branch entries:\n")
+		}
+		for _, repo := range result.Unknown {
+			fmt.Fprintf(&sb, "- %s (%s)\n", repo.Name, repo.File)
+		}
+	}
+	if len(result.Unmapped) > 0 {
+		sb.WriteString("\ncomponentMapping entries without a repo file:\n")
+		for _, name := range result.Unmapped {
+			fmt.Fprintf(&sb, "- %s\n", name)
+		}
+	}
+	if result.Generated != "" {
+		fmt.Fprintf(&sb, "\nMapping generated from the repo files:\n\n%s", result.Generated)
+	}
+	return sb.String()
+}
@@ -0,0 +1,258 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// configFileEnv is the environment variable pointing to the server configuration file
+	configFileEnv = "RELEASE_MCP_CONFIG_FILE"
+
+	// configPollInterval is how often the configuration file is checked for changes
+	configPollInterval = 10 * time.Second
+)
+
+// ServerConfig represents the non-sensitive settings that can be changed while
+// the server runs. Unset fields keep their built-in defaults. Credentials stay
+// in environment variables and are never read from this file.
+type ServerConfig struct {
+	Repositories            []Repository              `yaml:"repositories,omitempty" json:"repositories,omitempty" jsonschema:"Repositories release branches are cut in"`
+	OCPVersions             []string                  `yaml:"ocp_versions,omitempty" json:"ocp_versions,omitempty" jsonschema:"Default OCP versions of FBC release plans and mirror lists"`
+	FBCAllowedPackages      []string                  `yaml:"fbc_allowed_packages,omitempty" json:"fbc_allowed_packages,omitempty" jsonschema:"allowedPackages of generated FBC RPAs"`
+	Templates               map[string]string         `yaml:"templates,omitempty" json:"templates,omitempty" jsonschema:"Overrides of registered templates by name, and of notification templates by <name>.<locale>"`
+	Locale                  string                    `yaml:"locale,omitempty" json:"locale,omitempty" jsonschema:"Locale of the notification templates (e.g., fr or pt-BR), the default templates when unset or without a variant"`
+	DocsRepository          string                    `yaml:"docs_repository,omitempty" json:"docs_repository,omitempty" jsonschema:"GitHub repository release notes are proposed to"`
+	DocsBranch              string                    `yaml:"docs_branch,omitempty" json:"docs_branch,omitempty" jsonschema:"Branch of the documentation repository release notes target"`
+	DocsFork                string                    `yaml:"docs_fork,omitempty" json:"docs_fork,omitempty" jsonschema:"Fork release notes branches are pushed to when the server cannot push to the documentation repository"`
+	KonfluxRepository       string                    `yaml:"konflux_repository,omitempty" json:"konflux_repository,omitempty" jsonschema:"GitLab repository ReleasePlan and ReleasePlanAdmission merge requests target"`
+	KonfluxFork             string                    `yaml:"konflux_fork,omitempty" json:"konflux_fork,omitempty" jsonschema:"Fork konflux-release-data branches are pushed to when the server cannot push to the repository"`
+	ArtifactRepository      string                    `yaml:"artifact_repository,omitempty" json:"artifact_repository,omitempty" jsonschema:"OCI repository release records are pushed to by mirror-release-artifacts"`
+	DynamicComponentMapping bool                      `yaml:"dynamic_component_mapping,omitempty" json:"dynamic_component_mapping,omitempty" jsonschema:"Map hack repositories missing from componentMapping to themselves"`
+	ConfirmRepoThreshold    int                       `yaml:"confirm_repo_threshold,omitempty" json:"confirm_repo_threshold,omitempty" jsonschema:"How many repositories a tool may push to before its calls need a confirmed plan"`
+	Product                 string                    `yaml:"product,omitempty" json:"product,omitempty" jsonschema:"Product profile releases are made for, the built-in openshift-pipelines one when unset"`
+	Products                map[string]ProductProfile `yaml:"products,omitempty" json:"products,omitempty" jsonschema:"Product profiles by name"`
+}
+
+// EffectiveConfig represents the configuration currently in use, with defaults applied
+type EffectiveConfig struct {
+	Source                  string         `json:"source,omitempty" jsonschema:"Configuration file, empty when only defaults are used"`
+	LoadedAt                string         `json:"loaded_at,omitempty"`
+	Repositories            []Repository   `json:"repositories"`
+	RepositoriesSource      string         `json:"repositories_source" jsonschema:"Where the repositories come from: live configuration, the product profile, a repositories file or ConfigMap, or built-in"`
+	OCPVersions             []string       `json:"ocp_versions"`
+	FBCAllowedPackages      []string       `json:"fbc_allowed_packages"`
+	TemplateOverrides       []string       `json:"template_overrides,omitempty" jsonschema:"Names of the templates overridden by the configuration file"`
+	Locale                  string         `json:"locale,omitempty" jsonschema:"Locale of the notification templates"`
+	DocsRepository          string         `json:"docs_repository"`
+	DocsBranch              string         `json:"docs_branch"`
+	DocsFork                string         `json:"docs_fork,omitempty"`
+	KonfluxRepository       string         `json:"konflux_repository"`
+	KonfluxFork             string         `json:"konflux_fork,omitempty"`
+	ArtifactRepository      string         `json:"artifact_repository,omitempty"`
+	DynamicComponentMapping bool           `json:"dynamic_component_mapping"`
+	ConfirmRepoThreshold    int            `json:"confirm_repo_threshold"`
+	Product                 string         `json:"product" jsonschema:"Name of the selected product profile"`
+	ProductProfile          ProductProfile `json:"product_profile"`
+}
+
+// loadedConfig holds the last configuration successfully read from the file
+var (
+	loadedConfig     ServerConfig
+	loadedConfigPath string
+	loadedConfigTime time.Time
+	loadedConfigMu   sync.RWMutex
+)
+
+func currentConfig() ServerConfig {
+	loadedConfigMu.RLock()
+	defer loadedConfigMu.RUnlock()
+	return loadedConfig
+}
+
+// readServerConfig reads and validates the configuration file
+func readServerConfig(path string) (ServerConfig, error) {
+	var config ServerConfig
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return config, fmt.Errorf("failed to read configuration %s: %w", path, err)
+	}
+	if err := yaml.Unmarshal(content, &config); err != nil {
+		return config, fmt.Errorf("failed to parse configuration %s: %w", path, err)
+	}
+
+	if err := validateRepositories(config.Repositories); err != nil {
+		return config, fmt.Errorf("invalid %s: %w", path, err)
+	}
+	if err := validateProducts(config.Product, config.Products); err != nil {
+		return config, fmt.Errorf("invalid %s: %w", path, err)
+	}
+	for _, project := range []string{config.KonfluxRepository, config.KonfluxFork} {
+		if err := validateGitLabProject(project); err != nil {
+			return config, fmt.Errorf("invalid %s: %w", path, err)
+		}
+	}
+	if err := validateLocale(config.Locale); err != nil {
+		return config, fmt.Errorf("invalid %s: %w", path, err)
+	}
+	for key, text := range config.Templates {
+		// Notification templates are overridden per locale as <name>.<locale>
+		name, _ := splitLocalizedName(key, isLocalizedTemplate)
+		if _, ok := registeredTemplates[name]; !ok {
+			return config, fmt.Errorf("invalid template override in %s: %w", path, unknownTemplateError(name))
+		}
+		if _, err := newRegisteredTemplate(name, registeredTemplates[name], text); err != nil {
+			return config, fmt.Errorf("invalid template override %q in %s: %w", key, path, err)
+		}
+	}
+	return config, nil
+}
+
+// reloadServerConfig replaces the loaded configuration with the content of the
+// file. The previous configuration is kept when the file is invalid.
+func reloadServerConfig(path string) error {
+	config, err := readServerConfig(path)
+	if err != nil {
+		return err
+	}
+
+	loadedConfigMu.Lock()
+	defer loadedConfigMu.Unlock()
+	loadedConfig = config
+	loadedConfigPath = path
+	loadedConfigTime = time.Now()
+	return nil
+}
+
+// watchServerConfig loads the configuration file and reloads it whenever it
+// changes until the context is done. Reload failures are logged and leave the
+// previous configuration in place.
+//
+// The file is polled rather than watched with inotify: it is usually mounted
+// from a ConfigMap, which the kubelet updates by swapping the symlink of its
+// parent directory, so a watch on the file never sees the change. Stat follows
+// the symlinks and catches it, without a file notification dependency.
+func watchServerConfig(ctx context.Context) error {
+	path := os.Getenv(configFileEnv)
+	if path == "" {
+		return nil
+	}
+	if err := reloadServerConfig(path); err != nil {
+		return err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to stat configuration %s: %w", path, err)
+	}
+	lastModified, lastSize := info.ModTime(), info.Size()
+
+	go func() {
+		ticker := time.NewTicker(configPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			info, err := os.Stat(path)
+			if err != nil || (info.ModTime().Equal(lastModified) && info.Size() == lastSize) {
+				continue
+			}
+			lastModified, lastSize = info.ModTime(), info.Size()
+
+			if err := reloadServerConfig(path); err != nil {
+				slog.Warn("Keeping previous configuration", "error", err)
+				continue
+			}
+			slog.Info("Reloaded configuration", "path", path)
+		}
+	}()
+	return nil
+}
+
+// effectiveConfig returns the configuration currently in use
+func effectiveConfig() EffectiveConfig {
+	loadedConfigMu.RLock()
+	effective := EffectiveConfig{Source: loadedConfigPath}
+	if !loadedConfigTime.IsZero() {
+		effective.LoadedAt = loadedConfigTime.Format(time.RFC3339)
+	}
+	for name := range loadedConfig.Templates {
+		effective.TemplateOverrides = append(effective.TemplateOverrides, name)
+	}
+	loadedConfigMu.RUnlock()
+
+	sort.Strings(effective.TemplateOverrides)
+	effective.Repositories = releaseRepositories()
+	effective.RepositoriesSource = repositoriesSource()
+	effective.OCPVersions = configuredOCPVersions()
+	effective.FBCAllowedPackages = fbcAllowedPackages()
+	effective.DocsRepository, effective.DocsBranch, effective.DocsFork = docsRepository()
+	effective.KonfluxRepository, effective.KonfluxFork = konfluxRepository()
+	effective.ArtifactRepository = artifactRepository()
+	effective.DynamicComponentMapping = currentConfig().DynamicComponentMapping
+	effective.ConfirmRepoThreshold = confirmRepoThreshold()
+	effective.Product, effective.ProductProfile = currentProduct()
+	effective.Locale = currentConfig().Locale
+	return effective
+}
+
+func formatEffectiveConfig(config EffectiveConfig) string {
+	var sb strings.Builder
+	if config.Source == "" {
+		sb.WriteString("Using built-in defaults\n")
+	} else {
+		fmt.Fprintf(&sb, "Configuration loaded from %s at %s\n", config.Source, config.LoadedAt)
+	}
+
+	profile := config.ProductProfile
+	fmt.Fprintf(&sb, "\nProduct %s: %s applications in %s\n", config.Product, profile.Prefix, profile.Tenant)
+	fmt.Fprintf(&sb, "Policies: %s (stage), %s (prod), %s (FBC stage), %s (FBC prod)\n", profile.Policies.Stage, profile.Policies.Prod, profile.Policies.FBCStage, profile.Policies.FBCProd)
+	fmt.Fprintf(&sb, "Applications: %s\n", strings.Join(sortedComponentNames(profile.Components), ", "))
+
+	fmt.Fprintf(&sb, "\nRepositories (%s):\n", config.RepositoriesSource)
+	for _, repo := range config.Repositories {
+		if repo.Skip {
+			fmt.Fprintf(&sb, "- %s (skipped)\n", repo.Name)
+			continue
+		}
+		fmt.Fprintf(&sb, "- %s: %s from %s\n", repo.Name, repo.RepoURL, repo.SourceBranch)
+	}
+	fmt.Fprintf(&sb, "\nOCP versions: %s\n", strings.Join(config.OCPVersions, ", "))
+	fmt.Fprintf(&sb, "FBC allowed packages: %s\n", strings.Join(config.FBCAllowedPackages, ", "))
+	fmt.Fprintf(&sb, "Docs repository: %s (%s)", config.DocsRepository, config.DocsBranch)
+	if config.DocsFork != "" {
+		fmt.Fprintf(&sb, " through %s", config.DocsFork)
+	}
+	sb.WriteString("\n")
+	fmt.Fprintf(&sb, "Konflux release data repository: %s", config.KonfluxRepository)
+	if config.KonfluxFork != "" {
+		fmt.Fprintf(&sb, " through %s", config.KonfluxFork)
+	}
+	sb.WriteString("\n")
+	if config.ArtifactRepository != "" {
+		fmt.Fprintf(&sb, "Release records are pushed to %s\n", config.ArtifactRepository)
+	}
+	if config.DynamicComponentMapping {
+		sb.WriteString("Hack repositories missing from componentMapping are mapped dynamically\n")
+	}
+	fmt.Fprintf(&sb, "Calls pushing to more than %d repositories need a confirmed plan\n", config.ConfirmRepoThreshold)
+	if len(config.TemplateOverrides) > 0 {
+		fmt.Fprintf(&sb, "Overridden templates: %s\n", strings.Join(config.TemplateOverrides, ", "))
+	}
+	if config.Locale != "" {
+		fmt.Fprintf(&sb, "Notifications are rendered in the %s locale\n", config.Locale)
+	}
+	return sb.String()
+}
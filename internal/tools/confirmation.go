@@ -0,0 +1,283 @@
+package tools
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
+	mcp "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// confirmArg is the argument echoing the plan hash of a confirmed call
+	confirmArg = "confirm"
+
+	// defaultConfirmRepoThreshold is how many repositories a tool may push to
+	// without confirmation
+	defaultConfirmRepoThreshold = 3
+
+	// planTTL is how long a plan hash can be confirmed for
+	planTTL = 15 * time.Minute
+
+	// PlanHashMeta is the result metadata key holding the plan hash of a call
+	// held back for confirmation, for clients confirming on behalf of a person
+	PlanHashMeta = "release-mcp/plan-hash"
+)
+
+// ConfirmationPlan describes what a destructive tool call is about to change
+type ConfirmationPlan struct {
+	Targets []string // Repositories, registries or clusters the call changes
+	Prod    bool     // Whether the call changes a prod environment
+}
+
+// confirmationScope returns the plan of a tool call from its arguments
+type confirmationScope func(args map[string]any) ConfirmationPlan
+
+// confirmedTools holds the scopes of the tools requiring chained confirmation
+var (
+	confirmedTools   = map[string]confirmationScope{}
+	confirmedToolsMu sync.Mutex
+)
+
+type pendingPlanKey struct {
+	session *mcp.ServerSession
+	hash    string
+}
+
+// pendingPlans holds the plan hashes returned to sessions and not confirmed yet
+var (
+	pendingPlans   = map[pendingPlanKey]time.Time{}
+	pendingPlansMu sync.Mutex
+)
+
+// requireConfirmation makes calls of a tool pushing to more repositories than
+// the threshold, or changing prod, return a plan hash instead of running. The
+// call runs once repeated with the same arguments and the hash in the confirm
+// argument. It must be called before the tool is added to the server.
+func requireConfirmation(tool *mcp.Tool, scope confirmationScope) *mcp.Tool {
+	confirmedToolsMu.Lock()
+	confirmedTools[tool.Name] = scope
+	confirmedToolsMu.Unlock()
+
+	if tool.InputSchema.Properties == nil {
+		tool.InputSchema.Properties = map[string]*jsonschema.Schema{}
+	}
+	tool.InputSchema.Properties[confirmArg] = &jsonschema.Schema{
+		Type:        "string",
+		Description: "Plan hash returned by a previous call with the same arguments, required when the call pushes to many repositories or changes prod",
+	}
+	return tool
+}
+
+// confirmRepoThreshold returns how many repositories a tool may push to without confirmation
+func confirmRepoThreshold() int {
+	if threshold := currentConfig().ConfirmRepoThreshold; threshold > 0 {
+		return threshold
+	}
+	return defaultConfirmRepoThreshold
+}
+
+// planHash identifies a tool call by its name and arguments, without the confirmation
+func planHash(name string, args map[string]any) string {
+	unconfirmed := make(map[string]any, len(args))
+	for key, value := range args {
+		if key != confirmArg {
+			unconfirmed[key] = value
+		}
+	}
+	// Maps are encoded with sorted keys, so equal arguments hash the same
+	content, _ := json.Marshal(unconfirmed)
+	sum := sha256.Sum256(append([]byte(name+"\n"), content...))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// needsConfirmation returns the plan of a tool call and whether it must be
+// confirmed before running: it pushes to many repositories or changes prod
+func needsConfirmation(name string, args map[string]any) (ConfirmationPlan, bool) {
+	confirmedToolsMu.Lock()
+	scope := confirmedTools[name]
+	confirmedToolsMu.Unlock()
+	if scope == nil {
+		return ConfirmationPlan{}, false
+	}
+	plan := scope(args)
+	return plan, plan.Prod || len(plan.Targets) > confirmRepoThreshold()
+}
+
+// confirmationMiddleware holds back calls of confirmed tools whose plan needs
+// confirmation until they are repeated with the plan hash returned to the session
+func confirmationMiddleware(next mcp.MethodHandler[*mcp.ServerSession]) mcp.MethodHandler[*mcp.ServerSession] {
+	return func(ctx context.Context, session *mcp.ServerSession, method string, params mcp.Params) (mcp.Result, error) {
+		callParams, ok := params.(*mcp.CallToolParamsFor[json.RawMessage])
+		if method != "tools/call" || !ok {
+			return next(ctx, session, method, params)
+		}
+
+		var args map[string]any
+		_ = json.Unmarshal(callParams.Arguments, &args)
+		plan, needed := needsConfirmation(callParams.Name, args)
+		if !needed {
+			return next(ctx, session, method, params)
+		}
+
+		hash := planHash(callParams.Name, args)
+		now := time.Now()
+		key := pendingPlanKey{session: session, hash: hash}
+		confirm, _ := args[confirmArg].(string)
+
+		pendingPlansMu.Lock()
+		for pending, expires := range pendingPlans {
+			if !now.Before(expires) {
+				delete(pendingPlans, pending)
+			}
+		}
+		_, issued := pendingPlans[key]
+		if confirm == hash && issued {
+			delete(pendingPlans, key)
+			pendingPlansMu.Unlock()
+			return next(ctx, session, method, params)
+		}
+		pendingPlans[key] = now.Add(planTTL)
+		pendingPlansMu.Unlock()
+
+		return &mcp.CallToolResult{
+			Meta:    mcp.Meta{PlanHashMeta: hash},
+			Content: []mcp.Content{&mcp.TextContent{Text: formatConfirmationPlan(callParams.Name, plan, hash, confirm != "")}},
+			IsError: true,
+		}, nil
+	}
+}
+
+func formatConfirmationPlan(tool string, plan ConfirmationPlan, hash string, mismatch bool) string {
+	var sb strings.Builder
+	if mismatch {
+		sb.WriteString("The confirmation does not match a plan of this session: the arguments changed or the plan expired.\n\n")
+	}
+	fmt.Fprintf(&sb, "%s was not run, it needs confirmation because it", tool)
+	if plan.Prod {
+		sb.WriteString(" changes prod")
+	} else {
+		fmt.Fprintf(&sb, " pushes to %d repositories", len(plan.Targets))
+	}
+	sb.WriteString(". Plan:\n")
+	for _, target := range plan.Targets {
+		fmt.Fprintf(&sb, "- %s\n", target)
+	}
+	fmt.Fprintf(&sb, "\nCall %s again with the same arguments and %s set to %q within %s to proceed.\n", tool, confirmArg, hash, planTTL)
+	return sb.String()
+}
+
+// releaseRepositoriesScope plans a push to every release repository not skipped
+func releaseRepositoriesScope(map[string]any) ConfirmationPlan {
+	var plan ConfirmationPlan
+	for _, repo := range releaseRepositories() {
+		if !repo.Skip {
+			plan.Targets = append(plan.Targets, repo.Name)
+		}
+	}
+	return plan
+}
+
+// selectedRepositoriesScope plans a push to the release repositories named
+// by the repositories argument, or every release repository, unless the call
+// is a dry run
+func selectedRepositoriesScope(args map[string]any) ConfirmationPlan {
+	if dryRun, _ := args["dry_run"].(bool); dryRun {
+		return ConfirmationPlan{}
+	}
+	selected, _ := args["repositories"].([]any)
+	if len(selected) == 0 {
+		return releaseRepositoriesScope(args)
+	}
+	var plan ConfirmationPlan
+	for _, repo := range selected {
+		if name, ok := repo.(string); ok {
+			plan.Targets = append(plan.Targets, name)
+		}
+	}
+	return plan
+}
+
+// cherryPickScope plans a push to the release branches named by the branches
+// argument, unless the call is a dry run. Without branches, the call pushes to
+// every supported version, and always needs confirmation.
+func cherryPickScope(args map[string]any) ConfirmationPlan {
+	if dryRun, _ := args["dry_run"].(bool); dryRun {
+		return ConfirmationPlan{}
+	}
+	repository, _ := args["repository"].(string)
+	selected, _ := args["branches"].([]any)
+	if len(selected) == 0 {
+		return ConfirmationPlan{Targets: []string{repository + ": release branches of the supported versions"}, Prod: true}
+	}
+	var plan ConfirmationPlan
+	for _, branch := range selected {
+		if name, ok := branch.(string); ok {
+			plan.Targets = append(plan.Targets, repository+": "+name)
+		}
+	}
+	return plan
+}
+
+// prodReleasePlansScope plans a change of the prod release plans of a version,
+// unless the call is a dry run or limited to other environments
+func prodReleasePlansScope(target string) confirmationScope {
+	return func(args map[string]any) ConfirmationPlan {
+		version, _ := args["minor_version"].(string)
+		if version == "" {
+			version, _ = args["version"].(string)
+		}
+		plan := ConfirmationPlan{Targets: []string{fmt.Sprintf("%s: release plans of %s", target, version)}}
+		if dryRun, _ := args["dry_run"].(bool); dryRun {
+			return plan
+		}
+		plan.Prod = true
+		if environments, ok := args["environments"].([]any); ok && len(environments) > 0 {
+			plan.Prod = false
+			for _, environment := range environments {
+				plan.Prod = plan.Prod || environment == "prod"
+			}
+		}
+		return plan
+	}
+}
+
+// promotionScope plans the promotion of images to the prod registry
+func promotionScope(args map[string]any) ConfirmationPlan {
+	target, _ := args["target_registry"].(string)
+	if target == "" {
+		target = productImageNamespace("prod")
+	}
+	dryRun, _ := args["dry_run"].(bool)
+	return ConfirmationPlan{Targets: []string{target}, Prod: !dryRun && !strings.Contains(target, "stage")}
+}
+
+// orphanCleanupScope plans the removal of the orphaned release resources of a
+// tenant from konflux-release-data, which always needs confirmation
+func orphanCleanupScope(args map[string]any) ConfirmationPlan {
+	if cleanup, _ := args["cleanup"].(bool); !cleanup {
+		return ConfirmationPlan{}
+	}
+	dir, _ := args["rp_directory"].(string)
+	if dir == "" {
+		dir = tenantReleasePlanDir()
+	}
+	return ConfirmationPlan{Targets: []string{"konflux-release-data: orphaned release resources of " + filepath.Base(dir)}, Prod: true}
+}
+
+// releaseScope plans a Release through a prod ReleasePlan
+func releaseScope(args map[string]any) ConfirmationPlan {
+	snapshot, _ := args["snapshot"].(string)
+	releasePlan, _ := args["release_plan"].(string)
+	return ConfirmationPlan{
+		Targets: []string{fmt.Sprintf("Release of %s through %s", snapshot, releasePlan)},
+		Prod:    strings.Contains(releasePlan, "prod"),
+	}
+}
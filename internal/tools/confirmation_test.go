@@ -0,0 +1,69 @@
+package tools
+
+import "testing"
+
+func TestPlanHash(t *testing.T) {
+	base := map[string]any{"minor_version": "1.21", "repositories": []any{"operator", "pipeline"}}
+	tests := []struct {
+		name     string
+		tool     string
+		args     map[string]any
+		wantSame bool
+	}{
+		{name: "same call", tool: "release-repositories", args: map[string]any{"repositories": []any{"operator", "pipeline"}, "minor_version": "1.21"}, wantSame: true},
+		{name: "confirmation is ignored", tool: "release-repositories", args: map[string]any{"minor_version": "1.21", "repositories": []any{"operator", "pipeline"}, confirmArg: "0123456789ab"}, wantSame: true},
+		{name: "other tool", tool: "promote-images", args: base},
+		{name: "other version", tool: "release-repositories", args: map[string]any{"minor_version": "1.22", "repositories": []any{"operator", "pipeline"}}},
+		{name: "other repositories", tool: "release-repositories", args: map[string]any{"minor_version": "1.21", "repositories": []any{"operator"}}},
+	}
+	want := planHash("release-repositories", base)
+	if len(want) != 12 {
+		t.Fatalf("planHash() = %q, want 12 characters", want)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := planHash(tt.tool, tt.args); (got == want) != tt.wantSame {
+				t.Errorf("planHash(%q, %v) = %s, base call hashes to %s", tt.tool, tt.args, got, want)
+			}
+		})
+	}
+}
+
+func TestNeedsConfirmation(t *testing.T) {
+	const tool = "test-confirmed-push"
+	confirmedToolsMu.Lock()
+	confirmedTools[tool] = func(args map[string]any) ConfirmationPlan {
+		repos, _ := args["repositories"].([]any)
+		prod, _ := args["prod"].(bool)
+		plan := ConfirmationPlan{Prod: prod}
+		for _, repo := range repos {
+			plan.Targets = append(plan.Targets, repo.(string))
+		}
+		return plan
+	}
+	confirmedToolsMu.Unlock()
+	t.Cleanup(func() {
+		confirmedToolsMu.Lock()
+		delete(confirmedTools, tool)
+		confirmedToolsMu.Unlock()
+	})
+
+	tests := []struct {
+		name string
+		tool string
+		args map[string]any
+		want bool
+	}{
+		{name: "unconfirmed tool", tool: "list-releases", args: map[string]any{"prod": true}},
+		{name: "few repositories", tool: tool, args: map[string]any{"repositories": []any{"a", "b", "c"}}},
+		{name: "many repositories", tool: tool, args: map[string]any{"repositories": []any{"a", "b", "c", "d"}}, want: true},
+		{name: "prod", tool: tool, args: map[string]any{"repositories": []any{"a"}, "prod": true}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, got := needsConfirmation(tt.tool, tt.args); got != tt.want {
+				t.Errorf("needsConfirmation(%q, %v) = %t, want %t", tt.tool, tt.args, got, tt.want)
+			}
+		})
+	}
+}
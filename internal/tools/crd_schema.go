@@ -0,0 +1,352 @@
+package tools
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"knative.dev/pkg/injection"
+)
+
+// embeddedCRDs holds the CRDs generated manifests are validated against when
+// the cluster cannot be read
+//
+//go:embed crds/*.yaml
+var embeddedCRDs embed.FS
+
+// crdSchemaTTL is how long CRD schemas read from the cluster are reused
+const crdSchemaTTL = 10 * time.Minute
+
+var customResourceDefinitionGVR = schema.GroupVersionResource{
+	Group:    "apiextensions.k8s.io",
+	Version:  "v1",
+	Resource: "customresourcedefinitions",
+}
+
+// validatedKinds maps the kinds of the generated manifests validated against
+// their CRD to the name of the CRD
+var validatedKinds = map[string]string{
+	"appstudio.redhat.com/ReleasePlanAdmission": "releaseplanadmissions.appstudio.redhat.com",
+	"appstudio.redhat.com/ReleasePlan":          "releaseplans.appstudio.redhat.com",
+}
+
+// crdSchema is the subset of an OpenAPI v3 schema of a structural CRD that
+// generated manifests are validated against
+type crdSchema struct {
+	Type                  string                `json:"type"`
+	Properties            map[string]*crdSchema `json:"properties"`
+	AdditionalProperties  *crdSchemaOrBool      `json:"additionalProperties"`
+	Items                 *crdSchema            `json:"items"`
+	Required              []string              `json:"required"`
+	Enum                  []any                 `json:"enum"`
+	Pattern               string                `json:"pattern"`
+	MinLength             *int                  `json:"minLength"`
+	MaxLength             *int                  `json:"maxLength"`
+	Minimum               *float64              `json:"minimum"`
+	Maximum               *float64              `json:"maximum"`
+	MinItems              *int                  `json:"minItems"`
+	MaxItems              *int                  `json:"maxItems"`
+	Nullable              bool                  `json:"nullable"`
+	PreserveUnknownFields bool                  `json:"x-kubernetes-preserve-unknown-fields"`
+	IntOrString           bool                  `json:"x-kubernetes-int-or-string"`
+}
+
+// crdSchemaOrBool is an additionalProperties value, a schema or a boolean
+type crdSchemaOrBool struct {
+	Schema *crdSchema
+	Allows bool
+}
+
+func (s *crdSchemaOrBool) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &s.Allows); err == nil {
+		return nil
+	}
+	s.Allows = true
+	return json.Unmarshal(data, &s.Schema)
+}
+
+// cachedCRDSchema is the schema of a CRD version and where it was read from
+type cachedCRDSchema struct {
+	schema  *crdSchema
+	source  string
+	fetched time.Time
+}
+
+var (
+	crdClient    dynamic.Interface
+	crdSchemas   = map[string]cachedCRDSchema{}
+	crdSchemasMu sync.Mutex
+)
+
+// setCRDSchemaClient makes generated manifests validate against the CRDs
+// installed in the cluster of the server, when it can read them
+func setCRDSchemaClient(ctx context.Context) {
+	cfg := injection.GetConfig(ctx)
+	if cfg == nil {
+		return
+	}
+	if dc, err := dynamic.NewForConfig(cfg); err == nil {
+		crdSchemasMu.Lock()
+		crdClient = dc
+		crdSchemasMu.Unlock()
+	}
+}
+
+// lookupCRDSchema returns the schema of a version of a CRD, read from the
+// cluster or else from the embedded CRDs, and where it was read from
+func lookupCRDSchema(ctx context.Context, crdName, version string) (*crdSchema, string, error) {
+	key := crdName + "/" + version
+	crdSchemasMu.Lock()
+	defer crdSchemasMu.Unlock()
+	if cached, ok := crdSchemas[key]; ok && time.Since(cached.fetched) < crdSchemaTTL {
+		return cached.schema, cached.source, nil
+	}
+
+	var crd map[string]any
+	source := "cluster"
+	if crdClient != nil {
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+		if obj, err := crdClient.Resource(customResourceDefinitionGVR).Get(ctx, crdName, metav1.GetOptions{}); err == nil {
+			crd = obj.Object
+		}
+	}
+	if crd == nil {
+		source = "embedded"
+		content, err := embeddedCRDs.ReadFile("crds/" + strings.Split(crdName, ".")[0] + ".yaml")
+		if err != nil {
+			return nil, "", fmt.Errorf("no schema for %s: %w", crdName, err)
+		}
+		if err := yaml.Unmarshal(content, &crd); err != nil {
+			return nil, "", fmt.Errorf("failed to parse the embedded %s CRD: %w", crdName, err)
+		}
+	}
+
+	var definition struct {
+		Spec struct {
+			Versions []struct {
+				Name   string `json:"name"`
+				Schema struct {
+					OpenAPIV3Schema *crdSchema `json:"openAPIV3Schema"`
+				} `json:"schema"`
+			} `json:"versions"`
+		} `json:"spec"`
+	}
+	raw, err := json.Marshal(crd)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := json.Unmarshal(raw, &definition); err != nil {
+		return nil, "", fmt.Errorf("failed to read the schema of the %s CRD: %w", crdName, err)
+	}
+	for _, v := range definition.Spec.Versions {
+		if v.Name == version && v.Schema.OpenAPIV3Schema != nil {
+			crdSchemas[key] = cachedCRDSchema{schema: v.Schema.OpenAPIV3Schema, source: source, fetched: time.Now()}
+			return v.Schema.OpenAPIV3Schema, source, nil
+		}
+	}
+	return nil, "", fmt.Errorf("the %s CRD of the %s has no schema for %s", crdName, source, version)
+}
+
+// validateManifest validates a generated manifest against the CRD of its
+// kind, returning every field that does not match the schema. Manifests of
+// other kinds are not validated.
+func validateManifest(ctx context.Context, content []byte) error {
+	var manifest map[string]any
+	if err := yaml.Unmarshal(content, &manifest); err != nil {
+		return fmt.Errorf("generated manifest is not valid YAML: %w", err)
+	}
+	apiVersion, _ := manifest["apiVersion"].(string)
+	kind, _ := manifest["kind"].(string)
+	group, version, found := strings.Cut(apiVersion, "/")
+	if !found {
+		return nil
+	}
+	crdName, ok := validatedKinds[group+"/"+kind]
+	if !ok {
+		return nil
+	}
+	crd, source, err := lookupCRDSchema(ctx, crdName, version)
+	if err != nil {
+		return err
+	}
+
+	var errs []string
+	validateValue(crd, manifest, "", &errs)
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("generated %s does not match the %s %s CRD:\n- %s", kind, source, apiVersion, strings.Join(errs, "\n- "))
+}
+
+// validateValue appends the errors of a value against its schema to errs,
+// prefixed with the path of the field
+func validateValue(s *crdSchema, value any, path string, errs *[]string) {
+	field := path
+	if field == "" {
+		field = "<root>"
+	}
+	if value == nil {
+		if !s.Nullable && !s.PreserveUnknownFields {
+			*errs = append(*errs, fmt.Sprintf("%s: must not be null", field))
+		}
+		return
+	}
+	if s.IntOrString {
+		switch value.(type) {
+		case string, int, int64, uint64:
+		default:
+			*errs = append(*errs, fmt.Sprintf("%s: must be an integer or a string, got %s", field, jsonType(value)))
+		}
+		return
+	}
+	if s.Type != "" && !matchesType(s.Type, value) {
+		*errs = append(*errs, fmt.Sprintf("%s: must be of type %s, got %s", field, s.Type, jsonType(value)))
+		return
+	}
+
+	if len(s.Enum) > 0 {
+		allowed := false
+		var values []string
+		for _, e := range s.Enum {
+			if fmt.Sprint(e) == fmt.Sprint(value) {
+				allowed = true
+			}
+			values = append(values, fmt.Sprintf("%q", fmt.Sprint(e)))
+		}
+		if !allowed {
+			*errs = append(*errs, fmt.Sprintf("%s: unsupported value %q, supported values: %s", field, fmt.Sprint(value), strings.Join(values, ", ")))
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		if s.MinLength != nil && len(v) < *s.MinLength {
+			*errs = append(*errs, fmt.Sprintf("%s: must be at least %d characters long", field, *s.MinLength))
+		}
+		if s.MaxLength != nil && len(v) > *s.MaxLength {
+			*errs = append(*errs, fmt.Sprintf("%s: must be at most %d characters long, got %d", field, *s.MaxLength, len(v)))
+		}
+		if s.Pattern != "" {
+			if re, err := regexp.Compile(s.Pattern); err == nil && !re.MatchString(v) {
+				*errs = append(*errs, fmt.Sprintf("%s: %q does not match %s", field, v, s.Pattern))
+			}
+		}
+	case int, int64, uint64, float64:
+		n := toFloat(v)
+		if s.Minimum != nil && n < *s.Minimum {
+			*errs = append(*errs, fmt.Sprintf("%s: must be at least %v", field, *s.Minimum))
+		}
+		if s.Maximum != nil && n > *s.Maximum {
+			*errs = append(*errs, fmt.Sprintf("%s: must be at most %v", field, *s.Maximum))
+		}
+	case []any:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			*errs = append(*errs, fmt.Sprintf("%s: must have at least %d items", field, *s.MinItems))
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			*errs = append(*errs, fmt.Sprintf("%s: must have at most %d items", field, *s.MaxItems))
+		}
+		if s.Items != nil {
+			for i, item := range v {
+				validateValue(s.Items, item, fmt.Sprintf("%s[%d]", path, i), errs)
+			}
+		}
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				*errs = append(*errs, fmt.Sprintf("%s: required field is missing", joinFieldPath(path, name)))
+			}
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if property, ok := s.Properties[name]; ok {
+				validateValue(property, v[name], joinFieldPath(path, name), errs)
+				continue
+			}
+			switch {
+			case s.AdditionalProperties != nil && s.AdditionalProperties.Schema != nil:
+				validateValue(s.AdditionalProperties.Schema, v[name], joinFieldPath(path, name), errs)
+			case s.AdditionalProperties != nil && s.AdditionalProperties.Allows, s.PreserveUnknownFields:
+			case len(s.Properties) == 0 && s.AdditionalProperties == nil:
+				// Objects without properties, such as metadata, are not pruned
+			default:
+				*errs = append(*errs, fmt.Sprintf("%s: unknown field", joinFieldPath(path, name)))
+			}
+		}
+	}
+}
+
+func joinFieldPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// matchesType reports whether a value decoded from YAML has an OpenAPI type
+func matchesType(typ string, value any) bool {
+	switch value.(type) {
+	case string:
+		return typ == "string"
+	case bool:
+		return typ == "boolean"
+	case int, int64, uint64:
+		return typ == "integer" || typ == "number"
+	case float64:
+		f := value.(float64)
+		return typ == "number" || (typ == "integer" && f == math.Trunc(f))
+	case []any:
+		return typ == "array"
+	case map[string]any:
+		return typ == "object"
+	}
+	return false
+}
+
+func jsonType(value any) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case int, int64, uint64:
+		return "integer"
+	case float64:
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func toFloat(value any) float64 {
+	switch n := value.(type) {
+	case int:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case uint64:
+		return float64(n)
+	case float64:
+		return n
+	}
+	return 0
+}
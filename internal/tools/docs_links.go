@@ -0,0 +1,192 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/sync/errgroup"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// linkCheckWorkers bounds the number of links checked concurrently
+const linkCheckWorkers = 8
+
+// releaseNotesURL matches the URLs in release notes text, including markdown links
+var releaseNotesURL = regexp.MustCompile(`https?://[^\s"'<>()\[\]]+`)
+
+// LinkCheck represents a URL referenced by the release notes and whether it resolves
+type LinkCheck struct {
+	URL        string   `json:"url"`
+	Sources    []string `json:"sources" jsonschema:"Generated manifests and release notes fields referencing the URL"`
+	StatusCode int      `json:"status_code,omitempty"`
+	FinalURL   string   `json:"final_url,omitempty" jsonschema:"URL the link redirects to, when it redirects"`
+	OK         bool     `json:"ok"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// DocsLinksResult represents the structured result of check-release-notes-links
+type DocsLinksResult struct {
+	MinorVersion string      `json:"minor_version"`
+	FullVersion  string      `json:"full_version"`
+	Links        []LinkCheck `json:"links"`
+	Broken       int         `json:"broken"`
+}
+
+// checkReleaseNotesLinks renders the release plans of a release and checks
+// every URL of their release notes responds, so broken links are caught
+// before they are published in an advisory. The versioned documentation of
+// the release is checked too, as the advisory points readers to it.
+func checkReleaseNotesLinks(ctx context.Context, config RPAConfig, versionedDocs bool) (DocsLinksResult, error) {
+	_, fullVersion := releaseNotesType(config)
+	result := DocsLinksResult{MinorVersion: config.MinorVersion, FullVersion: fullVersion}
+
+	// Render into a fresh directory so only the manifests of this release are read
+	workDir, err := os.MkdirTemp("", "release-notes-links-*")
+	if err != nil {
+		return result, fmt.Errorf("failed to create working directory: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+	config.RepoPath = workDir
+
+	if err := createRPAs(ctx, config); err != nil {
+		return result, fmt.Errorf("failed to render ReleasePlanAdmissions: %w", err)
+	}
+	if err := createRPs(ctx, config); err != nil {
+		return result, fmt.Errorf("failed to render ReleasePlans: %w", err)
+	}
+	objects, err := readManifests(workDir)
+	if err != nil {
+		return result, err
+	}
+
+	sources := make(map[string][]string)
+	for _, obj := range objects {
+		collectReleaseNotesURLs(obj, sources)
+	}
+	if docs := product().Documentation; versionedDocs && docs != "" {
+		url := fmt.Sprintf("%s/%s", docs, config.MinorVersion)
+		sources[url] = append(sources[url], "versioned documentation of "+config.MinorVersion)
+	}
+	for url, from := range sources {
+		sort.Strings(from)
+		result.Links = append(result.Links, LinkCheck{URL: url, Sources: from})
+	}
+	sort.Slice(result.Links, func(i, j int) bool { return result.Links[i].URL < result.Links[j].URL })
+
+	client, err := httpClient()
+	if err != nil {
+		return result, err
+	}
+	var g errgroup.Group
+	g.SetLimit(linkCheckWorkers)
+	for i := range result.Links {
+		g.Go(func() error {
+			checkLink(ctx, client, &result.Links[i])
+			return nil
+		})
+	}
+	_ = g.Wait()
+	for _, link := range result.Links {
+		if !link.OK {
+			result.Broken++
+		}
+	}
+	return result, nil
+}
+
+// collectReleaseNotesURLs adds the URLs found in the release notes of a
+// manifest to sources, with the manifest and field they were found in
+func collectReleaseNotesURLs(obj *unstructured.Unstructured, sources map[string][]string) {
+	notes, found, err := unstructured.NestedMap(obj.Object, "spec", "data", "releaseNotes")
+	if err != nil || !found {
+		return
+	}
+	var walk func(field string, value any)
+	walk = func(field string, value any) {
+		switch v := value.(type) {
+		case string:
+			seen := make(map[string]bool)
+			for _, url := range releaseNotesURL.FindAllString(v, -1) {
+				url = strings.TrimRight(url, ".,;:")
+				if seen[url] {
+					continue
+				}
+				seen[url] = true
+				sources[url] = append(sources[url], fmt.Sprintf("%s %s: %s", obj.GetKind(), obj.GetName(), field))
+			}
+		case []any:
+			for _, item := range v {
+				walk(field, item)
+			}
+		case map[string]any:
+			for key, item := range v {
+				walk(field+"."+key, item)
+			}
+		}
+	}
+	for key, value := range notes {
+		walk("releaseNotes."+key, value)
+	}
+}
+
+// checkLink requests a URL, falling back to GET for servers refusing HEAD.
+// Redirects are followed, the link is OK when the final response is a 200.
+func checkLink(ctx context.Context, client *http.Client, link *LinkCheck) {
+	var resp *http.Response
+	for _, method := range []string{http.MethodHead, http.MethodGet} {
+		req, err := http.NewRequestWithContext(ctx, method, link.URL, nil)
+		if err != nil {
+			link.Error = err.Error()
+			return
+		}
+		if resp, err = client.Do(req); err != nil {
+			link.Error = err.Error()
+			return
+		}
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusMethodNotAllowed && resp.StatusCode != http.StatusForbidden {
+			break
+		}
+	}
+	link.StatusCode = resp.StatusCode
+	link.OK = resp.StatusCode == http.StatusOK
+	if final := resp.Request.URL.String(); final != link.URL {
+		link.FinalURL = final
+	}
+}
+
+func formatDocsLinks(result DocsLinksResult) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Release notes links of %s:\n", result.FullVersion)
+	for _, link := range result.Links {
+		switch {
+		case link.Error != "":
+			fmt.Fprintf(&sb, "✗ %s: %s\n", link.URL, link.Error)
+		case !link.OK:
+			fmt.Fprintf(&sb, "✗ %s: HTTP %d\n", link.URL, link.StatusCode)
+		default:
+			fmt.Fprintf(&sb, "✓ %s\n", link.URL)
+		}
+		if link.FinalURL != "" {
+			fmt.Fprintf(&sb, "  redirects to %s\n", link.FinalURL)
+		}
+		if !link.OK {
+			for _, source := range link.Sources {
+				fmt.Fprintf(&sb, "  referenced by %s\n", source)
+			}
+		}
+	}
+	if result.Broken > 0 {
+		fmt.Fprintf(&sb, "\n%d of %d links are broken, fix them before the advisory is published\n", result.Broken, len(result.Links))
+	} else {
+		fmt.Fprintf(&sb, "\nAll %d links resolve\n", len(result.Links))
+	}
+	return sb.String()
+}
@@ -0,0 +1,85 @@
+package tools
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// defaultSupportMonths is how long a minor version is supported after GA
+const defaultSupportMonths = 12
+
+// EOLConfig represents the configuration for computing end-of-life data
+type EOLConfig struct {
+	GADates       map[string]string // map of minor version to GA date (YYYY-MM-DD)
+	SupportMonths int
+	AsOf          time.Time
+}
+
+// EOLEntry represents the support status of a single minor version
+type EOLEntry struct {
+	Version   string `json:"version"`
+	GADate    string `json:"ga_date"`
+	EOLDate   string `json:"eol_date"`
+	DaysLeft  int    `json:"days_left"`
+	EndOfLife bool   `json:"end_of_life"`
+}
+
+func computeEOL(config EOLConfig) ([]EOLEntry, error) {
+	supportMonths := config.SupportMonths
+	if supportMonths <= 0 {
+		supportMonths = defaultSupportMonths
+	}
+	asOf := config.AsOf.Truncate(24 * time.Hour)
+
+	var entries []EOLEntry
+	for version, gaDate := range config.GADates {
+		ga, err := time.Parse("2006-01-02", gaDate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid GA date %q for version %s: %w", gaDate, version, err)
+		}
+		eol := ga.AddDate(0, supportMonths, 0)
+		entries = append(entries, EOLEntry{
+			Version:   version,
+			GADate:    ga.Format("2006-01-02"),
+			EOLDate:   eol.Format("2006-01-02"),
+			DaysLeft:  int(eol.Sub(asOf).Hours() / 24),
+			EndOfLife: !eol.After(asOf),
+		})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].EOLDate < entries[j].EOLDate
+	})
+	return entries, nil
+}
+
+// formatEOLAnnouncement drafts the announcement text for versions that have reached end of support
+func formatEOLAnnouncement(entries []EOLEntry) string {
+	var eolVersions, supported []string
+	for _, entry := range entries {
+		if entry.EndOfLife {
+			eolVersions = append(eolVersions, fmt.Sprintf("- %s (GA %s, end of support %s)", entry.Version, entry.GADate, entry.EOLDate))
+		} else {
+			supported = append(supported, fmt.Sprintf("- %s (supported until %s)", entry.Version, entry.EOLDate))
+		}
+	}
+
+	if len(eolVersions) == 0 {
+		return fmt.Sprintf("No %s versions have reached end of support.", product().DisplayName)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Subject: %s end of support announcement\n\n", product().DisplayName)
+	fmt.Fprintf(&sb, "The following %s versions have reached end of support and will no longer receive bug fixes or security updates:\n\n", product().DisplayName)
+	sb.WriteString(strings.Join(eolVersions, "\n"))
+	sb.WriteString("\n\n")
+	if len(supported) > 0 {
+		sb.WriteString("Please upgrade to a supported version:\n\n")
+		sb.WriteString(strings.Join(supported, "\n"))
+		sb.WriteString("\n\n")
+	}
+	sb.WriteString("For more details see [product documentation](https://docs.redhat.com/en/documentation/red_hat_openshift_pipelines).\n")
+	return sb.String()
+}
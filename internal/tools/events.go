@@ -0,0 +1,156 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// EventType identifies a domain event published by the tools
+type EventType string
+
+// Domain events. Data holds the value documented for each type.
+const (
+	EventToolCompleted    EventType = "ToolCompleted"    // AuditEvent of a finished tool call
+	EventBranchPushed     EventType = "BranchPushed"     // PushedBranch
+	EventBranchCut        EventType = "BranchCut"        // PushedBranch of a new release branch
+	EventMRCreated        EventType = "MRCreated"        // ChangeRequestInfo of an opened pull or merge request
+	EventReleaseTriggered EventType = "ReleaseTriggered" // TriggerReleaseResult of a created Release
+	EventImagePromoted    EventType = "ImagePromoted"    // ImagePromotion of a verified copy
+)
+
+// Event represents something a tool did, delivered to the subscribers of its type
+type Event struct {
+	Type    EventType `json:"type"`
+	Time    time.Time `json:"time"`
+	Tool    string    `json:"tool,omitempty" jsonschema:"Tool call the event happened in"`
+	Version string    `json:"version,omitempty"`
+	Data    any       `json:"data,omitempty"`
+}
+
+// eventHandler consumes an event, with the context of the tool call publishing it
+type eventHandler func(ctx context.Context, event Event) error
+
+type eventSubscription struct {
+	name    string
+	types   map[EventType]bool
+	handler eventHandler
+}
+
+// eventSubscriptions holds the subscribers of domain events, in subscription order
+var (
+	eventSubscriptions   []eventSubscription
+	eventSubscriptionsMu sync.RWMutex
+)
+
+// subscribe registers a handler for events of the given types, or of every
+// type when none is given. Handlers run synchronously in the goroutine of the
+// publisher, in subscription order, and must not block.
+func subscribe(name string, handler eventHandler, types ...EventType) {
+	subscription := eventSubscription{name: name, handler: handler}
+	if len(types) > 0 {
+		subscription.types = make(map[EventType]bool)
+		for _, eventType := range types {
+			subscription.types[eventType] = true
+		}
+	}
+	eventSubscriptionsMu.Lock()
+	defer eventSubscriptionsMu.Unlock()
+	eventSubscriptions = append(eventSubscriptions, subscription)
+}
+
+type eventSourceKey struct{}
+
+// eventSource identifies the tool call events are published from
+type eventSource struct {
+	tool    string
+	version string
+}
+
+// withEventSource returns a context publishing events as coming from a tool call
+func withEventSource(ctx context.Context, tool, version string) context.Context {
+	return context.WithValue(ctx, eventSourceKey{}, eventSource{tool: tool, version: version})
+}
+
+// publish delivers an event to its subscribers, filling in its time and the
+// tool call it comes from. Every subscriber gets the event even when others
+// fail, and the failures are returned together for publishers that depend on
+// a subscriber, such as a state store.
+func publish(ctx context.Context, event Event) error {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	if source, ok := ctx.Value(eventSourceKey{}).(eventSource); ok {
+		if event.Tool == "" {
+			event.Tool = source.tool
+		}
+		if event.Version == "" {
+			event.Version = source.version
+		}
+	}
+
+	eventSubscriptionsMu.RLock()
+	subscriptions := append([]eventSubscription(nil), eventSubscriptions...)
+	eventSubscriptionsMu.RUnlock()
+
+	var errs []error
+	for _, subscription := range subscriptions {
+		if subscription.types != nil && !subscription.types[event.Type] {
+			continue
+		}
+		if err := deliverEvent(ctx, subscription, event); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", subscription.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// deliverEvent runs a handler, turning its panics into errors so one
+// subscriber cannot take down the tool call
+func deliverEvent(ctx context.Context, subscription eventSubscription, event Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic handling %s: %v", event.Type, r)
+		}
+	}()
+	return subscription.handler(ctx, event)
+}
+
+// eventCounts exposes how many events of each type were published under /debug/vars
+var eventCounts = expvar.NewMap("release_mcp_events")
+
+func init() {
+	subscribe("audit-log", func(_ context.Context, event Event) error {
+		audit, ok := event.Data.(AuditEvent)
+		if !ok {
+			return nil
+		}
+		return recordAuditEvent(audit)
+	}, EventToolCompleted)
+
+	subscribe("promotion-log", func(_ context.Context, event Event) error {
+		promotion, ok := event.Data.(ImagePromotion)
+		if !ok {
+			return nil
+		}
+		return recordPromotion(event.Version, promotion)
+	}, EventImagePromoted)
+
+	subscribe("artifacts", func(ctx context.Context, event Event) error {
+		switch data := event.Data.(type) {
+		case PushedBranch:
+			runArtifactsFrom(ctx).recordBranch(data)
+		case ChangeRequestInfo:
+			runArtifactsFrom(ctx).recordChangeRequest(data.URL)
+		}
+		return nil
+	}, EventBranchPushed, EventMRCreated)
+
+	subscribe("metrics", func(_ context.Context, event Event) error {
+		eventCounts.Add(string(event.Type), 1)
+		return nil
+	})
+}
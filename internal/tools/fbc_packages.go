@@ -0,0 +1,189 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// fbcAllowedPackagesEnv is the environment variable overriding the packages
+	// FBC RPAs allow, as a comma-separated list
+	fbcAllowedPackagesEnv = "RELEASE_MCP_FBC_ALLOWED_PACKAGES"
+
+	// bundlePackageAnnotation declares the operator package name of a bundle
+	bundlePackageAnnotation = "operators.operatorframework.io.bundle.package.v1"
+
+	// operatorBundleAnnotationsPath is the bundle metadata file within the operator repository
+	operatorBundleAnnotationsPath = ".konflux/olm-catalog/bundle/metadata/annotations.yaml"
+)
+
+// fbcAllowedPackages returns the packages rendered into the allowedPackages of
+// FBC RPAs. The server configuration takes precedence over the environment,
+// which takes precedence over the product profile.
+func fbcAllowedPackages() []string {
+	if packages := currentConfig().FBCAllowedPackages; len(packages) > 0 {
+		return packages
+	}
+	value := os.Getenv(fbcAllowedPackagesEnv)
+	if value == "" {
+		return product().FBCPackages
+	}
+	var packages []string
+	for _, pkg := range strings.Split(value, ",") {
+		if pkg = strings.TrimSpace(pkg); pkg != "" {
+			packages = append(packages, pkg)
+		}
+	}
+	return packages
+}
+
+// FBCPackagesConfig represents the configuration for checking FBC allowedPackages
+type FBCPackagesConfig struct {
+	RepoPath     string
+	Dir          string // Relative to the konflux-release-data root
+	MinorVersion string // Only check RPAs of this version and read the bundle from its release branch when set
+	BundlePath   string // Relative to the operator repository root
+}
+
+// FBCPackagesRPA represents the allowedPackages of a single FBC RPA
+type FBCPackagesRPA struct {
+	File            string   `json:"file"`
+	AllowedPackages []string `json:"allowed_packages,omitempty"`
+	Matches         bool     `json:"matches" jsonschema:"Whether allowedPackages is exactly the bundle package"`
+}
+
+// FBCPackagesResult represents the structured result of check-fbc-allowed-packages
+type FBCPackagesResult struct {
+	Package    string           `json:"package" jsonschema:"Operator package name declared in the bundle metadata"`
+	BundleRef  string           `json:"bundle_ref" jsonschema:"Operator branch the bundle metadata was read from"`
+	Consistent bool             `json:"consistent" jsonschema:"Whether every FBC RPA allows exactly the bundle package"`
+	RPAs       []FBCPackagesRPA `json:"rpas,omitempty"`
+}
+
+func checkFBCAllowedPackages(ctx context.Context, config FBCPackagesConfig) (FBCPackagesResult, error) {
+	result := FBCPackagesResult{Consistent: true, BundleRef: "next"}
+	if config.MinorVersion != "" {
+		result.BundleRef = fmt.Sprintf("release-v%s.x", config.MinorVersion)
+	}
+	if config.BundlePath == "" {
+		config.BundlePath = operatorBundleAnnotationsPath
+	}
+
+	pkg, err := bundlePackageName(ctx, result.BundleRef, config.BundlePath)
+	if err != nil {
+		return result, err
+	}
+	result.Package = pkg
+
+	if err := cloneKonfluxRepo(ctx, RPAConfig{RepoPath: config.RepoPath}); err != nil {
+		return result, fmt.Errorf("failed to clone konflux-release-data repository: %w", err)
+	}
+
+	dir := filepath.Join(config.RepoPath, config.Dir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return result, fmt.Errorf("failed to read directory %s: %w", dir, err)
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".yaml") || !strings.Contains(name, "-fbc-") {
+			continue
+		}
+		if config.MinorVersion != "" && !strings.Contains(name, "-"+config.MinorVersion+"-") {
+			continue
+		}
+
+		packages, err := rpaAllowedPackages(filepath.Join(dir, name))
+		if err != nil {
+			return result, err
+		}
+		rpa := FBCPackagesRPA{
+			File:            name,
+			AllowedPackages: packages,
+			Matches:         len(packages) == 1 && packages[0] == pkg,
+		}
+		if !rpa.Matches {
+			result.Consistent = false
+		}
+		result.RPAs = append(result.RPAs, rpa)
+	}
+
+	sort.Slice(result.RPAs, func(i, j int) bool { return result.RPAs[i].File < result.RPAs[j].File })
+	return result, nil
+}
+
+// bundlePackageName reads the operator package name from the bundle metadata
+// annotations at ref of the operator repository
+func bundlePackageName(ctx context.Context, ref, bundlePath string) (string, error) {
+	client, err := newGitHubClient()
+	if err != nil {
+		return "", err
+	}
+
+	content, err := client.fileContent(ctx, operatorRepository(), bundlePath, ref)
+	if err != nil {
+		return "", err
+	}
+
+	var metadata struct {
+		Annotations map[string]string `yaml:"annotations"`
+	}
+	if err := yaml.Unmarshal(content, &metadata); err != nil {
+		return "", fmt.Errorf("failed to parse %s: %w", bundlePath, err)
+	}
+	pkg := metadata.Annotations[bundlePackageAnnotation]
+	if pkg == "" {
+		return "", fmt.Errorf("%s does not declare %s", bundlePath, bundlePackageAnnotation)
+	}
+	return pkg, nil
+}
+
+// rpaAllowedPackages returns spec.data.fbc.allowedPackages of an RPA file
+func rpaAllowedPackages(path string) ([]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var rpa struct {
+		Spec struct {
+			Data struct {
+				FBC struct {
+					AllowedPackages []string `yaml:"allowedPackages"`
+				} `yaml:"fbc"`
+			} `yaml:"data"`
+		} `yaml:"spec"`
+	}
+	if err := yaml.Unmarshal(content, &rpa); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return rpa.Spec.Data.FBC.AllowedPackages, nil
+}
+
+func formatFBCAllowedPackages(result FBCPackagesResult) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Bundle package on %s: %s\n", result.BundleRef, result.Package)
+	if len(result.RPAs) == 0 {
+		sb.WriteString("No FBC RPAs found\n")
+		return sb.String()
+	}
+	for _, rpa := range result.RPAs {
+		if rpa.Matches {
+			fmt.Fprintf(&sb, "✓ %s\n", rpa.File)
+			continue
+		}
+		allowed := strings.Join(rpa.AllowedPackages, ", ")
+		if allowed == "" {
+			allowed = "none"
+		}
+		fmt.Fprintf(&sb, "✗ %s allows %s\n", rpa.File, allowed)
+	}
+	return sb.String()
+}
@@ -0,0 +1,251 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// Annotations stamped onto generated manifests, recording what generated them
+	generatorVersionAnnotation = "release-mcp.openshift-pipelines.org/generator-version"
+	templateAnnotation         = "release-mcp.openshift-pipelines.org/template"
+	templateHashAnnotation     = "release-mcp.openshift-pipelines.org/template-hash"
+)
+
+// generatorVersion is the server version stamped onto generated manifests
+var (
+	generatorVersion   = "devel"
+	generatorVersionMu sync.RWMutex
+)
+
+// SetGeneratorVersion sets the server version stamped onto generated manifests
+func SetGeneratorVersion(version string) {
+	if version == "" {
+		return
+	}
+	generatorVersionMu.Lock()
+	defer generatorVersionMu.Unlock()
+	generatorVersion = version
+}
+
+func currentGeneratorVersion() string {
+	generatorVersionMu.RLock()
+	defer generatorVersionMu.RUnlock()
+	return generatorVersion
+}
+
+// templateHash returns the hash of the text a registered template currently
+// renders from, including its template files and configuration override.
+// It only changes when the template does, so files rendered from the same
+// template text carry the same hash.
+func templateHash(name string) (string, bool) {
+	registered, ok := lookupTemplate(name)
+	if !ok {
+		return "", false
+	}
+	sum := sha256.Sum256([]byte(registered.text))
+	return "sha256:" + hex.EncodeToString(sum[:8]), true
+}
+
+// stampGenerated adds the generator version, template and template hash
+// annotations to the metadata of a manifest rendered from a registered
+// template. The rest of the output is left as rendered, and Markdown output
+// is returned unchanged.
+func stampGenerated(name string, content []byte) []byte {
+	registered, ok := lookupTemplate(name)
+	if !ok || registered.markdown {
+		return content
+	}
+	hash, _ := templateHash(name)
+	stamps := []string{
+		fmt.Sprintf("%s: %q", generatorVersionAnnotation, currentGeneratorVersion()),
+		fmt.Sprintf("%s: %q", templateAnnotation, name),
+		fmt.Sprintf("%s: %q", templateHashAnnotation, hash),
+	}
+
+	lines := strings.Split(string(content), "\n")
+	metadata := -1
+	for i, line := range lines {
+		if strings.TrimRight(line, " ") == "metadata:" {
+			metadata = i
+			break
+		}
+	}
+	if metadata < 0 {
+		return content
+	}
+
+	// Fields of metadata are the indented lines following it
+	end := metadata + 1
+	for end < len(lines) && (strings.TrimSpace(lines[end]) == "" || strings.HasPrefix(lines[end], " ")) {
+		end++
+	}
+	indent := "  "
+	if metadata+1 < end {
+		indent = leadingSpaces(lines[metadata+1])
+	}
+
+	insertAt, entryIndent := metadata+1, indent+"  "
+	var inserted []string
+	for i := metadata + 1; i < end; i++ {
+		if lines[i] == indent+"annotations:" {
+			insertAt = i + 1
+			if i+1 < end && len(leadingSpaces(lines[i+1])) > len(indent) {
+				entryIndent = leadingSpaces(lines[i+1])
+			}
+			break
+		}
+	}
+	if insertAt == metadata+1 {
+		inserted = append(inserted, indent+"annotations:")
+	}
+	for _, stamp := range stamps {
+		inserted = append(inserted, entryIndent+stamp)
+	}
+
+	stamped := append(append(append([]string(nil), lines[:insertAt]...), inserted...), lines[insertAt:]...)
+	return []byte(strings.Join(stamped, "\n"))
+}
+
+func leadingSpaces(line string) string {
+	return line[:len(line)-len(strings.TrimLeft(line, " "))]
+}
+
+// GeneratedFile represents a manifest of konflux-release-data stamped with the template it was generated from
+type GeneratedFile struct {
+	Path             string `json:"path"`
+	Template         string `json:"template"`
+	TemplateHash     string `json:"template_hash" jsonschema:"Hash of the template the file was generated from"`
+	GeneratorVersion string `json:"generator_version,omitempty"`
+	Outdated         bool   `json:"outdated" jsonschema:"Whether the template changed since the file was generated"`
+}
+
+// OutdatedFilesResult represents the structured result of find-outdated-generated-files
+type OutdatedFilesResult struct {
+	Templates map[string]string `json:"templates" jsonschema:"Current hash of each template"`
+	Files     []GeneratedFile   `json:"files" jsonschema:"Generated files, outdated ones first"`
+	Outdated  int               `json:"outdated"`
+}
+
+// OutdatedFilesConfig represents the configuration for finding files generated by outdated templates
+type OutdatedFilesConfig struct {
+	RepoPath     string
+	MinorVersion string // Only files of this version, all versions when empty
+	Template     string // Only files of this template, all templates when empty
+}
+
+// findOutdatedGeneratedFiles clones konflux-release-data and compares the
+// template hash stamped onto each generated manifest to the current hash of
+// its template
+func findOutdatedGeneratedFiles(ctx context.Context, config OutdatedFilesConfig) (OutdatedFilesResult, error) {
+	result := OutdatedFilesResult{Templates: map[string]string{}}
+	if config.Template != "" {
+		if _, ok := registeredTemplates[config.Template]; !ok {
+			return result, unknownTemplateError(config.Template)
+		}
+	}
+	if err := cloneKonfluxRepo(ctx, RPAConfig{RepoPath: config.RepoPath}); err != nil {
+		return result, fmt.Errorf("failed to clone konflux-release-data repository: %w", err)
+	}
+	for _, name := range templateNames() {
+		if hash, ok := templateHash(name); ok {
+			result.Templates[name] = hash
+		}
+	}
+
+	err := filepath.WalkDir(config.RepoPath, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if entry.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".yaml") && !strings.HasSuffix(path, ".yml") {
+			return nil
+		}
+		if config.MinorVersion != "" && !versionPattern(config.MinorVersion).MatchString(entry.Name()) {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		// Most files were not generated by the server, only stamped ones are parsed
+		if !bytes.Contains(content, []byte(templateHashAnnotation)) {
+			return nil
+		}
+		var manifest struct {
+			Metadata struct {
+				Annotations map[string]string `yaml:"annotations"`
+			} `yaml:"metadata"`
+		}
+		if err := yaml.Unmarshal(content, &manifest); err != nil {
+			return nil
+		}
+		annotations := manifest.Metadata.Annotations
+		file := GeneratedFile{
+			Template:         annotations[templateAnnotation],
+			TemplateHash:     annotations[templateHashAnnotation],
+			GeneratorVersion: annotations[generatorVersionAnnotation],
+		}
+		if file.Template == "" || (config.Template != "" && file.Template != config.Template) {
+			return nil
+		}
+		file.Path, _ = filepath.Rel(config.RepoPath, path)
+		file.Outdated = file.TemplateHash != result.Templates[file.Template]
+		if file.Outdated {
+			result.Outdated++
+		}
+		result.Files = append(result.Files, file)
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+
+	sort.SliceStable(result.Files, func(i, j int) bool {
+		if result.Files[i].Outdated != result.Files[j].Outdated {
+			return result.Files[i].Outdated
+		}
+		return result.Files[i].Path < result.Files[j].Path
+	})
+	return result, nil
+}
+
+func formatOutdatedFiles(result OutdatedFilesResult) string {
+	var sb strings.Builder
+	if len(result.Files) == 0 {
+		sb.WriteString("No generated files found in konflux-release-data\n")
+		return sb.String()
+	}
+	for _, file := range result.Files {
+		if !file.Outdated {
+			continue
+		}
+		fmt.Fprintf(&sb, "✗ %s: %s template %s", file.Path, file.Template, file.TemplateHash)
+		if file.GeneratorVersion != "" {
+			fmt.Fprintf(&sb, " (generated by %s)", file.GeneratorVersion)
+		}
+		fmt.Fprintf(&sb, ", now %s\n", result.Templates[file.Template])
+	}
+	fmt.Fprintf(&sb, "\n%d of %d generated files are outdated\n", result.Outdated, len(result.Files))
+	if result.Outdated > 0 {
+		sb.WriteString("Regenerate them with the tool that created them, e.g. create-release-plans for the rpa and rp templates\n")
+	}
+	return sb.String()
+}
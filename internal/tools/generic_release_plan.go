@@ -0,0 +1,354 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// defaultGenericPipeline is the release-service-catalog pipeline of the generic RPAs
+const defaultGenericPipeline = "pipelines/managed/rh-advisories/rh-advisories.yaml"
+
+// GenericRPAConfig represents the configuration for ReleasePlanAdmission and
+// ReleasePlan creation for an arbitrary Konflux application
+type GenericRPAConfig struct {
+	Application        string
+	Version            string
+	Tenant             string // Tenant namespace the application is built in
+	ProductName        string
+	ProductID          int
+	ReleaseType        string // Defaults to RHEA
+	Components         []ComponentConfig
+	Environments       []string
+	RepoPath           string
+	RPADir             string            // Relative to the konflux-release-data root
+	RPDir              string            // Relative to the konflux-release-data root
+	ManagedNamespace   string            // Namespace of the RPAs, defaults to the releng tenant
+	Pipeline           string            // Path of the managed pipeline in release-service-catalog
+	CatalogRevision    string            // release-service-catalog revision of the pipeline (default: production)
+	PinCatalogRevision bool              // Resolve the default revision to its commit SHA
+	Policies           map[string]string // Overrides the policy per environment
+	ServiceAccounts    map[string]string // Overrides the service account per environment
+	AutoRelease        bool
+}
+
+// GenericRPATemplate represents the template for a caller-defined ReleasePlanAdmission
+const GenericRPATemplate = `apiVersion: appstudio.redhat.com/v1alpha1
+kind: ReleasePlanAdmission
+metadata:
+  labels:
+    release.appstudio.openshift.io/block-releases: "false"
+  name: {{.Application}}-{{.Env}}
+  namespace: {{.ManagedNamespace}}
+spec:
+  applications: [ {{.Application}} ]
+  origin: {{.Tenant}}
+  policy: {{.Policy}}
+  data:
+    releaseNotes:
+{{- if .ProductID}}
+      product_id: [ {{.ProductID}} ]
+{{- end}}
+{{- if .ProductName}}
+      product_name: "{{.ProductName}}"
+{{- end}}
+      product_version: "{{.Version}}"
+      type: "{{.ReleaseType}}"
+    mapping:
+      components:
+{{- range .Components }}
+        - name: {{.Name}}
+          repository: "{{$.RegistryURL}}/{{.Repository}}"
+          pushSourceContainer: true
+{{- end }}
+      defaults:
+        tags:
+          - "{{ "{{" }} git_sha {{ "}}" }}"
+          - "{{ "{{" }} git_short_sha {{ "}}" }}"
+          - "v{{.Version}}"
+          - "v{{.Version}}-{{ "{{" }} timestamp {{ "}}" }}"
+    intention: {{.Intention}}
+  pipeline:
+    serviceAccountName: {{.ServiceAccount}}
+    timeouts:
+      pipeline: "10h0m0s"
+      tasks: 10h0m0s
+    pipelineRef:
+      resolver: git
+      params:
+        - name: url
+          value: "https://github.com/konflux-ci/release-service-catalog.git"
+        - name: revision
+          value: {{.CatalogRevision}}
+        - name: pathInRepo
+          value: "{{.Pipeline}}"
+`
+
+// GenericRPTemplate represents the template for a caller-defined ReleasePlan
+const GenericRPTemplate = `apiVersion: appstudio.redhat.com/v1alpha1
+kind: ReleasePlan
+metadata:
+  labels:
+    release.appstudio.openshift.io/auto-release: "{{.AutoRelease}}"
+    release.appstudio.openshift.io/standing-attribution: "true"
+    release.appstudio.openshift.io/releasePlanAdmission: {{.Application}}-{{.Env}}
+  name: {{.Application}}-{{.Env}}
+spec:
+  application: {{.Application}}
+  target: {{.ManagedNamespace}}
+  data:
+    releaseNotes:
+      type: "{{.ReleaseType}}"
+{{- if .ProductName}}
+      synopsis: "{{.ProductName}} {{.Version}}"
+{{- end}}
+`
+
+// genericRPAData represents the values rendered into the generic templates
+type genericRPAData struct {
+	Application    string
+	Version        string
+	Tenant         string
+	ProductName    string
+	ProductID      int
+	ReleaseType    string
+	Env            string
+	Policy         string
+	Intention      string
+	ServiceAccount string
+	RegistryURL    string
+	AutoRelease    bool
+	Components     []ComponentConfig
+
+	ManagedNamespace string
+	Pipeline         string
+	CatalogRevision  string
+}
+
+func createGenericReleasePlans(ctx context.Context, config GenericRPAConfig) (PushedBranchResult, error) {
+	var result PushedBranchResult
+	if config.Application == "" || config.Version == "" || config.Tenant == "" {
+		return result, fmt.Errorf("application, version and tenant are required")
+	}
+	if len(config.Components) == 0 {
+		return result, fmt.Errorf("at least one component is required")
+	}
+	if err := checkProductVersion(config.Version, false, ""); err != nil {
+		return result, err
+	}
+	if config.ReleaseType == "" {
+		config.ReleaseType = "RHEA"
+	}
+	if config.RPADir == "" {
+		config.RPADir = filepath.Join("config", "kflux-prd-rh02.0fk9.p1", "product", "ReleasePlanAdmission", strings.TrimSuffix(config.Tenant, "-tenant"))
+	}
+	if config.RPDir == "" {
+		config.RPDir = filepath.Join("tenants-config", "cluster", "kflux-prd-rh02", "tenants", config.Tenant)
+	}
+	if config.ManagedNamespace == "" {
+		config.ManagedNamespace = relengTenant()
+	}
+	if config.Pipeline == "" {
+		config.Pipeline = defaultGenericPipeline
+	}
+	if err := validateGenericConfig(config); err != nil {
+		return result, err
+	}
+
+	// Pin the moving catalog branch to the commit it points to now
+	if config.CatalogRevision == "" {
+		config.CatalogRevision = releaseServiceCatalogRevision
+		if config.PinCatalogRevision {
+			sha, err := resolveCatalogRevision(ctx, releaseServiceCatalogRevision)
+			if err != nil {
+				return result, err
+			}
+			config.CatalogRevision = sha
+		}
+	}
+
+	// Reuse the pipelines flow for cloning, manifests and pushing
+	rpaConfig := RPAConfig{
+		MinorVersion: fmt.Sprintf("%s-%s", config.Application, config.Version),
+		RepoPath:     config.RepoPath,
+	}
+
+	if err := cloneKonfluxRepo(ctx, rpaConfig); err != nil {
+		return result, fmt.Errorf("failed to clone konflux-release-data repository: %w", err)
+	}
+
+	branchName, err := createPushBranch(ctx, config.RepoPath, releasePlanBranch(rpaConfig.MinorVersion))
+	if err != nil {
+		return result, err
+	}
+	rpaConfig.Branch = branchName
+	result.BranchName = branchName
+
+	rpFiles, err := writeGenericManifests(ctx, config)
+	if err != nil {
+		return result, err
+	}
+
+	kustomizationPath := filepath.Join(config.RepoPath, config.RPDir, "kustomization.yaml")
+	if err := addKustomizationResources(ctx, kustomizationPath, rpFiles); err != nil {
+		return result, fmt.Errorf("failed to update kustomization.yaml: %w", err)
+	}
+
+	if err := runBuildManifests(ctx, rpaConfig); err != nil {
+		return result, fmt.Errorf("failed to run build-manifests.sh: %w", err)
+	}
+
+	result.MergeRequestURL, err = createAndPushMR(ctx, rpaConfig)
+	if err != nil {
+		return result, fmt.Errorf("failed to create and push merge request: %w", err)
+	}
+	return result, nil
+}
+
+// validateGenericConfig checks the caller-supplied names and directories, so
+// that the manifests are written inside konflux-release-data
+func validateGenericConfig(config GenericRPAConfig) error {
+	names := map[string]string{"application": config.Application, "tenant": config.Tenant, "managed namespace": config.ManagedNamespace}
+	for i, env := range config.Environments {
+		names[fmt.Sprintf("environment %d", i+1)] = env
+	}
+	for field, name := range names {
+		if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+			return fmt.Errorf("invalid %s %q: %s", field, name, strings.Join(errs, ", "))
+		}
+	}
+	for field, dir := range map[string]string{"rpa_dir": config.RPADir, "rp_dir": config.RPDir} {
+		if !filepath.IsLocal(dir) {
+			return fmt.Errorf("invalid %s %q: must be a relative path inside the konflux-release-data repository", field, dir)
+		}
+	}
+	if !filepath.IsLocal(config.Pipeline) || !strings.HasSuffix(config.Pipeline, ".yaml") {
+		return fmt.Errorf("invalid pipeline %q: must be the path of a pipeline YAML file in release-service-catalog", config.Pipeline)
+	}
+	return nil
+}
+
+// writeGenericManifests renders the RPA and RP for every environment and
+// returns the ReleasePlan file names
+func writeGenericManifests(ctx context.Context, config GenericRPAConfig) ([]string, error) {
+	rpaTmpl, err := parseRegisteredTemplate("generic-rpa")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse RPA template: %w", err)
+	}
+	rpTmpl, err := parseRegisteredTemplate("generic-rp")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse RP template: %w", err)
+	}
+
+	rpaBasePath := filepath.Join(config.RepoPath, config.RPADir)
+	rpBasePath := filepath.Join(config.RepoPath, config.RPDir)
+	for _, dir := range []string{rpaBasePath, rpBasePath} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create directory %s: %w", dir, err)
+		}
+	}
+
+	var rpFiles []string
+	for _, env := range config.Environments {
+		envConfig := getEnvSpecificValues(env, false)
+		data := genericRPAData{
+			Application:    config.Application,
+			Version:        config.Version,
+			Tenant:         config.Tenant,
+			ProductName:    config.ProductName,
+			ProductID:      config.ProductID,
+			ReleaseType:    config.ReleaseType,
+			Env:            env,
+			Policy:         envConfig.Policy,
+			Intention:      envConfig.Intention,
+			ServiceAccount: envConfig.ServiceAccount,
+			RegistryURL:    envConfig.RegistryURL,
+			AutoRelease:    config.AutoRelease,
+			Components:     config.Components,
+
+			ManagedNamespace: config.ManagedNamespace,
+			Pipeline:         config.Pipeline,
+			CatalogRevision:  config.CatalogRevision,
+		}
+		if policy, ok := config.Policies[env]; ok {
+			data.Policy = policy
+		}
+		if sa, ok := config.ServiceAccounts[env]; ok {
+			data.ServiceAccount = sa
+		}
+
+		fileName := fmt.Sprintf("%s-%s.yaml", config.Application, env)
+		if err := renderToFile(ctx, rpaTmpl, filepath.Join(rpaBasePath, fileName), data); err != nil {
+			return nil, fmt.Errorf("failed to write RPA %s: %w", fileName, err)
+		}
+		if err := renderToFile(ctx, rpTmpl, filepath.Join(rpBasePath, fileName), data); err != nil {
+			return nil, fmt.Errorf("failed to write RP %s: %w", fileName, err)
+		}
+		rpFiles = append(rpFiles, fileName)
+	}
+	return rpFiles, nil
+}
+
+// renderToFile renders a registered template into a file, stamping manifests
+// with the generator version and the hash of the template. Manifests of
+// release-service kinds are validated against their CRD before being written.
+func renderToFile(ctx context.Context, tmpl *template.Template, filePath string, data any) error {
+	var buf bytes.Buffer
+	if err := executeTemplate(tmpl, &buf, data); err != nil {
+		return err
+	}
+	content := stampGenerated(tmpl.Name(), buf.Bytes())
+	if registered, ok := lookupTemplate(tmpl.Name()); ok && !registered.markdown {
+		if err := validateManifest(ctx, content); err != nil {
+			return err
+		}
+	}
+	file, err := createFile(ctx, filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(content)
+	return err
+}
+
+// addKustomizationResources adds the files to the resources of a
+// kustomization.yaml, creating it when missing and skipping existing entries
+func addKustomizationResources(ctx context.Context, kustomizationPath string, files []string) error {
+	content, err := os.ReadFile(kustomizationPath)
+	if os.IsNotExist(err) {
+		content = []byte("apiVersion: kustomize.config.k8s.io/v1beta1\nkind: Kustomization\nresources:\n")
+	} else if err != nil {
+		return err
+	}
+
+	var newResources []string
+	for _, file := range files {
+		if !strings.Contains(string(content), "- "+file) {
+			newResources = append(newResources, "  - "+file)
+		}
+	}
+
+	lines := strings.Split(string(content), "\n")
+	var updated []string
+	resourcesFound := false
+	for _, line := range lines {
+		updated = append(updated, line)
+		if strings.TrimSpace(line) == "resources:" {
+			resourcesFound = true
+			updated = append(updated, newResources...)
+		}
+	}
+	if !resourcesFound {
+		updated = append(updated, "resources:")
+		updated = append(updated, newResources...)
+	}
+
+	return writeFile(ctx, kustomizationPath, []byte(strings.Join(updated, "\n")), 0644)
+}
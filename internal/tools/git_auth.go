@@ -0,0 +1,157 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+const (
+	// Clone protocols selectable per forge
+	protocolSSH   = "ssh"
+	protocolHTTPS = "https"
+
+	githubHost = "github.com"
+	gitlabHost = "gitlab.cee.redhat.com"
+
+	// Environment variables used to hand credentials to the askpass helper
+	askpassUsernameEnv = "RELEASE_MCP_GIT_USERNAME"
+	askpassPasswordEnv = "RELEASE_MCP_GIT_PASSWORD"
+)
+
+// askpassScript answers git's credential prompts from the environment, so
+// tokens never end up in remote URLs or in .git/config
+const askpassScript = `#!/bin/sh
+case "$1" in
+Username*) printf '%s\n' "$` + askpassUsernameEnv + `" ;;
+*) printf '%s\n' "$` + askpassPasswordEnv + `" ;;
+esac
+`
+
+var (
+	askpassPath string
+	askpassErr  error
+	askpassOnce sync.Once
+)
+
+// gitCredentials represents the HTTPS credentials for a forge
+type gitCredentials struct {
+	Username string
+	Password string
+}
+
+// forgeProtocol returns the configured clone protocol for a forge. GitHub
+// defaults to SSH and GitLab to HTTPS, matching the previous behaviour.
+func forgeProtocol(host string) string {
+	var env, def string
+	switch host {
+	case githubHost:
+		env, def = "RELEASE_MCP_GITHUB_PROTOCOL", protocolSSH
+	default:
+		env, def = "RELEASE_MCP_GITLAB_PROTOCOL", protocolHTTPS
+	}
+	if protocol := strings.ToLower(os.Getenv(env)); protocol == protocolSSH || protocol == protocolHTTPS {
+		return protocol
+	}
+	return def
+}
+
+// forgeRepoURL returns the clone URL of a repository ("owner/name") on a
+// forge using the configured protocol
+func forgeRepoURL(host, repoPath string) string {
+	repoPath = strings.TrimSuffix(repoPath, ".git")
+	if forgeProtocol(host) == protocolSSH {
+		return fmt.Sprintf("git@%s:%s.git", host, repoPath)
+	}
+	return fmt.Sprintf("https://%s/%s.git", host, repoPath)
+}
+
+// forgeRepoPath returns the owner/name path of a repository from its SSH or HTTPS URL
+func forgeRepoPath(repoURL string) string {
+	repoPath := strings.TrimSuffix(repoURL, ".git")
+	if i := strings.Index(repoPath, "://"); i != -1 {
+		repoPath = repoPath[i+3:]
+		if j := strings.Index(repoPath, "/"); j != -1 {
+			return repoPath[j+1:]
+		}
+		return ""
+	}
+	if i := strings.Index(repoPath, ":"); i != -1 {
+		return repoPath[i+1:]
+	}
+	return repoPath
+}
+
+// forgeCredentials returns the HTTPS credentials for a forge from the environment
+func forgeCredentials(host string) (*gitCredentials, error) {
+	if host == githubHost {
+		token := os.Getenv("GITHUB_TOKEN")
+		if token == "" {
+			token = os.Getenv("GH_TOKEN")
+		}
+		if token == "" {
+			return nil, fmt.Errorf("GITHUB_TOKEN or GH_TOKEN environment variable must be set for HTTPS access to GitHub")
+		}
+		return &gitCredentials{Username: "x-access-token", Password: token}, nil
+	}
+
+	username := os.Getenv("GITLAB_USERNAME")
+	token := os.Getenv("GITLAB_TOKEN")
+	if username == "" || token == "" {
+		return nil, fmt.Errorf("GITLAB_USERNAME and GITLAB_TOKEN environment variables must be set")
+	}
+	return &gitCredentials{Username: username, Password: token}, nil
+}
+
+// ensureAskpass writes the askpass helper once per process
+func ensureAskpass() (string, error) {
+	askpassOnce.Do(func() {
+		dir, err := os.MkdirTemp("", "release-mcp-askpass-*")
+		if err != nil {
+			askpassErr = fmt.Errorf("failed to create askpass directory: %w", err)
+			return
+		}
+		askpassPath = filepath.Join(dir, "askpass.sh")
+		if err := os.WriteFile(askpassPath, []byte(askpassScript), 0700); err != nil {
+			askpassErr = fmt.Errorf("failed to write askpass helper: %w", err)
+		}
+	})
+	return askpassPath, askpassErr
+}
+
+// partialCloneFilter makes a clone partial: it has every commit and tree but
+// only the file contents of the checked out commit. Git fetches other contents
+// from the remote when a command reads them, so such commands are built with
+// gitCommand like the clone itself.
+const partialCloneFilter = "--filter=blob:none"
+
+// gitCommand creates a git command that authenticates against host. Over
+// HTTPS the credentials are provided through the askpass helper and the
+// command environment; over SSH the user's SSH configuration is used.
+func gitCommand(ctx context.Context, host string, args ...string) (*exec.Cmd, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	if forgeProtocol(host) == protocolSSH {
+		return cmd, nil
+	}
+
+	creds, err := forgeCredentials(host)
+	if err != nil {
+		return nil, err
+	}
+	askpass, err := ensureAskpass()
+	if err != nil {
+		return nil, err
+	}
+
+	cmd.Env = append(os.Environ(),
+		"GIT_ASKPASS="+askpass,
+		"GIT_TERMINAL_PROMPT=0",
+		askpassUsernameEnv+"="+creds.Username,
+		askpassPasswordEnv+"="+creds.Password,
+	)
+	return cmd, nil
+}
@@ -0,0 +1,265 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// githubAPIURL is the GitHub API the clients call, the fake forge in sandbox mode
+var githubAPIURL = "https://api.github.com"
+
+const (
+	// githubBatchSize is the number of repositories queried per GraphQL request
+	githubBatchSize = 20
+
+	// githubMaxRateLimitWait caps how long a call waits for the rate limit to reset
+	githubMaxRateLimitWait = 15 * time.Minute
+)
+
+// githubClient wraps the GitHub REST and GraphQL APIs with rate-limit handling
+type githubClient struct {
+	httpClient *http.Client
+	baseURL    string
+	token      string
+
+	mu        sync.Mutex
+	remaining int
+	reset     time.Time
+}
+
+func newGitHubClient() (*githubClient, error) {
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		token = os.Getenv("GH_TOKEN")
+	}
+	if token == "" {
+		return nil, fmt.Errorf("GITHUB_TOKEN or GH_TOKEN environment variable must be set")
+	}
+
+	client, err := httpClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+
+	return &githubClient{
+		httpClient: client,
+		baseURL:    githubAPIURL,
+		token:      token,
+		remaining:  -1,
+	}, nil
+}
+
+// waitForRateLimit blocks while the last known rate limit is exhausted
+func (c *githubClient) waitForRateLimit(ctx context.Context) error {
+	c.mu.Lock()
+	remaining, reset := c.remaining, c.reset
+	c.mu.Unlock()
+
+	if remaining != 0 {
+		return nil
+	}
+	wait := time.Until(reset)
+	if wait <= 0 {
+		return nil
+	}
+	if wait > githubMaxRateLimitWait {
+		return fmt.Errorf("GitHub rate limit exhausted until %s", reset.Format(time.RFC3339))
+	}
+
+	slog.WarnContext(ctx, "GitHub rate limit exhausted, waiting", "wait", wait.Round(time.Second))
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(wait):
+		return nil
+	}
+}
+
+// updateRateLimit records the X-RateLimit headers of a response
+func (c *githubClient) updateRateLimit(resp *http.Response) {
+	remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	c.remaining = remaining
+	c.reset = time.Unix(reset, 0)
+	c.mu.Unlock()
+}
+
+// isRateLimited reports whether a response was rejected by a primary or secondary rate limit
+func isRateLimited(resp *http.Response) bool {
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	return resp.StatusCode == http.StatusForbidden &&
+		(resp.Header.Get("X-RateLimit-Remaining") == "0" || resp.Header.Get("Retry-After") != "")
+}
+
+// do sends a request to the GitHub API, backing off when rate limited, and
+// decodes the JSON response into out when non-nil
+func (c *githubClient) do(ctx context.Context, method, path string, body any, out any) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		if err := c.waitForRateLimit(ctx); err != nil {
+			return err
+		}
+
+		// Throttled responses are retried here only, honouring the rate
+		// limit reset, rather than by the shared transport as well
+		req, err := http.NewRequestWithContext(withCallerRetriesThrottling(ctx), method, c.baseURL+path, bytes.NewReader(payload))
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+c.token)
+		req.Header.Set("Accept", "application/vnd.github+json")
+		req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("GitHub request %s %s failed: %w", method, path, err)
+		}
+		c.updateRateLimit(resp)
+
+		if isRateLimited(resp) && attempt < 3 {
+			resp.Body.Close()
+			wait := time.Duration(attempt+1) * time.Minute
+			if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+				wait = time.Duration(seconds) * time.Second
+			}
+			slog.WarnContext(ctx, "GitHub rate limited, retrying", "method", method, "path", path, "wait", wait)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(wait):
+			}
+			continue
+		}
+
+		defer resp.Body.Close()
+		respBody, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read GitHub response: %w", err)
+		}
+		runUsageFrom(ctx).recordAPICall(len(respBody))
+		if resp.StatusCode >= 300 {
+			return &githubError{StatusCode: resp.StatusCode, Body: string(respBody)}
+		}
+		if out != nil && len(respBody) > 0 {
+			if err := json.Unmarshal(respBody, out); err != nil {
+				return fmt.Errorf("failed to decode GitHub response: %w", err)
+			}
+		}
+		return nil
+	}
+}
+
+// githubError represents a non-successful GitHub API response
+type githubError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *githubError) Error() string {
+	return fmt.Sprintf("GitHub API returned %d: %s", e.StatusCode, e.Body)
+}
+
+// graphql runs a GraphQL query and decodes its data into out
+func (c *githubClient) graphql(ctx context.Context, query string, variables map[string]any, out any) error {
+	var resp struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/graphql", map[string]any{"query": query, "variables": variables}, &resp); err != nil {
+		return err
+	}
+	if len(resp.Errors) > 0 {
+		var messages []string
+		for _, e := range resp.Errors {
+			messages = append(messages, e.Message)
+		}
+		return fmt.Errorf("GitHub GraphQL errors: %s", strings.Join(messages, "; "))
+	}
+	if err := json.Unmarshal(resp.Data, out); err != nil {
+		return fmt.Errorf("failed to decode GraphQL data: %w", err)
+	}
+	return nil
+}
+
+// fileContent returns the content of a file of repo at ref
+func (c *githubClient) fileContent(ctx context.Context, repo, filePath, ref string) ([]byte, error) {
+	var file struct {
+		Content string `json:"content"`
+	}
+	apiPath := fmt.Sprintf("/repos/%s/contents/%s?ref=%s", repo, filePath, url.QueryEscape(ref))
+	if err := c.do(ctx, http.MethodGet, apiPath, nil, &file); err != nil {
+		return nil, fmt.Errorf("failed to read %s from %s@%s: %w", filePath, repo, ref, err)
+	}
+	content, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(file.Content, "\n", ""))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", filePath, err)
+	}
+	return content, nil
+}
+
+// branchesExist checks which repositories of owner have the given branch,
+// batching repositories into aliased GraphQL queries
+func (c *githubClient) branchesExist(ctx context.Context, owner string, repos []string, branch string) (map[string]bool, error) {
+	result := make(map[string]bool, len(repos))
+	for start := 0; start < len(repos); start += githubBatchSize {
+		end := min(start+githubBatchSize, len(repos))
+		batch := repos[start:end]
+
+		var sb strings.Builder
+		sb.WriteString("query($owner: String!, $ref: String!) {")
+		for i, repo := range batch {
+			fmt.Fprintf(&sb, " r%d: repository(owner: $owner, name: %q) { ref(qualifiedName: $ref) { name } }", i, repo)
+		}
+		sb.WriteString(" }")
+
+		var data map[string]*struct {
+			Ref *struct {
+				Name string `json:"name"`
+			} `json:"ref"`
+		}
+		variables := map[string]any{"owner": owner, "ref": "refs/heads/" + branch}
+		if err := c.graphql(ctx, sb.String(), variables, &data); err != nil {
+			return nil, fmt.Errorf("failed to query branches: %w", err)
+		}
+
+		for i, repo := range batch {
+			r := data[fmt.Sprintf("r%d", i)]
+			result[repo] = r != nil && r.Ref != nil
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,90 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+)
+
+// gitlabAPIURL is the REST API of the internal GitLab instance, the fake forge in sandbox mode
+var gitlabAPIURL = fmt.Sprintf("https://%s/api/v4", gitlabHost)
+
+// gitlabClient wraps the GitLab REST API of the internal GitLab instance
+type gitlabClient struct {
+	httpClient *http.Client
+	baseURL    string
+	token      string
+}
+
+func newGitLabClient() (*gitlabClient, error) {
+	token := os.Getenv("GITLAB_TOKEN")
+	if token == "" {
+		return nil, fmt.Errorf("GITLAB_TOKEN environment variable must be set")
+	}
+
+	client, err := httpClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+
+	return &gitlabClient{
+		httpClient: client,
+		baseURL:    gitlabAPIURL,
+		token:      token,
+	}, nil
+}
+
+// do sends a request to the GitLab API and decodes the JSON response into out when non-nil
+func (c *gitlabClient) do(ctx context.Context, method, path string, body any, out any) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("PRIVATE-TOKEN", c.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("GitLab request %s %s failed: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read GitLab response: %w", err)
+	}
+	runUsageFrom(ctx).recordAPICall(len(respBody))
+	if resp.StatusCode >= 300 {
+		return &gitlabError{StatusCode: resp.StatusCode, Body: string(respBody)}
+	}
+	if out != nil && len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return fmt.Errorf("failed to decode GitLab response: %w", err)
+		}
+	}
+	return nil
+}
+
+// gitlabError represents a non-successful GitLab API response
+type gitlabError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *gitlabError) Error() string {
+	return fmt.Sprintf("GitLab API returned %d: %s", e.StatusCode, e.Body)
+}
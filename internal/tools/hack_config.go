@@ -1,11 +1,13 @@
 package tools
 
 import (
-	"bytes"
+	"context"
 	"fmt"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -17,7 +19,27 @@ type HackConfig struct {
 	MinorVersion   string
 	OCPVersion     string
 	RepoPath       string
-	UpstreamConfig map[string]string // map of component name to upstream version
+	UpstreamConfig map[string]string  // map of component name to upstream version
+	Incremental    bool               // Only add missing branch entries, never rewrite existing ones
+	Patches        map[string][]Patch // map of component name to additional patches for the new branch
+	BaseBranch     string             // Branch to clone and target the PR at, defaults to release-v<minor>.x
+	PRTitle        string             // Overrides the default commit message and PR title
+	PRBody         string             // Overrides the default PR body
+}
+
+// hackBaseBranch returns the hack branch the configuration changes are based on
+func hackBaseBranch(config HackConfig) string {
+	if config.BaseBranch != "" {
+		return config.BaseBranch
+	}
+	return fmt.Sprintf("release-v%s.x", config.MinorVersion)
+}
+
+// HackResult represents the outcome of a hack repository update
+type HackResult struct {
+	PRURL          string             `json:"pr_url,omitempty" jsonschema:"URL of the created pull request, empty when nothing changed"`
+	PullRequest    *ChangeRequestInfo `json:"pull_request,omitempty" jsonschema:"Number, URL, head and base of the created pull request"`
+	UnchangedRepos []string           `json:"unchanged_repos,omitempty" jsonschema:"Repositories that already had the branch entry (incremental mode)"`
 }
 
 // RepoConfig represents the repository configuration in YAML
@@ -86,7 +108,7 @@ var specialComponents = map[string]bool{
 }
 
 func createBranchConfig(minorVersion string, repoName string, hasUpstream bool, upstreamVersions map[string]string) BranchConfig {
-	componentName, ok := componentMapping[repoName]
+	componentName, ok := componentForRepo(repoName)
 	if !ok {
 		// Default configuration for unknown components
 		return BranchConfig{
@@ -122,14 +144,24 @@ func createBranchConfig(minorVersion string, repoName string, hasUpstream bool,
 	return branchConfig
 }
 
-func formatBranchYAML(branchConfig BranchConfig, indentation string, hasPatches bool) []string {
+// formatBranchYAML renders a branch entry. Explicit patches take precedence;
+// otherwise the branch references patchAnchor when the file defines one.
+func formatBranchYAML(branchConfig BranchConfig, indentation string, patchAnchor string) []string {
 	var lines []string
 	lines = append(lines, indentation+"- name: "+branchConfig.Name)
 	if branchConfig.Upstream != "" {
 		lines = append(lines, indentation+"  upstream: "+branchConfig.Upstream)
 	}
-	if hasPatches {
-		lines = append(lines, indentation+"  patches: *patches")
+	if len(branchConfig.Patches) > 0 {
+		lines = append(lines, indentation+"  patches:")
+		for _, patch := range branchConfig.Patches {
+			lines = append(lines, indentation+"    - name: "+yamlScalar(patch.Name))
+			if patch.Script != "" {
+				lines = append(lines, indentation+"      script: "+yamlScalar(patch.Script))
+			}
+		}
+	} else if patchAnchor != "" {
+		lines = append(lines, indentation+"  patches: *"+patchAnchor)
 	}
 	lines = append(lines, indentation+"  versions:")
 	for _, version := range branchConfig.Versions {
@@ -138,108 +170,213 @@ func formatBranchYAML(branchConfig BranchConfig, indentation string, hasPatches
 	return lines
 }
 
-func ConfigureHackRepo(config HackConfig) error {
+// yamlScalar renders s as a single-line YAML scalar, quoting it when needed
+func yamlScalar(s string) string {
+	out, err := yaml.Marshal(s)
+	if err != nil || strings.Contains(strings.TrimSuffix(string(out), "\n"), "\n") {
+		return strconv.Quote(s)
+	}
+	return strings.TrimSuffix(string(out), "\n")
+}
+
+// topLevelPatches returns the top-level patches of a repo file and the name
+// of the anchor defined on them, if any
+func topLevelPatches(content []byte) ([]Patch, string, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, "", fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, "", nil
+	}
+
+	root := doc.Content[0]
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != "patches" {
+			continue
+		}
+		value := root.Content[i+1]
+		var patches []Patch
+		if err := value.Decode(&patches); err != nil {
+			return nil, "", fmt.Errorf("failed to decode patches: %w", err)
+		}
+		return patches, value.Anchor, nil
+	}
+	return nil, "", nil
+}
+
+func ConfigureHackRepo(ctx context.Context, config HackConfig) (HackResult, error) {
+	var result HackResult
+
 	// Clone hack repository
-	if err := cloneHackRepo(config); err != nil {
-		return fmt.Errorf("failed to clone hack repository: %w", err)
+	if err := cloneHackRepo(ctx, config); err != nil {
+		return result, fmt.Errorf("failed to clone hack repository: %w", err)
+	}
+
+	// Versions not passed explicitly come from the versions manifest
+	manifest, err := readVersionsManifest(config.RepoPath)
+	if err != nil {
+		return result, err
+	}
+	changedPins := pinnedUpstreamVersions(&config, manifest)
+
+	// Refuse to write branch entries with guessed names or upstreams
+	missing, err := missingUpstreamVersions(config)
+	if err != nil {
+		return result, err
+	}
+	if len(missing) > 0 {
+		return result, fmt.Errorf("upstream_versions is missing required versions:\n%s", formatMissingUpstreamVersions(missing))
 	}
 
 	// Create a new branch for changes
-	if err := createPRBranch(config); err != nil {
-		return fmt.Errorf("failed to create PR branch: %w", err)
+	if err := createPRBranch(ctx, config); err != nil {
+		return result, fmt.Errorf("failed to create PR branch: %w", err)
+	}
+
+	// Record new or changed versions so the manifest stays the source of truth
+	if len(changedPins) > 0 {
+		slog.InfoContext(ctx, "Updating the versions manifest", "path", versionsManifestPath, "version", config.MinorVersion, "pins", changedPins)
+		if err := writeVersionsManifest(ctx, config.RepoPath, manifest, config.MinorVersion, config.UpstreamConfig); err != nil {
+			return result, err
+		}
 	}
 
 	// Update Konflux configurations
-	if err := updateKonfluxConfigs(config); err != nil {
-		return fmt.Errorf("failed to update Konflux configurations: %w", err)
+	if err := updateKonfluxConfigs(ctx, config); err != nil {
+		return result, fmt.Errorf("failed to update Konflux configurations: %w", err)
 	}
 
 	// Update repository branch configurations
-	if err := updateRepoBranches(config); err != nil {
-		return fmt.Errorf("failed to update repository branch configurations: %w", err)
+	unchanged, err := updateRepoBranches(ctx, config)
+	if err != nil {
+		return result, fmt.Errorf("failed to update repository branch configurations: %w", err)
+	}
+	result.UnchangedRepos = unchanged
+
+	// Repeated incremental runs may have nothing left to change
+	if config.Incremental {
+		changed, err := hasWorkingTreeChanges(ctx, config.RepoPath)
+		if err != nil {
+			return result, err
+		}
+		if !changed {
+			slog.InfoContext(ctx, "No changes required in the hack repository, skipping pull request")
+			return result, nil
+		}
 	}
 
 	// Create and push pull request
-	prURL, err := createAndPushPR(config)
+	pr, err := createAndPushPR(ctx, config)
 	if err != nil {
-		return fmt.Errorf("failed to create and push PR: %w", err)
+		return result, fmt.Errorf("failed to create and push PR: %w", err)
 	}
+	result.PRURL = pr.URL
+	result.PullRequest = &pr
 
-	fmt.Printf("\nPull Request created successfully: %s\n", prURL)
-	return nil
+	slog.InfoContext(ctx, "Opened pull request", "url", pr.URL)
+	return result, nil
+}
+
+func hasWorkingTreeChanges(ctx context.Context, repoPath string) (bool, error) {
+	statusCmd := exec.CommandContext(ctx, "git", "status", "--porcelain")
+	statusCmd.Dir = repoPath
+	output, err := commandOutput(ctx, statusCmd)
+	if err != nil {
+		return false, fmt.Errorf("failed to get git status: %w", err)
+	}
+	return strings.TrimSpace(string(output)) != "", nil
+}
+
+// hasBranchEntry reports whether the parsed branches list already contains the given branch name
+func hasBranchEntry(yamlData map[string]interface{}, name string) bool {
+	branches, ok := yamlData["branches"].([]interface{})
+	if !ok {
+		return false
+	}
+	for _, b := range branches {
+		if branch, ok := b.(map[string]interface{}); ok && branch["name"] == name {
+			return true
+		}
+	}
+	return false
 }
 
-func cloneHackRepo(config HackConfig) error {
-	branchName := fmt.Sprintf("release-v%s.x", config.MinorVersion)
-	cloneCmd := exec.Command("git", "clone",
-		"git@github.com:openshift-pipelines/hack.git",
+func cloneHackRepo(ctx context.Context, config HackConfig) error {
+	branchName := hackBaseBranch(config)
+	cloneCmd, err := gitCommand(ctx, githubHost, "clone", partialCloneFilter, "--single-branch",
+		forgeRepoURL(githubHost, hackRepo),
 		"-b", branchName,
 		config.RepoPath)
+	if err != nil {
+		return err
+	}
 
-	fmt.Println("Cloning hack repository...with branch", branchName)
-	cloneCmd.Stdout = os.Stdout
-	cloneCmd.Stderr = os.Stderr
-	if err := cloneCmd.Run(); err != nil {
+	slog.DebugContext(ctx, "Cloning hack repository", "branch", branchName)
+	if err := runCommand(ctx, cloneCmd); err != nil {
 		return fmt.Errorf("failed to clone repository: %w", err)
 	}
+	runUsageFrom(ctx).recordClone(config.RepoPath)
 	return nil
 }
 
-func createPRBranch(config HackConfig) error {
+func createPRBranch(ctx context.Context, config HackConfig) error {
 	// Create a new branch for our changes
 	branchName := fmt.Sprintf("update-konflux-config-%s", time.Now().Format("20060102150405"))
-	cmd := exec.Command("git", "checkout", "-b", branchName)
+	cmd := exec.CommandContext(ctx, "git", "checkout", "-b", branchName)
 	cmd.Dir = config.RepoPath
-	if err := cmd.Run(); err != nil {
+	if err := runCommand(ctx, cmd); err != nil {
 		return fmt.Errorf("failed to create PR branch: %w", err)
 	}
 	return nil
 }
 
-func createAndPushPR(config HackConfig) (string, error) {
+// createAndPushPR commits the changes, pushes them to the fork the hack
+// repository was cloned from and opens a pull request through the GitHub API
+func createAndPushPR(ctx context.Context, config HackConfig) (ChangeRequestInfo, error) {
 	// Stage all changes
-	stageCmd := exec.Command("git", "add", ".")
+	stageCmd := exec.CommandContext(ctx, "git", "add", ".")
 	stageCmd.Dir = config.RepoPath
-	stageCmd.Stdout = os.Stdout
-	stageCmd.Stderr = os.Stderr
-	if err := stageCmd.Run(); err != nil {
-		return "", fmt.Errorf("failed to stage changes: %w", err)
+	if err := runCommand(ctx, stageCmd); err != nil {
+		return ChangeRequestInfo{}, fmt.Errorf("failed to stage changes: %w", err)
 	}
 
 	// Create commit
 	commitMsg := fmt.Sprintf("Update Konflux configuration for release v%s", config.MinorVersion)
-	commitCmd := exec.Command("git", "commit", "-m", commitMsg)
+	if config.PRTitle != "" {
+		commitMsg = config.PRTitle
+	}
+	commitCmd := exec.CommandContext(ctx, "git", "commit", "-m", commitMsg)
 	commitCmd.Dir = config.RepoPath
-	commitCmd.Stdout = os.Stdout
-	commitCmd.Stderr = os.Stderr
-	if err := commitCmd.Run(); err != nil {
-		return "", fmt.Errorf("failed to commit changes: %w", err)
+	if err := runCommand(ctx, commitCmd); err != nil {
+		return ChangeRequestInfo{}, fmt.Errorf("failed to commit changes: %w", err)
 	}
 
 	// Get current branch name
-	currentBranchCmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
+	currentBranchCmd := exec.CommandContext(ctx, "git", "rev-parse", "--abbrev-ref", "HEAD")
 	currentBranchCmd.Dir = config.RepoPath
-	branchOutput, err := currentBranchCmd.Output()
+	branchOutput, err := commandOutput(ctx, currentBranchCmd)
 	if err != nil {
-		return "", fmt.Errorf("failed to get current branch: %w", err)
+		return ChangeRequestInfo{}, fmt.Errorf("failed to get current branch: %w", err)
 	}
 	currentBranch := strings.TrimSpace(string(branchOutput))
 
 	// Push to your fork
-	pushCmd := exec.Command("git", "push", "-f", "origin", currentBranch)
+	pushCmd, err := gitCommand(ctx, githubHost, "push", "-f", "origin", currentBranch)
+	if err != nil {
+		return ChangeRequestInfo{}, err
+	}
 	pushCmd.Dir = config.RepoPath
-	pushCmd.Stdout = os.Stdout
-	pushCmd.Stderr = os.Stderr
-	if err := pushCmd.Run(); err != nil {
-		return "", fmt.Errorf("failed to push changes: %w", err)
+	if err := runCommand(ctx, pushCmd); err != nil {
+		return ChangeRequestInfo{}, fmt.Errorf("failed to push changes: %w", err)
 	}
 
 	// Get fork owner from git config
-	ownerCmd := exec.Command("git", "config", "--get", "remote.origin.url")
+	ownerCmd := exec.CommandContext(ctx, "git", "config", "--get", "remote.origin.url")
 	ownerCmd.Dir = config.RepoPath
-	ownerOutput, err := ownerCmd.Output()
+	ownerOutput, err := commandOutput(ctx, ownerCmd)
 	if err != nil {
-		return "", fmt.Errorf("failed to get remote URL: %w", err)
+		return ChangeRequestInfo{}, fmt.Errorf("failed to get remote URL: %w", err)
 	}
 	remoteURL := strings.TrimSpace(string(ownerOutput))
 
@@ -263,50 +400,42 @@ func createAndPushPR(config HackConfig) (string, error) {
 	}
 
 	if owner == "" {
-		return "", fmt.Errorf("could not determine fork owner from URL: %s", remoteURL)
+		return ChangeRequestInfo{}, fmt.Errorf("could not determine fork owner from URL: %s", remoteURL)
 	}
 
-	// Create PR using gh CLI
+	// Create PR through the GitHub API
 	prTitle := fmt.Sprintf("Update Konflux configuration for release v%s", config.MinorVersion)
 
-	// Build PR body
-	var ocpNote string
-	if config.OCPVersion != "" {
-		ocpNote = fmt.Sprintf("- Added new OCP %s configuration", config.OCPVersion)
-	}
-
-	prBody := fmt.Sprintf(`Update Konflux configuration for release v%s
-
-Changes:
-- Updated version references for release v%s
-- Updated branch configurations in repos directory
-%s`,
-		config.MinorVersion,
-		config.MinorVersion,
-		ocpNote,
-	)
-
-	// Create PR and capture output
-	var stdout, stderr bytes.Buffer
-	prCmd := exec.Command("gh", "pr", "create",
-		"--title", prTitle,
-		"--body", prBody,
-		"--repo", "openshift-pipelines/hack",
-		"--head", fmt.Sprintf("%s:%s", owner, currentBranch),
-		"--base", fmt.Sprintf("release-v%s.x", config.MinorVersion))
-	prCmd.Dir = config.RepoPath
-	prCmd.Stdout = &stdout
-	prCmd.Stderr = &stderr
-	if err := prCmd.Run(); err != nil {
-		return "", fmt.Errorf("failed to create pull request: %v\nError details: %s", err, stderr.String())
-	}
-
-	// The output from gh pr create is the PR URL
-	prURL := strings.TrimSpace(stdout.String())
-	return prURL, nil
+	if config.PRTitle != "" {
+		prTitle = config.PRTitle
+	}
+	prBody := config.PRBody
+	if prBody == "" {
+		if prBody, err = renderNotification("hack-config-pr", map[string]any{
+			"MinorVersion": config.MinorVersion,
+			"OCPVersion":   config.OCPVersion,
+		}); err != nil {
+			return ChangeRequestInfo{}, fmt.Errorf("branch %s was pushed to %s, open the pull request manually: %w", currentBranch, owner, err)
+		}
+	}
+
+	provider, err := vcsProvider(githubHost)
+	if err != nil {
+		return ChangeRequestInfo{}, fmt.Errorf("branch %s was pushed to %s, open the pull request manually: %w", currentBranch, owner, err)
+	}
+	pr, err := provider.OpenChangeRequest(ctx, hackRepo, ChangeRequest{
+		Title:        prTitle,
+		Body:         prBody,
+		SourceBranch: fmt.Sprintf("%s:%s", owner, currentBranch),
+		TargetBranch: hackBaseBranch(config),
+	})
+	if err != nil {
+		return ChangeRequestInfo{}, fmt.Errorf("failed to create pull request: %w", err)
+	}
+	return pr, nil
 }
 
-func updateKonfluxConfigs(config HackConfig) error {
+func updateKonfluxConfigs(ctx context.Context, config HackConfig) error {
 	konfluxDir := filepath.Join(config.RepoPath, "config", "konflux")
 
 	// Read all files in the konflux directory
@@ -327,7 +456,7 @@ func updateKonfluxConfigs(config HackConfig) error {
 			// Replace "next" with the release version
 			newContent := strings.ReplaceAll(string(content), "next", config.MinorVersion)
 
-			if err := os.WriteFile(filePath, []byte(newContent), 0644); err != nil {
+			if err := writeFile(ctx, filePath, []byte(newContent), 0644); err != nil {
 				return fmt.Errorf("failed to write file %s: %w", entry.Name(), err)
 			}
 		}
@@ -335,24 +464,25 @@ func updateKonfluxConfigs(config HackConfig) error {
 
 	// Create new OCP version file if needed
 	if config.OCPVersion != "" {
-		newFilePath := filepath.Join(konfluxDir, fmt.Sprintf("openshift-pipelines-index-%s.yaml", config.OCPVersion))
+		newFilePath := filepath.Join(konfluxDir, productName("index", config.OCPVersion)+".yaml")
 		// TODO: Implement template-based file creation for new OCP version
-		// For now, just print a message
-		fmt.Printf("Note: To add support for OCP %s, create a new file: %s\n", config.OCPVersion, newFilePath)
-		fmt.Printf("Follow the reference example from an existing openshift-pipelines-index-*.yaml file\n")
+		// For now, just log a message
+		slog.WarnContext(ctx, "Create the index of the OCP version from an existing "+productName("index", "*.yaml")+" file", "ocp_version", config.OCPVersion, "path", newFilePath)
 	}
 
 	return nil
 }
 
-func updateRepoBranches(config HackConfig) error {
+func updateRepoBranches(ctx context.Context, config HackConfig) ([]string, error) {
 	reposDir := filepath.Join(config.RepoPath, "config", "konflux", "repos")
 
 	entries, err := os.ReadDir(reposDir)
 	if err != nil {
-		return fmt.Errorf("failed to read repos directory: %w", err)
+		return nil, fmt.Errorf("failed to read repos directory: %w", err)
 	}
 
+	var unchanged []string
+
 	for _, entry := range entries {
 		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".yaml") {
 			filePath := filepath.Join(reposDir, entry.Name())
@@ -360,26 +490,51 @@ func updateRepoBranches(config HackConfig) error {
 			// Read the original content as string to preserve exact format
 			content, err := os.ReadFile(filePath)
 			if err != nil {
-				return fmt.Errorf("failed to read file %s: %w", entry.Name(), err)
+				return nil, fmt.Errorf("failed to read file %s: %w", entry.Name(), err)
 			}
 
 			// Parse YAML to find the repository name and upstream status
 			var yamlData map[string]interface{}
 			if err := yaml.Unmarshal(content, &yamlData); err != nil {
-				return fmt.Errorf("failed to parse YAML: %w", err)
+				return nil, fmt.Errorf("failed to parse YAML: %w", err)
 			}
 
 			repoName := yamlData["name"].(string)
 			hasUpstream := yamlData["upstream"] != nil
-			hasPatches := yamlData["patches"] != nil
+			patches, patchAnchor, err := topLevelPatches(content)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read patches from %s: %w", entry.Name(), err)
+			}
 
 			// Create branch config
 			branchConfig := createBranchConfig(config.MinorVersion, repoName, hasUpstream, config.UpstreamConfig)
 
+			// New patches cannot be appended to an aliased list, so the branch
+			// gets an explicit list. The same applies when the top-level patches
+			// have no anchor to reference.
+			if extra := config.Patches[repoName]; len(extra) > 0 {
+				branchConfig.Patches = append(append([]Patch{}, patches...), extra...)
+			} else if len(patches) > 0 && patchAnchor == "" {
+				branchConfig.Patches = patches
+			}
+
 			// Format branch YAML
-			branchLines := formatBranchYAML(branchConfig, "  ", hasPatches)
+			branchLines := formatBranchYAML(branchConfig, "  ", patchAnchor)
 			branchYAML := strings.Join(branchLines, "\n")
 
+			if config.Incremental {
+				if hasBranchEntry(yamlData, branchConfig.Name) {
+					slog.DebugContext(ctx, "Branch already configured", "repository", repoName, "branch", branchConfig.Name)
+					unchanged = append(unchanged, repoName)
+					continue
+				}
+				if err := appendBranchEntry(ctx, filePath, string(content), branchYAML); err != nil {
+					return nil, err
+				}
+				slog.DebugContext(ctx, "Added branch", "repository", repoName, "branch", branchConfig.Name)
+				continue
+			}
+
 			// Find the start of the branches section
 			branchesStart := strings.Index(string(content), "\nbranches:")
 			if branchesStart == -1 {
@@ -389,8 +544,8 @@ func updateRepoBranches(config HackConfig) error {
 					newContent += "\n"
 				}
 				newContent += "branches:\n" + branchYAML
-				if err := os.WriteFile(filePath, []byte(newContent), 0644); err != nil {
-					return fmt.Errorf("failed to write file: %w", err)
+				if err := writeFile(ctx, filePath, []byte(newContent), 0644); err != nil {
+					return nil, fmt.Errorf("failed to write file: %w", err)
 				}
 			} else {
 				// Find the end of the branches section
@@ -402,14 +557,42 @@ func updateRepoBranches(config HackConfig) error {
 
 				// Replace only the branches section
 				newContent := string(content)[:branchesStart+1] + "branches:\n" + branchYAML + string(content)[branchesStart+nextSection+1:]
-				if err := os.WriteFile(filePath, []byte(newContent), 0644); err != nil {
-					return fmt.Errorf("failed to write file: %w", err)
+				if err := writeFile(ctx, filePath, []byte(newContent), 0644); err != nil {
+					return nil, fmt.Errorf("failed to write file: %w", err)
 				}
 			}
 
-			fmt.Printf("Updated %s with version %s\n", repoName, config.MinorVersion)
+			slog.DebugContext(ctx, "Updated branches", "repository", repoName, "version", config.MinorVersion)
 		}
 	}
 
+	return unchanged, nil
+}
+
+// appendBranchEntry adds a branch entry at the end of the branches section,
+// leaving the existing entries untouched
+func appendBranchEntry(ctx context.Context, filePath, content, branchYAML string) error {
+	var newContent string
+	branchesStart := strings.Index(content, "\nbranches:")
+	if branchesStart == -1 {
+		newContent = content
+		if !strings.HasSuffix(newContent, "\n") {
+			newContent += "\n"
+		}
+		newContent += "branches:\n" + branchYAML + "\n"
+	} else {
+		// Find the end of the branches section
+		sectionEnd := strings.Index(content[branchesStart+1:], "\n\n")
+		if sectionEnd == -1 {
+			newContent = strings.TrimRight(content, "\n") + "\n" + branchYAML + "\n"
+		} else {
+			insertAt := branchesStart + 1 + sectionEnd
+			newContent = content[:insertAt] + "\n" + branchYAML + content[insertAt:]
+		}
+	}
+
+	if err := writeFile(ctx, filePath, []byte(newContent), 0644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
 	return nil
 }
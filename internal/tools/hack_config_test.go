@@ -0,0 +1,68 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestYAMLScalar(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "add-labels", want: "add-labels"},
+		{in: "", want: `""`},
+		{in: "true", want: `"true"`},
+		{in: "1.21", want: `"1.21"`},
+		{in: "key: value", want: `'key: value'`},
+		{in: "sed -i 's/a/b/' file", want: `sed -i 's/a/b/' file`},
+		{in: "line one\nline two", want: `"line one\nline two"`},
+	}
+	for _, tt := range tests {
+		if got := yamlScalar(tt.in); got != tt.want {
+			t.Errorf("yamlScalar(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestAppendBranchEntry(t *testing.T) {
+	const entry = "  release-v1.21.x:\n    upstream: release-v0.68.x"
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "no branches section",
+			content: "repository: tektoncd-pipeline",
+			want:    "repository: tektoncd-pipeline\nbranches:\n" + entry + "\n",
+		},
+		{
+			name:    "branches section at the end",
+			content: "repository: tektoncd-pipeline\nbranches:\n  main:\n    upstream: main\n",
+			want:    "repository: tektoncd-pipeline\nbranches:\n  main:\n    upstream: main\n" + entry + "\n",
+		},
+		{
+			name:    "branches section followed by another section",
+			content: "repository: tektoncd-pipeline\nbranches:\n  main:\n    upstream: main\n\npatches: []\n",
+			want:    "repository: tektoncd-pipeline\nbranches:\n  main:\n    upstream: main\n" + entry + "\n\npatches: []\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "repo.yaml")
+			if err := appendBranchEntry(context.Background(), path, tt.content, entry); err != nil {
+				t.Fatalf("appendBranchEntry() = %v", err)
+			}
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("appendBranchEntry() wrote\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
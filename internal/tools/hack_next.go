@@ -0,0 +1,140 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// nextMinorVersion returns the minor version following the given one (e.g. 1.21 -> 1.22)
+func nextMinorVersion(minorVersion string) (string, error) {
+	parts := strings.Split(minorVersion, ".")
+	if len(parts) != 2 {
+		return "", fmt.Errorf("invalid minor version %q, expected <major>.<minor>", minorVersion)
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return "", fmt.Errorf("invalid minor version %q: %w", minorVersion, err)
+	}
+	return fmt.Sprintf("%s.%d", parts[0], minor+1), nil
+}
+
+// ConfigureHackNext points hack's development branch at the next dev cycle
+// once the release branch for config.MinorVersion has been cut
+func ConfigureHackNext(ctx context.Context, config HackConfig) (HackResult, error) {
+	var result HackResult
+
+	nextVersion, err := nextMinorVersion(config.MinorVersion)
+	if err != nil {
+		return result, err
+	}
+
+	if config.BaseBranch == "" {
+		config.BaseBranch = "next"
+	}
+	config.PRTitle = fmt.Sprintf("Start %s development cycle", nextVersion)
+	config.PRBody = fmt.Sprintf(`Update Konflux configuration on %s for the %s development cycle
+
+Changes:
+- Bumped version references from %s to %s after cutting release-v%s.x
+`, config.BaseBranch, nextVersion, config.MinorVersion, nextVersion, config.MinorVersion)
+
+	if err := cloneHackRepo(ctx, config); err != nil {
+		return result, fmt.Errorf("failed to clone hack repository: %w", err)
+	}
+
+	if err := createPRBranch(ctx, config); err != nil {
+		return result, fmt.Errorf("failed to create PR branch: %w", err)
+	}
+
+	if err := bumpKonfluxVersions(ctx, config.RepoPath, config.MinorVersion, nextVersion); err != nil {
+		return result, fmt.Errorf("failed to bump Konflux configurations: %w", err)
+	}
+
+	changed, err := hasWorkingTreeChanges(ctx, config.RepoPath)
+	if err != nil {
+		return result, err
+	}
+	if !changed {
+		slog.InfoContext(ctx, "Branch already targets the next version, skipping pull request", "branch", config.BaseBranch, "next_version", nextVersion)
+		return result, nil
+	}
+
+	pr, err := createAndPushPR(ctx, config)
+	if err != nil {
+		return result, fmt.Errorf("failed to create and push PR: %w", err)
+	}
+	result.PRURL = pr.URL
+	result.PullRequest = &pr
+
+	slog.InfoContext(ctx, "Opened pull request", "url", pr.URL)
+	return result, nil
+}
+
+// bumpKonfluxVersions replaces references to the released version with the next version
+// in the top-level Konflux configuration files
+func bumpKonfluxVersions(ctx context.Context, repoPath, currentVersion, nextVersion string) error {
+	konfluxDir := filepath.Join(repoPath, "config", "konflux")
+
+	entries, err := os.ReadDir(konfluxDir)
+	if err != nil {
+		return fmt.Errorf("failed to read konflux directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".yaml") {
+			continue
+		}
+
+		filePath := filepath.Join(konfluxDir, entry.Name())
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return fmt.Errorf("failed to read file %s: %w", entry.Name(), err)
+		}
+
+		newContent := replaceVersion(string(content), currentVersion, nextVersion)
+		if newContent == string(content) {
+			continue
+		}
+
+		if err := writeFile(ctx, filePath, []byte(newContent), 0644); err != nil {
+			return fmt.Errorf("failed to write file %s: %w", entry.Name(), err)
+		}
+		slog.DebugContext(ctx, "Updated Konflux version", "file", entry.Name(), "next_version", nextVersion)
+	}
+
+	return nil
+}
+
+// replaceVersion replaces the occurrences of version standing on their own in
+// content, leaving longer versions such as 1.21.3 or 0.1.21 untouched
+func replaceVersion(content, version, replacement string) string {
+	var replaced strings.Builder
+	start := 0
+	for i := 0; ; {
+		j := strings.Index(content[i:], version)
+		if j < 0 {
+			break
+		}
+		j += i
+		end := j + len(version)
+		if (j == 0 || !isVersionByte(content[j-1])) && (end == len(content) || !isVersionByte(content[end])) {
+			replaced.WriteString(content[start:j])
+			replaced.WriteString(replacement)
+			start, i = end, end
+		} else {
+			i = j + 1
+		}
+	}
+	replaced.WriteString(content[start:])
+	return replaced.String()
+}
+
+// isVersionByte reports whether c can be part of a version number
+func isVersionByte(c byte) bool {
+	return c == '.' || ('0' <= c && c <= '9')
+}
@@ -0,0 +1,50 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBumpKonfluxVersions(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    string
+	}{
+		{name: "version field", file: "component.yaml", content: "version: 1.21\n", want: "version: 1.22\n"},
+		{name: "quoted version", file: "component.yaml", content: "version: \"1.21\"\n", want: "version: \"1.22\"\n"},
+		{name: "version in a name", file: "component.yaml", content: "name: openshift-pipelines-1.21-index\n", want: "name: openshift-pipelines-1.22-index\n"},
+		{name: "longer version", file: "component.yaml", content: "version: 1.210\n", want: "version: 1.210\n"},
+		{name: "patch version", file: "component.yaml", content: "version: 1.21.3\n", want: "version: 1.21.3\n"},
+		{name: "version ending like it", file: "component.yaml", content: "version: 0.1.21\n", want: "version: 0.1.21\n"},
+		{name: "adjacent versions", file: "component.yaml", content: "versions: v1.21,1.21\n", want: "versions: v1.22,1.22\n"},
+		{name: "other files are skipped", file: "README.md", content: "version: 1.21\n", want: "version: 1.21\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := t.TempDir()
+			dir := filepath.Join(repo, "config", "konflux")
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join(dir, tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := bumpKonfluxVersions(context.Background(), repo, "1.21", "1.22"); err != nil {
+				t.Fatalf("bumpKonfluxVersions() = %v", err)
+			}
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("bumpKonfluxVersions() turned %q into %q, want %q", tt.content, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MissingUpstreamVersion represents an upstream version the branch entries of a repository need
+type MissingUpstreamVersion struct {
+	Component string   `json:"component"`
+	Repos     []string `json:"repos" jsonschema:"Repository files in config/konflux/repos needing the version"`
+	Reason    string   `json:"reason"`
+}
+
+// BranchValidationResult represents the structured result of validate-branch-yaml
+type BranchValidationResult struct {
+	Valid   bool                     `json:"valid"`
+	Missing []MissingUpstreamVersion `json:"missing,omitempty"`
+}
+
+// missingUpstreamVersions reports the upstream versions createBranchConfig
+// needs but were not provided. Special components name their branch after the
+// version, and repositories tracking an upstream record it on every branch.
+func missingUpstreamVersions(config HackConfig) ([]MissingUpstreamVersion, error) {
+	reposDir := filepath.Join(config.RepoPath, "config", "konflux", "repos")
+	entries, err := os.ReadDir(reposDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read repos directory: %w", err)
+	}
+
+	missing := make(map[string]*MissingUpstreamVersion)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".yaml") {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(reposDir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file %s: %w", entry.Name(), err)
+		}
+		var yamlData map[string]interface{}
+		if err := yaml.Unmarshal(content, &yamlData); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", entry.Name(), err)
+		}
+
+		repoName, _ := yamlData["name"].(string)
+		componentName, ok := componentForRepo(repoName)
+		if !ok {
+			continue
+		}
+		if _, ok := config.UpstreamConfig[componentName]; ok {
+			continue
+		}
+
+		var reason string
+		switch {
+		case specialComponents[componentName]:
+			reason = "branch is named after the upstream version"
+		case yamlData["upstream"] != nil:
+			reason = "branch records the upstream release branch"
+		default:
+			continue
+		}
+
+		if missing[componentName] == nil {
+			missing[componentName] = &MissingUpstreamVersion{Component: componentName, Reason: reason}
+		}
+		missing[componentName].Repos = append(missing[componentName].Repos, entry.Name())
+	}
+
+	var result []MissingUpstreamVersion
+	for _, m := range missing {
+		result = append(result, *m)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Component < result[j].Component
+	})
+	return result, nil
+}
+
+func formatMissingUpstreamVersions(missing []MissingUpstreamVersion) string {
+	var sb strings.Builder
+	for _, m := range missing {
+		fmt.Fprintf(&sb, "- %s (%s): %s\n", m.Component, m.Reason, strings.Join(m.Repos, ", "))
+	}
+	return sb.String()
+}
+
+// validateBranchYAML clones the hack repository and reports missing upstream versions
+func validateBranchYAML(ctx context.Context, config HackConfig) (BranchValidationResult, error) {
+	result := BranchValidationResult{}
+	if err := cloneHackRepo(ctx, config); err != nil {
+		return result, fmt.Errorf("failed to clone hack repository: %w", err)
+	}
+
+	manifest, err := readVersionsManifest(config.RepoPath)
+	if err != nil {
+		return result, err
+	}
+	pinnedUpstreamVersions(&config, manifest)
+
+	missing, err := missingUpstreamVersions(config)
+	if err != nil {
+		return result, err
+	}
+	result.Missing = missing
+	result.Valid = len(missing) == 0
+	return result, nil
+}
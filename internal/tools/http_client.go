@@ -0,0 +1,253 @@
+package tools
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// HTTPClientConfig represents the configuration for outbound HTTP clients
+type HTTPClientConfig struct {
+	Timeout    time.Duration // Wait for the response headers of each attempt
+	MaxRetries int
+	RetryWait  time.Duration
+	UserAgent  string
+	CAFile     string // Optional PEM bundle appended to the system roots
+}
+
+// HTTPMetrics holds counters for outbound HTTP calls made through the shared client
+type HTTPMetrics struct {
+	Requests int64
+	Retries  int64
+	Failures int64
+}
+
+var (
+	httpRequests atomic.Int64
+	httpRetries  atomic.Int64
+	httpFailures atomic.Int64
+
+	sharedHTTPClient     *http.Client
+	sharedHTTPClientErr  error
+	sharedHTTPClientOnce sync.Once
+)
+
+// defaultHTTPClientConfig returns the client configuration, honouring
+// RELEASE_MCP_CA_FILE for internal endpoints signed by a private CA.
+func defaultHTTPClientConfig() HTTPClientConfig {
+	return HTTPClientConfig{
+		Timeout:    30 * time.Second,
+		MaxRetries: 3,
+		RetryWait:  time.Second,
+		UserAgent:  "tekton-release-mcp",
+		CAFile:     os.Getenv("RELEASE_MCP_CA_FILE"),
+	}
+}
+
+// httpClient returns the shared HTTP client used for all external calls
+// (GitLab, GitHub, registries). Integrations must use it instead of
+// creating their own clients.
+func httpClient() (*http.Client, error) {
+	sharedHTTPClientOnce.Do(func() {
+		sharedHTTPClient, sharedHTTPClientErr = newHTTPClient(defaultHTTPClientConfig())
+	})
+	return sharedHTTPClient, sharedHTTPClientErr
+}
+
+// GetHTTPMetrics returns a snapshot of the outbound HTTP counters
+func GetHTTPMetrics() HTTPMetrics {
+	return HTTPMetrics{
+		Requests: httpRequests.Load(),
+		Retries:  httpRetries.Load(),
+		Failures: httpFailures.Load(),
+	}
+}
+
+func newHTTPClient(config HTTPClientConfig) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	// The timeout bounds every attempt rather than the whole call: a client
+	// timeout would also cut the backoff and Retry-After waits between
+	// attempts, losing the response of a forge asking to wait longer
+	transport.ResponseHeaderTimeout = config.Timeout
+
+	if config.CAFile != "" {
+		pem, err := os.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file %s: %w", config.CAFile, err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", config.CAFile)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+
+	return &http.Client{
+		Transport: &instrumentedTransport{
+			base:       transport,
+			userAgent:  config.UserAgent,
+			maxRetries: config.MaxRetries,
+			retryWait:  config.RetryWait,
+		},
+	}, nil
+}
+
+// idempotentMethods are the methods of the requests retried on transient
+// failures. POST and PATCH requests, which open pull requests or post
+// comments, are never retried: a failure may still have been applied, and
+// retrying would duplicate it.
+var idempotentMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodOptions: true,
+	http.MethodPut:     true,
+	http.MethodDelete:  true,
+}
+
+// callerRetriesThrottlingKey marks the requests whose throttled responses
+// are retried by their caller
+type callerRetriesThrottlingKey struct{}
+
+// withCallerRetriesThrottling returns a context whose requests are not
+// retried by the transport when throttled, for clients such as the GitHub
+// client that wait for the rate limit themselves
+func withCallerRetriesThrottling(ctx context.Context) context.Context {
+	return context.WithValue(ctx, callerRetriesThrottlingKey{}, true)
+}
+
+// isThrottled reports whether a response was rejected by a rate limit
+func isThrottled(resp *http.Response) bool {
+	return resp != nil && (resp.StatusCode == http.StatusTooManyRequests || resp.Header.Get("X-RateLimit-Remaining") == "0")
+}
+
+// instrumentedTransport sets the user agent, counts requests and retries
+// idempotent requests on transient failures, with exponential backoff and
+// jitter or the delay asked by Retry-After. Retries are reported in the
+// usage of the tool call.
+type instrumentedTransport struct {
+	base       http.RoundTripper
+	userAgent  string
+	maxRetries int
+	retryWait  time.Duration
+}
+
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (resp *http.Response, err error) {
+	req = req.Clone(req.Context())
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", t.userAgent)
+	}
+	// Calls are traced as steps of tool calls, not on their own, so the
+	// exports of the spans are not traced themselves
+	if trace.SpanFromContext(req.Context()).IsRecording() {
+		ctx, span := tracer.Start(req.Context(), req.Method+" "+req.URL.Host, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+			attribute.String("http.request.method", req.Method),
+			attribute.String("url.path", req.URL.Path),
+		))
+		defer func() {
+			spanErr := err
+			if resp != nil {
+				span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+				if err == nil && resp.StatusCode >= 400 {
+					spanErr = fmt.Errorf("HTTP %d", resp.StatusCode)
+				}
+			}
+			endSpan(span, spanErr)
+		}()
+		req = req.WithContext(ctx)
+	}
+
+	// A request body can only be sent again when it can be replayed
+	retryable := idempotentMethods[req.Method] && (req.Body == nil || req.Body == http.NoBody || req.GetBody != nil)
+	callerRetriesThrottling, _ := req.Context().Value(callerRetriesThrottlingKey{}).(bool)
+	policy := retryPolicy{MaxAttempts: t.maxRetries + 1, BaseDelay: t.retryWait, MaxDelay: networkRetryPolicy.MaxDelay}
+
+	for attempt := 0; ; attempt++ {
+		httpRequests.Add(1)
+		resp, err = t.base.RoundTrip(req)
+		if !retryable || attempt >= t.maxRetries || req.Context().Err() != nil || !isTransient(resp, err) || (callerRetriesThrottling && isThrottled(resp)) {
+			if attempt > 0 {
+				retried := RetriedOperation{Operation: req.Method + " " + req.URL.Host, Attempts: attempt + 1, Succeeded: err == nil && !isTransient(resp, err)}
+				if !retried.Succeeded {
+					retried.LastError = transientError(resp, err)
+				}
+				runUsageFrom(req.Context()).recordRetry(retried)
+			}
+			break
+		}
+
+		delay := policy.delay(attempt + 1)
+		if resp != nil {
+			if after, ok := retryAfter(resp, time.Now()); ok {
+				delay = min(after, policy.MaxDelay)
+			}
+			resp.Body.Close()
+		}
+		if req.GetBody != nil {
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return nil, bodyErr
+			}
+			req.Body = body
+		}
+
+		httpRetries.Add(1)
+		slog.WarnContext(req.Context(), "Network operation failed, retrying", "operation", req.Method+" "+req.URL.Host, "attempt", attempt+1, "error", transientError(resp, err))
+		if err := sleepContext(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+
+	if err != nil || resp.StatusCode >= 400 {
+		httpFailures.Add(1)
+	}
+	return resp, err
+}
+
+// isTransient reports whether a response or error is worth retrying: network
+// errors such as timeouts and reset connections, throttling and 5xx responses
+func isTransient(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled)
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// retryAfter returns the delay a throttled or unavailable forge asks for in
+// the Retry-After header of its response, either in seconds or as a date
+func retryAfter(resp *http.Response, now time.Time) (time.Duration, bool) {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// transientError describes the failure of a transient response or error
+func transientError(resp *http.Response, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return resp.Status
+}
@@ -0,0 +1,124 @@
+package tools
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2026, 5, 4, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{name: "missing"},
+		{name: "seconds", value: "30", want: 30 * time.Second, wantOK: true},
+		{name: "zero", value: "0", want: 0, wantOK: true},
+		{name: "negative", value: "-5"},
+		{name: "date", value: now.Add(2 * time.Minute).Format(http.TimeFormat), want: 2 * time.Minute, wantOK: true},
+		{name: "past date", value: now.Add(-time.Minute).Format(http.TimeFormat), want: 0, wantOK: true},
+		{name: "invalid", value: "soon"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{Header: http.Header{}}
+			if tt.value != "" {
+				resp.Header.Set("Retry-After", tt.value)
+			}
+			got, ok := retryAfter(resp, now)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("retryAfter(%q) = %s, %t, want %s, %t", tt.value, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestInstrumentedTransportRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		statuses     []int  // Statuses answered in turn, the last one repeated
+		retryAfter   string // Retry-After header of the failed responses
+		callerRetry  bool   // The caller retries throttled responses itself
+		wantStatus   int
+		wantRequests int32
+	}{
+		{name: "success", method: http.MethodGet, statuses: []int{200}, wantStatus: 200, wantRequests: 1},
+		{name: "transient failure", method: http.MethodGet, statuses: []int{503, 502, 200}, wantStatus: 200, wantRequests: 3},
+		{name: "retries exhausted", method: http.MethodGet, statuses: []int{503}, wantStatus: 503, wantRequests: 3},
+		{name: "client error", method: http.MethodGet, statuses: []int{404}, wantStatus: 404, wantRequests: 1},
+		{name: "POST is not retried", method: http.MethodPost, statuses: []int{503, 200}, wantStatus: 503, wantRequests: 1},
+		{name: "throttled with Retry-After", method: http.MethodGet, statuses: []int{429, 200}, retryAfter: "0", wantStatus: 200, wantRequests: 2},
+		{name: "throttling retried by the caller", method: http.MethodGet, statuses: []int{429, 200}, callerRetry: true, wantStatus: 429, wantRequests: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := int(requests.Add(1))
+				status := tt.statuses[min(n, len(tt.statuses))-1]
+				if status >= 400 && tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(status)
+			}))
+			defer server.Close()
+
+			client, err := newHTTPClient(HTTPClientConfig{Timeout: 5 * time.Second, MaxRetries: 2, RetryWait: time.Millisecond, UserAgent: "test"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx := context.Background()
+			if tt.callerRetry {
+				ctx = withCallerRetriesThrottling(ctx)
+			}
+			req, err := http.NewRequestWithContext(ctx, tt.method, server.URL, strings.NewReader("{}"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("Do() = %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus || requests.Load() != tt.wantRequests {
+				t.Errorf("Do() = %d after %d requests, want %d after %d", resp.StatusCode, requests.Load(), tt.wantStatus, tt.wantRequests)
+			}
+		})
+	}
+}
+
+// TestInstrumentedTransportRetryAfterOutlastsTimeout checks that the wait a
+// throttled response asks for is not cut by the timeout of the attempts
+func TestInstrumentedTransportRetryAfterOutlastsTimeout(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := newHTTPClient(HTTPClientConfig{Timeout: 200 * time.Millisecond, MaxRetries: 1, RetryWait: time.Millisecond, UserAgent: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get() = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Get() = %d, want 200", resp.StatusCode)
+	}
+}
@@ -0,0 +1,283 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// imageReportPlatform is the platform image sizes are compared on
+const imageReportPlatform = "linux/amd64"
+
+// ImageReportConfig represents the configuration for the post-release image report
+type ImageReportConfig struct {
+	MinorVersion    string
+	PatchVersion    string
+	PreviousVersion string  // Release sizes are compared to, defaults to the previous patch or minor release
+	Registry        string  // Registry and namespace of the images, defaults to the prod registry
+	PublicKey       string  // Key signatures are verified with, only their presence is checked otherwise
+	MaxSizeIncrease float64 // Largest size increase in percent an image may have, unlimited when 0
+}
+
+// ImageReport represents the compliance checks of a shipped image
+type ImageReport struct {
+	Image             string   `json:"image"`
+	Digest            string   `json:"digest,omitempty"`
+	Signed            bool     `json:"signed" jsonschema:"Whether a cosign signature is attached to the digest"`
+	SignatureVerified bool     `json:"signature_verified" jsonschema:"Whether the signature verifies with the public key, when one is given"`
+	SBOM              bool     `json:"sbom" jsonschema:"Whether an SPDX or CycloneDX SBOM attestation, or an SBOM, is attached to the digest"`
+	Attestations      []string `json:"attestations,omitempty" jsonschema:"Predicate types of the attestations attached to the digest"`
+	Size              int64    `json:"size,omitempty" jsonschema:"Compressed size in bytes of the linux/amd64 image"`
+	PreviousSize      int64    `json:"previous_size,omitempty"`
+	SizeDelta         int64    `json:"size_delta,omitempty"`
+	SizeDeltaPercent  float64  `json:"size_delta_percent,omitempty"`
+	Issues            []string `json:"issues,omitempty" jsonschema:"Reasons the image is not compliant"`
+}
+
+// ImageReportResult represents the structured result of compute-image-size-and-signature-report
+type ImageReportResult struct {
+	Version         string        `json:"version"`
+	PreviousVersion string        `json:"previous_version,omitempty" jsonschema:"Release sizes are compared to, unset when none was found"`
+	Registry        string        `json:"registry"`
+	Compliant       bool          `json:"compliant" jsonschema:"Whether every image is signed, has an SBOM and stays within the size increase limit"`
+	Images          []ImageReport `json:"images"`
+}
+
+// computeImageReport checks the signature, SBOM and size of every component
+// image of a release, comparing sizes to the previous release
+func computeImageReport(ctx context.Context, config ImageReportConfig) (ImageReportResult, error) {
+	_, fullVersion := getReleaseType(config.MinorVersion, config.PatchVersion)
+	if config.Registry == "" {
+		config.Registry = productImageNamespace("prod")
+	}
+	result := ImageReportResult{Version: fullVersion, Registry: config.Registry, Compliant: true}
+
+	result.PreviousVersion = config.PreviousVersion
+	if result.PreviousVersion == "" {
+		previous, err := previousReleaseVersion(ctx, config.MinorVersion, config.PatchVersion)
+		if err != nil {
+			slog.WarnContext(ctx, "Not comparing image sizes", "error", err)
+		}
+		result.PreviousVersion = previous
+	}
+
+	images := componentImages()
+	for i, image := range images {
+		reportProgress(ctx, float64(i), float64(len(images)), "Checking "+image)
+		report := checkImage(ctx, config, fmt.Sprintf("%s/%s", config.Registry, image), fullVersion, result.PreviousVersion)
+		if len(report.Issues) > 0 {
+			result.Compliant = false
+		}
+		result.Images = append(result.Images, report)
+	}
+	return result, nil
+}
+
+// previousReleaseVersion returns the release preceding a version: the previous
+// patch of the same minor, or the latest patch of the previous minor
+func previousReleaseVersion(ctx context.Context, minorVersion, patchVersion string) (string, error) {
+	gh, err := newGitHubClient()
+	if err != nil {
+		return "", err
+	}
+	if patchVersion != "" && patchVersion != "0" {
+		patch, err := latestPatchVersion(ctx, gh, operatorRepository(), minorVersion, patchVersion)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s.%d", minorVersion, patch), nil
+	}
+
+	major, minor, _ := strings.Cut(minorVersion, ".")
+	minorNumber, err := strconv.Atoi(minor)
+	if err != nil || minorNumber == 0 {
+		return "", fmt.Errorf("no minor version precedes %s", minorVersion)
+	}
+	previousMinor := fmt.Sprintf("%s.%d", major, minorNumber-1)
+	patch, err := latestPatchVersion(ctx, gh, operatorRepository(), previousMinor, "")
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s.%d", previousMinor, patch), nil
+}
+
+// checkImage reports the compliance checks of the release tag of an image repository
+func checkImage(ctx context.Context, config ImageReportConfig, repository, version, previousVersion string) ImageReport {
+	report := ImageReport{Image: fmt.Sprintf("%s:v%s", repository, version)}
+
+	digest, err := runCrane(ctx, "digest", report.Image)
+	if err != nil {
+		report.Issues = append(report.Issues, fmt.Sprintf("failed to resolve: %v", err))
+		return report
+	}
+	report.Digest = digest
+	ref := repository + "@" + digest
+
+	report.Signed = attachedArtifactExists(ctx, ref, "signature")
+	if config.PublicKey != "" && report.Signed {
+		// Product signatures are not uploaded to the public transparency log
+		_, err := runCosign(ctx, "verify", "--key", config.PublicKey, "--insecure-ignore-tlog", ref)
+		report.SignatureVerified = err == nil
+		if err != nil {
+			report.Issues = append(report.Issues, "signature does not verify with the public key")
+		}
+	}
+	if !report.Signed {
+		report.Issues = append(report.Issues, "not signed")
+	}
+
+	report.Attestations, err = attestationTypes(ctx, ref)
+	if err != nil {
+		report.Issues = append(report.Issues, fmt.Sprintf("failed to read attestations: %v", err))
+	}
+	for _, predicateType := range report.Attestations {
+		if strings.Contains(predicateType, "spdx") || strings.Contains(predicateType, "cyclonedx") {
+			report.SBOM = true
+		}
+	}
+	if !report.SBOM {
+		report.SBOM = attachedArtifactExists(ctx, ref, "sbom")
+	}
+	if !report.SBOM {
+		report.Issues = append(report.Issues, "no SBOM attached")
+	}
+
+	if report.Size, err = imageSize(ctx, ref); err != nil {
+		report.Issues = append(report.Issues, fmt.Sprintf("failed to read size: %v", err))
+		return report
+	}
+	if previousVersion == "" {
+		return report
+	}
+	// Images new in this release have nothing to compare to
+	if report.PreviousSize, err = imageSize(ctx, fmt.Sprintf("%s:v%s", repository, previousVersion)); err != nil {
+		report.PreviousSize = 0
+		return report
+	}
+	report.SizeDelta = report.Size - report.PreviousSize
+	if report.PreviousSize > 0 {
+		report.SizeDeltaPercent = float64(report.SizeDelta) * 100 / float64(report.PreviousSize)
+	}
+	if config.MaxSizeIncrease > 0 && report.SizeDeltaPercent > config.MaxSizeIncrease {
+		report.Issues = append(report.Issues, fmt.Sprintf("grew %.1f%% since v%s, more than %.1f%%", report.SizeDeltaPercent, previousVersion, config.MaxSizeIncrease))
+	}
+	return report
+}
+
+// attachedArtifactExists reports whether the cosign signature or SBOM tag of an image digest exists
+func attachedArtifactExists(ctx context.Context, ref, kind string) bool {
+	tag, err := runCosign(ctx, "triangulate", "--type", kind, ref)
+	if err != nil {
+		return false
+	}
+	_, err = runCrane(ctx, "digest", tag)
+	return err == nil
+}
+
+// attestationTypes returns the predicate types of the in-toto attestations attached to an image digest
+func attestationTypes(ctx context.Context, ref string) ([]string, error) {
+	if !attachedArtifactExists(ctx, ref, "attestation") {
+		return nil, nil
+	}
+	output, err := runCosign(ctx, "download", "attestation", ref)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var types []string
+	for _, line := range strings.Split(output, "\n") {
+		var envelope struct {
+			Payload string `json:"payload"`
+		}
+		if strings.TrimSpace(line) == "" || json.Unmarshal([]byte(line), &envelope) != nil {
+			continue
+		}
+		payload, err := base64.StdEncoding.DecodeString(envelope.Payload)
+		if err != nil {
+			continue
+		}
+		var statement struct {
+			PredicateType string `json:"predicateType"`
+		}
+		if json.Unmarshal(payload, &statement) == nil && statement.PredicateType != "" && !seen[statement.PredicateType] {
+			seen[statement.PredicateType] = true
+			types = append(types, statement.PredicateType)
+		}
+	}
+	return types, nil
+}
+
+// imageSize returns the compressed size of the configuration and layers of an image on imageReportPlatform
+func imageSize(ctx context.Context, ref string) (int64, error) {
+	output, err := runCrane(ctx, "manifest", "--platform", imageReportPlatform, ref)
+	if err != nil {
+		return 0, err
+	}
+	var manifest struct {
+		Config struct {
+			Size int64 `json:"size"`
+		} `json:"config"`
+		Layers []struct {
+			Size int64 `json:"size"`
+		} `json:"layers"`
+	}
+	if err := json.Unmarshal([]byte(output), &manifest); err != nil {
+		return 0, fmt.Errorf("failed to parse manifest of %s: %w", ref, err)
+	}
+	size := manifest.Config.Size
+	for _, layer := range manifest.Layers {
+		size += layer.Size
+	}
+	return size, nil
+}
+
+func runCosign(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "cosign", args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := runCommand(ctx, cmd); err != nil {
+		return "", fmt.Errorf("cosign %s failed: %v\nError details: %s", args[0], err, stderr.String())
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+func formatImageReport(result ImageReportResult) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Image report for v%s in %s", result.Version, result.Registry)
+	if result.PreviousVersion != "" {
+		fmt.Fprintf(&sb, ", sizes compared to v%s", result.PreviousVersion)
+	}
+	sb.WriteString(":\n")
+
+	compliant := 0
+	for _, image := range result.Images {
+		marker := "✓"
+		if len(image.Issues) > 0 {
+			marker = "✗"
+		} else {
+			compliant++
+		}
+		fmt.Fprintf(&sb, "%s %s", marker, image.Image)
+		if image.Size > 0 {
+			fmt.Fprintf(&sb, " (%.1f MB", float64(image.Size)/1e6)
+			if image.PreviousSize > 0 {
+				fmt.Fprintf(&sb, ", %+.1f%%", image.SizeDeltaPercent)
+			}
+			sb.WriteString(")")
+		}
+		if len(image.Issues) > 0 {
+			fmt.Fprintf(&sb, ": %s", strings.Join(image.Issues, "; "))
+		}
+		sb.WriteString("\n")
+	}
+	fmt.Fprintf(&sb, "\n%d of %d images compliant\n", compliant, len(result.Images))
+	return sb.String()
+}
@@ -0,0 +1,130 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	mcp "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// instructionsIntro opens the instructions sent to clients on initialization
+const instructionsIntro = "This server automates the %s release process across GitHub, the hack repository and konflux-release-data."
+
+// releaseWorkflow is the order tools are recommended to run in for a minor
+// release. Registered tools missing from it are listed after it.
+var releaseWorkflow = []string{
+	"query-release-calendar",
+	"find-release-blocking-issues",
+	"create-release-branches",
+	"configure-hack-repo",
+	"validate-branch-yaml",
+	"verify-branch-build-yaml",
+	"generate-release-branch-readme",
+	"create-release-branch-ci-badge-and-status-page",
+	"list-ocp-index-applications",
+	"create-release-plans",
+	"verify-kustomize-build-of-tenant-config-in-CI-parity",
+	"verify-rpa-references",
+	"stage-vs-prod-rpa-consistency-check",
+	"verify-tenant-namespace-quota-and-permissions",
+	"check-fbc-allowed-packages",
+	"registry-tag-promotion",
+	"verify-git-tags-match-rpa-version-tags",
+	"diff-live-cluster-rpa-vs-git-before-release",
+	"configure-hack-next",
+}
+
+// instructionsMiddleware replaces the static server instructions with ones
+// generated from the tools registered when the client initializes
+func instructionsMiddleware(next mcp.MethodHandler[*mcp.ServerSession]) mcp.MethodHandler[*mcp.ServerSession] {
+	return func(ctx context.Context, session *mcp.ServerSession, method string, params mcp.Params) (mcp.Result, error) {
+		result, err := next(ctx, session, method, params)
+		initResult, ok := result.(*mcp.InitializeResult)
+		if method != "initialize" || err != nil || !ok {
+			return result, err
+		}
+
+		var tools []*mcp.Tool
+		listParams := &mcp.ListToolsParams{}
+		for {
+			listResult, err := next(ctx, session, "tools/list", listParams)
+			if err != nil {
+				// Keep the static instructions rather than failing the initialization
+				return initResult, nil
+			}
+			page := listResult.(*mcp.ListToolsResult)
+			tools = append(tools, page.Tools...)
+			if page.NextCursor == "" {
+				break
+			}
+			listParams = &mcp.ListToolsParams{Cursor: page.NextCursor}
+		}
+
+		initResult.Instructions = serverInstructions(tools)
+		return initResult, nil
+	}
+}
+
+// serverInstructions describes the tools, in the recommended order of the release workflow
+func serverInstructions(tools []*mcp.Tool) string {
+	byName := make(map[string]*mcp.Tool, len(tools))
+	for _, tool := range tools {
+		byName[tool.Name] = tool
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, instructionsIntro+"\n", product().DisplayName)
+
+	listed := make(map[string]bool)
+	step := 0
+	for _, name := range releaseWorkflow {
+		tool, ok := byName[name]
+		if !ok {
+			continue
+		}
+		if step == 0 {
+			sb.WriteString("\nRecommended order for a minor release:\n")
+		}
+		step++
+		listed[name] = true
+		fmt.Fprintf(&sb, "%d. %s: %s\n", step, tool.Name, tool.Description)
+	}
+
+	other := false
+	for _, tool := range tools {
+		if listed[tool.Name] {
+			continue
+		}
+		if !other {
+			sb.WriteString("\nOther tools:\n")
+			other = true
+		}
+		fmt.Fprintf(&sb, "- %s: %s\n", tool.Name, tool.Description)
+	}
+
+	windowGuardedToolsMu.Lock()
+	var guarded []string
+	for _, tool := range tools {
+		if windowGuardedTools[tool.Name] {
+			guarded = append(guarded, tool.Name)
+		}
+	}
+	windowGuardedToolsMu.Unlock()
+	if len(guarded) > 0 {
+		fmt.Fprintf(&sb, "\nThese tools change shared state and only run within the release window of their version unless %s is set: %s\n", windowOverrideArg, strings.Join(guarded, ", "))
+	}
+
+	confirmedToolsMu.Lock()
+	var confirmed []string
+	for _, tool := range tools {
+		if confirmedTools[tool.Name] != nil {
+			confirmed = append(confirmed, tool.Name)
+		}
+	}
+	confirmedToolsMu.Unlock()
+	if len(confirmed) > 0 {
+		fmt.Fprintf(&sb, "\nWhen these tools push to many repositories or change prod, the first call only returns a plan and its hash. Show the plan to the user and, once they approve it, repeat the call with %s set to the hash: %s\n", confirmArg, strings.Join(confirmed, ", "))
+	}
+	return sb.String()
+}
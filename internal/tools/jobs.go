@@ -0,0 +1,270 @@
+package tools
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
+	mcp "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// asyncArg is the argument running a tool call as a background job
+	asyncArg = "async"
+
+	// maxFinishedJobs is how many finished jobs are kept for polling
+	maxFinishedJobs = 100
+)
+
+// Job states
+const (
+	jobRunning   = "running"
+	jobSucceeded = "succeeded"
+	jobFailed    = "failed"
+)
+
+// asyncTools holds the names of the tools that can run as background jobs
+var (
+	asyncTools   = map[string]bool{}
+	asyncToolsMu sync.Mutex
+)
+
+// JobStatus represents a tool call running in the background
+type JobStatus struct {
+	ID               string         `json:"id"`
+	Tool             string         `json:"tool"`
+	Arguments        map[string]any `json:"arguments,omitempty"`
+	State            string         `json:"state" jsonschema:"running, succeeded or failed"`
+	StartedAt        time.Time      `json:"started_at"`
+	FinishedAt       time.Time      `json:"finished_at,omitzero"`
+	Result           string         `json:"result,omitempty" jsonschema:"Text result of the tool call once finished"`
+	StructuredResult any            `json:"structured_result,omitempty" jsonschema:"Structured result of the tool call once finished"`
+	LogLines         int            `json:"log_lines" jsonschema:"Number of output lines available through get-job-logs"`
+}
+
+// JobLogsResult represents the structured result of get-job-logs
+type JobLogsResult struct {
+	ID    string   `json:"id"`
+	State string   `json:"state"`
+	Lines []string `json:"lines"`
+	Next  int      `json:"next" jsonschema:"Offset to pass to read the lines logged after these"`
+}
+
+// job collects the status and subprocess output of a background tool call
+type job struct {
+	mu     sync.Mutex
+	status JobStatus
+	logs   []string
+}
+
+type jobKey struct{}
+
+// jobFrom returns the job of the context, or nil when the tool call runs in the foreground
+func jobFrom(ctx context.Context) *job {
+	j, _ := ctx.Value(jobKey{}).(*job)
+	return j
+}
+
+// jobManager keeps the jobs of the server in memory, they do not survive a restart
+type jobManager struct {
+	mu    sync.Mutex
+	jobs  map[string]*job
+	order []string // Job IDs, oldest first
+}
+
+var backgroundJobs = &jobManager{jobs: map[string]*job{}}
+
+// runAsync adds the async argument to the input schema of a long running tool
+func runAsync(tool *mcp.Tool) *mcp.Tool {
+	asyncToolsMu.Lock()
+	asyncTools[tool.Name] = true
+	asyncToolsMu.Unlock()
+
+	if tool.InputSchema.Properties == nil {
+		tool.InputSchema.Properties = map[string]*jsonschema.Schema{}
+	}
+	tool.InputSchema.Properties[asyncArg] = &jsonschema.Schema{
+		Type:        "boolean",
+		Description: "Return a job ID immediately and run the tool in the background. Poll get-job-status and get-job-logs for its progress and result",
+	}
+	return tool
+}
+
+func isAsync(name string) bool {
+	asyncToolsMu.Lock()
+	defer asyncToolsMu.Unlock()
+	return asyncTools[name]
+}
+
+// asyncMiddleware runs tool calls setting the async argument as background
+// jobs. The job runs the rest of the middleware chain, so it is audited,
+// guarded and locked like a call in the foreground, and outlives the request.
+// The working directories of its session are kept until it ends.
+func asyncMiddleware(next mcp.MethodHandler[*mcp.ServerSession]) mcp.MethodHandler[*mcp.ServerSession] {
+	return func(ctx context.Context, session *mcp.ServerSession, method string, params mcp.Params) (mcp.Result, error) {
+		callParams, ok := params.(*mcp.CallToolParamsFor[json.RawMessage])
+		if method != "tools/call" || !ok || !isAsync(callParams.Name) {
+			return next(ctx, session, method, params)
+		}
+
+		var args map[string]any
+		_ = json.Unmarshal(callParams.Arguments, &args)
+		if async, _ := args[asyncArg].(bool); !async {
+			return next(ctx, session, method, params)
+		}
+
+		jobDone := func() {}
+		if session != nil {
+			var err error
+			if jobDone, err = sessions.startJob(session); err != nil {
+				return nil, err
+			}
+		}
+		j, err := backgroundJobs.start(callParams.Name, args)
+		if err != nil {
+			jobDone()
+			return nil, err
+		}
+		jobCtx := context.WithValue(context.WithoutCancel(ctx), jobKey{}, j)
+		go func() {
+			defer jobDone()
+			result, err := next(jobCtx, session, method, params)
+			j.finish(result, err)
+		}()
+
+		status := j.snapshot()
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Started job %s running %s. Poll get-job-status and get-job-logs with job_id %s", status.ID, status.Tool, status.ID)}},
+			Meta:    mcp.Meta{"job": status},
+		}, nil
+	}
+}
+
+// start registers a running job, dropping the oldest finished jobs beyond maxFinishedJobs
+func (m *jobManager) start(tool string, args map[string]any) (*job, error) {
+	id := make([]byte, 4)
+	if _, err := rand.Read(id); err != nil {
+		return nil, fmt.Errorf("failed to generate job ID: %w", err)
+	}
+	j := &job{status: JobStatus{
+		ID:        hex.EncodeToString(id),
+		Tool:      tool,
+		Arguments: args,
+		State:     jobRunning,
+		StartedAt: time.Now(),
+	}}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.jobs[j.status.ID] = j
+	m.order = append(m.order, j.status.ID)
+
+	finished := 0
+	for _, id := range m.order {
+		if m.jobs[id].snapshot().State != jobRunning {
+			finished++
+		}
+	}
+	kept := m.order[:0]
+	for _, id := range m.order {
+		if finished > maxFinishedJobs && m.jobs[id].snapshot().State != jobRunning {
+			delete(m.jobs, id)
+			finished--
+			continue
+		}
+		kept = append(kept, id)
+	}
+	m.order = kept
+	return j, nil
+}
+
+func (m *jobManager) get(id string) (*job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j, ok := m.jobs[id]
+	if !ok {
+		return nil, fmt.Errorf("no job %s", id)
+	}
+	return j, nil
+}
+
+// send appends a line of subprocess output to the job logs
+func (j *job) send(level mcp.LoggingLevel, line string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.logs = append(j.logs, line)
+}
+
+// attach tees the output of a command to the job logs, see attachLines
+func (j *job) attach(cmd *exec.Cmd, captureStdout bool) func() {
+	return attachLines(j, cmd, captureStdout)
+}
+
+// finish records the result of the tool call
+func (j *job) finish(result mcp.Result, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.status.FinishedAt = time.Now()
+	j.status.State = jobSucceeded
+	if err != nil {
+		j.status.State = jobFailed
+		j.status.Result = err.Error()
+		return
+	}
+	toolResult, ok := result.(*mcp.CallToolResult)
+	if !ok {
+		return
+	}
+	if toolResult.IsError {
+		j.status.State = jobFailed
+	}
+	var texts []string
+	for _, content := range toolResult.Content {
+		if text, ok := content.(*mcp.TextContent); ok {
+			texts = append(texts, text.Text)
+		}
+	}
+	j.status.Result = strings.Join(texts, "\n")
+	j.status.StructuredResult = toolResult.StructuredContent
+}
+
+func (j *job) snapshot() JobStatus {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	status := j.status
+	status.LogLines = len(j.logs)
+	return status
+}
+
+// logsFrom returns the output lines of the job starting at offset
+func (j *job) logsFrom(offset int) JobLogsResult {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	offset = min(max(offset, 0), len(j.logs))
+	return JobLogsResult{
+		ID:    j.status.ID,
+		State: j.status.State,
+		Lines: append([]string{}, j.logs[offset:]...),
+		Next:  len(j.logs),
+	}
+}
+
+func formatJobStatus(status JobStatus) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Job %s (%s) %s, started %s", status.ID, status.Tool, status.State, status.StartedAt.Format(time.RFC3339))
+	if !status.FinishedAt.IsZero() {
+		fmt.Fprintf(&sb, ", finished after %s", status.FinishedAt.Sub(status.StartedAt).Round(time.Second))
+	}
+	fmt.Fprintf(&sb, ", %d log lines\n", status.LogLines)
+	if status.Result != "" {
+		fmt.Fprintf(&sb, "\n%s\n", status.Result)
+	}
+	return sb.String()
+}
@@ -0,0 +1,111 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+const (
+	// defaultKonfluxRepository is the canonical GitLab repository ReleasePlans
+	// and ReleasePlanAdmissions are proposed to
+	defaultKonfluxRepository = "releng/konflux-release-data"
+
+	// konfluxRepositoryEnv and konfluxForkEnv set the konflux-release-data
+	// remotes when neither the flags nor the configuration file do
+	konfluxRepositoryEnv = "RELEASE_MCP_KONFLUX_REPOSITORY"
+	konfluxForkEnv       = "RELEASE_MCP_KONFLUX_FORK"
+
+	// konfluxForkRemote is the remote of the fork in konflux-release-data clones
+	konfluxForkRemote = "fork"
+)
+
+// konfluxRemotes holds the konflux-release-data remotes set on startup
+var (
+	konfluxRemotes   struct{ repo, fork string }
+	konfluxRemotesMu sync.RWMutex
+)
+
+// SetKonfluxRemotes sets the konflux-release-data repository merge requests
+// target and the fork branches are pushed to, empty to push to the repository
+// itself. Unset values fall back to the RELEASE_MCP_KONFLUX_REPOSITORY and
+// RELEASE_MCP_KONFLUX_FORK environment variables. The configuration file
+// overrides both while the server runs.
+func SetKonfluxRemotes(repo, fork string) error {
+	if repo == "" {
+		repo = os.Getenv(konfluxRepositoryEnv)
+	}
+	if fork == "" {
+		fork = os.Getenv(konfluxForkEnv)
+	}
+	for _, project := range []string{repo, fork} {
+		if err := validateGitLabProject(project); err != nil {
+			return err
+		}
+	}
+
+	konfluxRemotesMu.Lock()
+	defer konfluxRemotesMu.Unlock()
+	konfluxRemotes.repo, konfluxRemotes.fork = repo, fork
+	return nil
+}
+
+// validateGitLabProject checks a project is a <namespace>/<name> path, empty being allowed
+func validateGitLabProject(project string) error {
+	if project == "" {
+		return nil
+	}
+	if !strings.Contains(project, "/") || strings.HasPrefix(project, "/") || strings.HasSuffix(project, "/") || strings.Contains(project, "//") {
+		return fmt.Errorf("invalid GitLab project %q, expected <namespace>/<name>", project)
+	}
+	return nil
+}
+
+// konfluxRepository returns the konflux-release-data repository merge requests
+// target and the fork branches are pushed to, empty when pushing to the
+// repository itself
+func konfluxRepository() (repo, fork string) {
+	konfluxRemotesMu.RLock()
+	repo, fork = konfluxRemotes.repo, konfluxRemotes.fork
+	konfluxRemotesMu.RUnlock()
+
+	config := currentConfig()
+	if config.KonfluxRepository != "" {
+		repo = config.KonfluxRepository
+	}
+	if config.KonfluxFork != "" {
+		fork = config.KonfluxFork
+	}
+	if repo == "" {
+		repo = defaultKonfluxRepository
+	}
+	if fork == repo {
+		fork = ""
+	}
+	return repo, fork
+}
+
+// konfluxPushRemote returns the remote of a konflux-release-data clone
+// branches are pushed to: the fork when the clone has one, origin otherwise
+func konfluxPushRemote(ctx context.Context, repoPath string) string {
+	cmd := exec.CommandContext(ctx, "git", "remote", "get-url", konfluxForkRemote)
+	cmd.Dir = repoPath
+	if err := runCommand(ctx, cmd); err != nil {
+		return "origin"
+	}
+	return konfluxForkRemote
+}
+
+// konfluxSourceBranch returns the source branch of a merge request opened
+// from a branch pushed by konfluxPushRemote, qualified with the fork project
+// when branches are pushed to one
+func konfluxSourceBranch(ctx context.Context, repoPath, branch string) string {
+	_, fork := konfluxRepository()
+	if fork == "" || konfluxPushRemote(ctx, repoPath) != konfluxForkRemote {
+		return branch
+	}
+	return fork + ":" + branch
+}
@@ -0,0 +1,205 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// KustomizationReconcileConfig represents the configuration for reconciling a kustomization.yaml
+type KustomizationReconcileConfig struct {
+	RepoPath string
+	Dir      string // Relative to the konflux-release-data root
+	DryRun   bool
+}
+
+// KustomizationDrift represents the differences between kustomization.yaml and the directory
+type KustomizationDrift struct {
+	Missing []string `json:"missing,omitempty"` // Files present in the directory but not listed
+	Stale   []string `json:"stale,omitempty"`   // Listed resources whose files no longer exist
+}
+
+func reconcileKustomization(ctx context.Context, config KustomizationReconcileConfig) (KustomizationDrift, error) {
+	var drift KustomizationDrift
+
+	if err := cloneKonfluxRepo(ctx, RPAConfig{RepoPath: config.RepoPath}); err != nil {
+		return drift, fmt.Errorf("failed to clone konflux-release-data repository: %w", err)
+	}
+
+	dir := filepath.Join(config.RepoPath, config.Dir)
+	kustomizationPath := filepath.Join(dir, "kustomization.yaml")
+	content, err := os.ReadFile(kustomizationPath)
+	if err != nil {
+		return drift, fmt.Errorf("failed to read kustomization.yaml: %w", err)
+	}
+
+	drift, err = kustomizationDrift(dir, content)
+	if err != nil {
+		return drift, err
+	}
+	if config.DryRun || (len(drift.Missing) == 0 && len(drift.Stale) == 0) {
+		return drift, nil
+	}
+
+	branchName := "reconcile-kustomization-" + strings.ReplaceAll(filepath.Base(config.Dir), "_", "-")
+	if err := createBranchInRepo(ctx, config.RepoPath, branchName); err != nil {
+		return drift, fmt.Errorf("failed to create branch: %w", err)
+	}
+
+	if err := writeFile(ctx, kustomizationPath, []byte(applyKustomizationDrift(string(content), drift)), 0644); err != nil {
+		return drift, fmt.Errorf("failed to write kustomization.yaml: %w", err)
+	}
+
+	if err := runBuildManifests(ctx, RPAConfig{RepoPath: config.RepoPath}); err != nil {
+		return drift, fmt.Errorf("failed to run build-manifests.sh: %w", err)
+	}
+
+	commitMsg := fmt.Sprintf("Reconcile %s kustomization with directory contents", filepath.Base(config.Dir))
+	if err := commitAndPushKonflux(ctx, config.RepoPath, branchName, commitMsg); err != nil {
+		return drift, err
+	}
+	return drift, nil
+}
+
+// kustomizationDrift compares the resources of a kustomization with the files of its directory
+func kustomizationDrift(dir string, content []byte) (KustomizationDrift, error) {
+	var drift KustomizationDrift
+
+	var kustomization struct {
+		Resources []string `yaml:"resources"`
+	}
+	if err := yaml.Unmarshal(content, &kustomization); err != nil {
+		return drift, fmt.Errorf("failed to parse kustomization.yaml: %w", err)
+	}
+
+	listed := make(map[string]bool)
+	for _, resource := range kustomization.Resources {
+		listed[resource] = true
+		// Only reconcile plain files of this directory; leave remote and nested resources alone
+		if strings.Contains(resource, "/") || strings.Contains(resource, "://") {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dir, resource)); os.IsNotExist(err) {
+			drift.Stale = append(drift.Stale, resource)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return drift, fmt.Errorf("failed to read directory %s: %w", dir, err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || name == "kustomization.yaml" || !(strings.HasSuffix(name, ".yaml") || strings.HasSuffix(name, ".yml")) {
+			continue
+		}
+		if !listed[name] {
+			drift.Missing = append(drift.Missing, name)
+		}
+	}
+
+	sort.Strings(drift.Missing)
+	sort.Strings(drift.Stale)
+	return drift, nil
+}
+
+// applyKustomizationDrift removes stale entries from the resources list and
+// appends missing ones, keeping the rest of the file as is
+func applyKustomizationDrift(content string, drift KustomizationDrift) string {
+	stale := make(map[string]bool)
+	for _, resource := range drift.Stale {
+		stale[resource] = true
+	}
+
+	lines := strings.Split(content, "\n")
+	var updated []string
+	inResources := false
+	indent := "  "
+	insertAt := -1
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "resources:" {
+			inResources = true
+			updated = append(updated, line)
+			insertAt = len(updated)
+			continue
+		}
+
+		if inResources {
+			if strings.HasPrefix(trimmed, "- ") {
+				indent = line[:len(line)-len(strings.TrimLeft(line, " "))]
+				resource := strings.Trim(strings.TrimSpace(strings.TrimPrefix(trimmed, "- ")), `"'`)
+				if stale[resource] {
+					continue
+				}
+				updated = append(updated, line)
+				insertAt = len(updated)
+				continue
+			}
+			if trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+				inResources = false
+			}
+		}
+		updated = append(updated, line)
+	}
+
+	var missing []string
+	for _, resource := range drift.Missing {
+		missing = append(missing, indent+"- "+resource)
+	}
+	if insertAt == -1 {
+		updated = append(updated, "resources:")
+		updated = append(updated, missing...)
+	} else {
+		updated = append(updated[:insertAt], append(missing, updated[insertAt:]...)...)
+	}
+	return strings.Join(updated, "\n")
+}
+
+// commitAndPushKonflux commits all changes of a konflux-release-data clone and
+// pushes them to branchName
+func commitAndPushKonflux(ctx context.Context, repoPath, branchName, commitMsg string) error {
+	for _, args := range [][]string{{"add", "."}, {"commit", "-m", commitMsg}} {
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Dir = repoPath
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		if err := runCommand(ctx, cmd); err != nil {
+			return fmt.Errorf("git %s failed: %v\nError details: %s", args[0], err, stderr.String())
+		}
+	}
+
+	pushCmd, err := gitCommand(ctx, gitlabHost, "push", "-u", konfluxPushRemote(ctx, repoPath), branchName)
+	if err != nil {
+		return err
+	}
+	pushCmd.Dir = repoPath
+	var pushStderr bytes.Buffer
+	pushCmd.Stderr = &pushStderr
+	if err := runCommand(ctx, pushCmd); err != nil {
+		return fmt.Errorf("failed to push changes: %v\nError details: %s", err, pushStderr.String())
+	}
+	return nil
+}
+
+func formatKustomizationDrift(drift KustomizationDrift) string {
+	if len(drift.Missing) == 0 && len(drift.Stale) == 0 {
+		return "kustomization.yaml matches the directory contents"
+	}
+
+	var sb strings.Builder
+	for _, resource := range drift.Missing {
+		fmt.Fprintf(&sb, "+ %s (file present but not listed)\n", resource)
+	}
+	for _, resource := range drift.Stale {
+		fmt.Fprintf(&sb, "- %s (listed but file missing)\n", resource)
+	}
+	return sb.String()
+}
@@ -0,0 +1,158 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	// konfluxCIConfig is the CI configuration of konflux-release-data, which pins the tool versions
+	konfluxCIConfig = ".gitlab-ci.yml"
+
+	// maxParityDiff bounds the diff reported by the parity check
+	maxParityDiff = 20000
+)
+
+// kustomizeVersionPin matches the kustomize version pinned in the CI configuration
+var kustomizeVersionPin = regexp.MustCompile(`KUSTOMIZE_VERSION:\s*["']?v?([0-9]+\.[0-9]+\.[0-9]+)`)
+
+// kustomizeVersionOutput matches the version printed by kustomize version
+var kustomizeVersionOutput = regexp.MustCompile(`v?([0-9]+\.[0-9]+\.[0-9]+)`)
+
+// KustomizeParityConfig represents the configuration for the CI parity check
+type KustomizeParityConfig struct {
+	RepoPath         string
+	Branch           string // Branch of konflux-release-data to check, defaults to the default branch
+	KustomizeVersion string // Overrides the version pinned in the CI configuration
+}
+
+// KustomizeParityResult represents the structured result of verify-kustomize-build-of-tenant-config-in-CI-parity
+type KustomizeParityResult struct {
+	Branch        string   `json:"branch,omitempty"`
+	PinnedVersion string   `json:"pinned_version,omitempty" jsonschema:"kustomize version the CI runs"`
+	LocalVersion  string   `json:"local_version"`
+	VersionMatch  bool     `json:"version_match"`
+	ChangedFiles  []string `json:"changed_files,omitempty" jsonschema:"Files the manifest build changed, which the CI gate rejects"`
+	Diff          string   `json:"diff,omitempty"`
+	Passed        bool     `json:"passed"`
+}
+
+func verifyKustomizeParity(ctx context.Context, config KustomizeParityConfig) (KustomizeParityResult, error) {
+	result := KustomizeParityResult{Branch: config.Branch}
+
+	if err := cloneKonfluxRepo(ctx, RPAConfig{RepoPath: config.RepoPath}); err != nil {
+		return result, fmt.Errorf("failed to clone konflux-release-data repository: %w", err)
+	}
+	if config.Branch != "" {
+		checkoutCmd, err := gitCommand(ctx, gitlabHost, "checkout", config.Branch)
+		if err != nil {
+			return result, err
+		}
+		checkoutCmd.Dir = config.RepoPath
+		var stderr bytes.Buffer
+		checkoutCmd.Stderr = &stderr
+		if err := runCommand(ctx, checkoutCmd); err != nil {
+			return result, fmt.Errorf("failed to check out %s: %v\nError details: %s", config.Branch, err, stderr.String())
+		}
+	}
+
+	result.PinnedVersion = config.KustomizeVersion
+	if result.PinnedVersion == "" {
+		pinned, err := pinnedKustomizeVersion(config.RepoPath)
+		if err != nil {
+			return result, err
+		}
+		result.PinnedVersion = pinned
+	}
+
+	versionCmd := exec.CommandContext(ctx, "kustomize", "version")
+	output, err := commandOutput(ctx, versionCmd)
+	if err != nil {
+		return result, fmt.Errorf("failed to run kustomize version, is kustomize on the PATH: %w", err)
+	}
+	if match := kustomizeVersionOutput.FindStringSubmatch(string(output)); match != nil {
+		result.LocalVersion = match[1]
+	} else {
+		result.LocalVersion = strings.TrimSpace(string(output))
+	}
+	// Without a pin there is nothing to differ from
+	result.VersionMatch = result.PinnedVersion == "" || strings.TrimPrefix(result.PinnedVersion, "v") == result.LocalVersion
+
+	if err := runBuildManifests(ctx, RPAConfig{RepoPath: config.RepoPath}); err != nil {
+		return result, err
+	}
+
+	// The CI gate fails when the committed manifests differ from the build
+	statusCmd := exec.CommandContext(ctx, "git", "status", "--porcelain")
+	statusCmd.Dir = config.RepoPath
+	status, err := commandOutput(ctx, statusCmd)
+	if err != nil {
+		return result, fmt.Errorf("failed to get git status: %w", err)
+	}
+	for _, line := range strings.Split(strings.TrimRight(string(status), "\n"), "\n") {
+		if len(line) > 3 {
+			result.ChangedFiles = append(result.ChangedFiles, line[3:])
+		}
+	}
+	if len(result.ChangedFiles) > 0 {
+		diffCmd := exec.CommandContext(ctx, "git", "diff", "--no-color")
+		diffCmd.Dir = config.RepoPath
+		diff, err := commandOutput(ctx, diffCmd)
+		if err != nil {
+			return result, fmt.Errorf("failed to diff the build output: %w", err)
+		}
+		result.Diff = string(diff)
+		if len(result.Diff) > maxParityDiff {
+			result.Diff = result.Diff[:maxParityDiff] + "\n... diff truncated\n"
+		}
+	}
+
+	result.Passed = result.VersionMatch && len(result.ChangedFiles) == 0
+	return result, nil
+}
+
+// pinnedKustomizeVersion returns the kustomize version pinned by the CI
+// configuration of a konflux-release-data clone, or "" when it pins none
+func pinnedKustomizeVersion(repoPath string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(repoPath, konfluxCIConfig))
+	if os.IsNotExist(err) {
+		return "", nil
+	} else if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", konfluxCIConfig, err)
+	}
+	if match := kustomizeVersionPin.FindSubmatch(content); match != nil {
+		return string(match[1]), nil
+	}
+	return "", nil
+}
+
+func formatKustomizeParity(result KustomizeParityResult) string {
+	var sb strings.Builder
+	switch {
+	case result.PinnedVersion == "":
+		fmt.Fprintf(&sb, "? kustomize %s (no version pinned in %s)\n", result.LocalVersion, konfluxCIConfig)
+	case result.VersionMatch:
+		fmt.Fprintf(&sb, "✓ kustomize %s matches the CI\n", result.LocalVersion)
+	default:
+		fmt.Fprintf(&sb, "✗ kustomize %s, the CI runs %s: outputs may differ\n", result.LocalVersion, result.PinnedVersion)
+	}
+
+	if len(result.ChangedFiles) == 0 {
+		sb.WriteString("✓ build-manifests.sh output matches the committed manifests\n")
+		return sb.String()
+	}
+	fmt.Fprintf(&sb, "✗ build-manifests.sh changed %d files, the CI gate would fail:\n", len(result.ChangedFiles))
+	for _, file := range result.ChangedFiles {
+		fmt.Fprintf(&sb, "- %s\n", file)
+	}
+	if result.Diff != "" {
+		fmt.Fprintf(&sb, "\n%s", result.Diff)
+	}
+	return sb.String()
+}
@@ -0,0 +1,379 @@
+package tools
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// gaWarningDays is how close to GA pending milestones are reported as a risk
+const gaWarningDays = 7
+
+// releaseMilestones are the tool calls marking the progress of a release in
+// its executive summary, in release order. Several tools reach the same milestone.
+var releaseMilestones = []struct {
+	name      string
+	tools     []string
+	minorOnly bool // Patch releases reuse the branches and configuration of their minor
+}{
+	{"Release branches cut", []string{"create-release-branches"}, true},
+	{"Build configuration updated", []string{"configure-hack-repo"}, true},
+	{"Release plans proposed", []string{"create-release-plans", "prepare-patch-release", "orchestrate-z-stream-release"}, false},
+	{"Release plans applied", []string{"apply-release-plans"}, false},
+	{"Release notes proposed", []string{"release-notes-docs-pr"}, false},
+	{"Next version opened", []string{"configure-hack-next"}, true},
+}
+
+// ReleaseMilestone represents a milestone of a release and when it was reached
+type ReleaseMilestone struct {
+	Name    string    `json:"name"`
+	Reached bool      `json:"reached"`
+	Time    time.Time `json:"time,omitempty" jsonschema:"First successful call reaching the milestone"`
+	Tool    string    `json:"tool,omitempty"`
+}
+
+// SummaryLink represents a link of an executive summary
+type SummaryLink struct {
+	Kind  string `json:"kind" jsonschema:"merge request, pull request, advisory, issue, cve or documentation"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// SummaryCVE represents a CVE fixed by a release
+type SummaryCVE struct {
+	Key       string `json:"key"`
+	Component string `json:"component,omitempty"`
+}
+
+// LeadershipSummaryResult represents the structured result of summarize-release-for-leadership
+type LeadershipSummaryResult struct {
+	Version      string             `json:"version"`
+	Phase        string             `json:"phase"`
+	Dates        *ReleaseDates      `json:"dates,omitempty"`
+	Milestones   []ReleaseMilestone `json:"milestones"`
+	AdvisoryType string             `json:"advisory_type"`
+	Components   []string           `json:"components"`
+	OCPVersions  []string           `json:"ocp_versions,omitempty"`
+	Features     []string           `json:"features,omitempty" jsonschema:"Notable features from the latest release notes"`
+	Issues       []string           `json:"issues,omitempty"`
+	CVEs         []SummaryCVE       `json:"cves,omitempty"`
+	Risks        []string           `json:"risks,omitempty"`
+	Links        []SummaryLink      `json:"links,omitempty"`
+	Markdown     string             `json:"markdown" jsonschema:"The executive summary, ready to share"`
+}
+
+// summarizeReleaseForLeadership condenses the release record of a version,
+// its calendar and audit log, into an executive summary. Nothing is
+// fetched: the summary reflects what the server did and recorded.
+func summarizeReleaseForLeadership(minorVersion, patchVersion string, now time.Time) (LeadershipSummaryResult, error) {
+	advisoryType, _ := getReleaseType(minorVersion, patchVersion)
+	// The audit log of a version is kept under the version its calls targeted, see auditVersion
+	version := minorVersion
+	if patchVersion != "" {
+		version += "." + patchVersion
+	}
+	result := LeadershipSummaryResult{Version: version, AdvisoryType: advisoryType}
+	if !isSafeVersionDir(version) {
+		return result, fmt.Errorf("invalid version %q", version)
+	}
+
+	// The calendar only plans minor releases
+	if patchVersion == "" {
+		calendar, err := loadReleaseCalendar()
+		if err != nil {
+			return result, err
+		}
+		if calendar != nil {
+			if dates, ok := calendar.Releases[minorVersion]; ok {
+				result.Dates = &dates
+			}
+		}
+	}
+	result.Phase = releasePhase(result.Dates, now)
+
+	events, err := readAuditEvents(version)
+	if err != nil {
+		return result, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	// Dry runs did not change anything
+	var recorded []AuditEvent
+	for _, event := range events {
+		if dryRun, _ := event.Arguments["dry_run"].(bool); !dryRun {
+			recorded = append(recorded, event)
+		}
+	}
+
+	result.Milestones = summaryMilestones(recorded, patchVersion != "")
+	summaryScope(&result, recorded)
+	result.Risks = summaryRisks(result, recorded, now)
+	result.Links = summaryLinks(result, recorded, minorVersion)
+	result.Markdown = formatLeadershipSummary(result)
+	return result, nil
+}
+
+// summaryMilestones returns the milestones of a release with the first
+// successful call reaching each of them
+func summaryMilestones(events []AuditEvent, patch bool) []ReleaseMilestone {
+	var milestones []ReleaseMilestone
+	for _, m := range releaseMilestones {
+		if patch && m.minorOnly {
+			continue
+		}
+		milestone := ReleaseMilestone{Name: m.name}
+		for _, event := range events {
+			if event.Success && slices.Contains(m.tools, event.Tool) {
+				milestone.Reached, milestone.Time, milestone.Tool = true, event.Time, event.Tool
+				break
+			}
+		}
+		milestones = append(milestones, milestone)
+	}
+	return milestones
+}
+
+// summaryScope fills the scope of a release from the arguments of the
+// successful calls proposing its release plans and release notes, the
+// latest call deciding the advisory type, OCP versions and features
+func summaryScope(result *LeadershipSummaryResult, events []AuditEvent) {
+	for name := range productComponents() {
+		result.Components = append(result.Components, name)
+	}
+	sort.Strings(result.Components)
+
+	issues := make(map[string]bool)
+	cves := make(map[string]SummaryCVE)
+	for _, event := range events {
+		if !event.Success || !slices.Contains([]string{"create-release-plans", "prepare-patch-release", "orchestrate-z-stream-release", "release-notes-docs-pr"}, event.Tool) {
+			continue
+		}
+		if advisoryType, _ := event.Arguments["advisory_type"].(string); advisoryType != "" {
+			result.AdvisoryType = advisoryType
+		}
+		if versions := stringsArg(event.Arguments["ocp_versions"]); len(versions) > 0 {
+			result.OCPVersions = versions
+		}
+		if features := stringsArg(event.Arguments["features"]); len(features) > 0 {
+			result.Features = features
+		}
+		for _, issue := range stringsArg(event.Arguments["issues"]) {
+			issues[issue] = true
+		}
+		items, _ := event.Arguments["cves"].([]any)
+		for _, item := range items {
+			c, _ := item.(map[string]any)
+			key, _ := c["key"].(string)
+			component, _ := c["component"].(string)
+			if key != "" {
+				cves[key] = SummaryCVE{Key: key, Component: component}
+			}
+		}
+	}
+	for issue := range issues {
+		result.Issues = append(result.Issues, issue)
+	}
+	sort.Strings(result.Issues)
+	for _, cve := range cves {
+		result.CVEs = append(result.CVEs, cve)
+	}
+	sort.Slice(result.CVEs, func(i, j int) bool { return result.CVEs[i].Key < result.CVEs[j].Key })
+}
+
+// summaryRisks returns the risks of a release: the latest risk assessment
+// and link check, steps whose latest attempt failed, rollbacks and
+// milestones still pending close to GA
+func summaryRisks(result LeadershipSummaryResult, events []AuditEvent, now time.Time) []string {
+	var risks []string
+	latest := make(map[string]AuditEvent)
+	var rollbacks []AuditEvent
+	for _, event := range events {
+		latest[event.Tool] = event
+		if event.Tool == "rollback-release-plans" && event.Success {
+			rollbacks = append(rollbacks, event)
+		}
+	}
+
+	if event, ok := latest["release-risk-report"]; ok && event.Success {
+		lines := strings.SplitN(strings.TrimSpace(event.Summary), "\n", 3)
+		risk := strings.Join(lines[:min(len(lines), 2)], ". ")
+		risks = append(risks, fmt.Sprintf("%s (assessed %s)", strings.TrimSuffix(risk, "."), event.Time.UTC().Format("2006-01-02")))
+	}
+	if event, ok := latest["check-release-notes-links"]; ok && event.Success {
+		lines := strings.Split(strings.TrimSpace(event.Summary), "\n")
+		if last := lines[len(lines)-1]; strings.Contains(last, "broken") {
+			risks = append(risks, "Release notes: "+last)
+		}
+	}
+	for _, m := range releaseMilestones {
+		for _, tool := range m.tools {
+			if event, ok := latest[tool]; ok && !event.Success {
+				summary := strings.TrimSpace(strings.SplitN(event.Summary, "\n", 2)[0])
+				risks = append(risks, fmt.Sprintf("%s: the last attempt (`%s`, %s) failed: %s", m.name, tool, event.Time.UTC().Format("2006-01-02"), summary))
+			}
+		}
+	}
+	for _, event := range rollbacks {
+		risks = append(risks, fmt.Sprintf("Release plans were rolled back on %s", event.Time.UTC().Format("2006-01-02")))
+	}
+
+	if result.Dates != nil && result.Dates.GA != "" {
+		ga, err := time.Parse("2006-01-02", result.Dates.GA)
+		if err == nil && now.AddDate(0, 0, gaWarningDays).After(ga) {
+			var pending []string
+			for _, milestone := range result.Milestones {
+				if !milestone.Reached && milestone.Name != "Next version opened" {
+					pending = append(pending, strings.ToLower(milestone.Name))
+				}
+			}
+			if len(pending) > 0 {
+				risks = append(risks, fmt.Sprintf("GA is planned for %s but not yet done: %s", result.Dates.GA, strings.Join(pending, ", ")))
+			}
+		}
+	}
+	return risks
+}
+
+// summaryLinks returns the change requests and advisories the calls of a
+// release reported, followed by the issues, CVEs and documentation of the release
+func summaryLinks(result LeadershipSummaryResult, events []AuditEvent, minorVersion string) []SummaryLink {
+	var links []SummaryLink
+	seen := make(map[string]bool)
+	add := func(link SummaryLink) {
+		if !seen[link.URL] {
+			seen[link.URL] = true
+			links = append(links, link)
+		}
+	}
+
+	for _, event := range events {
+		if !event.Success {
+			continue
+		}
+		for _, url := range releaseNotesURL.FindAllString(event.Summary, -1) {
+			url = strings.TrimRight(url, ".,:;")
+			switch {
+			case strings.Contains(url, "/merge_requests/"):
+				add(SummaryLink{Kind: "merge request", Title: event.Tool, URL: url})
+			case strings.Contains(url, "/pull/"):
+				add(SummaryLink{Kind: "pull request", Title: event.Tool, URL: url})
+			case strings.Contains(url, "/errata/") || strings.Contains(url, "/advisories/"):
+				add(SummaryLink{Kind: "advisory", Title: event.Tool, URL: url})
+			}
+		}
+	}
+	for _, cve := range result.CVEs {
+		add(SummaryLink{Kind: "cve", Title: cve.Key, URL: "https://access.redhat.com/security/cve/" + cve.Key})
+	}
+	for _, issue := range result.Issues {
+		add(SummaryLink{Kind: "issue", Title: issue, URL: "https://issues.redhat.com/browse/" + issue})
+	}
+	if profile := product(); profile.Documentation != "" {
+		add(SummaryLink{Kind: "documentation", Title: profile.DisplayName + " " + minorVersion, URL: profile.Documentation + "/" + minorVersion})
+	}
+	return links
+}
+
+// advisoryTypeNames describes the advisory types for readers outside the release team
+var advisoryTypeNames = map[string]string{
+	"RHEA": "enhancement advisory",
+	"RHBA": "bug fix advisory",
+	"RHSA": "security advisory",
+}
+
+func formatLeadershipSummary(result LeadershipSummaryResult) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s %s release summary\n\n", product().DisplayName, result.Version)
+
+	reached := 0
+	for _, milestone := range result.Milestones {
+		if milestone.Reached {
+			reached++
+		}
+	}
+	fmt.Fprintf(&sb, "**Status:** %s, %d of %d milestones reached\n", result.Phase, reached, len(result.Milestones))
+
+	if result.Dates != nil {
+		sb.WriteString("\n## Dates\n\n| Milestone | Planned |\n|---|---|\n")
+		for _, date := range []struct{ name, date string }{
+			{"Merge window", result.Dates.MergeWindowStart},
+			{"Code freeze", result.Dates.CodeFreeze},
+			{"Branching", result.Dates.BranchDate},
+			{"GA", result.Dates.GA},
+		} {
+			if date.date != "" {
+				fmt.Fprintf(&sb, "| %s | %s |\n", date.name, date.date)
+			}
+		}
+	}
+
+	sb.WriteString("\n## Progress\n\n")
+	for _, milestone := range result.Milestones {
+		if milestone.Reached {
+			fmt.Fprintf(&sb, "- ✅ %s on %s\n", milestone.Name, milestone.Time.UTC().Format("2006-01-02"))
+		} else {
+			fmt.Fprintf(&sb, "- ⏳ %s\n", milestone.Name)
+		}
+	}
+
+	sb.WriteString("\n## Scope\n\n")
+	fmt.Fprintf(&sb, "- Advisory: %s (%s)\n", result.AdvisoryType, advisoryTypeNames[result.AdvisoryType])
+	fmt.Fprintf(&sb, "- Components: %s\n", strings.Join(result.Components, ", "))
+	if len(result.OCPVersions) > 0 {
+		ocpVersions := make([]string, len(result.OCPVersions))
+		for i, version := range result.OCPVersions {
+			ocpVersions[i] = strings.ReplaceAll(version, "-", ".")
+		}
+		fmt.Fprintf(&sb, "- OpenShift: %s\n", strings.Join(ocpVersions, ", "))
+	}
+	fmt.Fprintf(&sb, "- Fixed issues: %d, CVEs: %d\n", len(result.Issues), len(result.CVEs))
+
+	if len(result.Features) > 0 {
+		sb.WriteString("\n## Highlights\n\n")
+		for _, feature := range result.Features {
+			fmt.Fprintf(&sb, "- %s\n", feature)
+		}
+	}
+
+	if len(result.Issues) > 0 || len(result.CVEs) > 0 {
+		sb.WriteString("\n## Notable Changes\n\n")
+		for _, cve := range result.CVEs {
+			if cve.Component != "" {
+				fmt.Fprintf(&sb, "- Security fix %s in `%s`\n", cve.Key, cve.Component)
+			} else {
+				fmt.Fprintf(&sb, "- Security fix %s\n", cve.Key)
+			}
+		}
+		for _, issue := range result.Issues {
+			fmt.Fprintf(&sb, "- Fixed %s\n", issue)
+		}
+	}
+
+	sb.WriteString("\n## Risks\n\n")
+	if len(result.Risks) == 0 {
+		sb.WriteString("No risks recorded.\n")
+	}
+	for _, risk := range result.Risks {
+		fmt.Fprintf(&sb, "- %s\n", risk)
+	}
+
+	if len(result.Links) > 0 {
+		sb.WriteString("\n## Links\n\n")
+		for _, link := range result.Links {
+			fmt.Fprintf(&sb, "- %s: [%s](%s)\n", link.Kind, link.Title, link.URL)
+		}
+	}
+	return sb.String()
+}
+
+// stringsArg returns the strings of an array argument
+func stringsArg(value any) []string {
+	items, _ := value.([]any)
+	var values []string
+	for _, item := range items {
+		if s, ok := item.(string); ok && s != "" {
+			values = append(values, s)
+		}
+	}
+	return values
+}
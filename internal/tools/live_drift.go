@@ -0,0 +1,330 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/yaml"
+)
+
+// liveOnlyFields are the fields the API server, controllers and ArgoCD set on
+// the live objects, which are not expected in git. The fields below them are
+// ignored too, and entries ending with a slash match every label or
+// annotation with that prefix.
+var liveOnlyFields = []string{
+	"status",
+	"metadata.namespace",
+	"metadata.uid",
+	"metadata.resourceVersion",
+	"metadata.generation",
+	"metadata.creationTimestamp",
+	"metadata.managedFields",
+	"metadata.selfLink",
+	"metadata.finalizers",
+	"metadata.ownerReferences",
+	"metadata.annotations.kubectl.kubernetes.io/last-applied-configuration",
+	"metadata.annotations.argocd.argoproj.io/",
+	"metadata.labels.argocd.argoproj.io/",
+	"metadata.labels.app.kubernetes.io/instance",
+}
+
+// LiveDriftConfig represents the configuration for comparing the live release
+// resources of a ReleasePlan with their merged version in git
+type LiveDriftConfig struct {
+	RepoPath    string
+	ReleasePlan string
+	Namespace   string // Defaults to the tenant namespace
+	AllowDrift  bool   // Let the release proceed despite differences
+}
+
+// LiveFieldDiff represents a field whose value on the cluster differs from git
+type LiveFieldDiff struct {
+	Field string `json:"field"`
+	Git   string `json:"git"`
+	Live  string `json:"live"`
+}
+
+// LiveResourceDiff represents the comparison of a live resource with its git version
+type LiveResourceDiff struct {
+	Kind        string          `json:"kind"`
+	Namespace   string          `json:"namespace"`
+	Name        string          `json:"name"`
+	File        string          `json:"file,omitempty" jsonschema:"File of konflux-release-data, empty when missing"`
+	Status      string          `json:"status" jsonschema:"in sync, drifted, missing on cluster or missing in git"`
+	Differences []LiveFieldDiff `json:"differences,omitempty"`
+}
+
+// LiveDriftResult represents the structured result of diff-live-cluster-rpa-vs-git-before-release
+type LiveDriftResult struct {
+	ReleasePlan string             `json:"release_plan"`
+	Commit      string             `json:"commit" jsonschema:"konflux-release-data commit the cluster was compared with"`
+	InSync      bool               `json:"in_sync"`
+	Proceed     bool               `json:"proceed" jsonschema:"Whether the release may be triggered: the resources are in sync or the differences were allowed"`
+	Resources   []LiveResourceDiff `json:"resources"`
+}
+
+// diffLiveReleaseResources compares, field by field, the ReleasePlan to
+// release through and its ReleasePlanAdmission on the cluster with their
+// version merged into konflux-release-data. Differences mean ArgoCD has not
+// synced the merged change yet or the cluster was patched manually, and the
+// release should not proceed unless they are allowed.
+func diffLiveReleaseResources(ctx context.Context, config LiveDriftConfig) (LiveDriftResult, error) {
+	result := LiveDriftResult{ReleasePlan: config.ReleasePlan}
+	if config.ReleasePlan == "" {
+		return result, fmt.Errorf("release plan is required")
+	}
+	if config.Namespace == "" {
+		config.Namespace = tenantNamespace()
+	}
+
+	if err := cloneKonfluxRepo(ctx, RPAConfig{RepoPath: config.RepoPath}); err != nil {
+		return result, fmt.Errorf("failed to clone konflux-release-data repository: %w", err)
+	}
+	revCmd := exec.CommandContext(ctx, "git", "rev-parse", "HEAD")
+	revCmd.Dir = config.RepoPath
+	commit, err := commandOutput(ctx, revCmd)
+	if err != nil {
+		return result, fmt.Errorf("failed to resolve the konflux-release-data commit: %w", err)
+	}
+	result.Commit = strings.TrimSpace(string(commit))
+
+	dc, err := dynamicClient(ctx)
+	if err != nil {
+		return result, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+
+	gitRP, rpFile, err := findGitManifest(config.RepoPath, tenantReleasePlanDir(), "ReleasePlan", config.ReleasePlan)
+	if err != nil {
+		return result, err
+	}
+	if gitRP != nil && gitRP.GetNamespace() == "" {
+		gitRP.SetNamespace(config.Namespace)
+	}
+	liveRP, err := getLiveResource(ctx, dc, "ReleasePlan", config.Namespace, config.ReleasePlan)
+	if err != nil {
+		return result, err
+	}
+	if gitRP == nil && liveRP == nil {
+		return result, fmt.Errorf("ReleasePlan %s exists neither in %s nor on the cluster", config.ReleasePlan, tenantReleasePlanDir())
+	}
+	result.Resources = append(result.Resources, compareLiveResource("ReleasePlan", config.Namespace, config.ReleasePlan, rpFile, gitRP, liveRP))
+
+	// The ReleasePlanAdmission is named by a label of the ReleasePlan and
+	// lives in the managed namespace the ReleasePlan targets
+	var rpaName, rpaNamespace string
+	for _, rp := range []*unstructured.Unstructured{gitRP, liveRP} {
+		if rp == nil {
+			continue
+		}
+		if rpaName == "" {
+			rpaName = rp.GetLabels()[releasePlanAdmissionLabel]
+		}
+		if rpaNamespace == "" {
+			rpaNamespace, _, _ = unstructured.NestedString(rp.Object, "spec", "target")
+		}
+	}
+	if rpaName == "" {
+		return result, fmt.Errorf("ReleasePlan %s has no %s label naming its ReleasePlanAdmission", config.ReleasePlan, releasePlanAdmissionLabel)
+	}
+
+	gitRPA, rpaFile, err := findGitManifest(config.RepoPath, productRPADir(), "ReleasePlanAdmission", rpaName)
+	if err != nil {
+		return result, err
+	}
+	if gitRPA != nil && gitRPA.GetNamespace() != "" {
+		rpaNamespace = gitRPA.GetNamespace()
+	}
+	if rpaNamespace == "" {
+		return result, fmt.Errorf("cannot tell the namespace of ReleasePlanAdmission %s", rpaName)
+	}
+	liveRPA, err := getLiveResource(ctx, dc, "ReleasePlanAdmission", rpaNamespace, rpaName)
+	if err != nil {
+		return result, err
+	}
+	result.Resources = append(result.Resources, compareLiveResource("ReleasePlanAdmission", rpaNamespace, rpaName, rpaFile, gitRPA, liveRPA))
+
+	result.InSync = true
+	for _, resource := range result.Resources {
+		if resource.Status != "in sync" {
+			result.InSync = false
+		}
+	}
+	result.Proceed = result.InSync || config.AllowDrift
+	if !result.InSync && config.AllowDrift {
+		slog.WarnContext(ctx, "Live release resources differ from git, proceeding as allowed", "release_plan", config.ReleasePlan, "commit", result.Commit)
+	}
+	return result, nil
+}
+
+// findGitManifest returns the manifest of a kind and name in a directory of
+// konflux-release-data, with its path relative to the root, or nil when
+// there is none
+func findGitManifest(repoPath, dir, kind, name string) (*unstructured.Unstructured, string, error) {
+	entries, err := os.ReadDir(filepath.Join(repoPath, dir))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read directory %s: %w", dir, err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || entry.Name() == "kustomization.yaml" || !strings.HasSuffix(entry.Name(), ".yaml") {
+			continue
+		}
+		file := filepath.Join(dir, entry.Name())
+		content, err := os.ReadFile(filepath.Join(repoPath, file))
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		data, err := yaml.YAMLToJSON(content)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(data); err != nil {
+			// Files that are not manifests, such as kustomize patches, are skipped
+			continue
+		}
+		if obj.GetKind() == kind && obj.GetName() == name {
+			return obj, file, nil
+		}
+	}
+	return nil, "", nil
+}
+
+// getLiveResource returns a release resource from the cluster, or nil when it does not exist
+func getLiveResource(ctx context.Context, dc dynamic.Interface, kind, namespace, name string) (*unstructured.Unstructured, error) {
+	obj, err := dc.Resource(releaseResources[kind]).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s %s/%s: %w", kind, namespace, name, err)
+	}
+	return obj, nil
+}
+
+// compareLiveResource compares the git and live versions of a resource, either of which may be nil
+func compareLiveResource(kind, namespace, name, file string, git, live *unstructured.Unstructured) LiveResourceDiff {
+	diff := LiveResourceDiff{Kind: kind, Namespace: namespace, Name: name, File: file}
+	switch {
+	case git == nil:
+		diff.Status = "missing in git"
+	case live == nil:
+		diff.Status = "missing on cluster"
+	default:
+		diff.Differences = liveDifferences(git.Object, live.Object)
+		diff.Status = "in sync"
+		if len(diff.Differences) > 0 {
+			diff.Status = "drifted"
+		}
+	}
+	return diff
+}
+
+// liveDifferences returns the fields differing between the git and live
+// versions of a resource. Only the fields set in git are compared, since the
+// API server fills in defaults and the namespace of manifests relying on
+// kustomize, with one exception: list entries removed from git but still
+// on the cluster are reported.
+func liveDifferences(git, live map[string]any) []LiveFieldDiff {
+	gitFields := make(map[string]any)
+	flattenFields("", git, gitFields)
+	liveFields := make(map[string]any)
+	flattenFields("", live, liveFields)
+	managedBy, _, _ := strings.Cut(ManagedBySelector(), "=")
+
+	paths := make(map[string]bool)
+	for path := range gitFields {
+		paths[path] = true
+	}
+	for path := range liveFields {
+		if !isLiveOnlyField(path, managedBy) && isRemovedListEntry(path, gitFields) {
+			paths[path] = true
+		}
+	}
+
+	var diffs []LiveFieldDiff
+	for path := range paths {
+		gitValue, inGit := gitFields[path]
+		liveValue, inLive := liveFields[path]
+		if inGit && inLive && reflect.DeepEqual(gitValue, liveValue) {
+			continue
+		}
+		diffs = append(diffs, LiveFieldDiff{
+			Field: path,
+			Git:   formatRPAValue(gitValue, inGit),
+			Live:  formatRPAValue(liveValue, inLive),
+		})
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Field < diffs[j].Field })
+	return diffs
+}
+
+// isRemovedListEntry reports whether a live field belongs to an entry of a
+// list that git has, but with fewer entries
+func isRemovedListEntry(path string, gitFields map[string]any) bool {
+	for _, index := range listIndex.FindAllStringIndex(path, -1) {
+		list, entry := path[:index[0]], path[:index[1]]
+		if hasFieldPrefix(gitFields, list+"[") && !hasFieldPrefix(gitFields, entry) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasFieldPrefix reports whether a flattened field path starts with prefix
+func hasFieldPrefix(fields map[string]any, prefix string) bool {
+	for path := range fields {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// isLiveOnlyField reports whether a field path is set on the cluster without being in git
+func isLiveOnlyField(path, managedBy string) bool {
+	if path == "metadata.labels."+managedBy {
+		return true
+	}
+	for _, field := range liveOnlyFields {
+		if path == field || strings.HasPrefix(path, field+".") || strings.HasPrefix(path, field+"[") ||
+			(strings.HasSuffix(field, "/") && strings.HasPrefix(path, field)) {
+			return true
+		}
+	}
+	return false
+}
+
+func formatLiveDrift(result LiveDriftResult) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Release resources of %s compared with konflux-release-data %s:\n", result.ReleasePlan, result.Commit[:min(len(result.Commit), 12)])
+	for _, resource := range result.Resources {
+		mark := "✓"
+		if resource.Status != "in sync" {
+			mark = "✗"
+		}
+		fmt.Fprintf(&sb, "%s %s %s/%s: %s\n", mark, resource.Kind, resource.Namespace, resource.Name, resource.Status)
+		for _, diff := range resource.Differences {
+			fmt.Fprintf(&sb, "  %s: git=%s live=%s\n", diff.Field, diff.Git, diff.Live)
+		}
+	}
+	switch {
+	case result.InSync:
+		sb.WriteString("\nThe cluster matches git, the release can proceed.\n")
+	case result.Proceed:
+		sb.WriteString("\nThe cluster differs from git, proceeding as allowed.\n")
+	default:
+		sb.WriteString("\nRefusing to proceed: the cluster differs from git. Wait for ArgoCD to sync the merged change or revert the manual changes, or set allow_drift to release anyway.\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,221 @@
+package tools
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	mcp "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// adminTokenEnv is the environment variable holding the bearer token of the admin endpoints
+	adminTokenEnv = "RELEASE_MCP_ADMIN_TOKEN"
+
+	// maintenanceFile is the file in the state directory recording an active maintenance
+	maintenanceFile = "maintenance.json"
+
+	// maxMaintenance bounds how long maintenance mode can be enabled for at once
+	maxMaintenance = 24 * time.Hour
+)
+
+// Maintenance represents the maintenance mode of the server
+type Maintenance struct {
+	Active bool      `json:"active"`
+	Until  time.Time `json:"until,omitzero"`
+	Reason string    `json:"reason,omitempty"`
+}
+
+// maintenanceState holds the current maintenance, persisted so a restart
+// during a maintenance window does not end it
+var maintenanceState = struct {
+	sync.Mutex
+	until  time.Time
+	reason string
+	loaded bool
+}{}
+
+// mutatingTools holds the names of the tools changing forge, registry or
+// cluster state, which are refused during maintenance
+var (
+	mutatingTools   = map[string]bool{}
+	mutatingToolsMu sync.Mutex
+)
+
+// markMutating records a tool as changing state outside the server
+func markMutating(name string) {
+	mutatingToolsMu.Lock()
+	mutatingTools[name] = true
+	mutatingToolsMu.Unlock()
+}
+
+func isMutating(name string) bool {
+	mutatingToolsMu.Lock()
+	defer mutatingToolsMu.Unlock()
+	return mutatingTools[name]
+}
+
+func maintenancePath() string {
+	return filepath.Join(stateDir(), maintenanceFile)
+}
+
+// currentMaintenance returns the maintenance in effect at now
+func currentMaintenance(now time.Time) Maintenance {
+	maintenanceState.Lock()
+	defer maintenanceState.Unlock()
+	if !maintenanceState.loaded {
+		maintenanceState.loaded = true
+		var persisted Maintenance
+		if content, err := os.ReadFile(maintenancePath()); err == nil && json.Unmarshal(content, &persisted) == nil {
+			maintenanceState.until, maintenanceState.reason = persisted.Until, persisted.Reason
+		}
+	}
+	if !now.Before(maintenanceState.until) {
+		return Maintenance{}
+	}
+	return Maintenance{Active: true, Until: maintenanceState.until, Reason: maintenanceState.reason}
+}
+
+// setMaintenance enables maintenance mode until the given time, or ends it
+// when until is zero
+func setMaintenance(until time.Time, reason string) (Maintenance, error) {
+	maintenance := Maintenance{Active: !until.IsZero(), Until: until, Reason: reason}
+
+	if err := os.MkdirAll(stateDir(), 0755); err != nil {
+		return maintenance, fmt.Errorf("failed to create state directory: %w", err)
+	}
+	if maintenance.Active {
+		content, err := json.MarshalIndent(maintenance, "", "  ")
+		if err != nil {
+			return maintenance, fmt.Errorf("failed to encode maintenance: %w", err)
+		}
+		if err := os.WriteFile(maintenancePath(), content, 0644); err != nil {
+			return maintenance, fmt.Errorf("failed to write maintenance: %w", err)
+		}
+	} else if err := os.Remove(maintenancePath()); err != nil && !os.IsNotExist(err) {
+		return maintenance, fmt.Errorf("failed to remove maintenance: %w", err)
+	}
+
+	maintenanceState.Lock()
+	maintenanceState.until, maintenanceState.reason, maintenanceState.loaded = until, reason, true
+	maintenanceState.Unlock()
+	return maintenance, nil
+}
+
+// maintenanceMessage explains why a tool call is refused during maintenance
+func maintenanceMessage(tool string, maintenance Maintenance) string {
+	message := fmt.Sprintf("The server is in maintenance mode until %s, %s is refused because it changes release state. Read-only tools keep working.",
+		maintenance.Until.Format(time.RFC3339), tool)
+	if maintenance.Reason != "" {
+		message += "\nReason: " + maintenance.Reason
+	}
+	return message
+}
+
+// maintenanceMiddleware rejects calls of mutating tools while maintenance mode is active
+func maintenanceMiddleware(next mcp.MethodHandler[*mcp.ServerSession]) mcp.MethodHandler[*mcp.ServerSession] {
+	return func(ctx context.Context, session *mcp.ServerSession, method string, params mcp.Params) (mcp.Result, error) {
+		callParams, ok := params.(*mcp.CallToolParamsFor[json.RawMessage])
+		if method != "tools/call" || !ok || !isMutating(callParams.Name) {
+			return next(ctx, session, method, params)
+		}
+
+		maintenance := currentMaintenance(time.Now())
+		if !maintenance.Active {
+			return next(ctx, session, method, params)
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: maintenanceMessage(callParams.Name, maintenance)}},
+			IsError: true,
+		}, nil
+	}
+}
+
+// maintenanceRequest represents the body of a request enabling maintenance mode
+type maintenanceRequest struct {
+	Duration string `json:"duration"`
+	Reason   string `json:"reason"`
+}
+
+// adminAuthorized checks a request to an admin endpoint carries the admin
+// token as a bearer token, writing the error response when it does not
+func adminAuthorized(w http.ResponseWriter, r *http.Request) bool {
+	token := os.Getenv(adminTokenEnv)
+	if token == "" {
+		http.Error(w, fmt.Sprintf("admin endpoints are disabled, set %s to enable them", adminTokenEnv), http.StatusNotFound)
+		return false
+	}
+	provided, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+// AdminOnly serves a handler only to requests carrying the admin token as a
+// bearer token, as the admin endpoints are. It is disabled when no admin
+// token is configured.
+func AdminOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if adminAuthorized(w, r) {
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// MaintenanceHandler serves the admin endpoint of maintenance mode. GET
+// returns the current maintenance, POST enables it for a duration and DELETE
+// ends it. Requests must carry the admin token as a bearer token, and the
+// endpoint is disabled when no admin token is configured.
+func MaintenanceHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !adminAuthorized(w, r) {
+			return
+		}
+
+		var maintenance Maintenance
+		switch r.Method {
+		case http.MethodGet:
+			maintenance = currentMaintenance(time.Now())
+		case http.MethodPost:
+			var request maintenanceRequest
+			if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16)).Decode(&request); err != nil {
+				http.Error(w, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+				return
+			}
+			duration, err := time.ParseDuration(request.Duration)
+			if err != nil || duration <= 0 || duration > maxMaintenance {
+				http.Error(w, fmt.Sprintf("duration must be a positive duration of at most %s (e.g., '2h')", maxMaintenance), http.StatusBadRequest)
+				return
+			}
+			if maintenance, err = setMaintenance(time.Now().Add(duration).Truncate(time.Second), request.Reason); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			slog.InfoContext(r.Context(), "Maintenance mode enabled", "until", maintenance.Until.Format(time.RFC3339), "reason", request.Reason)
+		case http.MethodDelete:
+			var err error
+			if maintenance, err = setMaintenance(time.Time{}, ""); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			slog.InfoContext(r.Context(), "Maintenance mode ended")
+		default:
+			w.Header().Set("Allow", "GET, POST, DELETE")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(maintenance)
+	})
+}
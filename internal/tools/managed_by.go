@@ -0,0 +1,72 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// ManagedByLabelKey is the label key used to mark what is managing a resource
+	ManagedByLabelKey = "app.kubernetes.io/managed-by"
+
+	// defaultManagedByValue identifies this server in the managed-by label
+	defaultManagedByValue = "release-mcp-server"
+
+	// DefaultManagedByLabel is the label identifying the resources this server creates
+	DefaultManagedByLabel = ManagedByLabelKey + "=" + defaultManagedByValue
+)
+
+// managedByKey and managedByValue are the label stamped onto the resources the
+// server creates on-cluster, which the filtered informers select
+var (
+	managedByKey   = ManagedByLabelKey
+	managedByValue = defaultManagedByValue
+	managedByMu    sync.RWMutex
+)
+
+// SetManagedByLabel sets the key=value label stamped onto the resources the
+// server creates on-cluster. It must be called before the informers start.
+func SetManagedByLabel(label string) error {
+	key, value, ok := strings.Cut(label, "=")
+	if !ok {
+		return fmt.Errorf("invalid managed-by label %q, expected key=value", label)
+	}
+	if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+		return fmt.Errorf("invalid managed-by label key %q: %s", key, strings.Join(errs, "; "))
+	}
+	if value == "" {
+		return fmt.Errorf("invalid managed-by label %q: the value must not be empty", label)
+	}
+	if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+		return fmt.Errorf("invalid managed-by label value %q: %s", value, strings.Join(errs, "; "))
+	}
+
+	managedByMu.Lock()
+	defer managedByMu.Unlock()
+	managedByKey, managedByValue = key, value
+	return nil
+}
+
+// ManagedBySelector returns the label selector matching the resources the server creates
+func ManagedBySelector() string {
+	managedByMu.RLock()
+	defer managedByMu.RUnlock()
+	return managedByKey + "=" + managedByValue
+}
+
+// stampManagedBy labels a resource before the server creates it on-cluster,
+// so the filtered informers select it
+func stampManagedBy(obj metav1.Object) {
+	managedByMu.RLock()
+	defer managedByMu.RUnlock()
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[managedByKey] = managedByValue
+	obj.SetLabels(labels)
+}
@@ -0,0 +1,91 @@
+package tools
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MirrorListConfig represents the configuration for generating the oc-mirror image list
+type MirrorListConfig struct {
+	MinorVersion string
+	PatchVersion string
+	OCPVersions  []string // OCP versions in the 4-16 form used by the index applications
+	Components   map[string][]ComponentConfig
+}
+
+// MirrorListResult represents the images disconnected customers need to mirror
+type MirrorListResult struct {
+	ImageSetConfiguration string   `json:"image_set_configuration" jsonschema:"ImageSetConfiguration snippet for oc-mirror"`
+	Catalogs              []string `json:"catalogs,omitempty" jsonschema:"Operator index images, one per OCP version"`
+	Images                []string `json:"images,omitempty" jsonschema:"Component images of the release"`
+}
+
+// ImageSetConfigTemplate represents the template for the oc-mirror ImageSetConfiguration
+const ImageSetConfigTemplate = `kind: ImageSetConfiguration
+apiVersion: mirror.openshift.io/v2alpha1
+mirror:
+  operators:
+{{- range .Catalogs}}
+    - catalog: {{.}}
+      packages:
+{{- range $.Packages}}
+        - name: {{.}}
+          channels:
+            - name: {{$.Channel}}
+              minVersion: {{$.FullVersion}}
+{{- end}}
+{{- end}}
+  additionalImages:
+{{- range .Images}}
+    - name: {{.}}
+{{- end}}
+`
+
+// operatorIndexImage returns the public operator index image for an OCP version
+func operatorIndexImage(ocpVersion string) string {
+	return fmt.Sprintf("%s/redhat/redhat-operator-index:v%s", getRegistryURL("prod"), strings.ReplaceAll(ocpVersion, "-", "."))
+}
+
+func generateMirrorList(config MirrorListConfig) (MirrorListResult, error) {
+	var result MirrorListResult
+	if config.MinorVersion == "" {
+		return result, fmt.Errorf("minor version is required")
+	}
+	if len(config.OCPVersions) == 0 {
+		return result, fmt.Errorf("at least one OCP version is required")
+	}
+
+	_, fullVersion := getReleaseType(config.MinorVersion, config.PatchVersion)
+	registryURL := getRegistryURL("prod")
+	for _, components := range config.Components {
+		for _, component := range components {
+			result.Images = append(result.Images, fmt.Sprintf("%s/%s/%s:v%s", registryURL, product().Prefix, component.Repository, fullVersion))
+		}
+	}
+	sort.Strings(result.Images)
+
+	for _, ocpVersion := range config.OCPVersions {
+		result.Catalogs = append(result.Catalogs, operatorIndexImage(ocpVersion))
+	}
+
+	packages, _ := getFBCConfig("prod")["allowedPackages"].([]string)
+
+	tmpl, err := parseRegisteredTemplate("imageset")
+	if err != nil {
+		return result, fmt.Errorf("failed to parse ImageSetConfiguration template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := executeTemplate(tmpl, &buf, map[string]any{
+		"Catalogs":    result.Catalogs,
+		"Packages":    packages,
+		"Channel":     fmt.Sprintf("pipelines-%s", config.MinorVersion),
+		"FullVersion": fullVersion,
+		"Images":      result.Images,
+	}); err != nil {
+		return result, fmt.Errorf("failed to render ImageSetConfiguration: %w", err)
+	}
+	result.ImageSetConfiguration = buf.String()
+	return result, nil
+}
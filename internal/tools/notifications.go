@@ -0,0 +1,106 @@
+package tools
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// notificationFuncs are the functions of the notification templates
+var notificationFuncs = template.FuncMap{"join": strings.Join}
+
+// localePattern matches locales such as fr, pt-BR or zh-Hant-TW
+var localePattern = regexp.MustCompile(`^[a-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+// normalizeLocale returns a locale in the form of the template file names,
+// accepting POSIX locales such as pt_BR.UTF-8
+func normalizeLocale(locale string) string {
+	locale, _, _ = strings.Cut(locale, ".")
+	return strings.ReplaceAll(locale, "_", "-")
+}
+
+// validateLocale checks the locale of the server configuration
+func validateLocale(locale string) error {
+	if locale != "" && !localePattern.MatchString(normalizeLocale(locale)) {
+		return fmt.Errorf("invalid locale %q, expected a language tag such as fr or pt-BR", locale)
+	}
+	return nil
+}
+
+// localeCandidates returns the locales a localized template is looked up in,
+// from the most specific to the default template: pt-BR, pt, then "".
+func localeCandidates(locale string) []string {
+	locale = normalizeLocale(locale)
+	var candidates []string
+	for locale != "" {
+		candidates = append(candidates, locale)
+		i := strings.LastIndex(locale, "-")
+		if i < 0 {
+			break
+		}
+		locale = locale[:i]
+	}
+	return append(candidates, "")
+}
+
+// localizedFileName returns the name of the locale variant of a template
+// file, e.g. release-plan-mr.fr.md.tmpl for release-plan-mr.md.tmpl
+func localizedFileName(name, locale string) string {
+	if locale == "" {
+		return name
+	}
+	base, ext, _ := strings.Cut(name, ".")
+	return base + "." + locale + "." + ext
+}
+
+// splitLocalizedName splits the name of a template override or template file
+// into the name it is a locale variant of and its locale. Names that are not
+// locale variants of a localized template are returned as is.
+func splitLocalizedName(name string, known func(string) bool) (string, string) {
+	base, rest, ok := strings.Cut(name, ".")
+	if !ok {
+		return name, ""
+	}
+	locale, ext, hasExt := strings.Cut(rest, ".")
+	variantOf := base
+	if hasExt {
+		variantOf = base + "." + ext
+	}
+	if !localePattern.MatchString(locale) || !known(variantOf) {
+		return name, ""
+	}
+	return variantOf, locale
+}
+
+// isLocalizedTemplate reports whether a registered template has locale variants
+func isLocalizedTemplate(name string) bool {
+	return registeredTemplates[name].localized
+}
+
+// isLocalizedTemplateFile reports whether an embedded template file belongs
+// to a localized template
+func isLocalizedTemplateFile(file string) bool {
+	for _, registered := range registeredTemplates {
+		if registered.localized && slices.Contains(registered.files, file) {
+			return true
+		}
+	}
+	return false
+}
+
+// renderNotification renders the message of a notification template, such as
+// the description of a pull or merge request, in the configured locale. A
+// template failing to render is an error, so that a broken override does not
+// open pull requests with a truncated description.
+func renderNotification(name string, data map[string]any) (string, error) {
+	rendered, err := renderTemplate(name, data)
+	if err != nil {
+		return "", err
+	}
+	if len(rendered.Errors) > 0 {
+		return "", fmt.Errorf("failed to render notification template %s: %s", name, rendered.Errors[0].Message)
+	}
+	return strings.TrimSpace(rendered.Rendered), nil
+}
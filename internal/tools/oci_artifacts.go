@@ -0,0 +1,262 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// releaseArtifactType is the artifact type of release records
+	releaseArtifactType = "application/vnd.openshift-pipelines.release.v1"
+
+	// Files of a release record, with the media types they are pushed as
+	versionsArtifactFile = "versions.yaml"
+	plansArtifactFile    = "release-plans.yaml"
+	auditArtifactFile    = "audit.jsonl"
+)
+
+// artifactMediaTypes maps the files of a release record to their media types
+var artifactMediaTypes = map[string]string{
+	versionsArtifactFile: "application/vnd.openshift-pipelines.release.versions.v1+yaml",
+	plansArtifactFile:    "application/vnd.openshift-pipelines.release.plans.v1+yaml",
+	auditArtifactFile:    "application/vnd.openshift-pipelines.release.audit.v1+jsonl",
+}
+
+// ArtifactMirrorConfig represents the configuration for pushing the record of a release to an OCI registry
+type ArtifactMirrorConfig struct {
+	MinorVersion string
+	PatchVersion string
+	Repository   string // OCI repository, defaults to artifact_repository of the configuration file
+	HackPath     string
+	KonfluxPath  string
+	WorkDir      string // Directory the artifact files are written to
+	DryRun       bool
+}
+
+// ArtifactFile represents a file of the release record
+type ArtifactFile struct {
+	Name      string `json:"name"`
+	MediaType string `json:"media_type"`
+	Size      int64  `json:"size"`
+}
+
+// ArtifactMirrorResult represents the structured result of mirror-release-artifacts
+type ArtifactMirrorResult struct {
+	Version   string         `json:"version"`
+	Reference string         `json:"reference" jsonschema:"Repository and tag the release record is pushed to"`
+	DryRun    bool           `json:"dry_run"`
+	Files     []ArtifactFile `json:"files"`
+	Digest    string         `json:"digest,omitempty" jsonschema:"Digest of the pushed artifact manifest"`
+}
+
+// ociDescriptor is the descriptor oras prints for a manifest
+type ociDescriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+}
+
+// artifactRepository returns the OCI repository release records are pushed to
+func artifactRepository() string {
+	return currentConfig().ArtifactRepository
+}
+
+// mirrorReleaseArtifacts pushes the versions manifest pins, the release plans
+// and the audit log of a version as one OCI artifact tagged with the version.
+// Tags are never overwritten, so every record stays as it was first pushed.
+func mirrorReleaseArtifacts(ctx context.Context, config ArtifactMirrorConfig) (ArtifactMirrorResult, error) {
+	_, fullVersion := getReleaseType(config.MinorVersion, config.PatchVersion)
+	if config.Repository == "" {
+		config.Repository = artifactRepository()
+	}
+	result := ArtifactMirrorResult{
+		Version:   fullVersion,
+		Reference: fmt.Sprintf("%s:v%s", config.Repository, fullVersion),
+		DryRun:    config.DryRun,
+	}
+	if config.Repository == "" {
+		return result, fmt.Errorf("no OCI repository to push to, set repository or artifact_repository in the configuration file")
+	}
+
+	if !config.DryRun {
+		if descriptor, err := fetchArtifactDescriptor(ctx, result.Reference); err == nil {
+			return result, fmt.Errorf("%s already exists (%s), release records are never overwritten", result.Reference, descriptor.Digest)
+		}
+	}
+
+	files := map[string][]byte{}
+	var err error
+	if files[versionsArtifactFile], err = versionPins(ctx, config); err != nil {
+		return result, err
+	}
+	if files[plansArtifactFile], err = releasePlansBundle(ctx, config); err != nil {
+		return result, err
+	}
+	if files[auditArtifactFile], err = releaseAuditLog(config.MinorVersion, config.PatchVersion); err != nil {
+		return result, err
+	}
+
+	args := []string{"push", result.Reference, "--artifact-type", releaseArtifactType,
+		"--annotation", "org.opencontainers.image.version=" + fullVersion,
+		"--annotation", "org.opencontainers.image.created=" + time.Now().UTC().Format(time.RFC3339)}
+	for _, name := range []string{versionsArtifactFile, plansArtifactFile, auditArtifactFile} {
+		if err := os.WriteFile(filepath.Join(config.WorkDir, name), files[name], 0644); err != nil {
+			return result, fmt.Errorf("failed to write %s: %w", name, err)
+		}
+		result.Files = append(result.Files, ArtifactFile{Name: name, MediaType: artifactMediaTypes[name], Size: int64(len(files[name]))})
+		args = append(args, name+":"+artifactMediaTypes[name])
+	}
+	if config.DryRun {
+		return result, nil
+	}
+
+	// Files are pushed by relative path, so their titles do not leak the workspace
+	if _, err := runOras(ctx, config.WorkDir, args...); err != nil {
+		return result, err
+	}
+	descriptor, err := fetchArtifactDescriptor(ctx, result.Reference)
+	if err != nil {
+		return result, fmt.Errorf("pushed %s but failed to resolve it: %w", result.Reference, err)
+	}
+	result.Digest = descriptor.Digest
+	return result, nil
+}
+
+// versionPins returns the entry of the version in the versions manifest of its hack branch
+func versionPins(ctx context.Context, config ArtifactMirrorConfig) ([]byte, error) {
+	if err := cloneHackRepo(ctx, HackConfig{MinorVersion: config.MinorVersion, RepoPath: config.HackPath}); err != nil {
+		return nil, fmt.Errorf("failed to clone hack repository: %w", err)
+	}
+	manifest, err := readVersionsManifest(config.HackPath)
+	if err != nil {
+		return nil, err
+	}
+	pins := VersionsManifest{Versions: map[string]map[string]string{config.MinorVersion: manifest.Versions[config.MinorVersion]}}
+	content, err := yaml.Marshal(pins)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode versions manifest: %w", err)
+	}
+	return content, nil
+}
+
+// releasePlansBundle concatenates the ReleasePlanAdmissions and ReleasePlans of
+// the version in konflux-release-data into one multi-document YAML file
+func releasePlansBundle(ctx context.Context, config ArtifactMirrorConfig) ([]byte, error) {
+	if err := cloneKonfluxRepo(ctx, RPAConfig{RepoPath: config.KonfluxPath}); err != nil {
+		return nil, fmt.Errorf("failed to clone konflux-release-data repository: %w", err)
+	}
+
+	pattern := versionPattern(config.MinorVersion)
+	var bundle bytes.Buffer
+	for _, dir := range []string{productRPADir(), tenantReleasePlanDir()} {
+		entries, err := os.ReadDir(filepath.Join(config.KonfluxPath, dir))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read directory %s: %w", dir, err)
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if entry.IsDir() || !strings.HasSuffix(name, ".yaml") || !pattern.MatchString(name) {
+				continue
+			}
+			content, err := os.ReadFile(filepath.Join(config.KonfluxPath, dir, name))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", name, err)
+			}
+			fmt.Fprintf(&bundle, "---\n# Source: %s\n", filepath.Join(dir, name))
+			bundle.Write(bytes.TrimPrefix(content, []byte("---\n")))
+			if !bytes.HasSuffix(content, []byte("\n")) {
+				bundle.WriteString("\n")
+			}
+		}
+	}
+	if bundle.Len() == 0 {
+		return nil, fmt.Errorf("konflux-release-data has no release plans for %s", config.MinorVersion)
+	}
+	return bundle.Bytes(), nil
+}
+
+// releaseAuditLog returns the audit events recorded for the minor version and,
+// for a patch release, for the patch version, oldest first
+func releaseAuditLog(minorVersion, patchVersion string) ([]byte, error) {
+	versions := []string{minorVersion}
+	if patchVersion != "" {
+		versions = append(versions, minorVersion+"."+patchVersion)
+	}
+
+	var events []AuditEvent
+	for _, version := range versions {
+		content, err := os.ReadFile(filepath.Join(stateDir(), version, auditLogFile))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read audit log of %s: %w", version, err)
+		}
+		for _, line := range strings.Split(string(content), "\n") {
+			var event AuditEvent
+			if strings.TrimSpace(line) == "" || json.Unmarshal([]byte(line), &event) != nil {
+				continue
+			}
+			events = append(events, event)
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Time.Before(events[j].Time) })
+
+	var log bytes.Buffer
+	for _, event := range events {
+		line, err := json.Marshal(event)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode audit event: %w", err)
+		}
+		log.Write(append(line, '\n'))
+	}
+	return log.Bytes(), nil
+}
+
+// fetchArtifactDescriptor resolves the manifest descriptor of a reference
+func fetchArtifactDescriptor(ctx context.Context, reference string) (ociDescriptor, error) {
+	var descriptor ociDescriptor
+	output, err := runOras(ctx, "", "manifest", "fetch", "--descriptor", reference)
+	if err != nil {
+		return descriptor, err
+	}
+	if err := json.Unmarshal([]byte(output), &descriptor); err != nil {
+		return descriptor, fmt.Errorf("failed to parse descriptor of %s: %w", reference, err)
+	}
+	return descriptor, nil
+}
+
+func runOras(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "oras", args...)
+	cmd.Dir = dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := runCommand(ctx, cmd); err != nil {
+		return "", fmt.Errorf("oras %s failed: %v\nError details: %s", args[0], err, stderr.String())
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+func formatArtifactMirror(result ArtifactMirrorResult) string {
+	var sb strings.Builder
+	if result.DryRun {
+		fmt.Fprintf(&sb, "Dry run: would push the release record of %s to %s\n", result.Version, result.Reference)
+	} else {
+		fmt.Fprintf(&sb, "Pushed the release record of %s to %s@%s\n", result.Version, result.Reference, result.Digest)
+	}
+	for _, file := range result.Files {
+		fmt.Fprintf(&sb, "- %s (%s, %d bytes)\n", file.Name, file.MediaType, file.Size)
+	}
+	return sb.String()
+}
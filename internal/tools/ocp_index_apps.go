@@ -0,0 +1,195 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// applicationGVR identifies Konflux Applications
+var applicationGVR = schema.GroupVersionResource{
+	Group:    "appstudio.redhat.com",
+	Version:  "v1alpha1",
+	Resource: "applications",
+}
+
+// OCPIndexAppsConfig represents the configuration for listing OCP index applications
+type OCPIndexAppsConfig struct {
+	MinorVersion string
+	OCPVersions  []string // Versions to report in addition to the ones found in hack and konflux-release-data
+	HackPath     string
+	KonfluxPath  string
+}
+
+// OCPIndexApplication represents where the index application of an OCP version exists
+type OCPIndexApplication struct {
+	OCPVersion      string   `json:"ocp_version"`
+	Application     string   `json:"application"`
+	InHack          bool     `json:"in_hack" jsonschema:"Whether the hack repository configures the index application"`
+	RPAEnvironments []string `json:"rpa_environments,omitempty" jsonschema:"Environments whose FBC RPA lists the application"`
+	OnCluster       bool     `json:"on_cluster"`
+	ClusterError    string   `json:"cluster_error,omitempty"`
+	Consistent      bool     `json:"consistent" jsonschema:"Whether the application exists in hack, in the stage and prod FBC RPAs and on the cluster"`
+}
+
+// OCPIndexAppsResult represents the structured result of list-ocp-index-applications
+type OCPIndexAppsResult struct {
+	MinorVersion string                `json:"minor_version"`
+	Consistent   bool                  `json:"consistent"`
+	Applications []OCPIndexApplication `json:"applications"`
+}
+
+// indexApplicationName returns the Konflux application building the index of an OCP version
+func indexApplicationName(ocpVersion, minorVersion string) string {
+	return productName("index", ocpVersion, minorVersion)
+}
+
+func listOCPIndexApplications(ctx context.Context, config OCPIndexAppsConfig) (OCPIndexAppsResult, error) {
+	result := OCPIndexAppsResult{MinorVersion: config.MinorVersion, Consistent: true}
+
+	if err := cloneHackRepo(ctx, HackConfig{MinorVersion: config.MinorVersion, RepoPath: config.HackPath}); err != nil {
+		return result, fmt.Errorf("failed to clone hack repository: %w", err)
+	}
+	hackVersions, err := hackIndexVersions(config.HackPath)
+	if err != nil {
+		return result, err
+	}
+
+	if err := cloneKonfluxRepo(ctx, RPAConfig{RepoPath: config.KonfluxPath}); err != nil {
+		return result, fmt.Errorf("failed to clone konflux-release-data repository: %w", err)
+	}
+	rpaApps := make(map[string][]string)
+	for _, env := range []string{"stage", "prod"} {
+		rpaPath := filepath.Join(config.KonfluxPath, productRPADir(), productName(config.MinorVersion, "fbc", env)+".yaml")
+		apps, err := rpaApplications(rpaPath)
+		if err != nil {
+			return result, err
+		}
+		for _, app := range apps {
+			rpaApps[app] = append(rpaApps[app], env)
+		}
+	}
+
+	// Report the union of the requested versions and the ones found anywhere
+	versions := make(map[string]bool)
+	for _, ocp := range config.OCPVersions {
+		versions[strings.ReplaceAll(ocp, ".", "-")] = true
+	}
+	for ocp := range hackVersions {
+		versions[ocp] = true
+	}
+	prefix, suffix := product().Prefix+"-index-", "-"+config.MinorVersion
+	for app := range rpaApps {
+		if strings.HasPrefix(app, prefix) && strings.HasSuffix(app, suffix) {
+			versions[strings.TrimSuffix(strings.TrimPrefix(app, prefix), suffix)] = true
+		}
+	}
+
+	dc, dcErr := dynamicClient(ctx)
+	for ocp := range versions {
+		app := OCPIndexApplication{
+			OCPVersion:      ocp,
+			Application:     indexApplicationName(ocp, config.MinorVersion),
+			InHack:          hackVersions[ocp],
+			RPAEnvironments: rpaApps[indexApplicationName(ocp, config.MinorVersion)],
+		}
+		switch {
+		case dcErr != nil:
+			app.ClusterError = fmt.Sprintf("failed to create dynamic client: %v", dcErr)
+		default:
+			_, err := dc.Resource(applicationGVR).Namespace(tenantNamespace()).Get(ctx, app.Application, metav1.GetOptions{})
+			switch {
+			case err == nil:
+				app.OnCluster = true
+			case !apierrors.IsNotFound(err):
+				app.ClusterError = err.Error()
+			}
+		}
+		app.Consistent = app.InHack && len(app.RPAEnvironments) == 2 && app.OnCluster
+		if !app.Consistent {
+			result.Consistent = false
+		}
+		result.Applications = append(result.Applications, app)
+	}
+
+	sort.Slice(result.Applications, func(i, j int) bool {
+		return ocpVersionLess(result.Applications[i].OCPVersion, result.Applications[j].OCPVersion)
+	})
+	return result, nil
+}
+
+// hackIndexVersions returns the OCP versions with an index configuration in
+// the hack repository, normalized to the dashed form used in application names
+func hackIndexVersions(hackPath string) (map[string]bool, error) {
+	konfluxDir := filepath.Join(hackPath, "config", "konflux")
+	entries, err := os.ReadDir(konfluxDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read konflux directory: %w", err)
+	}
+
+	prefix := productName("index") + "-"
+	versions := make(map[string]bool)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ".yaml") {
+			continue
+		}
+		ocp := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ".yaml")
+		versions[strings.ReplaceAll(ocp, ".", "-")] = true
+	}
+	return versions, nil
+}
+
+// rpaApplications returns spec.applications of an RPA file, or nothing when the file is missing
+func rpaApplications(path string) ([]string, error) {
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var rpa struct {
+		Spec struct {
+			Applications []string `yaml:"applications"`
+		} `yaml:"spec"`
+	}
+	if err := yaml.Unmarshal(content, &rpa); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return rpa.Spec.Applications, nil
+}
+
+func formatOCPIndexApplications(result OCPIndexAppsResult) string {
+	mark := func(ok bool) string {
+		if ok {
+			return "✓"
+		}
+		return "✗"
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "OCP index applications for %s:\n", result.MinorVersion)
+	fmt.Fprintf(&sb, "%-8s %-6s %-6s %-6s %s\n", "OCP", "hack", "stage", "prod", "cluster")
+	for _, app := range result.Applications {
+		envs := make(map[string]bool)
+		for _, env := range app.RPAEnvironments {
+			envs[env] = true
+		}
+		cluster := mark(app.OnCluster)
+		if app.ClusterError != "" {
+			cluster = "? " + app.ClusterError
+		}
+		fmt.Fprintf(&sb, "%-8s %-6s %-6s %-6s %s\n", app.OCPVersion, mark(app.InHack), mark(envs["stage"]), mark(envs["prod"]), cluster)
+	}
+	return sb.String()
+}
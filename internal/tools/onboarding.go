@@ -0,0 +1,395 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// KonfluxApplicationTemplate represents the template for the Konflux Application of a new application
+const KonfluxApplicationTemplate = `apiVersion: appstudio.redhat.com/v1alpha1
+kind: Application
+metadata:
+  name: {{.Application}}
+  namespace: {{.Tenant}}
+spec:
+  displayName: {{.Application}}
+`
+
+// KonfluxComponentTemplate represents the template for the Konflux Component building an image
+const KonfluxComponentTemplate = `apiVersion: appstudio.redhat.com/v1alpha1
+kind: Component
+metadata:
+  name: {{.Component}}
+  namespace: {{.Tenant}}
+spec:
+  application: {{.Application}}
+  componentName: {{.Component}}
+  source:
+    git:
+      url: {{.GitURL}}
+      revision: {{.Branch}}
+`
+
+// konfluxScaffoldData represents the values rendered into the Application and Component templates
+type konfluxScaffoldData struct {
+	Application string
+	Component   string
+	Tenant      string
+	GitURL      string
+	Branch      string
+}
+
+// OnboardingConfig represents the descriptor of a component joining the release
+type OnboardingConfig struct {
+	MinorVersion    string
+	Repository      string // Repository name in the hack configuration (e.g., tektoncd-foo)
+	Upstream        string // Upstream repository (e.g., tektoncd/foo), if the repository is a downstream fork
+	UpstreamVersion string // Upstream branch the release branch follows
+	Application     string // Konflux application the components are released with (e.g., core)
+	Components      []ComponentConfig
+	Environments    []string
+	HackBranch      string // Hack branch to onboard into, defaults to release-v<minor>.x
+	HackPath        string
+	KonfluxPath     string
+}
+
+// OnboardingResult represents the structured result of hack-repo-new-component-onboarding
+type OnboardingResult struct {
+	HackFile      string   `json:"hack_file"`
+	Scaffold      []string `json:"scaffold,omitempty" jsonschema:"Konflux Application and Component manifests added to hack"`
+	HackPRURL     string   `json:"hack_pr_url,omitempty"`
+	KonfluxBranch string   `json:"konflux_branch,omitempty"`
+	KonfluxMRURL  string   `json:"konflux_mr_url,omitempty"`
+	UpdatedRPAs   []string `json:"updated_rpas,omitempty" jsonschema:"Existing ReleasePlanAdmissions the components were added to"`
+	CreatedRPAs   []string `json:"created_rpas,omitempty" jsonschema:"ReleasePlanAdmissions created for a new application"`
+	NextSteps     []string `json:"next_steps,omitempty" jsonschema:"Manual follow-ups the tool could not perform"`
+}
+
+func onboardComponent(ctx context.Context, config OnboardingConfig) (OnboardingResult, error) {
+	result := OnboardingResult{HackFile: filepath.Join("config", "konflux", "repos", config.Repository+".yaml")}
+	if config.MinorVersion == "" || config.Repository == "" || config.Application == "" {
+		return result, fmt.Errorf("minor version, repository and application are required")
+	}
+	if len(config.Components) == 0 {
+		return result, fmt.Errorf("at least one component is required")
+	}
+	if len(config.Environments) == 0 {
+		config.Environments = []string{"stage", "prod"}
+	}
+
+	prURL, err := onboardHackRepo(ctx, config, &result)
+	if err != nil {
+		return result, err
+	}
+	result.HackPRURL = prURL
+
+	if err := onboardKonfluxRepo(ctx, config, &result); err != nil {
+		return result, err
+	}
+
+	known := false
+	for _, repo := range releaseRepositories() {
+		if repo.Name == config.Repository || strings.TrimSuffix(filepath.Base(repo.RepoURL), ".git") == config.Repository {
+			known = true
+			break
+		}
+	}
+	if !known {
+		result.NextSteps = append(result.NextSteps, fmt.Sprintf("Add %s to the repositories of %s so create-release-branches cuts its release branches", config.Repository, configFileEnv))
+	}
+	if _, ok := productComponents()[config.Application]; !ok {
+		result.NextSteps = append(result.NextSteps, fmt.Sprintf("Application %s is not generated by create-release-plans; re-run this tool for the next minor version or use create-generic-release-plans", config.Application))
+	}
+	return result, nil
+}
+
+// onboardHackRepo adds the repository configuration of the component and its
+// Konflux Application and Component manifests to hack and opens a pull
+// request for them
+func onboardHackRepo(ctx context.Context, config OnboardingConfig, result *OnboardingResult) (string, error) {
+	hackFile := result.HackFile
+	hackConfig := HackConfig{
+		MinorVersion: config.MinorVersion,
+		RepoPath:     config.HackPath,
+		BaseBranch:   config.HackBranch,
+		PRTitle:      fmt.Sprintf("Onboard %s for release v%s", config.Repository, config.MinorVersion),
+	}
+	if err := cloneHackRepo(ctx, hackConfig); err != nil {
+		return "", fmt.Errorf("failed to clone hack repository: %w", err)
+	}
+
+	filePath := filepath.Join(config.HackPath, hackFile)
+	if _, err := os.Stat(filePath); err == nil {
+		return "", fmt.Errorf("%s already exists in hack, %s is already onboarded", hackFile, config.Repository)
+	}
+
+	if err := createPRBranch(ctx, hackConfig); err != nil {
+		return "", fmt.Errorf("failed to create PR branch: %w", err)
+	}
+	if err := writeFile(ctx, filePath, []byte(hackRepoYAML(config)), 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", hackFile, err)
+	}
+	scaffold, err := writeKonfluxScaffold(ctx, config)
+	if err != nil {
+		return "", err
+	}
+	result.Scaffold = scaffold
+
+	hackConfig.PRBody = fmt.Sprintf(`Onboard %s for release v%s
+
+Changes:
+- Added %s with components: %s
+`, config.Repository, config.MinorVersion, hackFile, strings.Join(onboardingComponentNames(config), ", "))
+	for _, file := range scaffold {
+		hackConfig.PRBody += fmt.Sprintf("- Added %s\n", file)
+	}
+
+	pr, err := createAndPushPR(ctx, hackConfig)
+	if err != nil {
+		return "", fmt.Errorf("failed to create and push PR: %w", err)
+	}
+	return pr.URL, nil
+}
+
+// hackRepoYAML renders the hack repository configuration of a new component
+func hackRepoYAML(config OnboardingConfig) string {
+	lines := []string{"---", "name: " + yamlScalar(config.Repository)}
+	if config.Upstream != "" {
+		lines = append(lines, "upstream: "+yamlScalar(config.Upstream))
+	}
+	lines = append(lines, "components:")
+	for _, name := range onboardingComponentNames(config) {
+		lines = append(lines, "  - name: "+yamlScalar(name))
+	}
+	lines = append(lines, "branches:")
+	lines = append(lines, formatBranchYAML(BranchConfig{
+		Name:     fmt.Sprintf("release-v%s.x", config.MinorVersion),
+		Upstream: config.UpstreamVersion,
+		Versions: []string{config.MinorVersion},
+	}, "  ", "")...)
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// writeKonfluxScaffold writes the Konflux Component of every component, and
+// the Application when it is new, to .konflux/<application> in hack, skipping
+// existing manifests, and returns the files written
+func writeKonfluxScaffold(ctx context.Context, config OnboardingConfig) ([]string, error) {
+	application := productName(config.Application, config.MinorVersion)
+	dir := filepath.Join(".konflux", application)
+	data := konfluxScaffoldData{
+		Application: application,
+		Tenant:      tenantNamespace(),
+		GitURL:      fmt.Sprintf("https://%s/%s/%s", githubHost, product().Organization, config.Repository),
+		Branch:      fmt.Sprintf("release-v%s.x", config.MinorVersion),
+	}
+
+	type manifest struct {
+		template string
+		file     string
+		data     konfluxScaffoldData
+	}
+	var manifests []manifest
+	if _, ok := productComponents()[config.Application]; !ok {
+		manifests = append(manifests, manifest{"konflux-application", filepath.Join(dir, "application.yaml"), data})
+	}
+	for _, component := range config.Components {
+		componentData := data
+		componentData.Component = fmt.Sprintf("%s-%s-%s-%s", product().ComponentPrefix, config.Application, config.MinorVersion, component.Name)
+		manifests = append(manifests, manifest{"konflux-component", filepath.Join(dir, componentData.Component+".yaml"), componentData})
+	}
+
+	var written []string
+	for _, m := range manifests {
+		filePath := filepath.Join(config.HackPath, m.file)
+		if _, err := os.Stat(filePath); err == nil {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create directory %s: %w", filepath.Dir(m.file), err)
+		}
+		tmpl, err := parseRegisteredTemplate(m.template)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s template: %w", m.template, err)
+		}
+		if err := renderToFile(ctx, tmpl, filePath, m.data); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", m.file, err)
+		}
+		written = append(written, m.file)
+	}
+	return written, nil
+}
+
+// onboardKonfluxRepo adds the components to the ReleasePlanAdmissions of their
+// application, creating the release plans of a new application, and opens a
+// merge request
+func onboardKonfluxRepo(ctx context.Context, config OnboardingConfig, result *OnboardingResult) error {
+	rpaConfig := RPAConfig{
+		MinorVersion: config.MinorVersion,
+		RepoPath:     config.KonfluxPath,
+		Environments: config.Environments,
+	}
+	if err := cloneKonfluxRepo(ctx, rpaConfig); err != nil {
+		return fmt.Errorf("failed to clone konflux-release-data repository: %w", err)
+	}
+
+	result.KonfluxBranch = fmt.Sprintf("onboard-%s-%s", config.Repository, config.MinorVersion)
+	if err := createBranchInRepo(ctx, config.KonfluxPath, result.KonfluxBranch); err != nil {
+		return fmt.Errorf("failed to create branch: %w", err)
+	}
+
+	var missingEnvs []string
+	for _, env := range sortedEnvironments(config.Environments) {
+		fileName := productName(config.Application, config.MinorVersion, env) + ".yaml"
+		filePath := filepath.Join(config.KonfluxPath, productRPADir(), fileName)
+		content, err := os.ReadFile(filePath)
+		if os.IsNotExist(err) {
+			missingEnvs = append(missingEnvs, env)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", fileName, err)
+		}
+
+		updated, err := addRPAComponents(string(content), config, getEnvSpecificValues(env, false).RegistryURL)
+		if err != nil {
+			return fmt.Errorf("failed to update %s: %w", fileName, err)
+		}
+		if updated == string(content) {
+			continue
+		}
+		if err := writeFile(ctx, filePath, []byte(updated), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", fileName, err)
+		}
+		result.UpdatedRPAs = append(result.UpdatedRPAs, fileName)
+	}
+
+	// A new application gets its own release plans
+	if len(missingEnvs) > 0 {
+		rpaConfig.Components = map[string][]ComponentConfig{config.Application: config.Components}
+		rpaConfig.Environments = missingEnvs
+		if err := createRPAs(ctx, rpaConfig); err != nil {
+			return fmt.Errorf("failed to create RPAs: %w", err)
+		}
+		if err := createRPs(ctx, rpaConfig); err != nil {
+			return fmt.Errorf("failed to create RPs: %w", err)
+		}
+		if err := updateKustomization(ctx, rpaConfig); err != nil {
+			return fmt.Errorf("failed to update kustomization: %w", err)
+		}
+		for _, env := range missingEnvs {
+			result.CreatedRPAs = append(result.CreatedRPAs, productName(config.Application, config.MinorVersion, env)+".yaml")
+		}
+	}
+
+	changed, err := hasWorkingTreeChanges(ctx, config.KonfluxPath)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		result.KonfluxBranch = ""
+		return nil
+	}
+
+	if err := runBuildManifests(ctx, rpaConfig); err != nil {
+		return fmt.Errorf("failed to run build-manifests.sh: %w", err)
+	}
+	title := fmt.Sprintf("Onboard %s components for v%s", config.Repository, config.MinorVersion)
+	if err := commitAndPushKonflux(ctx, config.KonfluxPath, result.KonfluxBranch, title); err != nil {
+		return err
+	}
+
+	repo, _ := konfluxRepository()
+	provider, err := vcsProvider(gitlabHost)
+	var body string
+	if err == nil {
+		body, err = renderNotification("onboarding-mr", map[string]any{
+			"Repository":   config.Repository,
+			"Application":  config.Application,
+			"MinorVersion": config.MinorVersion,
+		})
+	}
+	if err == nil {
+		var mr ChangeRequestInfo
+		mr, err = provider.OpenChangeRequest(ctx, repo, ChangeRequest{
+			Title:        title,
+			Body:         body,
+			SourceBranch: konfluxSourceBranch(ctx, config.KonfluxPath, result.KonfluxBranch),
+			TargetBranch: "main",
+		})
+		result.KonfluxMRURL = mr.URL
+	}
+	if err != nil {
+		result.NextSteps = append(result.NextSteps, fmt.Sprintf("Open a merge request for branch %s of %s: %v", result.KonfluxBranch, repo, err))
+	}
+	return nil
+}
+
+// addRPAComponents appends mapping entries for the components missing from an
+// RPA, before its mapping defaults, keeping the rest of the file as is
+func addRPAComponents(content string, config OnboardingConfig, registryURL string) (string, error) {
+	componentsStart := strings.Index(content, "\n      components:\n")
+	if componentsStart == -1 {
+		return "", fmt.Errorf("no mapping components found")
+	}
+	insertAt := strings.Index(content[componentsStart:], "\n      defaults:")
+	if insertAt == -1 {
+		return "", fmt.Errorf("no mapping defaults found")
+	}
+	insertAt += componentsStart
+
+	var entries []string
+	for _, component := range config.Components {
+		name := fmt.Sprintf("%s-%s-%s-%s", product().ComponentPrefix, config.Application, config.MinorVersion, component.Name)
+		if strings.Contains(content, "- name: "+name+"\n") {
+			continue
+		}
+		entries = append(entries,
+			"        - name: "+name,
+			fmt.Sprintf("          repository: \"%s/%s/%s\"", registryURL, product().Prefix, component.Repository),
+			"          pushSourceContainer: true")
+	}
+	if len(entries) == 0 {
+		return content, nil
+	}
+	return content[:insertAt] + "\n" + strings.Join(entries, "\n") + content[insertAt:], nil
+}
+
+func onboardingComponentNames(config OnboardingConfig) []string {
+	var names []string
+	for _, component := range config.Components {
+		names = append(names, component.Name)
+	}
+	return names
+}
+
+func formatOnboarding(result OnboardingResult) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Added %s to hack: %s\n", result.HackFile, result.HackPRURL)
+	if len(result.Scaffold) > 0 {
+		fmt.Fprintf(&sb, "Added Konflux manifests to hack: %s\n", strings.Join(result.Scaffold, ", "))
+	}
+	if len(result.UpdatedRPAs) > 0 {
+		fmt.Fprintf(&sb, "Added components to: %s\n", strings.Join(result.UpdatedRPAs, ", "))
+	}
+	if len(result.CreatedRPAs) > 0 {
+		fmt.Fprintf(&sb, "Created release plans: %s\n", strings.Join(result.CreatedRPAs, ", "))
+	}
+	switch {
+	case result.KonfluxMRURL != "":
+		fmt.Fprintf(&sb, "konflux-release-data merge request: %s\n", result.KonfluxMRURL)
+	case result.KonfluxBranch != "":
+		fmt.Fprintf(&sb, "Pushed konflux-release-data branch %s\n", result.KonfluxBranch)
+	default:
+		sb.WriteString("The release plans already map every component\n")
+	}
+	if len(result.NextSteps) > 0 {
+		sb.WriteString("\nNext steps:\n")
+		for _, step := range result.NextSteps {
+			fmt.Fprintf(&sb, "- %s\n", step)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,359 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// releasePlanAdmissionLabel is the ReleasePlan label naming the RPA it releases through
+const releasePlanAdmissionLabel = "release.appstudio.openshift.io/releasePlanAdmission"
+
+// OrphanConfig represents the configuration for finding orphaned tenant resources
+type OrphanConfig struct {
+	RepoPath string
+	RPDir    string // Relative to the konflux-release-data root
+	RPADir   string // Relative to the konflux-release-data root
+	Cleanup  bool   // Push a branch removing the orphans and open a merge request
+}
+
+// OrphanedResource represents a file of konflux-release-data nothing refers to, or referring to nothing
+type OrphanedResource struct {
+	File   string `json:"file" jsonschema:"Path relative to the konflux-release-data root"`
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// StaleKustomizationEntry represents a kustomization resource whose file does not exist
+type StaleKustomizationEntry struct {
+	Kustomization string `json:"kustomization" jsonschema:"Path relative to the konflux-release-data root"`
+	Resource      string `json:"resource"`
+}
+
+// OrphanResult represents the structured result of find-orphaned-tenant-resources
+type OrphanResult struct {
+	Orphans         []OrphanedResource        `json:"orphans,omitempty"`
+	StaleEntries    []StaleKustomizationEntry `json:"stale_entries,omitempty"`
+	MergeRequestURL string                    `json:"merge_request_url,omitempty"`
+	Errors          []string                  `json:"errors,omitempty" jsonschema:"Checks that could not be made, whose orphans are not reported"`
+}
+
+// tenantManifest represents the fields of ReleasePlans and RPAs the orphan check reads
+type tenantManifest struct {
+	file string
+	Kind string `yaml:"kind"`
+	Meta struct {
+		Name   string            `yaml:"name"`
+		Labels map[string]string `yaml:"labels"`
+	} `yaml:"metadata"`
+	Spec struct {
+		Origin       string   `yaml:"origin"`
+		Application  string   `yaml:"application"`
+		Applications []string `yaml:"applications"`
+	} `yaml:"spec"`
+}
+
+// releasesThrough returns whether the ReleasePlan rp releases through rpa:
+// through the RPA its label names, or, without the label, through an RPA of
+// its application as the release service matches them
+func (rp tenantManifest) releasesThrough(rpa tenantManifest) bool {
+	if target := rp.Meta.Labels[releasePlanAdmissionLabel]; target != "" {
+		return target == rpa.Meta.Name
+	}
+	for _, application := range rpa.Spec.Applications {
+		if application == rp.Spec.Application {
+			return true
+		}
+	}
+	return false
+}
+
+// findOrphanedResources reports the ReleasePlans of the tenant whose RPA no
+// longer exists, the RPAs of the tenant no ReleasePlan releases through, and
+// the kustomization entries of both directories pointing at missing files.
+// ReleasePlans of the tenant on the cluster and those its kustomization pulls
+// from other directories count as references too, and RPAs are not reported
+// when the cluster cannot be checked. With Cleanup, it pushes a branch
+// removing them and opens a merge request.
+func findOrphanedResources(ctx context.Context, config OrphanConfig) (OrphanResult, error) {
+	var result OrphanResult
+
+	if err := cloneKonfluxRepo(ctx, RPAConfig{RepoPath: config.RepoPath}); err != nil {
+		return result, fmt.Errorf("failed to clone konflux-release-data repository: %w", err)
+	}
+
+	rps, err := readTenantManifests(config.RepoPath, config.RPDir, "ReleasePlan")
+	if err != nil {
+		return result, err
+	}
+	rpas, err := readTenantManifests(config.RepoPath, config.RPADir, "ReleasePlanAdmission")
+	if err != nil {
+		return result, err
+	}
+
+	tenant := filepath.Base(config.RPDir)
+	rpaNames := make(map[string]bool)
+	for _, rpa := range rpas {
+		rpaNames[rpa.Meta.Name] = true
+	}
+	for _, rp := range rps {
+		target := rp.Meta.Labels[releasePlanAdmissionLabel]
+		if target != "" && !rpaNames[target] {
+			result.Orphans = append(result.Orphans, OrphanedResource{
+				File:   rp.file,
+				Kind:   rp.Kind,
+				Name:   rp.Meta.Name,
+				Reason: fmt.Sprintf("ReleasePlanAdmission %s does not exist", target),
+			})
+		}
+	}
+
+	// Every ReleasePlan that may release through the RPAs of the tenant
+	releasePlans := rps
+	external, err := kustomizationReleasePlans(config.RepoPath, config.RPDir)
+	if err != nil {
+		return result, err
+	}
+	releasePlans = append(releasePlans, external...)
+	live, clusterErr := clusterReleasePlans(ctx, tenant)
+	if clusterErr != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("ReleasePlans on the cluster: %v, so no ReleasePlanAdmission is reported", clusterErr))
+	}
+	releasePlans = append(releasePlans, live...)
+	for _, rpa := range rpas {
+		if clusterErr != nil || rpa.Spec.Origin != tenant {
+			continue
+		}
+		referenced := false
+		for _, rp := range releasePlans {
+			if rp.releasesThrough(rpa) {
+				referenced = true
+				break
+			}
+		}
+		if !referenced {
+			result.Orphans = append(result.Orphans, OrphanedResource{
+				File:   rpa.file,
+				Kind:   rpa.Kind,
+				Name:   rpa.Meta.Name,
+				Reason: fmt.Sprintf("no ReleasePlan of %s releases through it, in konflux-release-data or on the cluster", tenant),
+			})
+		}
+	}
+
+	for _, dir := range []string{config.RPDir, config.RPADir} {
+		kustomizationPath := filepath.Join(config.RepoPath, dir, "kustomization.yaml")
+		content, err := os.ReadFile(kustomizationPath)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return result, fmt.Errorf("failed to read %s: %w", kustomizationPath, err)
+		}
+		drift, err := kustomizationDrift(filepath.Join(config.RepoPath, dir), content)
+		if err != nil {
+			return result, err
+		}
+		for _, resource := range drift.Stale {
+			result.StaleEntries = append(result.StaleEntries, StaleKustomizationEntry{Kustomization: filepath.Join(dir, "kustomization.yaml"), Resource: resource})
+		}
+	}
+
+	sort.Slice(result.Orphans, func(i, j int) bool { return result.Orphans[i].File < result.Orphans[j].File })
+	if !config.Cleanup || (len(result.Orphans) == 0 && len(result.StaleEntries) == 0) {
+		return result, nil
+	}
+
+	url, err := removeOrphanedResources(ctx, config, result)
+	if err != nil {
+		return result, err
+	}
+	result.MergeRequestURL = url
+	return result, nil
+}
+
+// readTenantManifests decodes the manifests of a kind in a directory of konflux-release-data
+func readTenantManifests(repoPath, dir, kind string) ([]tenantManifest, error) {
+	entries, err := os.ReadDir(filepath.Join(repoPath, dir))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory %s: %w", dir, err)
+	}
+
+	var manifests []tenantManifest
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || name == "kustomization.yaml" || !strings.HasSuffix(name, ".yaml") {
+			continue
+		}
+		file := filepath.Join(dir, name)
+		content, err := os.ReadFile(filepath.Join(repoPath, file))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		manifest := tenantManifest{file: file}
+		if err := yaml.Unmarshal(content, &manifest); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+		if manifest.Kind == kind {
+			manifests = append(manifests, manifest)
+		}
+	}
+	return manifests, nil
+}
+
+// kustomizationReleasePlans decodes the ReleasePlans the kustomization of a
+// directory lists from other directories of konflux-release-data
+func kustomizationReleasePlans(repoPath, dir string) ([]tenantManifest, error) {
+	content, err := os.ReadFile(filepath.Join(repoPath, dir, "kustomization.yaml"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filepath.Join(dir, "kustomization.yaml"), err)
+	}
+	var kustomization struct {
+		Resources []string `yaml:"resources"`
+	}
+	if err := yaml.Unmarshal(content, &kustomization); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath.Join(dir, "kustomization.yaml"), err)
+	}
+
+	var manifests []tenantManifest
+	for _, resource := range kustomization.Resources {
+		file := filepath.Join(dir, resource)
+		if !strings.Contains(resource, "/") || strings.Contains(resource, "://") || !strings.HasSuffix(resource, ".yaml") || !filepath.IsLocal(file) {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(repoPath, file))
+		if err != nil {
+			// Missing files are reported as stale entries
+			continue
+		}
+		manifest := tenantManifest{file: file}
+		if err := yaml.Unmarshal(content, &manifest); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+		if manifest.Kind == "ReleasePlan" {
+			manifests = append(manifests, manifest)
+		}
+	}
+	return manifests, nil
+}
+
+// clusterReleasePlans returns the ReleasePlans of the tenant namespace on the cluster
+func clusterReleasePlans(ctx context.Context, namespace string) ([]tenantManifest, error) {
+	dc, err := dynamicClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	list, err := dc.Resource(releaseResources["ReleasePlan"]).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ReleasePlans in %s: %w", namespace, err)
+	}
+	manifests := make([]tenantManifest, 0, len(list.Items))
+	for _, item := range list.Items {
+		manifest := tenantManifest{Kind: item.GetKind()}
+		manifest.Meta.Name = item.GetName()
+		manifest.Meta.Labels = item.GetLabels()
+		manifest.Spec.Application, _, _ = unstructured.NestedString(item.Object, "spec", "application")
+		manifests = append(manifests, manifest)
+	}
+	return manifests, nil
+}
+
+// removeOrphanedResources deletes the orphaned files and their kustomization
+// entries along with the stale ones, pushes the change and opens a merge request
+func removeOrphanedResources(ctx context.Context, config OrphanConfig, result OrphanResult) (string, error) {
+	branchName := "remove-orphaned-" + filepath.Base(config.RPDir)
+	if err := createBranchInRepo(ctx, config.RepoPath, branchName); err != nil {
+		return "", fmt.Errorf("failed to create branch: %w", err)
+	}
+
+	// Entries to drop from the kustomization of each directory
+	removed := make(map[string][]string)
+	for _, orphan := range result.Orphans {
+		if err := os.Remove(filepath.Join(config.RepoPath, orphan.File)); err != nil {
+			return "", fmt.Errorf("failed to remove %s: %w", orphan.File, err)
+		}
+		dir := filepath.Dir(orphan.File)
+		removed[dir] = append(removed[dir], filepath.Base(orphan.File))
+	}
+	for _, entry := range result.StaleEntries {
+		dir := filepath.Dir(entry.Kustomization)
+		removed[dir] = append(removed[dir], entry.Resource)
+	}
+	for dir, resources := range removed {
+		kustomizationPath := filepath.Join(config.RepoPath, dir, "kustomization.yaml")
+		content, err := os.ReadFile(kustomizationPath)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", kustomizationPath, err)
+		}
+		updated := applyKustomizationDrift(string(content), KustomizationDrift{Stale: resources})
+		if err := writeFile(ctx, kustomizationPath, []byte(updated), 0644); err != nil {
+			return "", fmt.Errorf("failed to write %s: %w", kustomizationPath, err)
+		}
+	}
+
+	if err := runBuildManifests(ctx, RPAConfig{RepoPath: config.RepoPath}); err != nil {
+		return "", fmt.Errorf("failed to run build-manifests.sh: %w", err)
+	}
+
+	title := fmt.Sprintf("Remove orphaned %s release resources", filepath.Base(config.RPDir))
+	if err := commitAndPushKonflux(ctx, config.RepoPath, branchName, title); err != nil {
+		return "", err
+	}
+
+	provider, err := vcsProvider(gitlabHost)
+	if err != nil {
+		return "", fmt.Errorf("branch %s was pushed, open the merge request manually: %w", branchName, err)
+	}
+	body, err := renderNotification("orphaned-resources-mr", map[string]any{"Orphans": formatOrphans(result)})
+	if err != nil {
+		return "", fmt.Errorf("branch %s was pushed, open the merge request manually: %w", branchName, err)
+	}
+	repo, _ := konfluxRepository()
+	mr, err := provider.OpenChangeRequest(ctx, repo, ChangeRequest{
+		Title:        title,
+		Body:         body,
+		SourceBranch: konfluxSourceBranch(ctx, config.RepoPath, branchName),
+		TargetBranch: "main",
+	})
+	if err != nil {
+		return "", fmt.Errorf("branch %s was pushed, open the merge request manually: %w", branchName, err)
+	}
+	return mr.URL, nil
+}
+
+func formatOrphans(result OrphanResult) string {
+	if len(result.Orphans) == 0 && len(result.StaleEntries) == 0 {
+		return "No orphaned ReleasePlans, ReleasePlanAdmissions or kustomization entries\n"
+	}
+
+	var sb strings.Builder
+	for _, orphan := range result.Orphans {
+		fmt.Fprintf(&sb, "- %s %s (%s): %s\n", orphan.Kind, orphan.Name, orphan.File, orphan.Reason)
+	}
+	for _, entry := range result.StaleEntries {
+		fmt.Fprintf(&sb, "- %s lists %s, which does not exist\n", entry.Kustomization, entry.Resource)
+	}
+	if result.MergeRequestURL != "" {
+		fmt.Fprintf(&sb, "\nCleanup merge request: %s\n", result.MergeRequestURL)
+	}
+	if len(result.Errors) > 0 {
+		sb.WriteString("\nCould not check:\n")
+		for _, err := range result.Errors {
+			fmt.Fprintf(&sb, "- %s\n", err)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,98 @@
+package tools
+
+import (
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
+)
+
+// BranchResult represents the structured result of create-release-branches
+type BranchResult struct {
+	BranchName   string             `json:"branch_name" jsonschema:"Release branch created in every repository"`
+	Repositories []string           `json:"repositories,omitempty" jsonschema:"Repositories the branch was created in"`
+	FailedRepos  []string           `json:"failed_repos,omitempty" jsonschema:"Repositories the branch could not be created in"`
+	Results      []RepoBranchResult `json:"results,omitempty" jsonschema:"Outcome of every repository, in the order of the repository list"`
+}
+
+// RepoBranchResult represents the outcome of creating the release branch of a repository
+type RepoBranchResult struct {
+	Repository string `json:"repository"`
+	Status     string `json:"status" jsonschema:"created or failed"`
+	Error      string `json:"error,omitempty"`
+	Duration   string `json:"duration" jsonschema:"Time spent cloning and pushing the repository"`
+}
+
+// BranchDeletion represents the release branch of a repository removed by delete-release-branches
+type BranchDeletion struct {
+	Repository string `json:"repository"`
+	Status     string `json:"status" jsonschema:"deleted, missing or failed"`
+	Error      string `json:"error,omitempty"`
+}
+
+// DeleteBranchesResult represents the structured result of delete-release-branches
+type DeleteBranchesResult struct {
+	BranchName string           `json:"branch_name"`
+	Branches   []BranchDeletion `json:"branches"`
+}
+
+// ReleaseBranchInfo represents the release branch of a repository reported by list-release-branches
+type ReleaseBranchInfo struct {
+	Repository   string `json:"repository"`
+	Exists       bool   `json:"exists"`
+	HeadSHA      string `json:"head_sha,omitempty"`
+	SourceBranch string `json:"source_branch" jsonschema:"Branch the release branch was cut from"`
+	AheadBy      int    `json:"ahead_by" jsonschema:"Commits on the release branch that are not on the source branch"`
+	BehindBy     int    `json:"behind_by" jsonschema:"Commits on the source branch that are not on the release branch"`
+	Error        string `json:"error,omitempty"`
+}
+
+// ListBranchesResult represents the structured result of list-release-branches
+type ListBranchesResult struct {
+	BranchName string              `json:"branch_name"`
+	Branches   []ReleaseBranchInfo `json:"branches"`
+}
+
+// PushedBranchResult represents the structured result of tools pushing a
+// konflux-release-data branch for a merge request
+type PushedBranchResult struct {
+	BranchName      string `json:"branch_name" jsonschema:"Branch pushed to konflux-release-data for the merge request"`
+	MergeRequestURL string `json:"merge_request_url,omitempty"`
+}
+
+// RPAReferencesResult represents the structured result of verify-rpa-references
+type RPAReferencesResult struct {
+	AllFound   bool           `json:"all_found" jsonschema:"Whether every referenced object exists"`
+	References []RPAReference `json:"references,omitempty"`
+}
+
+// EOLResult represents the structured result of generate-eol-announcement
+type EOLResult struct {
+	Versions []EOLEntry `json:"versions,omitempty"`
+}
+
+// OwnershipResult represents the structured result of lookup-component-owner
+type OwnershipResult struct {
+	Components map[string]ComponentOwner `json:"components,omitempty" jsonschema:"Ownership information keyed by component name"`
+}
+
+// BlockingIssuesResult represents the structured result of find-release-blocking-issues
+type BlockingIssuesResult struct {
+	Version  string          `json:"version"`
+	Blocking bool            `json:"blocking" jsonschema:"Whether any release-blocking issue is open"`
+	Issues   []BlockingIssue `json:"issues,omitempty"`
+}
+
+// CalendarResult represents the structured result of query-release-calendar
+type CalendarResult struct {
+	Version string       `json:"version"`
+	Dates   ReleaseDates `json:"dates"`
+}
+
+// outputSchema infers the JSON Schema advertised for a tool's structured result
+func outputSchema[T any]() *jsonschema.Schema {
+	schema, err := jsonschema.For[T]()
+	if err != nil {
+		panic(fmt.Sprintf("failed to infer output schema: %v", err))
+	}
+	return withArtifactsSchema(schema)
+}
@@ -0,0 +1,124 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ownershipFileEnv is the environment variable pointing to the ownership manifest
+const ownershipFileEnv = "RELEASE_MCP_OWNERSHIP_FILE"
+
+// ComponentOwner represents the ownership information of a component
+type ComponentOwner struct {
+	Team         string   `yaml:"team" json:"team"`
+	CodeOwners   string   `yaml:"codeowners,omitempty" json:"codeowners,omitempty"`
+	Contacts     []string `yaml:"contacts,omitempty" json:"contacts,omitempty"`
+	Escalation   []string `yaml:"escalation,omitempty" json:"escalation,omitempty"`
+	SlackChannel string   `yaml:"slack,omitempty" json:"slack,omitempty"`
+}
+
+// OwnershipManifest represents the ownership manifest in YAML
+type OwnershipManifest struct {
+	Components map[string]ComponentOwner `yaml:"components"`
+}
+
+// loadOwnershipManifest reads the ownership manifest at path, which must be
+// inside the working directory of the server, or the configured one when empty
+func loadOwnershipManifest(path string) (*OwnershipManifest, error) {
+	if path != "" {
+		if err := checkWorkingDirPath(path); err != nil {
+			return nil, err
+		}
+	}
+	if path == "" {
+		path = os.Getenv(ownershipFileEnv)
+	}
+	if path == "" {
+		return nil, fmt.Errorf("no ownership manifest configured, set %s", ownershipFileEnv)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ownership manifest %s: %w", path, err)
+	}
+
+	var manifest OwnershipManifest
+	if err := yaml.Unmarshal(content, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse ownership manifest %s: %w", path, err)
+	}
+	return &manifest, nil
+}
+
+// checkWorkingDirPath rejects a caller-supplied path resolving, through ".."
+// or symbolic links, outside the working directory of the server
+func checkWorkingDirPath(path string) error {
+	resolved := path
+	wd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(wd); err == nil {
+		wd = resolved
+	}
+	if !filepath.IsAbs(resolved) {
+		resolved = filepath.Join(wd, resolved)
+	}
+	if target, err := filepath.EvalSymlinks(resolved); err == nil {
+		resolved = target
+	}
+	if rel, err := filepath.Rel(wd, resolved); err != nil || !filepath.IsLocal(rel) {
+		return fmt.Errorf("invalid path %q: must be inside the working directory %s", path, wd)
+	}
+	return nil
+}
+
+// lookupOwners returns the owners of the given component, or of all components when empty
+func lookupOwners(manifest *OwnershipManifest, component string) (map[string]ComponentOwner, error) {
+	if component == "" {
+		return manifest.Components, nil
+	}
+
+	if owner, ok := manifest.Components[component]; ok {
+		return map[string]ComponentOwner{component: owner}, nil
+	}
+
+	// Fall back to the hack repository naming (e.g. "chains" for "tektoncd-chains")
+	for name, owner := range manifest.Components {
+		if strings.TrimPrefix(name, "tektoncd-") == strings.TrimPrefix(component, "tektoncd-") {
+			return map[string]ComponentOwner{name: owner}, nil
+		}
+	}
+	return nil, fmt.Errorf("component %s not found in ownership manifest", component)
+}
+
+func formatOwners(owners map[string]ComponentOwner) string {
+	var names []string
+	for name := range owners {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	for _, name := range names {
+		owner := owners[name]
+		fmt.Fprintf(&sb, "%s:\n  team: %s\n", name, owner.Team)
+		if owner.CodeOwners != "" {
+			fmt.Fprintf(&sb, "  code owners: %s\n", owner.CodeOwners)
+		}
+		if len(owner.Contacts) > 0 {
+			fmt.Fprintf(&sb, "  contacts: %s\n", strings.Join(owner.Contacts, ", "))
+		}
+		if len(owner.Escalation) > 0 {
+			fmt.Fprintf(&sb, "  escalation: %s\n", strings.Join(owner.Escalation, ", "))
+		}
+		if owner.SlackChannel != "" {
+			fmt.Fprintf(&sb, "  slack: %s\n", owner.SlackChannel)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,269 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// downstreamPatchesDir holds the patch files of a downstream repository
+var downstreamPatchesDir = filepath.Join(".konflux", "patches")
+
+// PatchRebaseConfig represents the configuration for checking downstream patches against new upstream refs
+type PatchRebaseConfig struct {
+	Hack             HackConfig // Hack branch the repository configurations are read from
+	WorkDir          string     // Scratch workspace the repositories are cloned into
+	Repositories     []string   // Only check these repositories, all with a new upstream ref otherwise
+	DownstreamBranch string     // Downstream branch the patches are taken from, defaults to release-v<minor>.x
+}
+
+// PatchConflict represents the hunks of a patch rejected by a file
+type PatchConflict struct {
+	File  string `json:"file"`
+	Hunks string `json:"hunks" jsonschema:"Rejected hunks in unified diff format"`
+}
+
+// PatchOutcome represents the result of applying a downstream patch onto the new upstream ref
+type PatchOutcome struct {
+	Name      string          `json:"name"`
+	Status    string          `json:"status" jsonschema:"applies, needs rework, or missing when no script or patch file is found"`
+	Output    string          `json:"output,omitempty" jsonschema:"Output of the failed script or git apply"`
+	Conflicts []PatchConflict `json:"conflicts,omitempty"`
+}
+
+// RepoPatchRebase represents the patches of a repository applied onto its new upstream ref
+type RepoPatchRebase struct {
+	Repository  string         `json:"repository"`
+	Upstream    string         `json:"upstream"`
+	UpstreamRef string         `json:"upstream_ref"`
+	Patches     []PatchOutcome `json:"patches,omitempty"`
+	Error       string         `json:"error,omitempty"`
+}
+
+// PatchRebaseResult represents the structured result of downstream-patch-rebase-helper
+type PatchRebaseResult struct {
+	Repositories []RepoPatchRebase `json:"repositories"`
+	NeedsRework  int               `json:"needs_rework" jsonschema:"Number of patches that no longer apply"`
+}
+
+// rebaseDownstreamPatches applies the patches configured in the hack
+// repository for every repository getting a new upstream ref onto that ref,
+// in a scratch checkout of the downstream branch with the upstream cloned
+// under upstream/, the layout the patch scripts run in. Patches apply in
+// order, so a later patch sees the hunks of earlier ones.
+func rebaseDownstreamPatches(ctx context.Context, config PatchRebaseConfig) (PatchRebaseResult, error) {
+	var result PatchRebaseResult
+	if config.DownstreamBranch == "" {
+		config.DownstreamBranch = fmt.Sprintf("release-v%s.x", config.Hack.MinorVersion)
+	}
+
+	if err := cloneHackRepo(ctx, config.Hack); err != nil {
+		return result, fmt.Errorf("failed to clone hack repository: %w", err)
+	}
+	manifest, err := readVersionsManifest(config.Hack.RepoPath)
+	if err != nil {
+		return result, err
+	}
+	pinnedUpstreamVersions(&config.Hack, manifest)
+
+	reposDir := filepath.Join(config.Hack.RepoPath, "config", "konflux", "repos")
+	entries, err := os.ReadDir(reposDir)
+	if err != nil {
+		return result, fmt.Errorf("failed to read repos directory: %w", err)
+	}
+
+	only := make(map[string]bool)
+	for _, name := range config.Repositories {
+		only[name] = true
+	}
+	branchName := fmt.Sprintf("release-v%s.x", config.Hack.MinorVersion)
+
+	total := 0
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".yaml") {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(reposDir, entry.Name()))
+		if err != nil {
+			return result, fmt.Errorf("failed to read file %s: %w", entry.Name(), err)
+		}
+		var repoConfig RepoConfig
+		if err := yaml.Unmarshal(content, &repoConfig); err != nil {
+			return result, fmt.Errorf("failed to parse %s: %w", entry.Name(), err)
+		}
+		if repoConfig.Upstream == "" || (len(only) > 0 && !only[repoConfig.Name]) {
+			continue
+		}
+		componentName, ok := componentForRepo(repoConfig.Name)
+		if !ok {
+			continue
+		}
+		ref, ok := config.Hack.UpstreamConfig[componentName]
+		if !ok {
+			continue
+		}
+
+		// The branch entry being bumped has its own patches, or uses the top-level ones
+		patches := repoConfig.Patches
+		for _, branch := range repoConfig.Branches {
+			if branch.Name == branchName && len(branch.Patches) > 0 {
+				patches = branch.Patches
+			}
+		}
+		if len(patches) == 0 {
+			continue
+		}
+
+		total++
+		reportProgress(ctx, float64(total), 0, fmt.Sprintf("Applying %d patches of %s onto %s %s", len(patches), repoConfig.Name, repoConfig.Upstream, ref))
+		rebase := rebaseRepoPatches(ctx, filepath.Join(config.WorkDir, repoConfig.Name), repoConfig, ref, config.DownstreamBranch, patches)
+		for _, patch := range rebase.Patches {
+			if patch.Status != "applies" {
+				result.NeedsRework++
+			}
+		}
+		result.Repositories = append(result.Repositories, rebase)
+	}
+
+	sort.Slice(result.Repositories, func(i, j int) bool {
+		return result.Repositories[i].Repository < result.Repositories[j].Repository
+	})
+	return result, nil
+}
+
+// rebaseRepoPatches prepares the workspace of a repository and applies its patches one by one
+func rebaseRepoPatches(ctx context.Context, workspace string, repoConfig RepoConfig, ref, downstreamBranch string, patches []Patch) RepoPatchRebase {
+	rebase := RepoPatchRebase{Repository: repoConfig.Name, Upstream: repoConfig.Upstream, UpstreamRef: ref}
+
+	for _, clone := range []struct{ repo, branch, dir string }{
+		{product().Organization + "/" + repoConfig.Name, downstreamBranch, workspace},
+		{repoConfig.Upstream, ref, filepath.Join(workspace, "upstream")},
+	} {
+		cmd, err := gitCommand(ctx, githubHost, "clone", "--depth", "1", "-b", clone.branch, forgeRepoURL(githubHost, clone.repo), clone.dir)
+		if err != nil {
+			rebase.Error = err.Error()
+			return rebase
+		}
+		if err := runCommand(ctx, cmd); err != nil {
+			rebase.Error = fmt.Sprintf("failed to clone %s at %s: %v", clone.repo, clone.branch, err)
+			return rebase
+		}
+		runUsageFrom(ctx).recordClone(clone.dir)
+	}
+
+	for _, patch := range patches {
+		rebase.Patches = append(rebase.Patches, applyDownstreamPatch(ctx, workspace, patch))
+	}
+	return rebase
+}
+
+// applyDownstreamPatch runs the script of a patch in the workspace, or applies
+// the patch file of the same name onto upstream/, keeping the hunks that apply
+// and collecting the rejected ones
+func applyDownstreamPatch(ctx context.Context, workspace string, patch Patch) PatchOutcome {
+	outcome := PatchOutcome{Name: patch.Name, Status: "applies"}
+
+	var cmd *exec.Cmd
+	if patch.Script != "" {
+		cmd = exec.CommandContext(ctx, "bash", "-ec", patch.Script)
+		cmd.Dir = workspace
+	} else {
+		patchFile := ""
+		for _, name := range []string{patch.Name, patch.Name + ".patch"} {
+			candidate := filepath.Join(workspace, downstreamPatchesDir, name)
+			if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+				patchFile = candidate
+				break
+			}
+		}
+		if patchFile == "" {
+			outcome.Status = "missing"
+			outcome.Output = fmt.Sprintf("the patch has no script and %s has no %s patch file", downstreamPatchesDir, patch.Name)
+			return outcome
+		}
+		cmd = exec.CommandContext(ctx, "git", "apply", "--reject", "--whitespace=nowarn", patchFile)
+		cmd.Dir = filepath.Join(workspace, "upstream")
+	}
+
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	err := runCommand(ctx, cmd)
+
+	conflicts, rejectErr := collectRejects(workspace)
+	if rejectErr != nil && err == nil {
+		err = rejectErr
+	}
+	outcome.Conflicts = conflicts
+	if err != nil || len(conflicts) > 0 {
+		outcome.Status = "needs rework"
+		outcome.Output = strings.TrimSpace(output.String())
+	}
+	return outcome
+}
+
+// collectRejects reads and removes the .rej files git apply left in the
+// workspace, so the next patch starts from the hunks that applied
+func collectRejects(workspace string) ([]PatchConflict, error) {
+	var conflicts []PatchConflict
+	err := filepath.WalkDir(workspace, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() && entry.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if entry.IsDir() || !strings.HasSuffix(path, ".rej") {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		rel, _ := filepath.Rel(workspace, strings.TrimSuffix(path, ".rej"))
+		conflicts = append(conflicts, PatchConflict{File: rel, Hunks: string(content)})
+		return os.Remove(path)
+	})
+	return conflicts, err
+}
+
+func formatPatchRebase(result PatchRebaseResult) string {
+	var sb strings.Builder
+	if len(result.Repositories) == 0 {
+		return "No repository with a new upstream ref has downstream patches\n"
+	}
+	for _, repo := range result.Repositories {
+		fmt.Fprintf(&sb, "%s onto %s %s:\n", repo.Repository, repo.Upstream, repo.UpstreamRef)
+		if repo.Error != "" {
+			fmt.Fprintf(&sb, "  ✗ %s\n", repo.Error)
+			continue
+		}
+		for _, patch := range repo.Patches {
+			if patch.Status == "applies" {
+				fmt.Fprintf(&sb, "  ✓ %s\n", patch.Name)
+				continue
+			}
+			fmt.Fprintf(&sb, "  ✗ %s: %s\n", patch.Name, patch.Status)
+			if patch.Output != "" {
+				fmt.Fprintf(&sb, "    %s\n", strings.ReplaceAll(patch.Output, "\n", "\n    "))
+			}
+			for _, conflict := range patch.Conflicts {
+				fmt.Fprintf(&sb, "    Rejected hunks of %s:\n    %s\n", conflict.File, strings.ReplaceAll(strings.TrimRight(conflict.Hunks, "\n"), "\n", "\n    "))
+			}
+		}
+	}
+	if result.NeedsRework == 0 {
+		sb.WriteString("\nAll patches apply\n")
+	} else {
+		fmt.Fprintf(&sb, "\n%d patches need rework\n", result.NeedsRework)
+	}
+	return sb.String()
+}
@@ -0,0 +1,253 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// releaseNotesTypeLine matches the advisory type of release notes
+var releaseNotesTypeLine = regexp.MustCompile(`^(\s*)type: "?(RHEA|RHBA|RHSA)"?\s*$`)
+
+// PatchReleaseConfig represents the configuration for preparing a patch release
+type PatchReleaseConfig struct {
+	MinorVersion string
+	PatchVersion string
+	RepoPath     string
+	AdvisoryType string   // Defaults to RHBA
+	CVEs         []CVE    // Fixed CVEs, required for RHSA
+	Issues       []string // Fixed JIRA issues (e.g., SRVKP-1234)
+	DryRun       bool     // Only report the changes, without pushing them
+}
+
+// PatchedFile represents a release plan updated for a patch release
+type PatchedFile struct {
+	Path            string   `json:"path"`
+	PreviousVersion string   `json:"previous_version,omitempty" jsonschema:"Version the file referenced before, unset for FBC ReleasePlanAdmissions"`
+	Changes         []string `json:"changes"`
+}
+
+// PatchReleaseResult represents the structured result of prepare-patch-release
+type PatchReleaseResult struct {
+	Version         string        `json:"version"`
+	ReleaseType     string        `json:"release_type"`
+	DryRun          bool          `json:"dry_run"`
+	Files           []PatchedFile `json:"files" jsonschema:"Release plans of the minor version that changed"`
+	Unchanged       int           `json:"unchanged" jsonschema:"Release plans of the minor version already up to date"`
+	BranchName      string        `json:"branch_name,omitempty"`
+	MergeRequestURL string        `json:"merge_request_url,omitempty"`
+}
+
+// preparePatchRelease updates the existing ReleasePlanAdmissions and
+// ReleasePlans of a minor version in konflux-release-data for a patch
+// release. Only the advisory type, the version references and the fixes of
+// the release notes change, so edits made to the files since they were
+// generated are kept, and no release branch or new file is created.
+func preparePatchRelease(ctx context.Context, config PatchReleaseConfig) (PatchReleaseResult, error) {
+	if config.PatchVersion == "" || config.PatchVersion == "0" {
+		return PatchReleaseResult{}, fmt.Errorf("%s.%s is not a patch release, use create-release-plans for minor releases", config.MinorVersion, config.PatchVersion)
+	}
+	rpaConfig := RPAConfig{
+		MinorVersion: config.MinorVersion,
+		PatchVersion: config.PatchVersion,
+		RepoPath:     config.RepoPath,
+		Components:   productComponents(),
+		Environments: []string{"stage", "prod"},
+		AdvisoryType: config.AdvisoryType,
+		CVEs:         config.CVEs,
+		Issues:       config.Issues,
+		Update:       true,
+	}
+	releaseType, fullVersion := releaseNotesType(rpaConfig)
+	result := PatchReleaseResult{Version: fullVersion, ReleaseType: releaseType, DryRun: config.DryRun}
+	if !advisoryTypes[releaseType] {
+		return result, fmt.Errorf("invalid advisory type %q, expected RHEA, RHBA or RHSA", releaseType)
+	}
+	if releaseType == "RHSA" && len(config.CVEs) == 0 {
+		return result, fmt.Errorf("RHSA advisories require at least one CVE")
+	}
+	if err := checkProductVersion(fullVersion, false, config.MinorVersion); err != nil {
+		return result, err
+	}
+
+	if err := cloneKonfluxRepo(ctx, rpaConfig); err != nil {
+		return result, fmt.Errorf("failed to clone konflux-release-data repository: %w", err)
+	}
+	reportProgress(ctx, 1, releasePlanSteps, "Cloned konflux-release-data")
+	if !config.DryRun {
+		branchName, err := createPushBranch(ctx, config.RepoPath, releasePlanBranch(fullVersion))
+		if err != nil {
+			return result, err
+		}
+		rpaConfig.Branch = branchName
+		result.BranchName = branchName
+	}
+
+	tmpl, err := parseRegisteredTemplate("rpa")
+	if err != nil {
+		return result, fmt.Errorf("failed to parse RPA template: %w", err)
+	}
+	fixes := tmpl.Lookup("fixes")
+	if fixes == nil {
+		return result, fmt.Errorf("the RPA template does not define the fixes of the release notes")
+	}
+
+	minor := regexp.QuoteMeta(config.MinorVersion)
+	prefix := regexp.QuoteMeta(product().Prefix)
+	planFiles := map[string]*regexp.Regexp{
+		productRPADir():        regexp.MustCompile(`^` + prefix + `-(?:(.+)-` + minor + `|` + minor + `-fbc)-[a-z]+\.yaml$`),
+		tenantReleasePlanDir(): regexp.MustCompile(`^` + prefix + `-(.+)-` + minor + `-[a-z]+-release-as-op\.yaml$`),
+	}
+	for _, dir := range []string{productRPADir(), tenantReleasePlanDir()} {
+		entries, err := os.ReadDir(filepath.Join(config.RepoPath, dir))
+		if err != nil {
+			return result, fmt.Errorf("failed to read directory %s: %w", dir, err)
+		}
+		for _, entry := range entries {
+			match := planFiles[dir].FindStringSubmatch(entry.Name())
+			if entry.IsDir() || match == nil {
+				continue
+			}
+			path := filepath.Join(config.RepoPath, dir, entry.Name())
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return result, fmt.Errorf("failed to read %s: %w", entry.Name(), err)
+			}
+
+			// Index images do not carry the fixes of the components
+			var fixesText bytes.Buffer
+			if component := match[1]; component != "" {
+				data := struct {
+					CVEs   []CVE
+					Issues []string
+				}{componentCVEs(rpaConfig, component), config.Issues}
+				if err := executeTemplate(fixes, &fixesText, data); err != nil {
+					return result, fmt.Errorf("failed to render the fixes of %s: %w", entry.Name(), err)
+				}
+			}
+
+			updated, file := patchReleasePlan(content, fullVersion, releaseType, fixesText.String())
+			if len(file.Changes) == 0 {
+				result.Unchanged++
+				continue
+			}
+			file.Path = filepath.Join(dir, entry.Name())
+			if err := writeFile(ctx, path, updated, 0644); err != nil {
+				return result, fmt.Errorf("failed to write %s: %w", entry.Name(), err)
+			}
+			result.Files = append(result.Files, file)
+		}
+	}
+	if len(result.Files) == 0 && result.Unchanged == 0 {
+		return result, fmt.Errorf("konflux-release-data has no release plans for %s, create them with create-release-plans first", config.MinorVersion)
+	}
+	reportProgress(ctx, 3, releasePlanSteps, fmt.Sprintf("Updated %d release plans", len(result.Files)))
+	if config.DryRun || len(result.Files) == 0 {
+		return result, nil
+	}
+
+	if err := runBuildManifests(ctx, rpaConfig); err != nil {
+		return result, fmt.Errorf("failed to run build-manifests.sh: %w", err)
+	}
+	reportProgress(ctx, 5, releasePlanSteps, "Built manifests")
+
+	mrURL, err := createAndPushMR(ctx, rpaConfig)
+	if err != nil {
+		return result, fmt.Errorf("failed to create and push merge request: %w", err)
+	}
+	reportProgress(ctx, releasePlanSteps, releasePlanSteps, "Opened "+mrURL)
+	result.MergeRequestURL = mrURL
+	return result, nil
+}
+
+// patchReleasePlan sets the advisory type, version references and fixes of
+// a release plan, returning the updated content and the changes made. The
+// previous version is read from the product version of ReleasePlanAdmissions
+// and the synopsis of ReleasePlans.
+func patchReleasePlan(content []byte, fullVersion, releaseType, fixes string) ([]byte, PatchedFile) {
+	var file PatchedFile
+	lines := strings.Split(string(content), "\n")
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if value, ok := strings.CutPrefix(trimmed, "product_version: "); ok && value != "fbc" {
+			file.PreviousVersion = strings.Trim(value, `"`)
+			break
+		}
+		if value, ok := strings.CutPrefix(trimmed, "synopsis: "); ok {
+			if _, version, ok := strings.Cut(strings.Trim(value, `"`), "Release "); ok {
+				file.PreviousVersion = version
+			}
+		}
+	}
+
+	var previousVersion *regexp.Regexp
+	if file.PreviousVersion != "" && file.PreviousVersion != fullVersion {
+		previousVersion = regexp.MustCompile(`(^|[^0-9.])` + regexp.QuoteMeta(file.PreviousVersion) + `([^0-9]|$)`)
+		file.Changes = append(file.Changes, fmt.Sprintf("version %s → %s", file.PreviousVersion, fullVersion))
+	}
+
+	var updated, oldFixes []string
+	fixesIndent := ""
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if fixesIndent != "" && (line == fixesIndent+"cves:" || line == fixesIndent+"issues:") {
+			// Drop the fixes block, its entries are more indented
+			oldFixes = append(oldFixes, line)
+			for i+1 < len(lines) && strings.HasPrefix(lines[i+1], fixesIndent+" ") {
+				i++
+				oldFixes = append(oldFixes, lines[i])
+			}
+			continue
+		}
+		if match := releaseNotesTypeLine.FindStringSubmatch(line); match != nil && fixesIndent == "" {
+			if match[2] != releaseType {
+				file.Changes = append(file.Changes, fmt.Sprintf("type %s → %s", match[2], releaseType))
+			}
+			fixesIndent = match[1]
+			updated = append(updated, fmt.Sprintf(`%stype: "%s"`, match[1], releaseType))
+			if fixes != "" {
+				updated = append(updated, strings.Split(strings.TrimPrefix(fixes, "\n"), "\n")...)
+			}
+			continue
+		}
+		if previousVersion != nil {
+			line = previousVersion.ReplaceAllString(line, "${1}"+fullVersion+"${2}")
+		}
+		updated = append(updated, line)
+	}
+
+	oldText := ""
+	if len(oldFixes) > 0 {
+		oldText = "\n" + strings.Join(oldFixes, "\n")
+	}
+	if fixesIndent != "" && oldText != fixes {
+		file.Changes = append(file.Changes, "fixes updated")
+	}
+	if len(file.Changes) == 0 {
+		return content, file
+	}
+	return []byte(strings.Join(updated, "\n")), file
+}
+
+func formatPatchRelease(result PatchReleaseResult) string {
+	var sb strings.Builder
+	if result.DryRun {
+		fmt.Fprintf(&sb, "Dry run: would update the release plans for %s (%s)\n", result.Version, result.ReleaseType)
+	} else {
+		fmt.Fprintf(&sb, "Updated the release plans for %s (%s)\n", result.Version, result.ReleaseType)
+	}
+	for _, file := range result.Files {
+		fmt.Fprintf(&sb, "- %s: %s\n", file.Path, strings.Join(file.Changes, ", "))
+	}
+	if result.Unchanged > 0 {
+		fmt.Fprintf(&sb, "%d release plans already up to date\n", result.Unchanged)
+	}
+	if result.MergeRequestURL != "" {
+		fmt.Fprintf(&sb, "\nBranch: %s\nMerge request: %s\n", result.BranchName, result.MergeRequestURL)
+	}
+	return sb.String()
+}
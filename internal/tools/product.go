@@ -0,0 +1,204 @@
+package tools
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// defaultProduct is the name of the built-in product profile
+	defaultProduct = "openshift-pipelines"
+
+	// defaultRelengTenant is the managed namespace of the ReleasePlanAdmissions
+	// of the products released by the release engineering team
+	defaultRelengTenant = "rhtap-releng-tenant"
+)
+
+// ProductProfile represents a product line released by the server: how its
+// Konflux applications, release plans and images are named, the tenant they
+// are built in, the policies they are released under and their components.
+type ProductProfile struct {
+	Prefix          string                       `yaml:"prefix" json:"prefix" jsonschema:"Prefix of the application, release plan and index names, and registry namespace of the images"`
+	DisplayName     string                       `yaml:"display_name,omitempty" json:"display_name,omitempty" jsonschema:"Product name of the release notes, defaults to the prefix"`
+	ProductID       int                          `yaml:"product_id,omitempty" json:"product_id,omitempty" jsonschema:"Product ID of the release notes"`
+	Description     string                       `yaml:"description,omitempty" json:"description,omitempty" jsonschema:"Product description of the release notes solution"`
+	Documentation   string                       `yaml:"documentation,omitempty" json:"documentation,omitempty" jsonschema:"Product documentation referenced by the release notes"`
+	ComponentPrefix string                       `yaml:"component_prefix,omitempty" json:"component_prefix,omitempty" jsonschema:"Prefix of the Konflux component names, defaults to the prefix"`
+	Organization    string                       `yaml:"organization,omitempty" json:"organization,omitempty" jsonschema:"GitHub organization of the downstream repositories, defaults to the prefix"`
+	OperatorRepo    string                       `yaml:"operator_repo,omitempty" json:"operator_repo,omitempty" jsonschema:"Repository of the operator, whose tags are the released versions, defaults to <organization>/operator"`
+	JiraProject     string                       `yaml:"jira_project,omitempty" json:"jira_project,omitempty" jsonschema:"JIRA project of the release blockers, none when unset"`
+	FBCPackages     []string                     `yaml:"fbc_packages,omitempty" json:"fbc_packages,omitempty" jsonschema:"Packages the FBC RPAs allow, defaults to <prefix>-operator-rh"`
+	Tenant          string                       `yaml:"tenant" json:"tenant" jsonschema:"Tenant namespace the applications are built in"`
+	RelengTenant    string                       `yaml:"releng_tenant,omitempty" json:"releng_tenant,omitempty" jsonschema:"Managed namespace of the ReleasePlanAdmissions, defaults to rhtap-releng-tenant"`
+	Policies        ProductPolicies              `yaml:"policies,omitempty" json:"policies"`
+	Components      map[string][]ComponentConfig `yaml:"components" json:"components" jsonschema:"Images of each application, fbc for the index images"`
+	Repositories    []Repository                 `yaml:"repositories,omitempty" json:"repositories,omitempty" jsonschema:"Repositories release branches are cut in, the repository list of the server when unset"`
+}
+
+// ProductPolicies represents the Enterprise Contract policies the release
+// plans of a product are admitted under
+type ProductPolicies struct {
+	Stage    string `yaml:"stage,omitempty" json:"stage"`
+	Prod     string `yaml:"prod,omitempty" json:"prod"`
+	FBCStage string `yaml:"fbc_stage,omitempty" json:"fbc_stage"`
+	FBCProd  string `yaml:"fbc_prod,omitempty" json:"fbc_prod"`
+}
+
+// builtinProduct returns the OpenShift Pipelines profile used when the
+// configuration selects no other one
+func builtinProduct() ProductProfile {
+	return withProductDefaults(ProductProfile{
+		Prefix:      "openshift-pipelines",
+		DisplayName: "Red Hat OpenShift Pipelines",
+		ProductID:   604,
+		Description: strings.Join([]string{
+			"Red Hat OpenShift Pipelines is a cloud-native, continuous integration and",
+			"continuous delivery (CI/CD) solution based on Kubernetes resources.",
+			"It uses Tekton building blocks to automate deployments across multiple",
+			"platforms by abstracting away the underlying implementation details.",
+			"Tekton introduces a number of standard custom resource definitions (CRDs)",
+			"for defining CI/CD pipelines that are portable across Kubernetes distributions.",
+		}, "\n"),
+		Documentation:   "https://docs.redhat.com/en/documentation/red_hat_openshift_pipelines",
+		ComponentPrefix: "tektoncd",
+		JiraProject:     "SRVKP",
+		Tenant:          "tekton-ecosystem-tenant",
+		Components:      releaseComponents,
+	})
+}
+
+// withProductDefaults fills the unset fields of a profile that can be
+// derived from the others
+func withProductDefaults(profile ProductProfile) ProductProfile {
+	if profile.DisplayName == "" {
+		profile.DisplayName = profile.Prefix
+	}
+	if profile.ComponentPrefix == "" {
+		profile.ComponentPrefix = profile.Prefix
+	}
+	if profile.Organization == "" {
+		profile.Organization = profile.Prefix
+	}
+	if profile.OperatorRepo == "" {
+		profile.OperatorRepo = profile.Organization + "/operator"
+	}
+	if len(profile.FBCPackages) == 0 {
+		profile.FBCPackages = []string{profile.Prefix + "-operator-rh"}
+	}
+	if profile.RelengTenant == "" {
+		profile.RelengTenant = defaultRelengTenant
+	}
+	group := strings.TrimSuffix(profile.Tenant, "-tenant")
+	if profile.Policies.Stage == "" {
+		profile.Policies.Stage = "registry-standard-stage"
+	}
+	if profile.Policies.Prod == "" {
+		profile.Policies.Prod = "registry-standard"
+	}
+	if profile.Policies.FBCStage == "" {
+		profile.Policies.FBCStage = "fbc-" + group + "-stage"
+	}
+	if profile.Policies.FBCProd == "" {
+		profile.Policies.FBCProd = "fbc-" + group + "-prod"
+	}
+	return profile
+}
+
+// validateProducts checks the profiles of the configuration and that the
+// selected product is one of them or the built-in one
+func validateProducts(selected string, products map[string]ProductProfile) error {
+	for name, profile := range products {
+		if profile.Prefix == "" || profile.Tenant == "" || len(profile.Components) == 0 {
+			return fmt.Errorf("product %q: prefix, tenant and components are required", name)
+		}
+		if err := validateRepositories(profile.Repositories); err != nil {
+			return fmt.Errorf("product %q: %w", name, err)
+		}
+	}
+	if _, ok := products[selected]; selected != "" && selected != defaultProduct && !ok {
+		names := []string{defaultProduct}
+		for name := range products {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("unknown product %q, expected one of %s", selected, strings.Join(names, ", "))
+	}
+	return nil
+}
+
+// currentProduct returns the name and profile of the product selected by the
+// server configuration
+func currentProduct() (string, ProductProfile) {
+	config := currentConfig()
+	name := config.Product
+	if name == "" {
+		name = defaultProduct
+	}
+	if profile, ok := config.Products[name]; ok {
+		return name, withProductDefaults(profile)
+	}
+	return defaultProduct, builtinProduct()
+}
+
+// product returns the profile of the selected product
+func product() ProductProfile {
+	_, profile := currentProduct()
+	return profile
+}
+
+// productComponents returns the applications of the selected product and their images
+func productComponents() map[string][]ComponentConfig {
+	return product().Components
+}
+
+// tenantNamespace returns the namespace the applications of the selected product are built in
+func tenantNamespace() string {
+	return product().Tenant
+}
+
+// relengTenant returns the managed namespace where the ReleasePlanAdmissions
+// of the selected product and their references live
+func relengTenant() string {
+	return product().RelengTenant
+}
+
+// operatorRepository returns the repository holding the operator of the
+// selected product, whose tags are the released versions
+func operatorRepository() string {
+	return product().OperatorRepo
+}
+
+// productJiraProjects returns the JIRA projects release blockers of the
+// selected product are tracked in, none when the profile sets no project
+func productJiraProjects() []string {
+	if project := product().JiraProject; project != "" {
+		return []string{project}
+	}
+	return nil
+}
+
+// tenantReleasePlanDir returns the directory of konflux-release-data holding
+// the ReleasePlans of the tenant of the selected product
+func tenantReleasePlanDir() string {
+	return filepath.Join("tenants-config", "cluster", "kflux-prd-rh02", "tenants", tenantNamespace())
+}
+
+// productRPADir returns the directory of konflux-release-data holding the
+// ReleasePlanAdmissions of the selected product
+func productRPADir() string {
+	return filepath.Join("config", "kflux-prd-rh02.0fk9.p1", "product", "ReleasePlanAdmission", strings.TrimSuffix(tenantNamespace(), "-tenant"))
+}
+
+// productName returns a Konflux name of the selected product, its prefix
+// followed by the parts (e.g., openshift-pipelines-core-1.21)
+func productName(parts ...string) string {
+	return strings.Join(append([]string{product().Prefix}, parts...), "-")
+}
+
+// productImageNamespace returns the registry namespace of the images of the
+// selected product in the stage or prod registry
+func productImageNamespace(env string) string {
+	return getRegistryURL(env) + "/" + product().Prefix
+}
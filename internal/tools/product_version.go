@@ -0,0 +1,196 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fbcProductVersion is the product_version of FBC RPAs, which do not ship a
+// versioned advisory of their own
+const fbcProductVersion = "fbc"
+
+var (
+	// errataVersion matches the MAJOR.MINOR.PATCH versions errata expects
+	errataVersion = regexp.MustCompile(`^\d+\.\d+\.\d+$`)
+	// looseVersion matches versions errata does not accept but that can be corrected
+	looseVersion = regexp.MustCompile(`^v?(\d+\.\d+)(\.\d+)?$`)
+	// rpaFileMinorVersion matches the minor version in RPA file names
+	rpaFileMinorVersion = regexp.MustCompile(`-(\d+\.\d+)-`)
+)
+
+// ProductVersionConfig represents the configuration for auditing the product_version of RPAs
+type ProductVersionConfig struct {
+	RepoPath     string
+	Dir          string // Relative to the konflux-release-data root
+	MinorVersion string // Only audit RPAs of this version when set
+}
+
+// ProductVersionViolation represents an RPA whose product_version breaks the errata conventions
+type ProductVersionViolation struct {
+	File           string `json:"file"`
+	ProductVersion string `json:"product_version"`
+	FBC            bool   `json:"fbc"`
+	Problem        string `json:"problem"`
+	Suggestion     string `json:"suggestion,omitempty" jsonschema:"Corrected product_version, when one can be derived"`
+}
+
+// ProductVersionResult represents the structured result of verify-product-version-field-matches-semver-policy
+type ProductVersionResult struct {
+	Compliant  bool                      `json:"compliant"`
+	Checked    int                       `json:"checked"`
+	Violations []ProductVersionViolation `json:"violations,omitempty"`
+}
+
+// productVersionProblem checks a product_version against the errata
+// conventions: MAJOR.MINOR.PATCH, of the minor version of the RPA when known,
+// and "fbc" only for FBC RPAs. It returns the problem and a suggested
+// correction, or empty strings when the version is compliant.
+func productVersionProblem(version string, isFBC bool, minorVersion string) (string, string) {
+	suggest := func() string {
+		if minorVersion != "" {
+			return minorVersion + ".0"
+		}
+		return ""
+	}
+
+	if version == fbcProductVersion {
+		if isFBC {
+			return "", ""
+		}
+		return fmt.Sprintf("%q is reserved for FBC RPAs", fbcProductVersion), suggest()
+	}
+	if !errataVersion.MatchString(version) {
+		problem := fmt.Sprintf("%q is not a MAJOR.MINOR.PATCH version", version)
+		if isFBC {
+			problem = fmt.Sprintf("%q is neither %q nor a MAJOR.MINOR.PATCH version", version, fbcProductVersion)
+		}
+		if m := looseVersion.FindStringSubmatch(version); m != nil {
+			patch := m[2]
+			if patch == "" {
+				patch = ".0"
+			}
+			return problem, m[1] + patch
+		}
+		return problem, suggest()
+	}
+	if minorVersion != "" && !strings.HasPrefix(version, minorVersion+".") {
+		return fmt.Sprintf("%q does not belong to version %s", version, minorVersion), suggest()
+	}
+	return "", ""
+}
+
+// checkProductVersion validates the product_version rendered into generated RPAs
+func checkProductVersion(version string, isFBC bool, minorVersion string) error {
+	problem, suggestion := productVersionProblem(version, isFBC, minorVersion)
+	if problem == "" {
+		return nil
+	}
+	if suggestion != "" {
+		return fmt.Errorf("invalid product_version: %s, use %q", problem, suggestion)
+	}
+	return fmt.Errorf("invalid product_version: %s", problem)
+}
+
+// auditProductVersions reads the RPAs of the konflux-release-data repository
+// and reports those whose product_version breaks the errata conventions
+func auditProductVersions(ctx context.Context, config ProductVersionConfig) (ProductVersionResult, error) {
+	result := ProductVersionResult{Compliant: true}
+
+	if err := cloneKonfluxRepo(ctx, RPAConfig{RepoPath: config.RepoPath}); err != nil {
+		return result, fmt.Errorf("failed to clone konflux-release-data repository: %w", err)
+	}
+
+	dir := filepath.Join(config.RepoPath, config.Dir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return result, fmt.Errorf("failed to read directory %s: %w", dir, err)
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".yaml") {
+			continue
+		}
+		if config.MinorVersion != "" && !strings.Contains(name, "-"+config.MinorVersion+"-") {
+			continue
+		}
+		version, isFBC, ok, err := rpaProductVersion(filepath.Join(dir, name))
+		if err != nil {
+			return result, err
+		}
+		if !ok {
+			continue
+		}
+		result.Checked++
+
+		var minorVersion string
+		if m := rpaFileMinorVersion.FindStringSubmatch(name); m != nil {
+			minorVersion = m[1]
+		}
+		problem, suggestion := productVersionProblem(version, isFBC, minorVersion)
+		if problem == "" {
+			continue
+		}
+		result.Compliant = false
+		result.Violations = append(result.Violations, ProductVersionViolation{
+			File:           name,
+			ProductVersion: version,
+			FBC:            isFBC,
+			Problem:        problem,
+			Suggestion:     suggestion,
+		})
+	}
+	sort.Slice(result.Violations, func(i, j int) bool { return result.Violations[i].File < result.Violations[j].File })
+	return result, nil
+}
+
+// rpaProductVersion returns the release notes product_version of an RPA and
+// whether it releases an FBC. RPAs without release notes are not reported.
+func rpaProductVersion(filePath string) (string, bool, bool, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", false, false, fmt.Errorf("failed to read %s: %w", filePath, err)
+	}
+	var rpa struct {
+		Kind string `yaml:"kind"`
+		Spec struct {
+			Data struct {
+				ReleaseNotes *struct {
+					ProductVersion string `yaml:"product_version"`
+				} `yaml:"releaseNotes"`
+				FBC map[string]any `yaml:"fbc"`
+			} `yaml:"data"`
+		} `yaml:"spec"`
+	}
+	if err := yaml.Unmarshal(content, &rpa); err != nil {
+		return "", false, false, fmt.Errorf("failed to parse %s: %w", filePath, err)
+	}
+	if rpa.Kind != "ReleasePlanAdmission" || rpa.Spec.Data.ReleaseNotes == nil {
+		return "", false, false, nil
+	}
+	return rpa.Spec.Data.ReleaseNotes.ProductVersion, rpa.Spec.Data.FBC != nil, true, nil
+}
+
+func formatProductVersions(result ProductVersionResult) string {
+	var sb strings.Builder
+	if result.Compliant {
+		fmt.Fprintf(&sb, "All %d RPAs follow the product_version policy\n", result.Checked)
+		return sb.String()
+	}
+	for _, violation := range result.Violations {
+		fmt.Fprintf(&sb, "✗ %s: %s", violation.File, violation.Problem)
+		if violation.Suggestion != "" {
+			fmt.Fprintf(&sb, ", use %q", violation.Suggestion)
+		}
+		sb.WriteString("\n")
+	}
+	fmt.Fprintf(&sb, "\n%d of %d RPAs break the product_version policy\n", len(result.Violations), result.Checked)
+	return sb.String()
+}
@@ -0,0 +1,68 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	mcp "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// progressReporter sends progress notifications for a tool call whose client
+// sent a progress token
+type progressReporter struct {
+	mu       sync.Mutex
+	ctx      context.Context
+	session  *mcp.ServerSession
+	token    any
+	progress float64
+}
+
+type progressReporterKey struct{}
+
+// progressMiddleware reports the progress of tool calls with a progress token.
+// Background jobs record their progress in their logs instead, the request
+// the token belongs to has already been answered.
+func progressMiddleware(next mcp.MethodHandler[*mcp.ServerSession]) mcp.MethodHandler[*mcp.ServerSession] {
+	return func(ctx context.Context, session *mcp.ServerSession, method string, params mcp.Params) (mcp.Result, error) {
+		callParams, ok := params.(*mcp.CallToolParamsFor[json.RawMessage])
+		if method != "tools/call" || !ok || session == nil || jobFrom(ctx) != nil {
+			return next(ctx, session, method, params)
+		}
+		if token := callParams.GetProgressToken(); token != nil {
+			reporter := &progressReporter{ctx: ctx, session: session, token: token}
+			ctx = context.WithValue(ctx, progressReporterKey{}, reporter)
+		}
+		return next(ctx, session, method, params)
+	}
+}
+
+// reportProgress tells the client how far a tool call is, out of total, and
+// records the message as a step of the call's artifacts. Nothing is sent
+// unless the client asked for progress or the call is a job.
+// Notifications not increasing the progress are dropped, as the protocol
+// requires it to increase. Delivery failures are ignored.
+func reportProgress(ctx context.Context, progress, total float64, message string) {
+	runArtifactsFrom(ctx).recordStep(message)
+	if j := jobFrom(ctx); j != nil {
+		j.send("info", message)
+		return
+	}
+	reporter, _ := ctx.Value(progressReporterKey{}).(*progressReporter)
+	if reporter == nil {
+		return
+	}
+
+	reporter.mu.Lock()
+	defer reporter.mu.Unlock()
+	if progress <= reporter.progress {
+		return
+	}
+	reporter.progress = progress
+	_ = reporter.session.NotifyProgress(reporter.ctx, &mcp.ProgressNotificationParams{
+		ProgressToken: reporter.token,
+		Progress:      progress,
+		Total:         total,
+		Message:       message,
+	})
+}
@@ -0,0 +1,134 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	mcp "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// releaseRunbookPrompt is the name of the prompt walking an agent through a release
+const releaseRunbookPrompt = "tekton-release-runbook"
+
+// runbookStep is a step of the release runbook, run with one or more tools
+type runbookStep struct {
+	title string
+	tools []string
+	text  string // Instructions, formatted with the minor and full version
+}
+
+// minorRunbook lists the steps of a minor release, in order
+var minorRunbook = []runbookStep{
+	{"Check the release is due and unblocked", []string{"query-release-calendar", "find-release-blocking-issues"},
+		"Confirm %[2]s is scheduled and that no blocker is open. Stop and tell me if any is."},
+	{"Cut the release branches", []string{"create-release-branches"},
+		"Create the release-v%[1]s.x branches of every component with minor_version '%[1]s'."},
+	{"Configure the hack repository", []string{"configure-hack-repo", "validate-branch-yaml", "generate-release-branch-readme"},
+		"Configure the hack repository for %[1]s, passing the upstream version of every component, then validate the generated branch files and generate the branch README. Wait for the hack pull request to merge."},
+	{"Create the release plans", []string{"create-release-plans", "verify-rpa-references", "stage-vs-prod-rpa-consistency-check", "check-fbc-allowed-packages"},
+		"Create the ReleasePlanAdmissions and ReleasePlans of %[1]s, then check their references, the consistency of stage and prod and the FBC packages. Wait for the konflux-release-data merge request to merge."},
+	{"Check the builds and the tenant namespace", []string{"check-build-status", "verify-tenant-namespace-quota-and-permissions"},
+		"Wait until the latest build of every component of %[1]s is green, and check the tenant namespace has the capacity and permissions to release."},
+	{"Select the snapshots", []string{"list-snapshots"},
+		"For every application of %[1]s, pick the newest snapshot whose integration tests all passed. Show me the chosen snapshots."},
+	{"Trigger the releases", []string{"trigger-release"},
+		"Release every selected snapshot of %[2]s through its stage ReleasePlan first. Once all stage releases succeed, release them through the prod ReleasePlans."},
+	{"Verify the release", []string{"verify-git-tags-match-rpa-version-tags", "compute-image-size-and-signature-report"},
+		"Check the git tags match the version tags of the release plans and that the shipped images of %[2]s are signed and have an SBOM."},
+}
+
+// patchRunbook lists the steps of a z-stream release, in order
+var patchRunbook = []runbookStep{
+	{"Check the release is warranted and unblocked", []string{"find-unreleased-merged-changes", "find-release-blocking-issues"},
+		"List what was merged into the release branches of %[1]s since the last z-stream, and confirm no blocker of %[2]s is open. Stop and tell me if nothing would ship or a blocker is open."},
+	{"Prepare the z-stream", []string{"orchestrate-z-stream-release"},
+		"Run the z-stream workflow of %[2]s with dry_run set to review the backports and readiness with me, then without it to create the RHBA release plans and trigger the builds. Wait for the konflux-release-data merge request to merge."},
+	{"Check the builds and the tenant namespace", []string{"check-build-status", "verify-tenant-namespace-quota-and-permissions"},
+		"Wait until the latest build of every component of %[1]s is green, and check the tenant namespace has the capacity and permissions to release."},
+	{"Select the snapshots", []string{"list-snapshots"},
+		"For every application of %[1]s, pick the newest snapshot built after the backports whose integration tests all passed. Show me the chosen snapshots."},
+	{"Trigger the releases", []string{"trigger-release"},
+		"Release every selected snapshot of %[2]s through its stage ReleasePlan first. Once all stage releases succeed, release them through the prod ReleasePlans."},
+	{"Verify the release", []string{"verify-git-tags-match-rpa-version-tags", "compute-image-size-and-signature-report"},
+		"Check the git tags match the version tags of the release plans and that the shipped images of %[2]s are signed and have an SBOM."},
+}
+
+// addReleaseRunbookPrompt exposes the release sequence as an MCP prompt
+func addReleaseRunbookPrompt(s *mcp.Server) {
+	s.AddPrompt(&mcp.Prompt{
+		Name:        releaseRunbookPrompt,
+		Title:       "Tekton release runbook",
+		Description: "Walks through the full release sequence of an OpenShift Pipelines version with the tools of this server: branching, hack configuration, release plans, snapshot selection and release trigger",
+		Arguments: []*mcp.PromptArgument{
+			{
+				Name:        "version",
+				Description: "Version to release, a minor (e.g., '1.21') or patch version (e.g., '1.21.3')",
+				Required:    true,
+			},
+			{
+				Name:        "release_type",
+				Description: "'minor' or 'patch'. Defaults to patch when version has a non-zero patch number, minor otherwise",
+			},
+		},
+	}, func(ctx context.Context, session *mcp.ServerSession, params *mcp.GetPromptParams) (*mcp.GetPromptResult, error) {
+		version := params.Arguments["version"]
+		if version == "" {
+			return nil, fmt.Errorf("version argument is required")
+		}
+		text, err := releaseRunbook(version, params.Arguments["release_type"])
+		if err != nil {
+			return nil, err
+		}
+		return &mcp.GetPromptResult{
+			Description: fmt.Sprintf("Release runbook for %s", version),
+			Messages:    []*mcp.PromptMessage{{Role: "user", Content: &mcp.TextContent{Text: text}}},
+		}, nil
+	})
+}
+
+// releaseRunbook renders the runbook of a version for a release type,
+// inferring the type from the version when it is empty
+func releaseRunbook(version, releaseType string) (string, error) {
+	parts := strings.Split(version, ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return "", fmt.Errorf("invalid version %q, expected X.Y or X.Y.Z", version)
+	}
+	minorVersion, patchVersion := parts[0]+"."+parts[1], ""
+	if len(parts) == 3 && parts[2] != "0" {
+		patchVersion = parts[2]
+	}
+
+	if releaseType == "" {
+		releaseType = "minor"
+		if patchVersion != "" {
+			releaseType = "patch"
+		}
+	}
+	steps := minorRunbook
+	switch releaseType {
+	case "minor":
+		if patchVersion != "" {
+			return "", fmt.Errorf("a minor release has no patch version, got %s", version)
+		}
+	case "patch":
+		steps = patchRunbook
+	default:
+		return "", fmt.Errorf("invalid release_type %q, expected minor or patch", releaseType)
+	}
+	_, fullVersion := getReleaseType(minorVersion, patchVersion)
+	if releaseType == "patch" && patchVersion == "" {
+		// The z-stream workflow picks the patch after the latest tagged one
+		fullVersion = minorVersion + ".z"
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Walk me through the %s release of %s %s with the tools of this server, one step at a time.\n\n", releaseType, product().DisplayName, fullVersion)
+	sb.WriteString("Before each step, tell me what it will do. After it, summarize its result and wait for me before going on. ")
+	sb.WriteString("When a tool returns a plan to confirm, show it to me and only repeat the call with its hash once I approve. ")
+	sb.WriteString("Stop at the first failure and help me diagnose it.\n\n")
+	for i, step := range steps {
+		fmt.Fprintf(&sb, "%d. %s (%s): %s\n", i+1, step.title, strings.Join(step.tools, ", "), fmt.Sprintf(step.text, minorVersion, fullVersion))
+	}
+	return sb.String(), nil
+}
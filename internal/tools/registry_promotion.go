@@ -0,0 +1,178 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// promotionsFile is the per-version log of promoted images in the state directory
+const promotionsFile = "promotions.jsonl"
+
+// PromotionConfig represents the configuration for promoting images from stage to prod
+type PromotionConfig struct {
+	MinorVersion   string
+	PatchVersion   string
+	Images         []string // Repositories under the product namespace, defaults to every component image
+	Tags           []string // Defaults to v<full version>
+	SourceRegistry string
+	TargetRegistry string
+	DryRun         bool
+}
+
+// ImagePromotion represents the promotion of a single image tag
+type ImagePromotion struct {
+	Source   string `json:"source"`
+	Target   string `json:"target"`
+	Digest   string `json:"digest,omitempty" jsonschema:"Digest of the source image"`
+	Verified bool   `json:"verified" jsonschema:"Whether the target tag resolves to the source digest after the copy"`
+	Error    string `json:"error,omitempty"`
+}
+
+// PromotionResult represents the structured result of registry-tag-promotion
+type PromotionResult struct {
+	Version    string           `json:"version"`
+	DryRun     bool             `json:"dry_run"`
+	Promoted   bool             `json:"promoted" jsonschema:"Whether every image was copied and verified"`
+	Promotions []ImagePromotion `json:"promotions"`
+}
+
+// PromotionRecord represents an entry of the promotion log
+type PromotionRecord struct {
+	Time time.Time `json:"time"`
+	ImagePromotion
+}
+
+func promoteImages(ctx context.Context, config PromotionConfig) (PromotionResult, error) {
+	_, fullVersion := getReleaseType(config.MinorVersion, config.PatchVersion)
+	result := PromotionResult{Version: fullVersion, DryRun: config.DryRun, Promoted: true}
+
+	if config.SourceRegistry == "" {
+		config.SourceRegistry = productImageNamespace("stage")
+	}
+	if config.TargetRegistry == "" {
+		config.TargetRegistry = productImageNamespace("prod")
+	}
+	if len(config.Tags) == 0 {
+		config.Tags = []string{"v" + fullVersion}
+	}
+	if len(config.Images) == 0 {
+		config.Images = componentImages()
+	}
+
+	for _, image := range config.Images {
+		for _, tag := range config.Tags {
+			sourceRepo := fmt.Sprintf("%s/%s", config.SourceRegistry, image)
+			promotion := ImagePromotion{
+				Source: sourceRepo + ":" + tag,
+				Target: fmt.Sprintf("%s/%s:%s", config.TargetRegistry, image, tag),
+			}
+			if err := promoteImage(ctx, &promotion, sourceRepo, config.DryRun); err != nil {
+				promotion.Error = err.Error()
+				result.Promoted = false
+			}
+			result.Promotions = append(result.Promotions, promotion)
+
+			if !config.DryRun && promotion.Digest != "" {
+				if err := publish(ctx, Event{Type: EventImagePromoted, Version: fullVersion, Data: promotion}); err != nil {
+					return result, err
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+// promoteImage copies the source digest to the target tag and checks the
+// target resolves to the same digest
+func promoteImage(ctx context.Context, promotion *ImagePromotion, sourceRepo string, dryRun bool) error {
+	digest, err := runCrane(ctx, "digest", promotion.Source)
+	if err != nil {
+		return fmt.Errorf("failed to resolve source: %w", err)
+	}
+	promotion.Digest = digest
+	if dryRun {
+		return nil
+	}
+
+	// Copy by digest so a concurrent push to the source tag cannot be promoted unverified
+	if _, err := runCrane(ctx, "copy", sourceRepo+"@"+digest, promotion.Target); err != nil {
+		return fmt.Errorf("failed to copy: %w", err)
+	}
+
+	targetDigest, err := runCrane(ctx, "digest", promotion.Target)
+	if err != nil {
+		return fmt.Errorf("failed to resolve target: %w", err)
+	}
+	if targetDigest != digest {
+		return fmt.Errorf("target resolves to %s, expected %s", targetDigest, digest)
+	}
+	promotion.Verified = true
+	return nil
+}
+
+func runCrane(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "crane", args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := runCommand(ctx, cmd); err != nil {
+		return "", fmt.Errorf("crane %s failed: %v\nError details: %s", args[0], err, stderr.String())
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// componentImages returns the repositories of every component image
+func componentImages() []string {
+	var images []string
+	for _, components := range productComponents() {
+		for _, component := range components {
+			images = append(images, component.Repository)
+		}
+	}
+	sort.Strings(images)
+	return images
+}
+
+// recordPromotion appends a promotion to the version's promotion log
+func recordPromotion(version string, promotion ImagePromotion) error {
+	auditMu.Lock()
+	defer auditMu.Unlock()
+
+	dir := filepath.Join(stateDir(), version)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	line, err := json.Marshal(PromotionRecord{Time: time.Now(), ImagePromotion: promotion})
+	if err != nil {
+		return fmt.Errorf("failed to encode promotion record: %w", err)
+	}
+	return appendToFile(filepath.Join(dir, promotionsFile), string(line)+"\n")
+}
+
+func formatPromotions(result PromotionResult) string {
+	var sb strings.Builder
+	if result.DryRun {
+		fmt.Fprintf(&sb, "Dry run: would promote %d images for %s\n", len(result.Promotions), result.Version)
+	} else {
+		fmt.Fprintf(&sb, "Promoted images for %s:\n", result.Version)
+	}
+	for _, promotion := range result.Promotions {
+		switch {
+		case promotion.Error != "":
+			fmt.Fprintf(&sb, "✗ %s: %s\n", promotion.Source, promotion.Error)
+		case result.DryRun:
+			fmt.Fprintf(&sb, "- %s (%s) -> %s\n", promotion.Source, promotion.Digest, promotion.Target)
+		default:
+			fmt.Fprintf(&sb, "✓ %s -> %s (%s)\n", promotion.Source, promotion.Target, promotion.Digest)
+		}
+	}
+	return sb.String()
+}
@@ -1,196 +1,396 @@
 package tools
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// defaultBranchParallelism is how many repositories create-release-branches processes at once
+	defaultBranchParallelism = 4
+
+	// maxBranchParallelism bounds the parallelism, to stay clear of the forge rate limits
+	maxBranchParallelism = 16
 )
 
-func createBranch(minorVersion string) (bool, error) {
+// createBranch cuts the release branch of a minor version in every release
+// repository, processing up to parallelism repositories at once
+func createBranch(ctx context.Context, minorVersion string, parallelism int) (BranchResult, error) {
+	result := BranchResult{BranchName: fmt.Sprintf("release-v%s.x", minorVersion)}
 	if minorVersion == "" {
-		return false, fmt.Errorf("minor version is required")
+		return result, fmt.Errorf("minor version is required")
 	}
 
-	fmt.Printf("Creating branches for version %s\n", minorVersion)
+	slog.InfoContext(ctx, "Creating release branches", "version", minorVersion)
 
 	// Create a temporary working directory
 	workDir, err := os.MkdirTemp("", "tekton-release-*")
 	if err != nil {
-		return false, fmt.Errorf("failed to create working directory: %w", err)
+		return result, fmt.Errorf("failed to create working directory: %w", err)
 	}
 	defer os.RemoveAll(workDir) // Clean up when done
 
-	fmt.Println("workDir:", workDir)
+	slog.DebugContext(ctx, "Created working directory", "path", workDir)
 
 	// Default repositories configuration
 	config := Config{
 		MinorVersion: minorVersion,
 		WorkDir:      workDir,
-		Repositories: []Repository{
-			{
-				Name:         "pipeline",
-				SourceBranch: "next",
-				RepoURL:      "git@github.com:openshift-pipelines/tektoncd-pipeline.git",
-			},
-			{
-				Name:         "triggers",
-				SourceBranch: "next",
-				RepoURL:      "git@github.com/openshift-pipelines/tektoncd-triggers.git",
-			},
-			{
-				Name:         "chains",
-				SourceBranch: "next",
-				RepoURL:      "git@github.com/openshift-pipelines/tektoncd-chains.git",
-			},
-			{
-				Name:         "results",
-				SourceBranch: "next",
-				RepoURL:      "git@github.com/openshift-pipelines/tektoncd-results.git",
-			},
-			{
-				Name:         "cli",
-				SourceBranch: "next",
-				RepoURL:      "git@github.com/openshift-pipelines/tektoncd-cli",
-			},
-			{
-				Name:         "hub",
-				SourceBranch: "next",
-				RepoURL:      "git@github.com/openshift-pipelines/tektoncd-hub",
-			},
-			{
-				Name:         "pac",
-				SourceBranch: "next",
-				RepoURL:      "git@github.com/openshift-pipelines/pac-downstream",
-			},
-			{
-				Name:         "cache",
-				SourceBranch: "next",
-				RepoURL:      "git@github.com/openshift-pipelines/tekton-caches",
-			},
-			{
-				Name:         "git-init",
-				SourceBranch: "next",
-				RepoURL:      "git@github.com/openshift-pipelines/tektoncd-git-clone",
-			},
-			{
-				Name:         "operator",
-				SourceBranch: "next",
-				RepoURL:      "git@github.com/openshift-pipelines/operator.git",
-			},
-			{
-				Name:         "hack",
-				SourceBranch: "next",
-				RepoURL:      "git@github.com/openshift-pipelines/hack.git",
-			},
-			// Skipped repositories
-			{
-				Name: "manual-approval-gate",
-				Skip: true,
-			},
-			{
-				Name: "opc",
-				Skip: true,
-			},
-			{
-				Name: "console-plugin",
-				Skip: true,
-			},
-			{
-				Name: "tektoncd-pruner",
-				Skip: true,
-			},
-			{
-				Name: "tekton-caches",
-				Skip: true,
-			},
-		},
+		Repositories: releaseRepositories(),
 	}
 
+	var repos []Repository
 	for _, repo := range config.Repositories {
-		if repo.Skip {
-			continue
+		if !repo.Skip {
+			repos = append(repos, repo)
 		}
+	}
+	total := len(repos)
 
-		if err := createBranchForRepo(repo, config); err != nil {
-			return false, fmt.Errorf("failed to create branch for %s: %w", repo.Name, err)
-		}
+	// Every repository is attempted, a failure does not stop the others
+	result.Results = make([]RepoBranchResult, total)
+	var mu sync.Mutex
+	done := 0
+	var g errgroup.Group
+	g.SetLimit(parallelism)
+	for i, repo := range repos {
+		g.Go(func() error {
+			mu.Lock()
+			started := done
+			mu.Unlock()
+			reportProgress(ctx, float64(started)+0.5, float64(total), fmt.Sprintf("Cloning %s (%d/%d done)", repo.Name, started, total))
+
+			start := time.Now()
+			err := createBranchForRepo(ctx, repo, config)
+			outcome := RepoBranchResult{Repository: repo.Name, Status: "created", Duration: time.Since(start).Round(100 * time.Millisecond).String()}
+			message := "Pushed %s to %s (%d/%d done)"
+			if err != nil {
+				outcome.Status, outcome.Error = "failed", err.Error()
+				message = "Failed to push %s to %s (%d/%d done)"
+			}
+			result.Results[i] = outcome
+
+			mu.Lock()
+			done++
+			finished := done
+			mu.Unlock()
+			reportProgress(ctx, float64(finished), float64(total), fmt.Sprintf(message, result.BranchName, repo.Name, finished, total))
+			return nil
+		})
 	}
+	_ = g.Wait()
 
-	return true, nil
+	var failures []string
+	for _, outcome := range result.Results {
+		if outcome.Status == "failed" {
+			result.FailedRepos = append(result.FailedRepos, outcome.Repository)
+			failures = append(failures, fmt.Sprintf("%s: %s", outcome.Repository, outcome.Error))
+		} else {
+			result.Repositories = append(result.Repositories, outcome.Repository)
+		}
+	}
+	if len(failures) > 0 {
+		return result, fmt.Errorf("failed to create branch in %d of %d repositories:\n%s", len(failures), total, strings.Join(failures, "\n"))
+	}
+	return result, nil
 }
 
-func createBranchForRepo(repo Repository, config Config) error {
-	fmt.Println("Creating branch for repo:", repo.Name)
+func createBranchForRepo(ctx context.Context, repo Repository, config Config) error {
+	slog.DebugContext(ctx, "Creating release branch", "repository", repo.Name)
 
 	// Create repository directory
 	repoDir := filepath.Join(config.WorkDir, repo.Name)
 	if err := os.MkdirAll(repoDir, 0755); err != nil {
 		return fmt.Errorf("failed to create directory for %s: %w", repo.Name, err)
 	}
-	fmt.Println("Repository directory:", repoDir)
 
-	// Clone the repository
-	fmt.Println("Cloning repository:", repo.RepoURL)
-	cloneCmd := exec.Command("git", "clone", repo.RepoURL, ".")
+	// Clone only the tip of the source branch, which the release branch starts from
+	slog.DebugContext(ctx, "Cloning repository", "url", repo.RepoURL, "branch", repo.SourceBranch, "path", repoDir)
+	cloneCmd, err := gitCommand(ctx, githubHost, "clone", "--depth", "1", "--single-branch", "-b", repo.SourceBranch, repo.RepoURL, ".")
+	if err != nil {
+		return err
+	}
 	cloneCmd.Dir = repoDir
-	cloneCmd.Stdout = os.Stdout
-	cloneCmd.Stderr = os.Stderr
-	if err := cloneCmd.Run(); err != nil {
-		return fmt.Errorf("failed to clone repository %s: %w", repo.Name, err)
-	}
-
-	// Fetch all branches
-	fmt.Println("Fetching all branches")
-	fetchCmd := exec.Command("git", "fetch", "--all")
-	fetchCmd.Dir = repoDir
-	fetchCmd.Stdout = os.Stdout
-	fetchCmd.Stderr = os.Stderr
-	if err := fetchCmd.Run(); err != nil {
-		return fmt.Errorf("failed to fetch branches for %s: %w", repo.Name, err)
-	}
-
-	// Checkout source branch
-	fmt.Printf("Checking out source branch: %s\n", repo.SourceBranch)
-	checkoutCmd := exec.Command("git", "checkout", repo.SourceBranch)
-	checkoutCmd.Dir = repoDir
-	checkoutCmd.Stdout = os.Stdout
-	checkoutCmd.Stderr = os.Stderr
-	if err := checkoutCmd.Run(); err != nil {
-		return fmt.Errorf("failed to checkout %s: %w", repo.SourceBranch, err)
-	}
-
-	// Pull latest changes
-	fmt.Println("Pulling latest changes")
-	pullCmd := exec.Command("git", "pull", "origin", repo.SourceBranch)
-	pullCmd.Dir = repoDir
-	pullCmd.Stdout = os.Stdout
-	pullCmd.Stderr = os.Stderr
-	if err := pullCmd.Run(); err != nil {
-		return fmt.Errorf("failed to pull latest changes for %s: %w", repo.Name, err)
+	if err := runCommand(ctx, cloneCmd); err != nil {
+		return fmt.Errorf("failed to clone %s of repository %s: %w", repo.SourceBranch, repo.Name, err)
 	}
+	runUsageFrom(ctx).recordClone(repoDir)
 
 	// Create new branch
 	newBranchName := fmt.Sprintf("release-v%s.x", config.MinorVersion)
-	fmt.Printf("Creating new branch: %s\n", newBranchName)
-	createBranchCmd := exec.Command("git", "checkout", "-b", newBranchName)
+	createBranchCmd := exec.CommandContext(ctx, "git", "checkout", "-b", newBranchName)
 	createBranchCmd.Dir = repoDir
-	createBranchCmd.Stdout = os.Stdout
-	createBranchCmd.Stderr = os.Stderr
-	if err := createBranchCmd.Run(); err != nil {
+	if err := runCommand(ctx, createBranchCmd); err != nil {
 		return fmt.Errorf("failed to create branch %s: %w", newBranchName, err)
 	}
 
 	// Push new branch to origin
-	fmt.Printf("Pushing branch %s to origin\n", newBranchName)
-	pushCmd := exec.Command("git", "push", "origin", newBranchName)
+	pushCmd, err := gitCommand(ctx, githubHost, "push", "origin", newBranchName)
+	if err != nil {
+		return err
+	}
 	pushCmd.Dir = repoDir
-	pushCmd.Stdout = os.Stdout
-	pushCmd.Stderr = os.Stderr
-	if err := pushCmd.Run(); err != nil {
+	if err := runCommand(ctx, pushCmd); err != nil {
 		return fmt.Errorf("failed to push branch %s: %w", newBranchName, err)
 	}
+	repository := repo.RepoURL
+	if path := forgeRepoPath(repository); path != "" {
+		repository = path
+	}
+	_ = publish(ctx, Event{Type: EventBranchCut, Data: PushedBranch{Repository: repository, Branch: newBranchName}})
 
-	fmt.Printf("Successfully created and pushed branch %s for %s\n", newBranchName, repo.Name)
+	slog.InfoContext(ctx, "Pushed release branch", "repository", repo.Name, "branch", newBranchName)
 	return nil
 }
+
+// releaseRepositories returns the repositories release branches are cut in,
+// from the live configuration, the selected product profile, the repositories
+// loaded at startup or the built-in list, in that order
+func releaseRepositories() []Repository {
+	if repos := currentConfig().Repositories; len(repos) > 0 {
+		return repos
+	}
+	if repos := product().Repositories; len(repos) > 0 {
+		return repos
+	}
+	externalRepositoriesMu.RLock()
+	defer externalRepositoriesMu.RUnlock()
+	if len(externalRepositories) > 0 {
+		return externalRepositories
+	}
+	return defaultReleaseRepositories()
+}
+
+// defaultReleaseRepositories returns the built-in repository list
+func defaultReleaseRepositories() []Repository {
+	return []Repository{
+		{
+			Name:         "pipeline",
+			SourceBranch: "next",
+			RepoURL:      forgeRepoURL(githubHost, "openshift-pipelines/tektoncd-pipeline"),
+		},
+		{
+			Name:         "triggers",
+			SourceBranch: "next",
+			RepoURL:      forgeRepoURL(githubHost, "openshift-pipelines/tektoncd-triggers"),
+		},
+		{
+			Name:         "chains",
+			SourceBranch: "next",
+			RepoURL:      forgeRepoURL(githubHost, "openshift-pipelines/tektoncd-chains"),
+		},
+		{
+			Name:         "results",
+			SourceBranch: "next",
+			RepoURL:      forgeRepoURL(githubHost, "openshift-pipelines/tektoncd-results"),
+		},
+		{
+			Name:         "cli",
+			SourceBranch: "next",
+			RepoURL:      forgeRepoURL(githubHost, "openshift-pipelines/tektoncd-cli"),
+		},
+		{
+			Name:         "hub",
+			SourceBranch: "next",
+			RepoURL:      forgeRepoURL(githubHost, "openshift-pipelines/tektoncd-hub"),
+		},
+		{
+			Name:         "pac",
+			SourceBranch: "next",
+			RepoURL:      forgeRepoURL(githubHost, "openshift-pipelines/pac-downstream"),
+		},
+		{
+			Name:         "cache",
+			SourceBranch: "next",
+			RepoURL:      forgeRepoURL(githubHost, "openshift-pipelines/tekton-caches"),
+		},
+		{
+			Name:         "git-init",
+			SourceBranch: "next",
+			RepoURL:      forgeRepoURL(githubHost, "openshift-pipelines/tektoncd-git-clone"),
+		},
+		{
+			Name:         "operator",
+			SourceBranch: "next",
+			RepoURL:      forgeRepoURL(githubHost, "openshift-pipelines/operator"),
+		},
+		{
+			Name:         "hack",
+			SourceBranch: "next",
+			RepoURL:      forgeRepoURL(githubHost, "openshift-pipelines/hack"),
+		},
+		// Skipped repositories
+		{
+			Name: "manual-approval-gate",
+			Skip: true,
+		},
+		{
+			Name: "opc",
+			Skip: true,
+		},
+		{
+			Name: "console-plugin",
+			Skip: true,
+		},
+		{
+			Name: "tektoncd-pruner",
+			Skip: true,
+		},
+		{
+			Name: "tekton-caches",
+			Skip: true,
+		},
+	}
+}
+
+// deleteBranches removes the release branch of the minor version from the
+// release repositories, undoing createBranch. The call is confirmed by the
+// confirmation middleware when it spans many repositories.
+func deleteBranches(ctx context.Context, minorVersion string) (DeleteBranchesResult, error) {
+	result := DeleteBranchesResult{BranchName: fmt.Sprintf("release-v%s.x", minorVersion)}
+	if minorVersion == "" {
+		return result, fmt.Errorf("minor version is required")
+	}
+
+	var repos []string
+	for _, repo := range releaseRepositories() {
+		if !repo.Skip {
+			repos = append(repos, forgeRepoPath(repo.RepoURL))
+		}
+	}
+
+	provider, err := vcsProvider(githubHost)
+	if err != nil {
+		return result, err
+	}
+	for _, repo := range repos {
+		deletion := BranchDeletion{Repository: repo, Status: "deleted"}
+		switch err := provider.DeleteBranch(ctx, repo, result.BranchName); {
+		case errors.Is(err, errBranchNotFound):
+			deletion.Status = "missing"
+		case err != nil:
+			deletion.Status = "failed"
+			deletion.Error = err.Error()
+		default:
+			slog.InfoContext(ctx, "Deleted release branch", "repository", repo, "branch", result.BranchName)
+		}
+		result.Branches = append(result.Branches, deletion)
+	}
+	return result, nil
+}
+
+func formatDeleteBranches(result DeleteBranchesResult) string {
+	var sb strings.Builder
+	for _, branch := range result.Branches {
+		switch branch.Status {
+		case "failed":
+			fmt.Fprintf(&sb, "✗ %s: %s\n", branch.Repository, branch.Error)
+		case "missing":
+			fmt.Fprintf(&sb, "- %s: no %s branch\n", branch.Repository, result.BranchName)
+		default:
+			fmt.Fprintf(&sb, "✓ %s: %s %s\n", branch.Repository, result.BranchName, branch.Status)
+		}
+	}
+	return sb.String()
+}
+
+// branchListWorkers bounds the number of repositories queried concurrently
+const branchListWorkers = 4
+
+// listBranches reports whether the release branch of the minor version exists
+// in each release repository, its head and how far it diverged from the
+// branch it was cut from
+func listBranches(ctx context.Context, minorVersion string) (ListBranchesResult, error) {
+	result := ListBranchesResult{BranchName: fmt.Sprintf("release-v%s.x", minorVersion)}
+	if minorVersion == "" {
+		return result, fmt.Errorf("minor version is required")
+	}
+
+	gh, err := newGitHubClient()
+	if err != nil {
+		return result, err
+	}
+
+	for _, repo := range releaseRepositories() {
+		if repo.Skip {
+			continue
+		}
+		source := repo.SourceBranch
+		if source == "" {
+			source = "next"
+		}
+		result.Branches = append(result.Branches, ReleaseBranchInfo{Repository: forgeRepoPath(repo.RepoURL), SourceBranch: source})
+	}
+
+	var g errgroup.Group
+	g.SetLimit(branchListWorkers)
+	for i := range result.Branches {
+		g.Go(func() error {
+			inspectReleaseBranch(ctx, gh, &result.Branches[i], result.BranchName)
+			return nil
+		})
+	}
+	_ = g.Wait()
+	return result, nil
+}
+
+// inspectReleaseBranch fills in the head and divergence of the release branch of a repository
+func inspectReleaseBranch(ctx context.Context, gh *githubClient, info *ReleaseBranchInfo, branch string) {
+	var head struct {
+		Commit struct {
+			SHA string `json:"sha"`
+		} `json:"commit"`
+	}
+	err := gh.do(ctx, http.MethodGet, fmt.Sprintf("/repos/%s/branches/%s", info.Repository, url.PathEscape(branch)), nil, &head)
+	var ghErr *githubError
+	if errors.As(err, &ghErr) && ghErr.StatusCode == http.StatusNotFound {
+		return
+	}
+	if err != nil {
+		info.Error = err.Error()
+		return
+	}
+	info.Exists = true
+	info.HeadSHA = head.Commit.SHA
+
+	var comparison struct {
+		AheadBy  int `json:"ahead_by"`
+		BehindBy int `json:"behind_by"`
+	}
+	if err := gh.do(ctx, http.MethodGet, fmt.Sprintf("/repos/%s/compare/%s...%s", info.Repository, url.PathEscape(info.SourceBranch), url.PathEscape(branch)), nil, &comparison); err != nil {
+		info.Error = err.Error()
+		return
+	}
+	info.AheadBy = comparison.AheadBy
+	info.BehindBy = comparison.BehindBy
+}
+
+func formatListBranches(result ListBranchesResult) string {
+	var sb strings.Builder
+	var existing int
+	for _, branch := range result.Branches {
+		switch {
+		case branch.Error != "":
+			fmt.Fprintf(&sb, "✗ %s: %s\n", branch.Repository, branch.Error)
+		case !branch.Exists:
+			fmt.Fprintf(&sb, "- %s: no %s branch\n", branch.Repository, result.BranchName)
+		default:
+			existing++
+			fmt.Fprintf(&sb, "✓ %s: %s at %s, %d ahead and %d behind %s\n", branch.Repository, result.BranchName, branch.HeadSHA[:min(12, len(branch.HeadSHA))], branch.AheadBy, branch.BehindBy, branch.SourceBranch)
+		}
+	}
+	fmt.Fprintf(&sb, "\n%s exists in %d of %d repositories\n", result.BranchName, existing, len(result.Branches))
+	return sb.String()
+}
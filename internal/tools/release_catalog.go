@@ -0,0 +1,182 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// releaseServiceCatalogRepo is the GitHub repository of the managed release pipelines
+	releaseServiceCatalogRepo = "konflux-ci/release-service-catalog"
+
+	// releaseServiceCatalogRevision is the catalog revision the generated RPAs resolve pipelines at
+	releaseServiceCatalogRevision = "production"
+)
+
+// releasePipelines are the catalog pipelines referenced by the generated RPAs, by name
+var releasePipelines = map[string]string{
+	"rh-advisories": "pipelines/managed/rh-advisories/rh-advisories.yaml",
+	"fbc-release":   "pipelines/managed/fbc-release/fbc-release.yaml",
+}
+
+// PipelineParam represents a parameter of a Tekton pipeline
+type PipelineParam struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Default     string `json:"default,omitempty" jsonschema:"Default value, JSON encoded for arrays and objects"`
+	Required    bool   `json:"required" jsonschema:"Whether the parameter has no default"`
+	Description string `json:"description,omitempty"`
+}
+
+// CatalogPipeline represents the parameters of a release-service-catalog pipeline
+type CatalogPipeline struct {
+	Name   string          `json:"name"`
+	Path   string          `json:"path"`
+	Params []PipelineParam `json:"params"`
+}
+
+// CatalogParamsResult represents the structured result of fetch-release-service-catalog-params
+type CatalogParamsResult struct {
+	Revision  string            `json:"revision"`
+	Pipelines []CatalogPipeline `json:"pipelines"`
+}
+
+// fetchCatalogParams reads the pipelines from the catalog at revision and lists
+// their parameters. Pipelines are catalog paths or names of releasePipelines;
+// all releasePipelines are read when none are given.
+func fetchCatalogParams(ctx context.Context, pipelines []string, revision string) (CatalogParamsResult, error) {
+	if revision == "" {
+		revision = releaseServiceCatalogRevision
+	}
+	result := CatalogParamsResult{Revision: revision}
+	if len(pipelines) == 0 {
+		pipelines = releasePipelineNames()
+	}
+
+	gh, err := newGitHubClient()
+	if err != nil {
+		return result, err
+	}
+
+	for _, pipeline := range pipelines {
+		catalogPipeline := CatalogPipeline{Name: pipeline, Path: pipeline}
+		if path, ok := releasePipelines[pipeline]; ok {
+			catalogPipeline.Path = path
+		} else if !strings.HasSuffix(pipeline, ".yaml") {
+			return result, fmt.Errorf("unknown pipeline %q, expected a catalog path or one of: %s", pipeline, strings.Join(releasePipelineNames(), ", "))
+		}
+
+		content, err := gh.fileContent(ctx, releaseServiceCatalogRepo, catalogPipeline.Path, revision)
+		if err != nil {
+			return result, err
+		}
+		catalogPipeline.Params, err = parsePipelineParams(content)
+		if err != nil {
+			return result, fmt.Errorf("failed to parse %s: %w", catalogPipeline.Path, err)
+		}
+		result.Pipelines = append(result.Pipelines, catalogPipeline)
+	}
+	return result, nil
+}
+
+// parsePipelineParams returns the parameters declared by a Tekton Pipeline
+func parsePipelineParams(content []byte) ([]PipelineParam, error) {
+	var pipeline struct {
+		Kind string `yaml:"kind"`
+		Spec struct {
+			Params []struct {
+				Name        string `yaml:"name"`
+				Type        string `yaml:"type"`
+				Description string `yaml:"description"`
+				Default     any    `yaml:"default"`
+			} `yaml:"params"`
+		} `yaml:"spec"`
+	}
+	if err := yaml.Unmarshal(content, &pipeline); err != nil {
+		return nil, err
+	}
+	if pipeline.Kind != "Pipeline" {
+		return nil, fmt.Errorf("expected a Pipeline, got %q", pipeline.Kind)
+	}
+
+	params := make([]PipelineParam, 0, len(pipeline.Spec.Params))
+	for _, p := range pipeline.Spec.Params {
+		param := PipelineParam{
+			Name:        p.Name,
+			Type:        p.Type,
+			Required:    p.Default == nil,
+			Description: strings.TrimSpace(p.Description),
+		}
+		if param.Type == "" {
+			param.Type = "string"
+		}
+		switch value := p.Default.(type) {
+		case nil:
+		case string:
+			param.Default = value
+		default:
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode default of %s: %w", p.Name, err)
+			}
+			param.Default = string(encoded)
+		}
+		params = append(params, param)
+	}
+	return params, nil
+}
+
+// resolveCatalogRevision returns the commit SHA the catalog ref points to
+func resolveCatalogRevision(ctx context.Context, ref string) (string, error) {
+	gh, err := newGitHubClient()
+	if err != nil {
+		return "", err
+	}
+	var commit struct {
+		SHA string `json:"sha"`
+	}
+	if err := gh.do(ctx, http.MethodGet, fmt.Sprintf("/repos/%s/commits/%s", releaseServiceCatalogRepo, url.PathEscape(ref)), nil, &commit); err != nil {
+		return "", fmt.Errorf("failed to resolve %s@%s: %w", releaseServiceCatalogRepo, ref, err)
+	}
+	return commit.SHA, nil
+}
+
+func releasePipelineNames() []string {
+	names := make([]string, 0, len(releasePipelines))
+	for name := range releasePipelines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func formatCatalogParams(result CatalogParamsResult) string {
+	var sb strings.Builder
+	for _, pipeline := range result.Pipelines {
+		fmt.Fprintf(&sb, "%s (%s@%s):\n", pipeline.Name, pipeline.Path, result.Revision)
+		for _, param := range pipeline.Params {
+			value := "required"
+			switch {
+			case param.Required:
+			case param.Type == "string":
+				value = fmt.Sprintf("default %q", param.Default)
+			default:
+				value = "default " + param.Default
+			}
+			fmt.Fprintf(&sb, "- %s (%s, %s)", param.Name, param.Type, value)
+			if param.Description != "" {
+				fmt.Fprintf(&sb, ": %s", strings.Join(strings.Fields(param.Description), " "))
+			}
+			sb.WriteString("\n")
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
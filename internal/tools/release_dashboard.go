@@ -0,0 +1,279 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+// DashboardBranch represents the release branch of a release repository
+type DashboardBranch struct {
+	Repository string `json:"repository"`
+	Exists     bool   `json:"exists"`
+}
+
+// DashboardRPA represents a ReleasePlanAdmission of the version on the cluster
+type DashboardRPA struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Present   bool   `json:"present"`
+}
+
+// DashboardRelease represents the latest Release of a ReleasePlan of the version
+type DashboardRelease struct {
+	ReleasePlan string    `json:"release_plan"`
+	Name        string    `json:"name"`
+	Snapshot    string    `json:"snapshot"`
+	Created     time.Time `json:"created"`
+	Outcome     string    `json:"outcome" jsonschema:"succeeded, failed or in progress"`
+	Message     string    `json:"message,omitempty" jsonschema:"Message of the Released condition"`
+}
+
+// ReleaseDashboardResult represents the structured result of release-status
+type ReleaseDashboardResult struct {
+	Version       string             `json:"version"`
+	Branch        string             `json:"branch"`
+	Branches      []DashboardBranch  `json:"branches"`
+	HackPR        *ChangeRequestInfo `json:"hack_pr,omitempty" jsonschema:"Latest pull request of configure-hack-repo, absent when none was opened"`
+	ReleasePlanMR *ChangeRequestInfo `json:"release_plan_mr,omitempty" jsonschema:"Latest konflux-release-data merge request of create-release-plans, absent when none was opened"`
+	RPAs          []DashboardRPA     `json:"rpas"`
+	Releases      []DashboardRelease `json:"releases,omitempty"`
+	Errors        []string           `json:"errors,omitempty" jsonschema:"Parts of the status that could not be fetched"`
+}
+
+// releaseDashboard gathers where a version stands across the forges and the
+// cluster: its release branches, the hack pull request, the release plan
+// merge request, its ReleasePlanAdmissions and the latest Release of each of
+// its ReleasePlans. A part failing to be fetched is reported in Errors
+// rather than hiding the others.
+func releaseDashboard(ctx context.Context, minorVersion string) (ReleaseDashboardResult, error) {
+	result := ReleaseDashboardResult{Version: minorVersion, Branch: fmt.Sprintf("release-v%s.x", minorVersion)}
+	if minorVersion == "" {
+		return result, fmt.Errorf("minor version is required")
+	}
+	fail := func(part string, err error) {
+		result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", part, err))
+	}
+
+	branches, err := dashboardBranches(ctx, result.Branch)
+	if err != nil {
+		fail("release branches", err)
+	}
+	result.Branches = branches
+
+	// configure-hack-repo and create-release-plans name their requests after the version
+	hackPR, err := latestChangeRequest(ctx, githubHost, hackRepo, fmt.Sprintf("Update Konflux configuration for release v%s", minorVersion))
+	if err != nil {
+		fail("hack pull request", err)
+	}
+	result.HackPR = hackPR
+	konfluxRepo, _ := konfluxRepository()
+	releasePlanMR, err := latestChangeRequest(ctx, gitlabHost, konfluxRepo, fmt.Sprintf("Add ReleasePlan and ReleasePlanAdmission for v%s", minorVersion))
+	if err != nil {
+		fail("release plan merge request", err)
+	}
+	result.ReleasePlanMR = releasePlanMR
+
+	dc, err := dynamicClient(ctx)
+	if err != nil {
+		fail("cluster", err)
+		return result, nil
+	}
+	rpas, err := dashboardRPAs(ctx, dc, minorVersion)
+	if err != nil {
+		fail("ReleasePlanAdmissions", err)
+	}
+	result.RPAs = rpas
+	releases, err := dashboardReleases(ctx, dc, minorVersion)
+	if err != nil {
+		fail("Releases", err)
+	}
+	result.Releases = releases
+	return result, nil
+}
+
+// dashboardBranches reports which release repositories have the release branch
+func dashboardBranches(ctx context.Context, branch string) ([]DashboardBranch, error) {
+	var repos []string
+	byOwner := make(map[string][]string)
+	for _, repo := range releaseRepositories() {
+		if repo.Skip {
+			continue
+		}
+		path := forgeRepoPath(repo.RepoURL)
+		repos = append(repos, path)
+		owner, name, _ := strings.Cut(path, "/")
+		byOwner[owner] = append(byOwner[owner], name)
+	}
+
+	gh, err := newGitHubClient()
+	if err != nil {
+		return nil, err
+	}
+	exists := make(map[string]bool)
+	for owner, names := range byOwner {
+		found, err := gh.branchesExist(ctx, owner, names, branch)
+		if err != nil {
+			return nil, err
+		}
+		for name, ok := range found {
+			exists[owner+"/"+name] = ok
+		}
+	}
+	branches := make([]DashboardBranch, 0, len(repos))
+	for _, repo := range repos {
+		branches = append(branches, DashboardBranch{Repository: repo, Exists: exists[repo]})
+	}
+	return branches, nil
+}
+
+// latestChangeRequest returns the most recent pull or merge request titled
+// exactly title, or nil when there is none
+func latestChangeRequest(ctx context.Context, host, repo, title string) (*ChangeRequestInfo, error) {
+	provider, err := vcsProvider(host)
+	if err != nil {
+		return nil, err
+	}
+	requests, err := provider.FindChangeRequests(ctx, repo, title)
+	if err != nil {
+		return nil, err
+	}
+	for _, request := range requests {
+		if request.Title == title {
+			return &request, nil
+		}
+	}
+	return nil, nil
+}
+
+// dashboardRPAs reports which of the ReleasePlanAdmissions create-release-plans
+// generates for the version exist on the cluster
+func dashboardRPAs(ctx context.Context, dc dynamic.Interface, minorVersion string) ([]DashboardRPA, error) {
+	var rpas []DashboardRPA
+	for _, component := range sortedComponentNames(productComponents()) {
+		for _, env := range []string{"stage", "prod"} {
+			name := productName(component, minorVersion, env)
+			if component == "fbc" {
+				name = productName(minorVersion, "fbc", env)
+			}
+			_, err := dc.Resource(releaseResources["ReleasePlanAdmission"]).Namespace(relengTenant()).Get(ctx, name, metav1.GetOptions{})
+			if err != nil && !apierrors.IsNotFound(err) {
+				return rpas, fmt.Errorf("failed to get ReleasePlanAdmission %s: %w", name, err)
+			}
+			rpas = append(rpas, DashboardRPA{Name: name, Namespace: relengTenant(), Present: err == nil})
+		}
+	}
+	return rpas, nil
+}
+
+// dashboardReleases returns the latest Release of each ReleasePlan of the
+// version in the tenant namespace
+func dashboardReleases(ctx context.Context, dc dynamic.Interface, minorVersion string) ([]DashboardRelease, error) {
+	releasePlans := make(map[string]bool)
+	for component := range productComponents() {
+		for _, env := range []string{"stage", "prod"} {
+			releasePlans[productName(component, minorVersion, env, "release-as-op")] = true
+		}
+	}
+
+	list, err := dc.Resource(releaseGVR).Namespace(tenantNamespace()).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Releases in %s: %w", tenantNamespace(), err)
+	}
+	latest := make(map[string]*unstructured.Unstructured)
+	for i := range list.Items {
+		release := &list.Items[i]
+		releasePlan, _, _ := unstructured.NestedString(release.Object, "spec", "releasePlan")
+		if !releasePlans[releasePlan] {
+			continue
+		}
+		if current := latest[releasePlan]; current == nil || current.GetCreationTimestamp().Time.Before(release.GetCreationTimestamp().Time) {
+			latest[releasePlan] = release
+		}
+	}
+
+	var releases []DashboardRelease
+	for releasePlan, release := range latest {
+		conditions, outcome := releaseConditions(release)
+		if outcome == "" {
+			outcome = "in progress"
+		}
+		entry := DashboardRelease{
+			ReleasePlan: releasePlan,
+			Name:        release.GetName(),
+			Created:     release.GetCreationTimestamp().Time,
+			Outcome:     outcome,
+		}
+		entry.Snapshot, _, _ = unstructured.NestedString(release.Object, "spec", "snapshot")
+		for _, condition := range conditions {
+			if condition.Type == releasedCondition {
+				entry.Message = condition.Message
+			}
+		}
+		releases = append(releases, entry)
+	}
+	sort.Slice(releases, func(i, j int) bool { return releases[i].ReleasePlan < releases[j].ReleasePlan })
+	return releases, nil
+}
+
+func formatReleaseDashboard(result ReleaseDashboardResult) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Release status of v%s\n", result.Version)
+
+	fmt.Fprintf(&sb, "\nBranches (%s):\n", result.Branch)
+	for _, branch := range result.Branches {
+		mark := "✓"
+		if !branch.Exists {
+			mark = "✗"
+		}
+		fmt.Fprintf(&sb, "%s %s\n", mark, branch.Repository)
+	}
+
+	sb.WriteString("\nPull and merge requests:\n")
+	for _, request := range []struct {
+		name string
+		info *ChangeRequestInfo
+	}{{"Hack pull request", result.HackPR}, {"Release plan merge request", result.ReleasePlanMR}} {
+		if request.info == nil {
+			fmt.Fprintf(&sb, "- %s: not opened\n", request.name)
+			continue
+		}
+		fmt.Fprintf(&sb, "- %s: %s (%s)\n", request.name, request.info.State, request.info.URL)
+	}
+
+	sb.WriteString("\nReleasePlanAdmissions on the cluster:\n")
+	for _, rpa := range result.RPAs {
+		mark := "✓"
+		if !rpa.Present {
+			mark = "✗"
+		}
+		fmt.Fprintf(&sb, "%s %s/%s\n", mark, rpa.Namespace, rpa.Name)
+	}
+
+	sb.WriteString("\nLatest Releases:\n")
+	if len(result.Releases) == 0 {
+		sb.WriteString("- none\n")
+	}
+	for _, release := range result.Releases {
+		fmt.Fprintf(&sb, "- %s: %s %s of %s", release.ReleasePlan, release.Name, release.Outcome, release.Snapshot)
+		if release.Message != "" {
+			fmt.Fprintf(&sb, " (%s)", release.Message)
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(result.Errors) > 0 {
+		sb.WriteString("\nCould not fetch:\n")
+		for _, err := range result.Errors {
+			fmt.Fprintf(&sb, "- %s\n", err)
+		}
+	}
+	return sb.String()
+}
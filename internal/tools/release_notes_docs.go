@@ -0,0 +1,290 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// defaultDocsRepository is the product documentation repository release notes are proposed to
+	defaultDocsRepository = "openshift/openshift-docs"
+
+	// defaultDocsBranch is the branch of the documentation repository release notes target
+	defaultDocsBranch = "main"
+
+	// releaseNotesAssembly is the assembly including the release notes module of every version
+	releaseNotesAssembly = "release_notes/op-release-notes.adoc"
+)
+
+// ReleaseNotesDocsTemplate represents the template for the release notes module of the product docs
+const ReleaseNotesDocsTemplate = `// This module is included in the following assembly:
+//
+// * ` + releaseNotesAssembly + `
+
+:_mod-docs-content-type: REFERENCE
+[id="op-release-notes-{{.ID}}_{context}"]
+= Release notes for {pipelines-title} General Availability {{.FullVersion}}
+
+With this update, {pipelines-title} General Availability (GA) {{.FullVersion}} is available on {OCP} {{.MinOCPVersion}} and later versions.
+{{- if .Features}}
+
+[id="new-features-{{.ID}}_{context}"]
+== New features
+{{range .Features}}
+* {{.}}
+{{- end}}
+{{- end}}
+{{- if .Issues}}
+
+[id="fixed-issues-{{.ID}}_{context}"]
+== Fixed issues
+{{range .Issues}}
+* link:https://issues.redhat.com/browse/{{.}}[{{.}}]
+{{- end}}
+{{- end}}
+{{- if .CVEs}}
+
+[id="security-fixes-{{.ID}}_{context}"]
+== Security fixes
+
+This release fixes the following CVEs:
+{{range .CVEs}}
+* link:https://access.redhat.com/security/cve/{{.Key}}[{{.Key}}] in ` + "`{{.Component}}`" + `
+{{- end}}
+{{- end}}
+`
+
+// ReleaseNotesDocsConfig represents the configuration for the release notes docs pull request
+type ReleaseNotesDocsConfig struct {
+	MinorVersion string
+	PatchVersion string
+	CVEs         []CVE
+	Issues       []string
+	Features     []string // Notable features, listed under New features
+	RepoPath     string
+	DryRun       bool
+}
+
+// ReleaseNotesDocsResult represents the structured result of release-notes-docs-pr
+type ReleaseNotesDocsResult struct {
+	Repository string `json:"repository"`
+	Branch     string `json:"branch"`
+	Module     string `json:"module" jsonschema:"Path of the release notes module in the documentation repository"`
+	Assembly   string `json:"assembly"`
+	Content    string `json:"content"`
+	DryRun     bool   `json:"dry_run"`
+	PRURL      string `json:"pr_url,omitempty"`
+}
+
+// releaseNotesDocsData represents the values rendered into the release notes module
+type releaseNotesDocsData struct {
+	ID            string
+	FullVersion   string
+	MinOCPVersion string
+	Features      []string
+	Issues        []string
+	CVEs          []CVE
+}
+
+// docsRepository returns the documentation repository, the branch release
+// notes target and the fork branches are pushed to, empty when pushing to
+// the repository itself
+func docsRepository() (repo, branch, fork string) {
+	config := currentConfig()
+	repo, branch, fork = config.DocsRepository, config.DocsBranch, config.DocsFork
+	if repo == "" {
+		repo = defaultDocsRepository
+	}
+	if branch == "" {
+		branch = defaultDocsBranch
+	}
+	return repo, branch, fork
+}
+
+// releaseNotesModule returns the module path of a version's release notes,
+// following the op-release-notes-<major>-<minor>-<patch> naming of the docs
+func releaseNotesModule(fullVersion string) string {
+	return fmt.Sprintf("modules/op-release-notes-%s.adoc", strings.ReplaceAll(fullVersion, ".", "-"))
+}
+
+// renderReleaseNotesDocs renders the release notes module of a version
+func renderReleaseNotesDocs(config ReleaseNotesDocsConfig, fullVersion string) ([]byte, error) {
+	tmpl, err := parseRegisteredTemplate("release-notes-docs")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse release notes template: %w", err)
+	}
+
+	ocpVersions := append([]string(nil), configuredOCPVersions()...)
+	sort.Slice(ocpVersions, func(i, j int) bool { return ocpVersionLess(ocpVersions[i], ocpVersions[j]) })
+	data := releaseNotesDocsData{
+		ID:          strings.ReplaceAll(fullVersion, ".", "-"),
+		FullVersion: fullVersion,
+		Features:    config.Features,
+		Issues:      config.Issues,
+		CVEs:        config.CVEs,
+	}
+	if len(ocpVersions) > 0 {
+		data.MinOCPVersion = strings.ReplaceAll(ocpVersions[0], "-", ".")
+	}
+
+	var content bytes.Buffer
+	if err := executeTemplate(tmpl, &content, data); err != nil {
+		return nil, err
+	}
+	return content.Bytes(), nil
+}
+
+// includeReleaseNotes adds the include of a module to the release notes
+// assembly, ahead of the previous versions, and reports whether it changed
+func includeReleaseNotes(assembly []byte, module string) ([]byte, bool) {
+	include := fmt.Sprintf("include::%s[leveloffset=+1]", module)
+	if bytes.Contains(assembly, []byte(include)) {
+		return assembly, false
+	}
+
+	lines := strings.SplitAfter(string(assembly), "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, "include::modules/op-release-notes-") {
+			updated := strings.Join(lines[:i], "") + include + "\n\n" + strings.Join(lines[i:], "")
+			return []byte(updated), true
+		}
+	}
+	updated := strings.TrimRight(string(assembly), "\n") + "\n\n" + include + "\n"
+	return []byte(updated), true
+}
+
+func createReleaseNotesDocsPR(ctx context.Context, config ReleaseNotesDocsConfig) (ReleaseNotesDocsResult, error) {
+	_, fullVersion := getReleaseType(config.MinorVersion, config.PatchVersion)
+	repo, baseBranch, fork := docsRepository()
+	result := ReleaseNotesDocsResult{
+		Repository: repo,
+		Branch:     "op-release-notes-" + strings.ReplaceAll(fullVersion, ".", "-"),
+		Module:     releaseNotesModule(fullVersion),
+		Assembly:   releaseNotesAssembly,
+		DryRun:     config.DryRun,
+	}
+
+	content, err := renderReleaseNotesDocs(config, fullVersion)
+	if err != nil {
+		return result, err
+	}
+	result.Content = string(content)
+	if config.DryRun {
+		return result, nil
+	}
+
+	pushRepo := repo
+	if fork != "" {
+		pushRepo = fork
+	}
+	// The documentation repository is large, only its target branch is needed
+	cloneCmd, err := gitCommand(ctx, githubHost, "clone", "--depth", "1", "-b", baseBranch, forgeRepoURL(githubHost, repo), config.RepoPath)
+	if err != nil {
+		return result, err
+	}
+	var cloneStderr bytes.Buffer
+	cloneCmd.Stderr = &cloneStderr
+	if err := runCommand(ctx, cloneCmd); err != nil {
+		return result, fmt.Errorf("failed to clone %s: %v\nError details: %s", repo, err, cloneStderr.String())
+	}
+	runUsageFrom(ctx).recordClone(config.RepoPath)
+
+	if err := createBranchInRepo(ctx, config.RepoPath, result.Branch); err != nil {
+		return result, err
+	}
+	if err := writeFile(ctx, filepath.Join(config.RepoPath, result.Module), content, 0644); err != nil {
+		return result, fmt.Errorf("failed to write %s: %w", result.Module, err)
+	}
+
+	assemblyPath := filepath.Join(config.RepoPath, releaseNotesAssembly)
+	assembly, err := os.ReadFile(assemblyPath)
+	if err != nil {
+		return result, fmt.Errorf("failed to read %s: %w", releaseNotesAssembly, err)
+	}
+	if updated, changed := includeReleaseNotes(assembly, result.Module); changed {
+		if err := writeFile(ctx, assemblyPath, updated, 0644); err != nil {
+			return result, fmt.Errorf("failed to write %s: %w", releaseNotesAssembly, err)
+		}
+	}
+
+	title := fmt.Sprintf("Release notes for %s %s", product().DisplayName, fullVersion)
+	commands := [][]string{
+		{"add", result.Module, releaseNotesAssembly},
+		{"commit", "-m", title},
+	}
+	if fork != "" {
+		commands = append(commands, []string{"remote", "add", "fork", forgeRepoURL(githubHost, fork)})
+	}
+	for _, args := range commands {
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Dir = config.RepoPath
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		if err := runCommand(ctx, cmd); err != nil {
+			return result, fmt.Errorf("git %s failed: %v\nError details: %s", args[0], err, stderr.String())
+		}
+	}
+
+	remote := "origin"
+	if fork != "" {
+		remote = "fork"
+	}
+	pushCmd, err := gitCommand(ctx, githubHost, "push", "-f", remote, result.Branch)
+	if err != nil {
+		return result, err
+	}
+	pushCmd.Dir = config.RepoPath
+	var pushStderr bytes.Buffer
+	pushCmd.Stderr = &pushStderr
+	if err := runCommand(ctx, pushCmd); err != nil {
+		return result, fmt.Errorf("failed to push %s to %s: %v\nError details: %s", result.Branch, pushRepo, err, pushStderr.String())
+	}
+
+	sourceBranch := result.Branch
+	if fork != "" {
+		owner, _, _ := strings.Cut(fork, "/")
+		sourceBranch = owner + ":" + result.Branch
+	}
+	provider, err := vcsProvider(githubHost)
+	if err != nil {
+		return result, err
+	}
+	body, err := renderNotification("release-notes-pr", map[string]any{
+		"Product":  product().DisplayName,
+		"Version":  fullVersion,
+		"Module":   result.Module,
+		"Assembly": releaseNotesAssembly,
+	})
+	if err != nil {
+		return result, err
+	}
+	pr, err := provider.OpenChangeRequest(ctx, repo, ChangeRequest{
+		Title:        title,
+		Body:         body,
+		SourceBranch: sourceBranch,
+		TargetBranch: baseBranch,
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to open pull request: %w", err)
+	}
+	result.PRURL = pr.URL
+	return result, nil
+}
+
+func formatReleaseNotesDocs(result ReleaseNotesDocsResult) string {
+	var sb strings.Builder
+	switch {
+	case result.DryRun:
+		fmt.Fprintf(&sb, "Release notes module %s for %s (dry run, no pull request opened):\n\n", result.Module, result.Repository)
+	default:
+		fmt.Fprintf(&sb, "Opened %s adding %s and including it in %s:\n\n", result.PRURL, result.Module, result.Assembly)
+	}
+	sb.WriteString(result.Content)
+	return sb.String()
+}
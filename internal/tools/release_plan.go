@@ -1,31 +1,96 @@
 package tools
 
 import (
-	"bytes"
+	"context"
 	"fmt"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 	"text/template"
+
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/sync/errgroup"
 )
 
 // ComponentConfig represents a component's configuration
 type ComponentConfig struct {
-	Name       string
-	Repository string
+	Name       string `yaml:"name" json:"name"`
+	Repository string `yaml:"repository" json:"repository"`
 }
 
 // RPAConfig represents the configuration for ReleasePlanAdmission and ReleasePlan creation
 type RPAConfig struct {
-	MinorVersion string
-	PatchVersion string // Optional, if not provided it's a minor release
-	RepoPath     string
-	Components   map[string][]ComponentConfig
-	Environments []string
-	OCPVersions  []string // List of OCP versions for FBC
+	MinorVersion       string
+	PatchVersion       string // Optional, if not provided it's a minor release
+	RepoPath           string
+	Components         map[string][]ComponentConfig
+	Environments       []string
+	OCPVersions        []string          // List of OCP versions for FBC
+	AdvisoryType       string            // Optional, overrides RHEA for minors and RHBA for patches
+	CVEs               []CVE             // Fixed CVEs, required for RHSA
+	Issues             []string          // Fixed JIRA issues (e.g., SRVKP-1234)
+	MRLabels           []string          // Labels of the merge request
+	MRReviewers        []string          // GitLab usernames requested to review the merge request
+	CatalogRevision    string            // release-service-catalog revision of the RPA pipelines (default: production)
+	PinCatalogRevision bool              // Resolve the default revision to its commit SHA
+	Branch             string            // konflux-release-data branch the changes are pushed to, see createPushBranch
+	Update             bool              // Existing release plans were updated for a patch release, see preparePatchRelease
+	AutoRelease        bool              // Let the ReleasePlans release new snapshots automatically
+	RPLabels           map[string]string // Extra labels of the ReleasePlans
+	Title              string            // Commit message and merge request title, generated from the version when empty
+	Description        string            // Merge request description, generated from the release plans when empty
+}
+
+// CVE represents a CVE fixed by a release and the Konflux component it was fixed in
+type CVE struct {
+	Key       string // e.g., CVE-2025-1234
+	Component string // Konflux component name (e.g., tektoncd-core-1.21-controller)
+}
+
+// advisoryTypes are the advisory types release notes can use
+var advisoryTypes = map[string]bool{"RHEA": true, "RHBA": true, "RHSA": true}
+
+// releaseComponents are the OpenShift Pipelines components and their images
+// per application, the components of the built-in product profile
+var releaseComponents = map[string][]ComponentConfig{
+	"cli": {
+		{Name: "tkn", Repository: "pipelines-cli-tkn-rhel9"},
+	},
+	"core": {
+		{Name: "controller", Repository: "pipelines-core-controller-rhel9"},
+		{Name: "webhook", Repository: "pipelines-core-webhook-rhel9"},
+	},
+	"operator": {
+		{Name: "operator", Repository: "pipelines-rhel9-operator"},
+		{Name: "proxy", Repository: "pipelines-operator-proxy-rhel9"},
+		{Name: "webhook", Repository: "pipelines-operator-webhook-rhel9"},
+	},
+	"fbc": {}, // FBC has special handling
+}
+
+// defaultOCPVersions are the OCP versions index images are released for
+var defaultOCPVersions = []string{"4-15", "4-16", "4-17", "4-18", "4-19"}
+
+// configuredOCPVersions returns the default OCP versions, as overridden by the server configuration
+func configuredOCPVersions() []string {
+	if versions := currentConfig().OCPVersions; len(versions) > 0 {
+		return versions
+	}
+	return defaultOCPVersions
+}
+
+// ocpVersionLess compares OCP versions numerically, so 4-9 sorts before 4-16.
+// Versions may be dashed, as in application names, or dotted.
+func ocpVersionLess(a, b string) bool {
+	return minorVersionLess(strings.ReplaceAll(a, "-", "."), strings.ReplaceAll(b, "-", "."))
 }
 
+// releasePlanSteps is the number of steps createReleasePlans reports progress for
+const releasePlanSteps = 6
+
 // getRegistryURL returns the appropriate registry URL based on environment
 func getRegistryURL(env string) string {
 	if env == "stage" {
@@ -44,7 +109,7 @@ func getFBCConfig(env string) map[string]interface{} {
 			"publishingCredentials": "staged-index-fbc-publishing-credentials",
 			"requestTimeoutSeconds": 1500,
 			"buildTimeoutSeconds":   1500,
-			"allowedPackages":       []string{"openshift-pipelines-operator-rh"},
+			"allowedPackages":       fbcAllowedPackages(),
 		}
 	}
 	return map[string]interface{}{
@@ -53,11 +118,12 @@ func getFBCConfig(env string) map[string]interface{} {
 		"publishingCredentials": "fbc-production-publishing-credentials-redhat-prod",
 		"requestTimeoutSeconds": 1500,
 		"buildTimeoutSeconds":   1500,
-		"allowedPackages":       []string{"openshift-pipelines-operator-rh"},
+		"allowedPackages":       fbcAllowedPackages(),
 	}
 }
 
-// getEnvSpecificValues returns environment-specific values
+// getEnvSpecificValues returns environment-specific values, the policies
+// being the ones of the product profile
 func getEnvSpecificValues(env string, isFBC bool) struct {
 	Policy         string
 	Intention      string
@@ -65,6 +131,7 @@ func getEnvSpecificValues(env string, isFBC bool) struct {
 	RegistryURL    string
 	BusinessUnit   string
 } {
+	policies := product().Policies
 	if isFBC {
 		if env == "stage" {
 			return struct {
@@ -74,7 +141,7 @@ func getEnvSpecificValues(env string, isFBC bool) struct {
 				RegistryURL    string
 				BusinessUnit   string
 			}{
-				Policy:         "fbc-tekton-ecosystem-stage",
+				Policy:         policies.FBCStage,
 				Intention:      "staging",
 				ServiceAccount: "release-index-image-staging",
 				RegistryURL:    "registry.stage.redhat.io",
@@ -88,7 +155,7 @@ func getEnvSpecificValues(env string, isFBC bool) struct {
 			RegistryURL    string
 			BusinessUnit   string
 		}{
-			Policy:         "fbc-tekton-ecosystem-prod",
+			Policy:         policies.FBCProd,
 			Intention:      "production",
 			ServiceAccount: "release-index-image-prod",
 			RegistryURL:    "registry.redhat.io",
@@ -104,7 +171,7 @@ func getEnvSpecificValues(env string, isFBC bool) struct {
 			RegistryURL    string
 			BusinessUnit   string
 		}{
-			Policy:         "registry-standard-stage",
+			Policy:         policies.Stage,
 			Intention:      "staging",
 			ServiceAccount: "release-registry-staging",
 			RegistryURL:    "registry.stage.redhat.io",
@@ -118,7 +185,7 @@ func getEnvSpecificValues(env string, isFBC bool) struct {
 		RegistryURL    string
 		BusinessUnit   string
 	}{
-		Policy:         "registry-standard",
+		Policy:         policies.Prod,
 		Intention:      "production",
 		ServiceAccount: "release-registry-prod",
 		RegistryURL:    "registry.redhat.io",
@@ -126,115 +193,11 @@ func getEnvSpecificValues(env string, isFBC bool) struct {
 	}
 }
 
-// RPATemplate represents the template for ReleasePlanAdmission
-const RPATemplate = `apiVersion: appstudio.redhat.com/v1alpha1
-kind: ReleasePlanAdmission
-metadata:
-  labels:
-    release.appstudio.openshift.io/block-releases: "false"
-    pp.engineering.redhat.com/business-unit: {{.EnvConfig.BusinessUnit}}
-  name: {{if .IsFBC}}openshift-pipelines-{{.MinorVersion}}-fbc-{{.Env}}{{else}}openshift-pipelines-{{.Component}}-{{.MinorVersion}}-{{.Env}}{{end}}
-  namespace: rhtap-releng-tenant
-  annotations:
-    rhel_target: el9
-spec:
-{{- if .IsFBC}}
-  applications:
-{{- range .OCPVersions}}
-    - openshift-pipelines-index-{{.}}-{{$.MinorVersion}}
-{{- end}}
-{{- else}}
-  applications: [ openshift-pipelines-{{.Component}}-{{.MinorVersion}} ]
-{{- end}}
-  origin: tekton-ecosystem-tenant
-  policy: {{.EnvConfig.Policy}}
-  data:
-    releaseNotes:
-      product_id: [ 604 ]
-      product_name: "Red Hat OpenShift Pipelines"
-      product_version: {{if .IsFBC}}fbc{{else}}{{.FullVersion}}{{end}}
-{{- if .IsFBC}}
-      references:
-        - "https://docs.redhat.com/en/documentation/red_hat_openshift_pipelines/"
-{{- end}}
-      type: "{{.ReleaseType}}"
-{{- if .IsFBC}}
-    fbc:
-{{- range $key, $value := .FBCConfig}}
-{{- if eq $key "allowedPackages"}}
-      allowedPackages:
-{{- range $value}}
-        - {{.}}
-{{- end}}
-{{- else}}
-      {{$key}}: {{$value}}
-{{- end}}
-{{- end}}
-{{- else}}
-    mapping:
-      components:
-{{- range .SubComponents }}
-        - name: tektoncd-{{$.Component}}-{{$.MinorVersion}}-{{.Name}}
-          repository: "{{$.EnvConfig.RegistryURL}}/openshift-pipelines/{{.Repository}}"
-          pushSourceContainer: true
-{{- end }}
-      defaults:
-        tags:
-          - "{{ "{{" }} git_sha {{ "}}" }}"
-          - "{{ "{{" }} git_short_sha {{ "}}" }}"
-          - "v{{.FullVersion}}"
-          - "v{{.FullVersion}}-{{ "{{" }} timestamp {{ "}}" }}"
-{{- end}}
-    intention: {{.EnvConfig.Intention}}
-  pipeline:
-    serviceAccountName: {{.EnvConfig.ServiceAccount}}
-    timeouts:
-      pipeline: "10h0m0s"
-      tasks: 10h0m0s
-    pipelineRef:
-      resolver: git
-      params:
-        - name: url
-          value: "https://github.com/konflux-ci/release-service-catalog.git"
-        - name: revision
-          value: production
-        - name: pathInRepo
-{{- if .IsFBC}}
-          value: "pipelines/managed/fbc-release/fbc-release.yaml"
-{{- else}}
-          value: "pipelines/managed/rh-advisories/rh-advisories.yaml"
-{{- end}}`
-
-// RPTemplate represents the template for ReleasePlan
-const RPTemplate = `apiVersion: appstudio.redhat.com/v1alpha1
-kind: ReleasePlan
-metadata:
-  labels:
-    release.appstudio.openshift.io/auto-release: "false"
-    release.appstudio.openshift.io/standing-attribution: "true"
-    release.appstudio.openshift.io/releasePlanAdmission: openshift-pipelines-{{.Component}}-{{.MinorVersion}}-{{.Env}}
-  name: openshift-pipelines-{{.Component}}-{{.MinorVersion}}-{{.Env}}-release-as-op
-spec:
-  application: openshift-pipelines-{{.Component}}-{{.MinorVersion}}
-  target: rhtap-releng-tenant
-  data:
-    releaseNotes:
-      references:
-        - "https://docs.redhat.com/en/documentation/red_hat_openshift_pipelines"
-      type: "{{.ReleaseType}}"
-      solution: |
-        Red Hat OpenShift Pipelines is a cloud-native, continuous integration and
-        continuous delivery (CI/CD) solution based on Kubernetes resources.
-        It uses Tekton building blocks to automate deployments across multiple
-        platforms by abstracting away the underlying implementation details.
-        Tekton introduces a number of standard custom resource definitions (CRDs)
-        for defining CI/CD pipelines that are portable across Kubernetes distributions.
-      description: "The {{.FullVersion}} release of Red Hat OpenShift Pipelines {{.Component | title}}."
-      topic: |
-        The {{.FullVersion}} GA release of Red Hat OpenShift Pipelines {{.Component | title}}..
-        For more details see [product documentation](https://docs.redhat.com/en/documentation/red_hat_openshift_pipelines).
-      synopsis: "Red Hat OpenShift Pipelines Release {{.FullVersion}}"
-`
+// indentLines indents every line of a string by a number of spaces
+func indentLines(spaces int, s string) string {
+	prefix := strings.Repeat(" ", spaces)
+	return prefix + strings.ReplaceAll(s, "\n", "\n"+prefix)
+}
 
 // titleCase converts a string to title case
 func titleCase(s string) string {
@@ -248,114 +211,196 @@ func titleCase(s string) string {
 	}
 }
 
-func createReleasePlans(config RPAConfig) error {
-	fmt.Printf("DEBUG: Starting createReleasePlans with config: %+v\n", config)
+// createReleasePlans generates the release plans of a version, pushes them
+// and returns the URL of the merge request opened for them
+func createReleasePlans(ctx context.Context, config RPAConfig) (PushedBranchResult, error) {
+	var result PushedBranchResult
+	slog.DebugContext(ctx, "Creating release plans", "version", config.MinorVersion, "patch", config.PatchVersion, "repo_path", config.RepoPath, "environments", config.Environments, "ocp_versions", config.OCPVersions)
+
+	if config.AdvisoryType != "" && !advisoryTypes[config.AdvisoryType] {
+		return result, fmt.Errorf("invalid advisory type %q, expected RHEA, RHBA or RHSA", config.AdvisoryType)
+	}
+	if config.AdvisoryType == "RHSA" && len(config.CVEs) == 0 {
+		return result, fmt.Errorf("RHSA advisories require at least one CVE")
+	}
+	if err := validateRPLabels(config.RPLabels); err != nil {
+		return result, err
+	}
+
+	// Pin the moving catalog branch to the commit it points to now
+	if config.PinCatalogRevision && config.CatalogRevision == "" {
+		sha, err := resolveCatalogRevision(ctx, releaseServiceCatalogRevision)
+		if err != nil {
+			return result, err
+		}
+		config.CatalogRevision = sha
+	}
 
 	// Clone the konflux-release-data repository
-	if err := cloneKonfluxRepo(config); err != nil {
-		return fmt.Errorf("failed to clone konflux-release-data repository: %w", err)
+	if err := cloneKonfluxRepo(ctx, config); err != nil {
+		return result, fmt.Errorf("failed to clone konflux-release-data repository: %w", err)
 	}
-	fmt.Println("DEBUG: Successfully cloned konflux repo")
+	slog.DebugContext(ctx, "Cloned konflux-release-data", "repo_path", config.RepoPath)
+	reportProgress(ctx, 1, releasePlanSteps, "Cloned konflux-release-data")
 
-	// Create a new branch for changes
-	branchName := fmt.Sprintf("add-release-plans-%s", config.MinorVersion)
-	if err := createBranchInRepo(config.RepoPath, branchName); err != nil {
-		return fmt.Errorf("failed to create branch: %w", err)
+	// Create the branch the changes are pushed to
+	branchName, err := createPushBranch(ctx, config.RepoPath, releasePlanBranch(config.MinorVersion))
+	if err != nil {
+		return result, err
 	}
+	config.Branch = branchName
+	result.BranchName = branchName
 
 	// Create ReleasePlanAdmissions
-	if err := createRPAs(config); err != nil {
-		return fmt.Errorf("failed to create ReleasePlanAdmissions: %w", err)
+	if err := createRPAs(ctx, config); err != nil {
+		return result, fmt.Errorf("failed to create ReleasePlanAdmissions: %w", err)
 	}
-	fmt.Println("DEBUG: Successfully created ReleasePlanAdmissions in konflux repo")
+	slog.DebugContext(ctx, "Created ReleasePlanAdmissions")
+	reportProgress(ctx, 2, releasePlanSteps, "Rendered ReleasePlanAdmissions")
 
 	// Create ReleasePlans
-	if err := createRPs(config); err != nil {
-		return fmt.Errorf("failed to create ReleasePlans: %w", err)
+	if err := createRPs(ctx, config); err != nil {
+		return result, fmt.Errorf("failed to create ReleasePlans: %w", err)
 	}
-	fmt.Println("DEBUG: Successfully created ReleasePlans in konflux repo")
+	slog.DebugContext(ctx, "Created ReleasePlans")
+	reportProgress(ctx, 3, releasePlanSteps, "Rendered ReleasePlans")
 
 	// Update kustomization.yaml
-	if err := updateKustomization(config); err != nil {
-		return fmt.Errorf("failed to update kustomization.yaml: %w", err)
+	if err := updateKustomization(ctx, config); err != nil {
+		return result, fmt.Errorf("failed to update kustomization.yaml: %w", err)
 	}
-	fmt.Println("DEBUG: Successfully updated kustomization.yaml in konflux repo")
+	slog.DebugContext(ctx, "Updated kustomization.yaml")
+	reportProgress(ctx, 4, releasePlanSteps, "Updated kustomization.yaml")
 
 	// Run build-manifests.sh
-	if err := runBuildManifests(config); err != nil {
-		return fmt.Errorf("failed to run build-manifests.sh: %w", err)
+	if err := runBuildManifests(ctx, config); err != nil {
+		return result, fmt.Errorf("failed to run build-manifests.sh: %w", err)
 	}
-	fmt.Println("DEBUG: Successfully ran build-manifests.sh")
+	slog.DebugContext(ctx, "Built manifests")
+	reportProgress(ctx, 5, releasePlanSteps, "Built manifests")
 
 	// Create and push merge request
-	if err := createAndPushMR(config); err != nil {
-		return fmt.Errorf("failed to create and push merge request: %w", err)
+	mrURL, err := createAndPushMR(ctx, config)
+	if err != nil {
+		return result, fmt.Errorf("failed to create and push merge request: %w", err)
 	}
-	fmt.Println("DEBUG: Successfully created and pushed merge request in konflux repo")
+	slog.InfoContext(ctx, "Pushed release plans", "branch", branchName, "merge_request", mrURL)
+	reportProgress(ctx, releasePlanSteps, releasePlanSteps, "Opened "+mrURL)
 
-	return nil
+	result.MergeRequestURL = mrURL
+	return result, nil
 }
 
-func cloneKonfluxRepo(config RPAConfig) error {
-	username := os.Getenv("GITLAB_USERNAME")
-	token := os.Getenv("GITLAB_TOKEN")
-	fmt.Printf("DEBUG: Attempting to clone with username: %s (token length: %d)\n", username, len(token))
-	if username == "" || token == "" {
-		return fmt.Errorf("GITLAB_USERNAME and GITLAB_TOKEN environment variables must be set")
-	}
-
-	repoURL := fmt.Sprintf("https://%s:%s@gitlab.cee.redhat.com/sashture/konflux-release-data.git", username, token)
-	fmt.Printf("DEBUG: Using repo URL: %s\n", strings.Replace(repoURL, token, "[REDACTED]", 1))
-
-	cloneCmd := exec.Command("git", "clone", repoURL, config.RepoPath)
-	fmt.Println("DEBUG: Executing git clone command...")
+// cloneKonfluxRepo clones the konflux-release-data repository, adding the
+// fork as a remote when branches are pushed to one
+func cloneKonfluxRepo(ctx context.Context, config RPAConfig) (err error) {
+	repo, fork := konfluxRepository()
+	ctx, span := startSpan(ctx, "clone konflux-release-data", attribute.String("vcs.repository", repo))
+	defer func() { endSpan(span, err) }()
+	repoURL := forgeRepoURL(gitlabHost, repo)
+	slog.DebugContext(ctx, "Cloning konflux-release-data", "url", repoURL)
 
-	// Capture both stdout and stderr
-	var stdout, stderr bytes.Buffer
-	cloneCmd.Stdout = &stdout
-	cloneCmd.Stderr = &stderr
+	cloneCmd, err := gitCommand(ctx, gitlabHost, "clone", partialCloneFilter, repoURL, config.RepoPath)
+	if err != nil {
+		return err
+	}
 
-	if err := cloneCmd.Run(); err != nil {
-		fmt.Printf("DEBUG: Clone failed with error: %v\n", err)
-		fmt.Printf("DEBUG: Clone stdout: %s\n", stdout.String())
-		fmt.Printf("DEBUG: Clone stderr: %s\n", stderr.String())
+	// The output of the clone is kept in the run log
+	if err := runCommand(ctx, cloneCmd); err != nil {
 		return fmt.Errorf("failed to clone repository: %w", err)
 	}
+	runUsageFrom(ctx).recordClone(config.RepoPath)
 
-	fmt.Println("DEBUG: Clone command completed successfully")
+	if fork != "" {
+		remoteCmd := exec.CommandContext(ctx, "git", "remote", "add", konfluxForkRemote, forgeRepoURL(gitlabHost, fork))
+		remoteCmd.Dir = config.RepoPath
+		if err := runCommand(ctx, remoteCmd); err != nil {
+			return fmt.Errorf("failed to add fork %s: %w", fork, err)
+		}
+	}
 	return nil
 }
 
-func createBranchInRepo(repoPath, branchName string) error {
-	cmd := exec.Command("git", "checkout", "-b", branchName)
+func createBranchInRepo(ctx context.Context, repoPath, branchName string) error {
+	cmd := exec.CommandContext(ctx, "git", "checkout", "-b", branchName)
 	cmd.Dir = repoPath
-	if err := cmd.Run(); err != nil {
+	if err := runCommand(ctx, cmd); err != nil {
 		return fmt.Errorf("failed to create branch: %w", err)
 	}
 	return nil
 }
 
-func createRPAs(config RPAConfig) error {
-	rpaBasePath := filepath.Join(config.RepoPath, "config", "kflux-prd-rh02.0fk9.p1", "product", "ReleasePlanAdmission", "tekton-ecosystem")
+// createPushBranch creates and checks out the branch changes to the
+// konflux-release-data clone at repoPath are pushed to. The branch is named
+// base, with a numeric suffix when a remote branch of that name exists from an
+// earlier run, so reruns never collide with it.
+func createPushBranch(ctx context.Context, repoPath, base string) (string, error) {
+	cmd, err := gitCommand(ctx, gitlabHost, "ls-remote", "--heads", konfluxPushRemote(ctx, repoPath), base, base+"-*")
+	if err != nil {
+		return "", err
+	}
+	cmd.Dir = repoPath
+	output, err := commandOutput(ctx, cmd)
+	if err != nil {
+		return "", fmt.Errorf("failed to list remote branches: %w", err)
+	}
+
+	taken := make(map[string]bool)
+	for _, line := range strings.Split(string(output), "\n") {
+		if _, ref, ok := strings.Cut(line, "\t"); ok {
+			taken[strings.TrimPrefix(ref, "refs/heads/")] = true
+		}
+	}
+	branchName := base
+	for i := 2; taken[branchName]; i++ {
+		branchName = fmt.Sprintf("%s-%d", base, i)
+	}
+	if branchName != base {
+		slog.InfoContext(ctx, "Branch exists on the remote, pushing to another one", "branch", base, "push_branch", branchName)
+	}
+
+	if err := createBranchInRepo(ctx, repoPath, branchName); err != nil {
+		return "", err
+	}
+	return branchName, nil
+}
+
+func createRPAs(ctx context.Context, config RPAConfig) error {
+	rpaBasePath := filepath.Join(config.RepoPath, productRPADir())
 
 	// Create base directory if it doesn't exist
 	if err := os.MkdirAll(rpaBasePath, 0755); err != nil {
 		return fmt.Errorf("failed to create RPA directory: %w", err)
 	}
 
-	tmpl, err := template.New("rpa").Parse(RPATemplate)
+	tmpl, err := parseRegisteredTemplate("rpa")
 	if err != nil {
 		return fmt.Errorf("failed to parse RPA template: %w", err)
 	}
 
 	// Get release type and full version
-	releaseType, fullVersion := getReleaseType(config.MinorVersion, config.PatchVersion)
+	releaseType, fullVersion := releaseNotesType(config)
+	if err := checkProductVersion(fullVersion, false, config.MinorVersion); err != nil {
+		return err
+	}
+
+	catalogRevision := config.CatalogRevision
+	if catalogRevision == "" {
+		catalogRevision = releaseServiceCatalogRevision
+	}
+
+	// Sort a copy so the FBC application list does not depend on the caller's order
+	ocpVersions := append([]string(nil), config.OCPVersions...)
+	sort.Slice(ocpVersions, func(i, j int) bool { return ocpVersionLess(ocpVersions[i], ocpVersions[j]) })
 
 	// Create RPAs for each component and environment
-	for componentName, subComponents := range config.Components {
+	profile := product()
+	var files []manifestFile
+	for _, componentName := range sortedComponentNames(config.Components) {
+		subComponents := config.Components[componentName]
 		isFBC := componentName == "fbc"
 
-		for _, env := range config.Environments {
+		for _, env := range sortedEnvironments(config.Environments) {
 			envConfig := getEnvSpecificValues(env, isFBC)
 
 			data := struct {
@@ -371,106 +416,168 @@ func createRPAs(config RPAConfig) error {
 					RegistryURL    string
 					BusinessUnit   string
 				}
-				IsFBC         bool
-				FBCConfig     map[string]interface{}
-				OCPVersions   []string
-				SubComponents []ComponentConfig
+				Product         ProductProfile
+				IsFBC           bool
+				FBCConfig       map[string]interface{}
+				CatalogRevision string
+				OCPVersions     []string
+				SubComponents   []ComponentConfig
+				CVEs            []CVE
+				Issues          []string
 			}{
-				Component:     componentName,
-				MinorVersion:  config.MinorVersion,
-				FullVersion:   fullVersion,
-				ReleaseType:   releaseType,
-				Env:           env,
-				EnvConfig:     envConfig,
-				IsFBC:         isFBC,
-				FBCConfig:     getFBCConfig(env),
-				OCPVersions:   config.OCPVersions,
-				SubComponents: subComponents,
+				Component:       componentName,
+				MinorVersion:    config.MinorVersion,
+				FullVersion:     fullVersion,
+				ReleaseType:     releaseType,
+				Env:             env,
+				EnvConfig:       envConfig,
+				Product:         profile,
+				IsFBC:           isFBC,
+				FBCConfig:       getFBCConfig(env),
+				CatalogRevision: catalogRevision,
+				OCPVersions:     ocpVersions,
+				SubComponents:   subComponents,
+			}
+			// Index images do not carry the fixes of the components
+			if !isFBC {
+				data.CVEs = componentCVEs(config, componentName)
+				data.Issues = config.Issues
 			}
 
 			var fileName string
 			if isFBC {
-				fileName = fmt.Sprintf("openshift-pipelines-%s-fbc-%s.yaml", config.MinorVersion, env)
+				fileName = productName(config.MinorVersion, "fbc", env) + ".yaml"
 			} else {
-				fileName = fmt.Sprintf("openshift-pipelines-%s-%s-%s.yaml", componentName, config.MinorVersion, env)
-			}
-			filePath := filepath.Join(rpaBasePath, fileName)
-
-			file, err := os.Create(filePath)
-			if err != nil {
-				return fmt.Errorf("failed to create RPA file %s: %w", fileName, err)
-			}
-
-			if err := tmpl.Execute(file, data); err != nil {
-				file.Close()
-				return fmt.Errorf("failed to write RPA template to %s: %w", fileName, err)
+				fileName = productName(componentName, config.MinorVersion, env) + ".yaml"
 			}
-			file.Close()
+			files = append(files, manifestFile{name: fileName, data: data})
 		}
 	}
 
+	if err := renderManifests(ctx, tmpl, rpaBasePath, files); err != nil {
+		return fmt.Errorf("failed to write RPA %w", err)
+	}
 	return nil
 }
 
-func createRPs(config RPAConfig) error {
-	rpBasePath := filepath.Join(config.RepoPath, "tenants-config", "cluster", "kflux-prd-rh02", "tenants", "tekton-ecosystem-tenant")
+func createRPs(ctx context.Context, config RPAConfig) error {
+	rpBasePath := filepath.Join(config.RepoPath, tenantReleasePlanDir())
 
 	// Create base directory if it doesn't exist
 	if err := os.MkdirAll(rpBasePath, 0755); err != nil {
 		return fmt.Errorf("failed to create RP directory: %w", err)
 	}
 
-	// Create template with custom function
-	tmpl := template.New("rp").Funcs(template.FuncMap{
-		"title": titleCase,
-	})
-
-	tmpl, err := tmpl.Parse(RPTemplate)
+	tmpl, err := parseRegisteredTemplate("rp")
 	if err != nil {
 		return fmt.Errorf("failed to parse RP template: %w", err)
 	}
 
 	// Get release type and full version
-	releaseType, fullVersion := getReleaseType(config.MinorVersion, config.PatchVersion)
+	releaseType, fullVersion := releaseNotesType(config)
 
 	// Create RPs for each component and environment
-	for componentName := range config.Components {
-		for _, env := range config.Environments {
+	profile := product()
+	var files []manifestFile
+	for _, componentName := range sortedComponentNames(config.Components) {
+		for _, env := range sortedEnvironments(config.Environments) {
 			data := struct {
 				Component    string
 				MinorVersion string
 				FullVersion  string
 				ReleaseType  string
 				Env          string
+				CVEs         []CVE
+				Issues       []string
+				AutoRelease  bool
+				Labels       map[string]string
+				Product      ProductProfile
 			}{
 				Component:    componentName,
 				MinorVersion: config.MinorVersion,
 				FullVersion:  fullVersion,
 				ReleaseType:  releaseType,
 				Env:          env,
+				CVEs:         componentCVEs(config, componentName),
+				Issues:       config.Issues,
+				AutoRelease:  config.AutoRelease,
+				Labels:       config.RPLabels,
+				Product:      profile,
 			}
 
-			fileName := fmt.Sprintf("openshift-pipelines-%s-%s-%s-release-as-op.yaml", componentName, config.MinorVersion, env)
-			filePath := filepath.Join(rpBasePath, fileName)
+			fileName := productName(componentName, config.MinorVersion, env, "release-as-op") + ".yaml"
+			files = append(files, manifestFile{name: fileName, data: data})
+		}
+	}
 
-			file, err := os.Create(filePath)
-			if err != nil {
-				return fmt.Errorf("failed to create RP file %s: %w", fileName, err)
-			}
+	if err := renderManifests(ctx, tmpl, rpBasePath, files); err != nil {
+		return fmt.Errorf("failed to write RP %w", err)
+	}
+	return nil
+}
 
-			if err := tmpl.Execute(file, data); err != nil {
-				file.Close()
-				return fmt.Errorf("failed to write RP template to %s: %w", fileName, err)
-			}
-			file.Close()
+// manifestFile represents a manifest rendered into a file
+type manifestFile struct {
+	name string
+	data any
+}
+
+// manifestWorkers bounds the number of manifests rendered concurrently
+const manifestWorkers = 8
+
+// renderManifests renders the files into dir concurrently. Every file is
+// rendered whole from its own data, so the output does not depend on the
+// scheduling, and the error of the first failed file in order is returned.
+func renderManifests(ctx context.Context, tmpl *template.Template, dir string, files []manifestFile) (err error) {
+	ctx, span := startSpan(ctx, "render "+tmpl.Name(), attribute.Int("manifests", len(files)))
+	defer func() { endSpan(span, err) }()
+	errs := make([]error, len(files))
+	var g errgroup.Group
+	g.SetLimit(manifestWorkers)
+	for i, file := range files {
+		g.Go(func() error {
+			errs[i] = renderToFile(ctx, tmpl, filepath.Join(dir, file.name), file.data)
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return fmt.Errorf("%s: %w", files[i].name, err)
 		}
 	}
-
 	return nil
 }
 
-func updateKustomization(config RPAConfig) error {
-	kustomizationPath := filepath.Join(config.RepoPath, "tenants-config", "cluster", "kflux-prd-rh02", "tenants", "tekton-ecosystem-tenant", "kustomization.yaml")
+// sortedComponentNames returns the application names of the components in a stable order
+func sortedComponentNames(components map[string][]ComponentConfig) []string {
+	names := make([]string, 0, len(components))
+	for name := range components {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sortedEnvironments returns the environments deduplicated and in a stable order
+func sortedEnvironments(environments []string) []string {
+	seen := make(map[string]bool)
+	var sorted []string
+	for _, env := range environments {
+		if !seen[env] {
+			seen[env] = true
+			sorted = append(sorted, env)
+		}
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+func updateKustomization(ctx context.Context, config RPAConfig) (err error) {
+	ctx, span := startSpan(ctx, "update kustomization")
+	defer func() { endSpan(span, err) }()
+	kustomizationPath := filepath.Join(config.RepoPath, tenantReleasePlanDir(), "kustomization.yaml")
 
 	// Read existing content
 	content, err := os.ReadFile(kustomizationPath)
@@ -480,11 +587,10 @@ func updateKustomization(config RPAConfig) error {
 
 	// Add new resources
 	var newResources []string
-	for componentName := range config.Components {
-		for _, env := range config.Environments {
+	for _, componentName := range sortedComponentNames(config.Components) {
+		for _, env := range sortedEnvironments(config.Environments) {
 			newResources = append(newResources,
-				fmt.Sprintf("  - openshift-pipelines-%s-%s-%s-release-as-op.yaml",
-					componentName, config.MinorVersion, env))
+				"  - "+productName(componentName, config.MinorVersion, env, "release-as-op")+".yaml")
 		}
 	}
 
@@ -508,114 +614,136 @@ func updateKustomization(config RPAConfig) error {
 	}
 
 	// Write back to file
-	if err := os.WriteFile(kustomizationPath, []byte(strings.Join(updated, "\n")), 0644); err != nil {
+	if err := writeFile(ctx, kustomizationPath, []byte(strings.Join(updated, "\n")), 0644); err != nil {
 		return fmt.Errorf("failed to write kustomization.yaml: %w", err)
 	}
 
 	return nil
 }
 
-func runBuildManifests(config RPAConfig) error {
-	fmt.Printf("DEBUG: Changing directory to: %s\n", config.RepoPath)
-	if err := os.Chdir(config.RepoPath); err != nil {
-		fmt.Printf("DEBUG: Failed to change directory: %v\n", err)
-		return fmt.Errorf("failed to change directory to %s: %w", config.RepoPath, err)
-	}
-
-	scriptPath := filepath.Join("tenants-config", "build-manifests.sh")
-	fmt.Printf("DEBUG: Attempting to run build-manifests.sh from path: %s\n", scriptPath)
+func runBuildManifests(ctx context.Context, config RPAConfig) error {
+	// Run the script from the clone without changing the process working
+	// directory, which is shared by concurrent tool calls
+	scriptPath := filepath.Join(config.RepoPath, "tenants-config", "build-manifests.sh")
+	slog.DebugContext(ctx, "Running build-manifests.sh", "path", scriptPath)
 
-	cmd := exec.Command("./" + scriptPath)
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	err := cmd.Run()
-	if err != nil {
-		fmt.Printf("DEBUG: build-manifests.sh failed with error: %v\n", err)
-		fmt.Printf("DEBUG: build-manifests.sh stdout: %s\n", stdout.String())
-		fmt.Printf("DEBUG: build-manifests.sh stderr: %s\n", stderr.String())
+	cmd := exec.CommandContext(ctx, scriptPath)
+	cmd.Dir = config.RepoPath
+	if err := runCommand(ctx, cmd); err != nil {
 		return fmt.Errorf("failed to run build-manifests.sh: %w", err)
 	}
-	fmt.Printf("DEBUG: Successfully ran build-manifests.sh\n")
 	return nil
 }
 
-func createAndPushMR(config RPAConfig) error {
-	fmt.Println("DEBUG: Starting createAndPushMR function")
-
+// createAndPushMR commits the generated manifests, pushes them to the branch of
+// the configuration and opens a merge request against konflux-release-data
+func createAndPushMR(ctx context.Context, config RPAConfig) (string, error) {
 	// Stage all changes
-	fmt.Printf("DEBUG: Staging changes in directory: %s\n", config.RepoPath)
-	stageCmd := exec.Command("git", "add", ".")
+	stageCmd := exec.CommandContext(ctx, "git", "add", ".")
 	stageCmd.Dir = config.RepoPath
-	var stageStdout, stageStderr bytes.Buffer
-	stageCmd.Stdout = &stageStdout
-	stageCmd.Stderr = &stageStderr
-	if err := stageCmd.Run(); err != nil {
-		fmt.Printf("DEBUG: Failed to stage changes. Error: %v\n", err)
-		fmt.Printf("DEBUG: git add stdout: %s\n", stageStdout.String())
-		fmt.Printf("DEBUG: git add stderr: %s\n", stageStderr.String())
-		return fmt.Errorf("failed to stage changes: %w", err)
+	if err := runCommand(ctx, stageCmd); err != nil {
+		return "", fmt.Errorf("failed to stage changes: %w", err)
 	}
-	fmt.Println("DEBUG: Successfully staged changes")
 
 	// Create commit
 	commitMsg := fmt.Sprintf("Add ReleasePlan and ReleasePlanAdmission for v%s", config.MinorVersion)
-	fmt.Printf("DEBUG: Creating commit with message: %s\n", commitMsg)
-	commitCmd := exec.Command("git", "commit", "-m", commitMsg)
+	if config.Update {
+		_, fullVersion := releaseNotesType(config)
+		commitMsg = fmt.Sprintf("Update ReleasePlan and ReleasePlanAdmission for v%s", fullVersion)
+	}
+	if config.Title != "" {
+		commitMsg = config.Title
+	}
+	commitCmd := exec.CommandContext(ctx, "git", "commit", "-m", commitMsg)
 	commitCmd.Dir = config.RepoPath
-	var commitStdout, commitStderr bytes.Buffer
-	commitCmd.Stdout = &commitStdout
-	commitCmd.Stderr = &commitStderr
-	if err := commitCmd.Run(); err != nil {
-		fmt.Printf("DEBUG: Failed to create commit. Error: %v\n", err)
-		fmt.Printf("DEBUG: git commit stdout: %s\n", commitStdout.String())
-		fmt.Printf("DEBUG: git commit stderr: %s\n", commitStderr.String())
-		return fmt.Errorf("failed to commit changes: %w", err)
-	}
-	fmt.Println("DEBUG: Successfully created commit")
-
-	// Create and checkout new branch
-	branchName := fmt.Sprintf("release-plan-v%s", config.MinorVersion)
-	fmt.Printf("DEBUG: Creating and checking out branch: %s\n", branchName)
-	checkoutCmd := exec.Command("git", "checkout", "-b", branchName)
-	checkoutCmd.Dir = config.RepoPath
-	var checkoutStdout, checkoutStderr bytes.Buffer
-	checkoutCmd.Stdout = &checkoutStdout
-	checkoutCmd.Stderr = &checkoutStderr
-	if err := checkoutCmd.Run(); err != nil {
-		fmt.Printf("DEBUG: Failed to create/checkout branch. Error: %v\n", err)
-		fmt.Printf("DEBUG: git checkout stdout: %s\n", checkoutStdout.String())
-		fmt.Printf("DEBUG: git checkout stderr: %s\n", checkoutStderr.String())
-		return fmt.Errorf("failed to create/checkout branch: %w", err)
-	}
-	fmt.Println("DEBUG: Successfully created and checked out branch")
-
-	// Push changes using credentials from environment variables
-	username := os.Getenv("GITLAB_USERNAME")
-	token := os.Getenv("GITLAB_TOKEN")
-	if username == "" || token == "" {
-		return fmt.Errorf("GITLAB_USERNAME and GITLAB_TOKEN environment variables must be set")
-	}
-
-	repoURL := fmt.Sprintf("https://%s:%s@gitlab.cee.redhat.com/sashture/konflux-release-data.git", username, token)
-	fmt.Printf("DEBUG: Pushing to repository with URL: %s\n", strings.Replace(repoURL, token, "[REDACTED]", 1))
-
-	pushCmd := exec.Command("git", "push", "-u", repoURL, branchName)
+	if err := runCommand(ctx, commitCmd); err != nil {
+		return "", fmt.Errorf("failed to commit changes: %w", err)
+	}
+
+	// The branch was created by createPushBranch before the changes were made
+	branchName := config.Branch
+	// Push changes, authenticating through the configured protocol
+	pushCmd, err := gitCommand(ctx, gitlabHost, "push", "-u", konfluxPushRemote(ctx, config.RepoPath), branchName)
+	if err != nil {
+		return "", err
+	}
 	pushCmd.Dir = config.RepoPath
-	var pushStdout, pushStderr bytes.Buffer
-	pushCmd.Stdout = &pushStdout
-	pushCmd.Stderr = &pushStderr
-	if err := pushCmd.Run(); err != nil {
-		fmt.Printf("DEBUG: Failed to push changes. Error: %v\n", err)
-		fmt.Printf("DEBUG: git push stdout: %s\n", pushStdout.String())
-		fmt.Printf("DEBUG: git push stderr: %s\n", pushStderr.String())
-		return fmt.Errorf("failed to push changes: %w", err)
-	}
-	fmt.Println("DEBUG: Successfully pushed changes")
-
-	fmt.Printf("DEBUG: Changes have been pushed to branch '%s'. Please create merge request manually via GitLab UI.\n", branchName)
-	return nil
+	if err := runCommand(ctx, pushCmd); err != nil {
+		return "", fmt.Errorf("failed to push changes: %w", err)
+	}
+
+	provider, err := vcsProvider(gitlabHost)
+	if err != nil {
+		return "", fmt.Errorf("branch %s was pushed, open the merge request manually: %w", branchName, err)
+	}
+	description := config.Description
+	if description == "" {
+		if description, err = mergeRequestDescription(config); err != nil {
+			return "", fmt.Errorf("branch %s was pushed, open the merge request manually: %w", branchName, err)
+		}
+	}
+	repo, _ := konfluxRepository()
+	mrCtx, span := startSpan(ctx, "open merge request", attribute.String("vcs.repository", repo), attribute.String("vcs.branch", branchName))
+	mr, err := provider.OpenChangeRequest(mrCtx, repo, ChangeRequest{
+		Title:        commitMsg,
+		Body:         description,
+		SourceBranch: konfluxSourceBranch(ctx, config.RepoPath, branchName),
+		TargetBranch: "main",
+		Labels:       config.MRLabels,
+		Reviewers:    config.MRReviewers,
+	})
+	endSpan(span, err)
+	if err != nil {
+		return "", fmt.Errorf("branch %s was pushed, open the merge request manually: %w", branchName, err)
+	}
+	slog.DebugContext(ctx, "Opened merge request", "url", mr.URL)
+	return mr.URL, nil
+}
+
+// mergeRequestDescription describes the release plans of a merge request
+// with the release-plan-mr notification template
+func mergeRequestDescription(config RPAConfig) (string, error) {
+	releaseType, fullVersion := releaseNotesType(config)
+	return renderNotification("release-plan-mr", map[string]any{
+		"MinorVersion": config.MinorVersion,
+		"Update":       config.Update,
+		"Version":      fullVersion,
+		"ReleaseType":  releaseType,
+		"Applications": sortedComponentNames(config.Components),
+		"Environments": sortedEnvironments(config.Environments),
+		"CVEs":         config.CVEs,
+		"Issues":       config.Issues,
+	})
+}
+
+// releasePlanBranch returns the konflux-release-data branch release plans are pushed to
+func releasePlanBranch(minorVersion string) string {
+	return fmt.Sprintf("release-plan-v%s", minorVersion)
+}
+
+// releaseNotesType returns the advisory type and full version of a release,
+// honoring the configured advisory type
+func releaseNotesType(config RPAConfig) (string, string) {
+	releaseType, fullVersion := getReleaseType(config.MinorVersion, config.PatchVersion)
+	if config.AdvisoryType != "" {
+		releaseType = config.AdvisoryType
+	}
+	return releaseType, fullVersion
+}
+
+// componentCVEs returns the CVEs fixed in the Konflux components of an application
+func componentCVEs(config RPAConfig, componentName string) []CVE {
+	names := make(map[string]bool)
+	for _, subComponent := range config.Components[componentName] {
+		names[fmt.Sprintf("%s-%s-%s-%s", product().ComponentPrefix, componentName, config.MinorVersion, subComponent.Name)] = true
+	}
+	var cves []CVE
+	for _, cve := range config.CVEs {
+		if names[cve.Component] {
+			cves = append(cves, cve)
+		}
+	}
+	return cves
 }
 
 func getReleaseType(minorVersion, patchVersion string) (string, string) {
@@ -0,0 +1,190 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// runbookURLEnv is the environment variable holding the default release runbook link
+	runbookURLEnv = "RELEASE_MCP_RUNBOOK_URL"
+
+	releaseReadmeFile = "RELEASE.md"
+)
+
+// ReleaseReadmeTemplate represents the template for the RELEASE.md provenance stamp
+const ReleaseReadmeTemplate = `# Release {{.Branch}}
+
+This branch was cut for the {{.Product}} {{.MinorVersion}} release.
+
+| | |
+|---|---|
+| Cut date | {{.CutDate}} |
+| Source branch | ` + "`{{.SourceBranch}}`" + ` |
+| Source commit | ` + "`{{.SourceSHA}}`" + ` |
+{{- if .Upstream}}
+| Upstream | ` + "`{{.Upstream}}`" + ` |
+{{- end}}
+{{- if .RunbookURL}}
+
+See the [release runbook]({{.RunbookURL}}) for the release process.
+{{- end}}
+`
+
+// ReleaseReadmeConfig represents the configuration for stamping release branches
+type ReleaseReadmeConfig struct {
+	MinorVersion     string
+	WorkDir          string
+	UpstreamVersions map[string]string // map of repository name to upstream version
+	RunbookURL       string
+	CutDate          string // YYYY-MM-DD, defaults to today
+	Overwrite        bool   // Replace an existing RELEASE.md instead of keeping it
+}
+
+// ReleaseReadmeResult represents the structured result of generate-release-branch-readme
+type ReleaseReadmeResult struct {
+	Branch      string   `json:"branch"`
+	Stamped     []string `json:"stamped,omitempty" jsonschema:"Repositories RELEASE.md was committed to"`
+	Unchanged   []string `json:"unchanged,omitempty" jsonschema:"Repositories whose RELEASE.md was kept as is"`
+	FailedRepos []string `json:"failed_repos,omitempty"`
+}
+
+// releaseReadmeData represents the values rendered into RELEASE.md
+type releaseReadmeData struct {
+	Product      string
+	Branch       string
+	MinorVersion string
+	CutDate      string
+	SourceBranch string
+	SourceSHA    string
+	Upstream     string
+	RunbookURL   string
+}
+
+func stampReleaseBranches(ctx context.Context, config ReleaseReadmeConfig) (ReleaseReadmeResult, error) {
+	result := ReleaseReadmeResult{Branch: fmt.Sprintf("release-v%s.x", config.MinorVersion)}
+	if config.MinorVersion == "" {
+		return result, fmt.Errorf("minor version is required")
+	}
+	if config.RunbookURL == "" {
+		config.RunbookURL = os.Getenv(runbookURLEnv)
+	}
+	if config.CutDate == "" {
+		config.CutDate = time.Now().Format("2006-01-02")
+	}
+	if len(config.UpstreamVersions) == 0 {
+		pins, err := fetchPinnedUpstreamVersions(ctx, config.MinorVersion)
+		if err != nil {
+			return result, fmt.Errorf("failed to read upstream versions from %s: %w", versionsManifestPath, err)
+		}
+		config.UpstreamVersions = pins
+	}
+
+	for _, repo := range releaseRepositories() {
+		if repo.Skip {
+			continue
+		}
+		changed, err := stampReleaseBranch(ctx, repo, result.Branch, config)
+		if err != nil {
+			result.FailedRepos = append(result.FailedRepos, repo.Name)
+			return result, fmt.Errorf("failed to stamp %s: %w", repo.Name, err)
+		}
+		if changed {
+			result.Stamped = append(result.Stamped, repo.Name)
+		} else {
+			result.Unchanged = append(result.Unchanged, repo.Name)
+		}
+	}
+	return result, nil
+}
+
+// stampReleaseBranch commits RELEASE.md onto the release branch of a
+// repository and reports whether it changed
+func stampReleaseBranch(ctx context.Context, repo Repository, branch string, config ReleaseReadmeConfig) (bool, error) {
+	repoDir := filepath.Join(config.WorkDir, repo.Name)
+	cloneCmd, err := gitCommand(ctx, githubHost, "clone", partialCloneFilter, "--single-branch", "-b", branch, repo.RepoURL, repoDir)
+	if err != nil {
+		return false, err
+	}
+	var cloneStderr bytes.Buffer
+	cloneCmd.Stderr = &cloneStderr
+	if err := runCommand(ctx, cloneCmd); err != nil {
+		return false, fmt.Errorf("failed to clone %s: %v\nError details: %s", branch, err, cloneStderr.String())
+	}
+	runUsageFrom(ctx).recordClone(repoDir)
+
+	// Only the history of the source branch is needed to find the branch
+	// point, not its contents nor the other branches
+	sourceRef := fmt.Sprintf("+refs/heads/%s:refs/remotes/origin/%s", repo.SourceBranch, repo.SourceBranch)
+	if _, err := gitFetchingIn(ctx, githubHost, repoDir, "fetch", "origin", sourceRef); err != nil {
+		return false, fmt.Errorf("failed to fetch %s: %w", repo.SourceBranch, err)
+	}
+
+	// The branch point is the newest commit shared with the source branch
+	mergeBaseCmd := exec.CommandContext(ctx, "git", "merge-base", "HEAD", "origin/"+repo.SourceBranch)
+	mergeBaseCmd.Dir = repoDir
+	sha, err := commandOutput(ctx, mergeBaseCmd)
+	if err != nil {
+		return false, fmt.Errorf("failed to find branch point from %s: %w", repo.SourceBranch, err)
+	}
+
+	// Keep the original stamp so re-runs do not rewrite the provenance
+	readmePath := filepath.Join(repoDir, releaseReadmeFile)
+	if _, err := os.Stat(readmePath); err == nil && !config.Overwrite {
+		return false, nil
+	}
+
+	tmpl, err := parseRegisteredTemplate("release-readme")
+	if err != nil {
+		return false, fmt.Errorf("failed to parse RELEASE.md template: %w", err)
+	}
+	repoName := strings.TrimSuffix(path.Base(repo.RepoURL), ".git")
+	data := releaseReadmeData{
+		Product:      product().DisplayName,
+		Branch:       branch,
+		MinorVersion: config.MinorVersion,
+		CutDate:      config.CutDate,
+		SourceBranch: repo.SourceBranch,
+		SourceSHA:    strings.TrimSpace(string(sha)),
+		Upstream:     config.UpstreamVersions[repoName],
+		RunbookURL:   config.RunbookURL,
+	}
+	if err := renderToFile(ctx, tmpl, readmePath, data); err != nil {
+		return false, fmt.Errorf("failed to write %s: %w", releaseReadmeFile, err)
+	}
+
+	changed, err := hasWorkingTreeChanges(ctx, repoDir)
+	if err != nil || !changed {
+		return false, err
+	}
+
+	commitMsg := fmt.Sprintf("Add %s for %s", releaseReadmeFile, branch)
+	for _, args := range [][]string{{"add", releaseReadmeFile}, {"commit", "-m", commitMsg}} {
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Dir = repoDir
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		if err := runCommand(ctx, cmd); err != nil {
+			return false, fmt.Errorf("git %s failed: %v\nError details: %s", args[0], err, stderr.String())
+		}
+	}
+
+	pushCmd, err := gitCommand(ctx, githubHost, "push", "origin", branch)
+	if err != nil {
+		return false, err
+	}
+	pushCmd.Dir = repoDir
+	var pushStderr bytes.Buffer
+	pushCmd.Stderr = &pushStderr
+	if err := runCommand(ctx, pushCmd); err != nil {
+		return false, fmt.Errorf("failed to push %s: %v\nError details: %s", branch, err, pushStderr.String())
+	}
+	return true, nil
+}
@@ -0,0 +1,233 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Points added to the risk score of a component, out of 100, per signal
+const (
+	riskLateCommit     = 5 // Per commit merged into the release branch after code freeze
+	maxLateDriftRisk   = 30
+	riskFailingBuild   = 30
+	riskPendingBuild   = 10
+	riskBlocker        = 25 // Per open release blocker
+	maxBlockerRisk     = 50
+	riskCVE            = 10 // Per CVE still to be fixed
+	maxCVERisk         = 30
+	riskMissingSignOff = 20
+)
+
+// Risk levels by score
+const (
+	mediumRiskScore = 25
+	highRiskScore   = 50
+)
+
+// ReleaseRiskConfig represents the configuration for assessing the risk of a release
+type ReleaseRiskConfig struct {
+	MinorVersion string
+	GitHubOrgs   []string
+	JiraProjects []string
+	CVEs         []CVE    // Component is the release repository name
+	SignOffs     []string // Repositories signed off, nil when sign-offs are not tracked
+}
+
+// RiskSignal represents a signal contributing to a risk score
+type RiskSignal struct {
+	Signal string `json:"signal" jsonschema:"late-drift, build, blockers, cves or sign-off"`
+	Points int    `json:"points"`
+	Detail string `json:"detail"`
+}
+
+// ComponentRisk represents the risk of a release repository
+type ComponentRisk struct {
+	Component  string       `json:"component"`
+	Repository string       `json:"repository"`
+	Score      int          `json:"score" jsonschema:"Risk score from 0 to 100"`
+	Level      string       `json:"level" jsonschema:"low, medium or high"`
+	Signals    []RiskSignal `json:"signals,omitempty"`
+}
+
+// ReleaseRiskResult represents the structured result of release-risk-report
+type ReleaseRiskResult struct {
+	Version        string          `json:"version"`
+	Score          int             `json:"score" jsonschema:"Risk score of the release: the highest component score plus release-wide signals, up to 100"`
+	Level          string          `json:"level" jsonschema:"low, medium or high"`
+	Recommendation string          `json:"recommendation"`
+	ReleaseSignals []RiskSignal    `json:"release_signals,omitempty" jsonschema:"Signals not tied to a component, such as JIRA blockers"`
+	Components     []ComponentRisk `json:"components"`
+}
+
+// assessReleaseRisk scores every release repository from the commits merged
+// into its release branch after code freeze, the CI state of the branch, its
+// open release blockers, the CVEs still to fix and missing sign-offs
+func assessReleaseRisk(ctx context.Context, config ReleaseRiskConfig) (ReleaseRiskResult, error) {
+	result := ReleaseRiskResult{Version: config.MinorVersion}
+
+	gh, err := newGitHubClient()
+	if err != nil {
+		return result, err
+	}
+	provider, err := vcsProvider(githubHost)
+	if err != nil {
+		return result, err
+	}
+	calendar, err := loadReleaseCalendar()
+	if err != nil {
+		return result, err
+	}
+	var codeFreeze time.Time
+	if calendar != nil && calendar.Releases[config.MinorVersion].CodeFreeze != "" {
+		if codeFreeze, err = time.Parse("2006-01-02", calendar.Releases[config.MinorVersion].CodeFreeze); err != nil {
+			return result, fmt.Errorf("invalid code freeze date for %s: %w", config.MinorVersion, err)
+		}
+	}
+
+	blockers, err := findBlockingIssues(ctx, BlockingIssuesConfig{
+		Version:      config.MinorVersion,
+		GitHubOrgs:   config.GitHubOrgs,
+		JiraProjects: config.JiraProjects,
+	})
+	if err != nil {
+		return result, err
+	}
+	repoBlockers := make(map[string][]string)
+	var releaseBlockers []string
+	for _, issue := range blockers {
+		if repo, _, ok := strings.Cut(issue.Key, "#"); ok && issue.Source == "github" {
+			repoBlockers[repo] = append(repoBlockers[repo], issue.Key)
+		} else {
+			releaseBlockers = append(releaseBlockers, issue.Key)
+		}
+	}
+	if len(releaseBlockers) > 0 {
+		result.ReleaseSignals = append(result.ReleaseSignals, RiskSignal{
+			Signal: "blockers",
+			Points: min(len(releaseBlockers)*riskBlocker, maxBlockerRisk),
+			Detail: fmt.Sprintf("%d open release blockers: %s", len(releaseBlockers), strings.Join(releaseBlockers, ", ")),
+		})
+	}
+
+	if codeFreeze.IsZero() {
+		result.ReleaseSignals = append(result.ReleaseSignals, RiskSignal{Signal: "late-drift", Detail: "not assessed, the release calendar has no code freeze date for " + config.MinorVersion})
+	}
+
+	signedOff := make(map[string]bool)
+	for _, name := range config.SignOffs {
+		signedOff[name] = true
+	}
+	branch := fmt.Sprintf("release-v%s.x", config.MinorVersion)
+
+	for _, repo := range releaseRepositories() {
+		if repo.Skip {
+			continue
+		}
+		component := ComponentRisk{Component: repo.Name, Repository: forgeRepoPath(repo.RepoURL)}
+		add := func(signal string, points int, detail string) {
+			component.Signals = append(component.Signals, RiskSignal{Signal: signal, Points: points, Detail: detail})
+			component.Score += points
+		}
+
+		// Late branch drift
+		if !codeFreeze.IsZero() {
+			if commits, err := commitsSince(ctx, gh, component.Repository, branch, codeFreeze); err != nil {
+				add("late-drift", 0, fmt.Sprintf("unavailable: %v", err))
+			} else if commits > 0 {
+				add("late-drift", min(commits*riskLateCommit, maxLateDriftRisk), fmt.Sprintf("%d commits merged into %s after code freeze", commits, branch))
+			}
+		}
+
+		// CI state of the release branch
+		switch status, err := provider.PipelineStatus(ctx, component.Repository, branch); {
+		case err != nil:
+			add("build", 0, fmt.Sprintf("unavailable: %v", err))
+		case status == pipelineFailure:
+			add("build", riskFailingBuild, branch+" is failing")
+		case status == pipelinePending:
+			add("build", riskPendingBuild, branch+" has no successful build yet")
+		}
+
+		if keys := repoBlockers[component.Repository]; len(keys) > 0 {
+			add("blockers", min(len(keys)*riskBlocker, maxBlockerRisk), fmt.Sprintf("%d open release blockers: %s", len(keys), strings.Join(keys, ", ")))
+		}
+
+		var cves []string
+		for _, cve := range config.CVEs {
+			if cve.Component == repo.Name {
+				cves = append(cves, cve.Key)
+			}
+		}
+		if len(cves) > 0 {
+			add("cves", min(len(cves)*riskCVE, maxCVERisk), fmt.Sprintf("%d CVEs to fix: %s", len(cves), strings.Join(cves, ", ")))
+		}
+
+		if config.SignOffs != nil && !signedOff[repo.Name] {
+			add("sign-off", riskMissingSignOff, "not signed off")
+		}
+
+		component.Score = min(component.Score, 100)
+		component.Level = riskLevel(component.Score)
+		result.Components = append(result.Components, component)
+		result.Score = max(result.Score, component.Score)
+	}
+
+	sort.SliceStable(result.Components, func(i, j int) bool { return result.Components[i].Score > result.Components[j].Score })
+	for _, signal := range result.ReleaseSignals {
+		result.Score += signal.Points
+	}
+	result.Score = min(result.Score, 100)
+	result.Level = riskLevel(result.Score)
+	switch result.Level {
+	case "high":
+		result.Recommendation = "Consider slipping the release date until the high risk components are addressed"
+	case "medium":
+		result.Recommendation = "Review the signals of the medium risk components before confirming the release date"
+	default:
+		result.Recommendation = "The release is on track"
+	}
+	return result, nil
+}
+
+// commitsSince counts the commits of branch since a date, up to 100
+func commitsSince(ctx context.Context, gh *githubClient, repo, branch string, since time.Time) (int, error) {
+	var commits []struct {
+		SHA string `json:"sha"`
+	}
+	apiPath := fmt.Sprintf("/repos/%s/commits?per_page=100&sha=%s&since=%s", repo, url.QueryEscape(branch), url.QueryEscape(since.Format(time.RFC3339)))
+	if err := gh.do(ctx, http.MethodGet, apiPath, nil, &commits); err != nil {
+		return 0, fmt.Errorf("failed to list commits of %s: %w", repo, err)
+	}
+	return len(commits), nil
+}
+
+func riskLevel(score int) string {
+	switch {
+	case score >= highRiskScore:
+		return "high"
+	case score >= mediumRiskScore:
+		return "medium"
+	default:
+		return "low"
+	}
+}
+
+func formatReleaseRisk(result ReleaseRiskResult) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Release %s risk: %d/100 (%s)\n%s\n", result.Version, result.Score, result.Level, result.Recommendation)
+	for _, signal := range result.ReleaseSignals {
+		fmt.Fprintf(&sb, "- %s (+%d): %s\n", signal.Signal, signal.Points, signal.Detail)
+	}
+	for _, component := range result.Components {
+		fmt.Fprintf(&sb, "\n%s: %d/100 (%s)\n", component.Component, component.Score, component.Level)
+		for _, signal := range component.Signals {
+			fmt.Fprintf(&sb, "- %s (+%d): %s\n", signal.Signal, signal.Points, signal.Detail)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,247 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+)
+
+// releaseGVR identifies the Konflux Release resource
+var releaseGVR = schema.GroupVersionResource{
+	Group:    "appstudio.redhat.com",
+	Version:  "v1alpha1",
+	Resource: "releases",
+}
+
+// releasedCondition is the Release condition reporting the outcome of the release
+const releasedCondition = "Released"
+
+// defaultReleaseTimeout bounds how long a triggered release is watched
+const defaultReleaseTimeout = 2 * time.Hour
+
+// TriggerReleaseConfig represents the configuration for triggering a Release
+type TriggerReleaseConfig struct {
+	Snapshot    string
+	ReleasePlan string
+	Namespace   string        // Defaults to the tenant namespace
+	Timeout     time.Duration // Defaults to defaultReleaseTimeout
+	RepoPath    string        // Where konflux-release-data is cloned to check the live resources against git
+	AllowDrift  bool          // Release even though the live resources differ from git
+}
+
+// ReleaseCondition represents a status condition of a Release
+type ReleaseCondition struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// TriggerReleaseResult represents the structured result of trigger-release
+type TriggerReleaseResult struct {
+	Release     string             `json:"release" jsonschema:"Name of the created Release"`
+	Namespace   string             `json:"namespace"`
+	Snapshot    string             `json:"snapshot"`
+	ReleasePlan string             `json:"release_plan"`
+	Outcome     string             `json:"outcome" jsonschema:"succeeded, failed, timed out, or refused when the live resources differ from git"`
+	Conditions  []ReleaseCondition `json:"conditions,omitempty"`
+	Drift       *LiveDriftResult   `json:"drift,omitempty" jsonschema:"Comparison of the live ReleasePlan and ReleasePlanAdmission with git, run before creating the Release"`
+}
+
+// triggerRelease checks that the ReleasePlan and its ReleasePlanAdmission on
+// the cluster match konflux-release-data, refusing to release when they
+// differ unless allowed. It then creates a Release of a snapshot through the
+// ReleasePlan and watches it until its Released condition reports the
+// outcome, reporting every condition change as progress.
+func triggerRelease(ctx context.Context, config TriggerReleaseConfig) (TriggerReleaseResult, error) {
+	if config.Namespace == "" {
+		config.Namespace = tenantNamespace()
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaultReleaseTimeout
+	}
+	result := TriggerReleaseResult{Namespace: config.Namespace, Snapshot: config.Snapshot, ReleasePlan: config.ReleasePlan}
+	if config.Snapshot == "" || config.ReleasePlan == "" {
+		return result, fmt.Errorf("snapshot and release plan are required")
+	}
+
+	drift, err := diffLiveReleaseResources(ctx, LiveDriftConfig{
+		RepoPath:    config.RepoPath,
+		ReleasePlan: config.ReleasePlan,
+		Namespace:   config.Namespace,
+		AllowDrift:  config.AllowDrift,
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to compare the live release resources with git: %w", err)
+	}
+	result.Drift = &drift
+	if !drift.Proceed {
+		result.Outcome = "refused"
+		return result, nil
+	}
+
+	dc, err := dynamicClient(ctx)
+	if err != nil {
+		return result, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+	client := dc.Resource(releaseGVR).Namespace(config.Namespace)
+
+	release := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": releaseGVR.GroupVersion().String(),
+		"kind":       "Release",
+		"metadata": map[string]any{
+			"generateName": config.ReleasePlan + "-",
+			"namespace":    config.Namespace,
+		},
+		"spec": map[string]any{
+			"snapshot":    config.Snapshot,
+			"releasePlan": config.ReleasePlan,
+		},
+	}}
+	stampManagedBy(release)
+	created, err := client.Create(ctx, release, metav1.CreateOptions{})
+	if err != nil {
+		return result, fmt.Errorf("failed to create Release: %w", err)
+	}
+	result.Release = created.GetName()
+	slog.InfoContext(ctx, "Created Release", "namespace", config.Namespace, "release", result.Release)
+	reportProgress(ctx, 1, 0, fmt.Sprintf("Created Release %s", result.Release))
+	_ = publish(ctx, Event{Type: EventReleaseTriggered, Data: result})
+
+	watchCtx, cancel := context.WithTimeout(ctx, config.Timeout)
+	defer cancel()
+
+	seen := map[string]string{}
+	step := 1.0
+	current := created
+	for {
+		conditions, done := releaseConditions(current)
+		result.Conditions = conditions
+		for _, condition := range conditions {
+			state := condition.Status + "/" + condition.Reason
+			if seen[condition.Type] == state {
+				continue
+			}
+			seen[condition.Type] = state
+			step++
+			message := fmt.Sprintf("%s: %s %s", condition.Type, condition.Status, condition.Reason)
+			if condition.Message != "" {
+				message += " (" + condition.Message + ")"
+			}
+			reportProgress(ctx, step, 0, message)
+		}
+		if done != "" {
+			result.Outcome = done
+			return result, nil
+		}
+
+		current, err = nextReleaseState(watchCtx, client, current)
+		if errors.Is(err, context.DeadlineExceeded) {
+			result.Outcome = "timed out"
+			return result, nil
+		}
+		if err != nil {
+			return result, err
+		}
+	}
+}
+
+// nextReleaseState waits for the Release to change, re-establishing the
+// watch when the API server closes it
+func nextReleaseState(ctx context.Context, client dynamic.ResourceInterface, release *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	for {
+		w, err := client.Watch(ctx, metav1.ListOptions{
+			FieldSelector:   fields.OneTermEqualSelector("metadata.name", release.GetName()).String(),
+			ResourceVersion: release.GetResourceVersion(),
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, fmt.Errorf("failed to watch Release %s: %w", release.GetName(), err)
+		}
+
+		for event := range w.ResultChan() {
+			switch event.Type {
+			case watch.Added, watch.Modified:
+				if obj, ok := event.Object.(*unstructured.Unstructured); ok {
+					w.Stop()
+					return obj, nil
+				}
+			case watch.Deleted:
+				w.Stop()
+				return nil, fmt.Errorf("release %s was deleted", release.GetName())
+			case watch.Error:
+				// The resource version expired, continue from the current state
+				w.Stop()
+				return client.Get(ctx, release.GetName(), metav1.GetOptions{})
+			}
+		}
+		w.Stop()
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// releaseConditions returns the status conditions of a Release, and its
+// outcome once the Released condition is no longer progressing
+func releaseConditions(release *unstructured.Unstructured) ([]ReleaseCondition, string) {
+	items, _, _ := unstructured.NestedSlice(release.Object, "status", "conditions")
+	var conditions []ReleaseCondition
+	outcome := ""
+	for _, item := range items {
+		values, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		condition := ReleaseCondition{}
+		condition.Type, _ = values["type"].(string)
+		condition.Status, _ = values["status"].(string)
+		condition.Reason, _ = values["reason"].(string)
+		condition.Message, _ = values["message"].(string)
+		conditions = append(conditions, condition)
+
+		if condition.Type != releasedCondition {
+			continue
+		}
+		switch {
+		case condition.Status == string(metav1.ConditionTrue):
+			outcome = "succeeded"
+		case condition.Status == string(metav1.ConditionFalse) && condition.Reason != "Progressing":
+			outcome = "failed"
+		}
+	}
+	return conditions, outcome
+}
+
+func formatTriggerRelease(result TriggerReleaseResult) string {
+	var sb strings.Builder
+	if result.Drift != nil && !result.Drift.InSync {
+		sb.WriteString(formatLiveDrift(*result.Drift))
+		sb.WriteString("\n")
+	}
+	if result.Outcome == "refused" {
+		fmt.Fprintf(&sb, "No Release of snapshot %s was created through %s\n", result.Snapshot, result.ReleasePlan)
+		return sb.String()
+	}
+	fmt.Fprintf(&sb, "Release %s/%s of snapshot %s through %s %s\n", result.Namespace, result.Release, result.Snapshot, result.ReleasePlan, result.Outcome)
+	for _, condition := range result.Conditions {
+		fmt.Fprintf(&sb, "- %s: %s %s", condition.Type, condition.Status, condition.Reason)
+		if condition.Message != "" {
+			fmt.Fprintf(&sb, " (%s)", condition.Message)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
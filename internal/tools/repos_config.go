@@ -0,0 +1,111 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// reposConfigMapKey is the key of the repository list in a ConfigMap
+const reposConfigMapKey = "repositories.yaml"
+
+// reposConfig represents a repository list kept outside of the server
+// configuration. JSON files are read as YAML.
+type reposConfig struct {
+	Repositories []Repository `yaml:"repositories" json:"repositories"`
+}
+
+// externalRepositories holds the repository list loaded at startup
+var (
+	externalRepositories       []Repository
+	externalRepositoriesSource string
+	externalRepositoriesMu     sync.RWMutex
+)
+
+// LoadRepositories loads the repositories release branches are cut in from a
+// YAML or JSON file, or from the repositories.yaml key of a ConfigMap given as
+// namespace/name. The list replaces the built-in one; the repositories of the
+// live configuration file still take precedence over it.
+func LoadRepositories(ctx context.Context, path, configMap string) error {
+	var content []byte
+	var source string
+	switch {
+	case path != "" && configMap != "":
+		return fmt.Errorf("only one of a repositories file and ConfigMap can be set")
+	case path != "":
+		var err error
+		if content, err = os.ReadFile(path); err != nil {
+			return fmt.Errorf("failed to read repositories %s: %w", path, err)
+		}
+		source = path
+	case configMap != "":
+		namespace, name, ok := strings.Cut(configMap, "/")
+		if !ok || namespace == "" || name == "" {
+			return fmt.Errorf("invalid repositories ConfigMap %q, expected namespace/name", configMap)
+		}
+		kc, err := kubeClient(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to read repositories ConfigMap %s: %w", configMap, err)
+		}
+		cm, err := kc.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to get repositories ConfigMap %s: %w", configMap, err)
+		}
+		data, ok := cm.Data[reposConfigMapKey]
+		if !ok {
+			return fmt.Errorf("ConfigMap %s has no %s key", configMap, reposConfigMapKey)
+		}
+		content = []byte(data)
+		source = "configmap/" + configMap
+	default:
+		return nil
+	}
+
+	var config reposConfig
+	if err := yaml.Unmarshal(content, &config); err != nil {
+		return fmt.Errorf("failed to parse repositories %s: %w", source, err)
+	}
+	if len(config.Repositories) == 0 {
+		return fmt.Errorf("no repositories in %s", source)
+	}
+	if err := validateRepositories(config.Repositories); err != nil {
+		return fmt.Errorf("invalid repositories %s: %w", source, err)
+	}
+
+	externalRepositoriesMu.Lock()
+	defer externalRepositoriesMu.Unlock()
+	externalRepositories = config.Repositories
+	externalRepositoriesSource = source
+	return nil
+}
+
+// validateRepositories checks that every repository that is not skipped can be branched
+func validateRepositories(repos []Repository) error {
+	for _, repo := range repos {
+		if repo.Name == "" || (!repo.Skip && (repo.RepoURL == "" || repo.SourceBranch == "")) {
+			return fmt.Errorf("repository %q: name, repo_url and source_branch are required", repo.Name)
+		}
+	}
+	return nil
+}
+
+// repositoriesSource returns where the repositories of releaseRepositories come from
+func repositoriesSource() string {
+	if len(currentConfig().Repositories) > 0 {
+		return "live configuration"
+	}
+	if name, profile := currentProduct(); len(profile.Repositories) > 0 {
+		return "product " + name
+	}
+	externalRepositoriesMu.RLock()
+	defer externalRepositoriesMu.RUnlock()
+	if len(externalRepositories) > 0 {
+		return externalRepositoriesSource
+	}
+	return "built-in"
+}
@@ -0,0 +1,192 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// retryPolicy represents how often and how patiently network operations are retried
+type retryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// networkRetryPolicy is the policy of clones, fetches, pushes and API calls
+var networkRetryPolicy = retryPolicy{
+	MaxAttempts: 4,
+	BaseDelay:   time.Second,
+	MaxDelay:    30 * time.Second,
+}
+
+// delay returns the wait before the given retry, starting at 1: the base
+// delay doubled for every retry and capped, of which a random half is
+// waited so that parallel clones of a flaky forge do not retry in lockstep
+func (p retryPolicy) delay(retry int) time.Duration {
+	backoff := p.BaseDelay << min(retry-1, 16)
+	if backoff <= 0 || backoff > p.MaxDelay {
+		backoff = p.MaxDelay
+	}
+	half := backoff / 2
+	return half + rand.N(half+1)
+}
+
+// wait blocks for the delay of the given retry, or until the context is done
+func (p retryPolicy) wait(ctx context.Context, retry int) error {
+	return sleepContext(ctx, p.delay(retry))
+}
+
+// sleepContext blocks for d, or until the context is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// RetriedOperation represents a network operation of a tool call that needed more than one attempt
+type RetriedOperation struct {
+	Operation string `json:"operation" jsonschema:"Operation retried, e.g. git clone or GET api.github.com"`
+	Attempts  int    `json:"attempts"`
+	Succeeded bool   `json:"succeeded"`
+	LastError string `json:"last_error,omitempty" jsonschema:"Error of the last failed attempt"`
+}
+
+// gitNetworkSubcommands are the git subcommands talking to a remote
+var gitNetworkSubcommands = []string{"clone", "fetch", "pull", "push", "ls-remote"}
+
+// retryableGitErrors are the git and ssh messages of failures worth retrying:
+// timeouts, dropped connections, name resolution and 5xx responses of the forge
+var retryableGitErrors = []string{
+	"timed out",
+	"could not resolve host",
+	"connection reset",
+	"connection refused",
+	"failed to connect to",
+	"connection closed by",
+	"early eof",
+	"the remote end hung up unexpectedly",
+	"rpc failed",
+	"unexpected disconnect",
+	"kex_exchange_identification",
+	"ssh: connect to host",
+	"gnutls_handshake() failed",
+	"the requested url returned error: 500",
+	"the requested url returned error: 502",
+	"the requested url returned error: 503",
+	"the requested url returned error: 504",
+	"service unavailable",
+	"internal server error",
+	"bad gateway",
+}
+
+// gitNetworkOperation returns the name of a git command talking to a remote,
+// e.g. git clone, or an empty string for other commands
+func gitNetworkOperation(args []string) string {
+	if len(args) == 0 || filepath.Base(args[0]) != "git" {
+		return ""
+	}
+	subcommand := strings.TrimPrefix(commandSpanName(args), "exec git ")
+	for _, candidate := range gitNetworkSubcommands {
+		if subcommand == candidate {
+			return "git " + subcommand
+		}
+	}
+	return ""
+}
+
+// isRetryableGitError reports whether the standard error of a failed git
+// command points at a transient network failure rather than a rejected
+// push, a missing branch or bad credentials
+func isRetryableGitError(stderr string) bool {
+	stderr = strings.ToLower(stderr)
+	for _, message := range retryableGitErrors {
+		if strings.Contains(stderr, message) {
+			return true
+		}
+	}
+	return false
+}
+
+// runRetried runs a command with run, which starts it and waits for it. Git
+// commands talking to a remote are run again, as copies of the command, when
+// they fail with a retryable error. The standard error buffer of the caller
+// only holds the output of the last attempt.
+func runRetried(ctx context.Context, cmd *exec.Cmd, callerStderr io.Writer, run func(*exec.Cmd) error) error {
+	operation := gitNetworkOperation(cmd.Args)
+	if operation == "" {
+		return run(cmd)
+	}
+
+	// Output sets the standard output of the command it runs
+	stdout := cmd.Stdout
+	var stderr bytes.Buffer
+	if cmd.Stderr == nil {
+		cmd.Stderr = &stderr
+	} else {
+		cmd.Stderr = io.MultiWriter(cmd.Stderr, &stderr)
+	}
+	attempt := cmd
+	var failure string
+	for n := 1; ; n++ {
+		err := run(attempt)
+		if err != nil {
+			failure = lastLine(stderr.String())
+		}
+		if err == nil || ctx.Err() != nil || !isRetryableGitError(stderr.String()) || n >= networkRetryPolicy.MaxAttempts {
+			if n > 1 {
+				runUsageFrom(ctx).recordRetry(RetriedOperation{Operation: operation, Attempts: n, Succeeded: err == nil, LastError: failure})
+			}
+			return err
+		}
+
+		slog.WarnContext(ctx, "Network operation failed, retrying", "operation", operation, "attempt", n, "error", failure)
+		if err := networkRetryPolicy.wait(ctx, n); err != nil {
+			return err
+		}
+		stderr.Reset()
+		if buf, ok := callerStderr.(*bytes.Buffer); ok {
+			buf.Reset()
+		}
+		attempt = copyCommand(ctx, cmd)
+		attempt.Stdout = stdout
+	}
+}
+
+// copyCommand returns an unstarted copy of a command, which cannot be run twice
+func copyCommand(ctx context.Context, cmd *exec.Cmd) *exec.Cmd {
+	next := exec.CommandContext(ctx, cmd.Path)
+	next.Args = cmd.Args
+	next.Dir = cmd.Dir
+	next.Env = cmd.Env
+	next.Stdin = cmd.Stdin
+	next.Stdout = cmd.Stdout
+	next.Stderr = cmd.Stderr
+	return next
+}
+
+// lastLine returns the last non-empty line of a command output
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+func (u *runUsage) recordRetry(retried RetriedOperation) {
+	if u == nil {
+		return
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.usage.Retries = append(u.usage.Retries, retried)
+}
@@ -0,0 +1,43 @@
+package tools
+
+import "testing"
+
+func TestGitNetworkOperation(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{args: []string{"git", "clone", "https://github.com/tektoncd/pipeline"}, want: "git clone"},
+		{args: []string{"/usr/bin/git", "-C", "/tmp/repo", "push", "origin", "main"}, want: "git push"},
+		{args: []string{"git", "-c", "http.extraHeader=x", "ls-remote", "origin"}, want: "git ls-remote"},
+		{args: []string{"git", "-C", "/tmp/repo", "commit", "-m", "fetch"}},
+		{args: []string{"crane", "copy", "a", "b"}},
+		{args: nil},
+	}
+	for _, tt := range tests {
+		if got := gitNetworkOperation(tt.args); got != tt.want {
+			t.Errorf("gitNetworkOperation(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestIsRetryableGitError(t *testing.T) {
+	tests := []struct {
+		stderr string
+		want   bool
+	}{
+		{stderr: "fatal: unable to access 'https://github.com/tektoncd/pipeline/': Could not resolve host: github.com", want: true},
+		{stderr: "error: RPC failed; curl 56 GnuTLS recv error\nfatal: early EOF", want: true},
+		{stderr: "fatal: unable to access 'https://github.com/tektoncd/pipeline/': The requested URL returned error: 503", want: true},
+		{stderr: "ssh: connect to host github.com port 22: Connection timed out", want: true},
+		{stderr: "fatal: unable to access 'https://github.com/tektoncd/pipeline/': The requested URL returned error: 403"},
+		{stderr: " ! [rejected]        main -> main (non-fast-forward)"},
+		{stderr: "fatal: Remote branch release-v1.21.x not found in upstream origin"},
+		{stderr: "fatal: Authentication failed for 'https://github.com/tektoncd/pipeline/'"},
+	}
+	for _, tt := range tests {
+		if got := isRetryableGitError(tt.stderr); got != tt.want {
+			t.Errorf("isRetryableGitError(%q) = %t, want %t", tt.stderr, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"strings"
+)
+
+// RollbackConfig represents the configuration for reverting a merged release plan MR
+type RollbackConfig struct {
+	MinorVersion string
+	CommitSHA    string // Commit (usually the merge commit) of the MR to revert
+	RepoPath     string
+}
+
+// rollbackReleasePlans pushes a revert of a merged release plan change and
+// opens a merge request for it, returning the branch and the merge request
+func rollbackReleasePlans(ctx context.Context, config RollbackConfig) (PushedBranchResult, error) {
+	var result PushedBranchResult
+	slog.DebugContext(ctx, "Rolling back release plans", "version", config.MinorVersion, "commit", config.CommitSHA, "repo_path", config.RepoPath)
+
+	// Clone the konflux-release-data repository
+	if err := cloneKonfluxRepo(ctx, RPAConfig{RepoPath: config.RepoPath}); err != nil {
+		return result, fmt.Errorf("failed to clone konflux-release-data repository: %w", err)
+	}
+
+	// Create a new branch for the revert
+	branchName, err := createPushBranch(ctx, config.RepoPath, "revert-"+releasePlanBranch(config.MinorVersion))
+	if err != nil {
+		return result, err
+	}
+	result.BranchName = branchName
+
+	// Revert the merged changes
+	if err := revertCommit(ctx, config); err != nil {
+		return result, fmt.Errorf("failed to revert commit %s: %w", config.CommitSHA, err)
+	}
+	slog.DebugContext(ctx, "Reverted commit", "commit", config.CommitSHA)
+
+	// Regenerate manifests so the rendered output matches the reverted sources
+	if err := runBuildManifests(ctx, RPAConfig{RepoPath: config.RepoPath}); err != nil {
+		return result, fmt.Errorf("failed to run build-manifests.sh: %w", err)
+	}
+
+	// Commit regenerated manifests, if any, and push the branch
+	if err := pushRollbackBranch(ctx, config, branchName); err != nil {
+		return result, fmt.Errorf("failed to push revert branch: %w", err)
+	}
+
+	provider, err := vcsProvider(gitlabHost)
+	if err != nil {
+		return result, fmt.Errorf("branch %s was pushed, open the merge request manually: %w", branchName, err)
+	}
+	repo, _ := konfluxRepository()
+	mr, err := provider.OpenChangeRequest(ctx, repo, ChangeRequest{
+		Title:        fmt.Sprintf("Revert release plans for v%s", config.MinorVersion),
+		Body:         fmt.Sprintf("Reverts %s, backing out the ReleasePlan and ReleasePlanAdmission change of v%s, and regenerates the manifests.", config.CommitSHA, config.MinorVersion),
+		SourceBranch: konfluxSourceBranch(ctx, config.RepoPath, branchName),
+		TargetBranch: "main",
+	})
+	if err != nil {
+		return result, fmt.Errorf("branch %s was pushed, open the merge request manually: %w", branchName, err)
+	}
+	slog.DebugContext(ctx, "Opened merge request", "url", mr.URL)
+	result.MergeRequestURL = mr.URL
+	return result, nil
+}
+
+func revertCommit(ctx context.Context, config RollbackConfig) error {
+	// Merge commits need a mainline parent to revert against
+	parentsCmd := exec.CommandContext(ctx, "git", "rev-list", "--parents", "-n", "1", config.CommitSHA)
+	parentsCmd.Dir = config.RepoPath
+	parentsOutput, err := commandOutput(ctx, parentsCmd)
+	if err != nil {
+		return fmt.Errorf("failed to find commit %s: %w", config.CommitSHA, err)
+	}
+
+	args := []string{"revert", "--no-edit"}
+	if len(strings.Fields(string(parentsOutput))) > 2 {
+		args = append(args, "-m", "1")
+	}
+	args = append(args, config.CommitSHA)
+
+	// The clone is partial, the revert fetches the contents it reads
+	revertCmd, err := gitCommand(ctx, gitlabHost, args...)
+	if err != nil {
+		return err
+	}
+	revertCmd.Dir = config.RepoPath
+	if err := runCommand(ctx, revertCmd); err != nil {
+		return fmt.Errorf("git revert failed: %w", err)
+	}
+	return nil
+}
+
+func pushRollbackBranch(ctx context.Context, config RollbackConfig, branchName string) error {
+	// Stage regenerated manifests
+	stageCmd := exec.CommandContext(ctx, "git", "add", ".")
+	stageCmd.Dir = config.RepoPath
+	if err := runCommand(ctx, stageCmd); err != nil {
+		return fmt.Errorf("failed to stage changes: %w", err)
+	}
+
+	// Only commit when build-manifests.sh produced a diff
+	diffCmd := exec.CommandContext(ctx, "git", "diff", "--cached", "--quiet")
+	diffCmd.Dir = config.RepoPath
+	if err := runCommand(ctx, diffCmd); err != nil {
+		commitMsg := fmt.Sprintf("Regenerate manifests after reverting release plans for v%s", config.MinorVersion)
+		commitCmd := exec.CommandContext(ctx, "git", "commit", "-m", commitMsg)
+		commitCmd.Dir = config.RepoPath
+		var commitStderr bytes.Buffer
+		commitCmd.Stderr = &commitStderr
+		if err := runCommand(ctx, commitCmd); err != nil {
+			return fmt.Errorf("failed to commit regenerated manifests: %v\nError details: %s", err, commitStderr.String())
+		}
+	}
+
+	// Push changes, authenticating through the configured protocol
+	pushCmd, err := gitCommand(ctx, gitlabHost, "push", "-u", konfluxPushRemote(ctx, config.RepoPath), branchName)
+	if err != nil {
+		return err
+	}
+	pushCmd.Dir = config.RepoPath
+	var pushStderr bytes.Buffer
+	pushCmd.Stderr = &pushStderr
+	if err := runCommand(ctx, pushCmd); err != nil {
+		return fmt.Errorf("failed to push changes: %v\nError details: %s", err, pushStderr.String())
+	}
+	return nil
+}
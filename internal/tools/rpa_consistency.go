@@ -0,0 +1,235 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// expectedEnvDifferences are the RPA fields allowed to differ between stage and
+// prod. Registry hosts are normalized before comparing, so repositories only
+// differing by registry are not reported.
+var expectedEnvDifferences = map[string]bool{
+	"metadata.name":                       true,
+	"spec.policy":                         true,
+	"spec.pipeline.serviceAccountName":    true,
+	"spec.data.intention":                 true,
+	"spec.data.fbc.stagedIndex":           true,
+	"spec.data.fbc.fromIndex":             true,
+	"spec.data.fbc.targetIndex":           true,
+	"spec.data.fbc.publishingCredentials": true,
+}
+
+// listIndex matches list indices in flattened field paths
+var listIndex = regexp.MustCompile(`\[\d+\]`)
+
+// RPAConsistencyConfig represents the configuration for comparing stage and prod RPAs
+type RPAConsistencyConfig struct {
+	RepoPath     string
+	Dir          string // Relative to the konflux-release-data root
+	MinorVersion string // Only check RPAs of this version when set
+}
+
+// RPADivergence represents a field that unexpectedly differs between stage and prod
+type RPADivergence struct {
+	Field string `json:"field"`
+	Stage string `json:"stage"`
+	Prod  string `json:"prod"`
+}
+
+// RPAPairReport represents the comparison of a stage RPA with its prod counterpart
+type RPAPairReport struct {
+	Stage       string          `json:"stage" jsonschema:"Stage RPA file, empty when missing"`
+	Prod        string          `json:"prod" jsonschema:"Prod RPA file, empty when missing"`
+	Divergences []RPADivergence `json:"divergences,omitempty"`
+}
+
+// RPAConsistencyResult represents the structured result of stage-vs-prod-rpa-consistency-check
+type RPAConsistencyResult struct {
+	Consistent bool            `json:"consistent" jsonschema:"Whether every RPA has a counterpart and only expected fields differ"`
+	Pairs      []RPAPairReport `json:"pairs,omitempty"`
+}
+
+func checkRPAConsistency(ctx context.Context, config RPAConsistencyConfig) (RPAConsistencyResult, error) {
+	result := RPAConsistencyResult{Consistent: true}
+
+	if err := cloneKonfluxRepo(ctx, RPAConfig{RepoPath: config.RepoPath}); err != nil {
+		return result, fmt.Errorf("failed to clone konflux-release-data repository: %w", err)
+	}
+
+	dir := filepath.Join(config.RepoPath, config.Dir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return result, fmt.Errorf("failed to read directory %s: %w", dir, err)
+	}
+
+	// Pair files by their name without the environment suffix
+	pairs := make(map[string]*RPAPairReport)
+	for _, entry := range entries {
+		name := entry.Name()
+		if config.MinorVersion != "" && !strings.Contains(name, "-"+config.MinorVersion+"-") {
+			continue
+		}
+		base := strings.TrimSuffix(name, ".yaml")
+		var env string
+		for _, e := range []string{"stage", "prod"} {
+			if strings.HasSuffix(base, "-"+e) {
+				env = e
+				base = strings.TrimSuffix(base, "-"+e)
+			}
+		}
+		if entry.IsDir() || env == "" {
+			continue
+		}
+		if pairs[base] == nil {
+			pairs[base] = &RPAPairReport{}
+		}
+		if env == "stage" {
+			pairs[base].Stage = name
+		} else {
+			pairs[base].Prod = name
+		}
+	}
+
+	var bases []string
+	for base := range pairs {
+		bases = append(bases, base)
+	}
+	sort.Strings(bases)
+
+	for _, base := range bases {
+		pair := pairs[base]
+		if pair.Stage == "" || pair.Prod == "" {
+			result.Consistent = false
+			result.Pairs = append(result.Pairs, *pair)
+			continue
+		}
+
+		stage, err := flattenRPAFile(filepath.Join(dir, pair.Stage))
+		if err != nil {
+			return result, err
+		}
+		prod, err := flattenRPAFile(filepath.Join(dir, pair.Prod))
+		if err != nil {
+			return result, err
+		}
+		pair.Divergences = rpaDivergences(stage, prod)
+		if len(pair.Divergences) > 0 {
+			result.Consistent = false
+		}
+		result.Pairs = append(result.Pairs, *pair)
+	}
+	return result, nil
+}
+
+// flattenRPAFile parses an RPA and flattens it to field paths
+func flattenRPAFile(filePath string) (map[string]any, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filePath, err)
+	}
+	var doc any
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filePath, err)
+	}
+	fields := make(map[string]any)
+	flattenFields("", doc, fields)
+	for path, value := range fields {
+		// Repositories legitimately point at the registry of their environment
+		if s, ok := value.(string); ok {
+			fields[path] = strings.ReplaceAll(s, getRegistryURL("stage"), getRegistryURL("prod"))
+		}
+	}
+	return fields, nil
+}
+
+// flattenFields flattens a decoded document to the values of its field
+// paths, e.g. spec.data.mapping.components[0].name
+func flattenFields(prefix string, value any, fields map[string]any) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			path := key
+			if prefix != "" {
+				path = prefix + "." + key
+			}
+			flattenFields(path, child, fields)
+		}
+	case []any:
+		for i, child := range v {
+			flattenFields(fmt.Sprintf("%s[%d]", prefix, i), child, fields)
+		}
+	default:
+		fields[prefix] = v
+	}
+}
+
+// rpaDivergences returns the fields differing between stage and prod, ignoring expected differences
+func rpaDivergences(stage, prod map[string]any) []RPADivergence {
+	paths := make(map[string]bool)
+	for path := range stage {
+		paths[path] = true
+	}
+	for path := range prod {
+		paths[path] = true
+	}
+
+	var divergences []RPADivergence
+	for path := range paths {
+		if expectedEnvDifferences[listIndex.ReplaceAllString(path, "")] {
+			continue
+		}
+		stageValue, inStage := stage[path]
+		prodValue, inProd := prod[path]
+		if inStage && inProd && reflect.DeepEqual(stageValue, prodValue) {
+			continue
+		}
+		divergences = append(divergences, RPADivergence{
+			Field: path,
+			Stage: formatRPAValue(stageValue, inStage),
+			Prod:  formatRPAValue(prodValue, inProd),
+		})
+	}
+	sort.Slice(divergences, func(i, j int) bool {
+		return divergences[i].Field < divergences[j].Field
+	})
+	return divergences
+}
+
+func formatRPAValue(value any, present bool) string {
+	if !present {
+		return "<missing>"
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+func formatRPAConsistency(result RPAConsistencyResult) string {
+	if len(result.Pairs) == 0 {
+		return "No stage/prod ReleasePlanAdmissions found"
+	}
+
+	var sb strings.Builder
+	for _, pair := range result.Pairs {
+		switch {
+		case pair.Stage == "":
+			fmt.Fprintf(&sb, "✗ %s has no stage counterpart\n", pair.Prod)
+		case pair.Prod == "":
+			fmt.Fprintf(&sb, "✗ %s has no prod counterpart\n", pair.Stage)
+		case len(pair.Divergences) == 0:
+			fmt.Fprintf(&sb, "✓ %s / %s\n", pair.Stage, pair.Prod)
+		default:
+			fmt.Fprintf(&sb, "✗ %s / %s\n", pair.Stage, pair.Prod)
+			for _, divergence := range pair.Divergences {
+				fmt.Fprintf(&sb, "  %s: stage=%s prod=%s\n", divergence.Field, divergence.Stage, divergence.Prod)
+			}
+		}
+	}
+	return sb.String()
+}
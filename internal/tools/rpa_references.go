@@ -0,0 +1,208 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// enterpriseContractPolicyGVR identifies the EnterpriseContractPolicy resource referenced by RPA policies
+var enterpriseContractPolicyGVR = schema.GroupVersionResource{
+	Group:    "appstudio.redhat.com",
+	Version:  "v1alpha1",
+	Resource: "enterprisecontractpolicies",
+}
+
+// RPAReferencesConfig represents the configuration for verifying the references of RPAs
+type RPAReferencesConfig struct {
+	RepoPath     string
+	MinorVersion string // Only verify RPAs of this version when set
+	Environments []string
+}
+
+// RPAReference represents a cluster object referenced by generated RPAs
+type RPAReference struct {
+	Kind      string   `json:"kind"`
+	Namespace string   `json:"namespace"`
+	Name      string   `json:"name"`
+	RPAs      []string `json:"rpas" jsonschema:"ReleasePlanAdmission files referencing the object"`
+	Exists    bool     `json:"exists"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// rpaReferenceFields holds the fields of an RPA referencing cluster objects
+type rpaReferenceFields struct {
+	Kind     string `yaml:"kind"`
+	Metadata struct {
+		Namespace string `yaml:"namespace"`
+	} `yaml:"metadata"`
+	Spec struct {
+		Policy   string         `yaml:"policy"`
+		Data     map[string]any `yaml:"data"`
+		Pipeline struct {
+			ServiceAccountName string `yaml:"serviceAccountName"`
+		} `yaml:"pipeline"`
+	} `yaml:"spec"`
+}
+
+// collectRPAReferences returns the policies, service accounts and credentials
+// referenced by the RPAs of the product in konflux-release-data for the given
+// environments
+func collectRPAReferences(config RPAReferencesConfig) ([]RPAReference, error) {
+	dir := filepath.Join(config.RepoPath, productRPADir())
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory %s: %w", productRPADir(), err)
+	}
+
+	index := map[string]int{}
+	var refs []RPAReference
+	add := func(kind, namespace, name, file string) {
+		key := kind + "/" + namespace + "/" + name
+		if name == "" {
+			return
+		}
+		if i, ok := index[key]; ok {
+			refs[i].RPAs = append(refs[i].RPAs, file)
+			return
+		}
+		index[key] = len(refs)
+		refs = append(refs, RPAReference{Kind: kind, Namespace: namespace, Name: name, RPAs: []string{file}})
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".yaml") {
+			continue
+		}
+		if config.MinorVersion != "" && !strings.Contains(name, "-"+config.MinorVersion+"-") {
+			continue
+		}
+		selected := false
+		for _, env := range config.Environments {
+			selected = selected || strings.HasSuffix(name, "-"+env+".yaml")
+		}
+		if !selected {
+			continue
+		}
+
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		var rpa rpaReferenceFields
+		if err := yaml.Unmarshal(content, &rpa); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+		if rpa.Kind != "ReleasePlanAdmission" {
+			continue
+		}
+		namespace := rpa.Metadata.Namespace
+		if namespace == "" {
+			namespace = relengTenant()
+		}
+
+		add("EnterpriseContractPolicy", namespace, rpa.Spec.Policy, name)
+		add("ServiceAccount", namespace, rpa.Spec.Pipeline.ServiceAccountName, name)
+		for _, secret := range credentialReferences(rpa.Spec.Data) {
+			add("Secret", namespace, secret, name)
+		}
+	}
+
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].Kind != refs[j].Kind {
+			return refs[i].Kind < refs[j].Kind
+		}
+		if refs[i].Namespace != refs[j].Namespace {
+			return refs[i].Namespace < refs[j].Namespace
+		}
+		return refs[i].Name < refs[j].Name
+	})
+	return refs, nil
+}
+
+// credentialReferences returns the secrets named in the data of an RPA, by
+// keys such as publishingCredentials or cosignSecretName
+func credentialReferences(data map[string]any) []string {
+	var secrets []string
+	for key, value := range data {
+		switch v := value.(type) {
+		case map[string]any:
+			secrets = append(secrets, credentialReferences(v)...)
+		case string:
+			lower := strings.ToLower(key)
+			if strings.HasSuffix(lower, "credentials") || strings.HasSuffix(lower, "secret") || strings.HasSuffix(lower, "secretname") {
+				secrets = append(secrets, v)
+			}
+		}
+	}
+	sort.Strings(secrets)
+	return secrets
+}
+
+func verifyRPAReferences(ctx context.Context, config RPAReferencesConfig) ([]RPAReference, error) {
+	if err := cloneKonfluxRepo(ctx, RPAConfig{RepoPath: config.RepoPath}); err != nil {
+		return nil, fmt.Errorf("failed to clone konflux-release-data repository: %w", err)
+	}
+	refs, err := collectRPAReferences(config)
+	if err != nil {
+		return nil, err
+	}
+
+	kc, err := kubeClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+	dc, err := dynamicClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+
+	for i := range refs {
+		var err error
+		switch refs[i].Kind {
+		case "EnterpriseContractPolicy":
+			_, err = dc.Resource(enterpriseContractPolicyGVR).Namespace(refs[i].Namespace).Get(ctx, refs[i].Name, metav1.GetOptions{})
+		case "ServiceAccount":
+			_, err = kc.CoreV1().ServiceAccounts(refs[i].Namespace).Get(ctx, refs[i].Name, metav1.GetOptions{})
+		case "Secret":
+			_, err = kc.CoreV1().Secrets(refs[i].Namespace).Get(ctx, refs[i].Name, metav1.GetOptions{})
+		}
+
+		switch {
+		case err == nil:
+			refs[i].Exists = true
+		case apierrors.IsNotFound(err):
+			refs[i].Exists = false
+		default:
+			refs[i].Error = err.Error()
+		}
+	}
+	return refs, nil
+}
+
+func formatRPAReferences(refs []RPAReference) (string, bool) {
+	var sb strings.Builder
+	allFound := true
+	for _, ref := range refs {
+		switch {
+		case ref.Error != "":
+			allFound = false
+			fmt.Fprintf(&sb, "? %s %s/%s: %s\n", ref.Kind, ref.Namespace, ref.Name, ref.Error)
+		case ref.Exists:
+			fmt.Fprintf(&sb, "✓ %s %s/%s\n", ref.Kind, ref.Namespace, ref.Name)
+		default:
+			allFound = false
+			fmt.Fprintf(&sb, "✗ %s %s/%s not found, referenced by %s\n", ref.Kind, ref.Namespace, ref.Name, strings.Join(ref.RPAs, ", "))
+		}
+	}
+	return sb.String(), allFound
+}
@@ -0,0 +1,384 @@
+package tools
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	mcp "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// runLogDir is the directory of the state directory keeping the log of every tool call
+	runLogDir = "runs"
+
+	// RunIDMeta is the result metadata key holding the ID of the run log of a tool call
+	RunIDMeta = "release-mcp/run-id"
+
+	// maxListedRuns bounds the runs listed by get-run-log without a run ID
+	maxListedRuns = 20
+
+	// runLogRetention is how long the log of a run is kept after its last entry
+	runLogRetention = 30 * 24 * time.Hour
+
+	// runLogPruneInterval is how often the expired run logs are removed
+	runLogPruneInterval = time.Hour
+)
+
+// RunLogEntry represents a record logged during a tool call
+type RunLogEntry struct {
+	Time       time.Time      `json:"time"`
+	Level      string         `json:"level"`
+	Message    string         `json:"message"`
+	Attributes map[string]any `json:"attributes,omitempty"`
+}
+
+// RunSummary represents a tool call with a run log
+type RunSummary struct {
+	RunID   string    `json:"run_id"`
+	Tool    string    `json:"tool"`
+	Started time.Time `json:"started"`
+}
+
+// RunLogResult represents the structured result of get-run-log
+type RunLogResult struct {
+	RunID   string        `json:"run_id,omitempty"`
+	Tool    string        `json:"tool,omitempty"`
+	Entries []RunLogEntry `json:"entries,omitempty"`
+	Skipped int           `json:"skipped,omitempty" jsonschema:"Entries of the level before the returned tail"`
+	Runs    []RunSummary  `json:"runs,omitempty" jsonschema:"Most recent runs first, when no run ID is given"`
+}
+
+var (
+	runLogMu        sync.Mutex
+	runLogsPrunedAt time.Time
+)
+
+type runIDKey struct{}
+
+// runIDFrom returns the run ID of the tool call of the context, empty outside tool calls
+func runIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(runIDKey{}).(string)
+	return id
+}
+
+// newRunID returns a run ID sorting in the order the runs started
+func newRunID() (string, error) {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", fmt.Errorf("failed to generate run ID: %w", err)
+	}
+	return time.Now().UTC().Format("20060102-150405") + "-" + hex.EncodeToString(suffix), nil
+}
+
+// runLogMiddleware gives every tool call a run log, which receives what is
+// logged while the call runs and the output of its subprocesses. The run ID
+// is returned in the result metadata.
+func runLogMiddleware(next mcp.MethodHandler[*mcp.ServerSession]) mcp.MethodHandler[*mcp.ServerSession] {
+	return func(ctx context.Context, session *mcp.ServerSession, method string, params mcp.Params) (mcp.Result, error) {
+		callParams, ok := params.(*mcp.CallToolParamsFor[json.RawMessage])
+		if method != "tools/call" || !ok {
+			return next(ctx, session, method, params)
+		}
+		id, err := newRunID()
+		if err != nil {
+			return next(ctx, session, method, params)
+		}
+
+		if err := pruneRunLogs(time.Now()); err != nil {
+			slog.WarnContext(ctx, "Failed to remove expired run logs", "error", err)
+		}
+		ctx = context.WithValue(ctx, runIDKey{}, id)
+		slog.InfoContext(ctx, "Tool call started", "tool", callParams.Name)
+		start := time.Now()
+		result, err := next(ctx, session, method, params)
+		toolResult, ok := result.(*mcp.CallToolResult)
+		ok = ok && toolResult != nil
+		switch {
+		case err != nil:
+			slog.ErrorContext(ctx, "Tool call failed", "tool", callParams.Name, "duration", time.Since(start), "error", err)
+		case ok && toolResult.IsError:
+			slog.WarnContext(ctx, "Tool call returned an error", "tool", callParams.Name, "duration", time.Since(start))
+		default:
+			slog.InfoContext(ctx, "Tool call finished", "tool", callParams.Name, "duration", time.Since(start))
+		}
+		if ok {
+			if toolResult.Meta == nil {
+				toolResult.Meta = mcp.Meta{}
+			}
+			toolResult.Meta[RunIDMeta] = id
+		}
+		return result, err
+	}
+}
+
+// LogHandler wraps the handler of the server log so the records logged
+// during a tool call are also appended to its run log, whatever their level.
+// Records of the server log are tagged with the run ID.
+func LogHandler(base slog.Handler) slog.Handler {
+	return &runLogHandler{base: base}
+}
+
+type runLogHandler struct {
+	base   slog.Handler
+	attrs  []slog.Attr
+	prefix string // Key prefix of the attributes added after WithGroup
+}
+
+func (h *runLogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return runIDFrom(ctx) != "" || h.base.Enabled(ctx, level)
+}
+
+func (h *runLogHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id := runIDFrom(ctx); id != "" {
+		entry := RunLogEntry{Time: record.Time, Level: record.Level.String(), Message: record.Message, Attributes: map[string]any{}}
+		for _, attr := range h.attrs {
+			addLogAttr(entry.Attributes, "", attr)
+		}
+		record.Attrs(func(attr slog.Attr) bool {
+			addLogAttr(entry.Attributes, h.prefix, attr)
+			return true
+		})
+		// The server log reports failures to write the run log, not the run log itself
+		if err := appendRunLog(id, entry); err != nil && h.base.Enabled(ctx, slog.LevelWarn) {
+			_ = h.base.Handle(ctx, slog.NewRecord(time.Now(), slog.LevelWarn, "Failed to write run log", 0))
+		}
+		record = record.Clone()
+		record.AddAttrs(slog.String("run", id))
+	}
+	if !h.base.Enabled(ctx, record.Level) {
+		return nil
+	}
+	return h.base.Handle(ctx, record)
+}
+
+func (h *runLogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	prefixed := make([]slog.Attr, 0, len(h.attrs)+len(attrs))
+	prefixed = append(prefixed, h.attrs...)
+	for _, attr := range attrs {
+		prefixed = append(prefixed, slog.Attr{Key: h.prefix + attr.Key, Value: attr.Value})
+	}
+	return &runLogHandler{base: h.base.WithAttrs(attrs), attrs: prefixed, prefix: h.prefix}
+}
+
+func (h *runLogHandler) WithGroup(name string) slog.Handler {
+	return &runLogHandler{base: h.base.WithGroup(name), attrs: h.attrs, prefix: h.prefix + name + "."}
+}
+
+// addLogAttr adds an attribute to the attributes of a run log entry,
+// flattening groups into dotted keys
+func addLogAttr(attributes map[string]any, prefix string, attr slog.Attr) {
+	value := attr.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindGroup:
+		for _, member := range value.Group() {
+			addLogAttr(attributes, prefix+attr.Key+".", member)
+		}
+	case slog.KindDuration:
+		attributes[prefix+attr.Key] = value.Duration().String()
+	default:
+		if err, ok := value.Any().(error); ok {
+			attributes[prefix+attr.Key] = err.Error()
+		} else {
+			attributes[prefix+attr.Key] = value.Any()
+		}
+	}
+}
+
+// appendRunLog appends an entry to the log of a run
+func appendRunLog(id string, entry RunLogEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode run log entry: %w", err)
+	}
+	runLogMu.Lock()
+	defer runLogMu.Unlock()
+	dir := filepath.Join(stateDir(), runLogDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create run log directory: %w", err)
+	}
+	return appendToFile(filepath.Join(dir, id+".jsonl"), string(line)+"\n")
+}
+
+// pruneRunLogs removes the run logs not written to for runLogRetention, at
+// most once per runLogPruneInterval
+func pruneRunLogs(now time.Time) error {
+	runLogMu.Lock()
+	defer runLogMu.Unlock()
+	if now.Sub(runLogsPrunedAt) < runLogPruneInterval {
+		return nil
+	}
+	runLogsPrunedAt = now
+
+	dir := filepath.Join(stateDir(), runLogDir)
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to list run logs: %w", err)
+	}
+	var errs []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".jsonl") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || now.Sub(info.ModTime()) < runLogRetention {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to remove run logs: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// runLogSink logs the command line and output of a subprocess of a tool call,
+// at the debug level so they only reach the server log when debugging
+type runLogSink struct {
+	ctx context.Context
+	// The command line is the first line sent, before the command starts
+	commandLogged bool
+}
+
+func (s *runLogSink) send(level mcp.LoggingLevel, line string) {
+	if !s.commandLogged {
+		s.commandLogged = true
+		slog.DebugContext(s.ctx, line)
+		return
+	}
+	stream := "stdout"
+	if level == "notice" {
+		stream = "stderr"
+	}
+	slog.DebugContext(s.ctx, line, "stream", stream)
+}
+
+// attachRunLog tees the output of a command of a tool call to its run log,
+// see attachLines. It does nothing outside tool calls.
+func attachRunLog(ctx context.Context, cmd *exec.Cmd, captureStdout bool) func() {
+	if runIDFrom(ctx) == "" {
+		return func() {}
+	}
+	return attachLines(&runLogSink{ctx: ctx}, cmd, captureStdout)
+}
+
+// readRunLog returns the entries of a run log at or above a level, only
+// the last tail entries when tail is positive, and how many were skipped
+func readRunLog(id string, minLevel slog.Level, tail int) (RunLogResult, error) {
+	result := RunLogResult{RunID: id}
+	if id == "" || id != filepath.Base(id) || strings.HasPrefix(id, ".") {
+		return result, fmt.Errorf("invalid run ID %q", id)
+	}
+	file, err := os.Open(filepath.Join(stateDir(), runLogDir, id+".jsonl"))
+	if os.IsNotExist(err) {
+		return result, fmt.Errorf("no run log %s, list the runs by calling get-run-log without a run ID", id)
+	}
+	if err != nil {
+		return result, fmt.Errorf("failed to open run log %s: %w", id, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var entry RunLogEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		if result.Tool == "" {
+			result.Tool, _ = entry.Attributes["tool"].(string)
+		}
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(entry.Level)); err == nil && level < minLevel {
+			continue
+		}
+		result.Entries = append(result.Entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return result, fmt.Errorf("failed to read run log %s: %w", id, err)
+	}
+	if tail > 0 && len(result.Entries) > tail {
+		result.Skipped = len(result.Entries) - tail
+		result.Entries = result.Entries[result.Skipped:]
+	}
+	return result, nil
+}
+
+// listRuns returns the most recent runs with a run log, newest first
+func listRuns() ([]RunSummary, error) {
+	entries, err := os.ReadDir(filepath.Join(stateDir(), runLogDir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list run logs: %w", err)
+	}
+	var ids []string
+	for _, entry := range entries {
+		if id, ok := strings.CutSuffix(entry.Name(), ".jsonl"); ok && !entry.IsDir() {
+			ids = append(ids, id)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(ids)))
+
+	var runs []RunSummary
+	for _, id := range ids[:min(len(ids), maxListedRuns)] {
+		run := RunSummary{RunID: id}
+		if log, err := readRunLog(id, slog.LevelInfo, 0); err == nil {
+			run.Tool = log.Tool
+			if len(log.Entries) > 0 {
+				run.Started = log.Entries[0].Time
+			}
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
+
+func formatRunLog(result RunLogResult) string {
+	var sb strings.Builder
+	if result.RunID == "" {
+		if len(result.Runs) == 0 {
+			return "No run logs yet\n"
+		}
+		sb.WriteString("Recent runs:\n")
+		for _, run := range result.Runs {
+			fmt.Fprintf(&sb, "- %s %s (%s)\n", run.RunID, run.Tool, run.Started.UTC().Format(time.RFC3339))
+		}
+		return sb.String()
+	}
+
+	fmt.Fprintf(&sb, "Run %s of %s:\n", result.RunID, result.Tool)
+	if result.Skipped > 0 {
+		fmt.Fprintf(&sb, "... %d earlier entries\n", result.Skipped)
+	}
+	for _, entry := range result.Entries {
+		fmt.Fprintf(&sb, "%s %-5s %s", entry.Time.UTC().Format("15:04:05.000"), entry.Level, entry.Message)
+		keys := make([]string, 0, len(entry.Attributes))
+		for key := range entry.Attributes {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(&sb, " %s=%v", key, entry.Attributes[key])
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
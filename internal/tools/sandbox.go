@@ -0,0 +1,398 @@
+package tools
+
+import (
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"net/http/cgi"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/rest"
+)
+
+const (
+	// sandboxIdentity is the user of the fake forges and the author of the sandbox commits
+	sandboxIdentity = "sandbox"
+
+	// sandboxSeedDate dates the seed commits, so a repository and its fork
+	// are seeded with the same history
+	sandboxSeedDate = "2024-01-01T00:00:00Z"
+
+	// defaultSandboxReleaseDelay is how long the fake release controller takes to complete a Release
+	defaultSandboxReleaseDelay = 5 * time.Second
+)
+
+// SandboxOptions configures the in-process fakes of sandbox mode
+type SandboxOptions struct {
+	Dir          string        // Where the repositories and the server state are kept, a temporary directory removed on stop when empty
+	FaultRate    float64       // Fraction of the forge and cluster API requests failing with a 503, to exercise retries
+	ReleaseDelay time.Duration // How long the fake release controller takes to complete a Release
+}
+
+// sandbox serves the forge and cluster operations of the server from
+// in-process fakes: the repositories are bare repositories served over
+// smart HTTP by git http-backend, the GitHub and GitLab APIs operate on them,
+// and the Kubernetes API is an in-memory API server
+type sandbox struct {
+	dir       string
+	temporary bool
+	gitConfig string
+
+	forge   *httptest.Server
+	cluster *httptest.Server
+
+	reposMu sync.Mutex
+	forgeState
+	clusterState
+}
+
+var (
+	activeSandbox   *sandbox
+	activeSandboxMu sync.Mutex
+)
+
+// StartSandbox starts the fake forges and cluster and points the server at
+// them: git remotes of github.com and the GitLab instance are rewritten to
+// the fake git server, the API clients call the fake APIs and fake tokens are
+// set. It returns the configuration of the fake cluster, used instead of the
+// kubeconfig. Repositories are created and seeded the first time they are
+// used. Registries, Jira and other services are not faked.
+func StartSandbox(options SandboxOptions) (*rest.Config, error) {
+	if options.FaultRate < 0 || options.FaultRate >= 1 {
+		return nil, fmt.Errorf("fault rate must be in [0, 1), got %v", options.FaultRate)
+	}
+	if options.ReleaseDelay <= 0 {
+		options.ReleaseDelay = defaultSandboxReleaseDelay
+	}
+	git, err := exec.LookPath("git")
+	if err != nil {
+		return nil, fmt.Errorf("sandbox mode serves repositories with git http-backend: %w", err)
+	}
+
+	sb := &sandbox{dir: options.Dir}
+	if sb.dir == "" {
+		if sb.dir, err = os.MkdirTemp("", "release-mcp-sandbox-*"); err != nil {
+			return nil, fmt.Errorf("failed to create sandbox directory: %w", err)
+		}
+		sb.temporary = true
+	}
+	if sb.dir, err = filepath.Abs(sb.dir); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Join(sb.dir, "repos"), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create sandbox directory: %w", err)
+	}
+	sb.forgeState.init()
+	sb.clusterState.init(options.ReleaseDelay)
+
+	forge := http.NewServeMux()
+	forge.Handle("/git/", sb.gitHandler(git))
+	forge.Handle("/github/", faultInjecting(options.FaultRate, http.StripPrefix("/github", sb.githubHandler())))
+	forge.Handle("/gitlab/api/v4/", faultInjecting(options.FaultRate, http.StripPrefix("/gitlab/api/v4", sb.gitlabHandler())))
+	sb.forge = httptest.NewServer(forge)
+	sb.cluster = httptest.NewServer(faultInjecting(options.FaultRate, sb.clusterHandler()))
+
+	if err := sb.configureClients(); err != nil {
+		sb.close()
+		return nil, err
+	}
+	sb.seedCluster()
+
+	activeSandboxMu.Lock()
+	activeSandbox = sb
+	activeSandboxMu.Unlock()
+	slog.Info("Sandbox mode", "forge", sb.forge.URL, "cluster", sb.cluster.URL, "dir", sb.dir)
+	return &rest.Config{Host: sb.cluster.URL, QPS: 100, Burst: 200}, nil
+}
+
+// StopSandbox stops the fakes of sandbox mode, removing the sandbox
+// directory unless it was given. It does nothing outside sandbox mode.
+func StopSandbox() {
+	activeSandboxMu.Lock()
+	sb := activeSandbox
+	activeSandbox = nil
+	activeSandboxMu.Unlock()
+	if sb != nil {
+		sb.close()
+	}
+}
+
+func (sb *sandbox) close() {
+	sb.clusterState.stop()
+	if sb.forge != nil {
+		sb.forge.Close()
+	}
+	if sb.cluster != nil {
+		sb.cluster.Close()
+	}
+	if sb.temporary {
+		os.RemoveAll(sb.dir)
+	}
+}
+
+// configureClients points git, the forge clients and the state of the server at the sandbox
+func (sb *sandbox) configureClients() error {
+	// A dedicated global configuration replaces the one of the user, so their
+	// URL rewrites, credential helpers and signing keys do not apply
+	var config strings.Builder
+	fmt.Fprintf(&config, "[user]\n\tname = Release Sandbox\n\temail = %s@sandbox.invalid\n", sandboxIdentity)
+	config.WriteString("[init]\n\tdefaultBranch = main\n[commit]\n\tgpgsign = false\n[tag]\n\tgpgsign = false\n")
+	for _, host := range []string{githubHost, gitlabHost} {
+		fmt.Fprintf(&config, "[url %q]\n\tinsteadOf = https://%s/\n\tinsteadOf = git@%s:\n", fmt.Sprintf("%s/git/%s/", sb.forge.URL, host), host, host)
+	}
+	sb.gitConfig = filepath.Join(sb.dir, "gitconfig")
+	if err := os.WriteFile(sb.gitConfig, []byte(config.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write sandbox git configuration: %w", err)
+	}
+
+	env := map[string]string{
+		"GIT_CONFIG_GLOBAL":   sb.gitConfig,
+		"GIT_CONFIG_NOSYSTEM": "1",
+		"GITHUB_TOKEN":        sandboxIdentity,
+		"GITLAB_USERNAME":     sandboxIdentity,
+		"GITLAB_TOKEN":        sandboxIdentity,
+		stateDirEnv:           filepath.Join(sb.dir, "state"),
+	}
+	for key, value := range env {
+		if err := os.Setenv(key, value); err != nil {
+			return fmt.Errorf("failed to set %s: %w", key, err)
+		}
+	}
+	githubAPIURL = sb.forge.URL + "/github"
+	gitlabAPIURL = sb.forge.URL + "/gitlab/api/v4"
+	return nil
+}
+
+// faultInjecting fails a fraction of the requests with a 503, which the
+// forge and Kubernetes clients retry after the Retry-After delay. Watches are
+// spared, as failing them only delays the events.
+func faultInjecting(rate float64, next http.Handler) http.Handler {
+	if rate == 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("watch") == "" && rand.Float64() < rate {
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "sandbox fault injection", http.StatusServiceUnavailable)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// gitServiceSuffixes are the smart HTTP endpoints following a repository path
+var gitServiceSuffixes = []string{"/info/refs", "/git-upload-pack", "/git-receive-pack", "/HEAD", "/objects/"}
+
+// gitHandler serves the repositories over smart HTTP, at
+// /git/<host>/<owner>/<name>.git, creating them on first use
+func (sb *sandbox) gitHandler(git string) http.Handler {
+	backend := &cgi.Handler{
+		Path:       git,
+		Args:       []string{"http-backend"},
+		Root:       "/git",
+		Dir:        sb.dir,
+		Env:        []string{"GIT_PROJECT_ROOT=" + filepath.Join(sb.dir, "repos"), "GIT_HTTP_EXPORT_ALL=1"},
+		InheritEnv: []string{"PATH", "HOME", "GIT_CONFIG_GLOBAL", "GIT_CONFIG_NOSYSTEM"},
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		repoPath, service := strings.TrimPrefix(r.URL.Path, "/git/"), ""
+		for _, suffix := range gitServiceSuffixes {
+			if i := strings.Index(repoPath, suffix); i > 0 {
+				repoPath, service = repoPath[:i], repoPath[i:]
+				break
+			}
+		}
+		host, repo, ok := strings.Cut(strings.TrimSuffix(repoPath, ".git"), "/")
+		if !ok || service == "" {
+			http.NotFound(w, r)
+			return
+		}
+		if _, err := sb.repository(host, repo); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		r.URL.Path = fmt.Sprintf("/git/%s/%s.git%s", host, repo, service)
+		backend.ServeHTTP(w, r)
+	})
+}
+
+// repository returns the bare repository of a forge repository, creating
+// and seeding it on first use
+func (sb *sandbox) repository(host, repo string) (string, error) {
+	repo = strings.TrimSuffix(repo, ".git")
+	if host != githubHost && host != gitlabHost {
+		return "", fmt.Errorf("unknown forge %s", host)
+	}
+	if repo == "" || !strings.Contains(repo, "/") || path.Clean(repo) != repo || strings.HasPrefix(repo, "..") {
+		return "", fmt.Errorf("invalid repository %q", repo)
+	}
+	dir := filepath.Join(sb.dir, "repos", host, filepath.FromSlash(repo)+".git")
+
+	sb.reposMu.Lock()
+	defer sb.reposMu.Unlock()
+	if _, err := os.Stat(dir); err == nil {
+		return dir, nil
+	}
+	if err := sb.seedRepository(dir, host, repo); err != nil {
+		os.RemoveAll(dir)
+		return "", fmt.Errorf("failed to seed %s/%s: %w", host, repo, err)
+	}
+	return dir, nil
+}
+
+// seedRepository creates a bare repository with a single commit laying out
+// what the tools expect of it, on main and on the branches releases are cut from
+func (sb *sandbox) seedRepository(dir, host, repo string) error {
+	if _, err := sb.git("", "init", "--bare", "--initial-branch=main", dir); err != nil {
+		return err
+	}
+	if _, err := sb.git(dir, "config", "http.receivepack", "true"); err != nil {
+		return err
+	}
+	files, executables := sandboxSeedFiles(host, repo)
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// Files are added to the index from blobs, without a work tree
+	for _, name := range names {
+		blob, err := sb.gitInput(dir, files[name], "hash-object", "-w", "--stdin")
+		if err != nil {
+			return err
+		}
+		mode := "100644"
+		if executables[name] {
+			mode = "100755"
+		}
+		if _, err := sb.git(dir, "update-index", "--add", "--cacheinfo", fmt.Sprintf("%s,%s,%s", mode, blob, name)); err != nil {
+			return err
+		}
+	}
+	tree, err := sb.git(dir, "write-tree")
+	if err != nil {
+		return err
+	}
+	commit, err := sb.git(dir, "commit-tree", tree, "-m", "Seed "+repo)
+	if err != nil {
+		return err
+	}
+	for _, branch := range sandboxSeedBranches(host, repo) {
+		if _, err := sb.git(dir, "update-ref", "refs/heads/"+branch, commit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// git runs a git command against a bare repository of the sandbox, or outside any when dir is empty
+func (sb *sandbox) git(dir string, args ...string) (string, error) {
+	return sb.gitInput(dir, "", args...)
+}
+
+func (sb *sandbox) gitInput(dir, input string, args ...string) (string, error) {
+	command := args[0]
+	if dir != "" {
+		args = append([]string{"--git-dir", dir}, args...)
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Env = append(os.Environ(),
+		"GIT_CONFIG_GLOBAL="+sb.gitConfig,
+		"GIT_CONFIG_NOSYSTEM=1",
+		"GIT_AUTHOR_DATE="+sandboxSeedDate,
+		"GIT_COMMITTER_DATE="+sandboxSeedDate,
+		"GIT_AUTHOR_NAME=Release Sandbox",
+		"GIT_AUTHOR_EMAIL="+sandboxIdentity+"@sandbox.invalid",
+		"GIT_COMMITTER_NAME=Release Sandbox",
+		"GIT_COMMITTER_EMAIL="+sandboxIdentity+"@sandbox.invalid",
+	)
+	if input != "" {
+		cmd.Stdin = strings.NewReader(input)
+	}
+	output, err := cmd.Output()
+	if err != nil {
+		var stderr string
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			stderr = strings.TrimSpace(string(exitErr.Stderr))
+		}
+		return "", fmt.Errorf("git %s: %w: %s", command, err, stderr)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// sandboxSeedBranches returns the branches a seeded repository starts with:
+// main, next and the source branch release branches are cut from
+func sandboxSeedBranches(host, repo string) []string {
+	branches := []string{"main", "next"}
+	if host != githubHost {
+		return branches
+	}
+	for _, r := range releaseRepositories() {
+		if forgeRepoPath(r.RepoURL) == repo && r.SourceBranch != "" && r.SourceBranch != "main" && r.SourceBranch != "next" {
+			branches = append(branches, r.SourceBranch)
+		}
+	}
+	return branches
+}
+
+// sandboxPipelineRun is the Konflux PipelineRun of the source branch seeded in
+// the release repositories, named after the repository
+const sandboxPipelineRun = `apiVersion: tekton.dev/v1
+kind: PipelineRun
+metadata:
+  annotations:
+    pipelinesascode.tekton.dev/on-cel-expression: event == "push" && target_branch == "next"
+  labels:
+    appstudio.openshift.io/application: openshift-pipelines-next
+    appstudio.openshift.io/component: %[1]s-next
+  name: %[1]s-next-on-push
+spec:
+  pipelineRef:
+    name: docker-build
+`
+
+// sandboxSeedFiles returns the files of a seeded repository and which are
+// executable. konflux-release-data and its fork get the tenant layout with a
+// no-op build-manifests.sh, the hack repository a repo file per release
+// repository, and any other repository a README and a .tekton PipelineRun.
+func sandboxSeedFiles(host, repo string) (map[string]string, map[string]bool) {
+	const kustomization = "apiVersion: kustomize.config.k8s.io/v1beta1\nkind: Kustomization\nresources:\n"
+	switch {
+	case host == gitlabHost:
+		return map[string]string{
+			"README.md":                         "# konflux-release-data\n\nSandbox copy of the tenant configuration.\n",
+			"tenants-config/build-manifests.sh": "#!/bin/sh\n# The sandbox does not build the tenant manifests with kustomize\nexit 0\n",
+			filepath.ToSlash(filepath.Join(tenantReleasePlanDir(), "kustomization.yaml")): kustomization,
+			filepath.ToSlash(filepath.Join(productRPADir(), "kustomization.yaml")):        kustomization,
+		}, map[string]bool{"tenants-config/build-manifests.sh": true}
+	case repo == hackRepo:
+		files := map[string]string{
+			"README.md": "# hack\n\nSandbox copy of the build configuration.\n",
+			"config/konflux/openshift-pipelines.yaml": "name: openshift-pipelines\nversion: next\nbranch: next\n",
+		}
+		for _, r := range releaseRepositories() {
+			if r.Skip || r.Name == "hack" {
+				continue
+			}
+			name := path.Base(forgeRepoPath(r.RepoURL))
+			files["config/konflux/repos/"+name+".yaml"] = fmt.Sprintf("name: %s\ncomponents:\n  - name: %s\nbranches:\n  - name: next\n    versions:\n      - next\n", name, name)
+		}
+		return files, nil
+	default:
+		name := path.Base(repo)
+		return map[string]string{
+			"README.md":                           fmt.Sprintf("# %s\n\nSandbox repository.\n", name),
+			tektonDir + "/" + name + "-push.yaml": fmt.Sprintf(sandboxPipelineRun, name),
+		}, nil
+	}
+}
@@ -0,0 +1,642 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/yaml"
+)
+
+// sandboxKinds maps the resources served by the fake cluster to their kind
+var sandboxKinds = map[string]string{
+	"namespaces":                 "Namespace",
+	"configmaps":                 "ConfigMap",
+	"secrets":                    "Secret",
+	"serviceaccounts":            "ServiceAccount",
+	"resourcequotas":             "ResourceQuota",
+	"customresourcedefinitions":  "CustomResourceDefinition",
+	"selfsubjectaccessreviews":   "SelfSubjectAccessReview",
+	"applications":               "Application",
+	"components":                 "Component",
+	"snapshots":                  "Snapshot",
+	"releases":                   "Release",
+	"releaseplans":               "ReleasePlan",
+	"releaseplanadmissions":      "ReleasePlanAdmission",
+	"enterprisecontractpolicies": "EnterpriseContractPolicy",
+	"pipelineruns":               "PipelineRun",
+}
+
+// sandboxClusterScoped are the resources of the fake cluster without a namespace
+var sandboxClusterScoped = map[string]bool{
+	"namespaces":                true,
+	"customresourcedefinitions": true,
+	"selfsubjectaccessreviews":  true,
+}
+
+// sandboxResourceRequest is a request of the Kubernetes API, parsed from its path
+type sandboxResourceRequest struct {
+	gvr         schema.GroupVersionResource
+	namespace   string
+	name        string
+	subresource string
+}
+
+// parseResourceRequest parses /api/v1/... and /apis/<group>/<version>/... paths
+func parseResourceRequest(path string) (sandboxResourceRequest, bool) {
+	var req sandboxResourceRequest
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	switch {
+	case len(segments) >= 3 && segments[0] == "api":
+		req.gvr.Version, segments = segments[1], segments[2:]
+	case len(segments) >= 4 && segments[0] == "apis":
+		req.gvr.Group, req.gvr.Version, segments = segments[1], segments[2], segments[3:]
+	default:
+		return req, false
+	}
+	if len(segments) >= 3 && segments[0] == "namespaces" {
+		req.namespace, segments = segments[1], segments[2:]
+	}
+	req.gvr.Resource = segments[0]
+	if len(segments) > 1 {
+		req.name = segments[1]
+	}
+	if len(segments) > 2 {
+		req.subresource = strings.Join(segments[2:], "/")
+	}
+	return req, req.gvr.Resource != ""
+}
+
+func (req sandboxResourceRequest) groupResource() schema.GroupResource {
+	return req.gvr.GroupResource()
+}
+
+// key identifies an object of the fake cluster, regardless of the version it is served at
+func (req sandboxResourceRequest) key() string {
+	return req.groupResource().String() + "/" + req.namespace + "/" + req.name
+}
+
+// sandboxWatcher streams the changes of the objects of a resource
+type sandboxWatcher struct {
+	resource  schema.GroupResource
+	namespace string
+	matches   func(*unstructured.Unstructured) bool
+	events    chan map[string]any
+}
+
+// clusterState holds the objects of the fake cluster
+type clusterState struct {
+	clusterMu       sync.Mutex
+	objects         map[string]*unstructured.Unstructured
+	resources       map[string]schema.GroupResource // Resource of the objects, by key
+	resourceVersion int64
+	watchers        map[*sandboxWatcher]bool
+	releaseDelay    time.Duration
+	done            chan struct{}
+}
+
+func (c *clusterState) init(releaseDelay time.Duration) {
+	c.objects = make(map[string]*unstructured.Unstructured)
+	c.resources = make(map[string]schema.GroupResource)
+	c.watchers = make(map[*sandboxWatcher]bool)
+	c.releaseDelay = releaseDelay
+	c.done = make(chan struct{})
+}
+
+func (c *clusterState) stop() {
+	c.clusterMu.Lock()
+	defer c.clusterMu.Unlock()
+	select {
+	case <-c.done:
+	default:
+		close(c.done)
+	}
+}
+
+// store saves an object under a new resource version and notifies the
+// watchers, the caller holding clusterMu
+func (c *clusterState) store(req sandboxResourceRequest, obj *unstructured.Unstructured, eventType string) {
+	c.resourceVersion++
+	obj.SetResourceVersion(strconv.FormatInt(c.resourceVersion, 10))
+	if eventType == "DELETED" {
+		delete(c.objects, req.key())
+		delete(c.resources, req.key())
+	} else {
+		c.objects[req.key()] = obj.DeepCopy()
+		c.resources[req.key()] = req.groupResource()
+	}
+	for w := range c.watchers {
+		if w.resource != req.groupResource() || (w.namespace != "" && w.namespace != req.namespace) || !w.matches(obj) {
+			continue
+		}
+		select {
+		case w.events <- map[string]any{"type": eventType, "object": obj.DeepCopy().Object}:
+		default:
+			// The watcher is not keeping up, it is closed and re-established by the client
+			delete(c.watchers, w)
+			close(w.events)
+		}
+	}
+}
+
+// list returns the objects of a resource matching a selector, by name
+func (c *clusterState) list(req sandboxResourceRequest, matches func(*unstructured.Unstructured) bool) []*unstructured.Unstructured {
+	var items []*unstructured.Unstructured
+	for key, obj := range c.objects {
+		if c.resources[key] != req.groupResource() || (req.namespace != "" && obj.GetNamespace() != req.namespace) || !matches(obj) {
+			continue
+		}
+		items = append(items, obj.DeepCopy())
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].GetNamespace() != items[j].GetNamespace() {
+			return items[i].GetNamespace() < items[j].GetNamespace()
+		}
+		return items[i].GetName() < items[j].GetName()
+	})
+	return items
+}
+
+// writeStatusError answers an API error as the Status object clients decode
+func writeStatusError(w http.ResponseWriter, err *apierrors.StatusError) {
+	status := err.ErrStatus
+	status.TypeMeta = metav1.TypeMeta{Kind: "Status", APIVersion: "v1"}
+	writeSandboxJSON(w, int(status.Code), status)
+}
+
+// selector returns the matcher of the label and field selectors of a request
+func selector(r *http.Request) (func(*unstructured.Unstructured) bool, error) {
+	labelSelector, err := labels.Parse(r.URL.Query().Get("labelSelector"))
+	if err != nil {
+		return nil, err
+	}
+	fieldSelector, err := fields.ParseSelector(r.URL.Query().Get("fieldSelector"))
+	if err != nil {
+		return nil, err
+	}
+	return func(obj *unstructured.Unstructured) bool {
+		return labelSelector.Matches(labels.Set(obj.GetLabels())) &&
+			fieldSelector.Matches(fields.Set{"metadata.name": obj.GetName(), "metadata.namespace": obj.GetNamespace()})
+	}, nil
+}
+
+// clusterHandler serves the Kubernetes API of the fake cluster: any
+// resource can be read, listed, watched, created, updated, merge-patched,
+// applied and deleted. Server-side apply is approximated by a merge patch.
+func (sb *sandbox) clusterHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req, ok := parseResourceRequest(r.URL.Path)
+		if !ok {
+			writeStatusError(w, apierrors.NewNotFound(schema.GroupResource{}, r.URL.Path))
+			return
+		}
+		if sandboxClusterScoped[req.gvr.Resource] {
+			if req.gvr.Resource == "namespaces" && req.name == "" && req.namespace != "" {
+				req.name = req.namespace
+			}
+			req.namespace = ""
+		}
+		matches, err := selector(r)
+		if err != nil {
+			writeStatusError(w, apierrors.NewBadRequest(err.Error()))
+			return
+		}
+
+		switch {
+		case r.Method == http.MethodGet && req.name == "" && (r.URL.Query().Get("watch") == "true" || r.URL.Query().Get("watch") == "1"):
+			sb.watchObjects(w, r, req, matches)
+		case r.Method == http.MethodGet && req.name == "":
+			sb.clusterMu.Lock()
+			items := sb.list(req, matches)
+			list := map[string]any{
+				"apiVersion": req.gvr.GroupVersion().String(),
+				"kind":       sandboxKind(req.gvr.Resource) + "List",
+				"metadata":   map[string]any{"resourceVersion": strconv.FormatInt(sb.resourceVersion, 10)},
+				"items":      []any{},
+			}
+			sb.clusterMu.Unlock()
+			for _, item := range items {
+				list["items"] = append(list["items"].([]any), item.Object)
+			}
+			writeSandboxJSON(w, http.StatusOK, list)
+		case r.Method == http.MethodGet:
+			sb.clusterMu.Lock()
+			obj, exists := sb.objects[req.key()]
+			if exists {
+				obj = obj.DeepCopy()
+			}
+			sb.clusterMu.Unlock()
+			if !exists {
+				writeStatusError(w, apierrors.NewNotFound(req.groupResource(), req.name))
+				return
+			}
+			writeSandboxJSON(w, http.StatusOK, obj.Object)
+		case r.Method == http.MethodPost:
+			sb.createObject(w, r, req)
+		case r.Method == http.MethodPut:
+			sb.updateObject(w, r, req)
+		case r.Method == http.MethodPatch:
+			sb.patchObject(w, r, req)
+		case r.Method == http.MethodDelete && req.name != "":
+			sb.clusterMu.Lock()
+			obj, exists := sb.objects[req.key()]
+			if exists && !dryRun(r) {
+				sb.store(req, obj.DeepCopy(), "DELETED")
+			}
+			sb.clusterMu.Unlock()
+			if !exists {
+				writeStatusError(w, apierrors.NewNotFound(req.groupResource(), req.name))
+				return
+			}
+			writeSandboxJSON(w, http.StatusOK, metav1.Status{TypeMeta: metav1.TypeMeta{Kind: "Status", APIVersion: "v1"}, Status: metav1.StatusSuccess})
+		default:
+			writeStatusError(w, apierrors.NewMethodNotSupported(req.groupResource(), r.Method))
+		}
+	})
+}
+
+func sandboxKind(resource string) string {
+	if kind, ok := sandboxKinds[resource]; ok {
+		return kind
+	}
+	return ""
+}
+
+func dryRun(r *http.Request) bool {
+	return slices.Contains(r.URL.Query()["dryRun"], metav1.DryRunAll)
+}
+
+// readObject decodes the JSON or YAML object of a request body
+func readObject(r *http.Request, req sandboxResourceRequest) (*unstructured.Unstructured, *apierrors.StatusError) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, apierrors.NewBadRequest(err.Error())
+	}
+	data, err := yaml.YAMLToJSON(body)
+	if err != nil {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("failed to decode object: %v", err))
+	}
+	var object map[string]any
+	if err := json.Unmarshal(data, &object); err != nil || object == nil {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("failed to decode object: %v", err))
+	}
+	obj := &unstructured.Unstructured{Object: object}
+	if obj.GetAPIVersion() == "" {
+		obj.SetAPIVersion(req.gvr.GroupVersion().String())
+	}
+	if obj.GetKind() == "" {
+		obj.SetKind(sandboxKind(req.gvr.Resource))
+	}
+	if req.namespace != "" {
+		obj.SetNamespace(req.namespace)
+	}
+	return obj, nil
+}
+
+// createObject stores a new object, naming it after its generateName when unnamed
+func (sb *sandbox) createObject(w http.ResponseWriter, r *http.Request, req sandboxResourceRequest) {
+	obj, statusErr := readObject(r, req)
+	if statusErr != nil {
+		writeStatusError(w, statusErr)
+		return
+	}
+	// Access reviews are answered rather than stored, everything is allowed in the sandbox
+	if req.gvr.Resource == "selfsubjectaccessreviews" {
+		obj.Object["status"] = map[string]any{"allowed": true, "reason": "the sandbox allows everything"}
+		writeSandboxJSON(w, http.StatusCreated, obj.Object)
+		return
+	}
+	if obj.GetName() == "" && obj.GetGenerateName() != "" {
+		obj.SetName(obj.GetGenerateName() + sandboxNameSuffix())
+	}
+	if obj.GetName() == "" {
+		writeStatusError(w, apierrors.NewInvalid(obj.GroupVersionKind().GroupKind(), "", field.ErrorList{field.Required(field.NewPath("metadata", "name"), "name or generateName is required")}))
+		return
+	}
+	req.name = obj.GetName()
+
+	sb.clusterMu.Lock()
+	defer sb.clusterMu.Unlock()
+	if _, exists := sb.objects[req.key()]; exists {
+		writeStatusError(w, apierrors.NewAlreadyExists(req.groupResource(), req.name))
+		return
+	}
+	obj.SetUID(types.UID(fmt.Sprintf("sandbox-%d", sb.resourceVersion+1)))
+	obj.SetCreationTimestamp(metav1.Now())
+	obj.SetGeneration(1)
+	if !dryRun(r) {
+		sb.store(req, obj, "ADDED")
+		if req.groupResource() == releaseGVR.GroupResource() {
+			go sb.reconcileRelease(req)
+		}
+	}
+	writeSandboxJSON(w, http.StatusCreated, obj.Object)
+}
+
+// updateObject replaces an existing object, or its status
+func (sb *sandbox) updateObject(w http.ResponseWriter, r *http.Request, req sandboxResourceRequest) {
+	obj, statusErr := readObject(r, req)
+	if statusErr != nil {
+		writeStatusError(w, statusErr)
+		return
+	}
+	sb.clusterMu.Lock()
+	defer sb.clusterMu.Unlock()
+	existing, exists := sb.objects[req.key()]
+	if !exists {
+		writeStatusError(w, apierrors.NewNotFound(req.groupResource(), req.name))
+		return
+	}
+	if rv := obj.GetResourceVersion(); rv != "" && rv != existing.GetResourceVersion() {
+		writeStatusError(w, apierrors.NewConflict(req.groupResource(), req.name, fmt.Errorf("the object has been modified")))
+		return
+	}
+	updated := existing.DeepCopy()
+	if req.subresource == "status" {
+		updated.Object["status"] = obj.Object["status"]
+	} else {
+		obj.SetUID(existing.GetUID())
+		obj.SetCreationTimestamp(existing.GetCreationTimestamp())
+		obj.SetGeneration(existing.GetGeneration() + 1)
+		obj.Object["status"] = existing.Object["status"]
+		updated = obj
+	}
+	if !dryRun(r) {
+		sb.store(req, updated, "MODIFIED")
+	}
+	writeSandboxJSON(w, http.StatusOK, updated.Object)
+}
+
+// patchObject merge-patches an existing object. An apply patch creates the
+// object when missing.
+func (sb *sandbox) patchObject(w http.ResponseWriter, r *http.Request, req sandboxResourceRequest) {
+	contentType := strings.TrimSpace(strings.Split(r.Header.Get("Content-Type"), ";")[0])
+	if contentType == string(types.JSONPatchType) {
+		writeStatusError(w, apierrors.NewGenericServerResponse(http.StatusUnsupportedMediaType, "patch", req.groupResource(), req.name, "the sandbox does not support JSON patches", 0, false))
+		return
+	}
+	patch, statusErr := readObject(r, req)
+	if statusErr != nil {
+		writeStatusError(w, statusErr)
+		return
+	}
+
+	sb.clusterMu.Lock()
+	defer sb.clusterMu.Unlock()
+	existing, exists := sb.objects[req.key()]
+	if !exists {
+		if contentType != string(types.ApplyPatchType) {
+			writeStatusError(w, apierrors.NewNotFound(req.groupResource(), req.name))
+			return
+		}
+		patch.SetName(req.name)
+		patch.SetUID(types.UID(fmt.Sprintf("sandbox-%d", sb.resourceVersion+1)))
+		patch.SetCreationTimestamp(metav1.Now())
+		patch.SetGeneration(1)
+		if !dryRun(r) {
+			sb.store(req, patch, "ADDED")
+		}
+		writeSandboxJSON(w, http.StatusCreated, patch.Object)
+		return
+	}
+
+	updated := existing.DeepCopy()
+	updated.Object = mergePatch(updated.Object, patch.Object).(map[string]any)
+	updated.SetUID(existing.GetUID())
+	updated.SetResourceVersion(existing.GetResourceVersion())
+	if !equalJSON(existing.Object["spec"], updated.Object["spec"]) {
+		updated.SetGeneration(existing.GetGeneration() + 1)
+	}
+	if !dryRun(r) {
+		sb.store(req, updated, "MODIFIED")
+	}
+	writeSandboxJSON(w, http.StatusOK, updated.Object)
+}
+
+// mergePatch applies a JSON merge patch (RFC 7386) to a decoded JSON document
+func mergePatch(target, patch any) any {
+	patchMap, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	targetMap, ok := target.(map[string]any)
+	if !ok {
+		targetMap = make(map[string]any)
+	}
+	for key, value := range patchMap {
+		if value == nil {
+			delete(targetMap, key)
+			continue
+		}
+		targetMap[key] = mergePatch(targetMap[key], value)
+	}
+	return targetMap
+}
+
+func equalJSON(a, b any) bool {
+	left, _ := json.Marshal(a)
+	right, _ := json.Marshal(b)
+	return string(left) == string(right)
+}
+
+// watchObjects streams the changes of the objects of a resource as watch
+// events. Objects changed after the requested resource version are sent
+// first, all the current objects when no version is requested.
+func (sb *sandbox) watchObjects(w http.ResponseWriter, r *http.Request, req sandboxResourceRequest, matches func(*unstructured.Unstructured) bool) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeStatusError(w, apierrors.NewInternalError(fmt.Errorf("streaming is not supported")))
+		return
+	}
+	watcher := &sandboxWatcher{resource: req.groupResource(), namespace: req.namespace, matches: matches, events: make(chan map[string]any, 100)}
+	since, _ := strconv.ParseInt(r.URL.Query().Get("resourceVersion"), 10, 64)
+
+	sb.clusterMu.Lock()
+	var initial []map[string]any
+	for _, obj := range sb.list(req, matches) {
+		rv, _ := strconv.ParseInt(obj.GetResourceVersion(), 10, 64)
+		switch {
+		case since == 0:
+			initial = append(initial, map[string]any{"type": "ADDED", "object": obj.Object})
+		case rv > since:
+			initial = append(initial, map[string]any{"type": "MODIFIED", "object": obj.Object})
+		}
+	}
+	sb.watchers[watcher] = true
+	sb.clusterMu.Unlock()
+	defer func() {
+		sb.clusterMu.Lock()
+		if sb.watchers[watcher] {
+			delete(sb.watchers, watcher)
+			close(watcher.events)
+		}
+		sb.clusterMu.Unlock()
+	}()
+
+	timeout := time.Hour
+	if seconds, err := strconv.Atoi(r.URL.Query().Get("timeoutSeconds")); err == nil && seconds > 0 {
+		timeout = time.Duration(seconds) * time.Second
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	encoder := json.NewEncoder(w)
+	for _, event := range initial {
+		_ = encoder.Encode(event)
+	}
+	flusher.Flush()
+	for {
+		select {
+		case event, open := <-watcher.events:
+			if !open {
+				return
+			}
+			if err := encoder.Encode(event); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		case <-sb.done:
+			return
+		case <-timer.C:
+			return
+		}
+	}
+}
+
+// sandboxNameSuffix returns the random suffix of a generated name
+func sandboxNameSuffix() string {
+	const alphabet = "bcdfghjklmnpqrstvwxz2456789"
+	suffix := make([]byte, 5)
+	for i := range suffix {
+		suffix[i] = alphabet[rand.IntN(len(alphabet))]
+	}
+	return string(suffix)
+}
+
+// reconcileRelease plays the release service for a created Release: after
+// the release delay, the Release succeeds when its ReleasePlan exists in its
+// namespace and fails otherwise
+func (sb *sandbox) reconcileRelease(req sandboxResourceRequest) {
+	progress := func(status, reason, message string) bool {
+		sb.clusterMu.Lock()
+		defer sb.clusterMu.Unlock()
+		obj, exists := sb.objects[req.key()]
+		if !exists {
+			return false
+		}
+		release := obj.DeepCopy()
+		conditions := []any{map[string]any{
+			"type":               releasedCondition,
+			"status":             status,
+			"reason":             reason,
+			"message":            message,
+			"lastTransitionTime": time.Now().UTC().Format(time.RFC3339),
+		}}
+		if reason != "Progressing" {
+			validated := "True"
+			if status != "True" {
+				validated = "False"
+			}
+			conditions = append([]any{map[string]any{"type": "Validated", "status": validated, "reason": reason}}, conditions...)
+			_ = unstructured.SetNestedField(release.Object, time.Now().UTC().Format(time.RFC3339), "status", "completionTime")
+		}
+		_ = unstructured.SetNestedSlice(release.Object, conditions, "status", "conditions")
+		sb.store(req, release, "MODIFIED")
+		return true
+	}
+
+	wait := func(d time.Duration) bool {
+		select {
+		case <-time.After(d):
+			return true
+		case <-sb.done:
+			return false
+		}
+	}
+	if !wait(sb.releaseDelay/2) || !progress("False", "Progressing", "Release processing by the sandbox release service") || !wait(sb.releaseDelay/2) {
+		return
+	}
+
+	sb.clusterMu.Lock()
+	release, exists := sb.objects[req.key()]
+	var plan string
+	if exists {
+		plan, _, _ = unstructured.NestedString(release.Object, "spec", "releasePlan")
+	}
+	planReq := sandboxResourceRequest{gvr: releasePlanGVR, namespace: req.namespace, name: plan}
+	_, planExists := sb.objects[planReq.key()]
+	sb.clusterMu.Unlock()
+	if planExists {
+		progress("True", "Succeeded", "Released by the sandbox release service")
+	} else {
+		progress("False", "Failed", fmt.Sprintf("ReleasePlan %s not found in namespace %s", plan, req.namespace))
+	}
+}
+
+// seedCluster creates the namespaces of the tenant and of the release
+// pipelines, the objects the release plans reference and the CRDs of the
+// release plans
+func (sb *sandbox) seedCluster() {
+	seed := func(gvr schema.GroupVersionResource, namespace, name string, object map[string]any) {
+		obj := &unstructured.Unstructured{Object: object}
+		obj.SetAPIVersion(gvr.GroupVersion().String())
+		obj.SetKind(sandboxKind(gvr.Resource))
+		obj.SetNamespace(namespace)
+		obj.SetName(name)
+		obj.SetUID(types.UID(fmt.Sprintf("sandbox-seed-%s-%s", gvr.Resource, name)))
+		obj.SetCreationTimestamp(metav1.Now())
+		sb.clusterMu.Lock()
+		sb.store(sandboxResourceRequest{gvr: gvr, namespace: namespace, name: name}, obj, "ADDED")
+		sb.clusterMu.Unlock()
+	}
+	core := func(resource string) schema.GroupVersionResource {
+		return schema.GroupVersionResource{Version: "v1", Resource: resource}
+	}
+
+	for _, namespace := range []string{tenantNamespace(), relengTenant()} {
+		seed(core("namespaces"), "", namespace, map[string]any{})
+	}
+	for _, env := range []string{"stage", "prod"} {
+		for _, isFBC := range []bool{false, true} {
+			envConfig := getEnvSpecificValues(env, isFBC)
+			seed(enterpriseContractPolicyGVR, relengTenant(), envConfig.Policy, map[string]any{"spec": map[string]any{"sources": []any{}}})
+			seed(core("serviceaccounts"), relengTenant(), envConfig.ServiceAccount, map[string]any{})
+		}
+		if creds, ok := getFBCConfig(env)["publishingCredentials"].(string); ok {
+			seed(core("secrets"), relengTenant(), creds, map[string]any{"type": "Opaque"})
+		}
+	}
+	entries, _ := embeddedCRDs.ReadDir("crds")
+	for _, entry := range entries {
+		content, err := embeddedCRDs.ReadFile("crds/" + entry.Name())
+		if err != nil {
+			continue
+		}
+		var crd map[string]any
+		if err := yaml.Unmarshal(content, &crd); err != nil {
+			continue
+		}
+		name, _, _ := unstructured.NestedString(crd, "metadata", "name")
+		seed(customResourceDefinitionGVR, "", name, crd)
+	}
+}
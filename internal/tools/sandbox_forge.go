@@ -0,0 +1,768 @@
+package tools
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sandboxChangeRequest is a pull request of the fake GitHub or a merge request of the fake GitLab
+type sandboxChangeRequest struct {
+	Number       int
+	Title        string
+	Body         string
+	SourceRepo   string
+	SourceBranch string
+	TargetBranch string
+	Labels       []string
+	Reviewers    []string
+	Comments     []string
+	State        string
+	Created      time.Time
+}
+
+// forgeState holds what the fake forges keep besides the repositories
+type forgeState struct {
+	forgeMu        sync.Mutex
+	changeRequests map[string][]*sandboxChangeRequest // By host/repository of the target
+	projectIDs     map[string]int                     // GitLab project IDs by path
+	projects       []string                           // GitLab project paths by ID - 1
+	protected      map[string]bool                    // Protected branches by host/repository:branch
+}
+
+func (f *forgeState) init() {
+	f.changeRequests = make(map[string][]*sandboxChangeRequest)
+	f.projectIDs = make(map[string]int)
+	f.protected = make(map[string]bool)
+}
+
+// changeRequest returns a change request of a repository by number
+func (f *forgeState) changeRequest(key string, number int) *sandboxChangeRequest {
+	f.forgeMu.Lock()
+	defer f.forgeMu.Unlock()
+	for _, cr := range f.changeRequests[key] {
+		if cr.Number == number {
+			return cr
+		}
+	}
+	return nil
+}
+
+// openChangeRequest records a change request of a repository, numbered after the previous ones
+func (f *forgeState) openChangeRequest(key string, cr *sandboxChangeRequest) {
+	f.forgeMu.Lock()
+	defer f.forgeMu.Unlock()
+	cr.Number = len(f.changeRequests[key]) + 1
+	cr.State = "open"
+	cr.Created = time.Now().UTC()
+	f.changeRequests[key] = append(f.changeRequests[key], cr)
+}
+
+// listChangeRequests returns the change requests of a repository in a state,
+// or any state for "all", whose title contains search, most recent first
+func (f *forgeState) listChangeRequests(key, state, search string) []*sandboxChangeRequest {
+	f.forgeMu.Lock()
+	defer f.forgeMu.Unlock()
+	var matching []*sandboxChangeRequest
+	for i := len(f.changeRequests[key]) - 1; i >= 0; i-- {
+		cr := f.changeRequests[key][i]
+		if (state == "all" || cr.State == state) && strings.Contains(cr.Title, search) {
+			matching = append(matching, cr)
+		}
+	}
+	return matching
+}
+
+// projectID returns the ID of a GitLab project, assigning one on first use
+func (f *forgeState) projectID(project string) int {
+	f.forgeMu.Lock()
+	defer f.forgeMu.Unlock()
+	if id, ok := f.projectIDs[project]; ok {
+		return id
+	}
+	f.projects = append(f.projects, project)
+	f.projectIDs[project] = len(f.projects)
+	return len(f.projects)
+}
+
+// project returns the path of a GitLab project from its ID or path
+func (f *forgeState) project(idOrPath string) (string, bool) {
+	id, err := strconv.Atoi(idOrPath)
+	if err != nil {
+		return idOrPath, true
+	}
+	f.forgeMu.Lock()
+	defer f.forgeMu.Unlock()
+	if id < 1 || id > len(f.projects) {
+		return "", false
+	}
+	return f.projects[id-1], true
+}
+
+// sandboxPage returns the page of items selected by the page and per_page
+// query parameters, as the forge APIs paginate their lists
+func sandboxPage[T any](items []T, query url.Values) []T {
+	perPage, err := strconv.Atoi(query.Get("per_page"))
+	if err != nil || perPage <= 0 {
+		perPage = 20
+	}
+	page, err := strconv.Atoi(query.Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	start := min((page-1)*perPage, len(items))
+	return items[start:min(start+perPage, len(items))]
+}
+
+func writeSandboxJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeSandboxError(w http.ResponseWriter, status int, format string, args ...any) {
+	writeSandboxJSON(w, status, map[string]string{"message": fmt.Sprintf(format, args...)})
+}
+
+// resolve returns the commit a branch, tag, ref or SHA points to in a bare repository
+func (sb *sandbox) resolve(dir, ref string) (string, bool) {
+	sha, err := sb.git(dir, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	return sha, err == nil && sha != ""
+}
+
+// sandboxCommit is a commit as returned by the GitHub API
+type sandboxCommit struct {
+	SHA    string `json:"sha"`
+	Commit struct {
+		Message string `json:"message"`
+		Author  struct {
+			Name string    `json:"name"`
+			Date time.Time `json:"date"`
+		} `json:"author"`
+	} `json:"commit"`
+}
+
+// commits lists the commits of a range of a bare repository, oldest first
+func (sb *sandbox) commits(dir string, args ...string) ([]sandboxCommit, error) {
+	output, err := sb.git(dir, append([]string{"log", "--reverse", "--format=%H%x1f%an%x1f%aI%x1f%B%x1e"}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	commits := []sandboxCommit{}
+	for _, record := range strings.Split(output, "\x1e") {
+		fields := strings.SplitN(strings.TrimSpace(record), "\x1f", 4)
+		if len(fields) < 4 {
+			continue
+		}
+		var commit sandboxCommit
+		commit.SHA = fields[0]
+		commit.Commit.Author.Name = fields[1]
+		commit.Commit.Author.Date, _ = time.Parse(time.RFC3339, fields[2])
+		commit.Commit.Message = strings.TrimSpace(fields[3])
+		commits = append(commits, commit)
+	}
+	return commits, nil
+}
+
+// githubPullRequest renders a pull request as returned by the GitHub API
+func (sb *sandbox) githubPullRequest(repo string, cr *sandboxChangeRequest) map[string]any {
+	owner, _, _ := strings.Cut(cr.SourceRepo, "/")
+	return map[string]any{
+		"number":     cr.Number,
+		"title":      cr.Title,
+		"body":       cr.Body,
+		"state":      cr.State,
+		"html_url":   fmt.Sprintf("%s/%s/%s/pull/%d", sb.forge.URL, githubHost, repo, cr.Number),
+		"created_at": cr.Created,
+		"user":       map[string]string{"login": sandboxIdentity},
+		"head":       map[string]string{"ref": cr.SourceBranch, "label": owner + ":" + cr.SourceBranch},
+		"base":       map[string]string{"ref": cr.TargetBranch},
+	}
+}
+
+// graphqlRepositoryAlias matches the aliased repository queries of branchesExist
+var graphqlRepositoryAlias = regexp.MustCompile(`(r\d+): repository\(owner: \$owner, name: "([^"]+)"\)`)
+
+// githubHandler serves the subset of the GitHub REST and GraphQL APIs the
+// tools call, on the sandbox repositories
+func (sb *sandbox) githubHandler() http.Handler {
+	mux := http.NewServeMux()
+	// repo resolves the repository of a request, answering a 404 when it is invalid
+	repo := func(w http.ResponseWriter, r *http.Request) (string, string, bool) {
+		name := r.PathValue("owner") + "/" + r.PathValue("name")
+		dir, err := sb.repository(githubHost, name)
+		if err != nil {
+			writeSandboxError(w, http.StatusNotFound, "Not Found: %v", err)
+			return "", "", false
+		}
+		return name, dir, true
+	}
+	pullRequest := func(w http.ResponseWriter, r *http.Request, name string) *sandboxChangeRequest {
+		number, _ := strconv.Atoi(r.PathValue("number"))
+		cr := sb.changeRequest(githubHost+"/"+name, number)
+		if cr == nil {
+			writeSandboxError(w, http.StatusNotFound, "Not Found")
+		}
+		return cr
+	}
+
+	mux.HandleFunc("GET /user", func(w http.ResponseWriter, r *http.Request) {
+		writeSandboxJSON(w, http.StatusOK, map[string]string{"login": sandboxIdentity})
+	})
+	mux.HandleFunc("GET /search/issues", func(w http.ResponseWriter, r *http.Request) {
+		writeSandboxJSON(w, http.StatusOK, map[string]any{"total_count": 0, "items": []any{}})
+	})
+	mux.HandleFunc("POST /graphql", func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Query     string         `json:"query"`
+			Variables map[string]any `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			writeSandboxError(w, http.StatusBadRequest, "Problems parsing JSON")
+			return
+		}
+		owner, _ := request.Variables["owner"].(string)
+		ref, _ := request.Variables["ref"].(string)
+		data := make(map[string]any)
+		for _, match := range graphqlRepositoryAlias.FindAllStringSubmatch(request.Query, -1) {
+			dir, err := sb.repository(githubHost, owner+"/"+match[2])
+			if err != nil {
+				data[match[1]] = nil
+				continue
+			}
+			if _, ok := sb.resolve(dir, ref); ok {
+				data[match[1]] = map[string]any{"ref": map[string]string{"name": strings.TrimPrefix(ref, "refs/heads/")}}
+			} else {
+				data[match[1]] = map[string]any{"ref": nil}
+			}
+		}
+		writeSandboxJSON(w, http.StatusOK, map[string]any{"data": data})
+	})
+
+	mux.HandleFunc("/repos/{owner}/{name}/branches/{branch...}", func(w http.ResponseWriter, r *http.Request) {
+		name, dir, ok := repo(w, r)
+		if !ok {
+			return
+		}
+		branch, protection := strings.CutSuffix(r.PathValue("branch"), "/protection")
+		sha, exists := sb.resolve(dir, "refs/heads/"+branch)
+		switch {
+		case !exists:
+			writeSandboxError(w, http.StatusNotFound, "Branch not found")
+		case protection && r.Method == http.MethodPut:
+			sb.forgeMu.Lock()
+			sb.protected[githubHost+"/"+name+":"+branch] = true
+			sb.forgeMu.Unlock()
+			writeSandboxJSON(w, http.StatusOK, map[string]any{"url": r.URL.String()})
+		case !protection && r.Method == http.MethodGet:
+			sb.forgeMu.Lock()
+			protected := sb.protected[githubHost+"/"+name+":"+branch]
+			sb.forgeMu.Unlock()
+			writeSandboxJSON(w, http.StatusOK, map[string]any{"name": branch, "protected": protected, "commit": map[string]string{"sha": sha}})
+		default:
+			writeSandboxError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		}
+	})
+	mux.HandleFunc("GET /repos/{owner}/{name}/compare/{spec...}", func(w http.ResponseWriter, r *http.Request) {
+		_, dir, ok := repo(w, r)
+		if !ok {
+			return
+		}
+		base, head, found := strings.Cut(r.PathValue("spec"), "...")
+		baseSHA, baseOK := sb.resolve(dir, base)
+		headSHA, headOK := sb.resolve(dir, head)
+		if !found || !baseOK || !headOK {
+			writeSandboxError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		commits, err := sb.commits(dir, baseSHA+".."+headSHA)
+		if err != nil {
+			writeSandboxError(w, http.StatusInternalServerError, "%v", err)
+			return
+		}
+		behind, err := sb.git(dir, "rev-list", "--count", headSHA+".."+baseSHA)
+		if err != nil {
+			writeSandboxError(w, http.StatusInternalServerError, "%v", err)
+			return
+		}
+		behindBy, _ := strconv.Atoi(behind)
+		status := "diverged"
+		switch {
+		case len(commits) == 0 && behindBy == 0:
+			status = "identical"
+		case behindBy == 0:
+			status = "ahead"
+		case len(commits) == 0:
+			status = "behind"
+		}
+		writeSandboxJSON(w, http.StatusOK, map[string]any{"status": status, "ahead_by": len(commits), "behind_by": behindBy, "total_commits": len(commits), "commits": commits})
+	})
+	mux.HandleFunc("GET /repos/{owner}/{name}/commits", func(w http.ResponseWriter, r *http.Request) {
+		_, dir, ok := repo(w, r)
+		if !ok {
+			return
+		}
+		ref := r.URL.Query().Get("sha")
+		if ref == "" {
+			ref = "main"
+		}
+		sha, exists := sb.resolve(dir, ref)
+		if !exists {
+			writeSandboxError(w, http.StatusNotFound, "No commit found for SHA: %s", ref)
+			return
+		}
+		args := []string{"-n", "100", sha}
+		if page, err := strconv.Atoi(r.URL.Query().Get("page")); err == nil && page > 1 {
+			args = append(args, fmt.Sprintf("--skip=%d", (page-1)*100))
+		}
+		if since := r.URL.Query().Get("since"); since != "" {
+			args = append(args, "--since="+since)
+		}
+		commits, err := sb.commits(dir, args...)
+		if err != nil {
+			writeSandboxError(w, http.StatusInternalServerError, "%v", err)
+			return
+		}
+		// The API lists the newest commits first
+		for i, j := 0, len(commits)-1; i < j; i, j = i+1, j-1 {
+			commits[i], commits[j] = commits[j], commits[i]
+		}
+		writeSandboxJSON(w, http.StatusOK, commits)
+	})
+	mux.HandleFunc("/repos/{owner}/{name}/commits/{ref...}", func(w http.ResponseWriter, r *http.Request) {
+		_, dir, ok := repo(w, r)
+		if !ok {
+			return
+		}
+		ref, status := strings.CutSuffix(r.PathValue("ref"), "/status")
+		ref, comments := strings.CutSuffix(ref, "/comments")
+		sha, exists := sb.resolve(dir, ref)
+		switch {
+		case !exists:
+			writeSandboxError(w, http.StatusUnprocessableEntity, "No commit found for SHA: %s", ref)
+		case status && r.Method == http.MethodGet:
+			// Every commit passes its checks in the sandbox
+			writeSandboxJSON(w, http.StatusOK, map[string]any{"state": "success", "sha": sha, "statuses": []any{}})
+		case comments && r.Method == http.MethodPost:
+			writeSandboxJSON(w, http.StatusCreated, map[string]any{"commit_id": sha})
+		case !status && !comments && r.Method == http.MethodGet:
+			commits, err := sb.commits(dir, "-n", "1", "--no-walk", sha)
+			if err != nil || len(commits) == 0 {
+				writeSandboxError(w, http.StatusInternalServerError, "failed to read %s: %v", sha, err)
+				return
+			}
+			writeSandboxJSON(w, http.StatusOK, commits[0])
+		default:
+			writeSandboxError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		}
+	})
+	mux.HandleFunc("GET /repos/{owner}/{name}/git/matching-refs/{prefix...}", func(w http.ResponseWriter, r *http.Request) {
+		_, dir, ok := repo(w, r)
+		if !ok {
+			return
+		}
+		output, err := sb.git(dir, "for-each-ref", "--format=%(refname) %(objectname)")
+		if err != nil {
+			writeSandboxError(w, http.StatusInternalServerError, "%v", err)
+			return
+		}
+		refs := []map[string]any{}
+		prefix := "refs/" + r.PathValue("prefix")
+		for _, line := range strings.Split(output, "\n") {
+			ref, sha, _ := strings.Cut(line, " ")
+			if ref != "" && strings.HasPrefix(ref, prefix) {
+				refs = append(refs, map[string]any{"ref": ref, "object": map[string]string{"sha": sha}})
+			}
+		}
+		writeSandboxJSON(w, http.StatusOK, refs)
+	})
+	mux.HandleFunc("GET /repos/{owner}/{name}/git/ref/heads/{branch...}", func(w http.ResponseWriter, r *http.Request) {
+		_, dir, ok := repo(w, r)
+		if !ok {
+			return
+		}
+		branch := r.PathValue("branch")
+		sha, exists := sb.resolve(dir, "refs/heads/"+branch)
+		if !exists {
+			writeSandboxError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		writeSandboxJSON(w, http.StatusOK, map[string]any{"ref": "refs/heads/" + branch, "object": map[string]string{"sha": sha, "type": "commit"}})
+	})
+	mux.HandleFunc("POST /repos/{owner}/{name}/git/refs", func(w http.ResponseWriter, r *http.Request) {
+		_, dir, ok := repo(w, r)
+		if !ok {
+			return
+		}
+		var request struct {
+			Ref string `json:"ref"`
+			SHA string `json:"sha"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil || !strings.HasPrefix(request.Ref, "refs/") {
+			writeSandboxError(w, http.StatusUnprocessableEntity, "Invalid request")
+			return
+		}
+		if _, exists := sb.resolve(dir, request.Ref); exists {
+			writeSandboxError(w, http.StatusUnprocessableEntity, "Reference already exists")
+			return
+		}
+		if _, err := sb.git(dir, "update-ref", request.Ref, request.SHA); err != nil {
+			writeSandboxError(w, http.StatusUnprocessableEntity, "Object does not exist")
+			return
+		}
+		writeSandboxJSON(w, http.StatusCreated, map[string]any{"ref": request.Ref, "object": map[string]string{"sha": request.SHA}})
+	})
+	mux.HandleFunc("DELETE /repos/{owner}/{name}/git/refs/heads/{branch...}", func(w http.ResponseWriter, r *http.Request) {
+		_, dir, ok := repo(w, r)
+		if !ok {
+			return
+		}
+		ref := "refs/heads/" + r.PathValue("branch")
+		if _, exists := sb.resolve(dir, ref); !exists {
+			writeSandboxError(w, http.StatusUnprocessableEntity, "Reference does not exist")
+			return
+		}
+		if _, err := sb.git(dir, "update-ref", "-d", ref); err != nil {
+			writeSandboxError(w, http.StatusInternalServerError, "%v", err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("GET /repos/{owner}/{name}/contents/{path...}", func(w http.ResponseWriter, r *http.Request) {
+		_, dir, ok := repo(w, r)
+		if !ok {
+			return
+		}
+		ref := r.URL.Query().Get("ref")
+		if ref == "" {
+			ref = "main"
+		}
+		content, err := sb.git(dir, "show", ref+":"+r.PathValue("path"))
+		if err != nil {
+			writeSandboxError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		// git strips the trailing newline of the output, files end with one
+		encoded := base64.StdEncoding.EncodeToString([]byte(content + "\n"))
+		writeSandboxJSON(w, http.StatusOK, map[string]any{"type": "file", "path": r.PathValue("path"), "encoding": "base64", "content": encoded})
+	})
+	mux.HandleFunc("POST /repos/{owner}/{name}/pulls", func(w http.ResponseWriter, r *http.Request) {
+		name, dir, ok := repo(w, r)
+		if !ok {
+			return
+		}
+		var request struct {
+			Title string `json:"title"`
+			Body  string `json:"body"`
+			Head  string `json:"head"`
+			Base  string `json:"base"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Title == "" {
+			writeSandboxError(w, http.StatusUnprocessableEntity, "Validation Failed")
+			return
+		}
+		cr := &sandboxChangeRequest{Title: request.Title, Body: request.Body, SourceRepo: name, SourceBranch: request.Head, TargetBranch: request.Base}
+		sourceDir := dir
+		if owner, branch, fromFork := strings.Cut(request.Head, ":"); fromFork {
+			cr.SourceRepo, cr.SourceBranch = owner+"/"+r.PathValue("name"), branch
+			var err error
+			if sourceDir, err = sb.repository(githubHost, cr.SourceRepo); err != nil {
+				writeSandboxError(w, http.StatusUnprocessableEntity, "Validation Failed: %v", err)
+				return
+			}
+		}
+		if _, exists := sb.resolve(sourceDir, "refs/heads/"+cr.SourceBranch); !exists {
+			writeSandboxError(w, http.StatusUnprocessableEntity, "Validation Failed: head %s does not exist", request.Head)
+			return
+		}
+		if _, exists := sb.resolve(dir, "refs/heads/"+cr.TargetBranch); !exists {
+			writeSandboxError(w, http.StatusUnprocessableEntity, "Validation Failed: base %s does not exist", request.Base)
+			return
+		}
+		sb.openChangeRequest(githubHost+"/"+name, cr)
+		writeSandboxJSON(w, http.StatusCreated, sb.githubPullRequest(name, cr))
+	})
+	mux.HandleFunc("GET /repos/{owner}/{name}/pulls", func(w http.ResponseWriter, r *http.Request) {
+		name, _, ok := repo(w, r)
+		if !ok {
+			return
+		}
+		state := r.URL.Query().Get("state")
+		if state == "" {
+			state = "open"
+		}
+		pulls := []map[string]any{}
+		for _, cr := range sb.listChangeRequests(githubHost+"/"+name, state, "") {
+			pulls = append(pulls, sb.githubPullRequest(name, cr))
+		}
+		writeSandboxJSON(w, http.StatusOK, sandboxPage(pulls, r.URL.Query()))
+	})
+	mux.HandleFunc("PATCH /repos/{owner}/{name}/pulls/{number}", func(w http.ResponseWriter, r *http.Request) {
+		name, _, ok := repo(w, r)
+		if !ok {
+			return
+		}
+		cr := pullRequest(w, r, name)
+		if cr == nil {
+			return
+		}
+		var request struct {
+			State string `json:"state"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		sb.forgeMu.Lock()
+		if request.State != "" {
+			cr.State = request.State
+		}
+		sb.forgeMu.Unlock()
+		writeSandboxJSON(w, http.StatusOK, sb.githubPullRequest(name, cr))
+	})
+	mux.HandleFunc("POST /repos/{owner}/{name}/pulls/{number}/requested_reviewers", func(w http.ResponseWriter, r *http.Request) {
+		name, _, ok := repo(w, r)
+		if !ok {
+			return
+		}
+		cr := pullRequest(w, r, name)
+		if cr == nil {
+			return
+		}
+		var request struct {
+			Reviewers []string `json:"reviewers"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		sb.forgeMu.Lock()
+		cr.Reviewers = append(cr.Reviewers, request.Reviewers...)
+		sb.forgeMu.Unlock()
+		writeSandboxJSON(w, http.StatusCreated, sb.githubPullRequest(name, cr))
+	})
+	mux.HandleFunc("POST /repos/{owner}/{name}/issues/{number}/{field}", func(w http.ResponseWriter, r *http.Request) {
+		name, _, ok := repo(w, r)
+		if !ok {
+			return
+		}
+		cr := pullRequest(w, r, name)
+		if cr == nil {
+			return
+		}
+		var request struct {
+			Labels []string `json:"labels"`
+			Body   string   `json:"body"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		sb.forgeMu.Lock()
+		defer sb.forgeMu.Unlock()
+		switch r.PathValue("field") {
+		case "labels":
+			cr.Labels = append(cr.Labels, request.Labels...)
+			writeSandboxJSON(w, http.StatusOK, cr.Labels)
+		case "comments":
+			cr.Comments = append(cr.Comments, request.Body)
+			writeSandboxJSON(w, http.StatusCreated, map[string]string{"body": request.Body})
+		default:
+			writeSandboxError(w, http.StatusNotFound, "Not Found")
+		}
+	})
+	return mux
+}
+
+// gitlabMergeRequest renders a merge request as returned by the GitLab API
+func (sb *sandbox) gitlabMergeRequest(project string, cr *sandboxChangeRequest) map[string]any {
+	state := cr.State
+	if state == "open" {
+		state = "opened"
+	}
+	return map[string]any{
+		"iid":               cr.Number,
+		"title":             cr.Title,
+		"description":       cr.Body,
+		"state":             state,
+		"web_url":           fmt.Sprintf("%s/%s/%s/-/merge_requests/%d", sb.forge.URL, gitlabHost, project, cr.Number),
+		"source_branch":     cr.SourceBranch,
+		"target_branch":     cr.TargetBranch,
+		"source_project_id": sb.projectID(cr.SourceRepo),
+		"target_project_id": sb.projectID(project),
+		"labels":            cr.Labels,
+		"created_at":        cr.Created,
+	}
+}
+
+// gitlabHandler serves the subset of the GitLab REST API the tools call, on
+// the sandbox repositories. Project paths are URL-encoded in a single path
+// segment, so requests are routed on the escaped path.
+func (sb *sandbox) gitlabHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		segments := strings.Split(strings.TrimPrefix(r.URL.EscapedPath(), "/"), "/")
+		for i, segment := range segments {
+			if unescaped, err := url.PathUnescape(segment); err == nil {
+				segments[i] = unescaped
+			}
+		}
+		query := r.URL.Query()
+
+		if len(segments) == 1 && segments[0] == "users" && r.Method == http.MethodGet {
+			writeSandboxJSON(w, http.StatusOK, []map[string]any{{"id": 1, "username": query.Get("username")}})
+			return
+		}
+		if len(segments) < 2 || segments[0] != "projects" {
+			writeSandboxError(w, http.StatusNotFound, "404 Not Found")
+			return
+		}
+		project, ok := sb.project(segments[1])
+		if !ok {
+			writeSandboxError(w, http.StatusNotFound, "404 Project Not Found")
+			return
+		}
+		dir, err := sb.repository(gitlabHost, project)
+		if err != nil {
+			writeSandboxError(w, http.StatusNotFound, "404 Project Not Found: %v", err)
+			return
+		}
+		key := gitlabHost + "/" + project
+		route := r.Method + " " + strings.Join(segments[2:], "/")
+
+		switch {
+		case route == "GET ":
+			writeSandboxJSON(w, http.StatusOK, map[string]any{"id": sb.projectID(project), "path_with_namespace": project, "default_branch": "main"})
+
+		case route == "POST repository/branches":
+			branch, ref := query.Get("branch"), query.Get("ref")
+			sha, exists := sb.resolve(dir, ref)
+			if !exists {
+				writeSandboxError(w, http.StatusBadRequest, "Invalid reference name: %s", ref)
+				return
+			}
+			if _, exists := sb.resolve(dir, "refs/heads/"+branch); exists {
+				writeSandboxError(w, http.StatusBadRequest, "Branch already exists")
+				return
+			}
+			if _, err := sb.git(dir, "update-ref", "refs/heads/"+branch, sha); err != nil {
+				writeSandboxError(w, http.StatusBadRequest, "Branch is invalid: %v", err)
+				return
+			}
+			writeSandboxJSON(w, http.StatusCreated, map[string]any{"name": branch, "commit": map[string]string{"id": sha}})
+
+		case r.Method == http.MethodDelete && len(segments) >= 5 && segments[2] == "repository" && segments[3] == "branches":
+			ref := "refs/heads/" + strings.Join(segments[4:], "/")
+			if _, exists := sb.resolve(dir, ref); !exists {
+				writeSandboxError(w, http.StatusNotFound, "404 Branch Not Found")
+				return
+			}
+			if _, err := sb.git(dir, "update-ref", "-d", ref); err != nil {
+				writeSandboxError(w, http.StatusInternalServerError, "%v", err)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+
+		case route == "POST merge_requests":
+			var request struct {
+				Title           string `json:"title"`
+				Description     string `json:"description"`
+				SourceBranch    string `json:"source_branch"`
+				TargetBranch    string `json:"target_branch"`
+				TargetProjectID int    `json:"target_project_id"`
+				Labels          string `json:"labels"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Title == "" {
+				writeSandboxError(w, http.StatusBadRequest, "title is missing")
+				return
+			}
+			if _, exists := sb.resolve(dir, "refs/heads/"+request.SourceBranch); !exists {
+				writeSandboxError(w, http.StatusBadRequest, "Source branch %s does not exist", request.SourceBranch)
+				return
+			}
+			// Merge requests from a fork are opened on the fork and belong to the target
+			target, targetDir := project, dir
+			if request.TargetProjectID != 0 {
+				if target, ok = sb.project(strconv.Itoa(request.TargetProjectID)); !ok {
+					writeSandboxError(w, http.StatusNotFound, "404 Target project Not Found")
+					return
+				}
+				if targetDir, err = sb.repository(gitlabHost, target); err != nil {
+					writeSandboxError(w, http.StatusNotFound, "404 Target project Not Found: %v", err)
+					return
+				}
+			}
+			if _, exists := sb.resolve(targetDir, "refs/heads/"+request.TargetBranch); !exists {
+				writeSandboxError(w, http.StatusBadRequest, "Target branch %s does not exist", request.TargetBranch)
+				return
+			}
+			cr := &sandboxChangeRequest{Title: request.Title, Body: request.Description, SourceRepo: project, SourceBranch: request.SourceBranch, TargetBranch: request.TargetBranch}
+			if request.Labels != "" {
+				cr.Labels = strings.Split(request.Labels, ",")
+			}
+			sb.openChangeRequest(gitlabHost+"/"+target, cr)
+			writeSandboxJSON(w, http.StatusCreated, sb.gitlabMergeRequest(target, cr))
+
+		case route == "GET merge_requests":
+			state := query.Get("state")
+			switch state {
+			case "":
+				state = "all"
+			case "opened":
+				state = "open"
+			}
+			mrs := []map[string]any{}
+			for _, cr := range sb.listChangeRequests(key, state, query.Get("search")) {
+				mrs = append(mrs, sb.gitlabMergeRequest(project, cr))
+			}
+			writeSandboxJSON(w, http.StatusOK, sandboxPage(mrs, query))
+
+		case len(segments) >= 4 && segments[2] == "merge_requests":
+			number, _ := strconv.Atoi(segments[3])
+			cr := sb.changeRequest(key, number)
+			if cr == nil {
+				writeSandboxError(w, http.StatusNotFound, "404 Not found")
+				return
+			}
+			var request struct {
+				Body       string `json:"body"`
+				StateEvent string `json:"state_event"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&request)
+			switch {
+			case r.Method == http.MethodPost && len(segments) == 5 && segments[4] == "notes":
+				sb.forgeMu.Lock()
+				cr.Comments = append(cr.Comments, request.Body)
+				sb.forgeMu.Unlock()
+				writeSandboxJSON(w, http.StatusCreated, map[string]string{"body": request.Body})
+			case r.Method == http.MethodPut && len(segments) == 4:
+				sb.forgeMu.Lock()
+				if request.StateEvent == "close" {
+					cr.State = "closed"
+				}
+				sb.forgeMu.Unlock()
+				writeSandboxJSON(w, http.StatusOK, sb.gitlabMergeRequest(project, cr))
+			case r.Method == http.MethodGet && len(segments) == 4:
+				writeSandboxJSON(w, http.StatusOK, sb.gitlabMergeRequest(project, cr))
+			default:
+				writeSandboxError(w, http.StatusNotFound, "404 Not Found")
+			}
+
+		case route == "GET pipelines":
+			// Every pipeline passes in the sandbox
+			ref := query.Get("ref")
+			sha, exists := sb.resolve(dir, ref)
+			if !exists {
+				writeSandboxJSON(w, http.StatusOK, []any{})
+				return
+			}
+			writeSandboxJSON(w, http.StatusOK, []map[string]any{{"id": 1, "status": "success", "ref": ref, "sha": sha}})
+
+		case route == "POST protected_branches":
+			sb.forgeMu.Lock()
+			sb.protected[key+":"+query.Get("name")] = true
+			sb.forgeMu.Unlock()
+			writeSandboxJSON(w, http.StatusCreated, map[string]string{"name": query.Get("name")})
+
+		default:
+			writeSandboxError(w, http.StatusNotFound, "404 Not Found")
+		}
+	})
+}
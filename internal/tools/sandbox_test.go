@@ -0,0 +1,114 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	mcp "github.com/modelcontextprotocol/go-sdk/mcp"
+	filteredinformerfactory "knative.dev/pkg/client/injection/kube/informers/factory/filtered"
+	"knative.dev/pkg/injection"
+)
+
+// TestSandboxReleaseWorkflow runs the first steps of a minor release against
+// the sandbox fakes: cutting the release branches and generating the release
+// plans, each confirmed through its plan hash as a client would
+func TestSandboxReleaseWorkflow(t *testing.T) {
+	t.Setenv(stateDirEnv, t.TempDir())
+	cfg, err := StartSandbox(SandboxOptions{Dir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("StartSandbox() = %v", err)
+	}
+	t.Cleanup(StopSandbox)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	ctx = filteredinformerfactory.WithSelectors(ctx, ManagedBySelector())
+	ctx, startInformers := injection.EnableInjectionOrDie(ctx, cfg)
+	startInformers()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "release-mcp-test"}, nil)
+	if err := Add(ctx, server); err != nil {
+		t.Fatalf("Add() = %v", err)
+	}
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(ctx, serverTransport); err != nil {
+		t.Fatalf("Connect() = %v", err)
+	}
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "release-mcp-test-client"}, nil).Connect(ctx, clientTransport)
+	if err != nil {
+		t.Fatalf("Connect() = %v", err)
+	}
+	t.Cleanup(func() { session.Close() })
+
+	const minorVersion = "1.21"
+	args := map[string]any{"minor_version": minorVersion, windowOverrideArg: true}
+	callConfirmedTool(ctx, t, session, "create-release-branches", args)
+
+	branch := "release-v" + minorVersion + ".x"
+	for _, repo := range releaseRepositories() {
+		if repo.Skip {
+			continue
+		}
+		dir, err := activeSandbox.repository(githubHost, forgeRepoPath(repo.RepoURL))
+		if err != nil {
+			t.Fatalf("repository(%s) = %v", repo.Name, err)
+		}
+		if _, ok := activeSandbox.resolve(dir, "refs/heads/"+branch); !ok {
+			t.Errorf("%s has no %s branch", repo.Name, branch)
+		}
+	}
+
+	result := callConfirmedTool(ctx, t, session, "create-release-plans", args)
+	var pushed PushedBranchResult
+	if err := json.Unmarshal(structuredContent(t, result), &pushed); err != nil {
+		t.Fatalf("failed to decode create-release-plans result: %v", err)
+	}
+	if pushed.BranchName == "" || pushed.MergeRequestURL == "" {
+		t.Errorf("create-release-plans = %+v, want a pushed branch and a merge request", pushed)
+	}
+}
+
+// callConfirmedTool calls a tool, repeating the call with the plan hash when
+// it is held back for confirmation, and fails the test when the tool fails
+func callConfirmedTool(ctx context.Context, t *testing.T, session *mcp.ClientSession, name string, args map[string]any) *mcp.CallToolResult {
+	t.Helper()
+	arguments := make(map[string]any, len(args)+1)
+	for key, value := range args {
+		arguments[key] = value
+	}
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: name, Arguments: arguments})
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	if hash, ok := result.Meta[PlanHashMeta].(string); ok {
+		arguments[confirmArg] = hash
+		if result, err = session.CallTool(ctx, &mcp.CallToolParams{Name: name, Arguments: arguments}); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+	}
+	if result.IsError {
+		t.Fatalf("%s failed: %s", name, resultText(result))
+	}
+	return result
+}
+
+func resultText(result *mcp.CallToolResult) string {
+	var sb strings.Builder
+	for _, content := range result.Content {
+		if text, ok := content.(*mcp.TextContent); ok {
+			sb.WriteString(text.Text)
+		}
+	}
+	return sb.String()
+}
+
+func structuredContent(t *testing.T, result *mcp.CallToolResult) []byte {
+	t.Helper()
+	content, err := json.Marshal(result.StructuredContent)
+	if err != nil {
+		t.Fatalf("failed to encode structured content: %v", err)
+	}
+	return content
+}
@@ -0,0 +1,591 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	mcp "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	scheduledTasksFile = "scheduled-tasks.json"
+
+	// schedulerLeaseFile records the server process running the scheduled
+	// tasks, so servers sharing a state directory do not run them twice
+	schedulerLeaseFile = "scheduler.lease"
+
+	// schedulerLeaseDuration is how long a lease lasts without being renewed
+	schedulerLeaseDuration = 3 * schedulerPollInterval
+
+	// schedulerPollInterval is how often the worker looks for due tasks and
+	// renews the lease
+	schedulerPollInterval = time.Minute
+
+	// schedulerLeaseSettle is how long a server taking over an expired lease
+	// waits for the servers racing with it before checking which one holds it
+	schedulerLeaseSettle = time.Second
+
+	// schedulerRunTimeout bounds a run of a task, so a task left running by a
+	// server that stopped is known to be abandoned once it has passed
+	schedulerRunTimeout = 2 * time.Hour
+
+	// minTaskInterval keeps recurring tasks from hammering the forges
+	minTaskInterval = 5 * time.Minute
+
+	// untilGA ends a recurring task on the GA date of its version
+	untilGA = "ga"
+)
+
+// schedulerTools cannot be scheduled themselves
+var schedulerTools = map[string]bool{
+	"schedule-release-task": true,
+	"list-scheduled-tasks":  true,
+	"cancel-scheduled-task": true,
+}
+
+// ScheduledTask represents a tool call to run later, once or repeatedly
+type ScheduledTask struct {
+	ID          string         `json:"id"`
+	Tool        string         `json:"tool"`
+	Arguments   map[string]any `json:"arguments,omitempty"`
+	Version     string         `json:"version"`
+	RunAt       time.Time      `json:"run_at"`
+	Interval    string         `json:"interval,omitempty" jsonschema:"Time between runs of a recurring task"`
+	Until       time.Time      `json:"until,omitzero" jsonschema:"No run of a recurring task starts after this time"`
+	Created     time.Time      `json:"created"`
+	Runs        int            `json:"runs"`
+	LastRun     time.Time      `json:"last_run,omitzero"`
+	LastSuccess bool           `json:"last_success,omitempty"`
+	LastResult  string         `json:"last_result,omitempty"`
+	Running     string         `json:"running,omitempty" jsonschema:"Lease holder running the task"`
+	Started     time.Time      `json:"started,omitzero"`
+}
+
+// ScheduledTasksResult represents the structured result of the scheduler tools
+type ScheduledTasksResult struct {
+	Tasks []ScheduledTask `json:"tasks"`
+}
+
+// scheduler runs the scheduled tasks persisted in the state directory
+type scheduler struct {
+	mu     sync.Mutex // Serializes reads and writes of the tasks file
+	server *mcp.Server
+	holder string // Identifies this process in the lease
+	leader atomic.Bool
+}
+
+// schedulerLease represents the lease of the process running the scheduled tasks
+type schedulerLease struct {
+	Holder  string    `json:"holder"`
+	Expires time.Time `json:"expires"`
+}
+
+var taskScheduler = &scheduler{}
+
+func scheduledTasksPath() string {
+	return filepath.Join(stateDir(), scheduledTasksFile)
+}
+
+// load returns the persisted tasks, ordered by their next run. Callers hold mu.
+func (s *scheduler) load() ([]ScheduledTask, error) {
+	content, err := os.ReadFile(scheduledTasksPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read scheduled tasks: %w", err)
+	}
+
+	var tasks []ScheduledTask
+	if err := json.Unmarshal(content, &tasks); err != nil {
+		return nil, fmt.Errorf("failed to parse scheduled tasks: %w", err)
+	}
+	sort.SliceStable(tasks, func(i, j int) bool { return tasks[i].RunAt.Before(tasks[j].RunAt) })
+	return tasks, nil
+}
+
+// save persists the tasks, replacing the file atomically. Callers hold mu.
+func (s *scheduler) save(tasks []ScheduledTask) error {
+	if err := os.MkdirAll(stateDir(), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	content, err := json.MarshalIndent(tasks, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode scheduled tasks: %w", err)
+	}
+	tmp := scheduledTasksPath() + ".tmp"
+	if err := os.WriteFile(tmp, content, 0644); err != nil {
+		return fmt.Errorf("failed to write scheduled tasks: %w", err)
+	}
+	return os.Rename(tmp, scheduledTasksPath())
+}
+
+// schedule validates and persists a new task
+func (s *scheduler) schedule(ctx context.Context, task ScheduledTask) (ScheduledTask, error) {
+	if schedulerTools[task.Tool] {
+		return task, fmt.Errorf("%s cannot be scheduled", task.Tool)
+	}
+	// Scheduled runs start in a fresh session, which never holds a plan to confirm
+	if _, needed := needsConfirmation(task.Tool, task.Arguments); needed {
+		return task, fmt.Errorf("%s needs confirmation with these arguments, which a scheduled run cannot give: call it directly", task.Tool)
+	}
+	if task.Interval != "" {
+		interval, err := time.ParseDuration(task.Interval)
+		if err != nil {
+			return task, fmt.Errorf("invalid interval %q: %w", task.Interval, err)
+		}
+		if interval < minTaskInterval {
+			return task, fmt.Errorf("interval must be at least %s", minTaskInterval)
+		}
+	}
+	if err := s.toolExists(ctx, task.Tool); err != nil {
+		return task, err
+	}
+
+	id := make([]byte, 4)
+	if _, err := rand.Read(id); err != nil {
+		return task, fmt.Errorf("failed to generate task ID: %w", err)
+	}
+	task.ID = hex.EncodeToString(id)
+	task.Version = auditVersion(task.Arguments)
+	task.Created = time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tasks, err := s.load()
+	if err != nil {
+		return task, err
+	}
+	return task, s.save(append(tasks, task))
+}
+
+// cancel removes a task and returns it
+func (s *scheduler) cancel(id string) (ScheduledTask, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tasks, err := s.load()
+	if err != nil {
+		return ScheduledTask{}, err
+	}
+	for i, task := range tasks {
+		if task.ID == id {
+			return task, s.save(append(tasks[:i], tasks[i+1:]...))
+		}
+	}
+	return ScheduledTask{}, fmt.Errorf("no scheduled task %s", id)
+}
+
+func (s *scheduler) list() ([]ScheduledTask, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load()
+}
+
+// StartScheduler runs the due scheduled tasks in the background until ctx is
+// done. Only the long-running server starts it, not run-tool, and only the
+// server holding the lease of the state directory runs the tasks.
+func StartScheduler(ctx context.Context) error {
+	return taskScheduler.start(ctx)
+}
+
+// start runs due tasks in the background until ctx is done
+func (s *scheduler) start(ctx context.Context) error {
+	hostname, _ := os.Hostname()
+	id := make([]byte, 4)
+	if _, err := rand.Read(id); err != nil {
+		return fmt.Errorf("failed to generate scheduler lease holder: %w", err)
+	}
+	s.holder = fmt.Sprintf("%s/%d/%s", hostname, os.Getpid(), hex.EncodeToString(id))
+
+	// The lease is renewed on its own, as runs of tasks such as branch cuts
+	// last longer than the lease
+	s.leader.Store(s.acquireLease(ctx, time.Now()))
+	go func() {
+		ticker := time.NewTicker(schedulerPollInterval)
+		defer ticker.Stop()
+		defer s.releaseLease()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.leader.Store(s.acquireLease(ctx, time.Now()))
+			}
+		}
+	}()
+
+	go func() {
+		ticker := time.NewTicker(schedulerPollInterval)
+		defer ticker.Stop()
+		for {
+			if s.leader.Load() {
+				s.runDue(ctx, time.Now())
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+func schedulerLeasePath() string {
+	return filepath.Join(stateDir(), schedulerLeaseFile)
+}
+
+// acquireLease takes or renews the lease of the state directory, and returns
+// whether this process holds it. A lease not renewed before it expires, by a
+// server that stopped or crashed, is taken over.
+func (s *scheduler) acquireLease(ctx context.Context, now time.Time) bool {
+	path := schedulerLeasePath()
+	lease := schedulerLease{Holder: s.holder, Expires: now.Add(schedulerLeaseDuration)}
+	content, err := json.Marshal(lease)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to encode scheduler lease", "error", err)
+		return false
+	}
+
+	current, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		var held schedulerLease
+		if err := json.Unmarshal(current, &held); err != nil {
+			slog.ErrorContext(ctx, "Failed to parse scheduler lease", "path", path, "error", err)
+			return false
+		}
+		if held.Holder == s.holder {
+			return s.replaceLease(ctx, path, current, content)
+		}
+		if held.Expires.After(now) {
+			return false
+		}
+		slog.InfoContext(ctx, "Taking over expired scheduler lease", "holder", held.Holder, "expired", held.Expires)
+		if !s.replaceLease(ctx, path, current, content) {
+			return false
+		}
+		// Servers taking over the same lease all replace it, the last one holds it
+		if err := sleepContext(ctx, schedulerLeaseSettle); err != nil {
+			return false
+		}
+		return s.holdsLease(path)
+	case !os.IsNotExist(err):
+		slog.ErrorContext(ctx, "Failed to read scheduler lease", "error", err)
+		return false
+	}
+
+	// Only one of the processes racing for a free lease creates the file
+	if err := os.MkdirAll(stateDir(), 0755); err != nil {
+		slog.ErrorContext(ctx, "Failed to create state directory", "error", err)
+		return false
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+	if _, err := file.Write(content); err != nil {
+		slog.ErrorContext(ctx, "Failed to write scheduler lease", "error", err)
+		return false
+	}
+	return true
+}
+
+// replaceLease replaces the lease read as current with content, through a
+// file of its own renamed over the lease, unless the lease changed meanwhile.
+// It returns whether the lease was replaced and names this process.
+func (s *scheduler) replaceLease(ctx context.Context, path string, current, content []byte) bool {
+	tmp, err := os.CreateTemp(filepath.Dir(path), schedulerLeaseFile+".*.tmp")
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to write scheduler lease", "error", err)
+		return false
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to write scheduler lease", "error", err)
+		return false
+	}
+
+	if latest, err := os.ReadFile(path); err != nil || !bytes.Equal(latest, current) {
+		return false
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		slog.ErrorContext(ctx, "Failed to replace scheduler lease", "error", err)
+		return false
+	}
+	return s.holdsLease(path)
+}
+
+// holdsLease reports whether the lease at path names this process
+func (s *scheduler) holdsLease(path string) bool {
+	current, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	var held schedulerLease
+	return json.Unmarshal(current, &held) == nil && held.Holder == s.holder
+}
+
+// releaseLease removes the lease when this process holds it, so another
+// server takes over without waiting for it to expire
+func (s *scheduler) releaseLease() {
+	if s.holdsLease(schedulerLeasePath()) {
+		os.Remove(schedulerLeasePath())
+	}
+}
+
+// runDue runs the tasks due at now one after the other. Tasks missed while
+// the server was down run on the first poll after it restarts.
+func (s *scheduler) runDue(ctx context.Context, now time.Time) {
+	// Due tasks stay due and run once maintenance ends
+	if currentMaintenance(now).Active {
+		return
+	}
+
+	s.mu.Lock()
+	tasks, err := s.load()
+	s.mu.Unlock()
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to load scheduled tasks", "error", err)
+		return
+	}
+
+	for _, task := range tasks {
+		if task.RunAt.After(now) {
+			break
+		}
+		// Another server took over the lease while the previous task ran
+		if !s.leader.Load() {
+			return
+		}
+		claimed, err := s.claim(task.ID, time.Now())
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to claim scheduled task", "task", task.ID, "error", err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		runCtx, cancel := context.WithTimeout(ctx, schedulerRunTimeout)
+		success, summary := s.run(runCtx, task)
+		cancel()
+		if err := s.finish(task.ID, time.Now(), success, summary); err != nil {
+			slog.ErrorContext(ctx, "Failed to record run of scheduled task", "task", task.ID, "error", err)
+		}
+	}
+}
+
+// claim marks a due task as run by this process before it runs, so a server
+// taking over the lease meanwhile does not start it again. It returns false
+// when the task was cancelled, is no longer due or is running elsewhere; a
+// task left running for longer than a run may last was abandoned by a server
+// that stopped, and is claimed again.
+func (s *scheduler) claim(id string, now time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tasks, err := s.load()
+	if err != nil {
+		return false, err
+	}
+
+	for i := range tasks {
+		task := &tasks[i]
+		if task.ID != id {
+			continue
+		}
+		if task.RunAt.After(now) || (task.Running != "" && now.Sub(task.Started) < schedulerRunTimeout) {
+			return false, nil
+		}
+		task.Running = s.holder
+		task.Started = now
+		return true, s.save(tasks)
+	}
+	return false, nil
+}
+
+// run calls the tool of a task through the server, so the call goes through
+// the same middleware as client calls and is recorded in the release record
+func (s *scheduler) run(ctx context.Context, task ScheduledTask) (bool, string) {
+	session, err := s.connect(ctx)
+	if err != nil {
+		return false, err.Error()
+	}
+	// Closing the session removes the working directories of the run
+	defer session.Close()
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: task.Tool, Arguments: task.Arguments})
+	if err != nil {
+		return false, err.Error()
+	}
+	summary := ""
+	for _, content := range result.Content {
+		if text, ok := content.(*mcp.TextContent); ok {
+			summary = strings.TrimSpace(strings.SplitN(text.Text, "\n", 2)[0])
+			break
+		}
+	}
+	return !result.IsError, summary
+}
+
+// finish records the run of a task and schedules its next run, removing it
+// once it has no run left
+func (s *scheduler) finish(id string, now time.Time, success bool, summary string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tasks, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	for i := range tasks {
+		task := &tasks[i]
+		if task.ID != id {
+			continue
+		}
+		task.Runs++
+		task.LastRun = now
+		task.LastSuccess = success
+		task.LastResult = summary
+		task.Running = ""
+		task.Started = time.Time{}
+
+		interval, _ := time.ParseDuration(task.Interval)
+		next := task.RunAt.Add(interval)
+		for interval > 0 && !next.After(now) {
+			next = next.Add(interval)
+		}
+		if interval == 0 || (!task.Until.IsZero() && next.After(task.Until)) {
+			tasks = append(tasks[:i], tasks[i+1:]...)
+		} else {
+			task.RunAt = next
+		}
+		return s.save(tasks)
+	}
+	// The task was cancelled while it ran
+	return nil
+}
+
+// connect opens an in-process client session on the server
+func (s *scheduler) connect(ctx context.Context) (*mcp.ClientSession, error) {
+	if s.server == nil {
+		return nil, fmt.Errorf("scheduler is not running")
+	}
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+	if _, err := s.server.Connect(ctx, serverTransport); err != nil {
+		return nil, fmt.Errorf("failed to connect to the server: %w", err)
+	}
+	client := mcp.NewClient(&mcp.Implementation{Name: "release-mcp-scheduler"}, nil)
+	session, err := client.Connect(ctx, clientTransport)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the server: %w", err)
+	}
+	return session, nil
+}
+
+// toolExists returns an error unless the server has a tool named name
+func (s *scheduler) toolExists(ctx context.Context, name string) error {
+	session, err := s.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	for tool, err := range session.Tools(ctx, nil) {
+		if err != nil {
+			return fmt.Errorf("failed to list tools: %w", err)
+		}
+		if tool.Name == name {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown tool %s", name)
+}
+
+// taskSchedule resolves when a task first runs and when a recurring task ends.
+// until is a YYYY-MM-DD date, inclusive, or "ga" for the GA date of the version.
+func taskSchedule(now time.Time, delay, at, until, version string) (time.Time, time.Time, error) {
+	var runAt, end time.Time
+	switch {
+	case at != "":
+		t, err := time.Parse(time.RFC3339, at)
+		if err != nil {
+			return runAt, end, fmt.Errorf("invalid at %q, expected RFC 3339: %w", at, err)
+		}
+		runAt = t
+	case delay != "":
+		d, err := time.ParseDuration(delay)
+		if err != nil {
+			return runAt, end, fmt.Errorf("invalid delay %q: %w", delay, err)
+		}
+		runAt = now.Add(d)
+	default:
+		runAt = now
+	}
+
+	if until == untilGA {
+		calendar, err := loadReleaseCalendar()
+		if err != nil {
+			return runAt, end, err
+		}
+		var dates ReleaseDates
+		if calendar != nil {
+			dates = calendar.Releases[version]
+		}
+		if dates.GA == "" {
+			return runAt, end, fmt.Errorf("no GA date for %s in the release calendar", version)
+		}
+		until = dates.GA
+	}
+	if until != "" {
+		t, err := time.Parse("2006-01-02", until)
+		if err != nil {
+			return runAt, end, fmt.Errorf("invalid until %q: %w", until, err)
+		}
+		end = t.AddDate(0, 0, 1)
+	}
+	return runAt, end, nil
+}
+
+func formatScheduledTasks(tasks []ScheduledTask) string {
+	if len(tasks) == 0 {
+		return "No scheduled tasks"
+	}
+	var sb strings.Builder
+	for _, task := range tasks {
+		fmt.Fprintf(&sb, "- %s: %s (%s) next run %s", task.ID, task.Tool, task.Version, task.RunAt.UTC().Format("2006-01-02 15:04 UTC"))
+		if task.Interval != "" {
+			fmt.Fprintf(&sb, ", every %s", task.Interval)
+			if !task.Until.IsZero() {
+				fmt.Fprintf(&sb, " until %s", task.Until.AddDate(0, 0, -1).Format("2006-01-02"))
+			}
+		}
+		if task.Running != "" {
+			fmt.Fprintf(&sb, "; running since %s", task.Started.UTC().Format("2006-01-02 15:04 UTC"))
+		}
+		if task.Runs > 0 {
+			status := "✅"
+			if !task.LastSuccess {
+				status = "❌"
+			}
+			fmt.Fprintf(&sb, "; run %d times, last %s %s", task.Runs, status, task.LastResult)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,220 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTaskSchedule(t *testing.T) {
+	now := time.Date(2026, 5, 4, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		delay     string
+		at        string
+		until     string
+		wantRunAt time.Time
+		wantEnd   time.Time
+		wantErr   bool
+	}{
+		{name: "now", wantRunAt: now},
+		{name: "delay", delay: "90m", wantRunAt: now.Add(90 * time.Minute)},
+		{name: "at", at: "2026-05-10T08:30:00Z", wantRunAt: time.Date(2026, 5, 10, 8, 30, 0, 0, time.UTC)},
+		{name: "until is inclusive", until: "2026-06-01", wantRunAt: now, wantEnd: time.Date(2026, 6, 2, 0, 0, 0, 0, time.UTC)},
+		{name: "invalid delay", delay: "soon", wantErr: true},
+		{name: "invalid at", at: "2026-05-10", wantErr: true},
+		{name: "invalid until", until: "June", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runAt, end, err := taskSchedule(now, tt.delay, tt.at, tt.until, "1.21")
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("taskSchedule() = %s, %s, want an error", runAt, end)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("taskSchedule() = %v", err)
+			}
+			if !runAt.Equal(tt.wantRunAt) || !end.Equal(tt.wantEnd) {
+				t.Errorf("taskSchedule() = %s, %s, want %s, %s", runAt, end, tt.wantRunAt, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestSchedulerFinish(t *testing.T) {
+	now := time.Date(2026, 5, 4, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		task      ScheduledTask
+		wantRunAt time.Time // Zero when the task is removed
+	}{
+		{
+			name: "one-off task is removed",
+			task: ScheduledTask{RunAt: now.Add(-time.Minute)},
+		},
+		{
+			name:      "recurring task moves to its next run",
+			task:      ScheduledTask{RunAt: now.Add(-time.Minute), Interval: "1h"},
+			wantRunAt: now.Add(59 * time.Minute),
+		},
+		{
+			name:      "missed runs are skipped",
+			task:      ScheduledTask{RunAt: now.Add(-150 * time.Minute), Interval: "1h"},
+			wantRunAt: now.Add(30 * time.Minute),
+		},
+		{
+			name: "recurring task ends after until",
+			task: ScheduledTask{RunAt: now.Add(-time.Minute), Interval: "1h", Until: now.Add(30 * time.Minute)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(stateDirEnv, t.TempDir())
+			s := &scheduler{holder: "test"}
+			tt.task.ID = "task"
+			if err := s.save([]ScheduledTask{tt.task}); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := s.finish("task", now, true, "done"); err != nil {
+				t.Fatalf("finish() = %v", err)
+			}
+			tasks, err := s.list()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantRunAt.IsZero() {
+				if len(tasks) != 0 {
+					t.Errorf("finish() kept %+v, want it removed", tasks)
+				}
+				return
+			}
+			if len(tasks) != 1 {
+				t.Fatalf("finish() left %d tasks, want 1", len(tasks))
+			}
+			got := tasks[0]
+			if !got.RunAt.Equal(tt.wantRunAt) || got.Runs != 1 || !got.LastSuccess || got.LastResult != "done" || got.Running != "" {
+				t.Errorf("finish() = %+v, want the next run at %s and the run recorded", got, tt.wantRunAt)
+			}
+		})
+	}
+}
+
+func TestSchedulerClaim(t *testing.T) {
+	now := time.Date(2026, 5, 4, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		task ScheduledTask
+		want bool
+	}{
+		{name: "due task", task: ScheduledTask{RunAt: now}, want: true},
+		{name: "task not due yet", task: ScheduledTask{RunAt: now.Add(time.Minute)}},
+		{name: "task running elsewhere", task: ScheduledTask{RunAt: now, Running: "other", Started: now.Add(-time.Hour)}},
+		{name: "abandoned task", task: ScheduledTask{RunAt: now, Running: "other", Started: now.Add(-schedulerRunTimeout)}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(stateDirEnv, t.TempDir())
+			s := &scheduler{holder: "test"}
+			tt.task.ID = "task"
+			if err := s.save([]ScheduledTask{tt.task}); err != nil {
+				t.Fatal(err)
+			}
+
+			claimed, err := s.claim("task", now)
+			if err != nil {
+				t.Fatalf("claim() = %v", err)
+			}
+			if claimed != tt.want {
+				t.Errorf("claim() = %t, want %t", claimed, tt.want)
+			}
+			if !claimed {
+				return
+			}
+			// A second server taking over the lease must not start it again
+			if again, err := (&scheduler{holder: "takeover"}).claim("task", now.Add(time.Minute)); err != nil || again {
+				t.Errorf("claim() by another holder = %t, %v, want false", again, err)
+			}
+		})
+	}
+
+	t.Run("cancelled task", func(t *testing.T) {
+		t.Setenv(stateDirEnv, t.TempDir())
+		if claimed, err := (&scheduler{holder: "test"}).claim("missing", now); err != nil || claimed {
+			t.Errorf("claim() = %t, %v, want false", claimed, err)
+		}
+	})
+}
+
+func TestSchedulerAcquireLease(t *testing.T) {
+	now := time.Date(2026, 5, 4, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		held *schedulerLease // Lease found in the state directory, if any
+		want bool
+	}{
+		{name: "free lease", want: true},
+		{name: "renewed lease", held: &schedulerLease{Holder: "test", Expires: now.Add(time.Minute)}, want: true},
+		{name: "lease held elsewhere", held: &schedulerLease{Holder: "other", Expires: now.Add(time.Minute)}},
+		{name: "expired lease", held: &schedulerLease{Holder: "other", Expires: now.Add(-time.Minute)}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(stateDirEnv, t.TempDir())
+			if tt.held != nil {
+				writeSchedulerLease(t, *tt.held)
+			}
+
+			s := &scheduler{holder: "test"}
+			if got := s.acquireLease(context.Background(), now); got != tt.want {
+				t.Errorf("acquireLease() = %t, want %t", got, tt.want)
+			}
+			if got := s.holdsLease(schedulerLeasePath()); got != tt.want {
+				t.Errorf("holdsLease() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("concurrent takeovers", func(t *testing.T) {
+		t.Setenv(stateDirEnv, t.TempDir())
+		writeSchedulerLease(t, schedulerLease{Holder: "crashed", Expires: now.Add(-time.Minute)})
+
+		var wg sync.WaitGroup
+		var holders atomic.Int32
+		for i := range 8 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if (&scheduler{holder: fmt.Sprintf("server-%d", i)}).acquireLease(context.Background(), now) {
+					holders.Add(1)
+				}
+			}()
+		}
+		wg.Wait()
+		if got := holders.Load(); got != 1 {
+			t.Errorf("%d servers took over the lease, want 1", got)
+		}
+	})
+}
+
+func writeSchedulerLease(t *testing.T, lease schedulerLease) {
+	t.Helper()
+	content, err := json.Marshal(lease)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(stateDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(schedulerLeasePath(), content, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,175 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
+	mcp "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// secretsOverrideArg is the argument allowing a push with potential secrets
+const secretsOverrideArg = "allow_potential_secrets"
+
+// secretPatterns are the kinds of content refused in pushed changes
+var secretPatterns = []struct {
+	kind    string
+	pattern *regexp.Regexp
+}{
+	{"GitHub token", regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})`)},
+	{"GitLab token", regexp.MustCompile(`\bglpat-[A-Za-z0-9_-]{20,}`)},
+	{"AWS access key", regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"Slack token", regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+	{"private key", regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`)},
+	{"kubeconfig credentials", regexp.MustCompile(`\b(client-key-data|client-certificate-data):\s*\S{16,}`)},
+	{"JSON web token", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`)},
+}
+
+// SecretFinding represents a potential secret in the changes about to be pushed
+type SecretFinding struct {
+	Commit string `json:"commit"`
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Kind   string `json:"kind"`
+	Match  string `json:"match" jsonschema:"The matched text, redacted"`
+}
+
+type secretsOverrideKey struct{}
+
+// scanBeforePush adds the secrets override argument to the input schema of a
+// tool that pushes changes and marks it as mutating. Pushes of every tool are
+// scanned regardless.
+func scanBeforePush(tool *mcp.Tool) *mcp.Tool {
+	markMutating(tool.Name)
+	if tool.InputSchema.Properties == nil {
+		tool.InputSchema.Properties = map[string]*jsonschema.Schema{}
+	}
+	tool.InputSchema.Properties[secretsOverrideArg] = &jsonschema.Schema{
+		Type:        "boolean",
+		Description: "Push even if the changes contain strings that look like secrets",
+	}
+	return tool
+}
+
+// secretsOverrideMiddleware lets the tool call push potential secrets when
+// the override argument is set
+func secretsOverrideMiddleware(next mcp.MethodHandler[*mcp.ServerSession]) mcp.MethodHandler[*mcp.ServerSession] {
+	return func(ctx context.Context, session *mcp.ServerSession, method string, params mcp.Params) (mcp.Result, error) {
+		callParams, ok := params.(*mcp.CallToolParamsFor[json.RawMessage])
+		if method != "tools/call" || !ok {
+			return next(ctx, session, method, params)
+		}
+
+		var args map[string]any
+		_ = json.Unmarshal(callParams.Arguments, &args)
+		if override, _ := args[secretsOverrideArg].(bool); override {
+			ctx = context.WithValue(ctx, secretsOverrideKey{}, true)
+		}
+		return next(ctx, session, method, params)
+	}
+}
+
+// checkPushForSecrets refuses a git push whose commits, not yet on any
+// remote, add content that looks like a secret
+func checkPushForSecrets(ctx context.Context, cmd *exec.Cmd) error {
+	if len(cmd.Args) < 2 || filepath.Base(cmd.Args[0]) != "git" || cmd.Args[1] != "push" {
+		return nil
+	}
+	if override, _ := ctx.Value(secretsOverrideKey{}).(bool); override {
+		return nil
+	}
+
+	refs := pushedRefs(cmd.Args[2:])
+	if len(refs) == 0 {
+		refs = []string{"HEAD"}
+	}
+	args := append([]string{"log", "-p", "--no-color", "--no-ext-diff", "--format=commit %H"}, refs...)
+	logCmd := exec.CommandContext(ctx, "git", append(args, "--not", "--remotes")...)
+	logCmd.Dir = cmd.Dir
+	output, err := commandOutput(ctx, logCmd)
+	if err != nil {
+		return fmt.Errorf("failed to read the changes to push for secrets scanning: %w", err)
+	}
+
+	findings := scanPatchForSecrets(string(output))
+	if len(findings) == 0 {
+		return nil
+	}
+	return fmt.Errorf("refusing to push, the changes contain potential secrets:\n%sSet %s to true to push anyway", formatSecretFindings(findings), secretsOverrideArg)
+}
+
+// pushedRefs returns the local refs of the refspecs of git push arguments
+func pushedRefs(args []string) []string {
+	var refs []string
+	remoteSeen := false
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			continue
+		}
+		if !remoteSeen {
+			remoteSeen = true
+			continue
+		}
+		src, _, _ := strings.Cut(strings.TrimPrefix(arg, "+"), ":")
+		if src != "" {
+			refs = append(refs, src)
+		}
+	}
+	return refs
+}
+
+// scanPatchForSecrets returns the potential secrets on the added lines of a git log patch
+func scanPatchForSecrets(patch string) []SecretFinding {
+	var findings []SecretFinding
+	var commit, file string
+	line := 0
+	for _, text := range strings.Split(patch, "\n") {
+		switch {
+		case strings.HasPrefix(text, "commit "):
+			commit = strings.TrimPrefix(text, "commit ")
+			if len(commit) > 12 {
+				commit = commit[:12]
+			}
+		case strings.HasPrefix(text, "+++ "):
+			file = strings.TrimPrefix(strings.TrimPrefix(text, "+++ "), "b/")
+		case strings.HasPrefix(text, "@@ "):
+			// @@ -a,b +c,d @@: added lines start at c
+			if fields := strings.Fields(text); len(fields) > 2 {
+				start, _, _ := strings.Cut(strings.TrimPrefix(fields[2], "+"), ",")
+				line, _ = strconv.Atoi(start)
+			}
+		case strings.HasPrefix(text, "+"):
+			for _, secret := range secretPatterns {
+				if match := secret.pattern.FindString(text); match != "" {
+					findings = append(findings, SecretFinding{Commit: commit, File: file, Line: line, Kind: secret.kind, Match: redactSecret(match)})
+				}
+			}
+			line++
+		case strings.HasPrefix(text, " "):
+			line++
+		}
+	}
+	return findings
+}
+
+// redactSecret keeps enough of a match to locate it without disclosing it
+func redactSecret(match string) string {
+	if len(match) <= 12 {
+		return strings.Repeat("*", len(match))
+	}
+	return match[:8] + strings.Repeat("*", 8)
+}
+
+func formatSecretFindings(findings []SecretFinding) string {
+	var sb strings.Builder
+	for _, finding := range findings {
+		fmt.Fprintf(&sb, "- %s:%d (commit %s): %s %s\n", finding.File, finding.Line, finding.Commit, finding.Kind, finding.Match)
+	}
+	return sb.String()
+}
@@ -0,0 +1,146 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+
+	mcp "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// defaultableArgs are the arguments a session remembers from its calls and
+// fills into later calls that omit them
+var defaultableArgs = []string{"minor_version"}
+
+// SessionDefaultsResult represents the structured result of session-defaults
+type SessionDefaultsResult struct {
+	Defaults map[string]string `json:"defaults" jsonschema:"Arguments filled into calls that omit them"`
+	Cleared  bool              `json:"cleared"`
+}
+
+// remember records the defaultable arguments of a call
+func (m *sessionManager) remember(session *mcp.ServerSession, args map[string]any) error {
+	state, err := m.state(session)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, name := range defaultableArgs {
+		if value, _ := args[name].(string); value != "" {
+			if state.defaults == nil {
+				state.defaults = map[string]string{}
+			}
+			state.defaults[name] = value
+		}
+	}
+	return nil
+}
+
+// defaults returns a copy of the remembered arguments of a session
+func (m *sessionManager) defaults(session *mcp.ServerSession) map[string]string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	state, ok := m.sessions[session]
+	if !ok {
+		return map[string]string{}
+	}
+	return maps.Clone(state.defaults)
+}
+
+// clearDefaults forgets the remembered arguments of a session
+func (m *sessionManager) clearDefaults(session *mcp.ServerSession) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if state, ok := m.sessions[session]; ok {
+		state.defaults = nil
+	}
+}
+
+// defaultsMiddleware remembers the defaultable arguments of every successful
+// tool call and fills them into later calls of the same session to tools requiring
+// them, so a version given once does not have to be repeated
+func defaultsMiddleware(next mcp.MethodHandler[*mcp.ServerSession]) mcp.MethodHandler[*mcp.ServerSession] {
+	return func(ctx context.Context, session *mcp.ServerSession, method string, params mcp.Params) (mcp.Result, error) {
+		callParams, ok := params.(*mcp.CallToolParamsFor[json.RawMessage])
+		if method != "tools/call" || !ok || session == nil {
+			return next(ctx, session, method, params)
+		}
+
+		args := map[string]any{}
+		_ = json.Unmarshal(callParams.Arguments, &args)
+
+		defaults := sessions.defaults(session)
+		filled := false
+		if len(defaults) > 0 {
+			required := requiredArgs(ctx, session, next, callParams.Name)
+			for name, value := range defaults {
+				if current, _ := args[name].(string); current == "" && required[name] {
+					args[name] = value
+					filled = true
+					slog.DebugContext(ctx, "Filled argument from the session defaults", "tool", callParams.Name, "argument", name, "value", value)
+				}
+			}
+		}
+		if filled {
+			raw, err := json.Marshal(args)
+			if err != nil {
+				return nil, err
+			}
+			callParams.Arguments = raw
+		}
+
+		result, err := next(ctx, session, method, params)
+		// Arguments of failed calls, such as a mistyped version, are not remembered
+		if toolResult, ok := result.(*mcp.CallToolResult); err == nil && ok && !toolResult.IsError {
+			if err := sessions.remember(session, args); err != nil {
+				slog.WarnContext(ctx, "Failed to remember the session defaults", "tool", callParams.Name, "error", err)
+			}
+		}
+		return result, err
+	}
+}
+
+// requiredArgs returns the required arguments of a registered tool
+func requiredArgs(ctx context.Context, session *mcp.ServerSession, next mcp.MethodHandler[*mcp.ServerSession], name string) map[string]bool {
+	listParams := &mcp.ListToolsParams{}
+	for {
+		result, err := next(ctx, session, "tools/list", listParams)
+		if err != nil {
+			return nil
+		}
+		page := result.(*mcp.ListToolsResult)
+		for _, tool := range page.Tools {
+			if tool.Name == name && tool.InputSchema != nil {
+				required := make(map[string]bool, len(tool.InputSchema.Required))
+				for _, arg := range tool.InputSchema.Required {
+					required[arg] = true
+				}
+				return required
+			}
+		}
+		if page.NextCursor == "" {
+			return nil
+		}
+		listParams = &mcp.ListToolsParams{Cursor: page.NextCursor}
+	}
+}
+
+func formatSessionDefaults(result SessionDefaultsResult) string {
+	if result.Cleared {
+		return "Cleared the session defaults\n"
+	}
+	if len(result.Defaults) == 0 {
+		return "No session defaults, they are remembered from the arguments of your tool calls\n"
+	}
+	var sb strings.Builder
+	sb.WriteString("Tool calls omitting these required arguments use:\n")
+	for _, name := range slices.Sorted(maps.Keys(result.Defaults)) {
+		fmt.Fprintf(&sb, "- %s: %s\n", name, result.Defaults[name])
+	}
+	return sb.String()
+}
@@ -0,0 +1,135 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+
+	mcp "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// sessionManager tracks the state of the sessions connected to the server.
+// The server is shared by every HTTP session, so tool runs must not rely on
+// process-wide state such as the working directory or fixed clone paths.
+type sessionManager struct {
+	mu       sync.Mutex
+	sessions map[*mcp.ServerSession]*sessionState
+}
+
+// sessionState represents the state owned by a single session
+type sessionState struct {
+	dir      string            // Parent of the working directories of the session's tool runs
+	defaults map[string]string // Arguments filled into later calls, see defaultsMiddleware
+	jobs     sync.WaitGroup    // Running jobs started by the session, see asyncMiddleware
+}
+
+var sessions = &sessionManager{sessions: map[*mcp.ServerSession]*sessionState{}}
+
+// state returns the state of a session, creating it on first use. The state is
+// released once the session ends.
+func (m *sessionManager) state(session *mcp.ServerSession) (*sessionState, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if state, ok := m.sessions[session]; ok {
+		return state, nil
+	}
+	dir, err := os.MkdirTemp("", "release-mcp-session-*")
+	if err != nil {
+		return nil, err
+	}
+	state := &sessionState{dir: dir}
+	m.sessions[session] = state
+
+	go func() {
+		_ = session.Wait()
+		m.release(session)
+	}()
+	return state, nil
+}
+
+// startJob keeps the working directories of a session until the job it
+// starts calls the returned function, as jobs outlive the request, and the
+// session, that started them
+func (m *sessionManager) startJob(session *mcp.ServerSession) (func(), error) {
+	state, err := m.state(session)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session directory: %w", err)
+	}
+	state.jobs.Add(1)
+	return state.jobs.Done, nil
+}
+
+// release removes the state of an ended session and its working directories,
+// once the jobs the session started have finished
+func (m *sessionManager) release(session *mcp.ServerSession) {
+	m.mu.Lock()
+	state, ok := m.sessions[session]
+	delete(m.sessions, session)
+	m.mu.Unlock()
+
+	if ok {
+		state.jobs.Wait()
+		if err := os.RemoveAll(state.dir); err != nil {
+			slog.Warn("Failed to clean up session directory", "path", state.dir, "error", err)
+		}
+	}
+}
+
+// runDir returns a fresh working directory for a tool run, so concurrent runs
+// never share a clone. Callers remove it once the run ends: sessions of the
+// HTTP server and the admin API last long, and run-tool has no session to
+// clean up after it.
+func runDir(session *mcp.ServerSession, name string) (string, error) {
+	if session == nil {
+		return os.MkdirTemp("", name+"-*")
+	}
+	state, err := sessions.state(session)
+	if err != nil {
+		return "", fmt.Errorf("failed to create session directory: %w", err)
+	}
+	return os.MkdirTemp(state.dir, name+"-*")
+}
+
+// versionLocks serializes destructive tool calls targeting the same version,
+// as they push to the same branches regardless of the session they come from
+var (
+	versionLocks   = map[string]*sync.Mutex{}
+	versionLocksMu sync.Mutex
+)
+
+func versionLock(version string) *sync.Mutex {
+	versionLocksMu.Lock()
+	defer versionLocksMu.Unlock()
+	if versionLocks[version] == nil {
+		versionLocks[version] = &sync.Mutex{}
+	}
+	return versionLocks[version]
+}
+
+// versionLockMiddleware runs guarded tool calls for a version one at a time
+func versionLockMiddleware(next mcp.MethodHandler[*mcp.ServerSession]) mcp.MethodHandler[*mcp.ServerSession] {
+	return func(ctx context.Context, session *mcp.ServerSession, method string, params mcp.Params) (mcp.Result, error) {
+		callParams, ok := params.(*mcp.CallToolParamsFor[json.RawMessage])
+		if method != "tools/call" || !ok {
+			return next(ctx, session, method, params)
+		}
+
+		windowGuardedToolsMu.Lock()
+		guarded := windowGuardedTools[callParams.Name]
+		windowGuardedToolsMu.Unlock()
+		if !guarded {
+			return next(ctx, session, method, params)
+		}
+
+		var args map[string]any
+		_ = json.Unmarshal(callParams.Arguments, &args)
+		lock := versionLock(auditVersion(args))
+		lock.Lock()
+		defer lock.Unlock()
+		return next(ctx, session, method, params)
+	}
+}
@@ -0,0 +1,48 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	mcp "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestSessionReleaseWaitsForJobs(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "session")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	session := &mcp.ServerSession{}
+	m := &sessionManager{sessions: map[*mcp.ServerSession]*sessionState{session: {dir: dir}}}
+
+	jobDone, err := m.startJob(session)
+	if err != nil {
+		t.Fatal(err)
+	}
+	released := make(chan struct{})
+	go func() {
+		m.release(session)
+		close(released)
+	}()
+
+	select {
+	case <-released:
+		t.Fatal("release() returned while a job of the session was running")
+	case <-time.After(100 * time.Millisecond):
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Fatalf("session directory removed while a job was running: %v", err)
+	}
+
+	jobDone()
+	select {
+	case <-released:
+	case <-time.After(5 * time.Second):
+		t.Fatal("release() did not return once the job ended")
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("session directory kept after release: %v", err)
+	}
+}
@@ -0,0 +1,165 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// snapshotGVR identifies the Konflux Snapshot resource
+var snapshotGVR = schema.GroupVersionResource{
+	Group:    "appstudio.redhat.com",
+	Version:  "v1alpha1",
+	Resource: "snapshots",
+}
+
+const (
+	// snapshotApplicationLabel is the Snapshot label naming its application
+	snapshotApplicationLabel = "appstudio.openshift.io/application"
+	// snapshotTestCondition is the Snapshot condition reporting the integration tests
+	snapshotTestCondition = "AppStudioTestSucceeded"
+	// defaultSnapshotLimit is the number of snapshots listed unless asked otherwise
+	defaultSnapshotLimit = 10
+)
+
+// SnapshotComponent represents a component image of a Snapshot
+type SnapshotComponent struct {
+	Name      string `json:"name"`
+	Image     string `json:"image"`
+	Digest    string `json:"digest,omitempty"`
+	Revision  string `json:"revision,omitempty" jsonschema:"Git commit the image was built from"`
+	SourceURL string `json:"source_url,omitempty"`
+}
+
+// SnapshotInfo represents a Snapshot that can be released
+type SnapshotInfo struct {
+	Name        string              `json:"name"`
+	CreatedAt   time.Time           `json:"created_at"`
+	TestsPassed *bool               `json:"tests_passed,omitempty" jsonschema:"Whether the integration tests of the snapshot succeeded, unset while they run"`
+	Components  []SnapshotComponent `json:"components"`
+}
+
+// ListSnapshotsResult represents the structured result of list-snapshots
+type ListSnapshotsResult struct {
+	Application string         `json:"application"`
+	Namespace   string         `json:"namespace"`
+	Snapshots   []SnapshotInfo `json:"snapshots"`
+}
+
+// listSnapshots returns the most recent Snapshots of an application, newest
+// first, with the images of their components
+func listSnapshots(ctx context.Context, application, namespace string, limit int) (ListSnapshotsResult, error) {
+	if namespace == "" {
+		namespace = tenantNamespace()
+	}
+	if limit <= 0 {
+		limit = defaultSnapshotLimit
+	}
+	result := ListSnapshotsResult{Application: application, Namespace: namespace}
+	if application == "" {
+		return result, fmt.Errorf("application is required")
+	}
+
+	dc, err := dynamicClient(ctx)
+	if err != nil {
+		return result, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+	list, err := dc.Resource(snapshotGVR).Namespace(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: snapshotApplicationLabel + "=" + application,
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	for i := range list.Items {
+		snapshot := &list.Items[i]
+		// The label is set by Konflux, the spec is authoritative
+		if app, _, _ := unstructured.NestedString(snapshot.Object, "spec", "application"); app != application {
+			continue
+		}
+		result.Snapshots = append(result.Snapshots, snapshotInfo(snapshot))
+	}
+	sort.Slice(result.Snapshots, func(i, j int) bool {
+		return result.Snapshots[i].CreatedAt.After(result.Snapshots[j].CreatedAt)
+	})
+	if len(result.Snapshots) > limit {
+		result.Snapshots = result.Snapshots[:limit]
+	}
+	return result, nil
+}
+
+// snapshotInfo extracts the components and test outcome of a Snapshot
+func snapshotInfo(snapshot *unstructured.Unstructured) SnapshotInfo {
+	info := SnapshotInfo{Name: snapshot.GetName(), CreatedAt: snapshot.GetCreationTimestamp().UTC()}
+
+	components, _, _ := unstructured.NestedSlice(snapshot.Object, "spec", "components")
+	for _, item := range components {
+		values, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		component := SnapshotComponent{}
+		component.Name, _ = values["name"].(string)
+		component.Image, _ = values["containerImage"].(string)
+		if _, digest, ok := strings.Cut(component.Image, "@"); ok {
+			component.Digest = digest
+		}
+		component.Revision, _, _ = unstructured.NestedString(values, "source", "git", "revision")
+		component.SourceURL, _, _ = unstructured.NestedString(values, "source", "git", "url")
+		info.Components = append(info.Components, component)
+	}
+	sort.Slice(info.Components, func(i, j int) bool { return info.Components[i].Name < info.Components[j].Name })
+
+	conditions, _, _ := unstructured.NestedSlice(snapshot.Object, "status", "conditions")
+	for _, item := range conditions {
+		values, ok := item.(map[string]any)
+		if !ok || values["type"] != snapshotTestCondition {
+			continue
+		}
+		switch values["status"] {
+		case string(metav1.ConditionTrue):
+			passed := true
+			info.TestsPassed = &passed
+		case string(metav1.ConditionFalse):
+			passed := false
+			info.TestsPassed = &passed
+		}
+	}
+	return info
+}
+
+func formatSnapshots(result ListSnapshotsResult) string {
+	var sb strings.Builder
+	if len(result.Snapshots) == 0 {
+		fmt.Fprintf(&sb, "No snapshots of %s in %s\n", result.Application, result.Namespace)
+		return sb.String()
+	}
+	for _, snapshot := range result.Snapshots {
+		tests := "tests running"
+		if snapshot.TestsPassed != nil {
+			tests = "tests failed"
+			if *snapshot.TestsPassed {
+				tests = "tests passed"
+			}
+		}
+		fmt.Fprintf(&sb, "%s (created %s, %s)\n", snapshot.Name, snapshot.CreatedAt.Format(time.RFC3339), tests)
+		for _, component := range snapshot.Components {
+			revision := component.Revision
+			if len(revision) > 12 {
+				revision = revision[:12]
+			}
+			fmt.Fprintf(&sb, "  - %s: %s", component.Name, component.Image)
+			if revision != "" {
+				fmt.Fprintf(&sb, " (%s)", revision)
+			}
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,161 @@
+package tools
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	mcp "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// recentAuditEvents is how many audit events the release status includes
+const recentAuditEvents = 10
+
+// releaseSteps are the tool calls making up a release, in order
+var releaseSteps = []string{
+	"create-release-branches",
+	"configure-hack-repo",
+	"create-release-plans",
+	"configure-hack-next",
+}
+
+// Release step states
+const (
+	stepPending = "pending"
+	stepDone    = "done"
+	stepFailed  = "failed"
+)
+
+// ReleaseStep represents the state of a release step
+type ReleaseStep struct {
+	Tool  string    `json:"tool"`
+	State string    `json:"state"`
+	Time  time.Time `json:"time,omitempty"`
+}
+
+// ReleaseStatus represents the state of a release as reported by the status resource
+type ReleaseStatus struct {
+	Version      string        `json:"version"`
+	Phase        string        `json:"phase"`
+	Dates        *ReleaseDates `json:"dates,omitempty"`
+	Steps        []ReleaseStep `json:"steps"`
+	RecentEvents []AuditEvent  `json:"recent_events,omitempty"`
+}
+
+// readAuditEvents returns the audit events recorded for a version, oldest first
+func readAuditEvents(version string) ([]AuditEvent, error) {
+	file, err := os.Open(filepath.Join(stateDir(), version, auditLogFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var events []AuditEvent
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var event AuditEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			continue
+		}
+		events = append(events, event)
+	}
+	return events, scanner.Err()
+}
+
+// releasePhase derives where a version is in its release cycle from its calendar dates
+func releasePhase(dates *ReleaseDates, now time.Time) string {
+	if dates == nil {
+		return "unscheduled"
+	}
+	today := now.Format("2006-01-02")
+	phase := "planning"
+	for _, milestone := range []struct{ date, phase string }{
+		{dates.MergeWindowStart, "merge window"},
+		{dates.CodeFreeze, "code freeze"},
+		{dates.BranchDate, "branched"},
+		{dates.GA, "released"},
+	} {
+		// Dates use the YYYY-MM-DD format, so they compare as strings
+		if milestone.date != "" && milestone.date <= today {
+			phase = milestone.phase
+		}
+	}
+	return phase
+}
+
+// releaseStatus assembles the release state of a version from the calendar and audit log
+func releaseStatus(version string, now time.Time) (ReleaseStatus, error) {
+	status := ReleaseStatus{Version: version}
+
+	calendar, err := loadReleaseCalendar()
+	if err != nil {
+		return status, err
+	}
+	if calendar != nil {
+		if dates, ok := calendar.Releases[version]; ok {
+			status.Dates = &dates
+		}
+	}
+	status.Phase = releasePhase(status.Dates, now)
+
+	events, err := readAuditEvents(version)
+	if err != nil {
+		return status, fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	for _, tool := range releaseSteps {
+		step := ReleaseStep{Tool: tool, State: stepPending}
+		// The latest call of a step decides its state
+		for _, event := range events {
+			if event.Tool != tool {
+				continue
+			}
+			step.Time = event.Time
+			step.State = stepFailed
+			if event.Success {
+				step.State = stepDone
+			}
+		}
+		status.Steps = append(status.Steps, step)
+	}
+
+	if len(events) > recentAuditEvents {
+		events = events[len(events)-recentAuditEvents:]
+	}
+	status.RecentEvents = events
+	return status, nil
+}
+
+// addReleaseStatusResource exposes the release state of a version as an MCP resource
+func addReleaseStatusResource(s *mcp.Server) {
+	s.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "release-status",
+		Description: "Release phase, step progress and recent audit events of a version",
+		MIMEType:    "application/json",
+		URITemplate: "release://{version}/status",
+	}, func(ctx context.Context, session *mcp.ServerSession, params *mcp.ReadResourceParams) (*mcp.ReadResourceResult, error) {
+		version := strings.TrimSuffix(strings.TrimPrefix(params.URI, "release://"), "/status")
+		if !isSafeVersionDir(version) {
+			return nil, mcp.ResourceNotFoundError(params.URI)
+		}
+		status, err := releaseStatus(version, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		content, err := json.Marshal(status)
+		if err != nil {
+			return nil, err
+		}
+		return &mcp.ReadResourceResult{
+			Contents: []*mcp.ResourceContents{{URI: params.URI, MIMEType: "application/json", Text: string(content)}},
+		}, nil
+	})
+}
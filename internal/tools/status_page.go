@@ -0,0 +1,306 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// statusPageBranch is the GitHub Pages branch the status pages are published to
+	statusPageBranch = "gh-pages"
+
+	// statusPageUpdateBranch is the branch proposing a status page update to statusPageBranch
+	statusPageUpdateBranch = "status-page-%s"
+)
+
+// StatusPageTemplate represents the template for the build status page of a release branch
+const StatusPageTemplate = `# {{.Branch}} build status
+
+Generated on {{.GeneratedAt}} from the combined CI status of each component's release branch.
+Run ` + "`create-release-branch-ci-badge-and-status-page`" + ` again to refresh it.
+
+| Component | Branch | Build |
+|---|---|---|
+{{- range .Components}}
+| [{{.Name}}]({{.URL}}) | [` + "`{{$.Branch}}`" + `]({{.URL}}/tree/{{$.Branch}}) | [![{{.Status}}]({{.BadgeURL}})]({{.URL}}/commits/{{$.Branch}}) |
+{{- end}}
+`
+
+// StatusPageConfig represents the configuration for the release branch status page
+type StatusPageConfig struct {
+	MinorVersion string
+	Repository   string // owner/name of the GitHub repository publishing the page
+	RepoPath     string
+	DryRun       bool
+	PushDirectly bool // Push to the Pages branch instead of opening a pull request
+}
+
+// ComponentBuildStatus represents the CI status of a component's release branch
+type ComponentBuildStatus struct {
+	Name     string `json:"name"`
+	URL      string `json:"url"`
+	Status   string `json:"status" jsonschema:"pending, success, failure or unknown"`
+	BadgeURL string `json:"badge_url"`
+	Error    string `json:"error,omitempty"`
+}
+
+// StatusPageResult represents the structured result of create-release-branch-ci-badge-and-status-page
+type StatusPageResult struct {
+	Branch     string                 `json:"branch"`
+	Page       string                 `json:"page" jsonschema:"Path of the page in the publishing repository"`
+	PageURL    string                 `json:"page_url"`
+	DryRun     bool                   `json:"dry_run"`
+	Published  bool                   `json:"published" jsonschema:"Whether a new version of the page was pushed to the Pages branch or proposed in a pull request"`
+	PRURL      string                 `json:"pr_url,omitempty" jsonschema:"Pull request proposing the page to the Pages branch, unless pushed directly"`
+	Content    string                 `json:"content"`
+	Components []ComponentBuildStatus `json:"components"`
+}
+
+// statusPageData represents the values rendered into the status page
+type statusPageData struct {
+	Branch      string
+	GeneratedAt string
+	Components  []ComponentBuildStatus
+}
+
+// statusBadgeURL returns a static shields.io badge for a pipeline state
+func statusBadgeURL(status string) string {
+	color := map[string]string{
+		pipelineSuccess: "brightgreen",
+		pipelinePending: "yellow",
+		pipelineFailure: "red",
+	}[status]
+	if color == "" {
+		color = "lightgrey"
+	}
+	return fmt.Sprintf("https://img.shields.io/badge/build-%s-%s", url.PathEscape(status), color)
+}
+
+func createStatusPage(ctx context.Context, config StatusPageConfig) (StatusPageResult, error) {
+	branch := fmt.Sprintf("release-v%s.x", config.MinorVersion)
+	result := StatusPageResult{Branch: branch, Page: branch + ".md", DryRun: config.DryRun}
+	if config.MinorVersion == "" {
+		return result, fmt.Errorf("minor version is required")
+	}
+	if config.Repository == "" {
+		config.Repository = hackRepo
+	}
+	if owner, name, ok := strings.Cut(config.Repository, "/"); ok {
+		result.PageURL = fmt.Sprintf("https://%s.github.io/%s/%s.html", owner, name, branch)
+	}
+
+	provider, err := vcsProvider(githubHost)
+	if err != nil {
+		return result, err
+	}
+	for _, repo := range releaseRepositories() {
+		if repo.Skip {
+			continue
+		}
+		repoPath := forgeRepoPath(repo.RepoURL)
+		component := ComponentBuildStatus{
+			Name: repo.Name,
+			URL:  fmt.Sprintf("https://%s/%s", githubHost, repoPath),
+		}
+		status, err := provider.PipelineStatus(ctx, repoPath, branch)
+		if err != nil {
+			component.Status = "unknown"
+			component.Error = err.Error()
+		} else {
+			component.Status = status
+		}
+		component.BadgeURL = statusBadgeURL(component.Status)
+		result.Components = append(result.Components, component)
+	}
+
+	tmpl, err := parseRegisteredTemplate("status-page")
+	if err != nil {
+		return result, fmt.Errorf("failed to parse status page template: %w", err)
+	}
+	var page bytes.Buffer
+	data := statusPageData{
+		Branch:      branch,
+		GeneratedAt: time.Now().UTC().Format("2006-01-02 15:04 MST"),
+		Components:  result.Components,
+	}
+	if err := executeTemplate(tmpl, &page, data); err != nil {
+		return result, err
+	}
+	result.Content = page.String()
+	if config.DryRun {
+		return result, nil
+	}
+
+	result.Published, result.PRURL, err = publishStatusPage(ctx, config, result.Page, page.Bytes())
+	return result, err
+}
+
+// publishStatusPage commits the page on top of the GitHub Pages branch of the
+// repository and proposes it in a pull request, or pushes it to the branch
+// when asked to, and reports whether it changed. The branch is only created
+// when pushing directly, a pull request cannot target a missing branch.
+func publishStatusPage(ctx context.Context, config StatusPageConfig, page string, content []byte) (bool, string, error) {
+	// Only the contents of the Pages branch are needed, fetched when it is checked out
+	cloneCmd, err := gitCommand(ctx, githubHost, "clone", partialCloneFilter, forgeRepoURL(githubHost, config.Repository), config.RepoPath)
+	if err != nil {
+		return false, "", err
+	}
+	var cloneStderr bytes.Buffer
+	cloneCmd.Stderr = &cloneStderr
+	if err := runCommand(ctx, cloneCmd); err != nil {
+		return false, "", fmt.Errorf("failed to clone %s: %v\nError details: %s", config.Repository, err, cloneStderr.String())
+	}
+	runUsageFrom(ctx).recordClone(config.RepoPath)
+
+	pushBranch := statusPageBranch
+	if !config.PushDirectly {
+		pushBranch = fmt.Sprintf(statusPageUpdateBranch, strings.TrimSuffix(page, ".md"))
+	}
+	checkout := [][]string{{"checkout", "-B", pushBranch, "origin/" + statusPageBranch}}
+	verifyCmd := exec.CommandContext(ctx, "git", "rev-parse", "--verify", "--quiet", "origin/"+statusPageBranch)
+	verifyCmd.Dir = config.RepoPath
+	if err := runCommand(ctx, verifyCmd); err != nil {
+		if !config.PushDirectly {
+			return false, "", fmt.Errorf("%s has no %s branch to open a pull request against, set push_directly to create it", config.Repository, statusPageBranch)
+		}
+		// Start the Pages branch without the history and files of the default branch
+		checkout = [][]string{{"checkout", "--orphan", statusPageBranch}, {"rm", "-rf", "--quiet", "."}}
+	}
+	for _, args := range checkout {
+		cmd, err := gitCommand(ctx, githubHost, args...)
+		if err != nil {
+			return false, "", err
+		}
+		cmd.Dir = config.RepoPath
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		if err := runCommand(ctx, cmd); err != nil {
+			return false, "", fmt.Errorf("git %s failed: %v\nError details: %s", args[0], err, stderr.String())
+		}
+	}
+
+	if err := writeFile(ctx, filepath.Join(config.RepoPath, page), content, 0644); err != nil {
+		return false, "", fmt.Errorf("failed to write %s: %w", page, err)
+	}
+
+	// Only the generation time changes between runs when no build status did
+	if changed, err := statusPageChanged(ctx, config.RepoPath, page); err != nil || !changed {
+		if !changed {
+			restoreCmd := exec.CommandContext(ctx, "git", "checkout", "--", page)
+			restoreCmd.Dir = config.RepoPath
+			_ = runCommand(ctx, restoreCmd)
+		}
+		return false, "", err
+	}
+
+	commitMsg := fmt.Sprintf("Update %s build status", strings.TrimSuffix(page, ".md"))
+	for _, args := range [][]string{{"add", page}, {"commit", "-m", commitMsg}} {
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Dir = config.RepoPath
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		if err := runCommand(ctx, cmd); err != nil {
+			return false, "", fmt.Errorf("git %s failed: %v\nError details: %s", args[0], err, stderr.String())
+		}
+	}
+
+	// The update branch is recreated from the Pages branch on every run
+	pushArgs := []string{"push", "origin", pushBranch}
+	if !config.PushDirectly {
+		pushArgs = []string{"push", "-f", "origin", pushBranch}
+	}
+	pushCmd, err := gitCommand(ctx, githubHost, pushArgs...)
+	if err != nil {
+		return false, "", err
+	}
+	pushCmd.Dir = config.RepoPath
+	var pushStderr bytes.Buffer
+	pushCmd.Stderr = &pushStderr
+	if err := runCommand(ctx, pushCmd); err != nil {
+		return false, "", fmt.Errorf("failed to push %s: %v\nError details: %s", pushBranch, err, pushStderr.String())
+	}
+	if config.PushDirectly {
+		return true, "", nil
+	}
+
+	// A pull request still open from a previous run now carries the new commit
+	if existing, err := latestChangeRequest(ctx, githubHost, config.Repository, commitMsg); err != nil {
+		return true, "", fmt.Errorf("branch %s was pushed, open the pull request manually: %w", pushBranch, err)
+	} else if existing != nil && existing.State == "open" {
+		return true, existing.URL, nil
+	}
+	provider, err := vcsProvider(githubHost)
+	if err != nil {
+		return true, "", fmt.Errorf("branch %s was pushed, open the pull request manually: %w", pushBranch, err)
+	}
+	pr, err := provider.OpenChangeRequest(ctx, config.Repository, ChangeRequest{
+		Title:        commitMsg,
+		Body:         fmt.Sprintf("Refreshes the build status page of %s on the %s branch.", strings.TrimSuffix(page, ".md"), statusPageBranch),
+		SourceBranch: pushBranch,
+		TargetBranch: statusPageBranch,
+	})
+	if err != nil {
+		return true, "", fmt.Errorf("failed to open pull request: %w", err)
+	}
+	return true, pr.URL, nil
+}
+
+// statusPageChanged reports whether the page differs from the committed one
+// in more than its generation time
+func statusPageChanged(ctx context.Context, repoPath, page string) (bool, error) {
+	// A page that is not tracked yet is new
+	lsCmd := exec.CommandContext(ctx, "git", "ls-files", "--", page)
+	lsCmd.Dir = repoPath
+	tracked, err := commandOutput(ctx, lsCmd)
+	if err != nil {
+		return false, fmt.Errorf("failed to list %s: %w", page, err)
+	}
+	if len(bytes.TrimSpace(tracked)) == 0 {
+		return true, nil
+	}
+
+	diffCmd := exec.CommandContext(ctx, "git", "diff", "--unified=0", "--no-color", "--", page)
+	diffCmd.Dir = repoPath
+	output, err := commandOutput(ctx, diffCmd)
+	if err != nil {
+		return false, fmt.Errorf("failed to diff %s: %w", page, err)
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		if !strings.HasPrefix(line, "+") && !strings.HasPrefix(line, "-") {
+			continue
+		}
+		if strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "---") || strings.Contains(line, "Generated on ") {
+			continue
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+func formatStatusPage(result StatusPageResult) string {
+	var sb strings.Builder
+	for _, component := range result.Components {
+		if component.Error != "" {
+			fmt.Fprintf(&sb, "? %s: %s\n", component.Name, component.Error)
+			continue
+		}
+		fmt.Fprintf(&sb, "%s: %s\n", component.Name, component.Status)
+	}
+	switch {
+	case result.PRURL != "":
+		fmt.Fprintf(&sb, "\nProposed %s to the %s branch in %s, it is published at %s once merged\n", result.Page, statusPageBranch, result.PRURL, result.PageURL)
+	case result.Published:
+		fmt.Fprintf(&sb, "\nPublished %s to the %s branch: %s\n", result.Page, statusPageBranch, result.PageURL)
+	case result.DryRun:
+		fmt.Fprintf(&sb, "\n%s", result.Content)
+	default:
+		fmt.Fprintf(&sb, "\nNo build status changed since %s was last published: %s\n", result.Page, result.PageURL)
+	}
+	return sb.String()
+}
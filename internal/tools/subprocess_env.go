@@ -0,0 +1,60 @@
+package tools
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// baseEnv are the environment variables passed to every subprocess
+var baseEnv = []string{
+	"PATH", "HOME", "USER", "LANG", "LC_ALL", "TZ", "TMPDIR",
+	"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "http_proxy", "https_proxy", "no_proxy",
+	"SSL_CERT_FILE", "SSL_CERT_DIR",
+}
+
+// subprocessEnv are the environment variables passed to a subprocess on top of
+// baseEnv, by executable name. Names ending with * match a prefix. Credentials
+// of the server, such as GITHUB_TOKEN and GITLAB_TOKEN, are never inherited:
+// git receives them through the askpass variables set by gitCommand.
+var subprocessEnv = map[string][]string{
+	"git":                {"GIT_*", "SSH_AUTH_SOCK", "XDG_CONFIG_HOME", askpassUsernameEnv, askpassPasswordEnv},
+	"crane":              {"DOCKER_CONFIG", "REGISTRY_AUTH_FILE", "XDG_RUNTIME_DIR"},
+	"oras":               {"DOCKER_CONFIG", "REGISTRY_AUTH_FILE", "XDG_RUNTIME_DIR"},
+	"cosign":             {"DOCKER_CONFIG", "REGISTRY_AUTH_FILE", "XDG_RUNTIME_DIR", "COSIGN_*", "SIGSTORE_*", "TUF_ROOT"},
+	"kustomize":          {"KUSTOMIZE_*", "XDG_CONFIG_HOME"},
+	"build-manifests.sh": {"KUSTOMIZE_*", "XDG_CONFIG_HOME"},
+}
+
+// restrictEnv limits the environment of cmd to the variables allowed for its
+// executable. Variables explicitly set on the command are filtered the same way.
+func restrictEnv(cmd *exec.Cmd) {
+	env := cmd.Env
+	if env == nil {
+		env = os.Environ()
+	}
+	allowed := append(append([]string(nil), baseEnv...), subprocessEnv[filepath.Base(cmd.Path)]...)
+
+	filtered := make([]string, 0, len(env))
+	for _, kv := range env {
+		name, _, _ := strings.Cut(kv, "=")
+		if envAllowed(name, allowed) {
+			filtered = append(filtered, kv)
+		}
+	}
+	cmd.Env = filtered
+}
+
+func envAllowed(name string, allowed []string) bool {
+	for _, pattern := range allowed {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+		} else if name == pattern {
+			return true
+		}
+	}
+	return false
+}
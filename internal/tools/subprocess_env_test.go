@@ -0,0 +1,50 @@
+package tools
+
+import (
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+func TestEnvAllowed(t *testing.T) {
+	allowed := []string{"PATH", "GIT_*"}
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{name: "PATH", want: true},
+		{name: "GIT_TRACE", want: true},
+		{name: "GIT_", want: true},
+		{name: "PATHEXT"},
+		{name: "GITHUB_TOKEN"},
+		{name: "path"},
+	}
+	for _, tt := range tests {
+		if got := envAllowed(tt.name, allowed); got != tt.want {
+			t.Errorf("envAllowed(%q) = %t, want %t", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRestrictEnv(t *testing.T) {
+	env := []string{"PATH=/usr/bin", "HOME=/root", "GITHUB_TOKEN=secret", "GIT_TRACE=1", "KUSTOMIZE_PLUGIN_HOME=/plugins", "DOCKER_CONFIG=/docker"}
+	tests := []struct {
+		name string
+		path string
+		want []string
+	}{
+		{name: "git", path: "/usr/bin/git", want: []string{"PATH=/usr/bin", "HOME=/root", "GIT_TRACE=1"}},
+		{name: "kustomize", path: "/usr/local/bin/kustomize", want: []string{"PATH=/usr/bin", "HOME=/root", "KUSTOMIZE_PLUGIN_HOME=/plugins"}},
+		{name: "crane", path: "crane", want: []string{"PATH=/usr/bin", "HOME=/root", "DOCKER_CONFIG=/docker"}},
+		{name: "other executable", path: "/usr/bin/skopeo", want: []string{"PATH=/usr/bin", "HOME=/root"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &exec.Cmd{Path: tt.path, Env: env}
+			restrictEnv(cmd)
+			if !reflect.DeepEqual(cmd.Env, tt.want) {
+				t.Errorf("restrictEnv(%s) = %q, want %q", tt.path, cmd.Env, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// supersededRepository is a repository the server opens release pull or merge requests on
+type supersededRepository struct {
+	Host string
+	Repo string
+}
+
+// supersededRepositories lists where the server opens release pull and merge requests
+func supersededRepositories() []supersededRepository {
+	konflux, _ := konfluxRepository()
+	return []supersededRepository{
+		{Host: githubHost, Repo: hackRepo},
+		{Host: gitlabHost, Repo: konflux},
+	}
+}
+
+// CloseSupersededConfig represents the configuration for closing superseded requests
+type CloseSupersededConfig struct {
+	MinorVersion string
+	DryRun       bool
+}
+
+// SupersededRequest represents an open request replaced by a newer one for the same step
+type SupersededRequest struct {
+	Repository   string            `json:"repository"`
+	Request      ChangeRequestInfo `json:"request"`
+	SupersededBy ChangeRequestInfo `json:"superseded_by"`
+	Closed       bool              `json:"closed"`
+	Error        string            `json:"error,omitempty"`
+}
+
+// CloseSupersededResult represents the structured result of close-superseded-release-requests
+type CloseSupersededResult struct {
+	MinorVersion string              `json:"minor_version"`
+	DryRun       bool                `json:"dry_run"`
+	Superseded   []SupersededRequest `json:"superseded"`
+	Errors       []string            `json:"errors,omitempty" jsonschema:"Repositories whose requests could not be listed"`
+}
+
+// versionPattern matches a minor version in a title, without matching longer
+// versions such as 1.210
+func versionPattern(minorVersion string) *regexp.Regexp {
+	return regexp.MustCompile(`(^|[^0-9.])v?` + regexp.QuoteMeta(minorVersion) + `([^0-9]|$)`)
+}
+
+// supersededRequests groups the requests of a version by title, which
+// identifies the step that opened them, and returns every request but the
+// newest of each group paired with that newest request
+func supersededRequests(requests []ChangeRequestInfo, minorVersion string) [][2]ChangeRequestInfo {
+	pattern := versionPattern(minorVersion)
+	steps := make(map[string][]ChangeRequestInfo)
+	var titles []string
+	for _, request := range requests {
+		if !pattern.MatchString(request.Title) {
+			continue
+		}
+		if _, ok := steps[request.Title]; !ok {
+			titles = append(titles, request.Title)
+		}
+		steps[request.Title] = append(steps[request.Title], request)
+	}
+	sort.Strings(titles)
+
+	var pairs [][2]ChangeRequestInfo
+	for _, title := range titles {
+		group := steps[title]
+		sort.Slice(group, func(i, j int) bool {
+			if !group[i].CreatedAt.Equal(group[j].CreatedAt) {
+				return group[i].CreatedAt.After(group[j].CreatedAt)
+			}
+			return group[i].Number > group[j].Number
+		})
+		for _, old := range group[1:] {
+			pairs = append(pairs, [2]ChangeRequestInfo{old, group[0]})
+		}
+	}
+	return pairs
+}
+
+func closeSupersededRequests(ctx context.Context, config CloseSupersededConfig) (CloseSupersededResult, error) {
+	result := CloseSupersededResult{MinorVersion: config.MinorVersion, DryRun: config.DryRun}
+
+	repositories := supersededRepositories()
+	for _, repo := range repositories {
+		provider, err := vcsProvider(repo.Host)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", repo.Repo, err))
+			continue
+		}
+		requests, err := provider.ListChangeRequests(ctx, repo.Repo)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: failed to list open requests: %v", repo.Repo, err))
+			continue
+		}
+
+		for _, pair := range supersededRequests(requests, config.MinorVersion) {
+			superseded := SupersededRequest{Repository: repo.Repo, Request: pair[0], SupersededBy: pair[1]}
+			if !config.DryRun {
+				comment := fmt.Sprintf("Closing, superseded by %s after v%s was respun.", pair[1].URL, config.MinorVersion)
+				if err := provider.CloseChangeRequest(ctx, repo.Repo, pair[0].Number, comment); err != nil {
+					superseded.Error = err.Error()
+				} else {
+					superseded.Closed = true
+				}
+			}
+			result.Superseded = append(result.Superseded, superseded)
+		}
+	}
+
+	if len(result.Errors) == len(repositories) {
+		return result, fmt.Errorf("no repository could be checked: %s", strings.Join(result.Errors, "; "))
+	}
+	return result, nil
+}
+
+func formatCloseSuperseded(result CloseSupersededResult) string {
+	var sb strings.Builder
+	if len(result.Superseded) == 0 {
+		fmt.Fprintf(&sb, "No superseded requests open for v%s\n", result.MinorVersion)
+	}
+	for _, superseded := range result.Superseded {
+		status := "✓ closed"
+		switch {
+		case result.DryRun:
+			status = "- would close"
+		case superseded.Error != "":
+			status = "✗ failed to close"
+		}
+		fmt.Fprintf(&sb, "%s %s (%s), superseded by %s\n", status, superseded.Request.URL, superseded.Request.Title, superseded.SupersededBy.URL)
+		if superseded.Error != "" {
+			fmt.Fprintf(&sb, "  %s\n", superseded.Error)
+		}
+	}
+	for _, err := range result.Errors {
+		fmt.Fprintf(&sb, "⚠ %s\n", err)
+	}
+	return sb.String()
+}
@@ -0,0 +1,188 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	mcp "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// supportedVersionsURI is the resource exposing the supported versions matrix
+const supportedVersionsURI = "release://supported-versions"
+
+// SupportedVersion represents the support status of a minor version
+type SupportedVersion struct {
+	Version      string   `json:"version"`
+	GADate       string   `json:"ga_date,omitempty"`
+	EOLDate      string   `json:"eol_date,omitempty" jsonschema:"Unset when the release calendar has no GA date for the version"`
+	EndOfLife    bool     `json:"end_of_life"`
+	LatestStream string   `json:"latest_z_stream,omitempty" jsonschema:"Latest patch release tagged in the operator repository"`
+	OCPVersions  []string `json:"ocp_versions,omitempty" jsonschema:"OCP versions the prod FBC RPA releases the version to"`
+	Error        string   `json:"error,omitempty"`
+}
+
+// SupportedVersionsResult represents the structured result of list-supported-versions
+type SupportedVersionsResult struct {
+	AsOf     string             `json:"as_of"`
+	Versions []SupportedVersion `json:"versions"`
+}
+
+// listSupportedVersions assembles the support matrix of every minor version
+// released through konflux-release-data or with a GA date in the release
+// calendar. End-of-life versions are only included when asked for.
+func listSupportedVersions(ctx context.Context, repoPath string, includeEOL bool, asOf time.Time) (SupportedVersionsResult, error) {
+	result := SupportedVersionsResult{AsOf: asOf.Format("2006-01-02")}
+
+	if err := cloneKonfluxRepo(ctx, RPAConfig{RepoPath: repoPath}); err != nil {
+		return result, fmt.Errorf("failed to clone konflux-release-data repository: %w", err)
+	}
+	calendar, err := loadReleaseCalendar()
+	if err != nil {
+		return result, err
+	}
+	gh, err := newGitHubClient()
+	if err != nil {
+		return result, err
+	}
+
+	versions := make(map[string]bool)
+	entries, err := os.ReadDir(filepath.Join(repoPath, productRPADir()))
+	if err != nil {
+		return result, fmt.Errorf("failed to read directory %s: %w", productRPADir(), err)
+	}
+	// The prod FBC RPA of a minor version marks it as released
+	fbcProdRPAFile := regexp.MustCompile(`^` + regexp.QuoteMeta(product().Prefix) + `-(\d+\.\d+)-fbc-prod\.yaml$`)
+	for _, entry := range entries {
+		if match := fbcProdRPAFile.FindStringSubmatch(entry.Name()); match != nil {
+			versions[match[1]] = true
+		}
+	}
+	if calendar != nil {
+		for version, dates := range calendar.Releases {
+			// Versions not generally available yet are not supported
+			if dates.GA != "" && dates.GA <= result.AsOf {
+				versions[version] = true
+			}
+		}
+	}
+
+	for version := range versions {
+		supported := SupportedVersion{Version: version}
+		if calendar != nil && calendar.Releases[version].GA != "" {
+			eol, err := computeEOL(EOLConfig{GADates: map[string]string{version: calendar.Releases[version].GA}, AsOf: asOf})
+			if err != nil {
+				return result, err
+			}
+			supported.GADate, supported.EOLDate, supported.EndOfLife = eol[0].GADate, eol[0].EOLDate, eol[0].EndOfLife
+		}
+		if supported.EndOfLife && !includeEOL {
+			continue
+		}
+
+		ocpVersions, err := fbcOCPVersions(repoPath, version)
+		if err != nil {
+			supported.Error = err.Error()
+		}
+		for _, ocp := range ocpVersions {
+			supported.OCPVersions = append(supported.OCPVersions, strings.ReplaceAll(ocp, "-", "."))
+		}
+		sort.Slice(supported.OCPVersions, func(i, j int) bool {
+			return minorVersionLess(supported.OCPVersions[i], supported.OCPVersions[j])
+		})
+
+		patches, err := releasedPatchVersions(ctx, gh, operatorRepository(), version, -1)
+		if err != nil {
+			supported.Error = err.Error()
+		} else if len(patches) > 0 {
+			supported.LatestStream = fmt.Sprintf("%s.%d", version, patches[len(patches)-1])
+		}
+		result.Versions = append(result.Versions, supported)
+	}
+
+	// Newest first
+	sort.Slice(result.Versions, func(i, j int) bool {
+		return minorVersionLess(result.Versions[j].Version, result.Versions[i].Version)
+	})
+	return result, nil
+}
+
+// minorVersionLess compares X.Y versions numerically
+func minorVersionLess(a, b string) bool {
+	aMajor, aMinor, _ := strings.Cut(a, ".")
+	bMajor, bMinor, _ := strings.Cut(b, ".")
+	if aMajor != bMajor {
+		x, _ := strconv.Atoi(aMajor)
+		y, _ := strconv.Atoi(bMajor)
+		return x < y
+	}
+	x, _ := strconv.Atoi(aMinor)
+	y, _ := strconv.Atoi(bMinor)
+	return x < y
+}
+
+// addSupportedVersionsResource exposes the supported versions matrix as an MCP resource
+func addSupportedVersionsResource(s *mcp.Server) {
+	s.AddResource(&mcp.Resource{
+		Name:        "supported-versions",
+		Description: "Currently supported OpenShift Pipelines versions with their OCP versions, EOL dates and latest z-stream",
+		MIMEType:    "application/json",
+		URI:         supportedVersionsURI,
+	}, func(ctx context.Context, session *mcp.ServerSession, params *mcp.ReadResourceParams) (*mcp.ReadResourceResult, error) {
+		repoPath, err := runDir(session, "konflux-release-data-supported-versions")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create working directory: %w", err)
+		}
+		defer os.RemoveAll(repoPath)
+
+		result, err := listSupportedVersions(ctx, repoPath, false, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		content, err := json.Marshal(result)
+		if err != nil {
+			return nil, err
+		}
+		return &mcp.ReadResourceResult{
+			Contents: []*mcp.ResourceContents{{URI: params.URI, MIMEType: "application/json", Text: string(content)}},
+		}, nil
+	})
+}
+
+func formatSupportedVersions(result SupportedVersionsResult) string {
+	var sb strings.Builder
+	if len(result.Versions) == 0 {
+		fmt.Fprintf(&sb, "No supported versions as of %s\n", result.AsOf)
+		return sb.String()
+	}
+	fmt.Fprintf(&sb, "Supported versions as of %s:\n", result.AsOf)
+	for _, version := range result.Versions {
+		fmt.Fprintf(&sb, "- %s", version.Version)
+		if version.LatestStream != "" {
+			fmt.Fprintf(&sb, " (latest %s)", version.LatestStream)
+		}
+		switch {
+		case version.EndOfLife:
+			fmt.Fprintf(&sb, ", end of life since %s", version.EOLDate)
+		case version.EOLDate != "":
+			fmt.Fprintf(&sb, ", GA %s, supported until %s", version.GADate, version.EOLDate)
+		default:
+			sb.WriteString(", no GA date in the release calendar")
+		}
+		if len(version.OCPVersions) > 0 {
+			fmt.Fprintf(&sb, ", OCP %s", strings.Join(version.OCPVersions, ", "))
+		}
+		if version.Error != "" {
+			fmt.Fprintf(&sb, " (%s)", version.Error)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
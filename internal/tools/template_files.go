@@ -0,0 +1,150 @@
+package tools
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// embeddedTemplates holds the built-in template files registered templates are assembled from
+//
+//go:embed templates/*.tmpl
+var embeddedTemplates embed.FS
+
+// templatesDir is the directory whose template files replace the embedded ones
+var (
+	templatesDir   string
+	templatesDirMu sync.RWMutex
+)
+
+// SetTemplatesDir makes the template files of dir replace the embedded files
+// of the same name. Files are read on every render, so changes apply without
+// a restart. Every file must replace an embedded one, or be a locale variant
+// of the file of a notification template (e.g., release-plan-mr.fr.md.tmpl),
+// and parse.
+func SetTemplatesDir(dir string) error {
+	if dir == "" {
+		return nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read templates directory %s: %w", dir, err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".tmpl") {
+			continue
+		}
+		if file, locale := splitLocalizedName(name, isLocalizedTemplateFile); locale != "" {
+			if err := parseLocalizedTemplateFile(dir, name, file); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := fs.Stat(embeddedTemplates, "templates/"+name); err != nil {
+			return fmt.Errorf("unknown template file %s in %s, expected one of %s or their locale variants", name, dir, strings.Join(embeddedTemplateFiles(), ", "))
+		}
+	}
+
+	templatesDirMu.Lock()
+	previous := templatesDir
+	templatesDir = dir
+	templatesDirMu.Unlock()
+
+	for _, name := range templateNames() {
+		registered := registeredTemplates[name]
+		if len(registered.files) == 0 {
+			continue
+		}
+		if _, err := newRegisteredTemplate(name, registered, templateFilesText(registered.files)); err != nil {
+			templatesDirMu.Lock()
+			templatesDir = previous
+			templatesDirMu.Unlock()
+			return fmt.Errorf("invalid template %q with the files of %s: %w", name, dir, err)
+		}
+	}
+	return nil
+}
+
+// parseLocalizedTemplateFile checks that the locale variant of the file of a
+// notification template parses with the functions of its template
+func parseLocalizedTemplateFile(dir, name, file string) error {
+	content, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return fmt.Errorf("failed to read template file %s: %w", name, err)
+	}
+	for templateName, registered := range registeredTemplates {
+		if !slices.Contains(registered.files, file) {
+			continue
+		}
+		if _, err := newRegisteredTemplate(templateName, registered, string(content)); err != nil {
+			return fmt.Errorf("invalid template file %s in %s: %w", name, dir, err)
+		}
+	}
+	return nil
+}
+
+// templateFilesExist reports whether every template file is in the templates
+// directory or embedded, which locale variants of template files may not be
+func templateFilesExist(files []string) bool {
+	templatesDirMu.RLock()
+	dir := templatesDir
+	templatesDirMu.RUnlock()
+
+	for _, name := range files {
+		if dir != "" {
+			if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+				continue
+			}
+		}
+		if _, err := fs.Stat(embeddedTemplates, "templates/"+name); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// embeddedTemplateFiles lists the names of the embedded template files
+func embeddedTemplateFiles() []string {
+	entries, _ := embeddedTemplates.ReadDir("templates")
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names
+}
+
+// templateFilesText concatenates template files, read from the templates
+// directory when it has them and from the embedded ones otherwise
+func templateFilesText(files []string) string {
+	templatesDirMu.RLock()
+	dir := templatesDir
+	templatesDirMu.RUnlock()
+
+	var sb strings.Builder
+	for _, name := range files {
+		if dir != "" {
+			content, err := os.ReadFile(filepath.Join(dir, name))
+			if err == nil {
+				sb.Write(content)
+				continue
+			}
+			if !os.IsNotExist(err) {
+				slog.Warn("Using the built-in template file", "file", name, "error", err)
+			}
+		}
+		content, err := embeddedTemplates.ReadFile("templates/" + name)
+		if err != nil {
+			// Registered templates only name embedded files
+			panic(fmt.Sprintf("missing embedded template file %s: %v", name, err))
+		}
+		sb.Write(content)
+	}
+	return sb.String()
+}
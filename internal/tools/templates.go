@@ -0,0 +1,277 @@
+package tools
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// registeredTemplate represents a template manifests are rendered from
+type registeredTemplate struct {
+	text      string
+	files     []string // Template files the text is assembled from, see SetTemplatesDir
+	funcs     template.FuncMap
+	markdown  bool // Markdown and AsciiDoc output is not linted as YAML
+	localized bool // Template files and overrides may have locale variants, see lookupLocalizedTemplate
+}
+
+// registeredTemplates holds every template the tools render, by name
+var registeredTemplates = map[string]registeredTemplate{
+	"rpa":                 {files: []string{"rpa.yaml.tmpl", "release-notes-fixes.tmpl"}},
+	"rp":                  {files: []string{"rp.yaml.tmpl", "release-notes-fixes.tmpl"}, funcs: template.FuncMap{"title": titleCase, "indent": indentLines}},
+	"generic-rpa":         {text: GenericRPATemplate},
+	"generic-rp":          {text: GenericRPTemplate},
+	"konflux-application": {text: KonfluxApplicationTemplate},
+	"konflux-component":   {text: KonfluxComponentTemplate},
+	"imageset":            {text: ImageSetConfigTemplate},
+	"release-readme":      {text: ReleaseReadmeTemplate, markdown: true},
+	"status-page":         {text: StatusPageTemplate, markdown: true},
+	"release-notes-docs":  {text: ReleaseNotesDocsTemplate, markdown: true},
+
+	// Notification templates, the descriptions of the pull and merge requests the tools open
+	"release-plan-mr":       {files: []string{"release-plan-mr.md.tmpl"}, funcs: notificationFuncs, markdown: true, localized: true},
+	"hack-config-pr":        {files: []string{"hack-config-pr.md.tmpl"}, funcs: notificationFuncs, markdown: true, localized: true},
+	"branch-tekton-pr":      {files: []string{"branch-tekton-pr.md.tmpl"}, funcs: notificationFuncs, markdown: true, localized: true},
+	"cherry-pick-pr":        {files: []string{"cherry-pick-pr.md.tmpl"}, funcs: notificationFuncs, markdown: true, localized: true},
+	"onboarding-mr":         {files: []string{"onboarding-mr.md.tmpl"}, funcs: notificationFuncs, markdown: true, localized: true},
+	"orphaned-resources-mr": {files: []string{"orphaned-resources-mr.md.tmpl"}, funcs: notificationFuncs, markdown: true, localized: true},
+	"release-notes-pr":      {files: []string{"release-notes-pr.md.tmpl"}, funcs: notificationFuncs, markdown: true, localized: true},
+}
+
+// templateErrorLine matches the line number in text/template and YAML errors
+var templateErrorLine = regexp.MustCompile(`^(?:template: [^:]+:|yaml: line )(\d+)`)
+
+// TemplateError represents a problem found while rendering a template
+type TemplateError struct {
+	Stage   string `json:"stage" jsonschema:"Stage that failed: parse, execute or yaml"`
+	Line    int    `json:"line,omitempty" jsonschema:"Line of the template (parse, execute) or of the rendered output (yaml)"`
+	Message string `json:"message"`
+	Source  string `json:"source,omitempty" jsonschema:"Content of the offending line"`
+}
+
+// RenderTemplateResult represents the structured result of render-template
+type RenderTemplateResult struct {
+	Template string          `json:"template"`
+	Rendered string          `json:"rendered,omitempty" jsonschema:"Rendered output, or the output rendered before an execute error"`
+	Errors   []TemplateError `json:"errors,omitempty"`
+}
+
+func templateNames() []string {
+	var names []string
+	for name := range registeredTemplates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// templateEnum lists the registered templates for the render-template input schema
+func templateEnum() []any {
+	var enum []any
+	for _, name := range templateNames() {
+		enum = append(enum, name)
+	}
+	return enum
+}
+
+func unknownTemplateError(name string) error {
+	return fmt.Errorf("unknown template %q, expected one of %s", name, strings.Join(templateNames(), ", "))
+}
+
+// lookupTemplate returns a registered template, with its text assembled from
+// its template files and replaced by the override of the server configuration
+// when there is one. Localized templates are looked up in the locale of the
+// server configuration.
+func lookupTemplate(name string) (registeredTemplate, bool) {
+	return lookupLocalizedTemplate(name, currentConfig().Locale)
+}
+
+// lookupLocalizedTemplate returns a registered template in a locale. The
+// override and template files of the most specific locale win, e.g. for pt-BR
+// the pt-BR variants, then the pt ones, then the default template.
+func lookupLocalizedTemplate(name, locale string) (registeredTemplate, bool) {
+	registered, ok := registeredTemplates[name]
+	if !ok {
+		return registered, false
+	}
+	locales := []string{""}
+	if registered.localized {
+		locales = localeCandidates(locale)
+	}
+	overrides := currentConfig().Templates
+	for _, candidate := range locales {
+		key := name
+		if candidate != "" {
+			key += "." + candidate
+		}
+		if text, ok := overrides[key]; ok {
+			registered.text = text
+			return registered, true
+		}
+		if len(registered.files) == 0 {
+			continue
+		}
+		files := make([]string, len(registered.files))
+		for i, file := range registered.files {
+			files[i] = localizedFileName(file, candidate)
+		}
+		if candidate == "" || templateFilesExist(files) {
+			registered.text = templateFilesText(files)
+			return registered, true
+		}
+	}
+	return registered, true
+}
+
+// newRegisteredTemplate parses text with the functions of a registered template
+func newRegisteredTemplate(name string, registered registeredTemplate, text string) (*template.Template, error) {
+	tmpl := template.New(name)
+	if registered.funcs != nil {
+		tmpl = tmpl.Funcs(registered.funcs)
+	}
+	return tmpl.Parse(text)
+}
+
+// parseRegisteredTemplate parses a registered template with its functions
+func parseRegisteredTemplate(name string) (*template.Template, error) {
+	registered, ok := lookupTemplate(name)
+	if !ok {
+		return nil, unknownTemplateError(name)
+	}
+	return newRegisteredTemplate(name, registered, registered.text)
+}
+
+// renderTemplate renders a registered template with caller data and lints the
+// output as YAML. Problems are reported in the result rather than as an error.
+func renderTemplate(name string, data map[string]any) (RenderTemplateResult, error) {
+	return renderLocalizedTemplate(name, currentConfig().Locale, data)
+}
+
+// renderLocalizedTemplate renders a registered template in a locale, see renderTemplate
+func renderLocalizedTemplate(name, locale string, data map[string]any) (RenderTemplateResult, error) {
+	result := RenderTemplateResult{Template: name}
+	registered, ok := lookupLocalizedTemplate(name, locale)
+	if !ok {
+		return result, unknownTemplateError(name)
+	}
+
+	tmpl, err := newRegisteredTemplate(name, registered, registered.text)
+	if err != nil {
+		result.Errors = append(result.Errors, newTemplateError("parse", err, registered.text))
+		return result, nil
+	}
+
+	// The release plan templates name their manifests after the selected product
+	if _, ok := data["Product"]; !ok && data != nil {
+		data["Product"] = product()
+	}
+
+	var buf bytes.Buffer
+	if err := executeTemplate(tmpl.Option("missingkey=error"), &buf, data); err != nil {
+		// The caller knows the data, the output rendered so far locates the failure
+		var execErr *TemplateExecError
+		if errors.As(err, &execErr) {
+			result.Rendered = execErr.Partial
+			err = execErr.Err
+		}
+		result.Errors = append(result.Errors, newTemplateError("execute", err, registered.text))
+		return result, nil
+	}
+	result.Rendered = buf.String()
+	if registered.markdown {
+		return result, nil
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(buf.Bytes()))
+	for {
+		var node yaml.Node
+		if err := decoder.Decode(&node); err != nil {
+			if err != io.EOF {
+				result.Errors = append(result.Errors, newTemplateError("yaml", err, result.Rendered))
+			}
+			break
+		}
+	}
+	return result, nil
+}
+
+// TemplateExecError represents a failed template execution, with the output
+// rendered before the failure and the data the template was executed with
+type TemplateExecError struct {
+	Template string
+	Err      error
+	Partial  string
+	Data     string
+}
+
+func (e *TemplateExecError) Error() string {
+	var sb strings.Builder
+	sb.WriteString(e.Err.Error())
+	if e.Partial != "" {
+		fmt.Fprintf(&sb, "\nRendered before the error:\n%s", e.Partial)
+	}
+	if e.Data != "" {
+		fmt.Fprintf(&sb, "\nTemplate data:\n%s", e.Data)
+	}
+	return sb.String()
+}
+
+func (e *TemplateExecError) Unwrap() error {
+	return e.Err
+}
+
+// executeTemplate renders a template into w. Output is only written once the
+// whole template rendered, and failures are reported as a TemplateExecError.
+func executeTemplate(tmpl *template.Template, w io.Writer, data any) error {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		execErr := &TemplateExecError{Template: tmpl.Name(), Err: err, Partial: buf.String()}
+		if dump, jsonErr := json.MarshalIndent(data, "", "  "); jsonErr == nil {
+			execErr.Data = string(dump)
+		}
+		return execErr
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// newTemplateError builds a TemplateError, resolving the line the error
+// points at in content
+func newTemplateError(stage string, err error, content string) TemplateError {
+	templateErr := TemplateError{Stage: stage, Message: err.Error()}
+	if match := templateErrorLine.FindStringSubmatch(err.Error()); match != nil {
+		templateErr.Line, _ = strconv.Atoi(match[1])
+		lines := strings.Split(content, "\n")
+		if templateErr.Line > 0 && templateErr.Line <= len(lines) {
+			templateErr.Source = strings.TrimRight(lines[templateErr.Line-1], " ")
+		}
+	}
+	return templateErr
+}
+
+func formatRenderTemplate(result RenderTemplateResult) string {
+	var sb strings.Builder
+	for _, templateErr := range result.Errors {
+		if templateErr.Line > 0 {
+			fmt.Fprintf(&sb, "%s error at line %d: %s\n  %d | %s\n", templateErr.Stage, templateErr.Line, templateErr.Message, templateErr.Line, templateErr.Source)
+		} else {
+			fmt.Fprintf(&sb, "%s error: %s\n", templateErr.Stage, templateErr.Message)
+		}
+	}
+	if result.Rendered != "" {
+		if sb.Len() > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(result.Rendered)
+	}
+	return sb.String()
+}
@@ -0,0 +1,216 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// defaultQuotaThreshold is the quota usage in percent from which a namespace lacks capacity
+	defaultQuotaThreshold = 90
+	// defaultMaxPendingPipelineRuns is the number of queued PipelineRuns from which a namespace lacks capacity
+	defaultMaxPendingPipelineRuns = 5
+)
+
+// tenantPermissions are the permissions the server needs in the tenant
+// namespace to snapshot builds and trigger and watch releases
+var tenantPermissions = []struct {
+	verb string
+	gvr  schema.GroupVersionResource
+}{
+	{"create", snapshotGVR},
+	{"create", releaseGVR},
+	{"get", releaseGVR},
+	{"watch", releaseGVR},
+}
+
+// TenantCapacityConfig represents the configuration for the tenant namespace capacity check
+type TenantCapacityConfig struct {
+	Namespace              string
+	QuotaThreshold         float64 // Quota usage in percent from which a resource is reported
+	MaxPendingPipelineRuns int     // Number of queued PipelineRuns from which the namespace is reported
+}
+
+// QuotaUsage represents the usage of a resource limited by a ResourceQuota
+type QuotaUsage struct {
+	Quota    string  `json:"quota"`
+	Resource string  `json:"resource"`
+	Used     string  `json:"used"`
+	Hard     string  `json:"hard"`
+	Percent  float64 `json:"percent"`
+	Exceeded bool    `json:"exceeded" jsonschema:"Whether the usage reaches the threshold"`
+}
+
+// PermissionCheck represents whether the server may perform an action in the tenant namespace
+type PermissionCheck struct {
+	Verb     string `json:"verb"`
+	Resource string `json:"resource"`
+	Allowed  bool   `json:"allowed"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// TenantCapacityResult represents the structured result of verify-tenant-namespace-quota-and-permissions
+type TenantCapacityResult struct {
+	Namespace           string            `json:"namespace"`
+	Ready               bool              `json:"ready" jsonschema:"Whether the namespace has quota headroom, a short PipelineRun queue and the server the permissions it needs"`
+	Quotas              []QuotaUsage      `json:"quotas"`
+	RunningPipelineRuns int               `json:"running_pipeline_runs"`
+	PendingPipelineRuns int               `json:"pending_pipeline_runs" jsonschema:"PipelineRuns not started yet"`
+	Permissions         []PermissionCheck `json:"permissions"`
+	Issues              []string          `json:"issues,omitempty"`
+}
+
+// verifyTenantCapacity checks the ResourceQuotas and PipelineRun queue of the
+// tenant namespace and the permissions of the server in it, reporting what
+// would make heavy builds or releases fail or stall
+func verifyTenantCapacity(ctx context.Context, config TenantCapacityConfig) (TenantCapacityResult, error) {
+	if config.Namespace == "" {
+		config.Namespace = tenantNamespace()
+	}
+	if config.QuotaThreshold <= 0 {
+		config.QuotaThreshold = defaultQuotaThreshold
+	}
+	if config.MaxPendingPipelineRuns <= 0 {
+		config.MaxPendingPipelineRuns = defaultMaxPendingPipelineRuns
+	}
+	result := TenantCapacityResult{Namespace: config.Namespace}
+
+	kc, err := kubeClient(ctx)
+	if err != nil {
+		return result, fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+	dc, err := dynamicClient(ctx)
+	if err != nil {
+		return result, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+
+	quotas, err := kc.CoreV1().ResourceQuotas(config.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		result.Issues = append(result.Issues, fmt.Sprintf("failed to list ResourceQuotas: %v", err))
+	} else {
+		for _, quota := range quotas.Items {
+			names := make([]string, 0, len(quota.Status.Hard))
+			for name := range quota.Status.Hard {
+				names = append(names, string(name))
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				hard := quota.Status.Hard[corev1.ResourceName(name)]
+				used := quota.Status.Used[corev1.ResourceName(name)]
+				usage := QuotaUsage{Quota: quota.Name, Resource: name, Used: used.String(), Hard: hard.String()}
+				if hard.MilliValue() > 0 {
+					usage.Percent = float64(used.MilliValue()) * 100 / float64(hard.MilliValue())
+				}
+				usage.Exceeded = hard.MilliValue() == 0 || usage.Percent >= config.QuotaThreshold
+				if usage.Exceeded {
+					result.Issues = append(result.Issues, fmt.Sprintf("quota %s uses %s of %s %s", quota.Name, usage.Used, usage.Hard, name))
+				}
+				result.Quotas = append(result.Quotas, usage)
+			}
+		}
+	}
+
+	runs, err := dc.Resource(pipelineRunGVR).Namespace(config.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		result.Issues = append(result.Issues, fmt.Sprintf("failed to list PipelineRuns: %v", err))
+	} else {
+		for i := range runs.Items {
+			run := &runs.Items[i]
+			if state, _ := pipelineRunState(run); state != "running" {
+				continue
+			}
+			if pipelineRunPending(run) {
+				result.PendingPipelineRuns++
+			} else {
+				result.RunningPipelineRuns++
+			}
+		}
+		if result.PendingPipelineRuns >= config.MaxPendingPipelineRuns {
+			result.Issues = append(result.Issues, fmt.Sprintf("%d PipelineRuns are waiting to start", result.PendingPipelineRuns))
+		}
+	}
+
+	for _, permission := range tenantPermissions {
+		review, err := kc.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Namespace: config.Namespace,
+					Verb:      permission.verb,
+					Group:     permission.gvr.Group,
+					Resource:  permission.gvr.Resource,
+				},
+			},
+		}, metav1.CreateOptions{})
+		check := PermissionCheck{Verb: permission.verb, Resource: permission.gvr.GroupResource().String()}
+		if err != nil {
+			check.Reason = err.Error()
+		} else {
+			check.Allowed = review.Status.Allowed
+			check.Reason = review.Status.Reason
+		}
+		if !check.Allowed {
+			result.Issues = append(result.Issues, fmt.Sprintf("cannot %s %s", check.Verb, check.Resource))
+		}
+		result.Permissions = append(result.Permissions, check)
+	}
+
+	result.Ready = len(result.Issues) == 0
+	return result, nil
+}
+
+// pipelineRunPending reports whether a PipelineRun is queued: held pending,
+// or not started by the controller yet
+func pipelineRunPending(run *unstructured.Unstructured) bool {
+	if status, _, _ := unstructured.NestedString(run.Object, "spec", "status"); status == "PipelineRunPending" {
+		return true
+	}
+	startTime, _, _ := unstructured.NestedString(run.Object, "status", "startTime")
+	return startTime == ""
+}
+
+func formatTenantCapacity(result TenantCapacityResult) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Tenant namespace %s:\n", result.Namespace)
+
+	if len(result.Quotas) == 0 {
+		sb.WriteString("No resource quotas\n")
+	}
+	for _, usage := range result.Quotas {
+		marker := "✓"
+		if usage.Exceeded {
+			marker = "✗"
+		}
+		fmt.Fprintf(&sb, "%s %s %s: %s of %s (%.0f%%)\n", marker, usage.Quota, usage.Resource, usage.Used, usage.Hard, usage.Percent)
+	}
+	fmt.Fprintf(&sb, "PipelineRuns: %d running, %d pending\n", result.RunningPipelineRuns, result.PendingPipelineRuns)
+
+	for _, check := range result.Permissions {
+		marker := "✓"
+		if !check.Allowed {
+			marker = "✗"
+		}
+		fmt.Fprintf(&sb, "%s %s %s", marker, check.Verb, check.Resource)
+		if !check.Allowed && check.Reason != "" {
+			fmt.Fprintf(&sb, ": %s", check.Reason)
+		}
+		sb.WriteString("\n")
+	}
+
+	if result.Ready {
+		sb.WriteString("\nThe namespace has the capacity and permissions for builds and releases\n")
+	} else {
+		fmt.Fprintf(&sb, "\n%d problems:\n", len(result.Issues))
+		for _, issue := range result.Issues {
+			fmt.Fprintf(&sb, "- %s\n", issue)
+		}
+	}
+	return sb.String()
+}
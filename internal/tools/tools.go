@@ -2,16 +2,48 @@ package tools
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
-	"path/filepath"
+	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/jsonschema"
 	mcp "github.com/modelcontextprotocol/go-sdk/mcp"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"knative.dev/pkg/injection"
 	"time"
 )
 
-func Add(_ context.Context, s *mcp.Server) error {
+func Add(ctx context.Context, s *mcp.Server) error {
+	// Record every tool call in the per-version audit log and changelog, keep
+	// destructive tools within the release window of their version, run
+	// them one at a time per version across sessions and report the work
+	// every call did. Pushes are refused when they contain potential secrets,
+	// unless the call allows them, and tools changing release state are
+	// refused during maintenance, before they are confirmed or started as
+	// jobs. Verbose calls stream the output of their
+	// subprocesses and long tools report their progress. Async calls run all
+	// of the above as background jobs. Clients receive instructions generated
+	// from the registered tools. Arguments given once, such as the version,
+	// are remembered for the later calls of the session. Calls pushing to
+	// many repositories or changing prod only run once their plan is confirmed.
+	// Every call is traced, with its steps as child spans, and keeps a run log
+	// of what it logged and the output of its subprocesses.
+	s.AddReceivingMiddleware(instructionsMiddleware, defaultsMiddleware, maintenanceMiddleware, confirmationMiddleware, asyncMiddleware, tracingMiddleware, runLogMiddleware, auditMiddleware, releaseWindowMiddleware, versionLockMiddleware, secretsOverrideMiddleware, verboseMiddleware, progressMiddleware, usageMiddleware, artifactsMiddleware)
+
+	// Load the configuration file and pick up its changes while the server runs
+	if err := watchServerConfig(ctx); err != nil {
+		return err
+	}
+	// Validate generated manifests against the CRDs installed in the cluster
+	setCRDSchemaClient(ctx)
+	addReleaseActionsResource(s)
+	addReleaseStatusResource(s)
+	addSupportedVersionsResource(s)
+	addReleaseRunbookPrompt(s)
+
 	// Register create-release-branches tool
 	branchTool := &mcp.Tool{
 		Name:        "create-release-branches",
@@ -23,9 +55,14 @@ func Add(_ context.Context, s *mcp.Server) error {
 					Type:        "string",
 					Description: "Minor version number (e.g., '1.19')",
 				},
+				"parallelism": {
+					Type:        "integer",
+					Description: fmt.Sprintf("Number of repositories cloned and pushed at once, from 1 to %d. Defaults to %d", maxBranchParallelism, defaultBranchParallelism),
+				},
 			},
 			Required: []string{"minor_version"},
 		},
+		OutputSchema: outputSchema[BranchResult](),
 	}
 
 	branchHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
@@ -35,18 +72,112 @@ func Add(_ context.Context, s *mcp.Server) error {
 			return nil, fmt.Errorf("minor_version parameter is required")
 		}
 
-		if _, err := createBranch(minorVersion); err != nil {
+		parallelism := defaultBranchParallelism
+		if value, ok := params.Arguments["parallelism"].(float64); ok {
+			if value < 1 || value > maxBranchParallelism {
+				return nil, fmt.Errorf("parallelism must be between 1 and %d", maxBranchParallelism)
+			}
+			parallelism = int(value)
+		}
+
+		branchResult, err := createBranch(ctx, minorVersion, parallelism)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content:           []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to create branches: %v", err)}},
+				StructuredContent: branchResult,
+				IsError:           true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Successfully created release branches for version %s", minorVersion)}},
+			StructuredContent: branchResult,
+		}, nil
+	}
+
+	s.AddTool(requireConfirmation(runAsync(streamCommands(guardReleaseWindow(scanBeforePush(branchTool)))), releaseRepositoriesScope), branchHandler)
+
+	// Register delete-release-branches tool
+	deleteBranchTool := &mcp.Tool{
+		Name:        "delete-release-branches",
+		Description: "Deletes the release branch of a version from every release repository, to undo create-release-branches. Use list-release-branches to see which repositories have it first",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"minor_version": {
+					Type:        "string",
+					Description: "Minor version whose branches to delete (e.g., '1.19' for release-v1.19.x)",
+				},
+			},
+			Required: []string{"minor_version"},
+		},
+		OutputSchema: outputSchema[DeleteBranchesResult](),
+	}
+
+	deleteBranchHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		minorVersion, ok := params.Arguments["minor_version"].(string)
+		if !ok || minorVersion == "" {
+			return nil, fmt.Errorf("minor_version parameter is required")
+		}
+		deleted, err := deleteBranches(ctx, minorVersion)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to delete branches: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+
+		failed := false
+		for _, branch := range deleted.Branches {
+			failed = failed || branch.Status == "failed"
+		}
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatDeleteBranches(deleted)}},
+			StructuredContent: deleted,
+			IsError:           failed,
+		}, nil
+	}
+
+	s.AddTool(requireConfirmation(guardReleaseWindow(deleteBranchTool), releaseRepositoriesScope), deleteBranchHandler)
+
+	// Register list-release-branches tool
+	listBranchTool := &mcp.Tool{
+		Name:        "list-release-branches",
+		Description: "Lists which release repositories have the release branch of a version, with its head commit and divergence from the branch it was cut from",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"minor_version": {
+					Type:        "string",
+					Description: "Minor version whose branches to list (e.g., '1.19' for release-v1.19.x)",
+				},
+			},
+			Required: []string{"minor_version"},
+		},
+		OutputSchema: outputSchema[ListBranchesResult](),
+	}
+
+	listBranchHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		minorVersion, ok := params.Arguments["minor_version"].(string)
+		if !ok || minorVersion == "" {
+			return nil, fmt.Errorf("minor_version parameter is required")
+		}
+
+		branches, err := listBranches(ctx, minorVersion)
+		if err != nil {
 			return &mcp.CallToolResultFor[any]{
-				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to create branches: %v", err)}},
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to list branches: %v", err)}},
+				IsError: true,
 			}, nil
 		}
 
 		return &mcp.CallToolResultFor[any]{
-			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Successfully created release branches for version %s", minorVersion)}},
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatListBranches(branches)}},
+			StructuredContent: branches,
 		}, nil
 	}
 
-	s.AddTool(branchTool, branchHandler)
+	s.AddTool(listBranchTool, listBranchHandler)
 
 	// Register configure-hack-repo tool
 	hackTool := &mcp.Tool{
@@ -69,11 +200,31 @@ func Add(_ context.Context, s *mcp.Server) error {
 						Type:        "string",
 						Description: "Upstream version for each component (e.g., '0.25.x' for chains)",
 					},
-					Description: "Map of component names to their upstream versions",
+					Description: "Map of component names to their upstream versions. Versions not passed are read from config/versions.yaml in the hack repository, and passed versions are recorded there",
+				},
+				"patches": {
+					Type: "object",
+					AdditionalProperties: &jsonschema.Schema{
+						Type: "array",
+						Items: &jsonschema.Schema{
+							Type: "object",
+							Properties: map[string]*jsonschema.Schema{
+								"name":   {Type: "string"},
+								"script": {Type: "string"},
+							},
+							Required: []string{"name"},
+						},
+					},
+					Description: "Map of component names to additional patches for the new branch. The branch gets an explicit patch list including the existing top-level patches",
+				},
+				"incremental": {
+					Type:        "boolean",
+					Description: "Only add the new branch entry where it is missing and leave existing entries untouched. Safe to run repeatedly",
 				},
 			},
 			Required: []string{"minor_version"},
 		},
+		OutputSchema: outputSchema[HackResult](),
 	}
 
 	hackHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
@@ -95,27 +246,67 @@ func Add(_ context.Context, s *mcp.Server) error {
 			}
 		}
 
-		repoPath := filepath.Join(os.TempDir(), fmt.Sprintf("hack-repo-%d", time.Now().Unix()))
+		incremental, _ := params.Arguments["incremental"].(bool)
+
+		// Extract additional patches per component
+		patches := make(map[string][]Patch)
+		if patchArgs, ok := params.Arguments["patches"].(map[string]interface{}); ok {
+			for component, list := range patchArgs {
+				items, _ := list.([]interface{})
+				for _, item := range items {
+					if p, ok := item.(map[string]interface{}); ok {
+						name, _ := p["name"].(string)
+						script, _ := p["script"].(string)
+						if name != "" {
+							patches[component] = append(patches[component], Patch{Name: name, Script: script})
+						}
+					}
+				}
+			}
+		}
+
+		repoPath, err := runDir(session, "hack-repo")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create working directory: %w", err)
+		}
+		defer os.RemoveAll(repoPath)
 
 		config := HackConfig{
 			MinorVersion:   minorVersion,
 			OCPVersion:     ocpVersion,
 			RepoPath:       repoPath,
 			UpstreamConfig: upstreamVersions,
+			Incremental:    incremental,
+			Patches:        patches,
 		}
 
-		if err := ConfigureHackRepo(config); err != nil {
+		hackResult, err := ConfigureHackRepo(ctx, config)
+		if err != nil {
 			return &mcp.CallToolResultFor[any]{
 				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to configure hack repository: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+
+		var unchangedNote string
+		if len(hackResult.UnchangedRepos) > 0 {
+			unchangedNote = fmt.Sprintf("\nAlready configured (no-op): %s", strings.Join(hackResult.UnchangedRepos, ", "))
+		}
+
+		if hackResult.PRURL == "" {
+			return &mcp.CallToolResultFor[any]{
+				Content:           []mcp.Content{&mcp.TextContent{Text: "Hack repository is already configured, no pull request needed" + unchangedNote}},
+				StructuredContent: hackResult,
 			}, nil
 		}
 
 		return &mcp.CallToolResultFor[any]{
-			Content: []mcp.Content{&mcp.TextContent{Text: "Successfully configured hack repository and created pull request"}},
+			Content:           []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Successfully configured hack repository and created pull request: %s%s", hackResult.PRURL, unchangedNote)}},
+			StructuredContent: hackResult,
 		}, nil
 	}
 
-	s.AddTool(hackTool, hackHandler)
+	s.AddTool(streamCommands(guardReleaseWindow(scanBeforePush(hackTool))), hackHandler)
 
 	// Register create-release-plans tool
 	releasePlanTool := &mcp.Tool{
@@ -139,9 +330,61 @@ func Add(_ context.Context, s *mcp.Server) error {
 					},
 					Description: "List of OCP versions (e.g., ['4-15', '4-16']). Defaults to ['4-15', '4-16', '4-17', '4-18', '4-19']",
 				},
+				"advisory_type": {
+					Type:        "string",
+					Enum:        []any{"RHEA", "RHBA", "RHSA"},
+					Description: "Advisory type of the release notes. Defaults to RHEA for minor releases and RHBA for patch releases. RHSA requires cves",
+				},
+				"cves": {
+					Type: "array",
+					Items: &jsonschema.Schema{
+						Type: "object",
+						Properties: map[string]*jsonschema.Schema{
+							"key":       {Type: "string", Description: "CVE identifier (e.g., 'CVE-2025-1234')"},
+							"component": {Type: "string", Description: "Konflux component the CVE is fixed in (e.g., 'tektoncd-core-1.21-controller')"},
+						},
+						Required: []string{"key", "component"},
+					},
+					Description: "CVEs fixed by the release",
+				},
+				"issues": {
+					Type: "array",
+					Items: &jsonschema.Schema{
+						Type: "string",
+					},
+					Description: "JIRA issues fixed by the release (e.g., ['SRVKP-1234'])",
+				},
+				"mr_labels": {
+					Type: "array",
+					Items: &jsonschema.Schema{
+						Type: "string",
+					},
+					Description: "Labels of the merge request opened against konflux-release-data",
+				},
+				"mr_reviewers": {
+					Type: "array",
+					Items: &jsonschema.Schema{
+						Type: "string",
+					},
+					Description: "GitLab usernames requested to review the merge request",
+				},
+				"pin_catalog_revision": {
+					Type:        "boolean",
+					Description: "Resolve the release-service-catalog '" + releaseServiceCatalogRevision + "' branch to its current commit SHA and embed the SHA in the RPAs",
+				},
+				"auto_release": {
+					Type:        "boolean",
+					Description: "Release every snapshot passing its tests without a Release being created. Defaults to false",
+				},
+				"rp_labels": {
+					Type:                 "object",
+					AdditionalProperties: &jsonschema.Schema{Type: "string"},
+					Description:          "Additional labels of the ReleasePlans",
+				},
 			},
 			Required: []string{"minor_version"},
 		},
+		OutputSchema: outputSchema[PushedBranchResult](),
 	}
 
 	releasePlanHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
@@ -164,48 +407,3902 @@ func Add(_ context.Context, s *mcp.Server) error {
 			}
 		}
 		if len(ocpVersions) == 0 {
-			ocpVersions = []string{"4-15", "4-16", "4-17", "4-18", "4-19"}
+			ocpVersions = configuredOCPVersions()
 		}
 
-		// Define component configurations
-		components := map[string][]ComponentConfig{
-			"cli": {
-				{Name: "tkn", Repository: "pipelines-cli-tkn-rhel9"},
-			},
-			"core": {
-				{Name: "controller", Repository: "pipelines-core-controller-rhel9"},
-				{Name: "webhook", Repository: "pipelines-core-webhook-rhel9"},
-			},
-			"operator": {
-				{Name: "operator", Repository: "pipelines-rhel9-operator"},
-				{Name: "proxy", Repository: "pipelines-operator-proxy-rhel9"},
-				{Name: "webhook", Repository: "pipelines-operator-webhook-rhel9"},
-			},
-			"fbc": {}, // FBC has special handling
+		repoPath, err := runDir(session, "konflux-release-data")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create working directory: %w", err)
 		}
+		defer os.RemoveAll(repoPath)
 
 		config := RPAConfig{
 			MinorVersion: minorVersion,
 			PatchVersion: patchVersion,
-			RepoPath:     filepath.Join(os.TempDir(), "konflux-release-data"),
-			Components:   components,
+			RepoPath:     repoPath,
+			Components:   productComponents(),
 			Environments: []string{"stage", "prod"},
 			OCPVersions:  ocpVersions,
 		}
+		config.AdvisoryType, _ = params.Arguments["advisory_type"].(string)
+		if cves, ok := params.Arguments["cves"].([]interface{}); ok {
+			for _, item := range cves {
+				if c, ok := item.(map[string]interface{}); ok {
+					key, _ := c["key"].(string)
+					component, _ := c["component"].(string)
+					config.CVEs = append(config.CVEs, CVE{Key: key, Component: component})
+				}
+			}
+		}
+		if issues, ok := params.Arguments["issues"].([]interface{}); ok {
+			for _, v := range issues {
+				if strVal, ok := v.(string); ok {
+					config.Issues = append(config.Issues, strVal)
+				}
+			}
+		}
+
+		for key, target := range map[string]*[]string{"mr_labels": &config.MRLabels, "mr_reviewers": &config.MRReviewers} {
+			if values, ok := params.Arguments[key].([]interface{}); ok {
+				for _, v := range values {
+					if strVal, ok := v.(string); ok && strVal != "" {
+						*target = append(*target, strVal)
+					}
+				}
+			}
+		}
 
-		if err := createReleasePlans(config); err != nil {
+		config.PinCatalogRevision, _ = params.Arguments["pin_catalog_revision"].(bool)
+		config.AutoRelease, _ = params.Arguments["auto_release"].(bool)
+		if labels, ok := params.Arguments["rp_labels"].(map[string]interface{}); ok {
+			config.RPLabels = make(map[string]string)
+			for k, v := range labels {
+				if strVal, ok := v.(string); ok {
+					config.RPLabels[k] = strVal
+				}
+			}
+		}
+
+		pushed, err := createReleasePlans(ctx, config)
+		if err != nil {
 			return &mcp.CallToolResultFor[any]{
 				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to create release plans: %v", err)}},
+				IsError: true,
 			}, nil
 		}
 
 		return &mcp.CallToolResultFor[any]{
-			Content: []mcp.Content{&mcp.TextContent{Text: "Successfully created ReleasePlan and ReleasePlanAdmission files"}},
+			Content:           []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Successfully created ReleasePlan and ReleasePlanAdmission files on branch %s, merge request: %s", pushed.BranchName, pushed.MergeRequestURL)}},
+			StructuredContent: pushed,
 		}, nil
 	}
 
-	s.AddTool(releasePlanTool, releasePlanHandler)
-	return nil
+	s.AddTool(requireConfirmation(runAsync(streamCommands(guardReleaseWindow(scanBeforePush(releasePlanTool)))), prodReleasePlansScope("konflux-release-data")), releasePlanHandler)
+
+	// Register rollback-release-plans tool
+	rollbackTool := &mcp.Tool{
+		Name:        "rollback-release-plans",
+		Description: "Reverts a merged ReleasePlan/ReleasePlanAdmission change in konflux-release-data, pushes the revert to a branch and opens a merge request for it",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"minor_version": {
+					Type:        "string",
+					Description: "Minor version number the release plans were created for (e.g., '1.21')",
+				},
+				"commit_sha": {
+					Type:        "string",
+					Description: "SHA of the merged commit (or merge commit) to revert",
+				},
+			},
+			Required: []string{"minor_version", "commit_sha"},
+		},
+		OutputSchema: outputSchema[PushedBranchResult](),
+	}
+
+	rollbackHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		// Extract parameters
+		minorVersion, ok := params.Arguments["minor_version"].(string)
+		if !ok || minorVersion == "" {
+			return nil, fmt.Errorf("minor_version parameter is required")
+		}
+
+		commitSHA, ok := params.Arguments["commit_sha"].(string)
+		if !ok || commitSHA == "" {
+			return nil, fmt.Errorf("commit_sha parameter is required")
+		}
+
+		repoPath, err := runDir(session, "konflux-release-data-revert")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create working directory: %w", err)
+		}
+		defer os.RemoveAll(repoPath)
+
+		config := RollbackConfig{
+			MinorVersion: minorVersion,
+			CommitSHA:    commitSHA,
+			RepoPath:     repoPath,
+		}
+
+		pushed, err := rollbackReleasePlans(ctx, config)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content:           []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to roll back release plans: %v", err)}},
+				StructuredContent: pushed,
+				IsError:           true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Successfully pushed revert of %s for release plans v%s to branch %s, merge request: %s", commitSHA, minorVersion, pushed.BranchName, pushed.MergeRequestURL)}},
+			StructuredContent: pushed,
+		}, nil
+	}
+
+	s.AddTool(streamCommands(guardReleaseWindow(scanBeforePush(rollbackTool))), rollbackHandler)
+
+	// Register verify-rpa-references tool
+	verifyRefsTool := &mcp.Tool{
+		Name:        "verify-rpa-references",
+		Description: "Verifies that the policies, service accounts and credentials referenced by the ReleasePlanAdmissions of the product in konflux-release-data exist on the cluster",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"minor_version": {
+					Type:        "string",
+					Description: "Only verify the ReleasePlanAdmissions of this minor version (e.g., '1.21'). Defaults to all versions",
+				},
+				"environments": {
+					Type: "array",
+					Items: &jsonschema.Schema{
+						Type: "string",
+					},
+					Description: "Environments to verify (e.g., ['stage', 'prod']). Defaults to ['stage', 'prod']",
+				},
+			},
+		},
+		OutputSchema: outputSchema[RPAReferencesResult](),
+	}
+
+	verifyRefsHandler := func(reqCtx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		repoPath, err := runDir(session, "konflux-release-data-rpa-references")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create working directory: %w", err)
+		}
+		defer os.RemoveAll(repoPath)
+
+		var environments []string
+		if envs, ok := params.Arguments["environments"].([]interface{}); ok {
+			for _, v := range envs {
+				if strVal, ok := v.(string); ok {
+					environments = append(environments, strVal)
+				}
+			}
+		}
+		if len(environments) == 0 {
+			environments = []string{"stage", "prod"}
+		}
+
+		config := RPAReferencesConfig{RepoPath: repoPath, Environments: environments}
+		config.MinorVersion, _ = params.Arguments["minor_version"].(string)
+
+		refs, err := verifyRPAReferences(clusterContext(reqCtx, ctx), config)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to verify RPA references: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+
+		if len(refs) == 0 {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("No ReleasePlanAdmission found in %s for %s", productRPADir(), strings.Join(environments, ", "))}},
+				IsError: true,
+			}, nil
+		}
+
+		report, allFound := formatRPAReferences(refs)
+		refsResult := RPAReferencesResult{AllFound: allFound, References: refs}
+		if !allFound {
+			return &mcp.CallToolResultFor[any]{
+				Content:           []mcp.Content{&mcp.TextContent{Text: "Some RPA references are missing:\n" + report}},
+				StructuredContent: refsResult,
+				IsError:           true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: "All RPA references exist:\n" + report}},
+			StructuredContent: refsResult,
+		}, nil
+	}
+
+	s.AddTool(streamCommands(verifyRefsTool), verifyRefsHandler)
+
+	// Register check-release-notes-links tool
+	docsLinksTool := &mcp.Tool{
+		Name:        "check-release-notes-links",
+		Description: "Renders the release plans of a release and checks every URL in their release notes, and the versioned documentation of the release, responds with a 200, flagging broken links before they reach an advisory",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"minor_version": {
+					Type:        "string",
+					Description: "Minor version number (e.g., '1.21')",
+				},
+				"patch_version": {
+					Type:        "string",
+					Description: "Optional patch version number",
+				},
+				"versioned_docs": {
+					Type:        "boolean",
+					Description: "Also check the documentation of the minor version is published. Defaults to true",
+				},
+			},
+			Required: []string{"minor_version"},
+		},
+		OutputSchema: outputSchema[DocsLinksResult](),
+	}
+
+	docsLinksHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		minorVersion, ok := params.Arguments["minor_version"].(string)
+		if !ok || minorVersion == "" {
+			return nil, fmt.Errorf("minor_version parameter is required")
+		}
+
+		config := RPAConfig{
+			MinorVersion: minorVersion,
+			Components:   productComponents(),
+			Environments: []string{"stage", "prod"},
+			OCPVersions:  configuredOCPVersions(),
+		}
+		config.PatchVersion, _ = params.Arguments["patch_version"].(string)
+		versionedDocs := true
+		if v, ok := params.Arguments["versioned_docs"].(bool); ok {
+			versionedDocs = v
+		}
+
+		result, err := checkReleaseNotesLinks(ctx, config, versionedDocs)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to check release notes links: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatDocsLinks(result)}},
+			StructuredContent: result,
+			IsError:           result.Broken > 0,
+		}, nil
+	}
+
+	s.AddTool(docsLinksTool, docsLinksHandler)
+
+	// Register apply-release-plans tool
+	applyTool := &mcp.Tool{
+		Name:        "apply-release-plans",
+		Description: "Creates or updates the generated ReleasePlanAdmission and ReleasePlan objects of a release directly on the Konflux cluster with server-side apply, optionally as a server-side dry run",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"minor_version": {
+					Type:        "string",
+					Description: "Minor version number (e.g., '1.21')",
+				},
+				"patch_version": {
+					Type:        "string",
+					Description: "Optional patch version number",
+				},
+				"ocp_versions": {
+					Type: "array",
+					Items: &jsonschema.Schema{
+						Type: "string",
+					},
+					Description: "List of OCP versions of the FBC RPAs (e.g., ['4-15', '4-16']). Defaults to the configured OCP versions",
+				},
+				"environments": {
+					Type: "array",
+					Items: &jsonschema.Schema{
+						Type: "string",
+					},
+					Description: "Environments to apply (e.g., ['stage', 'prod']). Defaults to ['stage', 'prod']",
+				},
+				"dry_run": {
+					Type:        "boolean",
+					Description: "Have the API server validate the objects without persisting them",
+				},
+			},
+			Required: []string{"minor_version"},
+		},
+		OutputSchema: outputSchema[ApplyResult](),
+	}
+
+	applyHandler := func(reqCtx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		minorVersion, ok := params.Arguments["minor_version"].(string)
+		if !ok || minorVersion == "" {
+			return nil, fmt.Errorf("minor_version parameter is required")
+		}
+
+		config := RPAConfig{
+			MinorVersion: minorVersion,
+			Components:   productComponents(),
+			Environments: []string{"stage", "prod"},
+		}
+		config.PatchVersion, _ = params.Arguments["patch_version"].(string)
+		if versions, ok := params.Arguments["ocp_versions"].([]interface{}); ok {
+			for _, v := range versions {
+				if strVal, ok := v.(string); ok {
+					config.OCPVersions = append(config.OCPVersions, strVal)
+				}
+			}
+		}
+		if len(config.OCPVersions) == 0 {
+			config.OCPVersions = configuredOCPVersions()
+		}
+		if envs, ok := params.Arguments["environments"].([]interface{}); ok && len(envs) > 0 {
+			config.Environments = nil
+			for _, v := range envs {
+				if strVal, ok := v.(string); ok {
+					config.Environments = append(config.Environments, strVal)
+				}
+			}
+		}
+		dryRun, _ := params.Arguments["dry_run"].(bool)
+
+		applied, err := applyReleasePlans(clusterContext(reqCtx, ctx), config, dryRun)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to apply release plans: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatApplyResult(applied)}},
+			StructuredContent: applied,
+			IsError:           applied.Failed > 0,
+		}, nil
+	}
+
+	s.AddTool(requireConfirmation(guardReleaseWindow(applyTool), prodReleasePlansScope("Konflux cluster")), applyHandler)
+
+	// Register toggle-auto-release tool
+	autoReleaseTool := &mcp.Tool{
+		Name:        "toggle-auto-release",
+		Description: "Sets the auto-release label of the existing ReleasePlans of a version, through a konflux-release-data merge request or by patching them on the cluster",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"minor_version": {
+					Type:        "string",
+					Description: "Minor version number (e.g., '1.21')",
+				},
+				"enabled": {
+					Type:        "boolean",
+					Description: "Whether snapshots passing their tests are released automatically",
+				},
+				"components": {
+					Type: "array",
+					Items: &jsonschema.Schema{
+						Type: "string",
+					},
+					Description: "Applications whose ReleasePlans are changed (e.g., ['core', 'fbc-4-18']). Defaults to all",
+				},
+				"environments": {
+					Type: "array",
+					Items: &jsonschema.Schema{
+						Type: "string",
+					},
+					Description: "Environments whose ReleasePlans are changed (e.g., ['stage']). Defaults to all",
+				},
+				"mode": {
+					Type:        "string",
+					Enum:        []any{"merge-request", "cluster"},
+					Description: "Open a konflux-release-data merge request, or patch the ReleasePlans on the cluster until the next sync. Defaults to merge-request",
+				},
+				"dry_run": {
+					Type:        "boolean",
+					Description: "Only report the ReleasePlans that would change",
+				},
+			},
+			Required: []string{"minor_version", "enabled"},
+		},
+		OutputSchema: outputSchema[AutoReleaseResult](),
+	}
+
+	autoReleaseHandler := func(reqCtx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		minorVersion, ok := params.Arguments["minor_version"].(string)
+		if !ok || minorVersion == "" {
+			return nil, fmt.Errorf("minor_version parameter is required")
+		}
+		enabled, ok := params.Arguments["enabled"].(bool)
+		if !ok {
+			return nil, fmt.Errorf("enabled parameter is required")
+		}
+
+		config := AutoReleaseConfig{MinorVersion: minorVersion, Enabled: enabled}
+		for key, target := range map[string]*[]string{"components": &config.Components, "environments": &config.Environments} {
+			if values, ok := params.Arguments[key].([]interface{}); ok {
+				for _, v := range values {
+					if strVal, ok := v.(string); ok && strVal != "" {
+						*target = append(*target, strVal)
+					}
+				}
+			}
+		}
+		mode, _ := params.Arguments["mode"].(string)
+		config.Cluster = mode == "cluster"
+		config.DryRun, _ = params.Arguments["dry_run"].(bool)
+		if !config.Cluster {
+			repoPath, err := runDir(session, "konflux-release-data-auto-release")
+			if err != nil {
+				return nil, fmt.Errorf("failed to create working directory: %w", err)
+			}
+			defer os.RemoveAll(repoPath)
+			config.RepoPath = repoPath
+		}
+
+		result, err := toggleAutoRelease(clusterContext(reqCtx, ctx), config)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to toggle auto-release: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatAutoRelease(result)}},
+			StructuredContent: result,
+		}, nil
+	}
+
+	s.AddTool(requireConfirmation(streamCommands(guardReleaseWindow(scanBeforePush(autoReleaseTool))), prodReleasePlansScope("auto-release label")), autoReleaseHandler)
+
+	// Register diff-live-cluster-rpa-vs-git-before-release tool
+	liveDriftTool := &mcp.Tool{
+		Name:        "diff-live-cluster-rpa-vs-git-before-release",
+		Description: "Compares, field by field, the ReleasePlan and its ReleasePlanAdmission on the cluster with their version merged into konflux-release-data, reporting whether ArgoCD has not synced them or they were patched manually. trigger-release runs the same check and refuses to release on differences",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"release_plan": {
+					Type:        "string",
+					Description: "ReleasePlan the release goes through (e.g., 'openshift-pipelines-core-1.21-stage-release-as-op')",
+				},
+				"namespace": {
+					Type:        "string",
+					Description: "Tenant namespace of the ReleasePlan. Defaults to the tenant of the product",
+				},
+				"allow_drift": {
+					Type:        "boolean",
+					Description: "Let the release proceed even though the cluster differs from git, e.g. when a hotfix was applied on purpose. Defaults to false",
+				},
+			},
+			Required: []string{"release_plan"},
+		},
+		OutputSchema: outputSchema[LiveDriftResult](),
+	}
+
+	liveDriftHandler := func(reqCtx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		config := LiveDriftConfig{}
+		config.ReleasePlan, _ = params.Arguments["release_plan"].(string)
+		if config.ReleasePlan == "" {
+			return nil, fmt.Errorf("release_plan parameter is required")
+		}
+		config.Namespace, _ = params.Arguments["namespace"].(string)
+		config.AllowDrift, _ = params.Arguments["allow_drift"].(bool)
+		repoPath, err := runDir(session, "konflux-release-data-live-drift")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create working directory: %w", err)
+		}
+		defer os.RemoveAll(repoPath)
+		config.RepoPath = repoPath
+
+		drift, err := diffLiveReleaseResources(clusterContext(reqCtx, ctx), config)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to compare the live release resources with git: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatLiveDrift(drift)}},
+			StructuredContent: drift,
+			IsError:           !drift.Proceed,
+		}, nil
+	}
+
+	s.AddTool(streamCommands(liveDriftTool), liveDriftHandler)
+
+	// Register trigger-release tool
+	triggerReleaseTool := &mcp.Tool{
+		Name:        "trigger-release",
+		Description: "Creates a Konflux Release of a snapshot through a ReleasePlan and watches its status conditions, reporting their changes as progress, until the release succeeds or fails. Refuses to release when the ReleasePlan or its ReleasePlanAdmission on the cluster differs from konflux-release-data, unless allow_drift is set",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"snapshot": {
+					Type:        "string",
+					Description: "Snapshot to release (e.g., 'openshift-pipelines-core-1-21-x2k9f')",
+				},
+				"release_plan": {
+					Type:        "string",
+					Description: "ReleasePlan to release through (e.g., 'openshift-pipelines-core-1.21-stage-release-as-op')",
+				},
+				"namespace": {
+					Type:        "string",
+					Description: "Tenant namespace of the snapshot and ReleasePlan. Defaults to the tenant of the product",
+				},
+				"timeout_minutes": {
+					Type:        "number",
+					Description: "How long to watch the release before giving up. Defaults to 120",
+				},
+				"allow_drift": {
+					Type:        "boolean",
+					Description: "Release even though the ReleasePlan or ReleasePlanAdmission on the cluster differs from konflux-release-data, e.g. when a hotfix was applied on purpose. Defaults to false",
+				},
+			},
+			Required: []string{"snapshot", "release_plan"},
+		},
+		OutputSchema: outputSchema[TriggerReleaseResult](),
+	}
+
+	triggerReleaseHandler := func(reqCtx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		config := TriggerReleaseConfig{}
+		config.Snapshot, _ = params.Arguments["snapshot"].(string)
+		if config.Snapshot == "" {
+			return nil, fmt.Errorf("snapshot parameter is required")
+		}
+		config.ReleasePlan, _ = params.Arguments["release_plan"].(string)
+		if config.ReleasePlan == "" {
+			return nil, fmt.Errorf("release_plan parameter is required")
+		}
+		config.Namespace, _ = params.Arguments["namespace"].(string)
+		if minutes, ok := params.Arguments["timeout_minutes"].(float64); ok && minutes > 0 {
+			config.Timeout = time.Duration(minutes * float64(time.Minute))
+		}
+		config.AllowDrift, _ = params.Arguments["allow_drift"].(bool)
+		repoPath, err := runDir(session, "konflux-release-data-trigger-release")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create working directory: %w", err)
+		}
+		defer os.RemoveAll(repoPath)
+		config.RepoPath = repoPath
+
+		release, err := triggerRelease(clusterContext(reqCtx, ctx), config)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to trigger release: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatTriggerRelease(release)}},
+			StructuredContent: release,
+			IsError:           release.Outcome != "succeeded",
+		}, nil
+	}
+
+	s.AddTool(requireConfirmation(runAsync(streamCommands(guardReleaseWindow(triggerReleaseTool))), releaseScope), triggerReleaseHandler)
+
+	// Register list-snapshots tool
+	snapshotsTool := &mcp.Tool{
+		Name:        "list-snapshots",
+		Description: "Lists the most recent Konflux Snapshots of an application, newest first, with the image digests and commits of their components and the outcome of their integration tests, to pick the snapshot to release",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"application": {
+					Type:        "string",
+					Description: "Konflux application of the snapshots (e.g., 'openshift-pipelines-operator-1-19')",
+				},
+				"namespace": {
+					Type:        "string",
+					Description: "Tenant namespace of the application. Defaults to the tenant of the product",
+				},
+				"limit": {
+					Type:        "number",
+					Description: "Number of snapshots to return. Defaults to 10",
+				},
+			},
+			Required: []string{"application"},
+		},
+		OutputSchema: outputSchema[ListSnapshotsResult](),
+	}
+
+	snapshotsHandler := func(reqCtx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		application, ok := params.Arguments["application"].(string)
+		if !ok || application == "" {
+			return nil, fmt.Errorf("application parameter is required")
+		}
+		namespace, _ := params.Arguments["namespace"].(string)
+		limit, _ := params.Arguments["limit"].(float64)
+
+		snapshots, err := listSnapshots(clusterContext(reqCtx, ctx), application, namespace, int(limit))
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to list snapshots: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatSnapshots(snapshots)}},
+			StructuredContent: snapshots,
+		}, nil
+	}
+
+	s.AddTool(snapshotsTool, snapshotsHandler)
+
+	// Register check-build-status tool
+	buildStatusTool := &mcp.Tool{
+		Name:        "check-build-status",
+		Description: "Summarizes which Konflux components of a version have a green, failed or still running latest build on the cluster, before a release is attempted",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"minor_version": {
+					Type:        "string",
+					Description: "Minor version number (e.g., '1.21')",
+				},
+				"application": {
+					Type:        "string",
+					Description: "Only check this Konflux application. Defaults to the cli, core and operator applications of the version",
+				},
+				"namespace": {
+					Type:        "string",
+					Description: "Tenant namespace of the applications. Defaults to the tenant of the product",
+				},
+			},
+			Required: []string{"minor_version"},
+		},
+		OutputSchema: outputSchema[BuildStatusResult](),
+	}
+
+	buildStatusHandler := func(reqCtx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		minorVersion, ok := params.Arguments["minor_version"].(string)
+		if !ok || minorVersion == "" {
+			return nil, fmt.Errorf("minor_version parameter is required")
+		}
+		namespace, _ := params.Arguments["namespace"].(string)
+		applications := releaseApplications(minorVersion)
+		if application, ok := params.Arguments["application"].(string); ok && application != "" {
+			applications = []string{application}
+		}
+
+		builds, err := checkBuildStatus(clusterContext(reqCtx, ctx), minorVersion, namespace, applications)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to check build status: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatBuildStatus(builds)}},
+			StructuredContent: builds,
+		}, nil
+	}
+
+	s.AddTool(buildStatusTool, buildStatusHandler)
+
+	// Register verify-tenant-namespace-quota-and-permissions tool
+	tenantCapacityTool := &mcp.Tool{
+		Name:        "verify-tenant-namespace-quota-and-permissions",
+		Description: "Checks the resource quotas and pending PipelineRuns of the tenant namespace and the server's permissions to create Snapshots and Releases, before heavy FBC builds or a release are triggered",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"namespace": {
+					Type:        "string",
+					Description: "Tenant namespace to check. Defaults to the tenant of the product",
+				},
+				"quota_threshold_percent": {
+					Type:        "number",
+					Description: fmt.Sprintf("Quota usage in percent from which a resource lacks headroom. Defaults to %d", defaultQuotaThreshold),
+				},
+				"max_pending_pipeline_runs": {
+					Type:        "number",
+					Description: fmt.Sprintf("Number of PipelineRuns waiting to start from which the namespace is reported congested. Defaults to %d", defaultMaxPendingPipelineRuns),
+				},
+			},
+		},
+		OutputSchema: outputSchema[TenantCapacityResult](),
+	}
+
+	tenantCapacityHandler := func(reqCtx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		config := TenantCapacityConfig{}
+		config.Namespace, _ = params.Arguments["namespace"].(string)
+		config.QuotaThreshold, _ = params.Arguments["quota_threshold_percent"].(float64)
+		if maxPending, ok := params.Arguments["max_pending_pipeline_runs"].(float64); ok {
+			config.MaxPendingPipelineRuns = int(maxPending)
+		}
+
+		result, err := verifyTenantCapacity(clusterContext(reqCtx, ctx), config)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to verify tenant namespace: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatTenantCapacity(result)}},
+			StructuredContent: result,
+		}, nil
+	}
+
+	s.AddTool(tenantCapacityTool, tenantCapacityHandler)
+
+	// Register generate-eol-announcement tool
+	eolTool := &mcp.Tool{
+		Name:        "generate-eol-announcement",
+		Description: "Computes end-of-life dates for released minor versions from the support policy and drafts the end of support announcement",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"ga_dates": {
+					Type: "object",
+					AdditionalProperties: &jsonschema.Schema{
+						Type:        "string",
+						Description: "GA date in YYYY-MM-DD format",
+					},
+					Description: "Map of minor versions to their GA dates (e.g., {'1.18': '2025-03-10'})",
+				},
+				"support_months": {
+					Type:        "integer",
+					Description: "Number of months a minor version is supported after GA. Defaults to 12",
+				},
+				"as_of": {
+					Type:        "string",
+					Description: "Date to compute support status for, in YYYY-MM-DD format. Defaults to today",
+				},
+			},
+			Required: []string{"ga_dates"},
+		},
+		OutputSchema: outputSchema[EOLResult](),
+	}
+
+	eolHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		gaDates := make(map[string]string)
+		if dates, ok := params.Arguments["ga_dates"].(map[string]interface{}); ok {
+			for k, v := range dates {
+				if strVal, ok := v.(string); ok {
+					gaDates[k] = strVal
+				}
+			}
+		}
+		if len(gaDates) == 0 {
+			return nil, fmt.Errorf("ga_dates parameter is required")
+		}
+
+		config := EOLConfig{
+			GADates: gaDates,
+			AsOf:    time.Now(),
+		}
+		if months, ok := params.Arguments["support_months"].(float64); ok {
+			config.SupportMonths = int(months)
+		}
+		if asOf, ok := params.Arguments["as_of"].(string); ok && asOf != "" {
+			t, err := time.Parse("2006-01-02", asOf)
+			if err != nil {
+				return nil, fmt.Errorf("invalid as_of date %q: %w", asOf, err)
+			}
+			config.AsOf = t
+		}
+
+		entries, err := computeEOL(config)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to compute EOL dates: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatEOLAnnouncement(entries)}},
+			StructuredContent: EOLResult{Versions: entries},
+		}, nil
+	}
+
+	s.AddTool(eolTool, eolHandler)
+
+	// Register list-supported-versions tool
+	supportedVersionsTool := &mcp.Tool{
+		Name:        "list-supported-versions",
+		Description: "Lists the currently supported OpenShift Pipelines versions with the OCP versions they are released on, their GA and EOL dates and latest z-stream, from konflux-release-data, the release calendar and the operator tags",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"include_end_of_life": {
+					Type:        "boolean",
+					Description: "Also list the versions past their end of support",
+				},
+			},
+		},
+		OutputSchema: outputSchema[SupportedVersionsResult](),
+	}
+
+	supportedVersionsHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		includeEOL, _ := params.Arguments["include_end_of_life"].(bool)
+
+		repoPath, err := runDir(session, "konflux-release-data-supported-versions")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create working directory: %w", err)
+		}
+		defer os.RemoveAll(repoPath)
+
+		versions, err := listSupportedVersions(ctx, repoPath, includeEOL, time.Now())
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to list supported versions: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatSupportedVersions(versions)}},
+			StructuredContent: versions,
+		}, nil
+	}
+
+	s.AddTool(streamCommands(supportedVersionsTool), supportedVersionsHandler)
+
+	// Register lookup-component-owner tool
+	ownerTool := &mcp.Tool{
+		Name:        "lookup-component-owner",
+		Description: "Looks up the owning team, code owners and escalation contacts of a component from the ownership manifest",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"component": {
+					Type:        "string",
+					Description: "Component name (e.g., 'tektoncd-chains'). Lists all components when omitted",
+				},
+				"manifest_path": {
+					Type:        "string",
+					Description: "Path to the ownership manifest, inside the working directory of the server. Defaults to $RELEASE_MCP_OWNERSHIP_FILE",
+				},
+			},
+		},
+		OutputSchema: outputSchema[OwnershipResult](),
+	}
+
+	ownerHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		component, _ := params.Arguments["component"].(string)
+		manifestPath, _ := params.Arguments["manifest_path"].(string)
+
+		manifest, err := loadOwnershipManifest(manifestPath)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to load ownership manifest: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+
+		owners, err := lookupOwners(manifest, component)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to look up component owner: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatOwners(owners)}},
+			StructuredContent: OwnershipResult{Components: owners},
+		}, nil
+	}
+
+	s.AddTool(ownerTool, ownerHandler)
+
+	// Register find-release-blocking-issues tool
+	blockersTool := &mcp.Tool{
+		Name:        "find-release-blocking-issues",
+		Description: "Searches GitHub orgs and JIRA projects for open issues labeled release-blocker targeting a version",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"version": {
+					Type:        "string",
+					Description: "Version the release blockers target (e.g., '1.21')",
+				},
+				"github_orgs": {
+					Type: "array",
+					Items: &jsonschema.Schema{
+						Type: "string",
+					},
+					Description: "GitHub organizations to search. Defaults to the organization of the product",
+				},
+				"jira_projects": {
+					Type: "array",
+					Items: &jsonschema.Schema{
+						Type: "string",
+					},
+					Description: "JIRA projects to search. Defaults to the JIRA project of the product",
+				},
+				"jira_fix_version": {
+					Type:        "string",
+					Description: "JIRA fixVersion to match. Defaults to the version",
+				},
+			},
+			Required: []string{"version"},
+		},
+		OutputSchema: outputSchema[BlockingIssuesResult](),
+	}
+
+	blockersHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		version, ok := params.Arguments["version"].(string)
+		if !ok || version == "" {
+			return nil, fmt.Errorf("version parameter is required")
+		}
+
+		config := BlockingIssuesConfig{
+			Version:      version,
+			GitHubOrgs:   []string{product().Organization},
+			JiraProjects: productJiraProjects(),
+		}
+		config.JiraFixVersion, _ = params.Arguments["jira_fix_version"].(string)
+		if orgs, ok := params.Arguments["github_orgs"].([]interface{}); ok {
+			config.GitHubOrgs = nil
+			for _, v := range orgs {
+				if strVal, ok := v.(string); ok {
+					config.GitHubOrgs = append(config.GitHubOrgs, strVal)
+				}
+			}
+		}
+		if projects, ok := params.Arguments["jira_projects"].([]interface{}); ok {
+			config.JiraProjects = nil
+			for _, v := range projects {
+				if strVal, ok := v.(string); ok {
+					config.JiraProjects = append(config.JiraProjects, strVal)
+				}
+			}
+		}
+
+		issues, err := findBlockingIssues(ctx, config)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to find release-blocking issues: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatBlockingIssues(version, issues)}},
+			StructuredContent: BlockingIssuesResult{Version: version, Blocking: len(issues) > 0, Issues: issues},
+		}, nil
+	}
+
+	s.AddTool(blockersTool, blockersHandler)
+
+	// Register configure-hack-next tool
+	hackNextTool := &mcp.Tool{
+		Name:        "configure-hack-next",
+		Description: "Points the hack repository's development branch at the next dev cycle after branching and creates a pull request",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"minor_version": {
+					Type:        "string",
+					Description: "Minor version that was just branched (e.g., '1.21'). References are bumped to the next minor version",
+				},
+				"base_branch": {
+					Type:        "string",
+					Description: "Development branch of the hack repository. Defaults to 'next'",
+				},
+			},
+			Required: []string{"minor_version"},
+		},
+		OutputSchema: outputSchema[HackResult](),
+	}
+
+	hackNextHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		minorVersion, ok := params.Arguments["minor_version"].(string)
+		if !ok || minorVersion == "" {
+			return nil, fmt.Errorf("minor_version parameter is required")
+		}
+		baseBranch, _ := params.Arguments["base_branch"].(string)
+
+		repoPath, err := runDir(session, "hack-repo-next")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create working directory: %w", err)
+		}
+		defer os.RemoveAll(repoPath)
+
+		config := HackConfig{
+			MinorVersion: minorVersion,
+			RepoPath:     repoPath,
+			BaseBranch:   baseBranch,
+		}
+
+		hackResult, err := ConfigureHackNext(ctx, config)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to configure hack repository for the next cycle: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+
+		if hackResult.PRURL == "" {
+			return &mcp.CallToolResultFor[any]{
+				Content:           []mcp.Content{&mcp.TextContent{Text: "Hack development branch already targets the next cycle, no pull request needed"}},
+				StructuredContent: hackResult,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Successfully created pull request for the next development cycle: %s", hackResult.PRURL)}},
+			StructuredContent: hackResult,
+		}, nil
+	}
+
+	s.AddTool(streamCommands(guardReleaseWindow(scanBeforePush(hackNextTool))), hackNextHandler)
+
+	// Register create-generic-release-plans tool
+	genericPlanTool := &mcp.Tool{
+		Name:        "create-generic-release-plans",
+		Description: "Creates ReleasePlanAdmission and ReleasePlan files for any Konflux application, with the application, components and mapping supplied by the caller",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"application": {
+					Type:        "string",
+					Description: "Konflux application name",
+				},
+				"version": {
+					Type:        "string",
+					Description: "Version being released (e.g., '2.3.0')",
+				},
+				"tenant": {
+					Type:        "string",
+					Description: "Tenant namespace the application is built in (e.g., 'my-team-tenant')",
+				},
+				"components": {
+					Type: "array",
+					Items: &jsonschema.Schema{
+						Type: "object",
+						Properties: map[string]*jsonschema.Schema{
+							"name":       {Type: "string", Description: "Konflux component name"},
+							"repository": {Type: "string", Description: "Repository path in the registry (e.g., 'my-org/my-image-rhel9')"},
+						},
+						Required: []string{"name", "repository"},
+					},
+					Description: "Components of the application and the repositories they are pushed to",
+				},
+				"product_name": {
+					Type:        "string",
+					Description: "Product name used in release notes",
+				},
+				"product_id": {
+					Type:        "integer",
+					Description: "Product ID used in release notes",
+				},
+				"release_type": {
+					Type:        "string",
+					Description: "Advisory type. Defaults to 'RHEA'",
+				},
+				"environments": {
+					Type: "array",
+					Items: &jsonschema.Schema{
+						Type: "string",
+					},
+					Description: "Environments to create plans for. Defaults to ['stage', 'prod']",
+				},
+				"policies": {
+					Type:                 "object",
+					AdditionalProperties: &jsonschema.Schema{Type: "string"},
+					Description:          "Map of environment to Enterprise Contract policy, overriding the registry-standard defaults",
+				},
+				"service_accounts": {
+					Type:                 "object",
+					AdditionalProperties: &jsonschema.Schema{Type: "string"},
+					Description:          "Map of environment to release service account, overriding the defaults",
+				},
+				"rpa_dir": {
+					Type:        "string",
+					Description: "Directory for ReleasePlanAdmissions relative to the konflux-release-data root",
+				},
+				"rp_dir": {
+					Type:        "string",
+					Description: "Directory for ReleasePlans relative to the konflux-release-data root",
+				},
+				"managed_namespace": {
+					Type:        "string",
+					Description: "Managed namespace of the ReleasePlanAdmissions and target of the ReleasePlans. Defaults to the releng tenant of the product",
+				},
+				"pipeline": {
+					Type:        "string",
+					Description: "Path of the managed pipeline in release-service-catalog. Defaults to " + defaultGenericPipeline,
+				},
+				"pin_catalog_revision": {
+					Type:        "boolean",
+					Description: "Resolve the release-service-catalog '" + releaseServiceCatalogRevision + "' branch to its current commit SHA and embed the SHA in the RPAs",
+				},
+				"auto_release": {
+					Type:        "boolean",
+					Description: "Release every snapshot passing its tests without a Release being created. Defaults to false",
+				},
+			},
+			Required: []string{"application", "version", "tenant", "components"},
+		},
+		OutputSchema: outputSchema[PushedBranchResult](),
+	}
+
+	genericPlanHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		repoPath, err := runDir(session, "konflux-release-data")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create working directory: %w", err)
+		}
+		defer os.RemoveAll(repoPath)
+
+		config := GenericRPAConfig{
+			Environments: []string{"stage", "prod"},
+			RepoPath:     repoPath,
+		}
+		config.Application, _ = params.Arguments["application"].(string)
+		config.Version, _ = params.Arguments["version"].(string)
+		config.Tenant, _ = params.Arguments["tenant"].(string)
+		config.ProductName, _ = params.Arguments["product_name"].(string)
+		config.ReleaseType, _ = params.Arguments["release_type"].(string)
+		config.RPADir, _ = params.Arguments["rpa_dir"].(string)
+		config.RPDir, _ = params.Arguments["rp_dir"].(string)
+		config.ManagedNamespace, _ = params.Arguments["managed_namespace"].(string)
+		config.Pipeline, _ = params.Arguments["pipeline"].(string)
+		config.PinCatalogRevision, _ = params.Arguments["pin_catalog_revision"].(bool)
+		config.AutoRelease, _ = params.Arguments["auto_release"].(bool)
+		if productID, ok := params.Arguments["product_id"].(float64); ok {
+			config.ProductID = int(productID)
+		}
+		if components, ok := params.Arguments["components"].([]interface{}); ok {
+			for _, item := range components {
+				if c, ok := item.(map[string]interface{}); ok {
+					name, _ := c["name"].(string)
+					repository, _ := c["repository"].(string)
+					config.Components = append(config.Components, ComponentConfig{Name: name, Repository: repository})
+				}
+			}
+		}
+		config.Policies = make(map[string]string)
+		if policies, ok := params.Arguments["policies"].(map[string]interface{}); ok {
+			for k, v := range policies {
+				if strVal, ok := v.(string); ok {
+					config.Policies[k] = strVal
+				}
+			}
+		}
+		config.ServiceAccounts = make(map[string]string)
+		if accounts, ok := params.Arguments["service_accounts"].(map[string]interface{}); ok {
+			for k, v := range accounts {
+				if strVal, ok := v.(string); ok {
+					config.ServiceAccounts[k] = strVal
+				}
+			}
+		}
+		if envs, ok := params.Arguments["environments"].([]interface{}); ok && len(envs) > 0 {
+			config.Environments = nil
+			for _, v := range envs {
+				if strVal, ok := v.(string); ok {
+					config.Environments = append(config.Environments, strVal)
+				}
+			}
+		}
+
+		pushed, err := createGenericReleasePlans(ctx, config)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to create release plans: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Successfully created ReleasePlan and ReleasePlanAdmission files for %s %s on branch %s, merge request: %s", config.Application, config.Version, pushed.BranchName, pushed.MergeRequestURL)}},
+			StructuredContent: pushed,
+		}, nil
+	}
+
+	s.AddTool(requireConfirmation(runAsync(streamCommands(guardReleaseWindow(scanBeforePush(genericPlanTool)))), prodReleasePlansScope("konflux-release-data")), genericPlanHandler)
+
+	// Register query-release-calendar tool
+	calendarTool := &mcp.Tool{
+		Name:        "query-release-calendar",
+		Description: "Returns the merge window, code freeze, branch, GA and release window dates of a version from the release calendar",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"version": {
+					Type:        "string",
+					Description: "Minor version (e.g., '1.19')",
+				},
+			},
+			Required: []string{"version"},
+		},
+		OutputSchema: outputSchema[CalendarResult](),
+	}
+
+	calendarHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		version, ok := params.Arguments["version"].(string)
+		if !ok || version == "" {
+			return nil, fmt.Errorf("version parameter is required")
+		}
+
+		calendar, err := loadReleaseCalendar()
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to load release calendar: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+		if calendar == nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("No release calendar configured, set %s", calendarFileEnv)}},
+			}, nil
+		}
+
+		dates, ok := calendar.Releases[version]
+		if !ok {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Version %s is not in the release calendar", version)}},
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatReleaseDates(version, dates)}},
+			StructuredContent: CalendarResult{Version: version, Dates: dates},
+		}, nil
+	}
+
+	s.AddTool(calendarTool, calendarHandler)
+
+	// Register reconcile-kustomization tool
+	reconcileTool := &mcp.Tool{
+		Name:        "reconcile-kustomization",
+		Description: "Reconciles a tenant kustomization.yaml in konflux-release-data with the files present in its directory",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"directory": {
+					Type:        "string",
+					Description: "Directory containing kustomization.yaml, relative to the konflux-release-data root. Defaults to the tenant directory of the product",
+				},
+				"dry_run": {
+					Type:        "boolean",
+					Description: "Only report the drift without pushing a fix. Defaults to true",
+				},
+			},
+		},
+		OutputSchema: outputSchema[KustomizationDrift](),
+	}
+
+	reconcileHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		repoPath, err := runDir(session, "konflux-release-data-reconcile")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create working directory: %w", err)
+		}
+		defer os.RemoveAll(repoPath)
+
+		config := KustomizationReconcileConfig{
+			RepoPath: repoPath,
+			Dir:      tenantReleasePlanDir(),
+			DryRun:   true,
+		}
+		if dir, ok := params.Arguments["directory"].(string); ok && dir != "" {
+			config.Dir = dir
+		}
+		if dryRun, ok := params.Arguments["dry_run"].(bool); ok {
+			config.DryRun = dryRun
+		}
+
+		drift, err := reconcileKustomization(ctx, config)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to reconcile kustomization.yaml: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+
+		text := formatKustomizationDrift(drift)
+		if !config.DryRun && (len(drift.Missing) > 0 || len(drift.Stale) > 0) {
+			text = "Pushed kustomization.yaml fix:\n" + text
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: text}},
+			StructuredContent: drift,
+		}, nil
+	}
+
+	s.AddTool(streamCommands(guardReleaseWindow(scanBeforePush(reconcileTool))), reconcileHandler)
+
+	// Register find-orphaned-tenant-resources tool
+	orphanTool := &mcp.Tool{
+		Name:        "find-orphaned-tenant-resources",
+		Description: "Finds ReleasePlans in konflux-release-data whose ReleasePlanAdmission no longer exists, ReleasePlanAdmissions of the tenant no ReleasePlan releases through, and kustomization entries pointing at missing files, and optionally opens a merge request removing them",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"rp_directory": {
+					Type:        "string",
+					Description: "Tenant ReleasePlan directory relative to the konflux-release-data root. Defaults to the tenant directory of the product",
+				},
+				"rpa_directory": {
+					Type:        "string",
+					Description: "ReleasePlanAdmission directory relative to the konflux-release-data root. Defaults to the ReleasePlanAdmission directory of the product",
+				},
+				"cleanup": {
+					Type:        "boolean",
+					Description: "Push a branch removing the orphaned files and stale kustomization entries and open a merge request. Defaults to false",
+				},
+			},
+		},
+		OutputSchema: outputSchema[OrphanResult](),
+	}
+
+	orphanHandler := func(reqCtx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		repoPath, err := runDir(session, "konflux-release-data-orphans")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create working directory: %w", err)
+		}
+		defer os.RemoveAll(repoPath)
+
+		config := OrphanConfig{
+			RepoPath: repoPath,
+			RPDir:    tenantReleasePlanDir(),
+			RPADir:   productRPADir(),
+		}
+		if dir, ok := params.Arguments["rp_directory"].(string); ok && dir != "" {
+			config.RPDir = dir
+		}
+		if dir, ok := params.Arguments["rpa_directory"].(string); ok && dir != "" {
+			config.RPADir = dir
+		}
+		config.Cleanup, _ = params.Arguments["cleanup"].(bool)
+
+		orphans, err := findOrphanedResources(clusterContext(reqCtx, ctx), config)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to find orphaned resources: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatOrphans(orphans)}},
+			StructuredContent: orphans,
+		}, nil
+	}
+
+	s.AddTool(requireConfirmation(streamCommands(guardReleaseWindow(scanBeforePush(orphanTool))), orphanCleanupScope), orphanHandler)
+
+	// Register generate-disconnected-mirror-list tool
+	mirrorTool := &mcp.Tool{
+		Name:        "generate-disconnected-mirror-list",
+		Description: "Generates the oc-mirror ImageSetConfiguration covering the released operator, component images and index images of a version for disconnected environments",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"minor_version": {
+					Type:        "string",
+					Description: "Minor version number (e.g., '1.21')",
+				},
+				"patch_version": {
+					Type:        "string",
+					Description: "Optional patch version number",
+				},
+				"ocp_versions": {
+					Type: "array",
+					Items: &jsonschema.Schema{
+						Type: "string",
+					},
+					Description: "Supported OCP versions (e.g., ['4-15', '4-16']). Defaults to ['4-15', '4-16', '4-17', '4-18', '4-19']",
+				},
+			},
+			Required: []string{"minor_version"},
+		},
+		OutputSchema: outputSchema[MirrorListResult](),
+	}
+
+	mirrorHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		minorVersion, ok := params.Arguments["minor_version"].(string)
+		if !ok || minorVersion == "" {
+			return nil, fmt.Errorf("minor_version parameter is required")
+		}
+
+		config := MirrorListConfig{
+			MinorVersion: minorVersion,
+			OCPVersions:  configuredOCPVersions(),
+			Components:   productComponents(),
+		}
+		config.PatchVersion, _ = params.Arguments["patch_version"].(string)
+		if versions, ok := params.Arguments["ocp_versions"].([]interface{}); ok && len(versions) > 0 {
+			config.OCPVersions = nil
+			for _, v := range versions {
+				if strVal, ok := v.(string); ok {
+					config.OCPVersions = append(config.OCPVersions, strVal)
+				}
+			}
+		}
+
+		mirrorList, err := generateMirrorList(config)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to generate mirror list: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: mirrorList.ImageSetConfiguration}},
+			StructuredContent: mirrorList,
+		}, nil
+	}
+
+	s.AddTool(mirrorTool, mirrorHandler)
+
+	// Register render-template tool
+	renderTool := &mcp.Tool{
+		Name:        "render-template",
+		Description: "Renders a registered manifest or notification template with caller-provided data and lints YAML output, reporting template errors with line numbers. Intended for maintainers iterating on templates",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"template": {
+					Type:        "string",
+					Enum:        templateEnum(),
+					Description: "Name of the registered template",
+				},
+				"data": {
+					Type:        "object",
+					Description: "Data the template is executed with (e.g., {'Component': 'core', 'MinorVersion': '1.21'}). Missing keys are reported as errors",
+				},
+				"locale": {
+					Type:        "string",
+					Description: "Locale notification templates are rendered in (e.g., fr or pt-BR). Defaults to the locale of the server configuration",
+				},
+			},
+			Required: []string{"template"},
+		},
+		OutputSchema: outputSchema[RenderTemplateResult](),
+	}
+
+	renderHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		name, ok := params.Arguments["template"].(string)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("template parameter is required")
+		}
+		data, _ := params.Arguments["data"].(map[string]any)
+		locale, _ := params.Arguments["locale"].(string)
+		if locale == "" {
+			locale = currentConfig().Locale
+		} else if err := validateLocale(locale); err != nil {
+			return nil, err
+		}
+
+		rendered, err := renderLocalizedTemplate(name, locale, data)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to render template: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatRenderTemplate(rendered)}},
+			StructuredContent: rendered,
+			IsError:           len(rendered.Errors) > 0,
+		}, nil
+	}
+
+	s.AddTool(renderTool, renderHandler)
+
+	// Register stage-vs-prod-rpa-consistency-check tool
+	consistencyTool := &mcp.Tool{
+		Name:        "stage-vs-prod-rpa-consistency-check",
+		Description: "Pairs each stage ReleasePlanAdmission in konflux-release-data with its prod counterpart and flags fields that differ beyond policy, service account, registry and intention",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"minor_version": {
+					Type:        "string",
+					Description: "Only check the RPAs of this minor version (e.g., '1.21'). Checks all RPAs when omitted",
+				},
+				"directory": {
+					Type:        "string",
+					Description: "RPA directory relative to the konflux-release-data root. Defaults to the ReleasePlanAdmission directory of the product",
+				},
+			},
+		},
+		OutputSchema: outputSchema[RPAConsistencyResult](),
+	}
+
+	consistencyHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		repoPath, err := runDir(session, "konflux-release-data-consistency")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create working directory: %w", err)
+		}
+		defer os.RemoveAll(repoPath)
+
+		config := RPAConsistencyConfig{
+			RepoPath: repoPath,
+			Dir:      productRPADir(),
+		}
+		config.MinorVersion, _ = params.Arguments["minor_version"].(string)
+		if dir, ok := params.Arguments["directory"].(string); ok && dir != "" {
+			config.Dir = dir
+		}
+
+		consistency, err := checkRPAConsistency(ctx, config)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to check RPA consistency: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatRPAConsistency(consistency)}},
+			StructuredContent: consistency,
+			IsError:           !consistency.Consistent,
+		}, nil
+	}
+
+	s.AddTool(streamCommands(consistencyTool), consistencyHandler)
+
+	// Register find-outdated-generated-files tool
+	outdatedFilesTool := &mcp.Tool{
+		Name:        "find-outdated-generated-files",
+		Description: "Finds the manifests of konflux-release-data generated from a template that changed since, from the template hash stamped onto them, so they can be regenerated after template fixes",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"minor_version": {
+					Type:        "string",
+					Description: "Only check the files of this minor version (e.g., '1.21'). Checks all files when omitted",
+				},
+				"template": {
+					Type:        "string",
+					Description: "Only check the files generated from this template",
+					Enum:        templateEnum(),
+				},
+			},
+		},
+		OutputSchema: outputSchema[OutdatedFilesResult](),
+	}
+
+	outdatedFilesHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		repoPath, err := runDir(session, "konflux-release-data-generated")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create working directory: %w", err)
+		}
+		defer os.RemoveAll(repoPath)
+
+		config := OutdatedFilesConfig{RepoPath: repoPath}
+		config.MinorVersion, _ = params.Arguments["minor_version"].(string)
+		config.Template, _ = params.Arguments["template"].(string)
+
+		outdated, err := findOutdatedGeneratedFiles(ctx, config)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to find outdated generated files: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatOutdatedFiles(outdated)}},
+			StructuredContent: outdated,
+		}, nil
+	}
+
+	s.AddTool(streamCommands(outdatedFilesTool), outdatedFilesHandler)
+
+	// Register validate-branch-yaml tool
+	validateBranchTool := &mcp.Tool{
+		Name:        "validate-branch-yaml",
+		Description: "Checks the hack repository for components whose new branch entry needs an upstream version missing from upstream_versions, without writing any file",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"minor_version": {
+					Type:        "string",
+					Description: "Minor version number (e.g., '1.21')",
+				},
+				"upstream_versions": {
+					Type: "object",
+					AdditionalProperties: &jsonschema.Schema{
+						Type: "string",
+					},
+					Description: "Map of component names to their upstream versions, as passed to configure-hack-repo. Completed with the pins of config/versions.yaml in the hack repository",
+				},
+			},
+			Required: []string{"minor_version"},
+		},
+		OutputSchema: outputSchema[BranchValidationResult](),
+	}
+
+	validateBranchHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		minorVersion, ok := params.Arguments["minor_version"].(string)
+		if !ok || minorVersion == "" {
+			return nil, fmt.Errorf("minor_version parameter is required")
+		}
+
+		upstreamVersions := make(map[string]string)
+		if upstream, ok := params.Arguments["upstream_versions"].(map[string]interface{}); ok {
+			for k, v := range upstream {
+				if strVal, ok := v.(string); ok {
+					upstreamVersions[k] = strVal
+				}
+			}
+		}
+
+		repoPath, err := runDir(session, "hack-repo-validate")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create working directory: %w", err)
+		}
+		defer os.RemoveAll(repoPath)
+
+		config := HackConfig{
+			MinorVersion:   minorVersion,
+			RepoPath:       repoPath,
+			UpstreamConfig: upstreamVersions,
+		}
+
+		validation, err := validateBranchYAML(ctx, config)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to validate branch configuration: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+
+		if !validation.Valid {
+			return &mcp.CallToolResultFor[any]{
+				Content:           []mcp.Content{&mcp.TextContent{Text: "Missing upstream versions:\n" + formatMissingUpstreamVersions(validation.Missing)}},
+				StructuredContent: validation,
+				IsError:           true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: "All upstream versions required by the branch configuration are provided"}},
+			StructuredContent: validation,
+		}, nil
+	}
+
+	s.AddTool(streamCommands(validateBranchTool), validateBranchHandler)
+
+	// Register downstream-patch-rebase-helper tool
+	patchRebaseTool := &mcp.Tool{
+		Name:        "downstream-patch-rebase-helper",
+		Description: "Applies the downstream patches configured in the hack repository onto the new upstream refs in a scratch workspace and reports which patches need rework, with their rejected hunks, before bumping the upstream versions",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"minor_version": {
+					Type:        "string",
+					Description: "Minor version whose branch entries are bumped (e.g., '1.21')",
+				},
+				"upstream_versions": {
+					Type: "object",
+					AdditionalProperties: &jsonschema.Schema{
+						Type: "string",
+					},
+					Description: "Map of component names to their new upstream release branch, as passed to configure-hack-repo. Completed with the pins of config/versions.yaml in the hack repository",
+				},
+				"repositories": {
+					Type: "array",
+					Items: &jsonschema.Schema{
+						Type: "string",
+					},
+					Description: "Only check these repositories (e.g., ['tektoncd-pipeline']). Defaults to every repository with a new upstream version",
+				},
+				"downstream_branch": {
+					Type:        "string",
+					Description: "Downstream branch the patch files are taken from. Defaults to release-v<minor>.x",
+				},
+			},
+			Required: []string{"minor_version"},
+		},
+		OutputSchema: outputSchema[PatchRebaseResult](),
+	}
+
+	patchRebaseHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		minorVersion, ok := params.Arguments["minor_version"].(string)
+		if !ok || minorVersion == "" {
+			return nil, fmt.Errorf("minor_version parameter is required")
+		}
+
+		upstreamVersions := make(map[string]string)
+		if upstream, ok := params.Arguments["upstream_versions"].(map[string]interface{}); ok {
+			for k, v := range upstream {
+				if strVal, ok := v.(string); ok {
+					upstreamVersions[k] = strVal
+				}
+			}
+		}
+
+		repoPath, err := runDir(session, "hack-repo-patch-rebase")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create working directory: %w", err)
+		}
+		defer os.RemoveAll(repoPath)
+		workDir, err := runDir(session, "patch-rebase")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create working directory: %w", err)
+		}
+		defer os.RemoveAll(workDir)
+
+		config := PatchRebaseConfig{
+			Hack: HackConfig{
+				MinorVersion:   minorVersion,
+				RepoPath:       repoPath,
+				UpstreamConfig: upstreamVersions,
+			},
+			WorkDir: workDir,
+		}
+		config.DownstreamBranch, _ = params.Arguments["downstream_branch"].(string)
+		if repos, ok := params.Arguments["repositories"].([]interface{}); ok {
+			for _, v := range repos {
+				if strVal, ok := v.(string); ok && strVal != "" {
+					config.Repositories = append(config.Repositories, strVal)
+				}
+			}
+		}
+
+		rebase, err := rebaseDownstreamPatches(ctx, config)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to apply downstream patches: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatPatchRebase(rebase)}},
+			StructuredContent: rebase,
+			IsError:           rebase.NeedsRework > 0,
+		}, nil
+	}
+
+	s.AddTool(streamCommands(patchRebaseTool), patchRebaseHandler)
+
+	// Register generate-release-branch-readme tool
+	readmeTool := &mcp.Tool{
+		Name:        "generate-release-branch-readme",
+		Description: "Commits a RELEASE.md onto the release branch of each repository recording the cut date, source commit, upstream version and runbook link",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"minor_version": {
+					Type:        "string",
+					Description: "Minor version of the release branches (e.g., '1.21')",
+				},
+				"upstream_versions": {
+					Type: "object",
+					AdditionalProperties: &jsonschema.Schema{
+						Type: "string",
+					},
+					Description: "Map of repository names (e.g., 'tektoncd-pipeline') to the upstream version the branch tracks. Defaults to the pins of config/versions.yaml in the hack repository",
+				},
+				"runbook_url": {
+					Type:        "string",
+					Description: "Link to the release runbook. Defaults to $RELEASE_MCP_RUNBOOK_URL",
+				},
+				"cut_date": {
+					Type:        "string",
+					Description: "Date the branches were cut, in YYYY-MM-DD format. Defaults to today",
+				},
+				"overwrite": {
+					Type:        "boolean",
+					Description: "Replace existing RELEASE.md files. By default existing stamps are kept",
+				},
+			},
+			Required: []string{"minor_version"},
+		},
+		OutputSchema: outputSchema[ReleaseReadmeResult](),
+	}
+
+	readmeHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		minorVersion, ok := params.Arguments["minor_version"].(string)
+		if !ok || minorVersion == "" {
+			return nil, fmt.Errorf("minor_version parameter is required")
+		}
+
+		workDir, err := runDir(session, "release-readme")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create working directory: %w", err)
+		}
+		defer os.RemoveAll(workDir)
+
+		config := ReleaseReadmeConfig{
+			MinorVersion:     minorVersion,
+			WorkDir:          workDir,
+			UpstreamVersions: make(map[string]string),
+		}
+		config.RunbookURL, _ = params.Arguments["runbook_url"].(string)
+		config.CutDate, _ = params.Arguments["cut_date"].(string)
+		config.Overwrite, _ = params.Arguments["overwrite"].(bool)
+		if config.CutDate != "" {
+			if _, err := time.Parse("2006-01-02", config.CutDate); err != nil {
+				return nil, fmt.Errorf("invalid cut_date %q: %w", config.CutDate, err)
+			}
+		}
+		if upstream, ok := params.Arguments["upstream_versions"].(map[string]interface{}); ok {
+			for k, v := range upstream {
+				if strVal, ok := v.(string); ok {
+					config.UpstreamVersions[k] = strVal
+				}
+			}
+		}
+
+		readmeResult, err := stampReleaseBranches(ctx, config)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content:           []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to stamp release branches: %v", err)}},
+				StructuredContent: readmeResult,
+				IsError:           true,
+			}, nil
+		}
+
+		text := fmt.Sprintf("Committed %s to %s in: %s", releaseReadmeFile, readmeResult.Branch, strings.Join(readmeResult.Stamped, ", "))
+		if len(readmeResult.Stamped) == 0 {
+			text = fmt.Sprintf("%s already present on %s in every repository", releaseReadmeFile, readmeResult.Branch)
+		} else if len(readmeResult.Unchanged) > 0 {
+			text += fmt.Sprintf("\nAlready stamped (kept): %s", strings.Join(readmeResult.Unchanged, ", "))
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: text}},
+			StructuredContent: readmeResult,
+		}, nil
+	}
+
+	s.AddTool(streamCommands(guardReleaseWindow(scanBeforePush(readmeTool))), readmeHandler)
+
+	// Register verify-branch-build-yaml tool
+	branchTektonTool := &mcp.Tool{
+		Name:        "verify-branch-build-yaml",
+		Description: "Checks the Konflux .tekton PipelineRuns of the release branches of a version and opens a pull request per repository retargeting the ones that still carry the branch filters and application and component names of the source branch",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"minor_version": {
+					Type:        "string",
+					Description: "Minor version of the release branches (e.g., '1.21')",
+				},
+				"source_version": {
+					Type:        "string",
+					Description: "Version the Konflux names carry on the source branch, replaced by the minor version (e.g., 'tektoncd-core-next-controller'). Defaults to 'next'",
+				},
+				"repositories": {
+					Type: "array",
+					Items: &jsonschema.Schema{
+						Type: "string",
+					},
+					Description: "Release repositories to check, by name (e.g., ['pipeline', 'triggers']). Defaults to every release repository",
+				},
+				"dry_run": {
+					Type:        "boolean",
+					Description: "Only report the PipelineRuns needing changes, without pushing or opening pull requests",
+				},
+			},
+			Required: []string{"minor_version"},
+		},
+		OutputSchema: outputSchema[BranchTektonResult](),
+	}
+
+	branchTektonHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		minorVersion, ok := params.Arguments["minor_version"].(string)
+		if !ok || minorVersion == "" {
+			return nil, fmt.Errorf("minor_version parameter is required")
+		}
+
+		workDir, err := runDir(session, "branch-tekton")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create working directory: %w", err)
+		}
+		defer os.RemoveAll(workDir)
+
+		config := BranchTektonConfig{
+			MinorVersion: minorVersion,
+			WorkDir:      workDir,
+		}
+		config.SourceVersion, _ = params.Arguments["source_version"].(string)
+		config.DryRun, _ = params.Arguments["dry_run"].(bool)
+		if repositories, ok := params.Arguments["repositories"].([]interface{}); ok {
+			for _, v := range repositories {
+				if strVal, ok := v.(string); ok {
+					config.Repositories = append(config.Repositories, strVal)
+				}
+			}
+		}
+
+		branchTekton, err := verifyBranchTekton(ctx, config)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to verify .tekton PipelineRuns: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatBranchTekton(branchTekton)}},
+			StructuredContent: branchTekton,
+		}, nil
+	}
+
+	s.AddTool(requireConfirmation(runAsync(streamCommands(guardReleaseWindow(scanBeforePush(branchTektonTool)))), selectedRepositoriesScope), branchTektonHandler)
+
+	// Register check-fbc-allowed-packages tool
+	fbcPackagesTool := &mcp.Tool{
+		Name:        "check-fbc-allowed-packages",
+		Description: "Checks that the allowedPackages of every FBC ReleasePlanAdmission in konflux-release-data is exactly the operator package name declared in the operator bundle metadata",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"minor_version": {
+					Type:        "string",
+					Description: "Only check the FBC RPAs of this minor version (e.g., '1.21') and read the bundle from its release branch. Checks all FBC RPAs against the next branch when omitted",
+				},
+				"directory": {
+					Type:        "string",
+					Description: "RPA directory relative to the konflux-release-data root. Defaults to the ReleasePlanAdmission directory of the product",
+				},
+				"bundle_path": {
+					Type:        "string",
+					Description: "Bundle annotations file relative to the operator repository root. Defaults to " + operatorBundleAnnotationsPath,
+				},
+			},
+		},
+		OutputSchema: outputSchema[FBCPackagesResult](),
+	}
+
+	fbcPackagesHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		repoPath, err := runDir(session, "konflux-release-data-fbc-packages")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create working directory: %w", err)
+		}
+		defer os.RemoveAll(repoPath)
+
+		config := FBCPackagesConfig{
+			RepoPath: repoPath,
+			Dir:      productRPADir(),
+		}
+		config.MinorVersion, _ = params.Arguments["minor_version"].(string)
+		config.BundlePath, _ = params.Arguments["bundle_path"].(string)
+		if dir, ok := params.Arguments["directory"].(string); ok && dir != "" {
+			config.Dir = dir
+		}
+
+		packages, err := checkFBCAllowedPackages(ctx, config)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to check FBC allowed packages: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatFBCAllowedPackages(packages)}},
+			StructuredContent: packages,
+			IsError:           !packages.Consistent,
+		}, nil
+	}
+
+	s.AddTool(streamCommands(fbcPackagesTool), fbcPackagesHandler)
+
+	// Register show-effective-config tool
+	configTool := &mcp.Tool{
+		Name:        "show-effective-config",
+		Description: "Shows the configuration the server currently uses: repositories, default OCP versions, FBC allowed packages and template overrides, and when it was last reloaded",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+		},
+		OutputSchema: outputSchema[EffectiveConfig](),
+	}
+
+	configHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		config := effectiveConfig()
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatEffectiveConfig(config)}},
+			StructuredContent: config,
+		}, nil
+	}
+
+	s.AddTool(configTool, configHandler)
+
+	// Register registry-tag-promotion tool
+	promotionTool := &mcp.Tool{
+		Name:        "registry-tag-promotion",
+		Description: "Promotes image tags from the stage to the prod registry by copying the stage digest with crane, verifies the prod tag resolves to the same digest and records every promotion",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"minor_version": {
+					Type:        "string",
+					Description: "Minor version number (e.g., '1.21')",
+				},
+				"patch_version": {
+					Type:        "string",
+					Description: "Optional patch version number",
+				},
+				"images": {
+					Type: "array",
+					Items: &jsonschema.Schema{
+						Type: "string",
+					},
+					Description: "Image repositories to promote (e.g., ['pipelines-cli-tkn-rhel9']). Defaults to every component image",
+				},
+				"tags": {
+					Type: "array",
+					Items: &jsonschema.Schema{
+						Type: "string",
+					},
+					Description: "Tags to promote. Defaults to v<minor>.<patch>",
+				},
+				"source_registry": {
+					Type:        "string",
+					Description: "Registry and namespace to promote from. Defaults to the product namespace of registry.stage.redhat.io",
+				},
+				"target_registry": {
+					Type:        "string",
+					Description: "Registry and namespace to promote to. Defaults to the product namespace of registry.redhat.io",
+				},
+				"dry_run": {
+					Type:        "boolean",
+					Description: "Only resolve the source digests without copying",
+				},
+			},
+			Required: []string{"minor_version"},
+		},
+		OutputSchema: outputSchema[PromotionResult](),
+	}
+
+	promotionHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		minorVersion, ok := params.Arguments["minor_version"].(string)
+		if !ok || minorVersion == "" {
+			return nil, fmt.Errorf("minor_version parameter is required")
+		}
+
+		config := PromotionConfig{MinorVersion: minorVersion}
+		config.PatchVersion, _ = params.Arguments["patch_version"].(string)
+		config.SourceRegistry, _ = params.Arguments["source_registry"].(string)
+		config.TargetRegistry, _ = params.Arguments["target_registry"].(string)
+		config.DryRun, _ = params.Arguments["dry_run"].(bool)
+		if images, ok := params.Arguments["images"].([]interface{}); ok {
+			for _, v := range images {
+				if strVal, ok := v.(string); ok && strVal != "" {
+					config.Images = append(config.Images, strVal)
+				}
+			}
+		}
+		if tags, ok := params.Arguments["tags"].([]interface{}); ok {
+			for _, v := range tags {
+				if strVal, ok := v.(string); ok && strVal != "" {
+					config.Tags = append(config.Tags, strVal)
+				}
+			}
+		}
+
+		promotion, err := promoteImages(ctx, config)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to promote images: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatPromotions(promotion)}},
+			StructuredContent: promotion,
+			IsError:           !promotion.Promoted,
+		}, nil
+	}
+
+	s.AddTool(requireConfirmation(streamCommands(guardReleaseWindow(promotionTool)), promotionScope), promotionHandler)
+
+	// Register mirror-release-artifacts tool
+	artifactMirrorTool := &mcp.Tool{
+		Name:        "mirror-release-artifacts",
+		Description: "Pushes the record of a release (its versions manifest pins, the ReleasePlanAdmissions and ReleasePlans of konflux-release-data as one YAML bundle, and its audit log) to an OCI registry with oras, as an artifact tagged with the release version. Existing tags are never overwritten",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"minor_version": {
+					Type:        "string",
+					Description: "Minor version number (e.g., '1.21')",
+				},
+				"patch_version": {
+					Type:        "string",
+					Description: "Optional patch version number",
+				},
+				"repository": {
+					Type:        "string",
+					Description: "OCI repository to push to (e.g., 'quay.io/openshift-pipelines/release-records'). Defaults to artifact_repository of the configuration file",
+				},
+				"dry_run": {
+					Type:        "boolean",
+					Description: "Only assemble the artifact files without pushing",
+				},
+			},
+			Required: []string{"minor_version"},
+		},
+		OutputSchema: outputSchema[ArtifactMirrorResult](),
+	}
+
+	artifactMirrorHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		minorVersion, ok := params.Arguments["minor_version"].(string)
+		if !ok || minorVersion == "" {
+			return nil, fmt.Errorf("minor_version parameter is required")
+		}
+
+		hackPath, err := runDir(session, "hack-repo-artifacts")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create working directory: %w", err)
+		}
+		defer os.RemoveAll(hackPath)
+		konfluxPath, err := runDir(session, "konflux-release-data-artifacts")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create working directory: %w", err)
+		}
+		defer os.RemoveAll(konfluxPath)
+		workDir, err := runDir(session, "release-artifacts")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create working directory: %w", err)
+		}
+		defer os.RemoveAll(workDir)
+
+		config := ArtifactMirrorConfig{MinorVersion: minorVersion, HackPath: hackPath, KonfluxPath: konfluxPath, WorkDir: workDir}
+		config.PatchVersion, _ = params.Arguments["patch_version"].(string)
+		config.Repository, _ = params.Arguments["repository"].(string)
+		config.DryRun, _ = params.Arguments["dry_run"].(bool)
+
+		mirror, err := mirrorReleaseArtifacts(ctx, config)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to mirror release artifacts: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatArtifactMirror(mirror)}},
+			StructuredContent: mirror,
+		}, nil
+	}
+
+	markMutating(artifactMirrorTool.Name)
+	s.AddTool(streamCommands(artifactMirrorTool), artifactMirrorHandler)
+
+	// Register verify-git-tags-match-rpa-version-tags tool
+	versionTagsTool := &mcp.Tool{
+		Name:        "verify-git-tags-match-rpa-version-tags",
+		Description: "Checks that every component repository has the v<version> tag the RPAs publish images under, on its release branch, and that the component images carry the same version label, catching tag and version skew before GA",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"minor_version": {
+					Type:        "string",
+					Description: "Minor version number (e.g., '1.21')",
+				},
+				"patch_version": {
+					Type:        "string",
+					Description: "Optional patch version number",
+				},
+				"registry": {
+					Type:        "string",
+					Description: "Registry and namespace of the images to inspect. Defaults to the product namespace of registry.stage.redhat.io",
+				},
+			},
+			Required: []string{"minor_version"},
+		},
+		OutputSchema: outputSchema[VersionTagsResult](),
+	}
+
+	versionTagsHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		minorVersion, ok := params.Arguments["minor_version"].(string)
+		if !ok || minorVersion == "" {
+			return nil, fmt.Errorf("minor_version parameter is required")
+		}
+
+		config := VersionTagsConfig{MinorVersion: minorVersion}
+		config.PatchVersion, _ = params.Arguments["patch_version"].(string)
+		config.Registry, _ = params.Arguments["registry"].(string)
+
+		versionTags, err := verifyVersionTags(ctx, config)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to verify version tags: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatVersionTags(versionTags)}},
+			StructuredContent: versionTags,
+			IsError:           !versionTags.Consistent,
+		}, nil
+	}
+
+	s.AddTool(versionTagsTool, versionTagsHandler)
+
+	// Register compute-image-size-and-signature-report tool
+	imageReportTool := &mcp.Tool{
+		Name:        "compute-image-size-and-signature-report",
+		Description: "Post-release compliance report: checks that every shipped component image has a cosign signature and an SBOM attached, and compares its size to the previous release",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"minor_version": {
+					Type:        "string",
+					Description: "Minor version number (e.g., '1.21')",
+				},
+				"patch_version": {
+					Type:        "string",
+					Description: "Optional patch version number",
+				},
+				"previous_version": {
+					Type:        "string",
+					Description: "Release to compare sizes to (e.g., '1.21.1'). Defaults to the previous patch release, or the latest patch of the previous minor for a .0 release",
+				},
+				"registry": {
+					Type:        "string",
+					Description: "Registry and namespace of the images. Defaults to the product namespace of registry.redhat.io",
+				},
+				"public_key": {
+					Type:        "string",
+					Description: "cosign public key (file, URL or KMS reference) to verify signatures with. Only the presence of signatures is checked when unset",
+				},
+				"max_size_increase_percent": {
+					Type:        "number",
+					Description: "Flag images growing by more than this percentage since the previous release",
+				},
+			},
+			Required: []string{"minor_version"},
+		},
+		OutputSchema: outputSchema[ImageReportResult](),
+	}
+
+	imageReportHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		minorVersion, ok := params.Arguments["minor_version"].(string)
+		if !ok || minorVersion == "" {
+			return nil, fmt.Errorf("minor_version parameter is required")
+		}
+
+		config := ImageReportConfig{MinorVersion: minorVersion}
+		config.PatchVersion, _ = params.Arguments["patch_version"].(string)
+		config.PreviousVersion, _ = params.Arguments["previous_version"].(string)
+		config.Registry, _ = params.Arguments["registry"].(string)
+		config.PublicKey, _ = params.Arguments["public_key"].(string)
+		config.MaxSizeIncrease, _ = params.Arguments["max_size_increase_percent"].(float64)
+
+		report, err := computeImageReport(ctx, config)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to compute image report: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatImageReport(report)}},
+			StructuredContent: report,
+		}, nil
+	}
+
+	s.AddTool(imageReportTool, imageReportHandler)
+
+	// Register find-unreleased-merged-changes tool
+	unreleasedTool := &mcp.Tool{
+		Name:        "find-unreleased-merged-changes",
+		Description: "Lists the commits merged into the release branch of every component since its last shipped z-stream tag, to decide whether a new z-stream is warranted and what it would contain",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"minor_version": {
+					Type:        "string",
+					Description: "Minor version whose release branches to check (e.g., '1.19')",
+				},
+				"repositories": {
+					Type: "array",
+					Items: &jsonschema.Schema{
+						Type: "string",
+					},
+					Description: "Only check these release repositories, by name or owner/name. Defaults to all of them",
+				},
+			},
+			Required: []string{"minor_version"},
+		},
+		OutputSchema: outputSchema[UnreleasedChangesResult](),
+	}
+
+	unreleasedHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		minorVersion, ok := params.Arguments["minor_version"].(string)
+		if !ok || minorVersion == "" {
+			return nil, fmt.Errorf("minor_version parameter is required")
+		}
+		var repositories []string
+		if repos, ok := params.Arguments["repositories"].([]interface{}); ok {
+			for _, v := range repos {
+				if strVal, ok := v.(string); ok {
+					repositories = append(repositories, strVal)
+				}
+			}
+		}
+
+		unreleased, err := findUnreleasedChanges(ctx, minorVersion, repositories)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to find unreleased changes: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatUnreleasedChanges(unreleased)}},
+			StructuredContent: unreleased,
+		}, nil
+	}
+
+	s.AddTool(unreleasedTool, unreleasedHandler)
+
+	// Register orchestrate-z-stream-release tool
+	zStreamTool := &mcp.Tool{
+		Name:        "orchestrate-z-stream-release",
+		Description: "Runs the z-stream workflow for a minor version: detects the previous z-stream, collects the backports merged since, creates the RHBA release plans, triggers builds of the changed release branches and reports readiness",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"minor_version": {
+					Type:        "string",
+					Description: "Minor version number (e.g., '1.21')",
+				},
+				"patch_version": {
+					Type:        "string",
+					Description: "Patch version to release. Defaults to the patch after the latest tagged one",
+				},
+				"ocp_versions": {
+					Type: "array",
+					Items: &jsonschema.Schema{
+						Type: "string",
+					},
+					Description: "Supported OCP versions for the FBC release plans. Defaults to the configured OCP versions",
+				},
+				"issues": {
+					Type: "array",
+					Items: &jsonschema.Schema{
+						Type: "string",
+					},
+					Description: "JIRA issues fixed by the z-stream (e.g., ['SRVKP-1234'])",
+				},
+				"dry_run": {
+					Type:        "boolean",
+					Description: "Only collect backports and report readiness, without creating release plans or triggering builds. Use it to re-check readiness once builds finish",
+				},
+			},
+			Required: []string{"minor_version"},
+		},
+		OutputSchema: outputSchema[ZStreamResult](),
+	}
+
+	zStreamHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		minorVersion, ok := params.Arguments["minor_version"].(string)
+		if !ok || minorVersion == "" {
+			return nil, fmt.Errorf("minor_version parameter is required")
+		}
+
+		repoPath, err := runDir(session, "konflux-release-data-z-stream")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create working directory: %w", err)
+		}
+		defer os.RemoveAll(repoPath)
+
+		config := ZStreamConfig{
+			MinorVersion: minorVersion,
+			RepoPath:     repoPath,
+			OCPVersions:  configuredOCPVersions(),
+		}
+		config.PatchVersion, _ = params.Arguments["patch_version"].(string)
+		config.DryRun, _ = params.Arguments["dry_run"].(bool)
+		if versions, ok := params.Arguments["ocp_versions"].([]interface{}); ok && len(versions) > 0 {
+			config.OCPVersions = nil
+			for _, v := range versions {
+				if strVal, ok := v.(string); ok {
+					config.OCPVersions = append(config.OCPVersions, strVal)
+				}
+			}
+		}
+		if issues, ok := params.Arguments["issues"].([]interface{}); ok {
+			for _, v := range issues {
+				if strVal, ok := v.(string); ok {
+					config.Issues = append(config.Issues, strVal)
+				}
+			}
+		}
+
+		zStream, err := orchestrateZStream(ctx, config)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to orchestrate z-stream release: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatZStream(zStream)}},
+			StructuredContent: zStream,
+		}, nil
+	}
+
+	s.AddTool(requireConfirmation(runAsync(streamCommands(guardReleaseWindow(scanBeforePush(zStreamTool)))), prodReleasePlansScope("konflux-release-data")), zStreamHandler)
+
+	// Register prepare-patch-release tool
+	patchReleaseTool := &mcp.Tool{
+		Name:        "prepare-patch-release",
+		Description: "Prepares a patch release (e.g., '1.19.2') by updating the advisory type, version references and fixes of the existing ReleasePlanAdmissions and ReleasePlans of its minor version in konflux-release-data, without creating branches or new files",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"version": {
+					Type:        "string",
+					Description: "Patch version to release (e.g., '1.19.2')",
+				},
+				"advisory_type": {
+					Type:        "string",
+					Enum:        []any{"RHBA", "RHSA"},
+					Description: "Advisory type of the release notes. Defaults to RHBA. RHSA requires cves",
+				},
+				"cves": {
+					Type: "array",
+					Items: &jsonschema.Schema{
+						Type: "object",
+						Properties: map[string]*jsonschema.Schema{
+							"key":       {Type: "string", Description: "CVE identifier (e.g., 'CVE-2025-1234')"},
+							"component": {Type: "string", Description: "Konflux component the CVE is fixed in (e.g., 'tektoncd-core-1.19-controller')"},
+						},
+						Required: []string{"key", "component"},
+					},
+					Description: "CVEs fixed by the release, replacing those of the previous release",
+				},
+				"issues": {
+					Type: "array",
+					Items: &jsonschema.Schema{
+						Type: "string",
+					},
+					Description: "JIRA issues fixed by the release (e.g., ['SRVKP-1234']), replacing those of the previous release",
+				},
+				"dry_run": {
+					Type:        "boolean",
+					Description: "Only report the changes, without pushing them or opening a merge request",
+				},
+			},
+			Required: []string{"version"},
+		},
+		OutputSchema: outputSchema[PatchReleaseResult](),
+	}
+
+	patchReleaseHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		version, ok := params.Arguments["version"].(string)
+		if !ok || version == "" {
+			return nil, fmt.Errorf("version parameter is required")
+		}
+		parts := strings.Split(strings.TrimPrefix(version, "v"), ".")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid version %q, expected a patch version such as 1.19.2", version)
+		}
+
+		repoPath, err := runDir(session, "konflux-release-data-patch-release")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create working directory: %w", err)
+		}
+		defer os.RemoveAll(repoPath)
+
+		config := PatchReleaseConfig{
+			MinorVersion: parts[0] + "." + parts[1],
+			PatchVersion: parts[2],
+			RepoPath:     repoPath,
+		}
+		config.AdvisoryType, _ = params.Arguments["advisory_type"].(string)
+		config.DryRun, _ = params.Arguments["dry_run"].(bool)
+		if cves, ok := params.Arguments["cves"].([]interface{}); ok {
+			for _, item := range cves {
+				if c, ok := item.(map[string]interface{}); ok {
+					key, _ := c["key"].(string)
+					component, _ := c["component"].(string)
+					config.CVEs = append(config.CVEs, CVE{Key: key, Component: component})
+				}
+			}
+		}
+		if issues, ok := params.Arguments["issues"].([]interface{}); ok {
+			for _, v := range issues {
+				if strVal, ok := v.(string); ok {
+					config.Issues = append(config.Issues, strVal)
+				}
+			}
+		}
+
+		patchRelease, err := preparePatchRelease(ctx, config)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to prepare patch release: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatPatchRelease(patchRelease)}},
+			StructuredContent: patchRelease,
+		}, nil
+	}
+
+	s.AddTool(requireConfirmation(streamCommands(guardReleaseWindow(scanBeforePush(patchReleaseTool))), prodReleasePlansScope("konflux-release-data")), patchReleaseHandler)
+
+	// Register bulk-cherry-pick-fix tool
+	cherryPickTool := &mcp.Tool{
+		Name:        "bulk-cherry-pick-fix",
+		Description: "Cherry-picks a fix, typically a CVE fix, onto the release branches of all supported versions of a release repository, opening one pull request per branch and summarizing the branches it conflicts with",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"repository": {
+					Type:        "string",
+					Description: "Release repository, by name or owner/name (e.g., 'tektoncd-pipeline' or 'openshift-pipelines/tektoncd-pipeline')",
+				},
+				"commit": {
+					Type:        "string",
+					Description: "SHA of the fix on the default branch",
+				},
+				"branches": {
+					Type: "array",
+					Items: &jsonschema.Schema{
+						Type: "string",
+					},
+					Description: "Release branches to cherry-pick onto (e.g., ['release-v1.19.x']). Defaults to the release branches of the supported versions",
+				},
+				"cve": {
+					Type:        "string",
+					Description: "CVE fixed by the commit (e.g., 'CVE-2025-1234'), referenced in the pull requests",
+				},
+				"dry_run": {
+					Type:        "boolean",
+					Description: "Only cherry-pick locally to report conflicts, without pushing or opening pull requests",
+				},
+			},
+			Required: []string{"repository", "commit"},
+		},
+		OutputSchema: outputSchema[CherryPickResult](),
+	}
+
+	cherryPickHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		repository, ok := params.Arguments["repository"].(string)
+		if !ok || repository == "" {
+			return nil, fmt.Errorf("repository parameter is required")
+		}
+		commit, ok := params.Arguments["commit"].(string)
+		if !ok || commit == "" {
+			return nil, fmt.Errorf("commit parameter is required")
+		}
+
+		workDir, err := runDir(session, "cherry-pick")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create working directory: %w", err)
+		}
+		defer os.RemoveAll(workDir)
+		konfluxPath, err := runDir(session, "konflux-release-data-cherry-pick")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create working directory: %w", err)
+		}
+		defer os.RemoveAll(konfluxPath)
+
+		config := CherryPickConfig{
+			Repository:  repository,
+			Commit:      commit,
+			WorkDir:     workDir,
+			KonfluxPath: konfluxPath,
+		}
+		config.CVE, _ = params.Arguments["cve"].(string)
+		config.DryRun, _ = params.Arguments["dry_run"].(bool)
+		config.OverrideWindow, _ = params.Arguments[windowOverrideArg].(bool)
+		if branches, ok := params.Arguments["branches"].([]interface{}); ok {
+			for _, v := range branches {
+				if strVal, ok := v.(string); ok {
+					config.Branches = append(config.Branches, strVal)
+				}
+			}
+		}
+
+		cherryPick, err := cherryPickFix(ctx, config)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to cherry-pick fix: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatCherryPick(cherryPick)}},
+			StructuredContent: cherryPick,
+		}, nil
+	}
+
+	s.AddTool(requireConfirmation(runAsync(streamCommands(guardReleaseWindow(scanBeforePush(cherryPickTool)))), cherryPickScope), cherryPickHandler)
+
+	// Register list-ocp-index-applications tool
+	ocpIndexAppsTool := &mcp.Tool{
+		Name:        "list-ocp-index-applications",
+		Description: "Reports, per OCP version, whether the index application is configured in the hack repository, listed by the stage and prod FBC ReleasePlanAdmissions in konflux-release-data, and present on the cluster",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"minor_version": {
+					Type:        "string",
+					Description: "Minor version to check (e.g., '1.21')",
+				},
+				"ocp_versions": {
+					Type:        "array",
+					Description: "OCP versions to report in addition to the ones found in hack and konflux-release-data (e.g., ['4-18', '4-19']). Defaults to the configured OCP versions",
+					Items: &jsonschema.Schema{
+						Type: "string",
+					},
+				},
+			},
+			Required: []string{"minor_version"},
+		},
+		OutputSchema: outputSchema[OCPIndexAppsResult](),
+	}
+
+	ocpIndexAppsHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		minorVersion, ok := params.Arguments["minor_version"].(string)
+		if !ok || minorVersion == "" {
+			return nil, fmt.Errorf("minor_version parameter is required")
+		}
+
+		hackPath, err := runDir(session, "hack-repo-index-apps")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create working directory: %w", err)
+		}
+		defer os.RemoveAll(hackPath)
+		konfluxPath, err := runDir(session, "konflux-release-data-index-apps")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create working directory: %w", err)
+		}
+		defer os.RemoveAll(konfluxPath)
+
+		config := OCPIndexAppsConfig{
+			MinorVersion: minorVersion,
+			OCPVersions:  configuredOCPVersions(),
+			HackPath:     hackPath,
+			KonfluxPath:  konfluxPath,
+		}
+		if versions, ok := params.Arguments["ocp_versions"].([]interface{}); ok && len(versions) > 0 {
+			config.OCPVersions = nil
+			for _, v := range versions {
+				if strVal, ok := v.(string); ok {
+					config.OCPVersions = append(config.OCPVersions, strVal)
+				}
+			}
+		}
+
+		apps, err := listOCPIndexApplications(ctx, config)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to list OCP index applications: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatOCPIndexApplications(apps)}},
+			StructuredContent: apps,
+			IsError:           !apps.Consistent,
+		}, nil
+	}
+
+	s.AddTool(streamCommands(ocpIndexAppsTool), ocpIndexAppsHandler)
+
+	// Register create-release-branch-ci-badge-and-status-page tool
+	statusPageTool := &mcp.Tool{
+		Name:        "create-release-branch-ci-badge-and-status-page",
+		Description: "Generates a Markdown status page with a build badge for every component's release branch and opens a pull request publishing it to the gh-pages branch of the hack repository. Run it again to refresh the page",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"minor_version": {
+					Type:        "string",
+					Description: "Minor version whose release branches to report (e.g., '1.21')",
+				},
+				"repository": {
+					Type:        "string",
+					Description: "GitHub repository (owner/name) publishing the page. Defaults to " + hackRepo,
+				},
+				"dry_run": {
+					Type:        "boolean",
+					Description: "Only return the page without publishing it",
+				},
+				"push_directly": {
+					Type:        "boolean",
+					Description: "Push the page to the gh-pages branch, creating it when missing, instead of opening a pull request",
+				},
+			},
+			Required: []string{"minor_version"},
+		},
+		OutputSchema: outputSchema[StatusPageResult](),
+	}
+
+	statusPageHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		minorVersion, ok := params.Arguments["minor_version"].(string)
+		if !ok || minorVersion == "" {
+			return nil, fmt.Errorf("minor_version parameter is required")
+		}
+
+		repoPath, err := runDir(session, "status-page")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create working directory: %w", err)
+		}
+		defer os.RemoveAll(repoPath)
+
+		config := StatusPageConfig{
+			MinorVersion: minorVersion,
+			RepoPath:     repoPath,
+		}
+		config.Repository, _ = params.Arguments["repository"].(string)
+		config.DryRun, _ = params.Arguments["dry_run"].(bool)
+		config.PushDirectly, _ = params.Arguments["push_directly"].(bool)
+
+		page, err := createStatusPage(ctx, config)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content:           []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to create status page: %v", err)}},
+				StructuredContent: page,
+				IsError:           true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatStatusPage(page)}},
+			StructuredContent: page,
+		}, nil
+	}
+
+	s.AddTool(streamCommands(scanBeforePush(statusPageTool)), statusPageHandler)
+
+	// Register hack-repo-new-component-onboarding tool
+	onboardingTool := &mcp.Tool{
+		Name:        "hack-repo-new-component-onboarding",
+		Description: "Onboards a new component: adds its repository configuration to the hack repository and its images to the ReleasePlanAdmissions of its application in konflux-release-data, creating the release plans of a new application, and opens the pull and merge requests",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"minor_version": {
+					Type:        "string",
+					Description: "Minor version the component is released with first (e.g., '1.21')",
+				},
+				"repository": {
+					Type:        "string",
+					Description: "Repository name in the hack configuration (e.g., 'tektoncd-foo')",
+				},
+				"upstream": {
+					Type:        "string",
+					Description: "Upstream repository of a downstream fork (e.g., 'tektoncd/foo')",
+				},
+				"upstream_version": {
+					Type:        "string",
+					Description: "Upstream branch the release branch follows (e.g., 'release-v0.3.x')",
+				},
+				"application": {
+					Type:        "string",
+					Description: "Konflux application the components are released with (e.g., 'core'). A new application gets its own release plans",
+				},
+				"components": {
+					Type: "array",
+					Items: &jsonschema.Schema{
+						Type: "object",
+						Properties: map[string]*jsonschema.Schema{
+							"name":       {Type: "string", Description: "Component name (e.g., 'controller')"},
+							"repository": {Type: "string", Description: "Image repository under openshift-pipelines in the registry (e.g., 'pipelines-foo-controller-rhel9')"},
+						},
+						Required: []string{"name", "repository"},
+					},
+					Description: "Components built from the repository and the image repositories they are released to",
+				},
+				"environments": {
+					Type: "array",
+					Items: &jsonschema.Schema{
+						Type: "string",
+					},
+					Description: "Environments to add the components to. Defaults to ['stage', 'prod']",
+				},
+				"hack_branch": {
+					Type:        "string",
+					Description: "Hack branch to onboard into. Defaults to release-v<minor_version>.x",
+				},
+			},
+			Required: []string{"minor_version", "repository", "application", "components"},
+		},
+		OutputSchema: outputSchema[OnboardingResult](),
+	}
+
+	onboardingHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		minorVersion, ok := params.Arguments["minor_version"].(string)
+		if !ok || minorVersion == "" {
+			return nil, fmt.Errorf("minor_version parameter is required")
+		}
+
+		hackPath, err := runDir(session, "hack-repo-onboarding")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create working directory: %w", err)
+		}
+		defer os.RemoveAll(hackPath)
+		konfluxPath, err := runDir(session, "konflux-release-data-onboarding")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create working directory: %w", err)
+		}
+		defer os.RemoveAll(konfluxPath)
+
+		config := OnboardingConfig{
+			MinorVersion: minorVersion,
+			HackPath:     hackPath,
+			KonfluxPath:  konfluxPath,
+		}
+		config.Repository, _ = params.Arguments["repository"].(string)
+		config.Upstream, _ = params.Arguments["upstream"].(string)
+		config.UpstreamVersion, _ = params.Arguments["upstream_version"].(string)
+		config.Application, _ = params.Arguments["application"].(string)
+		config.HackBranch, _ = params.Arguments["hack_branch"].(string)
+		if components, ok := params.Arguments["components"].([]interface{}); ok {
+			for _, item := range components {
+				if c, ok := item.(map[string]interface{}); ok {
+					name, _ := c["name"].(string)
+					repository, _ := c["repository"].(string)
+					config.Components = append(config.Components, ComponentConfig{Name: name, Repository: repository})
+				}
+			}
+		}
+		if envs, ok := params.Arguments["environments"].([]interface{}); ok && len(envs) > 0 {
+			for _, v := range envs {
+				if strVal, ok := v.(string); ok {
+					config.Environments = append(config.Environments, strVal)
+				}
+			}
+		}
+
+		onboarding, err := onboardComponent(ctx, config)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content:           []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to onboard %s: %v", config.Repository, err)}},
+				StructuredContent: onboarding,
+				IsError:           true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatOnboarding(onboarding)}},
+			StructuredContent: onboarding,
+		}, nil
+	}
+
+	s.AddTool(streamCommands(guardReleaseWindow(scanBeforePush(onboardingTool))), onboardingHandler)
+
+	// Register fetch-release-service-catalog-params tool
+	catalogParamsTool := &mcp.Tool{
+		Name:        "fetch-release-service-catalog-params",
+		Description: "Fetches the release-service-catalog pipelines referenced by the ReleasePlanAdmissions at the pinned revision and lists their parameters, types and defaults",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"pipelines": {
+					Type: "array",
+					Items: &jsonschema.Schema{
+						Type: "string",
+					},
+					Description: "Pipelines to fetch, by name ('rh-advisories', 'fbc-release') or path in the catalog. Defaults to the pipelines referenced by the generated RPAs",
+				},
+				"revision": {
+					Type:        "string",
+					Description: "Catalog revision to read. Defaults to '" + releaseServiceCatalogRevision + "', the revision the RPAs resolve pipelines at",
+				},
+			},
+		},
+		OutputSchema: outputSchema[CatalogParamsResult](),
+	}
+
+	catalogParamsHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		var pipelines []string
+		if items, ok := params.Arguments["pipelines"].([]interface{}); ok {
+			for _, v := range items {
+				if strVal, ok := v.(string); ok {
+					pipelines = append(pipelines, strVal)
+				}
+			}
+		}
+		revision, _ := params.Arguments["revision"].(string)
+
+		catalogParams, err := fetchCatalogParams(ctx, pipelines, revision)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to fetch pipeline parameters: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatCatalogParams(catalogParams)}},
+			StructuredContent: catalogParams,
+		}, nil
+	}
+
+	s.AddTool(catalogParamsTool, catalogParamsHandler)
+
+	// Register schedule-release-task tool
+	scheduleTool := &mcp.Tool{
+		Name:        "schedule-release-task",
+		Description: "Schedules a tool call to run later, once or repeatedly, such as re-checking readiness in 2 hours or refreshing FBC status daily until GA. Scheduled tasks survive server restarts and their runs are recorded in the release actions of their version",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"tool": {
+					Type:        "string",
+					Description: "Name of the tool to run",
+				},
+				"arguments": {
+					Type:        "object",
+					Description: "Arguments of the tool call",
+				},
+				"delay": {
+					Type:        "string",
+					Description: "How long from now to run the task first (e.g., '2h', '30m'). Runs on the next poll when neither delay nor at is set",
+				},
+				"at": {
+					Type:        "string",
+					Description: "When to run the task first, in RFC 3339 format (e.g., '2025-06-01T09:00:00Z')",
+				},
+				"interval": {
+					Type:        "string",
+					Description: "Repeat the task at this interval (e.g., '24h'). At least " + minTaskInterval.String(),
+				},
+				"until": {
+					Type:        "string",
+					Description: "Last day a recurring task runs, as YYYY-MM-DD, or 'ga' for the GA date of the version in the release calendar",
+				},
+			},
+			Required: []string{"tool"},
+		},
+		OutputSchema: outputSchema[ScheduledTasksResult](),
+	}
+
+	scheduleHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		toolName, ok := params.Arguments["tool"].(string)
+		if !ok || toolName == "" {
+			return nil, fmt.Errorf("tool parameter is required")
+		}
+
+		task := ScheduledTask{Tool: toolName}
+		task.Arguments, _ = params.Arguments["arguments"].(map[string]interface{})
+		task.Interval, _ = params.Arguments["interval"].(string)
+		delay, _ := params.Arguments["delay"].(string)
+		at, _ := params.Arguments["at"].(string)
+		until, _ := params.Arguments["until"].(string)
+		if until != "" && task.Interval == "" {
+			return nil, fmt.Errorf("until requires an interval")
+		}
+
+		var err error
+		task.RunAt, task.Until, err = taskSchedule(time.Now(), delay, at, until, auditVersion(task.Arguments))
+		if err == nil {
+			task, err = taskScheduler.schedule(ctx, task)
+		}
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to schedule %s: %v", toolName, err)}},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: "Scheduled task:\n" + formatScheduledTasks([]ScheduledTask{task})}},
+			StructuredContent: ScheduledTasksResult{Tasks: []ScheduledTask{task}},
+		}, nil
+	}
+
+	s.AddTool(scheduleTool, scheduleHandler)
+
+	// Register list-scheduled-tasks tool
+	listTasksTool := &mcp.Tool{
+		Name:        "list-scheduled-tasks",
+		Description: "Lists the scheduled tasks with their next run and the outcome of their last run",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"version": {
+					Type:        "string",
+					Description: "Only list the tasks of this version (e.g., '1.21')",
+				},
+			},
+		},
+		OutputSchema: outputSchema[ScheduledTasksResult](),
+	}
+
+	listTasksHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		tasks, err := taskScheduler.list()
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to list scheduled tasks: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+		if version, ok := params.Arguments["version"].(string); ok && version != "" {
+			var filtered []ScheduledTask
+			for _, task := range tasks {
+				if task.Version == version || strings.HasPrefix(task.Version, version+".") {
+					filtered = append(filtered, task)
+				}
+			}
+			tasks = filtered
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatScheduledTasks(tasks)}},
+			StructuredContent: ScheduledTasksResult{Tasks: tasks},
+		}, nil
+	}
+
+	s.AddTool(listTasksTool, listTasksHandler)
+
+	// Register cancel-scheduled-task tool
+	cancelTaskTool := &mcp.Tool{
+		Name:        "cancel-scheduled-task",
+		Description: "Cancels a scheduled task",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"id": {
+					Type:        "string",
+					Description: "ID of the task, as returned by schedule-release-task or list-scheduled-tasks",
+				},
+			},
+			Required: []string{"id"},
+		},
+		OutputSchema: outputSchema[ScheduledTasksResult](),
+	}
+
+	cancelTaskHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		id, ok := params.Arguments["id"].(string)
+		if !ok || id == "" {
+			return nil, fmt.Errorf("id parameter is required")
+		}
+
+		task, err := taskScheduler.cancel(id)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to cancel task %s: %v", id, err)}},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: "Cancelled task:\n" + formatScheduledTasks([]ScheduledTask{task})}},
+			StructuredContent: ScheduledTasksResult{Tasks: []ScheduledTask{task}},
+		}, nil
+	}
+
+	s.AddTool(cancelTaskTool, cancelTaskHandler)
+
+	// Register verify-kustomize-build-of-tenant-config-in-CI-parity tool
+	parityTool := &mcp.Tool{
+		Name:        "verify-kustomize-build-of-tenant-config-in-CI-parity",
+		Description: "Runs the manifest build the konflux-release-data CI runs on a branch and reports files whose committed manifests differ from the build, and whether the local kustomize matches the version the CI pins",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"branch": {
+					Type:        "string",
+					Description: "Branch of konflux-release-data to check (e.g., 'release-plan-v1.21'). Defaults to the default branch",
+				},
+				"kustomize_version": {
+					Type:        "string",
+					Description: "kustomize version the CI runs, overriding the one pinned in " + konfluxCIConfig,
+				},
+			},
+		},
+		OutputSchema: outputSchema[KustomizeParityResult](),
+	}
+
+	parityHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		repoPath, err := runDir(session, "konflux-release-data-parity")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create working directory: %w", err)
+		}
+		defer os.RemoveAll(repoPath)
+
+		config := KustomizeParityConfig{RepoPath: repoPath}
+		config.Branch, _ = params.Arguments["branch"].(string)
+		config.KustomizeVersion, _ = params.Arguments["kustomize_version"].(string)
+
+		parity, err := verifyKustomizeParity(ctx, config)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content:           []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to verify the manifest build: %v", err)}},
+				StructuredContent: parity,
+				IsError:           true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatKustomizeParity(parity)}},
+			StructuredContent: parity,
+			IsError:           !parity.Passed,
+		}, nil
+	}
+
+	s.AddTool(streamCommands(parityTool), parityHandler)
+
+	// Register close-superseded-release-requests tool
+	supersededTool := &mcp.Tool{
+		Name:        "close-superseded-release-requests",
+		Description: "Finds open pull and merge requests the server opened for a version that a newer request for the same step replaced, such as after a respin, and closes them with a comment linking the superseding request",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"minor_version": {
+					Type:        "string",
+					Description: "Minor version of the release (e.g., '1.21')",
+				},
+				"dry_run": {
+					Type:        "boolean",
+					Description: "Only report the superseded requests without closing them",
+				},
+			},
+			Required: []string{"minor_version"},
+		},
+		OutputSchema: outputSchema[CloseSupersededResult](),
+	}
+
+	supersededHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		minorVersion, ok := params.Arguments["minor_version"].(string)
+		if !ok || minorVersion == "" {
+			return nil, fmt.Errorf("minor_version parameter is required")
+		}
+
+		config := CloseSupersededConfig{MinorVersion: minorVersion}
+		config.DryRun, _ = params.Arguments["dry_run"].(bool)
+
+		closed, err := closeSupersededRequests(ctx, config)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content:           []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to close superseded requests: %v", err)}},
+				StructuredContent: closed,
+				IsError:           true,
+			}, nil
+		}
+
+		failed := len(closed.Errors) > 0
+		for _, superseded := range closed.Superseded {
+			failed = failed || superseded.Error != ""
+		}
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatCloseSuperseded(closed)}},
+			StructuredContent: closed,
+			IsError:           failed,
+		}, nil
+	}
+
+	s.AddTool(guardReleaseWindow(supersededTool), supersededHandler)
+
+	// Register release-notes-docs-pr tool
+	docsTool := &mcp.Tool{
+		Name:        "release-notes-docs-pr",
+		Description: "Turns the release notes of a version into an AsciiDoc module and opens a pull request against the configured product documentation repository, adding the module under modules/ and including it in the release notes assembly",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"minor_version": {
+					Type:        "string",
+					Description: "Minor version of the release (e.g., '1.21')",
+				},
+				"patch_version": {
+					Type:        "string",
+					Description: "Patch version for z-stream releases (e.g., '3' for 1.21.3)",
+				},
+				"cves": {
+					Type: "array",
+					Items: &jsonschema.Schema{
+						Type: "object",
+						Properties: map[string]*jsonschema.Schema{
+							"key":       {Type: "string", Description: "CVE identifier (e.g., 'CVE-2025-1234')"},
+							"component": {Type: "string", Description: "Konflux component the CVE is fixed in (e.g., 'tektoncd-core-1.21-controller')"},
+						},
+						Required: []string{"key", "component"},
+					},
+					Description: "CVEs fixed by the release, as passed to create-release-plans",
+				},
+				"issues": {
+					Type: "array",
+					Items: &jsonschema.Schema{
+						Type: "string",
+					},
+					Description: "JIRA issues fixed by the release (e.g., ['SRVKP-1234']), as passed to create-release-plans",
+				},
+				"features": {
+					Type: "array",
+					Items: &jsonschema.Schema{
+						Type: "string",
+					},
+					Description: "Notable features of the release, one sentence each, listed under New features and in the leadership summary",
+				},
+				"dry_run": {
+					Type:        "boolean",
+					Description: "Only render the module without opening a pull request",
+				},
+			},
+			Required: []string{"minor_version"},
+		},
+		OutputSchema: outputSchema[ReleaseNotesDocsResult](),
+	}
+
+	docsHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		minorVersion, ok := params.Arguments["minor_version"].(string)
+		if !ok || minorVersion == "" {
+			return nil, fmt.Errorf("minor_version parameter is required")
+		}
+
+		repoPath, err := runDir(session, "docs")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create working directory: %w", err)
+		}
+		defer os.RemoveAll(repoPath)
+
+		config := ReleaseNotesDocsConfig{MinorVersion: minorVersion, RepoPath: repoPath}
+		config.PatchVersion, _ = params.Arguments["patch_version"].(string)
+		config.DryRun, _ = params.Arguments["dry_run"].(bool)
+		if cves, ok := params.Arguments["cves"].([]interface{}); ok {
+			for _, item := range cves {
+				if c, ok := item.(map[string]interface{}); ok {
+					key, _ := c["key"].(string)
+					component, _ := c["component"].(string)
+					config.CVEs = append(config.CVEs, CVE{Key: key, Component: component})
+				}
+			}
+		}
+		if issues, ok := params.Arguments["issues"].([]interface{}); ok {
+			for _, v := range issues {
+				if strVal, ok := v.(string); ok {
+					config.Issues = append(config.Issues, strVal)
+				}
+			}
+		}
+		config.Features = stringsArg(params.Arguments["features"])
+
+		docs, err := createReleaseNotesDocsPR(ctx, config)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content:           []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to open release notes pull request: %v", err)}},
+				StructuredContent: docs,
+				IsError:           true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatReleaseNotesDocs(docs)}},
+			StructuredContent: docs,
+		}, nil
+	}
+
+	s.AddTool(streamCommands(scanBeforePush(docsTool)), docsHandler)
+
+	// Register reconcile-componentMapping-with-hack-repo tool
+	mappingTool := &mcp.Tool{
+		Name:        "reconcile-componentMapping-with-hack-repo",
+		Description: "Cross-checks the componentMapping built into the server against the repo files in config/konflux/repos of the hack repository, reporting repositories missing from the mapping and mapping entries without a repo file",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"branch": {
+					Type:        "string",
+					Description: "Hack branch to compare with (e.g., 'release-v1.21.x'). Defaults to main",
+				},
+				"generate": {
+					Type:        "boolean",
+					Description: "Also generate the mapping from the repo files, as Go source",
+				},
+			},
+		},
+		OutputSchema: outputSchema[ComponentMappingResult](),
+	}
+
+	mappingHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		repoPath, err := runDir(session, "hack")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create working directory: %w", err)
+		}
+		defer os.RemoveAll(repoPath)
+
+		config := ComponentMappingConfig{RepoPath: repoPath}
+		config.Branch, _ = params.Arguments["branch"].(string)
+		config.Generate, _ = params.Arguments["generate"].(bool)
+
+		mapping, err := reconcileComponentMapping(ctx, config)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content:           []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to reconcile componentMapping: %v", err)}},
+				StructuredContent: mapping,
+				IsError:           true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatComponentMapping(mapping)}},
+			StructuredContent: mapping,
+			IsError:           !mapping.Consistent,
+		}, nil
+	}
+
+	s.AddTool(streamCommands(mappingTool), mappingHandler)
+
+	// Register pin-and-verify-release-service-catalog-revision tool
+	catalogPinTool := &mcp.Tool{
+		Name:        "pin-and-verify-release-service-catalog-revision",
+		Description: "Audits the ReleasePlanAdmissions in konflux-release-data for release-service-catalog revisions that follow a moving branch instead of a commit SHA, and resolves the commit the '" + releaseServiceCatalogRevision + "' branch currently points to",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"minor_version": {
+					Type:        "string",
+					Description: "Only audit the RPAs of this minor version (e.g., '1.21'). Audits all RPAs when omitted",
+				},
+				"directory": {
+					Type:        "string",
+					Description: "RPA directory relative to the konflux-release-data root. Defaults to the ReleasePlanAdmission directory of the product",
+				},
+			},
+		},
+		OutputSchema: outputSchema[CatalogPinResult](),
+	}
+
+	catalogPinHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		repoPath, err := runDir(session, "konflux-release-data-catalog-pins")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create working directory: %w", err)
+		}
+		defer os.RemoveAll(repoPath)
+
+		config := CatalogPinConfig{
+			RepoPath: repoPath,
+			Dir:      productRPADir(),
+		}
+		config.MinorVersion, _ = params.Arguments["minor_version"].(string)
+		if dir, ok := params.Arguments["directory"].(string); ok && dir != "" {
+			config.Dir = dir
+		}
+
+		pins, err := auditCatalogPins(ctx, config)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to audit catalog revisions: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatCatalogPins(pins)}},
+			StructuredContent: pins,
+			IsError:           !pins.Pinned,
+		}, nil
+	}
+
+	s.AddTool(streamCommands(catalogPinTool), catalogPinHandler)
+
+	// Register verify-product-version-field-matches-semver-policy tool
+	productVersionTool := &mcp.Tool{
+		Name:        "verify-product-version-field-matches-semver-policy",
+		Description: "Audits the release notes product_version of the ReleasePlanAdmissions in konflux-release-data against the errata conventions (MAJOR.MINOR.PATCH of the RPA's version, 'fbc' only for FBC RPAs) and suggests corrections",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"minor_version": {
+					Type:        "string",
+					Description: "Only audit the RPAs of this minor version (e.g., '1.21'). Audits all RPAs when omitted",
+				},
+				"directory": {
+					Type:        "string",
+					Description: "RPA directory relative to the konflux-release-data root. Defaults to the ReleasePlanAdmission directory of the product",
+				},
+			},
+		},
+		OutputSchema: outputSchema[ProductVersionResult](),
+	}
+
+	productVersionHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		repoPath, err := runDir(session, "konflux-release-data-product-versions")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create working directory: %w", err)
+		}
+		defer os.RemoveAll(repoPath)
+
+		config := ProductVersionConfig{
+			RepoPath: repoPath,
+			Dir:      productRPADir(),
+		}
+		config.MinorVersion, _ = params.Arguments["minor_version"].(string)
+		if dir, ok := params.Arguments["directory"].(string); ok && dir != "" {
+			config.Dir = dir
+		}
+
+		versions, err := auditProductVersions(ctx, config)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to audit product versions: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatProductVersions(versions)}},
+			StructuredContent: versions,
+			IsError:           !versions.Compliant,
+		}, nil
+	}
+
+	s.AddTool(streamCommands(productVersionTool), productVersionHandler)
+
+	// Register get-job-status tool
+	jobStatusTool := &mcp.Tool{
+		Name:        "get-job-status",
+		Description: "Returns the state of a tool call started with async, and its result once finished",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"job_id": {
+					Type:        "string",
+					Description: "ID of the job returned by the async tool call",
+				},
+			},
+			Required: []string{"job_id"},
+		},
+		OutputSchema: outputSchema[JobStatus](),
+	}
+
+	jobStatusHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		jobID, ok := params.Arguments["job_id"].(string)
+		if !ok || jobID == "" {
+			return nil, fmt.Errorf("job_id parameter is required")
+		}
+
+		j, err := backgroundJobs.get(jobID)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to get job status: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+
+		status := j.snapshot()
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatJobStatus(status)}},
+			StructuredContent: status,
+		}, nil
+	}
+
+	s.AddTool(jobStatusTool, jobStatusHandler)
+
+	// Register get-job-logs tool
+	jobLogsTool := &mcp.Tool{
+		Name:        "get-job-logs",
+		Description: "Returns the output of the git commands and scripts run by a tool call started with async",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"job_id": {
+					Type:        "string",
+					Description: "ID of the job returned by the async tool call",
+				},
+				"offset": {
+					Type:        "integer",
+					Description: "Return the lines from this offset, the next of the previous call, to only read new output. Defaults to 0",
+				},
+			},
+			Required: []string{"job_id"},
+		},
+		OutputSchema: outputSchema[JobLogsResult](),
+	}
+
+	jobLogsHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		jobID, ok := params.Arguments["job_id"].(string)
+		if !ok || jobID == "" {
+			return nil, fmt.Errorf("job_id parameter is required")
+		}
+		offset, _ := params.Arguments["offset"].(float64)
+
+		j, err := backgroundJobs.get(jobID)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to get job logs: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+
+		logs := j.logsFrom(int(offset))
+		text := fmt.Sprintf("Job %s %s, no new output\n", logs.ID, logs.State)
+		if len(logs.Lines) > 0 {
+			text = strings.Join(logs.Lines, "\n") + "\n"
+		}
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: text}},
+			StructuredContent: logs,
+		}, nil
+	}
+
+	s.AddTool(jobLogsTool, jobLogsHandler)
+
+	// Register get-run-log tool
+	runLogTool := &mcp.Tool{
+		Name:        "get-run-log",
+		Description: "Returns the log of a tool call, including the output of its git commands and scripts, identified by the run ID in the metadata of its result. Lists the recent runs without a run ID",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"run_id": {
+					Type:        "string",
+					Description: "ID of the run, from the release-mcp/run-id metadata of the result of the tool call. Lists the recent runs when omitted",
+				},
+				"level": {
+					Type:        "string",
+					Description: "Only return the entries of this level or above. Defaults to debug, which includes the output of subprocesses",
+					Enum:        []any{"debug", "info", "warn", "error"},
+				},
+				"tail": {
+					Type:        "integer",
+					Description: "Only return the last entries, all of them when 0. Defaults to 0",
+				},
+			},
+		},
+		OutputSchema: outputSchema[RunLogResult](),
+	}
+
+	getRunLogHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		runID, _ := params.Arguments["run_id"].(string)
+		tail, _ := params.Arguments["tail"].(float64)
+		minLevel := slog.LevelDebug
+		if level, _ := params.Arguments["level"].(string); level != "" {
+			if err := minLevel.UnmarshalText([]byte(level)); err != nil {
+				return nil, fmt.Errorf("invalid level %q, expected debug, info, warn or error", level)
+			}
+		}
+
+		var result RunLogResult
+		var err error
+		if runID == "" {
+			result.Runs, err = listRuns()
+		} else {
+			result, err = readRunLog(runID, minLevel, int(tail))
+		}
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to get run log: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatRunLog(result)}},
+			StructuredContent: result,
+		}, nil
+	}
+
+	s.AddTool(runLogTool, getRunLogHandler)
+
+	// Register session-defaults tool
+	sessionDefaultsTool := &mcp.Tool{
+		Name:        "session-defaults",
+		Description: "Shows the arguments remembered from the tool calls of this session, which are filled into later calls omitting them, or clears them",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"clear": {
+					Type:        "boolean",
+					Description: "Forget the remembered arguments",
+				},
+			},
+		},
+		OutputSchema: outputSchema[SessionDefaultsResult](),
+	}
+
+	sessionDefaultsHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		result := SessionDefaultsResult{Defaults: map[string]string{}}
+		if clear, _ := params.Arguments["clear"].(bool); clear {
+			sessions.clearDefaults(session)
+			result.Cleared = true
+		} else if defaults := sessions.defaults(session); defaults != nil {
+			result.Defaults = defaults
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatSessionDefaults(result)}},
+			StructuredContent: result,
+		}, nil
+	}
+
+	s.AddTool(sessionDefaultsTool, sessionDefaultsHandler)
+
+	// Register generate-upgrade-test-matrix tool
+	upgradeMatrixTool := &mcp.Tool{
+		Name:        "generate-upgrade-test-matrix",
+		Description: "Computes the supported upgrade paths into a new version, from earlier patches of its minor and the latest patch of the previous supported minors, on the OCP versions both versions are released on, as a matrix for QE automation",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"minor_version": {
+					Type:        "string",
+					Description: "Minor version upgraded to (e.g., '1.21')",
+				},
+				"patch_version": {
+					Type:        "string",
+					Description: "Patch version upgraded to (e.g., '3' for 1.21.3). Defaults to 0, a minor release",
+				},
+				"previous_minors": {
+					Type:        "integer",
+					Description: fmt.Sprintf("How many previous minor versions upgrades are supported from. Defaults to %d", defaultUpgradeMinors),
+				},
+				"ocp_versions": {
+					Type: "array",
+					Items: &jsonschema.Schema{
+						Type: "string",
+					},
+					Description: "OCP versions the new version is released on (e.g., ['4-16', '4-17']). Defaults to the configured OCP versions",
+				},
+			},
+			Required: []string{"minor_version"},
+		},
+		OutputSchema: outputSchema[UpgradeMatrixResult](),
+	}
+
+	upgradeMatrixHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		minorVersion, ok := params.Arguments["minor_version"].(string)
+		if !ok || minorVersion == "" {
+			return nil, fmt.Errorf("minor_version parameter is required")
+		}
+
+		repoPath, err := runDir(session, "konflux-release-data-upgrade-matrix")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create working directory: %w", err)
+		}
+		defer os.RemoveAll(repoPath)
+
+		config := UpgradeMatrixConfig{
+			MinorVersion: minorVersion,
+			RepoPath:     repoPath,
+			AsOf:         time.Now(),
+		}
+		config.PatchVersion, _ = params.Arguments["patch_version"].(string)
+		if previous, ok := params.Arguments["previous_minors"].(float64); ok {
+			config.PreviousMinors = int(previous)
+		}
+		if versions, ok := params.Arguments["ocp_versions"].([]interface{}); ok {
+			for _, v := range versions {
+				if strVal, ok := v.(string); ok {
+					config.OCPVersions = append(config.OCPVersions, strVal)
+				}
+			}
+		}
+		if len(config.OCPVersions) == 0 {
+			config.OCPVersions = configuredOCPVersions()
+		}
+
+		matrix, err := generateUpgradeMatrix(ctx, config)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to generate upgrade test matrix: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatUpgradeMatrix(matrix)}},
+			StructuredContent: matrix,
+		}, nil
+	}
+
+	s.AddTool(streamCommands(upgradeMatrixTool), upgradeMatrixHandler)
+
+	// Register release-risk-report tool
+	riskTool := &mcp.Tool{
+		Name:        "release-risk-report",
+		Description: "Scores the risk of every component of a release from late branch drift, failing builds, open blockers, CVEs to fix and missing sign-offs, with the explanation of each score, to help decide whether to slip the release date",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"minor_version": {
+					Type:        "string",
+					Description: "Minor version of the release (e.g., '1.21')",
+				},
+				"cves": {
+					Type: "array",
+					Items: &jsonschema.Schema{
+						Type: "object",
+						Properties: map[string]*jsonschema.Schema{
+							"key":       {Type: "string", Description: "CVE identifier (e.g., 'CVE-2025-1234')"},
+							"component": {Type: "string", Description: "Release repository the CVE is to be fixed in (e.g., 'pipeline')"},
+						},
+						Required: []string{"key", "component"},
+					},
+					Description: "CVEs still to be fixed in the release",
+				},
+				"sign_offs": {
+					Type: "array",
+					Items: &jsonschema.Schema{
+						Type: "string",
+					},
+					Description: "Release repositories whose owners signed off (e.g., ['pipeline', 'triggers']). Sign-offs are not assessed when omitted",
+				},
+			},
+			Required: []string{"minor_version"},
+		},
+		OutputSchema: outputSchema[ReleaseRiskResult](),
+	}
+
+	riskHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		minorVersion, ok := params.Arguments["minor_version"].(string)
+		if !ok || minorVersion == "" {
+			return nil, fmt.Errorf("minor_version parameter is required")
+		}
+
+		config := ReleaseRiskConfig{
+			MinorVersion: minorVersion,
+			GitHubOrgs:   []string{product().Organization},
+		}
+		if os.Getenv("JIRA_TOKEN") != "" {
+			config.JiraProjects = productJiraProjects()
+		}
+		if cves, ok := params.Arguments["cves"].([]interface{}); ok {
+			for _, item := range cves {
+				if c, ok := item.(map[string]interface{}); ok {
+					key, _ := c["key"].(string)
+					component, _ := c["component"].(string)
+					config.CVEs = append(config.CVEs, CVE{Key: key, Component: component})
+				}
+			}
+		}
+		if signOffs, ok := params.Arguments["sign_offs"].([]interface{}); ok {
+			config.SignOffs = []string{}
+			for _, v := range signOffs {
+				if strVal, ok := v.(string); ok {
+					config.SignOffs = append(config.SignOffs, strVal)
+				}
+			}
+		}
+
+		risk, err := assessReleaseRisk(ctx, config)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to assess release risk: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatReleaseRisk(risk)}},
+			StructuredContent: risk,
+		}, nil
+	}
+
+	s.AddTool(riskTool, riskHandler)
+
+	// Register summarize-release-for-leadership tool
+	leadershipSummaryTool := &mcp.Tool{
+		Name:        "summarize-release-for-leadership",
+		Description: "Condenses the release record of a version, its calendar and the audit log of the calls made for it, into a Markdown executive summary: dates, progress, scope, notable fixes, risks and links to merge requests and advisories",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"minor_version": {
+					Type:        "string",
+					Description: "Minor version to summarize (e.g., '1.21')",
+				},
+				"patch_version": {
+					Type:        "string",
+					Description: "Patch version of a z-stream to summarize instead of the minor release",
+				},
+			},
+			Required: []string{"minor_version"},
+		},
+		OutputSchema: outputSchema[LeadershipSummaryResult](),
+	}
+
+	leadershipSummaryHandler := func(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		minorVersion, ok := params.Arguments["minor_version"].(string)
+		if !ok || minorVersion == "" {
+			return nil, fmt.Errorf("minor_version parameter is required")
+		}
+		patchVersion, _ := params.Arguments["patch_version"].(string)
+
+		summary, err := summarizeReleaseForLeadership(minorVersion, patchVersion, time.Now())
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to summarize release: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: summary.Markdown}},
+			StructuredContent: summary,
+		}, nil
+	}
+
+	s.AddTool(leadershipSummaryTool, leadershipSummaryHandler)
+
+	// Register release-status tool
+	releaseDashboardTool := &mcp.Tool{
+		Name:        "release-status",
+		Description: "Gathers where a version stands: which release repositories have its release branch, the state of the hack pull request and of the konflux-release-data merge request, which of its ReleasePlanAdmissions exist on the cluster and the outcome of the latest Release of each of its ReleasePlans",
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"minor_version": {
+					Type:        "string",
+					Description: "Minor version to report on (e.g., '1.21')",
+				},
+			},
+			Required: []string{"minor_version"},
+		},
+		OutputSchema: outputSchema[ReleaseDashboardResult](),
+	}
+
+	releaseDashboardHandler := func(reqCtx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[map[string]any]) (*mcp.CallToolResultFor[any], error) {
+		minorVersion, ok := params.Arguments["minor_version"].(string)
+		if !ok || minorVersion == "" {
+			return nil, fmt.Errorf("minor_version parameter is required")
+		}
+
+		dashboard, err := releaseDashboard(clusterContext(reqCtx, ctx), minorVersion)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to get release status: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+		return &mcp.CallToolResultFor[any]{
+			Content:           []mcp.Content{&mcp.TextContent{Text: formatReleaseDashboard(dashboard)}},
+			StructuredContent: dashboard,
+		}, nil
+	}
+
+	s.AddTool(releaseDashboardTool, releaseDashboardHandler)
+
+	// Scheduled tasks call the tools through the server. The long-running
+	// server starts running them with StartScheduler.
+	taskScheduler.server = s
+	return nil
+}
+
+// clusterContext returns the request context of a tool call carrying the
+// Kubernetes config injected in the server context, which the request context
+// does not carry
+func clusterContext(reqCtx, serverCtx context.Context) context.Context {
+	return injection.WithConfig(reqCtx, injection.GetConfig(serverCtx))
+}
+
+// errNoCluster is returned by the cluster tools when the server runs without
+// a Kubernetes config
+var errNoCluster = errors.New("no Kubernetes cluster is configured for this server")
+
+// dynamicClient returns a dynamic client of the cluster injected in ctx
+func dynamicClient(ctx context.Context) (*dynamic.DynamicClient, error) {
+	cfg := injection.GetConfig(ctx)
+	if cfg == nil {
+		return nil, errNoCluster
+	}
+	return dynamic.NewForConfig(cfg)
+}
+
+// kubeClient returns a typed client of the cluster injected in ctx
+func kubeClient(ctx context.Context) (*kubernetes.Clientset, error) {
+	cfg := injection.GetConfig(ctx)
+	if cfg == nil {
+		return nil, errNoCluster
+	}
+	return kubernetes.NewForConfig(cfg)
 }
 
 func result(s string) *mcp.CallToolResultFor[string] {
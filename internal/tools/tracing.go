@@ -0,0 +1,502 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	mcp "github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/embedded"
+)
+
+const (
+	// tracerName is the instrumentation scope of the spans of the tools
+	tracerName = "github.com/tektoncd/release-mcp/internal/tools"
+
+	// traceServiceName identifies the server in the exported spans
+	traceServiceName = "release-mcp-server"
+
+	// traceBatchSize and traceFlushInterval bound how long ended spans wait
+	// before they are exported
+	traceBatchSize     = 256
+	traceFlushInterval = 5 * time.Second
+)
+
+// tracer starts the spans of the tools. Spans are dropped until StartTracing
+// installs the OTLP exporter.
+var tracer = otel.Tracer(tracerName)
+
+var (
+	activeTracing   *otlpTracerProvider
+	activeTracingMu sync.Mutex
+)
+
+// StartTracing exports the spans of tool calls and of their steps to an
+// OTLP/HTTP collector. The endpoint defaults to $OTEL_EXPORTER_OTLP_TRACES_ENDPOINT,
+// or to the /v1/traces path of $OTEL_EXPORTER_OTLP_ENDPOINT, and tracing is
+// disabled when neither is set. Headers of $OTEL_EXPORTER_OTLP_HEADERS, such
+// as the API key of a hosted collector, are sent with every export.
+func StartTracing(endpoint string) error {
+	if endpoint == "" {
+		endpoint = os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")
+	}
+	if endpoint == "" {
+		if base := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); base != "" {
+			endpoint = strings.TrimSuffix(base, "/") + "/v1/traces"
+		}
+	}
+	if endpoint == "" {
+		return nil
+	}
+	if !strings.HasPrefix(endpoint, "http://") && !strings.HasPrefix(endpoint, "https://") {
+		return fmt.Errorf("OTLP endpoint %q must be an http or https URL", endpoint)
+	}
+	client, err := newHTTPClient(defaultHTTPClientConfig())
+	if err != nil {
+		return err
+	}
+
+	provider := &otlpTracerProvider{
+		endpoint: endpoint,
+		headers:  otlpHeaders(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS")),
+		client:   client,
+		flushC:   make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+	go provider.run()
+
+	activeTracingMu.Lock()
+	activeTracing = provider
+	activeTracingMu.Unlock()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return nil
+}
+
+// StopTracing exports the spans ended so far, before the process exits
+func StopTracing(ctx context.Context) {
+	activeTracingMu.Lock()
+	provider := activeTracing
+	activeTracing = nil
+	activeTracingMu.Unlock()
+	if provider == nil {
+		return
+	}
+	close(provider.done)
+	if err := provider.export(ctx); err != nil {
+		slog.Warn("Failed to export spans", "error", err)
+	}
+}
+
+// startSpan starts a span of a step of a tool call
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan ends a span, recording the error the step failed with
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// tracingMiddleware starts a span for every tool call, the parent of the
+// spans of its steps
+func tracingMiddleware(next mcp.MethodHandler[*mcp.ServerSession]) mcp.MethodHandler[*mcp.ServerSession] {
+	return func(ctx context.Context, session *mcp.ServerSession, method string, params mcp.Params) (mcp.Result, error) {
+		callParams, ok := params.(*mcp.CallToolParamsFor[json.RawMessage])
+		if method != "tools/call" || !ok {
+			return next(ctx, session, method, params)
+		}
+
+		var args map[string]any
+		_ = json.Unmarshal(callParams.Arguments, &args)
+		ctx, span := tracer.Start(ctx, "tools/call "+callParams.Name, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(
+			attribute.String("mcp.tool.name", callParams.Name),
+			attribute.String("mcp.session.id", session.ID()),
+			attribute.String("release.version", auditVersion(args)),
+		))
+		result, err := next(ctx, session, method, params)
+		if toolResult, ok := result.(*mcp.CallToolResult); ok && err == nil && toolResult.IsError {
+			span.SetStatus(codes.Error, "tool call failed")
+		}
+		endSpan(span, err)
+		return result, err
+	}
+}
+
+// commandSpanName names the span of a subprocess after its executable and,
+// for git, its subcommand, leaving out arguments that may hold secrets
+func commandSpanName(args []string) string {
+	if len(args) == 0 {
+		return "exec"
+	}
+	name := "exec " + filepath.Base(args[0])
+	if filepath.Base(args[0]) == "git" {
+		for i := 1; i < len(args); i++ {
+			switch {
+			case args[i] == "-C" || args[i] == "-c":
+				i++
+			case !strings.HasPrefix(args[i], "-"):
+				return name + " " + args[i]
+			}
+		}
+	}
+	return name
+}
+
+// otlpTracerProvider records spans and exports them in batches to an
+// OTLP/HTTP collector with the JSON encoding
+type otlpTracerProvider struct {
+	embedded.TracerProvider
+
+	endpoint string
+	headers  map[string]string
+	client   *http.Client
+
+	mu     sync.Mutex
+	ended  []*otlpSpan
+	flushC chan struct{}
+	done   chan struct{}
+}
+
+func (p *otlpTracerProvider) Tracer(name string, _ ...trace.TracerOption) trace.Tracer {
+	return &otlpTracer{provider: p, scope: name}
+}
+
+// run exports the ended spans periodically, or as soon as a batch is full
+func (p *otlpTracerProvider) run() {
+	ticker := time.NewTicker(traceFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+		case <-p.flushC:
+		}
+		if err := p.export(context.Background()); err != nil {
+			slog.Warn("Failed to export spans", "error", err)
+		}
+	}
+}
+
+func (p *otlpTracerProvider) record(span *otlpSpan) {
+	p.mu.Lock()
+	p.ended = append(p.ended, span)
+	full := len(p.ended) >= traceBatchSize
+	p.mu.Unlock()
+	if full {
+		select {
+		case p.flushC <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// export sends the ended spans to the collector. Spans failing to export are
+// dropped, so an unreachable collector does not grow the memory of the server.
+func (p *otlpTracerProvider) export(ctx context.Context) error {
+	p.mu.Lock()
+	spans := p.ended
+	p.ended = nil
+	p.mu.Unlock()
+	if len(spans) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(otlpRequest(spans))
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range p.headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("collector returned %s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
+
+type otlpTracer struct {
+	embedded.Tracer
+
+	provider *otlpTracerProvider
+	scope    string
+}
+
+func (t *otlpTracer) Start(ctx context.Context, name string, options ...trace.SpanStartOption) (context.Context, trace.Span) {
+	config := trace.NewSpanStartConfig(options...)
+	parent := trace.SpanContextFromContext(ctx)
+	if config.NewRoot() {
+		parent = trace.SpanContext{}
+	}
+	traceID := parent.TraceID()
+	if !parent.IsValid() {
+		_, _ = rand.Read(traceID[:])
+	}
+	var spanID trace.SpanID
+	_, _ = rand.Read(spanID[:])
+
+	start := config.Timestamp()
+	if start.IsZero() {
+		start = time.Now()
+	}
+	span := &otlpSpan{
+		tracer: t,
+		name:   name,
+		kind:   config.SpanKind(),
+		parent: parent.SpanID(),
+		start:  start,
+		attrs:  config.Attributes(),
+		context: trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    traceID,
+			SpanID:     spanID,
+			TraceFlags: trace.FlagsSampled,
+		}),
+	}
+	return trace.ContextWithSpan(ctx, span), span
+}
+
+type otlpSpanEvent struct {
+	name  string
+	time  time.Time
+	attrs []attribute.KeyValue
+}
+
+type otlpSpan struct {
+	embedded.Span
+
+	tracer  *otlpTracer
+	context trace.SpanContext
+	parent  trace.SpanID
+	kind    trace.SpanKind
+
+	mu            sync.Mutex
+	name          string
+	start, end    time.Time
+	attrs         []attribute.KeyValue
+	events        []otlpSpanEvent
+	status        codes.Code
+	statusMessage string
+	ended         bool
+}
+
+func (s *otlpSpan) End(options ...trace.SpanEndOption) {
+	config := trace.NewSpanEndConfig(options...)
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.end = config.Timestamp()
+	if s.end.IsZero() {
+		s.end = time.Now()
+	}
+	s.mu.Unlock()
+	s.tracer.provider.record(s)
+}
+
+func (s *otlpSpan) AddEvent(name string, options ...trace.EventOption) {
+	config := trace.NewEventConfig(options...)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ended {
+		s.events = append(s.events, otlpSpanEvent{name: name, time: config.Timestamp(), attrs: config.Attributes()})
+	}
+}
+
+func (s *otlpSpan) AddLink(trace.Link) {}
+
+func (s *otlpSpan) IsRecording() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !s.ended
+}
+
+func (s *otlpSpan) RecordError(err error, options ...trace.EventOption) {
+	if err == nil {
+		return
+	}
+	options = append(options, trace.WithAttributes(
+		attribute.String("exception.type", fmt.Sprintf("%T", err)),
+		attribute.String("exception.message", err.Error()),
+	))
+	s.AddEvent("exception", options...)
+}
+
+func (s *otlpSpan) SpanContext() trace.SpanContext { return s.context }
+
+func (s *otlpSpan) SetStatus(code codes.Code, description string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// An error status is not downgraded, and only errors carry a description
+	if s.ended || s.status == codes.Error && code != codes.Ok {
+		return
+	}
+	s.status, s.statusMessage = code, ""
+	if code == codes.Error {
+		s.statusMessage = description
+	}
+}
+
+func (s *otlpSpan) SetName(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.name = name
+}
+
+func (s *otlpSpan) SetAttributes(kv ...attribute.KeyValue) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ended {
+		s.attrs = append(s.attrs, kv...)
+	}
+}
+
+func (s *otlpSpan) TracerProvider() trace.TracerProvider { return s.tracer.provider }
+
+// otlpRequest encodes spans as an OTLP ExportTraceServiceRequest, grouped by
+// instrumentation scope
+func otlpRequest(spans []*otlpSpan) map[string]any {
+	scopes := map[string][]any{}
+	var order []string
+	for _, span := range spans {
+		scope := span.tracer.scope
+		if _, ok := scopes[scope]; !ok {
+			order = append(order, scope)
+		}
+		scopes[scope] = append(scopes[scope], span.otlp())
+	}
+	var scopeSpans []any
+	for _, scope := range order {
+		scopeSpans = append(scopeSpans, map[string]any{"scope": map[string]any{"name": scope}, "spans": scopes[scope]})
+	}
+	resource := otlpAttributes([]attribute.KeyValue{
+		attribute.String("service.name", traceServiceName),
+		attribute.String("service.version", currentGeneratorVersion()),
+	})
+	return map[string]any{"resourceSpans": []any{map[string]any{"resource": map[string]any{"attributes": resource}, "scopeSpans": scopeSpans}}}
+}
+
+func (s *otlpSpan) otlp() map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	span := map[string]any{
+		"traceId":           s.context.TraceID().String(),
+		"spanId":            s.context.SpanID().String(),
+		"name":              s.name,
+		"kind":              otlpSpanKind(s.kind),
+		"startTimeUnixNano": strconv.FormatInt(s.start.UnixNano(), 10),
+		"endTimeUnixNano":   strconv.FormatInt(s.end.UnixNano(), 10),
+		"attributes":        otlpAttributes(s.attrs),
+		"status":            map[string]any{"code": otlpStatusCode(s.status), "message": s.statusMessage},
+	}
+	if s.parent.IsValid() {
+		span["parentSpanId"] = hex.EncodeToString(s.parent[:])
+	}
+	var events []any
+	for _, event := range s.events {
+		events = append(events, map[string]any{
+			"name":         event.name,
+			"timeUnixNano": strconv.FormatInt(event.time.UnixNano(), 10),
+			"attributes":   otlpAttributes(event.attrs),
+		})
+	}
+	if len(events) > 0 {
+		span["events"] = events
+	}
+	return span
+}
+
+func otlpAttributes(attrs []attribute.KeyValue) []any {
+	encoded := make([]any, 0, len(attrs))
+	for _, kv := range attrs {
+		encoded = append(encoded, map[string]any{"key": string(kv.Key), "value": otlpValue(kv.Value)})
+	}
+	return encoded
+}
+
+func otlpValue(value attribute.Value) map[string]any {
+	switch value.Type() {
+	case attribute.BOOL:
+		return map[string]any{"boolValue": value.AsBool()}
+	case attribute.INT64:
+		return map[string]any{"intValue": strconv.FormatInt(value.AsInt64(), 10)}
+	case attribute.FLOAT64:
+		return map[string]any{"doubleValue": value.AsFloat64()}
+	case attribute.STRINGSLICE:
+		var values []any
+		for _, v := range value.AsStringSlice() {
+			values = append(values, map[string]any{"stringValue": v})
+		}
+		return map[string]any{"arrayValue": map[string]any{"values": values}}
+	}
+	return map[string]any{"stringValue": value.Emit()}
+}
+
+// otlpSpanKind maps a span kind to its OTLP enum value, which matches the
+// trace API one
+func otlpSpanKind(kind trace.SpanKind) int {
+	if kind == trace.SpanKindUnspecified {
+		kind = trace.SpanKindInternal
+	}
+	return int(kind)
+}
+
+// otlpStatusCode maps a status code to its OTLP enum value, in which Ok and
+// Error are swapped
+func otlpStatusCode(code codes.Code) int {
+	switch code {
+	case codes.Ok:
+		return 1
+	case codes.Error:
+		return 2
+	}
+	return 0
+}
+
+// otlpHeaders parses the comma separated key=value pairs of $OTEL_EXPORTER_OTLP_HEADERS
+func otlpHeaders(value string) map[string]string {
+	headers := map[string]string{}
+	for _, pair := range strings.Split(value, ",") {
+		key, val, ok := strings.Cut(pair, "=")
+		if key = strings.TrimSpace(key); ok && key != "" {
+			headers[key] = strings.TrimSpace(val)
+		}
+	}
+	return headers
+}
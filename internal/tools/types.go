@@ -2,10 +2,10 @@ package tools
 
 // Repository represents a Git repository configuration
 type Repository struct {
-	Name         string
-	SourceBranch string
-	Skip         bool
-	RepoURL      string
+	Name         string `yaml:"name" json:"name"`
+	SourceBranch string `yaml:"source_branch,omitempty" json:"source_branch,omitempty"`
+	Skip         bool   `yaml:"skip,omitempty" json:"skip,omitempty"`
+	RepoURL      string `yaml:"repo_url,omitempty" json:"repo_url,omitempty"`
 }
 
 // Config holds the configuration for branch creation
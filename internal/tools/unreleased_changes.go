@@ -0,0 +1,194 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// commitsPageSize is the number of commits requested per page of the commits API
+const commitsPageSize = 100
+
+// MergedCommit represents a commit merged into a branch
+type MergedCommit struct {
+	SHA     string    `json:"sha"`
+	Subject string    `json:"subject"`
+	Author  string    `json:"author,omitempty"`
+	Date    time.Time `json:"date,omitzero"`
+}
+
+// ComponentChanges represents the commits of a release branch not shipped in a z-stream yet
+type ComponentChanges struct {
+	Repository string         `json:"repository"`
+	LastTag    string         `json:"last_tag,omitempty" jsonschema:"Latest z-stream tag of the minor version the commits are compared to"`
+	TagSource  string         `json:"tag_source,omitempty" jsonschema:"repository when the tag is the latest of the repository, operator when the repository has none and the commits since the operator tag date are listed"`
+	Commits    []MergedCommit `json:"commits,omitempty" jsonschema:"Commits merged since the tag, oldest first"`
+	Error      string         `json:"error,omitempty"`
+}
+
+// UnreleasedChangesResult represents the structured result of find-unreleased-merged-changes
+type UnreleasedChangesResult struct {
+	MinorVersion  string             `json:"minor_version"`
+	Branch        string             `json:"branch"`
+	LatestRelease string             `json:"latest_release,omitempty" jsonschema:"Latest z-stream tagged in the operator repository"`
+	Components    []ComponentChanges `json:"components"`
+	Changed       int                `json:"changed" jsonschema:"Components with unreleased commits"`
+	Warranted     bool               `json:"warranted" jsonschema:"Whether any component has unreleased commits, so a new z-stream would ship changes"`
+}
+
+// findUnreleasedChanges lists, for every release repository, the commits
+// merged into the release branch of the minor version since the last z-stream
+// tagged in the repository. The operator tag does not exist in the other
+// repositories, so those without a tag of the minor version list the commits
+// of the branch since the operator tag was cut.
+func findUnreleasedChanges(ctx context.Context, minorVersion string, repositories []string) (UnreleasedChangesResult, error) {
+	result := UnreleasedChangesResult{MinorVersion: minorVersion, Branch: fmt.Sprintf("release-v%s.x", minorVersion)}
+	gh, err := newGitHubClient()
+	if err != nil {
+		return result, err
+	}
+
+	var operatorTagDate time.Time
+	if patches, err := releasedPatchVersions(ctx, gh, operatorRepository(), minorVersion, -1); err != nil {
+		return result, err
+	} else if len(patches) > 0 {
+		result.LatestRelease = fmt.Sprintf("%s.%d", minorVersion, patches[len(patches)-1])
+		if operatorTagDate, err = commitDate(ctx, gh, operatorRepository(), "v"+result.LatestRelease); err != nil {
+			return result, err
+		}
+	}
+
+	selected := make(map[string]bool)
+	for _, repo := range repositories {
+		selected[repo] = true
+	}
+	for _, repo := range releaseRepositories() {
+		name := forgeRepoPath(repo.RepoURL)
+		if repo.Skip || repo.Name == "hack" {
+			continue
+		}
+		if len(selected) > 0 && !selected[repo.Name] && !selected[name] {
+			continue
+		}
+
+		changes := ComponentChanges{Repository: name}
+		patches, err := releasedPatchVersions(ctx, gh, name, minorVersion, -1)
+		switch {
+		case err != nil:
+			changes.Error = err.Error()
+		case len(patches) > 0:
+			changes.LastTag, changes.TagSource = fmt.Sprintf("v%s.%d", minorVersion, patches[len(patches)-1]), "repository"
+			if changes.Commits, err = compareCommits(ctx, gh, name, changes.LastTag, result.Branch); err != nil {
+				changes.Error = err.Error()
+			}
+		case !operatorTagDate.IsZero():
+			changes.LastTag, changes.TagSource = "v"+result.LatestRelease, "operator"
+			if changes.Commits, err = branchCommitsSince(ctx, gh, name, result.Branch, operatorTagDate); err != nil {
+				changes.Error = err.Error()
+			}
+		default:
+			changes.Error = fmt.Sprintf("no tag: %s was never released, neither %s nor %s has a tag of it", minorVersion, name, operatorRepository())
+		}
+		if len(changes.Commits) > 0 {
+			result.Changed++
+		}
+		result.Components = append(result.Components, changes)
+	}
+	if len(selected) > 0 && len(result.Components) == 0 {
+		return result, fmt.Errorf("none of %s is a release repository", strings.Join(repositories, ", "))
+	}
+	result.Warranted = result.Changed > 0
+	return result, nil
+}
+
+// commitDate returns the committer date of the commit a ref points to
+func commitDate(ctx context.Context, gh *githubClient, repo, ref string) (time.Time, error) {
+	var commit struct {
+		Commit struct {
+			Committer struct {
+				Date time.Time `json:"date"`
+			} `json:"committer"`
+		} `json:"commit"`
+	}
+	if err := gh.do(ctx, http.MethodGet, fmt.Sprintf("/repos/%s/commits/%s", repo, url.PathEscape(ref)), nil, &commit); err != nil {
+		return time.Time{}, fmt.Errorf("failed to resolve %s@%s: %w", repo, ref, err)
+	}
+	return commit.Commit.Committer.Date, nil
+}
+
+// branchCommitsSince lists the commits of branch committed after since,
+// oldest first, reading every page of the commits API
+func branchCommitsSince(ctx context.Context, gh *githubClient, repo, branch string, since time.Time) ([]MergedCommit, error) {
+	var commits []MergedCommit
+	for page := 1; ; page++ {
+		var pageCommits []struct {
+			SHA    string `json:"sha"`
+			Commit struct {
+				Message string `json:"message"`
+				Author  struct {
+					Name string    `json:"name"`
+					Date time.Time `json:"date"`
+				} `json:"author"`
+			} `json:"commit"`
+		}
+		apiPath := fmt.Sprintf("/repos/%s/commits?per_page=%d&page=%d&sha=%s&since=%s", repo, commitsPageSize, page, url.QueryEscape(branch), url.QueryEscape(since.Format(time.RFC3339)))
+		if err := gh.do(ctx, http.MethodGet, apiPath, nil, &pageCommits); err != nil {
+			return nil, fmt.Errorf("failed to list commits of %s: %w", repo, err)
+		}
+		for _, commit := range pageCommits {
+			commits = append(commits, MergedCommit{
+				SHA:     commit.SHA,
+				Subject: strings.SplitN(commit.Commit.Message, "\n", 2)[0],
+				Author:  commit.Commit.Author.Name,
+				Date:    commit.Commit.Author.Date,
+			})
+		}
+		if len(pageCommits) < commitsPageSize {
+			break
+		}
+	}
+	// The API lists the newest commits first
+	for i, j := 0, len(commits)-1; i < j; i, j = i+1, j-1 {
+		commits[i], commits[j] = commits[j], commits[i]
+	}
+	return commits, nil
+}
+
+func formatUnreleasedChanges(result UnreleasedChangesResult) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Changes merged into %s since the last z-stream", result.Branch)
+	if result.LatestRelease != "" {
+		fmt.Fprintf(&sb, " (latest release %s)", result.LatestRelease)
+	}
+	sb.WriteString(":\n")
+	for _, changes := range result.Components {
+		switch {
+		case changes.Error != "":
+			fmt.Fprintf(&sb, "\n✗ %s: %s\n", changes.Repository, changes.Error)
+		case len(changes.Commits) == 0:
+			fmt.Fprintf(&sb, "\n- %s: nothing since %s\n", changes.Repository, changes.LastTag)
+		default:
+			fmt.Fprintf(&sb, "\n● %s: %d commits since %s", changes.Repository, len(changes.Commits), changes.LastTag)
+			if changes.TagSource == "operator" {
+				sb.WriteString(" (date of the operator tag)")
+			}
+			sb.WriteString("\n")
+			for _, commit := range changes.Commits {
+				fmt.Fprintf(&sb, "  %s %s", commit.SHA[:min(len(commit.SHA), 8)], commit.Subject)
+				if commit.Author != "" {
+					fmt.Fprintf(&sb, " (%s)", commit.Author)
+				}
+				sb.WriteString("\n")
+			}
+		}
+	}
+	if result.Warranted {
+		fmt.Fprintf(&sb, "\n%d of %d components have unreleased changes, a new z-stream would ship them\n", result.Changed, len(result.Components))
+	} else {
+		sb.WriteString("\nNo unreleased changes, a new z-stream is not needed\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,200 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultUpgradeMinors is how many previous minor versions upgrades into a new minor are supported from
+const defaultUpgradeMinors = 2
+
+// UpgradeMatrixConfig represents the configuration for computing the upgrade test matrix
+type UpgradeMatrixConfig struct {
+	MinorVersion   string
+	PatchVersion   string // Defaults to 0, a minor release
+	PreviousMinors int    // Defaults to defaultUpgradeMinors
+	OCPVersions    []string
+	RepoPath       string
+	AsOf           time.Time
+}
+
+// UpgradePath represents a supported upgrade into the new version
+type UpgradePath struct {
+	From        string   `json:"from"`
+	To          string   `json:"to"`
+	Kind        string   `json:"kind" jsonschema:"z-stream from an earlier patch of the same minor, or minor from a previous minor"`
+	OCPVersions []string `json:"ocp_versions" jsonschema:"OCP versions both versions are released on"`
+}
+
+// UpgradeMatrixEntry represents a single upgrade test
+type UpgradeMatrixEntry struct {
+	From       string `json:"from"`
+	To         string `json:"to"`
+	OCPVersion string `json:"ocp_version"`
+}
+
+// SkippedUpgradeSource represents a version upgrades are not tested from
+type SkippedUpgradeSource struct {
+	Version string `json:"version"`
+	Reason  string `json:"reason"`
+}
+
+// UpgradeMatrixResult represents the structured result of generate-upgrade-test-matrix
+type UpgradeMatrixResult struct {
+	Version string                 `json:"version"`
+	Paths   []UpgradePath          `json:"paths"`
+	Matrix  []UpgradeMatrixEntry   `json:"matrix" jsonschema:"One entry per upgrade path and OCP version, for test automation"`
+	Skipped []SkippedUpgradeSource `json:"skipped,omitempty"`
+}
+
+// generateUpgradeMatrix computes the upgrade paths into the new version: from
+// every earlier patch of its minor and from the latest patch of the previous
+// minors still supported, on the OCP versions both versions are released on.
+// Released versions are read from the operator tags and the OCP versions of a
+// minor from its prod FBC RPA.
+func generateUpgradeMatrix(ctx context.Context, config UpgradeMatrixConfig) (UpgradeMatrixResult, error) {
+	var result UpgradeMatrixResult
+
+	major, minor, ok := strings.Cut(config.MinorVersion, ".")
+	minorNumber, err := strconv.Atoi(minor)
+	if !ok || err != nil {
+		return result, fmt.Errorf("invalid minor version %q", config.MinorVersion)
+	}
+	patch := 0
+	if config.PatchVersion != "" {
+		if patch, err = strconv.Atoi(config.PatchVersion); err != nil {
+			return result, fmt.Errorf("invalid patch version %q", config.PatchVersion)
+		}
+	}
+	if config.PreviousMinors <= 0 {
+		config.PreviousMinors = defaultUpgradeMinors
+	}
+	result.Version = fmt.Sprintf("%s.%d", config.MinorVersion, patch)
+
+	gh, err := newGitHubClient()
+	if err != nil {
+		return result, err
+	}
+	if err := cloneKonfluxRepo(ctx, RPAConfig{RepoPath: config.RepoPath}); err != nil {
+		return result, fmt.Errorf("failed to clone konflux-release-data repository: %w", err)
+	}
+	calendar, err := loadReleaseCalendar()
+	if err != nil {
+		return result, err
+	}
+
+	targetOCP := make([]string, 0, len(config.OCPVersions))
+	for _, ocp := range config.OCPVersions {
+		targetOCP = append(targetOCP, strings.ReplaceAll(ocp, ".", "-"))
+	}
+
+	addPath := func(from, kind string, sourceOCP []string) {
+		var common []string
+		for _, ocp := range targetOCP {
+			for _, source := range sourceOCP {
+				if ocp == source {
+					common = append(common, ocp)
+				}
+			}
+		}
+		sort.Slice(common, func(i, j int) bool { return ocpVersionLess(common[i], common[j]) })
+		if len(common) == 0 {
+			result.Skipped = append(result.Skipped, SkippedUpgradeSource{Version: from, Reason: "not released on any OCP version of " + result.Version})
+			return
+		}
+		result.Paths = append(result.Paths, UpgradePath{From: from, To: result.Version, Kind: kind, OCPVersions: common})
+		for _, ocp := range common {
+			result.Matrix = append(result.Matrix, UpgradeMatrixEntry{From: from, To: result.Version, OCPVersion: ocp})
+		}
+	}
+
+	// Earlier patches of the same minor upgrade directly to the new one
+	if patch > 0 {
+		patches, err := releasedPatchVersions(ctx, gh, operatorRepository(), config.MinorVersion, patch)
+		if err != nil {
+			return result, err
+		}
+		sourceOCP, err := fbcOCPVersions(config.RepoPath, config.MinorVersion)
+		if err != nil {
+			return result, err
+		}
+		if len(sourceOCP) == 0 {
+			sourceOCP = targetOCP
+		}
+		for _, p := range patches {
+			addPath(fmt.Sprintf("%s.%d", config.MinorVersion, p), "z-stream", sourceOCP)
+		}
+	}
+
+	// The latest patch of every previous minor still supported upgrades to the new version
+	for i := 1; i <= config.PreviousMinors && minorNumber-i >= 0; i++ {
+		source := fmt.Sprintf("%s.%d", major, minorNumber-i)
+		if calendar != nil && calendar.Releases[source].GA != "" {
+			entries, err := computeEOL(EOLConfig{GADates: map[string]string{source: calendar.Releases[source].GA}, AsOf: config.AsOf})
+			if err != nil {
+				return result, err
+			}
+			if entries[0].EndOfLife {
+				result.Skipped = append(result.Skipped, SkippedUpgradeSource{Version: source, Reason: "end of life since " + entries[0].EOLDate})
+				continue
+			}
+		}
+
+		patches, err := releasedPatchVersions(ctx, gh, operatorRepository(), source, -1)
+		if err != nil {
+			return result, err
+		}
+		if len(patches) == 0 {
+			result.Skipped = append(result.Skipped, SkippedUpgradeSource{Version: source, Reason: "no release tagged in " + operatorRepository()})
+			continue
+		}
+		sourceOCP, err := fbcOCPVersions(config.RepoPath, source)
+		if err != nil {
+			return result, err
+		}
+		addPath(fmt.Sprintf("%s.%d", source, patches[len(patches)-1]), "minor", sourceOCP)
+	}
+	return result, nil
+}
+
+// fbcOCPVersions returns the OCP versions the prod FBC RPA of a minor version releases to
+func fbcOCPVersions(konfluxPath, minorVersion string) ([]string, error) {
+	rpaPath := filepath.Join(konfluxPath, productRPADir(), productName(minorVersion, "fbc", "prod")+".yaml")
+	apps, err := rpaApplications(rpaPath)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix, suffix := product().Prefix+"-index-", "-"+minorVersion
+	var versions []string
+	for _, app := range apps {
+		if strings.HasPrefix(app, prefix) && strings.HasSuffix(app, suffix) {
+			versions = append(versions, strings.TrimSuffix(strings.TrimPrefix(app, prefix), suffix))
+		}
+	}
+	return versions, nil
+}
+
+func formatUpgradeMatrix(result UpgradeMatrixResult) string {
+	var sb strings.Builder
+	if len(result.Paths) == 0 {
+		fmt.Fprintf(&sb, "No supported upgrade path into %s\n", result.Version)
+	} else {
+		fmt.Fprintf(&sb, "Upgrade paths into %s (%d tests):\n", result.Version, len(result.Matrix))
+		for _, path := range result.Paths {
+			fmt.Fprintf(&sb, "- %s -> %s (%s) on OCP %s\n", path.From, path.To, path.Kind, strings.Join(path.OCPVersions, ", "))
+		}
+	}
+	if len(result.Skipped) > 0 {
+		sb.WriteString("\nNot tested:\n")
+		for _, skipped := range result.Skipped {
+			fmt.Fprintf(&sb, "- %s: %s\n", skipped.Version, skipped.Reason)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,244 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	mcp "github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// RunUsage represents how much work a single tool call did
+type RunUsage struct {
+	ReposCloned       int     `json:"repos_cloned"`
+	BytesTransferred  int64   `json:"bytes_transferred" jsonschema:"Bytes received by clones and API responses"`
+	FilesWritten      int     `json:"files_written"`
+	APICalls          int     `json:"api_calls"`
+	SubprocessSeconds float64 `json:"subprocess_seconds"`
+
+	Retries []RetriedOperation `json:"retries,omitempty" jsonschema:"Network operations that needed more than one attempt"`
+}
+
+// runUsage accounts the usage of a tool call across goroutines
+type runUsage struct {
+	mu    sync.Mutex
+	usage RunUsage
+}
+
+type runUsageKey struct{}
+
+// withRunUsage returns a context accounting the work of a tool call
+func withRunUsage(ctx context.Context) (context.Context, *runUsage) {
+	usage := &runUsage{}
+	return context.WithValue(ctx, runUsageKey{}, usage), usage
+}
+
+// runUsageFrom returns the usage of the tool call of the context. The
+// methods of the returned usage are no-ops when there is none.
+func runUsageFrom(ctx context.Context) *runUsage {
+	usage, _ := ctx.Value(runUsageKey{}).(*runUsage)
+	return usage
+}
+
+func (u *runUsage) recordClone(repoPath string) {
+	if u == nil {
+		return
+	}
+	size := dirSize(filepath.Join(repoPath, ".git"))
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.usage.ReposCloned++
+	u.usage.BytesTransferred += size
+}
+
+func (u *runUsage) recordAPICall(responseBytes int) {
+	if u == nil {
+		return
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.usage.APICalls++
+	u.usage.BytesTransferred += int64(responseBytes)
+}
+
+func (u *runUsage) recordFileWritten() {
+	if u == nil {
+		return
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.usage.FilesWritten++
+}
+
+func (u *runUsage) recordSubprocess(d time.Duration) {
+	if u == nil {
+		return
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.usage.SubprocessSeconds += d.Seconds()
+}
+
+func (u *runUsage) snapshot() RunUsage {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	usage := u.usage
+	usage.Retries = slices.Clone(u.usage.Retries)
+	return usage
+}
+
+func (usage RunUsage) String() string {
+	s := fmt.Sprintf("Usage: %d repos cloned, %s transferred, %d files written, %d API calls, %.1fs in subprocesses",
+		usage.ReposCloned, formatBytes(usage.BytesTransferred), usage.FilesWritten, usage.APICalls, usage.SubprocessSeconds)
+	if len(usage.Retries) == 0 {
+		return s
+	}
+	var retries []string
+	for _, retried := range usage.Retries {
+		if retried.Succeeded {
+			retries = append(retries, fmt.Sprintf("%s succeeded after %d attempts", retried.Operation, retried.Attempts))
+		} else {
+			retries = append(retries, fmt.Sprintf("%s failed after %d attempts: %s", retried.Operation, retried.Attempts, retried.LastError))
+		}
+	}
+	return s + "\nRetried: " + strings.Join(retries, "; ")
+}
+
+// runCommand runs a subprocess and accounts its duration to the tool call.
+// Git pushes are scanned for secrets first and the environment is restricted
+// to the variables the executable needs. The output of verbose tool calls is
+// streamed to the client, the output of background jobs is kept in their logs
+// and the output of every tool call in its run log.
+// Git commands talking to a remote are retried on network failures, see runRetried.
+// Branches pushed are published as BranchPushed events.
+func runCommand(ctx context.Context, cmd *exec.Cmd) (err error) {
+	if err := checkPushForSecrets(ctx, cmd); err != nil {
+		return err
+	}
+	callerStderr := cmd.Stderr
+	ctx, span := startSpan(ctx, commandSpanName(cmd.Args), attribute.String("process.working_directory", cmd.Dir))
+	defer func() { endSpan(span, err) }()
+	restrictEnv(cmd)
+	if stream := commandStreamFrom(ctx); stream != nil {
+		defer stream.attach(cmd, false)()
+	}
+	if j := jobFrom(ctx); j != nil {
+		defer j.attach(cmd, false)()
+	}
+	defer attachRunLog(ctx, cmd, false)()
+	start := time.Now()
+	err = runRetried(ctx, cmd, callerStderr, (*exec.Cmd).Run)
+	runUsageFrom(ctx).recordSubprocess(time.Since(start))
+	if err != nil {
+		return err
+	}
+	for _, branch := range resolvePush(ctx, cmd) {
+		_ = publish(ctx, Event{Type: EventBranchPushed, Data: branch})
+	}
+	return nil
+}
+
+// commandOutput runs a subprocess, accounting its duration, and returns its
+// standard output. Only the standard error of verbose tool calls is streamed.
+func commandOutput(ctx context.Context, cmd *exec.Cmd) (output []byte, err error) {
+	_, span := startSpan(ctx, commandSpanName(cmd.Args), attribute.String("process.working_directory", cmd.Dir))
+	defer func() { endSpan(span, err) }()
+	callerStderr := cmd.Stderr
+	restrictEnv(cmd)
+	if stream := commandStreamFrom(ctx); stream != nil {
+		defer stream.attach(cmd, true)()
+	}
+	if j := jobFrom(ctx); j != nil {
+		defer j.attach(cmd, true)()
+	}
+	defer attachRunLog(ctx, cmd, true)()
+	start := time.Now()
+	defer func() { runUsageFrom(ctx).recordSubprocess(time.Since(start)) }()
+	err = runRetried(ctx, cmd, callerStderr, func(attempt *exec.Cmd) error {
+		output, err = attempt.Output()
+		return err
+	})
+	return output, err
+}
+
+// writeFile writes a file and accounts it to the tool call
+func writeFile(ctx context.Context, path string, data []byte, perm os.FileMode) error {
+	if err := os.WriteFile(path, data, perm); err != nil {
+		return err
+	}
+	runUsageFrom(ctx).recordFileWritten()
+	runArtifactsFrom(ctx).recordFile(path)
+	return nil
+}
+
+// createFile creates a file and accounts it to the tool call
+func createFile(ctx context.Context, path string) (*os.File, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	runUsageFrom(ctx).recordFileWritten()
+	runArtifactsFrom(ctx).recordFile(path)
+	return file, nil
+}
+
+func dirSize(dir string) int64 {
+	var size int64
+	_ = filepath.WalkDir(dir, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return nil
+		}
+		if info, err := entry.Info(); err == nil {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// usageMiddleware accounts the work of every tool call and reports it in the
+// result, as text and under the usage key of the result metadata
+func usageMiddleware(next mcp.MethodHandler[*mcp.ServerSession]) mcp.MethodHandler[*mcp.ServerSession] {
+	return func(ctx context.Context, session *mcp.ServerSession, method string, params mcp.Params) (mcp.Result, error) {
+		if _, ok := params.(*mcp.CallToolParamsFor[json.RawMessage]); method != "tools/call" || !ok {
+			return next(ctx, session, method, params)
+		}
+
+		ctx, usage := withRunUsage(ctx)
+		result, err := next(ctx, session, method, params)
+		toolResult, ok := result.(*mcp.CallToolResult)
+		if err != nil || !ok {
+			return result, err
+		}
+
+		snapshot := usage.snapshot()
+		if toolResult.Meta == nil {
+			toolResult.Meta = mcp.Meta{}
+		}
+		toolResult.Meta["usage"] = snapshot
+		toolResult.Content = append(toolResult.Content, &mcp.TextContent{Text: snapshot.String()})
+		return toolResult, nil
+	}
+}
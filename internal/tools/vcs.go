@@ -0,0 +1,511 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// errBranchNotFound is returned when deleting a branch that does not exist
+var errBranchNotFound = errors.New("branch not found")
+
+// changeRequestsPageSize is the number of pull or merge requests requested per page
+const changeRequestsPageSize = 100
+
+// Pipeline states reported by VCS providers
+const (
+	pipelinePending = "pending"
+	pipelineSuccess = "success"
+	pipelineFailure = "failure"
+)
+
+// ChangeRequest represents a pull request or merge request to open
+type ChangeRequest struct {
+	Title        string
+	Body         string
+	SourceBranch string // May be "owner:branch" for cross-fork pull requests, or "namespace/project:branch" on GitLab
+	TargetBranch string
+	Labels       []string
+	Reviewers    []string // Forge usernames
+}
+
+// ChangeRequestInfo describes a pull request or merge request
+type ChangeRequestInfo struct {
+	Number       int       `json:"number"` // Pull request number or merge request IID
+	Title        string    `json:"title"`
+	URL          string    `json:"url"`
+	State        string    `json:"state,omitempty" jsonschema:"open, merged or closed"`
+	SourceBranch string    `json:"source_branch"`
+	TargetBranch string    `json:"target_branch"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// VCSProvider abstracts the operations the tools perform on a forge, so
+// workflows spanning several forges handle them uniformly. Repositories are
+// identified by their "owner/name" path on the forge.
+type VCSProvider interface {
+	// Host returns the forge host, as used by gitCommand and forgeRepoURL
+	Host() string
+	// CreateBranch creates branch from the head of base
+	CreateBranch(ctx context.Context, repo, branch, base string) error
+	// DeleteBranch deletes branch, returning errBranchNotFound when it does not exist
+	DeleteBranch(ctx context.Context, repo, branch string) error
+	// OpenChangeRequest opens a pull or merge request, publishes an MRCreated
+	// event and describes it
+	OpenChangeRequest(ctx context.Context, repo string, request ChangeRequest) (ChangeRequestInfo, error)
+	// ListChangeRequests returns the open pull or merge requests authored by the authenticated user
+	ListChangeRequests(ctx context.Context, repo string) ([]ChangeRequestInfo, error)
+	// FindChangeRequests returns the pull or merge requests of any author and
+	// state whose title contains search, most recent first
+	FindChangeRequests(ctx context.Context, repo, search string) ([]ChangeRequestInfo, error)
+	// CloseChangeRequest comments on a pull or merge request and closes it
+	CloseChangeRequest(ctx context.Context, repo string, number int, comment string) error
+	// PipelineStatus returns the combined CI state of ref: pending, success or failure
+	PipelineStatus(ctx context.Context, repo, ref string) (string, error)
+	// ProtectBranch protects branch against force pushes and deletion
+	ProtectBranch(ctx context.Context, repo, branch string) error
+}
+
+var (
+	vcsProviders   = map[string]func() (VCSProvider, error){}
+	vcsProvidersMu sync.Mutex
+)
+
+// registerVCSProvider makes a provider available for a forge host. Additional
+// backends, such as Gitea mirrors, register themselves the same way.
+func registerVCSProvider(host string, factory func() (VCSProvider, error)) {
+	vcsProvidersMu.Lock()
+	defer vcsProvidersMu.Unlock()
+	vcsProviders[host] = factory
+}
+
+// vcsProvider returns the provider of a forge host
+func vcsProvider(host string) (VCSProvider, error) {
+	vcsProvidersMu.Lock()
+	factory, ok := vcsProviders[host]
+	vcsProvidersMu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("no VCS provider registered for %s", host)
+	}
+	return factory()
+}
+
+func init() {
+	registerVCSProvider(githubHost, func() (VCSProvider, error) {
+		client, err := newGitHubClient()
+		if err != nil {
+			return nil, err
+		}
+		return &githubProvider{client: client}, nil
+	})
+	registerVCSProvider(gitlabHost, func() (VCSProvider, error) {
+		client, err := newGitLabClient()
+		if err != nil {
+			return nil, err
+		}
+		return &gitlabProvider{client: client}, nil
+	})
+}
+
+// githubProvider implements VCSProvider on the GitHub REST API
+type githubProvider struct {
+	client *githubClient
+}
+
+func (p *githubProvider) Host() string { return githubHost }
+
+func (p *githubProvider) CreateBranch(ctx context.Context, repo, branch, base string) error {
+	var ref struct {
+		Object struct {
+			SHA string `json:"sha"`
+		} `json:"object"`
+	}
+	if err := p.client.do(ctx, http.MethodGet, fmt.Sprintf("/repos/%s/git/ref/heads/%s", repo, base), nil, &ref); err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", base, err)
+	}
+	body := map[string]string{"ref": "refs/heads/" + branch, "sha": ref.Object.SHA}
+	return p.client.do(ctx, http.MethodPost, fmt.Sprintf("/repos/%s/git/refs", repo), body, nil)
+}
+
+func (p *githubProvider) DeleteBranch(ctx context.Context, repo, branch string) error {
+	err := p.client.do(ctx, http.MethodDelete, fmt.Sprintf("/repos/%s/git/refs/heads/%s", repo, branch), nil, nil)
+	var ghErr *githubError
+	if errors.As(err, &ghErr) && (ghErr.StatusCode == http.StatusNotFound || strings.Contains(ghErr.Body, "Reference does not exist")) {
+		return errBranchNotFound
+	}
+	return err
+}
+
+func (p *githubProvider) OpenChangeRequest(ctx context.Context, repo string, request ChangeRequest) (ChangeRequestInfo, error) {
+	var pr struct {
+		Number  int       `json:"number"`
+		Title   string    `json:"title"`
+		HTMLURL string    `json:"html_url"`
+		Created time.Time `json:"created_at"`
+		Head    struct {
+			Label string `json:"label"`
+		} `json:"head"`
+		Base struct {
+			Ref string `json:"ref"`
+		} `json:"base"`
+	}
+	body := map[string]string{
+		"title": request.Title,
+		"body":  request.Body,
+		"head":  request.SourceBranch,
+		"base":  request.TargetBranch,
+	}
+	if err := p.client.do(ctx, http.MethodPost, fmt.Sprintf("/repos/%s/pulls", repo), body, &pr); err != nil {
+		return ChangeRequestInfo{}, err
+	}
+	info := ChangeRequestInfo{
+		Number:       pr.Number,
+		Title:        pr.Title,
+		URL:          pr.HTMLURL,
+		SourceBranch: pr.Head.Label,
+		TargetBranch: pr.Base.Ref,
+		CreatedAt:    pr.Created,
+	}
+	_ = publish(ctx, Event{Type: EventMRCreated, Data: info})
+
+	// The pull request is open, failing to label it or request reviews only warrants a warning
+	if len(request.Labels) > 0 {
+		if err := p.client.do(ctx, http.MethodPost, fmt.Sprintf("/repos/%s/issues/%d/labels", repo, pr.Number), map[string][]string{"labels": request.Labels}, nil); err != nil {
+			slog.WarnContext(ctx, "Failed to label pull request", "url", pr.HTMLURL, "error", err)
+		}
+	}
+	if len(request.Reviewers) > 0 {
+		if err := p.client.do(ctx, http.MethodPost, fmt.Sprintf("/repos/%s/pulls/%d/requested_reviewers", repo, pr.Number), map[string][]string{"reviewers": request.Reviewers}, nil); err != nil {
+			slog.WarnContext(ctx, "Failed to request reviews", "url", pr.HTMLURL, "error", err)
+		}
+	}
+	return info, nil
+}
+
+func (p *githubProvider) ListChangeRequests(ctx context.Context, repo string) ([]ChangeRequestInfo, error) {
+	var user struct {
+		Login string `json:"login"`
+	}
+	if err := p.client.do(ctx, http.MethodGet, "/user", nil, &user); err != nil {
+		return nil, fmt.Errorf("failed to resolve the authenticated user: %w", err)
+	}
+
+	var requests []ChangeRequestInfo
+	for page := 1; ; page++ {
+		var pulls []struct {
+			Number  int       `json:"number"`
+			Title   string    `json:"title"`
+			HTMLURL string    `json:"html_url"`
+			Created time.Time `json:"created_at"`
+			User    struct {
+				Login string `json:"login"`
+			} `json:"user"`
+			Head struct {
+				Ref string `json:"ref"`
+			} `json:"head"`
+			Base struct {
+				Ref string `json:"ref"`
+			} `json:"base"`
+		}
+		apiPath := fmt.Sprintf("/repos/%s/pulls?state=open&per_page=%d&page=%d", repo, changeRequestsPageSize, page)
+		if err := p.client.do(ctx, http.MethodGet, apiPath, nil, &pulls); err != nil {
+			return nil, err
+		}
+		for _, pull := range pulls {
+			if pull.User.Login != user.Login {
+				continue
+			}
+			requests = append(requests, ChangeRequestInfo{
+				Number:       pull.Number,
+				Title:        pull.Title,
+				URL:          pull.HTMLURL,
+				State:        "open",
+				SourceBranch: pull.Head.Ref,
+				TargetBranch: pull.Base.Ref,
+				CreatedAt:    pull.Created,
+			})
+		}
+		if len(pulls) < changeRequestsPageSize {
+			break
+		}
+	}
+	return requests, nil
+}
+
+func (p *githubProvider) FindChangeRequests(ctx context.Context, repo, search string) ([]ChangeRequestInfo, error) {
+	var pulls []struct {
+		Number   int        `json:"number"`
+		Title    string     `json:"title"`
+		HTMLURL  string     `json:"html_url"`
+		State    string     `json:"state"`
+		MergedAt *time.Time `json:"merged_at"`
+		Created  time.Time  `json:"created_at"`
+		Head     struct {
+			Ref string `json:"ref"`
+		} `json:"head"`
+		Base struct {
+			Ref string `json:"ref"`
+		} `json:"base"`
+	}
+	// The pulls API cannot search titles, only the last hundred are looked at
+	if err := p.client.do(ctx, http.MethodGet, fmt.Sprintf("/repos/%s/pulls?state=all&sort=created&direction=desc&per_page=100", repo), nil, &pulls); err != nil {
+		return nil, err
+	}
+	var requests []ChangeRequestInfo
+	for _, pull := range pulls {
+		if !strings.Contains(pull.Title, search) {
+			continue
+		}
+		state := pull.State
+		if pull.MergedAt != nil {
+			state = "merged"
+		}
+		requests = append(requests, ChangeRequestInfo{
+			Number:       pull.Number,
+			Title:        pull.Title,
+			URL:          pull.HTMLURL,
+			State:        state,
+			SourceBranch: pull.Head.Ref,
+			TargetBranch: pull.Base.Ref,
+			CreatedAt:    pull.Created,
+		})
+	}
+	return requests, nil
+}
+
+func (p *githubProvider) CloseChangeRequest(ctx context.Context, repo string, number int, comment string) error {
+	if err := p.client.do(ctx, http.MethodPost, fmt.Sprintf("/repos/%s/issues/%d/comments", repo, number), map[string]string{"body": comment}, nil); err != nil {
+		return fmt.Errorf("failed to comment: %w", err)
+	}
+	return p.client.do(ctx, http.MethodPatch, fmt.Sprintf("/repos/%s/pulls/%d", repo, number), map[string]string{"state": "closed"}, nil)
+}
+
+func (p *githubProvider) PipelineStatus(ctx context.Context, repo, ref string) (string, error) {
+	var status struct {
+		State string `json:"state"`
+	}
+	if err := p.client.do(ctx, http.MethodGet, fmt.Sprintf("/repos/%s/commits/%s/status", repo, url.PathEscape(ref)), nil, &status); err != nil {
+		return "", err
+	}
+	switch status.State {
+	case "success":
+		return pipelineSuccess, nil
+	case "failure", "error":
+		return pipelineFailure, nil
+	default:
+		return pipelinePending, nil
+	}
+}
+
+func (p *githubProvider) ProtectBranch(ctx context.Context, repo, branch string) error {
+	body := map[string]any{
+		"required_status_checks":        nil,
+		"enforce_admins":                nil,
+		"required_pull_request_reviews": nil,
+		"restrictions":                  nil,
+		"allow_force_pushes":            false,
+		"allow_deletions":               false,
+	}
+	return p.client.do(ctx, http.MethodPut, fmt.Sprintf("/repos/%s/branches/%s/protection", repo, url.PathEscape(branch)), body, nil)
+}
+
+// gitlabProvider implements VCSProvider on the GitLab REST API
+type gitlabProvider struct {
+	client *gitlabClient
+}
+
+func (p *gitlabProvider) Host() string { return gitlabHost }
+
+func (p *gitlabProvider) CreateBranch(ctx context.Context, repo, branch, base string) error {
+	path := fmt.Sprintf("/projects/%s/repository/branches?branch=%s&ref=%s", url.PathEscape(repo), url.QueryEscape(branch), url.QueryEscape(base))
+	return p.client.do(ctx, http.MethodPost, path, nil, nil)
+}
+
+func (p *gitlabProvider) DeleteBranch(ctx context.Context, repo, branch string) error {
+	err := p.client.do(ctx, http.MethodDelete, fmt.Sprintf("/projects/%s/repository/branches/%s", url.PathEscape(repo), url.PathEscape(branch)), nil, nil)
+	var glErr *gitlabError
+	if errors.As(err, &glErr) && glErr.StatusCode == http.StatusNotFound {
+		return errBranchNotFound
+	}
+	return err
+}
+
+func (p *gitlabProvider) OpenChangeRequest(ctx context.Context, repo string, request ChangeRequest) (ChangeRequestInfo, error) {
+	var mr struct {
+		IID          int       `json:"iid"`
+		Title        string    `json:"title"`
+		WebURL       string    `json:"web_url"`
+		SourceBranch string    `json:"source_branch"`
+		TargetBranch string    `json:"target_branch"`
+		CreatedAt    time.Time `json:"created_at"`
+	}
+	body := map[string]any{
+		"title":                request.Title,
+		"description":          request.Body,
+		"source_branch":        request.SourceBranch,
+		"target_branch":        request.TargetBranch,
+		"remove_source_branch": true,
+	}
+	// Merge requests from a fork are opened on the fork and target the repository by ID
+	sourceRepo := repo
+	if owner, branch, ok := strings.Cut(request.SourceBranch, ":"); ok {
+		sourceRepo = owner
+		if !strings.Contains(owner, "/") {
+			sourceRepo = owner + "/" + path.Base(repo)
+		}
+		var target struct {
+			ID int `json:"id"`
+		}
+		if err := p.client.do(ctx, http.MethodGet, "/projects/"+url.PathEscape(repo), nil, &target); err != nil {
+			return ChangeRequestInfo{}, fmt.Errorf("failed to look up %s: %w", repo, err)
+		}
+		body["source_branch"] = branch
+		body["target_project_id"] = target.ID
+	}
+	if len(request.Labels) > 0 {
+		body["labels"] = strings.Join(request.Labels, ",")
+	}
+	if len(request.Reviewers) > 0 {
+		body["reviewer_ids"] = p.userIDs(ctx, request.Reviewers)
+	}
+	if err := p.client.do(ctx, http.MethodPost, fmt.Sprintf("/projects/%s/merge_requests", url.PathEscape(sourceRepo)), body, &mr); err != nil {
+		return ChangeRequestInfo{}, err
+	}
+	info := ChangeRequestInfo{
+		Number:       mr.IID,
+		Title:        mr.Title,
+		URL:          mr.WebURL,
+		SourceBranch: mr.SourceBranch,
+		TargetBranch: mr.TargetBranch,
+		CreatedAt:    mr.CreatedAt,
+	}
+	_ = publish(ctx, Event{Type: EventMRCreated, Data: info})
+	return info, nil
+}
+
+func (p *gitlabProvider) ListChangeRequests(ctx context.Context, repo string) ([]ChangeRequestInfo, error) {
+	var requests []ChangeRequestInfo
+	for page := 1; ; page++ {
+		var mrs []struct {
+			IID          int       `json:"iid"`
+			Title        string    `json:"title"`
+			WebURL       string    `json:"web_url"`
+			SourceBranch string    `json:"source_branch"`
+			TargetBranch string    `json:"target_branch"`
+			CreatedAt    time.Time `json:"created_at"`
+		}
+		path := fmt.Sprintf("/projects/%s/merge_requests?state=opened&scope=created_by_me&per_page=%d&page=%d", url.PathEscape(repo), changeRequestsPageSize, page)
+		if err := p.client.do(ctx, http.MethodGet, path, nil, &mrs); err != nil {
+			return nil, err
+		}
+		for _, mr := range mrs {
+			requests = append(requests, ChangeRequestInfo{
+				Number:       mr.IID,
+				Title:        mr.Title,
+				URL:          mr.WebURL,
+				State:        "open",
+				SourceBranch: mr.SourceBranch,
+				TargetBranch: mr.TargetBranch,
+				CreatedAt:    mr.CreatedAt,
+			})
+		}
+		if len(mrs) < changeRequestsPageSize {
+			break
+		}
+	}
+	return requests, nil
+}
+
+func (p *gitlabProvider) FindChangeRequests(ctx context.Context, repo, search string) ([]ChangeRequestInfo, error) {
+	var mrs []struct {
+		IID          int       `json:"iid"`
+		Title        string    `json:"title"`
+		WebURL       string    `json:"web_url"`
+		State        string    `json:"state"`
+		SourceBranch string    `json:"source_branch"`
+		TargetBranch string    `json:"target_branch"`
+		CreatedAt    time.Time `json:"created_at"`
+	}
+	path := fmt.Sprintf("/projects/%s/merge_requests?state=all&in=title&search=%s&order_by=created_at&sort=desc&per_page=100", url.PathEscape(repo), url.QueryEscape(search))
+	if err := p.client.do(ctx, http.MethodGet, path, nil, &mrs); err != nil {
+		return nil, err
+	}
+	requests := make([]ChangeRequestInfo, 0, len(mrs))
+	for _, mr := range mrs {
+		state := mr.State
+		switch state {
+		case "opened":
+			state = "open"
+		case "locked":
+			state = "closed"
+		}
+		requests = append(requests, ChangeRequestInfo{
+			Number:       mr.IID,
+			Title:        mr.Title,
+			URL:          mr.WebURL,
+			State:        state,
+			SourceBranch: mr.SourceBranch,
+			TargetBranch: mr.TargetBranch,
+			CreatedAt:    mr.CreatedAt,
+		})
+	}
+	return requests, nil
+}
+
+func (p *gitlabProvider) CloseChangeRequest(ctx context.Context, repo string, number int, comment string) error {
+	project := url.PathEscape(repo)
+	if err := p.client.do(ctx, http.MethodPost, fmt.Sprintf("/projects/%s/merge_requests/%d/notes", project, number), map[string]string{"body": comment}, nil); err != nil {
+		return fmt.Errorf("failed to comment: %w", err)
+	}
+	return p.client.do(ctx, http.MethodPut, fmt.Sprintf("/projects/%s/merge_requests/%d", project, number), map[string]string{"state_event": "close"}, nil)
+}
+
+// userIDs resolves usernames to the user IDs the GitLab API expects. Unknown
+// users are skipped with a warning rather than failing the merge request.
+func (p *gitlabProvider) userIDs(ctx context.Context, usernames []string) []int {
+	var ids []int
+	for _, username := range usernames {
+		var users []struct {
+			ID int `json:"id"`
+		}
+		if err := p.client.do(ctx, http.MethodGet, "/users?username="+url.QueryEscape(username), nil, &users); err != nil || len(users) == 0 {
+			slog.WarnContext(ctx, "Skipping unknown GitLab reviewer", "username", username)
+			continue
+		}
+		ids = append(ids, users[0].ID)
+	}
+	return ids
+}
+
+func (p *gitlabProvider) PipelineStatus(ctx context.Context, repo, ref string) (string, error) {
+	var pipelines []struct {
+		Status string `json:"status"`
+	}
+	path := fmt.Sprintf("/projects/%s/pipelines?ref=%s&per_page=1", url.PathEscape(repo), url.QueryEscape(ref))
+	if err := p.client.do(ctx, http.MethodGet, path, nil, &pipelines); err != nil {
+		return "", err
+	}
+	if len(pipelines) == 0 {
+		return pipelinePending, nil
+	}
+	switch pipelines[0].Status {
+	case "success":
+		return pipelineSuccess, nil
+	case "failed", "canceled":
+		return pipelineFailure, nil
+	default:
+		return pipelinePending, nil
+	}
+}
+
+func (p *gitlabProvider) ProtectBranch(ctx context.Context, repo, branch string) error {
+	path := fmt.Sprintf("/projects/%s/protected_branches?name=%s", url.PathEscape(repo), url.QueryEscape(branch))
+	return p.client.do(ctx, http.MethodPost, path, nil, nil)
+}
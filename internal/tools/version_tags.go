@@ -0,0 +1,166 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// imageVersionLabel is the image label carrying the product version
+const imageVersionLabel = "version"
+
+// VersionTagsConfig represents the configuration for checking version tags
+type VersionTagsConfig struct {
+	MinorVersion string
+	PatchVersion string
+	Registry     string // Registry and namespace of the images, defaults to the stage registry
+}
+
+// GitTagCheck represents the version tag of a component repository
+type GitTagCheck struct {
+	Repository string `json:"repository"`
+	Tag        string `json:"tag"`
+	Exists     bool   `json:"exists"`
+	OnBranch   bool   `json:"on_branch" jsonschema:"Whether the tagged commit is on the release branch"`
+	Commit     string `json:"commit,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// ImageLabelCheck represents the version label of a component image
+type ImageLabelCheck struct {
+	Image   string `json:"image"`
+	Version string `json:"version,omitempty" jsonschema:"Value of the version label"`
+	Matches bool   `json:"matches"`
+	Error   string `json:"error,omitempty"`
+}
+
+// VersionTagsResult represents the structured result of verify-git-tags-match-rpa-version-tags
+type VersionTagsResult struct {
+	Version     string            `json:"version"`
+	Consistent  bool              `json:"consistent" jsonschema:"Whether every repository is tagged on its release branch and every image carries the version"`
+	GitTags     []GitTagCheck     `json:"git_tags"`
+	ImageLabels []ImageLabelCheck `json:"image_labels"`
+}
+
+func verifyVersionTags(ctx context.Context, config VersionTagsConfig) (VersionTagsResult, error) {
+	_, fullVersion := getReleaseType(config.MinorVersion, config.PatchVersion)
+	result := VersionTagsResult{Version: fullVersion, Consistent: true}
+	if config.Registry == "" {
+		config.Registry = productImageNamespace("stage")
+	}
+
+	gh, err := newGitHubClient()
+	if err != nil {
+		return result, err
+	}
+
+	tag := "v" + fullVersion
+	branch := fmt.Sprintf("release-v%s.x", config.MinorVersion)
+	for _, repo := range releaseRepositories() {
+		if repo.Skip || repo.Name == "hack" {
+			continue
+		}
+		check := checkGitTag(ctx, gh, forgeRepoPath(repo.RepoURL), tag, branch)
+		if !check.Exists || !check.OnBranch {
+			result.Consistent = false
+		}
+		result.GitTags = append(result.GitTags, check)
+	}
+
+	for _, image := range componentImages() {
+		check := ImageLabelCheck{Image: fmt.Sprintf("%s/%s:%s", config.Registry, image, tag)}
+		version, err := imageLabel(ctx, check.Image, imageVersionLabel)
+		if err != nil {
+			check.Error = err.Error()
+		}
+		check.Version = version
+		check.Matches = strings.TrimPrefix(version, "v") == fullVersion
+		if !check.Matches {
+			result.Consistent = false
+		}
+		result.ImageLabels = append(result.ImageLabels, check)
+	}
+	return result, nil
+}
+
+// checkGitTag checks the tag exists and points to a commit of the release branch
+func checkGitTag(ctx context.Context, gh *githubClient, repoPath, tag, branch string) GitTagCheck {
+	check := GitTagCheck{Repository: repoPath, Tag: tag}
+
+	var commit struct {
+		SHA string `json:"sha"`
+	}
+	err := gh.do(ctx, http.MethodGet, fmt.Sprintf("/repos/%s/commits/%s", repoPath, url.PathEscape("refs/tags/"+tag)), nil, &commit)
+	var ghErr *githubError
+	if errors.As(err, &ghErr) && (ghErr.StatusCode == http.StatusNotFound || ghErr.StatusCode == http.StatusUnprocessableEntity) {
+		return check
+	}
+	if err != nil {
+		check.Error = err.Error()
+		return check
+	}
+	check.Exists = true
+	check.Commit = commit.SHA
+
+	// The tagged commit is on the branch when the branch is identical to or ahead of it
+	var comparison struct {
+		Status string `json:"status"`
+	}
+	if err := gh.do(ctx, http.MethodGet, fmt.Sprintf("/repos/%s/compare/%s...%s", repoPath, commit.SHA, url.PathEscape(branch)), nil, &comparison); err != nil {
+		check.Error = err.Error()
+		return check
+	}
+	check.OnBranch = comparison.Status == "identical" || comparison.Status == "ahead"
+	return check
+}
+
+// imageLabel returns a label of an image from its configuration
+func imageLabel(ctx context.Context, image, label string) (string, error) {
+	output, err := runCrane(ctx, "config", image)
+	if err != nil {
+		return "", err
+	}
+	var config struct {
+		Config struct {
+			Labels map[string]string `json:"Labels"`
+		} `json:"config"`
+	}
+	if err := json.Unmarshal([]byte(output), &config); err != nil {
+		return "", fmt.Errorf("failed to parse configuration of %s: %w", image, err)
+	}
+	return config.Config.Labels[label], nil
+}
+
+func formatVersionTags(result VersionTagsResult) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Git tags for v%s:\n", result.Version)
+	for _, check := range result.GitTags {
+		switch {
+		case check.Error != "":
+			fmt.Fprintf(&sb, "? %s: %s\n", check.Repository, check.Error)
+		case !check.Exists:
+			fmt.Fprintf(&sb, "✗ %s: tag %s not found\n", check.Repository, check.Tag)
+		case !check.OnBranch:
+			fmt.Fprintf(&sb, "✗ %s: tag %s points to %s, which is not on the release branch\n", check.Repository, check.Tag, check.Commit)
+		default:
+			fmt.Fprintf(&sb, "✓ %s: %s\n", check.Repository, check.Tag)
+		}
+	}
+
+	sb.WriteString("\nImage version labels:\n")
+	for _, check := range result.ImageLabels {
+		switch {
+		case check.Error != "":
+			fmt.Fprintf(&sb, "? %s: %s\n", check.Image, check.Error)
+		case !check.Matches:
+			fmt.Fprintf(&sb, "✗ %s: version label is %q\n", check.Image, check.Version)
+		default:
+			fmt.Fprintf(&sb, "✓ %s\n", check.Image)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,123 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// hackRepo is the repository holding the hack configuration and the versions manifest
+	hackRepo = "openshift-pipelines/hack"
+
+	// versionsManifestPath is the versions manifest within the hack repository
+	versionsManifestPath = "config/versions.yaml"
+
+	versionsManifestHeader = "# Upstream versions shipped by each downstream minor version, by repository.\n" +
+		"# This file is the source of truth for configure-hack-repo and is updated by it.\n"
+)
+
+// VersionsManifest represents the upstream version pins of every downstream minor version
+type VersionsManifest struct {
+	Versions map[string]map[string]string `yaml:"versions"`
+}
+
+// readVersionsManifest reads the versions manifest of a hack clone. A missing
+// manifest is returned empty.
+func readVersionsManifest(repoPath string) (*VersionsManifest, error) {
+	content, err := os.ReadFile(filepath.Join(repoPath, versionsManifestPath))
+	if os.IsNotExist(err) {
+		return &VersionsManifest{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", versionsManifestPath, err)
+	}
+	return parseVersionsManifest(content)
+}
+
+func parseVersionsManifest(content []byte) (*VersionsManifest, error) {
+	var manifest VersionsManifest
+	if err := yaml.Unmarshal(content, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", versionsManifestPath, err)
+	}
+	return &manifest, nil
+}
+
+// pinnedUpstreamVersions completes the upstream versions of a hack
+// configuration with the pins of its minor version in the manifest. Explicit
+// versions take precedence. It returns the repositories whose pin the explicit
+// versions add or change.
+func pinnedUpstreamVersions(config *HackConfig, manifest *VersionsManifest) []string {
+	if config.UpstreamConfig == nil {
+		config.UpstreamConfig = make(map[string]string)
+	}
+	pins := manifest.Versions[config.MinorVersion]
+
+	var changed []string
+	for repo, version := range config.UpstreamConfig {
+		if pins[repo] != version {
+			changed = append(changed, repo)
+		}
+	}
+	for repo, version := range pins {
+		if _, ok := config.UpstreamConfig[repo]; !ok {
+			config.UpstreamConfig[repo] = version
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// writeVersionsManifest records the upstream versions of a minor version in
+// the manifest of a hack clone
+func writeVersionsManifest(ctx context.Context, repoPath string, manifest *VersionsManifest, minorVersion string, upstreamVersions map[string]string) error {
+	if manifest.Versions == nil {
+		manifest.Versions = make(map[string]map[string]string)
+	}
+	manifest.Versions[minorVersion] = upstreamVersions
+
+	var content bytes.Buffer
+	content.WriteString(versionsManifestHeader)
+	encoder := yaml.NewEncoder(&content)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(manifest); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", versionsManifestPath, err)
+	}
+	manifestPath := filepath.Join(repoPath, versionsManifestPath)
+	if err := os.MkdirAll(filepath.Dir(manifestPath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", versionsManifestPath, err)
+	}
+	if err := writeFile(ctx, manifestPath, content.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", versionsManifestPath, err)
+	}
+	return nil
+}
+
+// fetchPinnedUpstreamVersions reads the pins of a minor version from the
+// manifest on its hack release branch. A missing manifest has no pins.
+func fetchPinnedUpstreamVersions(ctx context.Context, minorVersion string) (map[string]string, error) {
+	gh, err := newGitHubClient()
+	if err != nil {
+		return nil, err
+	}
+	content, err := gh.fileContent(ctx, hackRepo, versionsManifestPath, fmt.Sprintf("release-v%s.x", minorVersion))
+	var ghErr *githubError
+	if errors.As(err, &ghErr) && ghErr.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := parseVersionsManifest(content)
+	if err != nil {
+		return nil, err
+	}
+	return manifest.Versions[minorVersion], nil
+}
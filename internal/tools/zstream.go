@@ -0,0 +1,381 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// buildTriggerComment is the Pipelines-as-Code GitOps command re-running the
+// on-push pipelines of the commit it is posted on. Without the branch,
+// Pipelines-as-Code matches the pipelines against the default branch.
+const buildTriggerComment = "/retest branch:%s"
+
+// comparePageSize is the number of commits requested per page of the compare API
+const comparePageSize = 100
+
+// ZStreamConfig represents the configuration for orchestrating a z-stream release
+type ZStreamConfig struct {
+	MinorVersion string
+	PatchVersion string // Defaults to the patch after the latest released one
+	RepoPath     string
+	OCPVersions  []string
+	Issues       []string // Fixed JIRA issues recorded in the release notes
+	DryRun       bool     // Only report, without creating release plans or triggering builds
+}
+
+// ZStreamStep represents the outcome of a step of the z-stream workflow
+type ZStreamStep struct {
+	Name   string `json:"name"`
+	Status string `json:"status" jsonschema:"done, skipped or failed"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// RepoBackports represents the changes merged into a release branch since the previous z-stream
+type RepoBackports struct {
+	Repository     string   `json:"repository"`
+	Commits        []string `json:"commits,omitempty" jsonschema:"Subjects of the commits merged since the previous z-stream"`
+	HeadSHA        string   `json:"head_sha,omitempty"`
+	BuildTriggered bool     `json:"build_triggered"`
+	PipelineStatus string   `json:"pipeline_status,omitempty" jsonschema:"CI state of the branch head: pending, success or failure"`
+	Error          string   `json:"error,omitempty"`
+}
+
+// ZStreamResult represents the structured result and readiness report of orchestrate-z-stream-release
+type ZStreamResult struct {
+	Version           string          `json:"version"`
+	PreviousVersion   string          `json:"previous_version"`
+	DryRun            bool            `json:"dry_run"`
+	Ready             bool            `json:"ready" jsonschema:"Whether plans exist, every changed repository built successfully and nothing blocks the release"`
+	ReleasePlanBranch string          `json:"release_plan_branch,omitempty"`
+	ReleasePlanMRURL  string          `json:"release_plan_mr_url,omitempty"`
+	Steps             []ZStreamStep   `json:"steps"`
+	Repositories      []RepoBackports `json:"repositories"`
+	BlockingIssues    []BlockingIssue `json:"blocking_issues,omitempty"`
+}
+
+func (r *ZStreamResult) step(name, status, detail string) {
+	r.Steps = append(r.Steps, ZStreamStep{Name: name, Status: status, Detail: detail})
+}
+
+func orchestrateZStream(ctx context.Context, config ZStreamConfig) (ZStreamResult, error) {
+	result := ZStreamResult{DryRun: config.DryRun}
+	if len(config.OCPVersions) == 0 {
+		return result, fmt.Errorf("no OCP versions to release the index images for")
+	}
+
+	gh, err := newGitHubClient()
+	if err != nil {
+		return result, err
+	}
+
+	// Detect the previous z-stream from the operator tags
+	previous, err := latestPatchVersion(ctx, gh, operatorRepository(), config.MinorVersion, config.PatchVersion)
+	if err != nil {
+		return result, err
+	}
+	if config.PatchVersion == "" {
+		config.PatchVersion = strconv.Itoa(previous + 1)
+	}
+	result.PreviousVersion = fmt.Sprintf("%s.%d", config.MinorVersion, previous)
+	_, result.Version = getReleaseType(config.MinorVersion, config.PatchVersion)
+	result.step("detect-previous-version", "done", "v"+result.PreviousVersion)
+
+	// Collect what was merged into every release branch since then
+	branch := fmt.Sprintf("release-v%s.x", config.MinorVersion)
+	changed := 0
+	for _, repo := range releaseRepositories() {
+		if repo.Skip || repo.Name == "hack" {
+			continue
+		}
+		backports := collectBackports(ctx, gh, forgeRepoPath(repo.RepoURL), "v"+result.PreviousVersion, branch)
+		if len(backports.Commits) > 0 {
+			changed++
+		}
+		result.Repositories = append(result.Repositories, backports)
+	}
+	result.step("collect-backports", "done", fmt.Sprintf("%d of %d repositories changed", changed, len(result.Repositories)))
+
+	// Generate the RHBA release plans
+	if config.DryRun {
+		result.step("create-release-plans", "skipped", "dry run")
+	} else {
+		pushed, err := createReleasePlans(ctx, RPAConfig{
+			MinorVersion: config.MinorVersion,
+			PatchVersion: config.PatchVersion,
+			RepoPath:     config.RepoPath,
+			Components:   productComponents(),
+			Environments: []string{"stage", "prod"},
+			OCPVersions:  config.OCPVersions,
+			Issues:       config.Issues,
+		})
+		if err != nil {
+			result.step("create-release-plans", "failed", err.Error())
+		} else {
+			result.ReleasePlanBranch = pushed.BranchName
+			result.ReleasePlanMRURL = pushed.MergeRequestURL
+			result.step("create-release-plans", "done", "opened "+pushed.MergeRequestURL)
+		}
+	}
+
+	// Trigger builds of the changed branches
+	if config.DryRun {
+		result.step("trigger-builds", "skipped", "dry run")
+	} else {
+		triggered, failed := 0, 0
+		for i := range result.Repositories {
+			backports := &result.Repositories[i]
+			if len(backports.Commits) == 0 || backports.HeadSHA == "" {
+				continue
+			}
+			if err := triggerBuild(ctx, gh, backports.Repository, branch, backports.HeadSHA); err != nil {
+				backports.Error = err.Error()
+				failed++
+				continue
+			}
+			backports.BuildTriggered = true
+			triggered++
+		}
+		status := "done"
+		if failed > 0 {
+			status = "failed"
+		}
+		result.step("trigger-builds", status, fmt.Sprintf("%d triggered, %d failed", triggered, failed))
+	}
+
+	// Report readiness
+	result.Ready = readinessReport(ctx, &result)
+	return result, nil
+}
+
+// latestPatchVersion returns the highest patch of the minor version tagged in
+// repo, below the target patch when one is given
+func latestPatchVersion(ctx context.Context, gh *githubClient, repo, minorVersion, targetPatch string) (int, error) {
+	limit := -1
+	if targetPatch != "" {
+		var err error
+		if limit, err = strconv.Atoi(targetPatch); err != nil {
+			return 0, fmt.Errorf("invalid patch version %q", targetPatch)
+		}
+	}
+
+	patches, err := releasedPatchVersions(ctx, gh, repo, minorVersion, limit)
+	if err != nil {
+		return 0, err
+	}
+	if len(patches) == 0 {
+		return 0, fmt.Errorf("no previous release of %s is tagged in %s", minorVersion, repo)
+	}
+	return patches[len(patches)-1], nil
+}
+
+// releasedPatchVersions returns the patches of the minor version tagged in
+// repo in ascending order, only those below limit unless it is negative
+func releasedPatchVersions(ctx context.Context, gh *githubClient, repo, minorVersion string, limit int) ([]int, error) {
+	var refs []struct {
+		Ref string `json:"ref"`
+	}
+	prefix := fmt.Sprintf("refs/tags/v%s.", minorVersion)
+	if err := gh.do(ctx, http.MethodGet, fmt.Sprintf("/repos/%s/git/matching-refs/%s", repo, strings.TrimPrefix(prefix, "refs/")), nil, &refs); err != nil {
+		return nil, fmt.Errorf("failed to list tags of %s: %w", repo, err)
+	}
+
+	var patches []int
+	for _, ref := range refs {
+		patch, err := strconv.Atoi(strings.TrimPrefix(ref.Ref, prefix))
+		if err != nil || (limit >= 0 && patch >= limit) {
+			continue
+		}
+		patches = append(patches, patch)
+	}
+	sort.Ints(patches)
+	return patches, nil
+}
+
+// collectBackports lists the commits of branch that are not in tag
+func collectBackports(ctx context.Context, gh *githubClient, repo, tag, branch string) RepoBackports {
+	backports := RepoBackports{Repository: repo}
+	commits, err := compareCommits(ctx, gh, repo, tag, branch)
+	if err != nil {
+		backports.Error = err.Error()
+		return backports
+	}
+	for _, commit := range commits {
+		backports.Commits = append(backports.Commits, commit.Subject)
+		backports.HeadSHA = commit.SHA
+	}
+	return backports
+}
+
+// compareCommits lists the commits of head that are not in base, oldest
+// first. The compare API pages the commits, so the pages are read until
+// total_commits are collected.
+func compareCommits(ctx context.Context, gh *githubClient, repo, base, head string) ([]MergedCommit, error) {
+	var commits []MergedCommit
+	for page := 1; ; page++ {
+		pageCommits, total, err := compareCommitsPage(ctx, gh, repo, base, head, page)
+		if err != nil {
+			return nil, err
+		}
+		commits = append(commits, pageCommits...)
+		if len(pageCommits) == 0 || len(commits) >= total {
+			return commits, nil
+		}
+	}
+}
+
+// compareCommitsPage returns a page of the commits of head that are not in
+// base, along with the total number of such commits
+func compareCommitsPage(ctx context.Context, gh *githubClient, repo, base, head string, page int) ([]MergedCommit, int, error) {
+	var comparison struct {
+		TotalCommits int `json:"total_commits"`
+		Commits      []struct {
+			SHA    string `json:"sha"`
+			Commit struct {
+				Message string `json:"message"`
+				Author  struct {
+					Name string    `json:"name"`
+					Date time.Time `json:"date"`
+				} `json:"author"`
+			} `json:"commit"`
+		} `json:"commits"`
+	}
+	apiPath := fmt.Sprintf("/repos/%s/compare/%s...%s?per_page=%d&page=%d", repo, url.PathEscape(base), url.PathEscape(head), comparePageSize, page)
+	if err := gh.do(ctx, http.MethodGet, apiPath, nil, &comparison); err != nil {
+		return nil, 0, err
+	}
+	commits := make([]MergedCommit, 0, len(comparison.Commits))
+	for _, commit := range comparison.Commits {
+		commits = append(commits, MergedCommit{
+			SHA:     commit.SHA,
+			Subject: strings.SplitN(commit.Commit.Message, "\n", 2)[0],
+			Author:  commit.Commit.Author.Name,
+			Date:    commit.Commit.Author.Date,
+		})
+	}
+	return commits, comparison.TotalCommits, nil
+}
+
+// triggerBuild asks Pipelines-as-Code to re-run the on-push pipelines of the
+// head commit of a branch. The command is not posted again when an earlier
+// run already left it on the commit.
+func triggerBuild(ctx context.Context, gh *githubClient, repo, branch, sha string) error {
+	command := fmt.Sprintf(buildTriggerComment, branch)
+	var comments []struct {
+		Body string `json:"body"`
+	}
+	if err := gh.do(ctx, http.MethodGet, fmt.Sprintf("/repos/%s/commits/%s/comments?per_page=100", repo, sha), nil, &comments); err != nil {
+		return fmt.Errorf("failed to list comments of %s: %w", sha, err)
+	}
+	for _, comment := range comments {
+		if strings.TrimSpace(comment.Body) == command {
+			return nil
+		}
+	}
+
+	body := map[string]string{"body": command}
+	if err := gh.do(ctx, http.MethodPost, fmt.Sprintf("/repos/%s/commits/%s/comments", repo, sha), body, nil); err != nil {
+		return fmt.Errorf("failed to trigger build of %s: %w", sha, err)
+	}
+	return nil
+}
+
+// readinessReport records the build status of the changed branches and the
+// open blockers, and returns whether the z-stream is ready to ship
+func readinessReport(ctx context.Context, result *ZStreamResult) bool {
+	ready := result.DryRun || result.ReleasePlanBranch != ""
+
+	provider, err := vcsProvider(githubHost)
+	if err != nil {
+		result.step("check-builds", "failed", err.Error())
+		ready = false
+	} else {
+		for i := range result.Repositories {
+			backports := &result.Repositories[i]
+			if backports.Error != "" {
+				ready = false
+				continue
+			}
+			if len(backports.Commits) == 0 {
+				continue
+			}
+			status, err := provider.PipelineStatus(ctx, backports.Repository, backports.HeadSHA)
+			if err != nil {
+				backports.Error = err.Error()
+				ready = false
+				continue
+			}
+			backports.PipelineStatus = status
+			if status != pipelineSuccess {
+				ready = false
+			}
+		}
+		result.step("check-builds", "done", "")
+	}
+
+	config := BlockingIssuesConfig{Version: result.Version, GitHubOrgs: []string{product().Organization}}
+	if os.Getenv("JIRA_TOKEN") != "" {
+		config.JiraProjects = productJiraProjects()
+	}
+	issues, err := findBlockingIssues(ctx, config)
+	if err != nil {
+		result.step("find-blocking-issues", "failed", err.Error())
+		return false
+	}
+	result.BlockingIssues = issues
+	result.step("find-blocking-issues", "done", fmt.Sprintf("%d open", len(issues)))
+	return ready && len(issues) == 0
+}
+
+func formatZStream(result ZStreamResult) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Z-stream %s (previous %s)", result.Version, result.PreviousVersion)
+	if result.DryRun {
+		sb.WriteString(", dry run")
+	}
+	sb.WriteString("\n\nSteps:\n")
+	for _, step := range result.Steps {
+		fmt.Fprintf(&sb, "- %s: %s", step.Name, step.Status)
+		if step.Detail != "" {
+			fmt.Fprintf(&sb, " (%s)", step.Detail)
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("\nBackports:\n")
+	for _, backports := range result.Repositories {
+		switch {
+		case backports.Error != "":
+			fmt.Fprintf(&sb, "? %s: %s\n", backports.Repository, backports.Error)
+		case len(backports.Commits) == 0:
+			fmt.Fprintf(&sb, "- %s: no changes\n", backports.Repository)
+		default:
+			fmt.Fprintf(&sb, "- %s: %d commits", backports.Repository, len(backports.Commits))
+			if backports.PipelineStatus != "" {
+				fmt.Fprintf(&sb, ", build %s", backports.PipelineStatus)
+			}
+			sb.WriteString("\n")
+			for _, commit := range backports.Commits {
+				fmt.Fprintf(&sb, "  - %s\n", commit)
+			}
+		}
+	}
+
+	if len(result.BlockingIssues) > 0 {
+		sb.WriteString("\n")
+		sb.WriteString(formatBlockingIssues(result.Version, result.BlockingIssues))
+	}
+
+	if result.Ready {
+		fmt.Fprintf(&sb, "\n%s is ready to release\n", result.Version)
+	} else {
+		fmt.Fprintf(&sb, "\n%s is not ready to release\n", result.Version)
+	}
+	return sb.String()
+}